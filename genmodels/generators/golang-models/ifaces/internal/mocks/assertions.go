@@ -0,0 +1,11 @@
+package mocks
+
+import "github.com/fredbi/core/jsonschema/analyzers/structural"
+
+// The mocks are generated by mockery (see .mockery.yml): these assertions break the build as soon as
+// a mocked interface changes and the mocks are not regenerated.
+var (
+	_ structural.Analyzer               = (*MockAnalyzer)(nil)
+	_ structural.Namespace              = (*MockNamespace)(nil)
+	_ structural.BacktrackableNamespace = (*MockBacktrackableNamespace)(nil)
+)
@@ -5,12 +5,14 @@
 package mocks
 
 import (
+	"io"
 	"iter"
 	"sync"
 
 	"github.com/fredbi/core/jsonschema"
 	"github.com/fredbi/core/jsonschema/analyzers"
 	"github.com/fredbi/core/jsonschema/analyzers/structural"
+	"github.com/fredbi/core/jsonschema/analyzers/structural/export"
 )
 
 // Ensure that MockAnalyzer does implement structural.Analyzer.
@@ -38,6 +40,9 @@ var _ structural.Analyzer = &MockAnalyzer{}
 //			BundleFunc: func() (structural.Analyzer, error) {
 //				panic("mock out the Bundle method")
 //			},
+//			ExportGraphFunc: func(writer io.Writer, format export.Format, filters ...structural.Filter) error {
+//				panic("mock out the ExportGraph method")
+//			},
 //			LenFunc: func() int {
 //				panic("mock out the Len method")
 //			},
@@ -53,8 +58,11 @@ var _ structural.Analyzer = &MockAnalyzer{}
 //			MarkSchemaFunc: func(analyzedSchema structural.AnalyzedSchema, extensions structural.Extensions)  {
 //				panic("mock out the MarkSchema method")
 //			},
-//			NamespacesFunc: func(filters ...structural.Filter) iter.Seq[string] {
-//				panic("mock out the Namespaces method")
+//			PackageByIDFunc: func(uniqueID analyzers.UniqueID) (structural.AnalyzedPackage, bool) {
+//				panic("mock out the PackageByID method")
+//			},
+//			PackagePathsFunc: func(filters ...structural.Filter) iter.Seq[string] {
+//				panic("mock out the PackagePaths method")
 //			},
 //			PackagesFunc: func(filters ...structural.Filter) iter.Seq[structural.AnalyzedPackage] {
 //				panic("mock out the Packages method")
@@ -62,6 +70,9 @@ var _ structural.Analyzer = &MockAnalyzer{}
 //			SchemaByIDFunc: func(uniqueID analyzers.UniqueID) (structural.AnalyzedSchema, bool) {
 //				panic("mock out the SchemaByID method")
 //			},
+//			SchemasFunc: func() iter.Seq[jsonschema.Schema] {
+//				panic("mock out the Schemas method")
+//			},
 //		}
 //
 //		// use mockedAnalyzer in code that requires structural.Analyzer
@@ -84,6 +95,9 @@ type MockAnalyzer struct {
 	// BundleFunc mocks the Bundle method.
 	BundleFunc func() (structural.Analyzer, error)
 
+	// ExportGraphFunc mocks the ExportGraph method.
+	ExportGraphFunc func(writer io.Writer, format export.Format, filters ...structural.Filter) error
+
 	// LenFunc mocks the Len method.
 	LenFunc func() int
 
@@ -99,8 +113,11 @@ type MockAnalyzer struct {
 	// MarkSchemaFunc mocks the MarkSchema method.
 	MarkSchemaFunc func(analyzedSchema structural.AnalyzedSchema, extensions structural.Extensions)
 
-	// NamespacesFunc mocks the Namespaces method.
-	NamespacesFunc func(filters ...structural.Filter) iter.Seq[string]
+	// PackageByIDFunc mocks the PackageByID method.
+	PackageByIDFunc func(uniqueID analyzers.UniqueID) (structural.AnalyzedPackage, bool)
+
+	// PackagePathsFunc mocks the PackagePaths method.
+	PackagePathsFunc func(filters ...structural.Filter) iter.Seq[string]
 
 	// PackagesFunc mocks the Packages method.
 	PackagesFunc func(filters ...structural.Filter) iter.Seq[structural.AnalyzedPackage]
@@ -108,6 +125,9 @@ type MockAnalyzer struct {
 	// SchemaByIDFunc mocks the SchemaByID method.
 	SchemaByIDFunc func(uniqueID analyzers.UniqueID) (structural.AnalyzedSchema, bool)
 
+	// SchemasFunc mocks the Schemas method.
+	SchemasFunc func() iter.Seq[jsonschema.Schema]
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// Analyze holds details about calls to the Analyze method.
@@ -135,6 +155,15 @@ type MockAnalyzer struct {
 		// Bundle holds details about calls to the Bundle method.
 		Bundle []struct {
 		}
+		// ExportGraph holds details about calls to the ExportGraph method.
+		ExportGraph []struct {
+			// Writer is the writer argument value.
+			Writer io.Writer
+			// Format is the format argument value.
+			Format export.Format
+			// Filters is the filters argument value.
+			Filters []structural.Filter
+		}
 		// Len holds details about calls to the Len method.
 		Len []struct {
 		}
@@ -166,8 +195,13 @@ type MockAnalyzer struct {
 			// Extensions is the extensions argument value.
 			Extensions structural.Extensions
 		}
-		// Namespaces holds details about calls to the Namespaces method.
-		Namespaces []struct {
+		// PackageByID holds details about calls to the PackageByID method.
+		PackageByID []struct {
+			// UniqueID is the uniqueID argument value.
+			UniqueID analyzers.UniqueID
+		}
+		// PackagePaths holds details about calls to the PackagePaths method.
+		PackagePaths []struct {
 			// Filters is the filters argument value.
 			Filters []structural.Filter
 		}
@@ -181,20 +215,26 @@ type MockAnalyzer struct {
 			// UniqueID is the uniqueID argument value.
 			UniqueID analyzers.UniqueID
 		}
+		// Schemas holds details about calls to the Schemas method.
+		Schemas []struct {
+		}
 	}
 	lockAnalyze           sync.RWMutex
 	lockAnalyzeCollection sync.RWMutex
 	lockAnalyzedSchemas   sync.RWMutex
 	lockAnnotateSchema    sync.RWMutex
 	lockBundle            sync.RWMutex
+	lockExportGraph       sync.RWMutex
 	lockLen               sync.RWMutex
 	lockLogAudit          sync.RWMutex
 	lockLogAuditPackage   sync.RWMutex
 	lockMarkPackage       sync.RWMutex
 	lockMarkSchema        sync.RWMutex
-	lockNamespaces        sync.RWMutex
+	lockPackageByID       sync.RWMutex
+	lockPackagePaths      sync.RWMutex
 	lockPackages          sync.RWMutex
 	lockSchemaByID        sync.RWMutex
+	lockSchemas           sync.RWMutex
 }
 
 // Analyze calls AnalyzeFunc.
@@ -356,6 +396,46 @@ func (mock *MockAnalyzer) BundleCalls() []struct {
 	return calls
 }
 
+// ExportGraph calls ExportGraphFunc.
+func (mock *MockAnalyzer) ExportGraph(writer io.Writer, format export.Format, filters ...structural.Filter) error {
+	if mock.ExportGraphFunc == nil {
+		panic("MockAnalyzer.ExportGraphFunc: method is nil but Analyzer.ExportGraph was just called")
+	}
+	callInfo := struct {
+		Writer  io.Writer
+		Format  export.Format
+		Filters []structural.Filter
+	}{
+		Writer:  writer,
+		Format:  format,
+		Filters: filters,
+	}
+	mock.lockExportGraph.Lock()
+	mock.calls.ExportGraph = append(mock.calls.ExportGraph, callInfo)
+	mock.lockExportGraph.Unlock()
+	return mock.ExportGraphFunc(writer, format, filters...)
+}
+
+// ExportGraphCalls gets all the calls that were made to ExportGraph.
+// Check the length with:
+//
+//	len(mockedAnalyzer.ExportGraphCalls())
+func (mock *MockAnalyzer) ExportGraphCalls() []struct {
+	Writer  io.Writer
+	Format  export.Format
+	Filters []structural.Filter
+} {
+	var calls []struct {
+		Writer  io.Writer
+		Format  export.Format
+		Filters []structural.Filter
+	}
+	mock.lockExportGraph.RLock()
+	calls = mock.calls.ExportGraph
+	mock.lockExportGraph.RUnlock()
+	return calls
+}
+
 // Len calls LenFunc.
 func (mock *MockAnalyzer) Len() int {
 	if mock.LenFunc == nil {
@@ -527,35 +607,67 @@ func (mock *MockAnalyzer) MarkSchemaCalls() []struct {
 	return calls
 }
 
-// Namespaces calls NamespacesFunc.
-func (mock *MockAnalyzer) Namespaces(filters ...structural.Filter) iter.Seq[string] {
-	if mock.NamespacesFunc == nil {
-		panic("MockAnalyzer.NamespacesFunc: method is nil but Analyzer.Namespaces was just called")
+// PackageByID calls PackageByIDFunc.
+func (mock *MockAnalyzer) PackageByID(uniqueID analyzers.UniqueID) (structural.AnalyzedPackage, bool) {
+	if mock.PackageByIDFunc == nil {
+		panic("MockAnalyzer.PackageByIDFunc: method is nil but Analyzer.PackageByID was just called")
+	}
+	callInfo := struct {
+		UniqueID analyzers.UniqueID
+	}{
+		UniqueID: uniqueID,
+	}
+	mock.lockPackageByID.Lock()
+	mock.calls.PackageByID = append(mock.calls.PackageByID, callInfo)
+	mock.lockPackageByID.Unlock()
+	return mock.PackageByIDFunc(uniqueID)
+}
+
+// PackageByIDCalls gets all the calls that were made to PackageByID.
+// Check the length with:
+//
+//	len(mockedAnalyzer.PackageByIDCalls())
+func (mock *MockAnalyzer) PackageByIDCalls() []struct {
+	UniqueID analyzers.UniqueID
+} {
+	var calls []struct {
+		UniqueID analyzers.UniqueID
+	}
+	mock.lockPackageByID.RLock()
+	calls = mock.calls.PackageByID
+	mock.lockPackageByID.RUnlock()
+	return calls
+}
+
+// PackagePaths calls PackagePathsFunc.
+func (mock *MockAnalyzer) PackagePaths(filters ...structural.Filter) iter.Seq[string] {
+	if mock.PackagePathsFunc == nil {
+		panic("MockAnalyzer.PackagePathsFunc: method is nil but Analyzer.PackagePaths was just called")
 	}
 	callInfo := struct {
 		Filters []structural.Filter
 	}{
 		Filters: filters,
 	}
-	mock.lockNamespaces.Lock()
-	mock.calls.Namespaces = append(mock.calls.Namespaces, callInfo)
-	mock.lockNamespaces.Unlock()
-	return mock.NamespacesFunc(filters...)
+	mock.lockPackagePaths.Lock()
+	mock.calls.PackagePaths = append(mock.calls.PackagePaths, callInfo)
+	mock.lockPackagePaths.Unlock()
+	return mock.PackagePathsFunc(filters...)
 }
 
-// NamespacesCalls gets all the calls that were made to Namespaces.
+// PackagePathsCalls gets all the calls that were made to PackagePaths.
 // Check the length with:
 //
-//	len(mockedAnalyzer.NamespacesCalls())
-func (mock *MockAnalyzer) NamespacesCalls() []struct {
+//	len(mockedAnalyzer.PackagePathsCalls())
+func (mock *MockAnalyzer) PackagePathsCalls() []struct {
 	Filters []structural.Filter
 } {
 	var calls []struct {
 		Filters []structural.Filter
 	}
-	mock.lockNamespaces.RLock()
-	calls = mock.calls.Namespaces
-	mock.lockNamespaces.RUnlock()
+	mock.lockPackagePaths.RLock()
+	calls = mock.calls.PackagePaths
+	mock.lockPackagePaths.RUnlock()
 	return calls
 }
 
@@ -623,6 +735,33 @@ func (mock *MockAnalyzer) SchemaByIDCalls() []struct {
 	return calls
 }
 
+// Schemas calls SchemasFunc.
+func (mock *MockAnalyzer) Schemas() iter.Seq[jsonschema.Schema] {
+	if mock.SchemasFunc == nil {
+		panic("MockAnalyzer.SchemasFunc: method is nil but Analyzer.Schemas was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockSchemas.Lock()
+	mock.calls.Schemas = append(mock.calls.Schemas, callInfo)
+	mock.lockSchemas.Unlock()
+	return mock.SchemasFunc()
+}
+
+// SchemasCalls gets all the calls that were made to Schemas.
+// Check the length with:
+//
+//	len(mockedAnalyzer.SchemasCalls())
+func (mock *MockAnalyzer) SchemasCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockSchemas.RLock()
+	calls = mock.calls.Schemas
+	mock.lockSchemas.RUnlock()
+	return calls
+}
+
 // Ensure that MockNamespace does implement structural.Namespace.
 // If this is not the case, regenerate this file with mockery.
 var _ structural.Namespace = &MockNamespace{}
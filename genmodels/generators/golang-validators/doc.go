@@ -0,0 +1,17 @@
+// Package validators generates go code that validates JSON data against a JSON schema.
+//
+// The schema is compiled ahead of time by the validations analyzer: "$ref" s are resolved, all checks
+// are unrolled into go functions, and constants such as numbers, JSON values or regular expressions are
+// evaluated once when the generated package is initialized.
+//
+// The generated package exposes:
+//
+//   - ValidateDocument(json.Document) error
+//   - ValidateBytes([]byte) error
+//   - SetFormats(registries.Registry), to assert "format" with a registry of string formats
+//
+// Validations report the same violations as the interpreted validator of
+// github.com/fredbi/core/jsonschema/validator, with the same locations and messages.
+//
+// Custom keywords, "$dynamicRef" and "$recursiveRef" to a dynamic anchor are not supported.
+package validators
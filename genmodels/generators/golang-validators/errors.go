@@ -0,0 +1,13 @@
+package validators
+
+// Error is a sentinel error type for all errors originated from this package.
+type Error string
+
+const (
+	// ErrValidators is a sentinel error that wraps any error that occurred during generation.
+	ErrValidators Error = "error in golang validators generator"
+)
+
+func (e Error) Error() string {
+	return string(e)
+}
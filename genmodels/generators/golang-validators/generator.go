@@ -0,0 +1,134 @@
+package validators
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/fredbi/core/codegen/genapp"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/analyzers/validations"
+)
+
+const userWritableOtherReadable = 0o755
+
+const (
+	validatorTemplate = "validator"
+	supportTemplate   = "support"
+	validatorFile     = "validator.go"
+	supportFile       = "support.go"
+)
+
+// Generator of go validators for JSON schemas.
+type Generator struct {
+	options
+
+	generator *genapp.GoGenApp
+}
+
+// New [Generator] for go validators, with [Option] s.
+func New(opts ...Option) *Generator {
+	g := &Generator{
+		options: optionsWithDefaults(opts),
+	}
+
+	g.generator = genapp.New(g.templatesFS,
+		genapp.WithOutputAferoFS(g.baseFS),
+		genapp.WithOutputPath(g.outputPath),
+		genapp.WithSkipFormat(g.skipFmt),
+	)
+
+	return g
+}
+
+// genPackage is the data passed to the templates.
+type genPackage struct {
+	validations.Program
+
+	Package  string
+	Location string
+	Imports  []string
+}
+
+// Generate the go package that validates JSON data against a [jsonschema.Schema].
+//
+// The package is made of two files: "validator.go" with the compiled schema, and "support.go" with the
+// types and helpers it uses.
+func (g *Generator) Generate(sch jsonschema.Schema) error {
+	program, err := validations.New(g.compileOpts...).Compile(sch)
+	if err != nil {
+		return fmt.Errorf("%w: %w", err, ErrValidators)
+	}
+
+	if err := g.baseFS.MkdirAll(g.outputPath, userWritableOtherReadable); err != nil {
+		return fmt.Errorf("cannot create the output folder %q: %w: %w", g.outputPath, err, ErrValidators)
+	}
+
+	pkg := genPackage{
+		Program:  program,
+		Package:  g.packageName,
+		Location: g.locationOf(program),
+		Imports:  importsOf(program),
+	}
+
+	if err := g.generator.Render(validatorTemplate, validatorFile, pkg); err != nil {
+		return fmt.Errorf("cannot render %q: %w: %w", validatorFile, err, ErrValidators)
+	}
+
+	if err := g.generator.Render(supportTemplate, supportFile, pkg); err != nil {
+		return fmt.Errorf("cannot render %q: %w: %w", supportFile, err, ErrValidators)
+	}
+
+	return nil
+}
+
+func (g *Generator) locationOf(program validations.Program) string {
+	for _, function := range program.Functions {
+		if function.Name == program.Root {
+			return function.Location
+		}
+	}
+
+	return ""
+}
+
+// importsOf lists the standard packages imported by the validation code, which depend on the compiled checks.
+func importsOf(program validations.Program) []string {
+	imports := []string{"fmt"}
+	add := func(path string) {
+		if !slices.Contains(imports, path) {
+			imports = append(imports, path)
+		}
+	}
+
+	if len(program.Patterns) > 0 {
+		add("regexp")
+	}
+
+	for _, function := range program.Functions {
+		if len(function.Arrays) > 0 {
+			add("slices")
+		}
+
+		for _, checks := range [][]validations.Check{function.Numbers, function.Strings, function.Arrays, function.Applicators} {
+			for _, check := range checks {
+				switch check.Kind {
+				case validations.CheckMultipleOf:
+					add("math/big")
+				case validations.CheckMinLength, validations.CheckMaxLength:
+					add("unicode/utf8")
+				case validations.CheckItems:
+					if check.Rest != nil {
+						add("strconv")
+					}
+				case validations.CheckContains, validations.CheckUnevaluatedItems:
+					add("strconv")
+				default:
+				}
+			}
+		}
+	}
+
+	slices.Sort(imports)
+
+	return imports
+}
@@ -0,0 +1,446 @@
+package validators
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"text/template"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/genmodels/generators/golang-validators/internal/suiteformats"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/validator"
+)
+
+const (
+	// fixturesDir holds the official JSON-Schema-Test-Suite, by version
+	fixturesDir = "../../../fixtures/schemas"
+	remotesURL  = "http://localhost:1234/"
+
+	// casesDir holds test cases in the format of the test suite, for keywords introduced since draft 4
+	casesDir = "fixtures/cases"
+
+	// modulePath is the import path of this package, to import the packages generated for the test suite
+	modulePath = "github.com/fredbi/core/genmodels/generators/golang-validators"
+)
+
+// suites of test cases run against the generated validators, with the folder of the remote documents they refer to.
+//
+// Suites which are not vendored in the fixtures are reported as skipped.
+//
+//nolint:gochecknoglobals // test fixture
+var suites = []struct {
+	name    string
+	dir     string
+	remotes string
+	version jsonschema.Version
+}{
+	{
+		name:    "draft4",
+		dir:     filepath.Join(fixturesDir, "v4/jsonschema_suite"),
+		remotes: filepath.Join(fixturesDir, "v4/jsonschema_suite/remotes"),
+		version: jsonschema.VersionDraft4,
+	},
+	{
+		name:    "draft4/optional",
+		dir:     filepath.Join(fixturesDir, "v4/jsonschema_suite/optional"),
+		remotes: filepath.Join(fixturesDir, "v4/jsonschema_suite/remotes"),
+		version: jsonschema.VersionDraft4,
+	},
+	{
+		name:    "draft7",
+		dir:     filepath.Join(fixturesDir, "draft7/jsonschema_suite"),
+		remotes: filepath.Join(fixturesDir, "draft7/jsonschema_suite/remotes"),
+		version: jsonschema.VersionDraft7,
+	},
+	{
+		name:    "draft2019-09",
+		dir:     filepath.Join(fixturesDir, "draft2019-09/jsonschema_suite"),
+		remotes: filepath.Join(fixturesDir, "draft2019-09/jsonschema_suite/remotes"),
+		version: jsonschema.VersionDraft2019,
+	},
+	{
+		name:    "draft2020-12",
+		dir:     filepath.Join(fixturesDir, "draft2020-12/jsonschema_suite"),
+		remotes: filepath.Join(fixturesDir, "draft2020-12/jsonschema_suite/remotes"),
+		version: jsonschema.VersionDraft2020,
+	},
+	{
+		name:    "cases",
+		dir:     casesDir,
+		version: jsonschema.VersionDraft2020,
+	},
+}
+
+// reasons why cases of the test suite are skipped.
+const (
+	offlineDraft4         = "the draft 4 meta-schema is not available offline"
+	offlineDraft2020      = "the draft 2020-12 meta-schema is not available offline"
+	unsupportedDynamicRef = `"$dynamicRef" to a dynamic anchor is not supported`
+)
+
+// skipped cases of the test suite, by file and description of the case, with the reason why.
+//
+//nolint:gochecknoglobals // test fixture
+var skipped = map[string]string{
+	"draft4/definitions.json/valid definition":                                                                                                                                offlineDraft4,
+	"draft4/definitions.json/invalid definition":                                                                                                                              offlineDraft4,
+	"draft4/ref.json/remote ref, containing refs itself":                                                                                                                      offlineDraft4,
+	"draft4/optional/ecmascript-regex.json/ECMA 262 regex non-compliance":                                                                                                     "patterns are not ECMA 262 regular expressions",
+	"draft4/optional/zeroTerminatedFloats.json/some languages do not distinguish between different types of numeric value":                                                    "numbers with a zero fractional part are integers, as in later drafts",
+	"draft2020-12/defs.json/validate definition against metaschema":                                                                                                           offlineDraft2020,
+	"draft2020-12/ref.json/remote ref, containing refs itself":                                                                                                                offlineDraft2020,
+	"draft2020-12/dynamicRef.json/A $dynamicRef to a $dynamicAnchor in the same schema resource behaves like a normal $ref to an $anchor":                                     unsupportedDynamicRef,
+	"draft2020-12/dynamicRef.json/A $dynamicRef resolves to the first $dynamicAnchor still in scope that is encountered when the schema is evaluated":                         unsupportedDynamicRef,
+	"draft2020-12/dynamicRef.json/A $dynamicRef without anchor in fragment behaves identical to $ref":                                                                         unsupportedDynamicRef,
+	"draft2020-12/dynamicRef.json/A $dynamicRef with intermediate scopes that don't include a matching $dynamicAnchor does not affect dynamic scope resolution":               unsupportedDynamicRef,
+	"draft2020-12/dynamicRef.json/An $anchor with the same name as a $dynamicAnchor is not used for dynamic scope resolution":                                                 unsupportedDynamicRef,
+	"draft2020-12/dynamicRef.json/A $dynamicRef with a non-matching $dynamicAnchor in the same schema resource behaves like a normal $ref to $anchor":                         unsupportedDynamicRef,
+	"draft2020-12/dynamicRef.json/A $dynamicRef that initially resolves to a schema with a matching $dynamicAnchor resolves to the first $dynamicAnchor in the dynamic scope": unsupportedDynamicRef,
+	"draft2020-12/dynamicRef.json/multiple dynamic paths to the $dynamicRef keyword":                                                                                          unsupportedDynamicRef,
+	"draft2020-12/dynamicRef.json/after leaving a dynamic scope, it is not used by a $dynamicRef":                                                                             unsupportedDynamicRef,
+	"draft2020-12/dynamicRef.json/strict-tree schema, guards against misspelled properties":                                                                                   unsupportedDynamicRef,
+	"draft2020-12/dynamicRef.json/tests for implementation dynamic anchor and reference link":                                                                                 unsupportedDynamicRef,
+	"draft2020-12/dynamicRef.json/$ref and $dynamicAnchor are independent of order - $defs first":                                                                             unsupportedDynamicRef,
+	"draft2020-12/dynamicRef.json/$ref and $dynamicAnchor are independent of order - $ref first":                                                                              unsupportedDynamicRef,
+	"draft2020-12/dynamicRef.json/$ref to $dynamicRef finds detached $dynamicAnchor":                                                                                          unsupportedDynamicRef,
+	"draft2020-12/dynamicRef.json/$dynamicRef skips over intermediate resources - direct reference":                                                                           unsupportedDynamicRef,
+	"draft2020-12/unevaluatedItems.json/unevaluatedItems with $dynamicRef":                                                                                                    unsupportedDynamicRef,
+	"draft2020-12/unevaluatedProperties.json/unevaluatedProperties with $dynamicRef":                                                                                          unsupportedDynamicRef,
+}
+
+// suiteCase is a test case in the format of the JSON-Schema-Test-Suite.
+type suiteCase struct {
+	Description string             `json:"description"`
+	Schema      stdjson.RawMessage `json:"schema"`
+	Tests       []suiteTest        `json:"tests"`
+
+	// Package is the generated package that validates the schema of the case
+	Package string `json:"-"`
+}
+
+type suiteTest struct {
+	Description string             `json:"description"`
+	Data        stdjson.RawMessage `json:"data"`
+	Valid       bool               `json:"valid"`
+}
+
+// suiteFile is a file of the test suite.
+type suiteFile struct {
+	Name     string
+	Version  jsonschema.Version
+	Resolver *jsonschema.Resolver
+	Cases    []suiteCase
+}
+
+// suiteResult is the outcome of the validation of the data of a test by a generated package.
+type suiteResult struct {
+	Violations validator.Violations `json:"violations"`
+	Error      string               `json:"error"`
+}
+
+func TestSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the test suite builds the generated packages")
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("the test suite requires the go toolchain")
+	}
+
+	var suite []suiteFile
+	for _, spec := range suites {
+		if _, err := os.Stat(spec.dir); errors.Is(err, os.ErrNotExist) {
+			t.Run(spec.name, func(t *testing.T) {
+				t.Skipf("the test suite is not vendored in %s", spec.dir)
+			})
+
+			continue
+		}
+
+		var options []jsonschema.ResolverOption
+		if spec.remotes != "" {
+			options = append(options, jsonschema.WithOfflineMirror(remotesURL, spec.remotes), jsonschema.WithOfflineMode(true))
+		}
+
+		suite = append(suite, loadSuite(t, spec.name, spec.dir, spec.version, jsonschema.NewResolver(options...))...)
+	}
+
+	// the generated packages must belong to this module: directories starting with "_" are ignored by "./..."
+	dir, err := os.MkdirTemp(".", "_suite-")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	generateSuite(t, dir, suite)
+	results := runSuite(t, dir, suite)
+
+	for _, file := range suite {
+		for _, tc := range file.Cases {
+			if tc.Package == "" {
+				t.Run(file.Name+": "+tc.Description, func(t *testing.T) {
+					reason, _ := skipReason(file.Name, tc.Description)
+					t.Skip(reason)
+				})
+
+				continue
+			}
+
+			interpreted, err := validator.New(mustSchema(t, tc.Schema),
+				validator.WithResolver(file.Resolver),
+				validator.WithVersion(file.Version),
+				validator.WithFormats(suiteformats.Registry{}),
+			)
+			require.NoError(t, err)
+
+			for _, test := range tc.Tests {
+				result := results[0]
+				results = results[1:]
+
+				t.Run(file.Name+": "+tc.Description+": "+test.Description, func(t *testing.T) {
+					require.Empty(t, result.Error)
+					assert.Equal(t, test.Valid, len(result.Violations) == 0, "%v", result.Violations)
+
+					var expected validator.Violations
+					if err := interpreted.ValidateBytes(test.Data); err != nil {
+						require.True(t, errors.As(err, &expected))
+					}
+					assert.Equal(t, expected, result.Violations)
+				})
+			}
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("should generate a validator package", func(t *testing.T) {
+		memFS := afero.NewMemMapFs()
+		g := New(WithPackageName("person"), WithOutputPath("person"), WithOutputAferoFS(memFS))
+
+		require.NoError(t, g.Generate(mustSchema(t, []byte(`{
+			"type": "object",
+			"properties": {"name": {"type": "string", "pattern": "^[A-Z]"}, "age": {"minimum": 0}},
+			"required": ["name"]
+		}`))))
+
+		source, err := afero.ReadFile(memFS, "person/validator.go")
+		require.NoError(t, err)
+		assert.Contains(t, string(source), "package person")
+		assert.Contains(t, string(source), "func ValidateDocument(data json.Document) error")
+		assert.Contains(t, string(source), `pattern0 = regexp.MustCompile("^[A-Z]")`)
+
+		support, err := afero.ReadFile(memFS, "person/support.go")
+		require.NoError(t, err)
+		assert.Contains(t, string(support), "func SetFormats(registry registries.Registry)")
+	})
+
+	t.Run("should not generate unsupported schemas", func(t *testing.T) {
+		g := New(WithOutputAferoFS(afero.NewMemMapFs()))
+
+		err := g.Generate(mustSchema(t, []byte(`{"$dynamicAnchor": "node", "items": {"$dynamicRef": "#node"}}`)))
+		require.ErrorIs(t, err, ErrValidators)
+	})
+}
+
+// skipReason tells if a case of the test suite is skipped, and why.
+func skipReason(file, description string) (string, bool) {
+	reason, skip := skipped[file+"/"+description]
+
+	return reason, skip
+}
+
+func loadSuite(t *testing.T, name, dir string, version jsonschema.Version, resolver *jsonschema.Resolver) []suiteFile {
+	t.Helper()
+
+	names, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+
+	suite := make([]suiteFile, 0, len(names))
+	for _, pth := range names {
+		content, err := os.ReadFile(pth)
+		require.NoError(t, err)
+
+		file := suiteFile{Name: path.Join(name, filepath.Base(pth)), Version: version, Resolver: resolver}
+		require.NoError(t, stdjson.Unmarshal(content, &file.Cases))
+		suite = append(suite, file)
+	}
+
+	return suite
+}
+
+// generateSuite generates a validator package for every case of the test suite which is not skipped, and
+// a program that runs the tests with the generated packages.
+func generateSuite(t *testing.T, dir string, suite []suiteFile) {
+	t.Helper()
+
+	var packages []string
+	for i := range suite {
+		file := &suite[i]
+		for j := range file.Cases {
+			tc := &file.Cases[j]
+			if _, skip := skipReason(file.Name, tc.Description); skip {
+				continue
+			}
+
+			tc.Package = "case" + strconv.Itoa(len(packages))
+			packages = append(packages, tc.Package)
+
+			g := New(
+				WithPackageName(tc.Package),
+				WithOutputPath(filepath.Join(dir, tc.Package)),
+				WithResolver(file.Resolver),
+				WithDefaultVersion(file.Version),
+			)
+			require.NoErrorf(t, g.Generate(mustSchema(t, tc.Schema)), "%s: %s", file.Name, tc.Description)
+		}
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(t, runnerTemplate.Execute(&buffer, struct {
+		Module   string
+		Formats  string
+		Packages []string
+	}{
+		Module:   path.Join(modulePath, filepath.ToSlash(dir)),
+		Formats:  path.Join(modulePath, "internal/suiteformats"),
+		Packages: packages,
+	}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), buffer.Bytes(), 0o600))
+}
+
+// runSuite runs the tests with the generated packages, and yields their results in the order of the suite.
+func runSuite(t *testing.T, dir string, suite []suiteFile) []suiteResult {
+	t.Helper()
+
+	type input struct {
+		Package string             `json:"package"`
+		Data    stdjson.RawMessage `json:"data"`
+	}
+
+	var inputs []input
+	for _, file := range suite {
+		for _, tc := range file.Cases {
+			if tc.Package == "" {
+				continue
+			}
+
+			for _, test := range tc.Tests {
+				inputs = append(inputs, input{Package: tc.Package, Data: test.Data})
+			}
+		}
+	}
+
+	stdin, err := stdjson.Marshal(inputs)
+	require.NoError(t, err)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", "run", "./"+filepath.ToSlash(dir))
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	require.NoErrorf(t, cmd.Run(), "the generated packages should build: %s", stderr.String())
+
+	var results []suiteResult
+	require.NoError(t, stdjson.Unmarshal(stdout.Bytes(), &results))
+	require.Len(t, results, len(inputs))
+
+	return results
+}
+
+//nolint:gochecknoglobals // template of the program that runs the test suite with the generated packages
+var runnerTemplate = template.Must(template.New("runner").Parse(`// Code generated by TestSuite; DO NOT EDIT.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fredbi/core/jsonschema/validator"
+	"github.com/fredbi/core/strfmt/registries"
+	"{{ .Formats }}"
+{{- range .Packages }}
+	"{{ $.Module }}/{{ . }}"
+{{- end }}
+)
+
+type input struct {
+	Package string          ` + "`json:\"package\"`" + `
+	Data    json.RawMessage ` + "`json:\"data\"`" + `
+}
+
+type result struct {
+	Violations validator.Violations ` + "`json:\"violations\"`" + `
+	Error      string               ` + "`json:\"error\"`" + `
+}
+
+var validators = map[string]func([]byte) (validator.Violations, error){
+{{- range .Packages }}
+	"{{ . }}": func(data []byte) (validator.Violations, error) {
+		var violations {{ . }}.Violations
+		if err := {{ . }}.ValidateBytes(data); !errors.As(err, &violations) {
+			return nil, err
+		}
+
+		converted := make(validator.Violations, 0, len(violations))
+		for _, violation := range violations {
+			converted = append(converted, validator.Violation(violation))
+		}
+
+		return converted, nil
+	},
+{{- end }}
+}
+
+var setFormats = []func(registries.Registry){
+{{- range .Packages }}
+	{{ . }}.SetFormats,
+{{- end }}
+}
+
+func main() {
+	for _, set := range setFormats {
+		set(suiteformats.Registry{})
+	}
+
+	var inputs []input
+	if err := json.NewDecoder(os.Stdin).Decode(&inputs); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	results := make([]result, 0, len(inputs))
+	for _, in := range inputs {
+		violations, err := validators[in.Package](in.Data)
+		r := result{Violations: violations}
+		if err != nil {
+			r.Error = err.Error()
+		}
+		results = append(results, r)
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(results); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`))
+
+func mustSchema(t *testing.T, input []byte) jsonschema.Schema {
+	t.Helper()
+
+	s := jsonschema.Make()
+	require.NoError(t, s.UnmarshalJSON(input))
+
+	return s
+}
@@ -0,0 +1,134 @@
+// Package suiteformats provides a registry of the string formats asserted by the JSON-Schema-Test-Suite.
+//
+// It is shared by the tests of the generated validators and the program that runs them, so that "format"
+// is asserted the same way by the interpreted validator and the generated packages.
+package suiteformats
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fredbi/core/strfmt"
+)
+
+// Registry of formats which supports "date", "date-time", "email", "hostname", "ipv4", "ipv6" and "uri".
+type Registry struct{}
+
+// validators of the supported formats.
+//
+//nolint:gochecknoglobals // registry of formats
+var validators = map[string]func(string) error{
+	"date":      validateDate,
+	"date-time": validateDateTime,
+	"email":     validateEmail,
+	"hostname":  validateHostname,
+	"ipv4":      validateIPv4,
+	"ipv6":      validateIPv6,
+	"uri":       validateURI,
+}
+
+//nolint:gochecknoglobals // compiled once
+var hostnameLabel = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+func (Registry) Parse(string, string) (strfmt.Format, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (Registry) Validate(format string, value string) error {
+	validate, ok := validators[format]
+	if !ok {
+		return fmt.Errorf("unsupported format %q", format)
+	}
+
+	return validate(value)
+}
+
+func (Registry) SupportedFormats() []string {
+	return slices.Sorted(maps.Keys(validators))
+}
+
+func validateDate(value string) error {
+	_, err := time.Parse(time.DateOnly, value)
+
+	return err
+}
+
+func validateDateTime(value string) error {
+	_, err := time.Parse(time.RFC3339, value)
+
+	return err
+}
+
+func validateEmail(value string) error {
+	address, err := mail.ParseAddress(value)
+	if err != nil {
+		return err
+	}
+
+	if address.Address != value {
+		return fmt.Errorf("not a bare e-mail address: %q", value)
+	}
+
+	return nil
+}
+
+func validateHostname(value string) error {
+	if len(value) > 253 {
+		return fmt.Errorf("host name too long: %q", value)
+	}
+
+	for label := range strings.SplitSeq(strings.TrimSuffix(value, "."), ".") {
+		if !hostnameLabel.MatchString(label) {
+			return fmt.Errorf("invalid host name label %q", label)
+		}
+	}
+
+	return nil
+}
+
+func validateIPv4(value string) error {
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return err
+	}
+
+	if !addr.Is4() {
+		return fmt.Errorf("not an IPv4 address: %q", value)
+	}
+
+	return nil
+}
+
+func validateIPv6(value string) error {
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return err
+	}
+
+	if !addr.Is6() || addr.Zone() != "" {
+		return fmt.Errorf("not an IPv6 address: %q", value)
+	}
+
+	return nil
+}
+
+func validateURI(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+
+	if !u.IsAbs() {
+		return fmt.Errorf("not an absolute URI: %q", value)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package validators
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/spf13/afero"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/analyzers/validations"
+)
+
+//go:embed templates
+var embedFS embed.FS
+
+// Option to configure the generation of validators.
+type Option func(*options)
+
+type options struct {
+	packageName string
+	outputPath  string
+	baseFS      afero.Fs
+	skipFmt     bool
+	compileOpts []validations.Option
+	templatesFS fs.FS
+}
+
+func optionsWithDefaults(opts []Option) options {
+	o := options{
+		packageName: "validator",
+		outputPath:  ".",
+	}
+
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	if o.baseFS == nil {
+		o.baseFS = afero.NewOsFs()
+	}
+
+	if o.templatesFS == nil {
+		templatesFS, err := fs.Sub(embedFS, "templates")
+		if err != nil {
+			panic(err) // the embedded templates are always there
+		}
+		o.templatesFS = templatesFS
+	}
+
+	return o
+}
+
+// WithPackageName sets the name of the generated package.
+//
+// The default is "validator".
+func WithPackageName(name string) Option {
+	return func(o *options) {
+		o.packageName = name
+	}
+}
+
+// WithOutputPath sets the folder where the generated package is written.
+//
+// The default is the current folder.
+func WithOutputPath(path string) Option {
+	return func(o *options) {
+		o.outputPath = path
+	}
+}
+
+// WithOutputAferoFS specifies the output file system as an [afero.Fs].
+//
+// By default, the local os file system is used.
+// This is primarily intended for testing.
+func WithOutputAferoFS(fs afero.Fs) Option {
+	return func(o *options) {
+		o.baseFS = fs
+	}
+}
+
+// WithSkipFormat skips the formatting of the generated code (e.g. for debug).
+func WithSkipFormat(skipped bool) Option {
+	return func(o *options) {
+		o.skipFmt = skipped
+	}
+}
+
+// WithBaseURI sets the URI of the compiled schema, used to resolve its "$ref" s and reported by violations.
+//
+// See [validations.WithBaseURI].
+func WithBaseURI(uri string) Option {
+	return func(o *options) {
+		o.compileOpts = append(o.compileOpts, validations.WithBaseURI(uri))
+	}
+}
+
+// WithDefaultVersion sets the version of JSON schema for schemas that don't declare any.
+//
+// See [validations.WithDefaultVersion].
+func WithDefaultVersion(version jsonschema.Version) Option {
+	return func(o *options) {
+		o.compileOpts = append(o.compileOpts, validations.WithDefaultVersion(version))
+	}
+}
+
+// WithResolver sets the [jsonschema.Resolver] used to resolve "$ref" s to other documents.
+//
+// See [validations.WithResolver].
+func WithResolver(resolver *jsonschema.Resolver) Option {
+	return func(o *options) {
+		o.compileOpts = append(o.compileOpts, validations.WithResolver(resolver))
+	}
+}
@@ -0,0 +1,2 @@
+// Package templates contains text templates to generate go validators.
+package templates
@@ -8,6 +8,7 @@ require (
 	github.com/fredbi/core/json v0.0.0-00010101000000-000000000000
 	github.com/fredbi/core/jsonschema v0.0.0-00010101000000-000000000000
 	github.com/fredbi/core/mangling v0.0.0-20250515102727-3befc1aaa0d7
+	github.com/fredbi/core/strfmt v0.0.0-00010101000000-000000000000
 	github.com/fredbi/core/swag/loading v0.0.0-00010101000000-000000000000
 	github.com/spf13/afero v1.14.0
 	github.com/stretchr/testify v1.10.0
@@ -36,6 +37,7 @@ replace (
 	github.com/fredbi/core/json => ../json
 	github.com/fredbi/core/jsonschema => ../jsonschema
 	github.com/fredbi/core/mangling => ../mangling
+	github.com/fredbi/core/strfmt => ../strfmt
 	github.com/fredbi/core/swag/conv => ../swag/conv
 	github.com/fredbi/core/swag/fs => ../swag/fs
 	github.com/fredbi/core/swag/loading => ../swag/loading
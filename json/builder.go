@@ -1,6 +1,11 @@
 package json
 
 import (
+	"errors"
+	"slices"
+
+	"github.com/fredbi/core/json/nodes"
+	nodecodes "github.com/fredbi/core/json/nodes/error-codes"
 	"github.com/fredbi/core/json/nodes/light"
 	"github.com/fredbi/core/json/stores"
 	"github.com/fredbi/core/json/types"
@@ -216,20 +221,157 @@ func (b *Builder) Null() *Builder {
 
 // AtPointer replaces a value in a [Document] at [Pointer].
 //
+// The empty [Pointer] replaces the whole [Document].
+//
 // No replacement is made if the [Pointer] is not found.
 func (b *Builder) AtPointer(p Pointer, value Document) *Builder {
-	/* TODO
-	resolved, err := b.doc.setNodePointer(&b.doc.root, p, value)
-	if err != nil {
-		// silently ignores unresolved pointers
+	if !b.Ok() {
 		return b
 	}
-	*/
 
-	return b
+	replacement := b.own(value)
+	if !b.Ok() {
+		return b
+	}
+
+	return b.updatePointer(p, func(light.Node) (light.Node, error) {
+		return replacement, nil
+	})
 }
 
+// AtPointerMerge merges a value into a [Document] at [Pointer].
+//
+// Merging follows the rules of the "update" action of OpenAPI overlays:
+//
+//   - when both the target and the value are objects, members of the value are merged recursively into the target,
+//     and new members are appended to the target
+//   - when the target is an array, the value is appended to it as a new element.
+//     Nested arrays met while merging objects are concatenated
+//   - otherwise the target is replaced by the value
+//
+// No merge is made if the [Pointer] is not found.
 func (b *Builder) AtPointerMerge(p Pointer, value Document) *Builder {
-	// TODO
+	if !b.Ok() {
+		return b
+	}
+
+	update := b.own(value)
+	if !b.Ok() {
+		return b
+	}
+
+	return b.updatePointer(p, func(target light.Node) (light.Node, error) {
+		return b.mergeNodes(target, update, false)
+	})
+}
+
+// AtPointerRemove removes the value in a [Document] at [Pointer], i.e. an object member or an array element.
+//
+// No removal is made if the [Pointer] is not found or if it is the empty [Pointer].
+func (b *Builder) AtPointerRemove(p Pointer) *Builder {
+	if !b.Ok() || len(p) == 0 {
+		return b
+	}
+
+	last := p[len(p)-1]
+
+	return b.updatePointer(p[:len(p)-1], func(parent light.Node) (light.Node, error) {
+		bn := light.NewBuilder(b.Store()).From(parent)
+
+		switch {
+		case parent.Kind() == nodes.KindObject && last.kind&pathElemString != 0:
+			if _, ok := parent.AtInternedKey(last.s); !ok {
+				return parent, errPointerNoKey(last.s)
+			}
+
+			bn.RemoveKey(last.s.String())
+		case parent.Kind() == nodes.KindArray && last.kind&pathElemInt != 0:
+			if _, ok := parent.Elem(last.i); !ok {
+				return parent, errPointerNoIndex(last.i)
+			}
+
+			bn.RemoveElem(last.i)
+		default:
+			return parent, errPointerGotKey(last.s)
+		}
+
+		return bn.Node(), bn.Err()
+	})
+}
+
+// updatePointer applies an update to the node at [Pointer], silently ignoring unresolved pointers.
+func (b *Builder) updatePointer(p Pointer, update func(light.Node) (light.Node, error)) *Builder {
+	root, err := updateNodePointer(light.NewBuilder(b.Store()), b.doc.root, p, update)
+
+	switch {
+	case err == nil:
+		b.doc.root = root
+	case errors.Is(err, nodecodes.ErrBuilder):
+		b.SetErr(err)
+	}
+
 	return b
 }
+
+// mergeNodes merges a value node into a target node (see [Builder.AtPointerMerge]).
+func (b *Builder) mergeNodes(target, value light.Node, nested bool) (light.Node, error) {
+	bn := light.NewBuilder(b.Store())
+
+	switch {
+	case target.Kind() == nodes.KindArray && !nested:
+		bn.From(target).AppendElem(value)
+
+		return bn.Node(), bn.Err()
+	case target.Kind() == nodes.KindArray && value.Kind() == nodes.KindArray:
+		bn.From(target).AppendElems(slices.Collect(value.Elems())...)
+
+		return bn.Node(), bn.Err()
+	case target.Kind() != nodes.KindObject || value.Kind() != nodes.KindObject:
+		return value, nil
+	}
+
+	merged := target
+	for key, member := range value.Pairs() {
+		index, ok := merged.KeyIndex(key.String())
+		if !ok {
+			merged = bn.From(merged).AppendKey(key.String(), member).Node()
+
+			continue
+		}
+
+		current, _ := merged.AtInternedKey(key)
+		child, err := b.mergeNodes(current, member, true)
+		if err != nil {
+			return target, err
+		}
+
+		merged = bn.From(merged).ReplaceChild(index, child).Node()
+	}
+
+	return merged, bn.Err()
+}
+
+// own returns the root node of a [Document], which must share the [stores.Store] of the built [Document].
+//
+// Documents with another store are re-encoded with the store of the [Builder].
+func (b *Builder) own(value Document) light.Node {
+	if value.store == b.doc.store {
+		return value.root
+	}
+
+	data, err := value.MarshalJSON()
+	if err != nil {
+		b.SetErr(err)
+
+		return value.root
+	}
+
+	owned := Document{options: b.doc.options}
+	if err := owned.UnmarshalJSON(data); err != nil {
+		b.SetErr(err)
+
+		return value.root
+	}
+
+	return owned.root
+}
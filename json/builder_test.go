@@ -35,3 +35,106 @@ func TestBuilder(t *testing.T) {
 		assert.JSONEq(t, `{"test":[null,true,"abc",123.45]}`, w.String())
 	})
 }
+
+func TestBuilderAtPointer(t *testing.T) {
+	const original = `{"a": {"b": [1, 2], "c": "x"}, "d": [{"e": 1}]}`
+
+	for _, tc := range []struct {
+		name     string
+		build    func(*Builder, Document) *Builder
+		expected string
+	}{
+		{
+			name: "should replace a member",
+			build: func(b *Builder, value Document) *Builder {
+				return b.AtPointer(mustPointer(t, "/a/c"), value)
+			},
+			expected: `{"a": {"b": [1, 2], "c": {"new": true}}, "d": [{"e": 1}]}`,
+		},
+		{
+			name: "should replace an element",
+			build: func(b *Builder, value Document) *Builder {
+				return b.AtPointer(mustPointer(t, "/d/0"), value)
+			},
+			expected: `{"a": {"b": [1, 2], "c": "x"}, "d": [{"new": true}]}`,
+		},
+		{
+			name: "should replace the root",
+			build: func(b *Builder, value Document) *Builder {
+				return b.AtPointer(EmptyPointer, value)
+			},
+			expected: `{"new": true}`,
+		},
+		{
+			name: "should ignore unresolved pointers",
+			build: func(b *Builder, value Document) *Builder {
+				return b.AtPointer(mustPointer(t, "/a/x/y"), value).
+					AtPointerMerge(mustPointer(t, "/d/3"), value).
+					AtPointerRemove(mustPointer(t, "/z"))
+			},
+			expected: original,
+		},
+		{
+			name: "should merge into an object",
+			build: func(b *Builder, value Document) *Builder {
+				return b.AtPointerMerge(mustPointer(t, "/d/0"), value)
+			},
+			expected: `{"a": {"b": [1, 2], "c": "x"}, "d": [{"e": 1, "new": true}]}`,
+		},
+		{
+			name: "should append to an array",
+			build: func(b *Builder, value Document) *Builder {
+				return b.AtPointerMerge(mustPointer(t, "/d"), value)
+			},
+			expected: `{"a": {"b": [1, 2], "c": "x"}, "d": [{"e": 1}, {"new": true}]}`,
+		},
+		{
+			name: "should merge recursively",
+			build: func(b *Builder, _ Document) *Builder {
+				return b.AtPointerMerge(EmptyPointer, mustDocument(t, `{"a": {"b": [3], "c": "y"}, "f": null}`))
+			},
+			expected: `{"a": {"b": [1, 2, 3], "c": "y"}, "d": [{"e": 1}], "f": null}`,
+		},
+		{
+			name: "should remove a member and an element",
+			build: func(b *Builder, _ Document) *Builder {
+				return b.AtPointerRemove(mustPointer(t, "/a/c")).AtPointerRemove(mustPointer(t, "/a/b/0"))
+			},
+			expected: `{"a": {"b": [2]}, "d": [{"e": 1}]}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := mustDocument(t, original)
+			value := mustDocument(t, `{"new": true}`)
+
+			b := tc.build(NewBuilder(doc.Store()).From(doc), value)
+			require.NoError(t, b.Err())
+
+			actual, err := b.Document().MarshalJSON()
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(actual))
+
+			unaltered, err := doc.MarshalJSON()
+			require.NoError(t, err)
+			assert.JSONEq(t, original, string(unaltered))
+		})
+	}
+}
+
+func mustPointer(t *testing.T, s string) Pointer {
+	t.Helper()
+
+	p, err := MakePointer(s)
+	require.NoError(t, err)
+
+	return p
+}
+
+func mustDocument(t *testing.T, s string) Document {
+	t.Helper()
+
+	doc := Make()
+	require.NoError(t, doc.UnmarshalJSON([]byte(s)))
+
+	return doc
+}
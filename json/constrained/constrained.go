@@ -1,507 +1,90 @@
 package constrained
 
 import (
-	"fmt"
-	"io"
-
 	"github.com/fredbi/core/json"
-	"github.com/fredbi/core/json/lexers"
-	"github.com/fredbi/core/json/lexers/token"
-	"github.com/fredbi/core/json/nodes"
-	codes "github.com/fredbi/core/json/nodes/error-codes"
-	"github.com/fredbi/core/json/nodes/light"
 )
 
 // predefined constrained documents (e.g. used by jsonschema)
-// using hooks to customize light.Node.
 
-// MakeObject build an [Object].
-func MakeObject(opts ...json.Option) Object {
-	return Object{
-		Document: json.Make(opts...),
+//nolint:gochecknoglobals // declarations of constraints
+var (
+	objectConstraints = Constraints{
+		Root: KindObject,
 	}
-}
-
-type objectContext struct {
-	initialLevel int
-	isObject     bool
-}
-
-func (c *objectContext) Reset() {
-	c.initialLevel = 0
-	c.isObject = false
-}
-
-// Object is a [json.Document] constrained to be a JSON object.
-type Object struct {
-	json.Document
-}
-
-func (d *Object) Decode(r io.Reader) error {
-	lex, redeem := d.LexerFromReaderFactory()(r)
-	defer redeem()
-
-	return d.decode(lex)
-}
-
-func (d *Object) UnmarshalJSON(data []byte) error {
-	lex, redeem := d.LexerFactory()(data)
-	defer redeem()
-
-	return d.decode(lex)
-}
-
-func (d *Object) Hooks() light.DecodeOptions {
-	return d.hooks()
-}
-
-func (d *Object) hooks() light.DecodeOptions {
-	decodeOptions := d.DecodeOptions
-	decodeOptions.OnEnter = d.mustBeObject
 
-	return decodeOptions
-}
-
-func (d *Object) mustBeObject(
-	ctx *light.ParentContext,
-	l lexers.Lexer,
-	ev light.HookEvent,
-) (light.Action, error) {
-	octx, ok := ctx.X.(*objectContext)
-	if !ok {
-		return light.Continue, nil
+	arrayConstraints = Constraints{
+		Root: KindArray,
 	}
 
-	if octx.isObject {
-		return light.Continue, nil
+	stringOrArrayOfStringsConstraints = Constraints{
+		Root:  KindString | KindArray,
+		Elems: KindString,
 	}
 
-	level := l.IndentLevel() - octx.initialLevel
-
-	if level == 1 && ev.Token.IsStartObject() {
-		octx.isObject = true
-
-		return light.Continue, nil
+	boolOrObjectConstraints = Constraints{
+		Root: KindBool | KindObject,
 	}
 
-	return light.Continue, fmt.Errorf("a JSON object is expected. Got: %v: %w", ev.Token, codes.ErrNode)
-}
-
-func (d *Object) decode(lex lexers.Lexer) error {
-	context, redeemContext := light.BorrowParentContext()
-	context.L = lex
-	context.S = d.Store()
-	context.DO = d.hooks()
-	octx := poolOfObjectContexts.Borrow()
-	octx.initialLevel = lex.IndentLevel()
-	context.X = octx
-
-	n := d.Node()
-	n.Decode(context)
-
-	redeemContext()
-	poolOfObjectContexts.Redeem(octx)
-
-	return lex.Err()
-}
-
-func MakeArray(opts ...json.Option) Array {
-	return Array{
-		Document: json.Make(opts...),
+	objectOrArrayOfObjectsConstraints = Constraints{
+		Root:  KindObject | KindArray,
+		Elems: KindObject,
 	}
-}
-
-// Array is a [json.Document] constrained to be a JSON array.
-type Array struct {
-	json.Document
-}
-
-type arrayContext struct {
-	initialLevel int
-	isArray      bool
-}
-
-func (c *arrayContext) Reset() {
-	c.initialLevel = 0
-	c.isArray = false
-}
-
-func (d *Array) Decode(r io.Reader) error {
-	lex, redeem := d.LexerFromReaderFactory()(r)
-	defer redeem()
-
-	return d.decode(lex)
-}
-
-func (d *Array) UnmarshalJSON(data []byte) error {
-	lex, redeem := d.LexerFactory()(data)
-	defer redeem()
+)
 
-	return d.decode(lex)
-}
+type (
+	objectSpec                 struct{}
+	arraySpec                  struct{}
+	stringOrArrayOfStringsSpec struct{}
+	boolOrObjectSpec           struct{}
+	objectOrArrayOfObjectsSpec struct{}
+)
 
-func (d *Array) Hooks() light.DecodeOptions {
-	return d.hooks()
+func (objectSpec) Constraints() *Constraints { return &objectConstraints }
+func (arraySpec) Constraints() *Constraints  { return &arrayConstraints }
+func (stringOrArrayOfStringsSpec) Constraints() *Constraints {
+	return &stringOrArrayOfStringsConstraints
 }
-
-func (d *Array) hooks() light.DecodeOptions {
-	decodeOptions := d.DecodeOptions
-	decodeOptions.OnEnter = d.mustBeArray
-
-	return decodeOptions
+func (boolOrObjectSpec) Constraints() *Constraints { return &boolOrObjectConstraints }
+func (objectOrArrayOfObjectsSpec) Constraints() *Constraints {
+	return &objectOrArrayOfObjectsConstraints
 }
 
-func (d *Array) mustBeArray(
-	ctx *light.ParentContext,
-	l lexers.Lexer,
-	ev light.HookEvent,
-) (light.Action, error) {
-	octx, ok := ctx.X.(*arrayContext)
-	if !ok {
-		return light.Continue, nil
-	}
-
-	if octx.isArray {
-		return light.Continue, nil
-	}
-
-	level := l.IndentLevel() - octx.initialLevel
-	if level == 1 && ev.Token.IsStartArray() {
-		octx.isArray = true
-
-		return light.Continue, nil
-	}
+// Object is a [json.Document] constrained to be a JSON object.
+type Object = Document[objectSpec]
 
-	return light.Continue, fmt.Errorf("a JSON array is expected. Got: %v: %w", ev.Token, codes.ErrNode)
+// MakeObject build an [Object].
+func MakeObject(opts ...json.Option) Object {
+	return Make[objectSpec](opts...)
 }
 
-func (d *Array) decode(lex lexers.Lexer) error {
-	context, redeemContext := light.BorrowParentContext()
-	context.L = lex
-	context.S = d.Store()
-	context.DO = d.hooks()
-	octx := poolOfArrayContexts.Borrow()
-	octx.initialLevel = lex.IndentLevel()
-	context.X = octx
-
-	n := d.Node()
-	n.Decode(context)
-	redeemContext()
-	poolOfArrayContexts.Redeem(octx)
-
-	return lex.Err()
-}
+// Array is a [json.Document] constrained to be a JSON array.
+type Array = Document[arraySpec]
 
-func MakeStringOrArrayOfStrings(opts ...json.Option) StringOrArrayOfStrings {
-	return StringOrArrayOfStrings{
-		Document: json.Make(opts...),
-	}
+// MakeArray builds an [Array].
+func MakeArray(opts ...json.Option) Array {
+	return Make[arraySpec](opts...)
 }
 
 // StringOrArrayOfStrings is a [json.Document] constrained to be either a string or an array of strings.
-type StringOrArrayOfStrings struct {
-	json.Document
-}
-
-type stringOfArrayContext struct {
-	initialLevel             int
-	isStringOrArrayOfStrings bool
-}
-
-func (c *stringOfArrayContext) Reset() {
-	c.initialLevel = 0
-	c.isStringOrArrayOfStrings = false
-}
-
-func (d *StringOrArrayOfStrings) Decode(r io.Reader) error {
-	lex, redeem := d.LexerFromReaderFactory()(r)
-	defer redeem()
-
-	return d.decode(lex)
-}
-
-func (d *StringOrArrayOfStrings) UnmarshalJSON(data []byte) error {
-	lex, redeem := d.LexerFactory()(data)
-	defer redeem()
-
-	return d.decode(lex)
-}
-
-func (d *StringOrArrayOfStrings) Hooks() light.DecodeOptions {
-	return d.hooks()
-}
+type StringOrArrayOfStrings = Document[stringOrArrayOfStringsSpec]
 
-func (d *StringOrArrayOfStrings) hooks() light.DecodeOptions {
-	decodeOptions := d.DecodeOptions
-	decodeOptions.OnEnter = d.mustBeStringOrArrayOfStrings
-
-	return decodeOptions
-}
-
-func (d *StringOrArrayOfStrings) mustBeStringOrArrayOfStrings(
-	ctx *light.ParentContext,
-	l lexers.Lexer,
-	ev light.HookEvent,
-) (light.Action, error) {
-	octx, ok := ctx.X.(*stringOfArrayContext)
-	if !ok {
-		return light.Continue, nil
-	}
-	if octx.isStringOrArrayOfStrings {
-		return light.Continue, nil
-	}
-
-	level := l.IndentLevel() - octx.initialLevel
-	switch {
-	case level == 0 && ev.Token.Kind() == token.String:
-		fallthrough
-	case level == 0 && ev.Token.IsEndArray():
-		octx.isStringOrArrayOfStrings = true
-
-		return light.Continue, nil
-	case level == 1 && ev.Token.IsStartArray():
-		fallthrough
-	case level == 1 && (ev.Token.Kind() == token.String || ev.Token.IsComma()):
-		return light.Continue, nil
-	default:
-		return light.Continue, fmt.Errorf(
-			"a string or an array of strings is expected. Got: %v: %w",
-			ev.Token,
-			codes.ErrNode,
-		)
-	}
-}
-
-func (d *StringOrArrayOfStrings) decode(lex lexers.Lexer) error {
-	context, redeemContext := light.BorrowParentContext()
-	context.L = lex
-	context.S = d.Store()
-	context.DO = d.hooks()
-	octx := poolOfStringOrArrayContexts.Borrow()
-	octx.initialLevel = lex.IndentLevel()
-	context.X = octx
-
-	n := d.Node()
-	n.Decode(context)
-	redeemContext()
-	poolOfStringOrArrayContexts.Redeem(octx)
-
-	return lex.Err()
-}
-
-func MakeBoolOrObject(opts ...json.Option) BoolOrObject {
-	return BoolOrObject{
-		Document: json.Make(opts...),
-	}
+// MakeStringOrArrayOfStrings builds a [StringOrArrayOfStrings].
+func MakeStringOrArrayOfStrings(opts ...json.Option) StringOrArrayOfStrings {
+	return Make[stringOrArrayOfStringsSpec](opts...)
 }
 
 // BoolOrObject is a [json.Document] constrained to be either a boolean or an object.
-type BoolOrObject struct {
-	json.Document
-}
-
-type boolOrObjectContext struct {
-	initialLevel   int
-	isBoolOrObject bool
-}
+type BoolOrObject = Document[boolOrObjectSpec]
 
-func (c *boolOrObjectContext) Reset() {
-	c.initialLevel = 0
-	c.isBoolOrObject = false
-}
-
-func (d *BoolOrObject) Decode(r io.Reader) error {
-	lex, redeem := d.LexerFromReaderFactory()(r)
-	defer redeem()
-
-	return d.decode(lex)
-}
-
-func (d *BoolOrObject) UnmarshalJSON(data []byte) error {
-	lex, redeem := d.LexerFactory()(data)
-	defer redeem()
-
-	return d.decode(lex)
-}
-
-func (d *BoolOrObject) Hooks() light.DecodeOptions {
-	return d.hooks()
-}
-
-func (d *BoolOrObject) hooks() light.DecodeOptions {
-	decodeOptions := d.DecodeOptions
-	decodeOptions.OnEnter = d.mustBeBoolOrObject
-
-	return decodeOptions
-}
-
-func (d *BoolOrObject) mustBeBoolOrObject(
-	ctx *light.ParentContext,
-	l lexers.Lexer,
-	ev light.HookEvent,
-) (light.Action, error) {
-	boolOrObjectContext, ok := ctx.X.(*boolOrObjectContext)
-	if !ok {
-		return light.Continue, nil
-	}
-
-	if boolOrObjectContext.isBoolOrObject {
-		return light.Continue, nil
-	}
-	level := l.IndentLevel() - boolOrObjectContext.initialLevel
-
-	switch {
-	case level == 0 && ev.Token.Kind() == token.Boolean:
-		fallthrough
-	case level == 1 && ev.Token.IsStartObject():
-		boolOrObjectContext.isBoolOrObject = true
-
-		return light.Continue, nil
-	default:
-		return light.Continue, fmt.Errorf(
-			"a boolean or an object is expected. Got: %v: %w",
-			ev.Token,
-			codes.ErrNode,
-		)
-	}
+// MakeBoolOrObject builds a [BoolOrObject].
+func MakeBoolOrObject(opts ...json.Option) BoolOrObject {
+	return Make[boolOrObjectSpec](opts...)
 }
 
-func (d *BoolOrObject) decode(lex lexers.Lexer) error {
-	context, redeemContext := light.BorrowParentContext()
-	context.L = lex
-	context.S = d.Store()
-	context.DO = d.hooks()
-	octx := poolOfBoolOrObjectContexts.Borrow()
-	octx.initialLevel = lex.IndentLevel()
-	context.X = octx
-
-	n := d.Node()
-	n.Decode(context)
-	redeemContext()
-	poolOfBoolOrObjectContexts.Redeem(octx)
-
-	return lex.Err()
-}
+// ObjectOrArrayOfObjects is a [json.Document] constrained to be either an object or an array of objects.
+type ObjectOrArrayOfObjects = Document[objectOrArrayOfObjectsSpec]
 
+// MakeObjectOrArrayOfObjects builds an [ObjectOrArrayOfObjects].
 func MakeObjectOrArrayOfObjects(opts ...json.Option) ObjectOrArrayOfObjects {
-	return ObjectOrArrayOfObjects{
-		Document: json.Make(opts...),
-	}
-}
-
-// ObjectOrArray is a [json.Document] constrained to be either an object or an array of objects.
-type ObjectOrArrayOfObjects struct {
-	json.Document
-}
-
-type objectOrArrayOfObjectsContext struct {
-	initialLevel int
-	isObject     bool
-	isArray      bool
-}
-
-func (c *objectOrArrayOfObjectsContext) Reset() {
-	c.initialLevel = 0
-	c.isObject = false
-	c.isArray = false
-}
-
-func (d *ObjectOrArrayOfObjects) Decode(r io.Reader) error {
-	lex, redeem := d.LexerFromReaderFactory()(r)
-	defer redeem()
-
-	return d.decode(lex)
-}
-
-func (d *ObjectOrArrayOfObjects) UnmarshalJSON(data []byte) error {
-	lex, redeem := d.LexerFactory()(data)
-	defer redeem()
-
-	return d.decode(lex)
-}
-
-func (d *ObjectOrArrayOfObjects) Hooks() light.DecodeOptions {
-	return d.hooks()
-}
-
-func (d *ObjectOrArrayOfObjects) hooks() light.DecodeOptions {
-	decodeOptions := d.DecodeOptions
-	decodeOptions.OnEnter = d.mustBeObjectOrArrayOfObjects
-	decodeOptions.OnExit = d.elementMustBeObject
-
-	return decodeOptions
-}
-
-func (d *ObjectOrArrayOfObjects) mustBeObjectOrArrayOfObjects(
-	ctx *light.ParentContext,
-	l lexers.Lexer,
-	ev light.HookEvent,
-) (light.Action, error) {
-	octx, ok := ctx.X.(*objectOrArrayOfObjectsContext)
-	if !ok {
-		return light.Continue, nil
-	}
-	if octx.isObject || octx.isArray {
-		return light.Continue, nil
-	}
-
-	level := l.IndentLevel() - octx.initialLevel
-	switch {
-	case level == 0 && ev.Token.IsEndArray():
-		fallthrough
-	case level == 1 && ev.Token.IsStartArray():
-		octx.isArray = true
-	case level == 0 && ev.Token.IsEndObject():
-		fallthrough
-	case level == 1 && ev.Token.IsStartObject():
-		octx.isObject = true
-	case level == 0:
-		return light.Continue, fmt.Errorf(
-			"an object or an array of objects is expected. Got: %v: %w",
-			ev.Token,
-			codes.ErrNode,
-		)
-	default:
-	}
-
-	return light.Continue, nil
-}
-
-func (d *ObjectOrArrayOfObjects) elementMustBeObject(
-	_ *light.ParentContext,
-	_ lexers.Lexer,
-	ev light.HookEvent,
-) (light.Action, error) {
-	// OnExit fires for every value; restrict to the top array's direct elements (depth 1, no key).
-	// When the top is an object its members carry keys and are skipped here; deeper values too.
-	if ev.Depth != 1 || ev.HasKey() {
-		return light.Continue, nil
-	}
-
-	if ev.Node.Kind() == nodes.KindObject {
-		return light.Continue, nil
-	}
-
-	return light.Continue, fmt.Errorf(
-		"an object or an array of objects is expected. Got array element: %v: %w",
-		ev.Node.Kind(),
-		codes.ErrNode,
-	)
-}
-
-func (d *ObjectOrArrayOfObjects) decode(lex lexers.Lexer) error {
-	context, redeemContext := light.BorrowParentContext()
-	context.L = lex
-	context.S = d.Store()
-	context.DO = d.hooks()
-	octx := poolOfObjectOrArrayOfObjectsContexts.Borrow()
-	octx.initialLevel = lex.IndentLevel()
-	context.X = octx
-
-	n := d.Node()
-	n.Decode(context)
-	redeemContext()
-	poolOfObjectOrArrayOfObjectsContexts.Redeem(octx)
-
-	return lex.Err()
+	return Make[objectOrArrayOfObjectsSpec](opts...)
 }
@@ -0,0 +1,318 @@
+package constrained
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/fredbi/core/json/lexers"
+	"github.com/fredbi/core/json/lexers/token"
+	codes "github.com/fredbi/core/json/nodes/error-codes"
+	"github.com/fredbi/core/json/nodes/light"
+	"github.com/fredbi/core/json/stores"
+	"github.com/fredbi/core/json/stores/values"
+)
+
+// Kinds is a set of JSON value kinds, e.g. KindString|KindArray.
+//
+// Unlike [nodes.Kind], scalars are told apart: booleans, numbers and strings are distinct kinds.
+type Kinds uint8
+
+const (
+	KindNull Kinds = 1 << iota
+	KindBool
+	KindNumber
+	KindString
+	KindObject
+	KindArray
+
+	// KindScalar is any of a boolean, a number or a string.
+	KindScalar = KindBool | KindNumber | KindString
+	// KindAny is any kind of JSON value.
+	KindAny = KindNull | KindScalar | KindObject | KindArray
+)
+
+//nolint:gochecknoglobals
+var kindNames = [...]struct {
+	kind             Kinds
+	singular, plural string
+}{
+	{KindNull, "null", "nulls"},
+	{KindBool, "a boolean", "booleans"},
+	{KindNumber, "a number", "numbers"},
+	{KindString, "a string", "strings"},
+	{KindObject, "an object", "objects"},
+	{KindArray, "an array", "arrays"},
+}
+
+// Allows tells if all the kinds in k are allowed by this set.
+//
+// The empty set allows any kind.
+func (s Kinds) Allows(k Kinds) bool {
+	return s == 0 || k&^s == 0
+}
+
+// String describes the set of kinds, e.g. "a string or an array".
+func (s Kinds) String() string {
+	return s.describe(false, 0)
+}
+
+func (s Kinds) describe(plural bool, elems Kinds) string {
+	if s == 0 || s == KindAny {
+		if plural {
+			return "values"
+		}
+
+		return "any value"
+	}
+
+	parts := make([]string, 0, len(kindNames))
+	for _, name := range kindNames {
+		if s&name.kind == 0 {
+			continue
+		}
+
+		switch {
+		case plural:
+			parts = append(parts, name.plural)
+		case name.kind == KindArray && elems != 0 && elems != KindAny:
+			parts = append(parts, "an array of "+elems.describe(true, 0))
+		default:
+			parts = append(parts, name.singular)
+		}
+	}
+
+	return strings.Join(parts, " or ")
+}
+
+// kindsOfToken tells the kind of the value opened by a token.
+func kindsOfToken(tok token.T) Kinds {
+	switch {
+	case tok.IsStartObject():
+		return KindObject
+	case tok.IsStartArray():
+		return KindArray
+	case tok.IsNull():
+		return KindNull
+	case tok.IsBool():
+		return KindBool
+	case tok.Kind() == token.Number:
+		return KindNumber
+	case tok.Kind() == token.String:
+		return KindString
+	default:
+		return 0
+	}
+}
+
+// kindsOfNode tells the kind of a decoded value.
+func kindsOfNode(n light.Node, s stores.Store) Kinds {
+	switch {
+	case n.IsObject():
+		return KindObject
+	case n.IsArray():
+		return KindArray
+	case n.IsNull():
+		return KindNull
+	case n.IsBool(s):
+		return KindBool
+	case n.IsNumber(s):
+		return KindNumber
+	case n.IsString(s):
+		return KindString
+	default:
+		return 0
+	}
+}
+
+// Constraints declare the shape of a constrained JSON document.
+//
+// Constraints are checked while decoding, by the hooks returned by [Constraints.Hooks], or against an
+// already decoded node with [Constraints.Check]. The zero value does not constrain anything.
+//
+// Example: the "type" keyword of a JSON schema is a string or an array of strings.
+//
+//	var typeConstraints = constrained.Constraints{
+//		Root:  constrained.KindString | constrained.KindArray,
+//		Elems: constrained.KindString,
+//	}
+//
+// Constraints must not be altered once used.
+type Constraints struct {
+	// Root tells the allowed kinds for the document. The empty set allows any kind.
+	Root Kinds
+
+	// Elems tells the allowed kinds for the elements of a document which is an array.
+	// The empty set allows any kind.
+	Elems Kinds
+
+	// Keys tells the allowed kinds for some members of a document which is an object.
+	// Members with other keys are not constrained.
+	Keys map[string]Kinds
+
+	// Required lists the keys that a document which is an object must define.
+	Required []string
+
+	// MaxDepth is the maximum nesting depth of values in the document. Zero means unlimited.
+	//
+	// The document is at depth 0, the members or elements of the document at depth 1, etc.
+	MaxDepth int
+
+	// Description of the expected document, used in errors, e.g. "a JSON schema".
+	//
+	// The default is inferred from Root and Elems, e.g. "a string or an array of strings".
+	Description string
+
+	once     sync.Once
+	keys     map[values.InternedKey]Kinds
+	required []values.InternedKey
+}
+
+// Hooks returns decode options which check the constraints while decoding, then fire the hooks already
+// defined by the options, if any.
+func (c *Constraints) Hooks(o light.DecodeOptions) light.DecodeOptions {
+	o.OnEnter = c.OnEnter(o.OnEnter)
+	o.OnExit = c.OnExit(o.OnExit)
+
+	return o
+}
+
+// OnEnter returns a [light.Hook] which checks the kind of values and the nesting depth before they are
+// decoded, then calls next if not nil.
+func (c *Constraints) OnEnter(next light.Hook) light.Hook {
+	c.compile()
+
+	return func(ctx *light.ParentContext, l lexers.Lexer, ev light.HookEvent) (light.Action, error) {
+		if v := c.violation(ev.Depth, ev.Key, kindsOfToken(ev.Token)); v != "" {
+			return light.Continue, fmt.Errorf("%s. Got: %v: %w", v, ev.Token, codes.ErrNode)
+		}
+
+		if next == nil {
+			return light.Continue, nil
+		}
+
+		return next(ctx, l, ev)
+	}
+}
+
+// OnExit returns a [light.Hook] which checks the required keys once the document is decoded, then calls
+// next if not nil.
+func (c *Constraints) OnExit(next light.Hook) light.Hook {
+	c.compile()
+
+	return func(ctx *light.ParentContext, l lexers.Lexer, ev light.HookEvent) (light.Action, error) {
+		if ev.Depth == 0 {
+			if err := c.checkRequired(ev.Node); err != nil {
+				return light.Continue, err
+			}
+		}
+
+		if next == nil {
+			return light.Continue, nil
+		}
+
+		return next(ctx, l, ev)
+	}
+}
+
+// Check that a decoded node satisfies the constraints.
+func (c *Constraints) Check(n light.Node, s stores.Store) error {
+	c.compile()
+
+	return c.check(n, s, values.InternedKey{}, 0)
+}
+
+func (c *Constraints) check(n light.Node, s stores.Store, key values.InternedKey, depth int) error {
+	kind := kindsOfNode(n, s)
+	if v := c.violation(depth, key, kind); v != "" {
+		return fmt.Errorf("%s. Got: %v: %w", v, kind, codes.ErrNode)
+	}
+
+	// children are only visited when some constraint applies to them
+	descend := c.MaxDepth > 0 || (depth == 0 && (c.Elems != 0 || len(c.keys) > 0))
+
+	switch {
+	case !descend:
+	case n.IsObject():
+		for k, member := range n.Pairs() {
+			if err := c.check(member, s, k, depth+1); err != nil {
+				return err
+			}
+		}
+	case n.IsArray():
+		for elem := range n.Elems() {
+			if err := c.check(elem, s, values.InternedKey{}, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	if depth == 0 {
+		return c.checkRequired(n)
+	}
+
+	return nil
+}
+
+// violation describes how a value of the given kind, at some depth and key, violates the constraints.
+//
+// It returns an empty string if the value is valid.
+func (c *Constraints) violation(depth int, key values.InternedKey, kind Kinds) string {
+	switch {
+	case depth == 0:
+		if !c.Root.Allows(kind) {
+			return c.description() + " is expected"
+		}
+	case c.MaxDepth > 0 && depth > c.MaxDepth:
+		return fmt.Sprintf("%s is expected, with a maximum depth of %d", c.description(), c.MaxDepth)
+	case depth == 1 && key == values.InternedKey{}:
+		if !c.Elems.Allows(kind) {
+			return fmt.Sprintf("%s is expected. Array elements must be %s", c.description(), c.Elems.describe(true, 0))
+		}
+	case depth == 1:
+		if allowed, ok := c.keys[key]; ok && !allowed.Allows(kind) {
+			return fmt.Sprintf("%s is expected. Key %q must be %v", c.description(), key.String(), allowed)
+		}
+	}
+
+	return ""
+}
+
+func (c *Constraints) checkRequired(n light.Node) error {
+	if !n.IsObject() {
+		return nil
+	}
+
+	for _, key := range c.required {
+		if _, ok := n.AtInternedKey(key); !ok {
+			return fmt.Errorf("%s is expected. Missing required key %q: %w", c.description(), key.String(), codes.ErrNode)
+		}
+	}
+
+	return nil
+}
+
+func (c *Constraints) description() string {
+	if c.Description != "" {
+		return c.Description
+	}
+
+	return c.Root.describe(false, c.Elems)
+}
+
+// compile interns the declared keys.
+func (c *Constraints) compile() {
+	c.once.Do(func() {
+		if len(c.Keys) > 0 {
+			c.keys = make(map[values.InternedKey]Kinds, len(c.Keys))
+			for k, kinds := range c.Keys {
+				c.keys[values.MakeInternedKey(k)] = kinds
+			}
+		}
+
+		c.required = make([]values.InternedKey, 0, len(c.Required))
+		for _, k := range c.Required {
+			c.required = append(c.required, values.MakeInternedKey(k))
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package constrained
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/json/lexers"
+	codes "github.com/fredbi/core/json/nodes/error-codes"
+	"github.com/fredbi/core/json/nodes/light"
+)
+
+//nolint:gochecknoglobals
+var personConstraints = Constraints{
+	Root: KindObject,
+	Keys: map[string]Kinds{
+		"name":    KindString,
+		"age":     KindNumber,
+		"aliases": KindString | KindArray,
+	},
+	Required: []string{"name"},
+	MaxDepth: 2,
+}
+
+type personSpec struct{}
+
+func (personSpec) Constraints() *Constraints { return &personConstraints }
+
+func TestConstraints(t *testing.T) {
+	t.Run("with a declared constrained type", func(t *testing.T) {
+		for _, tc := range []struct {
+			input  string
+			errMsg string
+		}{
+			{input: `{"name":"x"}`},
+			{input: `{"name":"x","age":12,"aliases":["a","b"],"other":{"any":true}}`},
+			{input: `{"name":"x","aliases":"a"}`},
+			{input: `[]`, errMsg: "an object is expected"},
+			{input: `{"age":12}`, errMsg: `Missing required key "name"`},
+			{input: `{"name":12}`, errMsg: `Key "name" must be a string`},
+			{input: `{"name":"x","aliases":{}}`, errMsg: `Key "aliases" must be a string or an array`},
+			{input: `{"name":"x","other":{"nested":[1]}}`, errMsg: "maximum depth of 2"},
+		} {
+			t.Run(tc.input, func(t *testing.T) {
+				doc := Make[personSpec]()
+				err := doc.UnmarshalJSON([]byte(tc.input))
+
+				if tc.errMsg == "" {
+					require.NoError(t, err)
+					assertEncode(tc.input, &doc)(t)
+
+					return
+				}
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, codes.ErrNode)
+				assert.Contains(t, err.Error(), tc.errMsg)
+
+				t.Run("Check should report the same violation on a decoded document", func(t *testing.T) {
+					unconstrained := json.Make()
+					require.NoError(t, unconstrained.UnmarshalJSON([]byte(tc.input)))
+
+					err := personConstraints.Check(*unconstrained.Node(), unconstrained.Store())
+					require.ErrorIs(t, err, codes.ErrNode)
+					assert.Contains(t, err.Error(), tc.errMsg)
+				})
+			})
+		}
+	})
+
+	t.Run("should chain with other hooks", func(t *testing.T) {
+		var entered, exited int
+		doc := Make[personSpec]()
+		doc.OnEnter = func(*light.ParentContext, lexers.Lexer, light.HookEvent) (light.Action, error) {
+			entered++
+
+			return light.Continue, nil
+		}
+		doc.OnExit = func(*light.ParentContext, lexers.Lexer, light.HookEvent) (light.Action, error) {
+			exited++
+
+			return light.Continue, nil
+		}
+
+		require.NoError(t, doc.UnmarshalJSON([]byte(`{"name":"x","aliases":["a"]}`)))
+		assert.Equal(t, 4, entered)
+		assert.Equal(t, 4, exited)
+
+		entered, exited = 0, 0
+		require.Error(t, doc.UnmarshalJSON([]byte(`{"name":1}`)))
+		assert.Equal(t, 1, entered, "the hook should not be called after a violation")
+	})
+
+	t.Run("should describe kinds", func(t *testing.T) {
+		assert.Equal(t, "a string or an array", (KindString | KindArray).String())
+		assert.Equal(t, "any value", Kinds(0).String())
+		assert.Equal(t, "a boolean or an object", boolOrObjectConstraints.description())
+		assert.Equal(t, "a string or an array of strings", stringOrArrayOfStringsConstraints.description())
+		assert.Equal(t, "an object or an array of objects", objectOrArrayOfObjectsConstraints.description())
+	})
+
+	t.Run("should report invalid array elements", func(t *testing.T) {
+		doc := MakeStringOrArrayOfStrings()
+		err := doc.Decode(bytes.NewBufferString(`["a","b",3]`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "an array of strings is expected. Array elements must be strings")
+	})
+}
@@ -1,5 +1,9 @@
 // Package constrained exposes constrained [json.Document] types.
 //
 // The initial intent with this package was to provide a training & observation ground for
-// callback-based validations
+// callback-based validations.
+//
+// Constrained types are declared with [Constraints] (allowed kinds at the root, for array elements and
+// for members of an object, required keys, maximum depth), which drive the decode hooks of a single
+// generic implementation, [Document].
 package constrained
@@ -0,0 +1,75 @@
+package constrained
+
+import (
+	"io"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/json/lexers"
+	"github.com/fredbi/core/json/nodes/light"
+)
+
+// Spec declares the [Constraints] of a constrained [Document] type.
+//
+// A Spec is usually an empty struct type, e.g.:
+//
+//	type typeSpec struct{}
+//
+//	func (typeSpec) Constraints() *constrained.Constraints { return &typeConstraints }
+//
+//	type TypeKeyword = constrained.Document[typeSpec]
+type Spec interface {
+	Constraints() *Constraints
+}
+
+// Make a constrained [Document].
+func Make[S Spec](opts ...json.Option) Document[S] {
+	return Document[S]{
+		Document: json.Make(opts...),
+	}
+}
+
+// Document is a [json.Document] constrained by the [Constraints] declared by S.
+//
+// Decoding fails whenever the input does not satisfy the constraints.
+type Document[S Spec] struct {
+	json.Document
+}
+
+// Constraints that apply to this [Document].
+func (d Document[S]) Constraints() *Constraints {
+	var spec S
+
+	return spec.Constraints()
+}
+
+func (d *Document[S]) Decode(r io.Reader) error {
+	lex, redeem := d.LexerFromReaderFactory()(r)
+	defer redeem()
+
+	return d.decode(lex)
+}
+
+func (d *Document[S]) UnmarshalJSON(data []byte) error {
+	lex, redeem := d.LexerFactory()(data)
+	defer redeem()
+
+	return d.decode(lex)
+}
+
+// Hooks returns the decode options used to check the constraints of this [Document].
+func (d *Document[S]) Hooks() light.DecodeOptions {
+	return d.Constraints().Hooks(d.DecodeOptions)
+}
+
+func (d *Document[S]) decode(lex lexers.Lexer) error {
+	context, redeemContext := light.BorrowParentContext()
+	context.L = lex
+	context.S = d.Store()
+	context.DO = d.Hooks()
+
+	n := d.Node()
+	n.Decode(context)
+	redeemContext()
+
+	return lex.Err()
+}
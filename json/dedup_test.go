@@ -0,0 +1,42 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	store "github.com/fredbi/core/json/stores/default-store"
+)
+
+func TestDedupStore(t *testing.T) {
+	t.Run("should decode a document with repeated values", func(t *testing.T) {
+		s := store.NewDedup()
+		const doc = `[
+			{"$ref": "#/components/schemas/Pet", "description": "a pet in the store"},
+			{"$ref": "#/components/schemas/Pet", "description": "a pet in the store"},
+			{"$ref": "#/components/schemas/Pet", "description": "a pet in the store"}
+		]`
+
+		d := Make(WithStore(s))
+		require.NoError(t, d.UnmarshalJSON([]byte(doc)))
+
+		stats := s.Statistics()
+		assert.Equal(t, 2, stats.Unique)
+		assert.Equal(t, 4, stats.Hits)
+
+		for elem := range d.Elems() {
+			for key, member := range elem.Pairs() {
+				v, ok := member.Value()
+				require.True(t, ok)
+
+				switch key {
+				case "$ref":
+					assert.Equal(t, "#/components/schemas/Pet", v.String())
+				case "description":
+					assert.Equal(t, "a pet in the store", v.String())
+				}
+			}
+		}
+	})
+}
@@ -50,6 +50,9 @@ var EmptyDocument = Make()
 //
 // JSON pointers are supported within a [Document] using [Document.GetPointer].
 //
+// [Walk] visits all the nodes of a [Document] with pre-order and post-order callbacks,
+// and [Transform] rewrites a [Document] by replacing some of its nodes.
+//
 // An implementation of JSONPath is provided in [github.com/fredbi/core/json/documents.jsonpath] to resolve
 // JSONPath expressions as a [Document] iterator.
 //
@@ -262,7 +265,13 @@ func (d *Document) Decode(r io.Reader) error {
 }
 
 // UnmarshalJSON builds a [Document] from JSON bytes.
+//
+// Large top-level arrays may be decoded in parallel: see [WithParallelDecode].
 func (d *Document) UnmarshalJSON(data []byte) error {
+	if d.canDecodeParallel(data) {
+		return d.decodeParallel(data)
+	}
+
 	lex, redeem := d.lexerFactory(data)
 	defer redeem()
 
@@ -0,0 +1,124 @@
+package dynamic
+
+import (
+	"fmt"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/json/lexers/token"
+)
+
+// ToJSON converts a [json.Document] into a dynamic [JSON] data structure.
+//
+// Options tell how objects, arrays and numbers are represented, like when decoding (e.g. [WithOrderedMaps]).
+func ToJSON(d json.Document, opts ...Option) (JSON, error) {
+	j := Make(opts...)
+
+	inner, err := j.fromDocument(d)
+	if err != nil {
+		return Make(opts...), err
+	}
+	j.inner = inner
+
+	return j, nil
+}
+
+// ToDocument builds a [json.Document] from a dynamic [JSON] data structure,
+//
+// i.e. akin to what you get when the standard library unmarshals into a "any" type.
+//
+// Options apply to the produced [json.Document], e.g. to specify its [stores.Store].
+func ToDocument(value JSON, opts ...json.Option) (json.Document, error) {
+	data, err := value.MarshalJSON()
+	if err != nil {
+		return json.EmptyDocument, err
+	}
+
+	doc := json.Make(opts...)
+	if err := doc.UnmarshalJSON(data); err != nil {
+		return json.EmptyDocument, err
+	}
+
+	return doc, nil
+}
+
+func (d *JSON) fromDocument(doc json.Document) (any, error) {
+	switch {
+	case doc.IsObject():
+		return d.objectFromDocument(doc)
+	case doc.IsArray():
+		return d.arrayFromDocument(doc)
+	case doc.IsEmpty() || doc.IsNull():
+		return nil, nil
+	}
+
+	v, ok := doc.Value()
+	if !ok {
+		return nil, nil
+	}
+
+	switch v.Kind() {
+	case token.String:
+		return v.String(), nil
+	case token.Boolean:
+		return v.Bool(), nil
+	case token.Number:
+		return d.number(v.NumberValue().Value)
+	default:
+		return nil, fmt.Errorf("unexpected value of kind %v in document", v.Kind())
+	}
+}
+
+func (d *JSON) objectFromDocument(doc json.Document) (any, error) {
+	var (
+		obj any
+		set func(key string, value any)
+	)
+
+	switch {
+	case d.objectFactory != nil:
+		o := d.objectFactory()
+		set, obj = o.Set, o
+	case d.orderedMaps:
+		o := NewOrderedMap(doc.Len())
+		set, obj = o.Set, o
+	default:
+		o := make(map[string]any, doc.Len())
+		set, obj = func(key string, value any) { o[key] = value }, o
+	}
+
+	for key, member := range doc.Pairs() {
+		value, err := d.fromDocument(member)
+		if err != nil {
+			return nil, err
+		}
+		set(key, value)
+	}
+
+	return obj, nil
+}
+
+func (d *JSON) arrayFromDocument(doc json.Document) (any, error) {
+	if d.arrayFactory != nil {
+		arr := d.arrayFactory()
+		for elem := range doc.Elems() {
+			value, err := d.fromDocument(elem)
+			if err != nil {
+				return nil, err
+			}
+			arr.Append(value)
+		}
+
+		return arr, nil
+	}
+
+	arr := make([]any, 0, doc.Len())
+	for elem := range doc.Elems() {
+		value, err := d.fromDocument(elem)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, value)
+	}
+
+	return arr, nil
+}
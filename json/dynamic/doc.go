@@ -1,6 +1,6 @@
 // Package dynamic converts a [json.Document] to dynamic [JSON]
 // and vice versa.
 //
-// Dynamic [SON] holds the dynamic go data structure created
+// Dynamic [JSON] holds the dynamic go data structure created
 // when unmarshaling JSON into an untyped `interface{}` value.
 package dynamic
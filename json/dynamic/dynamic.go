@@ -1,68 +1,78 @@
 package dynamic
 
 import (
-	"errors"
+	"bytes"
 	"io"
 	"iter"
 	"slices"
 
-	"github.com/fredbi/core/json"
 	"github.com/fredbi/core/json/internal"
 	"github.com/fredbi/core/json/lexers"
 	codes "github.com/fredbi/core/json/lexers/error-codes"
 	"github.com/fredbi/core/json/lexers/token"
 	"github.com/fredbi/core/json/writers"
-	"github.com/fredbi/core/swag/conv"
 )
 
 // [JSON] holds the dynamic go data structure created
 // when unmarshaling JSON into an untyped `interface{}` value.
 //
-// The inner structure is built from a JSON string using map[string]any for objects,
-// []any for arrays, string for strings and float64 for numbers.
+// By default, the inner structure is built from a JSON string using map[string]any for objects,
+// []any for arrays, string for strings, bool for booleans, float64 for numbers and nil for null.
+//
+// Options may change the representation of objects (e.g. [WithOrderedMaps]), arrays and numbers (e.g. [WithNumbers]).
+// Encoding honors these representations.
 type JSON struct {
 	options
 	inner any
 }
 
-// Make a new [JSON] object.
-func Make(opts ...Option) JSON {
-	var inner any
+// Object is the interface for types holding JSON objects in a dynamic [JSON] structure,
+// such as [OrderedMap] or types produced by the factory set with [WithObjectFactory].
+type Object interface {
+	// Set the value for a key.
+	Set(key string, value any)
 
-	return JSON{
-		options: optionsWithDefaults(opts),
-		inner:   &inner,
-	}
+	// All iterates over the (key, value) pairs of the object, in the order they should be encoded.
+	All() iter.Seq2[string, any]
 }
 
-// TODO: data navigation methods (iterators) like for Document.
+// Array is the interface for types holding JSON arrays in a dynamic [JSON] structure,
+// such as the types produced by the factory set with [WithArrayFactory].
+type Array interface {
+	// Append a value.
+	Append(value any)
 
-// ToJSON converts a [json.Document] into a dynamic [JSON] data structure.
-func ToJSON(d json.Document, opts ...Option) JSON {
-	//	j := Make(opts...)
+	// All iterates over the (index, value) pairs of the array.
+	All() iter.Seq2[int, any]
+}
 
-	return JSON{} // TODO
+// Make a new [JSON] object.
+func Make(opts ...Option) JSON {
+	return JSON{
+		options: optionsWithDefaults(opts),
+	}
 }
 
-// ToDocument builds a [json.Document] from a dynamic [JSON] data structure,
+// From builds a [JSON] object holding a go value.
 //
-// i.e. akin to what you get when the standard library unmarshals into a "any" type.
-// TODO
-// Options: specify Store
-func ToDocument(value JSON, opts ...json.Option) (json.Document, error) {
-	// TODO b := json.NewBuilder()
+// The value is expected to be made of the types produced when decoding JSON (see [JSON]).
+// Other types cause an error when encoding.
+func From(value any, opts ...Option) JSON {
+	j := Make(opts...)
+	j.inner = value
 
-	return json.EmptyDocument, errors.New("not imlemented")
+	return j
 }
 
+// TODO: data navigation methods (iterators) like for Document.
+
 // Interface returns the inner untyped go structure (type "any").
 func (d JSON) Interface() any {
 	return d.inner
 }
 
 func (d *JSON) Reset() {
-	var inner any
-	d.inner = &inner
+	d.inner = nil
 }
 
 func (d *JSON) Decode(r io.Reader) error {
@@ -105,7 +115,8 @@ func (d JSON) MarshalJSON() ([]byte, error) {
 		return nil, err
 	}
 
-	return buf.Bytes(), nil
+	// the buffer is redeemed and may be recycled: the bytes must be copied
+	return bytes.Clone(buf.Bytes()), nil
 }
 
 func (d JSON) AppendText(b []byte) ([]byte, error) {
@@ -128,178 +139,150 @@ func (d JSON) AppendText(b []byte) ([]byte, error) {
 func (d JSON) encodeInner(jw writers.JSONWriter) error {
 	d.encode(jw)
 
+	if flusher, ok := jw.(writers.Flusher); ok {
+		if err := flusher.Flush(); err != nil {
+			return err
+		}
+	}
+
 	return jw.Err()
 }
 
 func (d *JSON) decode(l lexers.Lexer) any {
+	var value any
+
+	for l.Ok() {
+		tok := l.NextToken()
+		if !l.Ok() || tok.IsEOF() {
+			break
+		}
+
+		// the lexer reports any value after the first one as an error
+		value = d.decodeToken(l, tok)
+	}
+
 	if !l.Ok() {
 		return nil
 	}
 
-	for {
-		tok := l.NextToken()
-		if !l.Ok() {
+	return value
+}
+
+func (d *JSON) decodeToken(l lexers.Lexer, tok token.T) any {
+	switch {
+	case tok.IsStartObject():
+		return d.decodeObject(l)
+	case tok.IsStartArray():
+		return d.decodeArray(l)
+	case tok.IsNull():
+		return nil
+	case tok.IsBool():
+		return tok.Bool()
+	case tok.Kind() == token.String:
+		return string(tok.Value())
+	case tok.Kind() == token.Number:
+		n, err := d.number(tok.Value())
+		if err != nil {
+			l.SetErr(err)
+
 			return nil
 		}
 
-		switch {
-		case tok.IsStartObject():
-			node := make(map[string]any)
-			for key, value := range d.decodeObject(l) {
-				if !l.Ok() {
-					return nil
-				}
-				node[key] = value
-			}
+		return n
+	default:
+		l.SetErr(codes.ErrInvalidToken)
 
-			return node
+		return nil
+	}
+}
 
-		case tok.IsStartArray():
-			node := make([]any, 0, 10)
-			for elem := range d.decodeArray(l) {
-				node = append(node, elem)
-				if !l.Ok() {
-					return nil
-				}
-			}
-			slices.Clip(node)
+func (d *JSON) decodeObject(l lexers.Lexer) any {
+	switch {
+	case d.objectFactory != nil:
+		obj := d.objectFactory()
+		for key, value := range d.decodeMembers(l) {
+			obj.Set(key, value)
+		}
 
-			return node
+		return obj
+	case d.orderedMaps:
+		obj := NewOrderedMap(0)
+		for key, value := range d.decodeMembers(l) {
+			obj.Set(key, value)
+		}
 
-		case tok.IsNull():
-			return nil
-		case tok.IsBool():
-			return tok.Bool()
-		case tok.IsScalar():
-			switch tok.Kind() {
-			case token.String:
-				return string(tok.Value())
-			case token.Number:
-				// TODO: smarter number conversion
-				f, err := conv.ConvertFloat64(string(tok.Value()))
-				if err != nil {
-					l.SetErr(err)
-					return nil
-				}
-				return f
-			default:
-				l.SetErr(codes.ErrInvalidToken)
-				return nil
-			}
-		case tok.IsEOF():
-			return nil
-		default:
-			// wrong
-			l.SetErr(codes.ErrInvalidToken)
-			return nil
+		return obj
+	default:
+		obj := make(map[string]any)
+		for key, value := range d.decodeMembers(l) {
+			obj[key] = value
 		}
+
+		return obj
 	}
 }
 
-func (d *JSON) decodeObject(l lexers.Lexer) iter.Seq2[string, any] {
-	if !l.Ok() {
-		return nil
+func (d *JSON) decodeArray(l lexers.Lexer) any {
+	if d.arrayFactory != nil {
+		arr := d.arrayFactory()
+		for elem := range d.decodeElems(l) {
+			arr.Append(elem)
+		}
+
+		return arr
+	}
+
+	arr := make([]any, 0, 10) //nolint:mnd // initial capacity
+	for elem := range d.decodeElems(l) {
+		arr = append(arr, elem)
 	}
 
+	return slices.Clip(arr)
+}
+
+func (d *JSON) decodeMembers(l lexers.Lexer) iter.Seq2[string, any] {
 	return func(yield func(string, any) bool) {
-		for {
+		for l.Ok() {
 			tok := l.NextToken()
-			if !l.Ok() {
+			if !l.Ok() || tok.IsEndObject() {
 				return
 			}
 
-			if tok.IsEndObject() {
-				// empty object
-				return
-			}
-
-			if tok.IsKey() {
+			if !tok.IsKey() {
 				l.SetErr(codes.ErrMissingKey)
-				return
-			}
 
-			tok = l.NextToken() // skip the colon separator following the key
-			if !tok.IsColon() {
-				l.SetErr(codes.ErrKeyColon)
 				return
 			}
 
 			key := string(tok.Value())
 
-			v := Make()
-			v.decode(l)
+			// the ":" separator is elided by the lexer: the next token is the value
+			tok = l.NextToken()
 			if !l.Ok() {
 				return
 			}
-			value := v.Interface()
 
-			if !yield(key, value) {
+			value := d.decodeToken(l, tok)
+			if !l.Ok() || !yield(key, value) {
 				return
 			}
-
-			separator := l.NextToken()
-			if !l.Ok() {
-				return
-			}
-
-			if separator.IsComma() {
-				continue
-			}
-
-			if separator.IsEndObject() {
-				return
-			}
-
-			l.SetErr(codes.ErrInvalidToken)
-
-			return
 		}
 	}
 }
 
-func (d *JSON) decodeArray(l lexers.Lexer) iter.Seq[any] {
-	if !l.Ok() {
-		return nil
-	}
-
+func (d *JSON) decodeElems(l lexers.Lexer) iter.Seq[any] {
 	return func(yield func(any) bool) {
-		for {
+		for l.Ok() {
 			tok := l.NextToken()
-			if !l.Ok() {
-				return
-			}
-
-			if tok.IsEndArray() {
-				// empty object
-				return
-			}
-
-			v := Make()
-			v.decode(l)
-			if !l.Ok() {
-				return
-			}
-			elem := v.Interface()
-
-			if !yield(elem) {
+			if !l.Ok() || tok.IsEndArray() {
 				return
 			}
 
-			separator := l.NextToken()
-			if !l.Ok() {
+			// the "," separator is elided by the lexer
+			elem := d.decodeToken(l, tok)
+			if !l.Ok() || !yield(elem) {
 				return
 			}
-
-			if separator.IsComma() {
-				continue
-			}
-
-			if separator.IsEndArray() {
-				return
-			}
-
-			l.SetErr(codes.ErrMissingComma)
-			return
 		}
 	}
 }
@@ -0,0 +1,201 @@
+package dynamic
+
+import (
+	"bytes"
+	"iter"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/json/types"
+)
+
+const orderedFixture = `{"zeta":1,"alpha":{"y":[1,2.5,"x",true,null],"b":false},"big":123456789012345678901234567890.125,"m":{}}`
+
+func TestJSON(t *testing.T) {
+	t.Run("with default options", func(t *testing.T) {
+		j := Make()
+		require.NoError(t, j.UnmarshalJSON([]byte(`{"b":[1,"x",true,null],"a":{"c":1.5}}`)))
+
+		inner, ok := j.Interface().(map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, []any{float64(1), "x", true, nil}, inner["b"])
+		assert.Equal(t, map[string]any{"c": 1.5}, inner["a"])
+
+		t.Run("should encode with sorted keys", func(t *testing.T) {
+			data, err := j.MarshalJSON()
+			require.NoError(t, err)
+			assert.Equal(t, `{"a":{"c":1.5},"b":[1,"x",true,null]}`, string(data))
+		})
+	})
+
+	t.Run("should decode scalars", func(t *testing.T) {
+		for _, tc := range []struct {
+			input    string
+			expected any
+		}{
+			{input: `"x"`, expected: "x"},
+			{input: `12`, expected: float64(12)},
+			{input: `false`, expected: false},
+			{input: `null`, expected: nil},
+		} {
+			j := Make()
+			require.NoError(t, j.UnmarshalJSON([]byte(tc.input)))
+			assert.Equal(t, tc.expected, j.Interface())
+		}
+	})
+
+	t.Run("should report invalid JSON", func(t *testing.T) {
+		for _, invalid := range []string{`{"a":}`, `[1,,2]`, `{"a" 1}`, `[1] 2`, ``} {
+			j := Make()
+			require.Error(t, j.UnmarshalJSON([]byte(invalid)), invalid)
+			assert.Nil(t, j.Interface())
+		}
+	})
+
+	t.Run("with ordered maps and precise numbers", func(t *testing.T) {
+		for _, mode := range []NumberMode{NumberJSON, NumberString, NumberBigFloat} {
+			j := Make(WithOrderedMaps(true), WithNumbers(mode))
+			require.NoError(t, j.Decode(bytes.NewReader([]byte(orderedFixture))))
+
+			obj, ok := j.Interface().(*OrderedMap)
+			require.True(t, ok)
+			assert.Equal(t, []string{"zeta", "alpha", "big", "m"}, slices.Collect(obj.Keys()))
+
+			t.Run("should round-trip", func(t *testing.T) {
+				data, err := j.MarshalJSON()
+				require.NoError(t, err)
+				assert.Equal(t, orderedFixture, string(data))
+			})
+
+			num, ok := obj.Get("big")
+			require.True(t, ok)
+			switch mode {
+			case NumberJSON:
+				assert.Equal(t, types.Number{Value: []byte("123456789012345678901234567890.125")}, num)
+			case NumberString:
+				assert.Equal(t, Number("123456789012345678901234567890.125"), num)
+			case NumberBigFloat:
+				f, isFloat := num.(*big.Float)
+				require.True(t, isFloat)
+				assert.Equal(t, "123456789012345678901234567890.125", f.Text('f', -1))
+			}
+		}
+	})
+
+	t.Run("with caller factory types", func(t *testing.T) {
+		j := Make(
+			WithObjectFactory(func() Object { return &pairs{} }),
+			WithArrayFactory(func() Array { return &list{} }),
+			WithNumberFactory(func(n types.Number) (any, error) { return Number(n.String()), nil }),
+		)
+		require.NoError(t, j.UnmarshalJSON([]byte(`{"b":[1,{"x":2}],"a":3}`)))
+
+		obj, ok := j.Interface().(*pairs)
+		require.True(t, ok)
+		require.Len(t, obj.keys, 2)
+		assert.Equal(t, "b", obj.keys[0])
+		assert.IsType(t, &list{}, obj.values[0])
+
+		data, err := j.MarshalJSON()
+		require.NoError(t, err)
+		assert.Equal(t, `{"b":[1,{"x":2}],"a":3}`, string(data))
+	})
+
+	t.Run("should report unsupported types", func(t *testing.T) {
+		_, err := From(map[string]any{"a": struct{}{}}).MarshalJSON()
+		require.Error(t, err)
+	})
+}
+
+func TestConvert(t *testing.T) {
+	doc := json.Make()
+	require.NoError(t, doc.UnmarshalJSON([]byte(orderedFixture)))
+
+	t.Run("ToJSON should preserve order and numbers", func(t *testing.T) {
+		j, err := ToJSON(doc, WithOrderedMaps(true), WithNumbers(NumberString))
+		require.NoError(t, err)
+
+		data, err := j.MarshalJSON()
+		require.NoError(t, err)
+		assert.Equal(t, orderedFixture, string(data))
+	})
+
+	t.Run("ToJSON with default options", func(t *testing.T) {
+		j, err := ToJSON(doc)
+		require.NoError(t, err)
+
+		inner, ok := j.Interface().(map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, float64(1), inner["zeta"])
+	})
+
+	t.Run("ToDocument should round-trip", func(t *testing.T) {
+		j, err := ToJSON(doc, WithOrderedMaps(true), WithNumbers(NumberJSON))
+		require.NoError(t, err)
+
+		back, err := ToDocument(j)
+		require.NoError(t, err)
+
+		data, err := back.MarshalJSON()
+		require.NoError(t, err)
+		assert.Equal(t, orderedFixture, string(data))
+	})
+}
+
+func TestOrderedMap(t *testing.T) {
+	var m OrderedMap
+	m.Set("c", 1)
+	m.Set("a", 2)
+	m.Set("b", 3)
+	m.Set("a", 4)
+
+	assert.Equal(t, 3, m.Len())
+	assert.Equal(t, []string{"c", "a", "b"}, slices.Collect(m.Keys()))
+	assert.Equal(t, []any{1, 4, 3}, slices.Collect(m.Values()))
+
+	require.True(t, m.Delete("c"))
+	require.False(t, m.Delete("c"))
+	assert.False(t, m.Has("c"))
+
+	v, ok := m.Get("b")
+	require.True(t, ok)
+	assert.Equal(t, 3, v)
+
+	m.Set("c", 5)
+	assert.Equal(t, []string{"a", "b", "c"}, slices.Collect(m.Keys()))
+
+	var empty *OrderedMap
+	assert.Zero(t, empty.Len())
+	assert.Empty(t, slices.Collect(empty.Keys()))
+}
+
+type pairs struct {
+	keys   []string
+	values []any
+}
+
+func (p *pairs) Set(key string, value any) {
+	p.keys = append(p.keys, key)
+	p.values = append(p.values, value)
+}
+
+func (p *pairs) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for i, key := range p.keys {
+			if !yield(key, p.values[i]) {
+				return
+			}
+		}
+	}
+}
+
+type list []any
+
+func (l *list) Append(value any) { *l = append(*l, value) }
+
+func (l *list) All() iter.Seq2[int, any] { return slices.All(*l) }
@@ -1,123 +1,111 @@
 package dynamic
 
 import (
+	stdjson "encoding/json"
 	"fmt"
+	"maps"
 	"math/big"
+	"slices"
 
+	"github.com/fredbi/core/json/types"
 	"github.com/fredbi/core/json/writers"
 )
 
+// maxPlainExponent is the largest binary exponent of a *big.Float written without a decimal exponent (about 1e38)
+const maxPlainExponent = 128
+
 func (d *JSON) encode(w writers.JSONWriter) {
+	encodeValue(w, d.inner)
+}
+
+// encodeValue writes a dynamic go value as JSON.
+//
+// Keys of a map[string]any are written in lexicographic order, so the output is deterministic.
+func encodeValue(w writers.JSONWriter, value any) {
 	if !w.Ok() {
 		return
 	}
 
-	if d.inner == nil {
+	switch inner := value.(type) {
+	case nil:
 		w.Null()
-
-		return
-	}
-
-	switch inner := d.inner.(type) {
 	case map[string]any:
 		w.StartObject()
-		if !w.Ok() {
-			return
-		}
-
-		l := len(inner)
-
-		if l == 0 {
-			w.EndObject()
-
-			return
-		}
-
-		i := 0
-		for key, value := range inner {
+		for i, key := range slices.Sorted(maps.Keys(inner)) {
+			if i > 0 {
+				w.Comma()
+			}
 			w.String(key)
-
-			v := JSON{inner: value}
-			v.encode(w)
-			if i < l {
+			w.Colon()
+			encodeValue(w, inner[key])
+		}
+		w.EndObject()
+	case Object:
+		w.StartObject()
+		var i int
+		for key, elem := range inner.All() {
+			if i > 0 {
 				w.Comma()
 			}
+			w.String(key)
+			w.Colon()
+			encodeValue(w, elem)
 			i++
 		}
-
 		w.EndObject()
-
-		return
-
 	case []any:
 		w.StartArray()
-		if !w.Ok() {
-			return
-		}
-
-		l := len(inner)
-		if l == 0 {
-			w.EndArray()
-
-			return
+		for i, elem := range inner {
+			if i > 0 {
+				w.Comma()
+			}
+			encodeValue(w, elem)
 		}
-
-		v0 := JSON{inner: inner[0]}
-		v0.encode(w)
-
-		for _, elem := range inner[1:] {
-			w.Comma()
-			v := JSON{inner: elem}
-			v.encode(w)
+		w.EndArray()
+	case Array:
+		w.StartArray()
+		for i, elem := range inner.All() {
+			if i > 0 {
+				w.Comma()
+			}
+			encodeValue(w, elem)
 		}
-
 		w.EndArray()
-
-		return
-
 	case string:
 		w.String(inner)
 	case bool:
 		w.Bool(inner)
-	case float64:
-		w.Number(inner)
-	case float32:
-		w.Number(inner)
-	case int8:
-		w.Number(inner)
-	case int16:
-		w.Number(inner)
-	case int32:
-		w.Number(inner)
-	case int64:
-		w.Number(inner)
-	case int:
-		w.Number(inner)
-	case uint8:
-		w.Number(inner)
-	case uint16:
-		w.Number(inner)
-	case uint32:
-		w.Number(inner)
-	case uint64:
-		w.Number(inner)
-	case uint:
-		w.Number(inner)
-	case *big.Int:
-		w.Number(inner)
-	case big.Int:
-		w.Number(inner)
-	case *big.Rat:
-		w.Number(inner)
-	case big.Rat:
-		w.Number(inner)
+	case types.Number:
+		w.JSONNumber(inner)
+	case Number:
+		w.NumberBytes([]byte(inner))
+	case stdjson.Number:
+		w.NumberBytes([]byte(inner))
 	case *big.Float:
+		if inner == nil {
+			w.Null()
+
+			return
+		}
+		// the shortest decimal representation that round-trips at the precision of the value,
+		// with an exponent only for very large or very small values
+		format := byte('f')
+		if exp := inner.MantExp(nil); exp > maxPlainExponent || exp < -maxPlainExponent {
+			format = 'g'
+		}
+		w.NumberBytes(inner.Append(nil, format, -1))
+	case float64, float32, int8, int16, int32, int64, int, uint8, uint16, uint32, uint64, uint,
+		*big.Int, big.Int, *big.Rat, big.Rat, big.Float:
 		w.Number(inner)
-	case big.Float:
-		w.Number(inner)
-	// TODO: json types, strformat types, ordered map
+	case stdjson.Marshaler:
+		b, err := inner.MarshalJSON()
+		if err != nil {
+			w.SetErr(err)
+
+			return
+		}
+		w.Raw(b)
 	default:
-		w.SetErr(fmt.Errorf("invalid dynamic JSON type: %T", d.inner))
-		return
+		w.SetErr(fmt.Errorf("invalid dynamic JSON type: %T", value))
 	}
 }
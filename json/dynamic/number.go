@@ -0,0 +1,72 @@
+package dynamic
+
+import (
+	"bytes"
+	"math/big"
+	"strconv"
+
+	"github.com/fredbi/core/json/types"
+	"github.com/fredbi/core/swag/conv"
+)
+
+// NumberMode tells how JSON numbers are represented in a dynamic [JSON] structure.
+type NumberMode uint8
+
+const (
+	// NumberFloat64 represents numbers as float64 values, like the standard library does.
+	//
+	// This is the default. Numbers with more digits than a float64 can hold are rounded.
+	NumberFloat64 NumberMode = iota
+
+	// NumberJSON keeps numbers as [types.Number] values, holding the original JSON literal.
+	NumberJSON
+
+	// NumberString keeps numbers as [Number] values, akin to the json.Number of the standard library.
+	NumberString
+
+	// NumberBigFloat represents numbers as *big.Float values, with a precision large enough to hold all
+	// the digits of the original JSON literal.
+	NumberBigFloat
+)
+
+// Number is a JSON number kept as its literal string, akin to the json.Number of the standard library.
+type Number string
+
+// String returns the literal text of the number.
+func (n Number) String() string {
+	return string(n)
+}
+
+// Float64 returns the number as a float64.
+func (n Number) Float64() (float64, error) {
+	return strconv.ParseFloat(string(n), 64)
+}
+
+// Int64 returns the number as an int64.
+func (n Number) Int64() (int64, error) {
+	return strconv.ParseInt(string(n), 10, 64)
+}
+
+// number converts a JSON number literal to its dynamic representation.
+//
+// The literal may be a transient buffer: it must be copied if retained.
+func (o options) number(literal []byte) (any, error) {
+	if o.numberFactory != nil {
+		return o.numberFactory(types.Number{Value: bytes.Clone(literal)})
+	}
+
+	switch o.numberMode {
+	case NumberJSON:
+		return types.Number{Value: bytes.Clone(literal)}, nil
+	case NumberString:
+		return Number(literal), nil
+	case NumberBigFloat:
+		// about 3.32 bits per decimal digit: 4 bits per byte of the literal is enough to keep all digits
+		prec := max(uint(len(literal))*4, 64) //nolint:gosec // the length of a literal is positive
+		f, _, err := big.ParseFloat(string(literal), 10, prec, big.ToNearestEven)
+
+		return f, err
+	default:
+		return conv.ConvertFloat64(string(literal))
+	}
+}
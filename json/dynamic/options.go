@@ -5,17 +5,23 @@ import (
 
 	"github.com/fredbi/core/json/lexers"
 	lexer "github.com/fredbi/core/json/lexers/default-lexer"
+	"github.com/fredbi/core/json/types"
 	"github.com/fredbi/core/json/writers"
 	writer "github.com/fredbi/core/json/writers/default-writer"
 )
 
 type Option func(*options)
 
-// TODO: options: support other numeric types than float64, use OrderedMap instead of map
 type options struct {
 	lexerFactory           func([]byte) (lexers.Lexer, func())
 	lexerFromReaderFactory func(io.Reader) (lexers.Lexer, func())
 	writerToWriterFactory  func(io.Writer) (writers.JSONWriter, func())
+
+	orderedMaps   bool
+	numberMode    NumberMode
+	objectFactory func() Object
+	arrayFactory  func() Array
+	numberFactory func(types.Number) (any, error)
 }
 
 func defaultLexerFactory(data []byte) (lexers.Lexer, func()) {
@@ -81,3 +87,46 @@ func WithWriter(w writers.JSONWriter) Option {
 		}
 	}
 }
+
+// WithOrderedMaps decodes JSON objects as [*OrderedMap] values rather than map[string]any,
+// so keys are kept in the order of the original JSON document.
+func WithOrderedMaps(enabled bool) Option {
+	return func(o *options) {
+		o.orderedMaps = enabled
+	}
+}
+
+// WithNumbers tells how JSON numbers are decoded. The default is [NumberFloat64].
+func WithNumbers(mode NumberMode) Option {
+	return func(o *options) {
+		o.numberMode = mode
+	}
+}
+
+// WithObjectFactory decodes JSON objects as the [Object] values produced by factory.
+//
+// This takes precedence over [WithOrderedMaps].
+func WithObjectFactory(factory func() Object) Option {
+	return func(o *options) {
+		o.objectFactory = factory
+	}
+}
+
+// WithArrayFactory decodes JSON arrays as the [Array] values produced by factory, rather than []any.
+func WithArrayFactory(factory func() Array) Option {
+	return func(o *options) {
+		o.arrayFactory = factory
+	}
+}
+
+// WithNumberFactory decodes JSON numbers as the values produced by factory.
+//
+// This takes precedence over [WithNumbers].
+//
+// The values produced should either be native go numerical types, types from math/big,
+// or implement the [encoding/json.Marshaler] interface to be encoded back.
+func WithNumberFactory(factory func(types.Number) (any, error)) Option {
+	return func(o *options) {
+		o.numberFactory = factory
+	}
+}
@@ -0,0 +1,139 @@
+package dynamic
+
+import (
+	"iter"
+	"slices"
+)
+
+var _ Object = &OrderedMap{}
+
+// OrderedMap is a map with string keys, which remembers the order in which keys are set.
+//
+// This is how objects are decoded when [WithOrderedMaps] is enabled: iterating over an [OrderedMap] yields
+// the keys in the order of the original JSON document, and lookups by key are O(1).
+//
+// Setting an existing key replaces its value and keeps its position. Deleting a key is O(n).
+//
+// The zero value is an empty map ready to use.
+type OrderedMap struct {
+	keys   []string
+	values []any
+	index  map[string]int
+}
+
+// NewOrderedMap builds an empty [OrderedMap] with some initial capacity.
+func NewOrderedMap(capacity int) *OrderedMap {
+	return &OrderedMap{
+		keys:   make([]string, 0, capacity),
+		values: make([]any, 0, capacity),
+		index:  make(map[string]int, capacity),
+	}
+}
+
+// Len yields the number of keys in the map.
+func (m *OrderedMap) Len() int {
+	if m == nil {
+		return 0
+	}
+
+	return len(m.keys)
+}
+
+// Get the value for a key.
+func (m *OrderedMap) Get(key string) (any, bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	i, ok := m.index[key]
+	if !ok {
+		return nil, false
+	}
+
+	return m.values[i], true
+}
+
+// Has tells if a key is set.
+func (m *OrderedMap) Has(key string) bool {
+	if m == nil {
+		return false
+	}
+
+	_, ok := m.index[key]
+
+	return ok
+}
+
+// Set the value for a key.
+//
+// A new key is appended after all other keys.
+func (m *OrderedMap) Set(key string, value any) {
+	if i, ok := m.index[key]; ok {
+		m.values[i] = value
+
+		return
+	}
+
+	if m.index == nil {
+		m.index = make(map[string]int)
+	}
+
+	m.index[key] = len(m.keys)
+	m.keys = append(m.keys, key)
+	m.values = append(m.values, value)
+}
+
+// Delete a key, and reports whether the key was present.
+func (m *OrderedMap) Delete(key string) bool {
+	if m == nil {
+		return false
+	}
+
+	i, ok := m.index[key]
+	if !ok {
+		return false
+	}
+
+	delete(m.index, key)
+	m.keys = slices.Delete(m.keys, i, i+1)
+	m.values = slices.Delete(m.values, i, i+1)
+
+	for j := i; j < len(m.keys); j++ {
+		m.index[m.keys[j]] = j
+	}
+
+	return true
+}
+
+// Keys iterates over the keys of the map, in order.
+func (m *OrderedMap) Keys() iter.Seq[string] {
+	if m == nil {
+		return func(func(string) bool) {}
+	}
+
+	return slices.Values(m.keys)
+}
+
+// Values iterates over the values of the map, in the order of keys.
+func (m *OrderedMap) Values() iter.Seq[any] {
+	if m == nil {
+		return func(func(any) bool) {}
+	}
+
+	return slices.Values(m.values)
+}
+
+// All iterates over the (key, value) pairs of the map, in order.
+func (m *OrderedMap) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		if m == nil {
+			return
+		}
+
+		for i, key := range m.keys {
+			if !yield(key, m.values[i]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package jsonpath
+
+// Error is an error raised by this package.
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrJSONPath is raised when parsing an invalid JSONPath expression.
+	ErrJSONPath Error = "invalid JSONPath expression"
+
+	// ErrUnsupported is raised when parsing a JSONPath expression with features that are not supported yet.
+	ErrUnsupported Error = "unsupported JSONPath feature"
+)
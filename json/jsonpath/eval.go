@@ -0,0 +1,266 @@
+package jsonpath
+
+import (
+	"iter"
+	"maps"
+	"slices"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/json/dynamic"
+)
+
+// navigator knows how to explore the children of nodes of type T.
+type navigator[T any] interface {
+	member(node T, key string) (T, bool)
+	members(node T) iter.Seq2[string, T]
+	elems(node T) ([]T, bool)
+}
+
+// located is a node matched by a JSONPath expression, with its location in the root node.
+type located[T any] struct {
+	path  []any // string keys or int indices
+	value T
+}
+
+// evaluate the segments of a JSONPath expression against a root node.
+//
+// Nodes are yielded in document order, as specified by RFC 9535, with possible duplicates.
+func evaluate[T any](nav navigator[T], root T, segments []segment) iter.Seq[located[T]] {
+	return func(yield func(located[T]) bool) {
+		current := []located[T]{{value: root}}
+
+		for _, seg := range segments {
+			var next []located[T]
+			for _, node := range current {
+				if seg.descendant {
+					next = appendDescendants(nav, next, node, seg.selectors)
+
+					continue
+				}
+
+				next = appendSelected(nav, next, node, seg.selectors)
+			}
+
+			current = next
+			if len(current) == 0 {
+				return
+			}
+		}
+
+		for _, node := range current {
+			if !yield(node) {
+				return
+			}
+		}
+	}
+}
+
+// appendDescendants applies selectors to a node, then to all its descendants.
+func appendDescendants[T any](nav navigator[T], matched []located[T], node located[T], selectors []selector) []located[T] {
+	matched = appendSelected(nav, matched, node, selectors)
+
+	for child := range children(nav, node) {
+		matched = appendDescendants(nav, matched, child, selectors)
+	}
+
+	return matched
+}
+
+func appendSelected[T any](nav navigator[T], matched []located[T], node located[T], selectors []selector) []located[T] {
+	for _, sel := range selectors {
+		switch sel.kind {
+		case selectName:
+			if child, ok := nav.member(node.value, sel.name); ok {
+				matched = append(matched, node.child(sel.name, child))
+			}
+		case selectWildcard:
+			for child := range children(nav, node) {
+				matched = append(matched, child)
+			}
+		case selectIndex:
+			elems, ok := nav.elems(node.value)
+			if !ok {
+				continue
+			}
+
+			index := sel.index
+			if index < 0 {
+				index += len(elems)
+			}
+
+			if index >= 0 && index < len(elems) {
+				matched = append(matched, node.child(index, elems[index]))
+			}
+		case selectSlice:
+			elems, ok := nav.elems(node.value)
+			if !ok {
+				continue
+			}
+
+			for index := range sel.indices(len(elems)) {
+				matched = append(matched, node.child(index, elems[index]))
+			}
+		}
+	}
+
+	return matched
+}
+
+func children[T any](nav navigator[T], node located[T]) iter.Seq[located[T]] {
+	return func(yield func(located[T]) bool) {
+		if elems, ok := nav.elems(node.value); ok {
+			for index, elem := range elems {
+				if !yield(node.child(index, elem)) {
+					return
+				}
+			}
+
+			return
+		}
+
+		for key, value := range nav.members(node.value) {
+			if !yield(node.child(key, value)) {
+				return
+			}
+		}
+	}
+}
+
+func (l located[T]) child(token any, value T) located[T] {
+	path := make([]any, len(l.path)+1)
+	copy(path, l.path)
+	path[len(l.path)] = token
+
+	return located[T]{path: path, value: value}
+}
+
+// indices selected by a slice selector for an array of a given length, as specified by RFC 9535.
+func (s selector) indices(length int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if s.step == 0 {
+			return
+		}
+
+		normalize := func(i int) int {
+			if i < 0 {
+				return i + length
+			}
+
+			return i
+		}
+
+		if s.step > 0 {
+			start, end := 0, length
+			if s.hasStart {
+				start = min(max(normalize(s.start), 0), length)
+			}
+			if s.hasEnd {
+				end = min(max(normalize(s.end), 0), length)
+			}
+
+			for i := start; i < end; i += s.step {
+				if !yield(i) {
+					return
+				}
+			}
+
+			return
+		}
+
+		start, end := length-1, -1
+		if s.hasStart {
+			start = min(max(normalize(s.start), -1), length-1)
+		}
+		if s.hasEnd {
+			end = min(max(normalize(s.end), -1), length-1)
+		}
+
+		for i := start; i > end; i += s.step {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// documentNavigator explores [json.Document] s.
+type documentNavigator struct{}
+
+func (documentNavigator) member(node json.Document, key string) (json.Document, bool) {
+	if !node.IsObject() {
+		return json.EmptyDocument, false
+	}
+
+	return node.AtKey(key)
+}
+
+func (documentNavigator) members(node json.Document) iter.Seq2[string, json.Document] {
+	return node.Pairs()
+}
+
+func (documentNavigator) elems(node json.Document) ([]json.Document, bool) {
+	if !node.IsArray() {
+		return nil, false
+	}
+
+	return slices.Collect(node.Elems()), true
+}
+
+// dynamicNavigator explores the go values held by a [dynamic.JSON].
+//
+// Members of go maps are explored in the lexicographic order of their keys.
+type dynamicNavigator struct{}
+
+func (dynamicNavigator) member(node any, key string) (any, bool) {
+	switch object := node.(type) {
+	case map[string]any:
+		value, ok := object[key]
+
+		return value, ok
+	case interface{ Get(string) (any, bool) }:
+		return object.Get(key)
+	case dynamic.Object:
+		for k, value := range object.All() {
+			if k == key {
+				return value, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (dynamicNavigator) members(node any) iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		switch object := node.(type) {
+		case map[string]any:
+			for _, key := range slices.Sorted(maps.Keys(object)) {
+				if !yield(key, object[key]) {
+					return
+				}
+			}
+		case dynamic.Object:
+			for key, value := range object.All() {
+				if !yield(key, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (dynamicNavigator) elems(node any) ([]any, bool) {
+	switch array := node.(type) {
+	case []any:
+		return array, true
+	case dynamic.Array:
+		var elems []any
+		for _, value := range array.All() {
+			elems = append(elems, value)
+		}
+
+		return elems, true
+	default:
+		return nil, false
+	}
+}
@@ -18,21 +18,34 @@ func New() *PathFinder {
 }
 
 // Expression is a JSONPath expression.
+//
+// The supported syntax is the subset of RFC 9535 without filter selectors and functions:
+// the root identifier "$", child segments in dot (".name", ".*") or bracket notation
+// (with quoted names, indices, slices "start:end:step", wildcards and unions of those),
+// and descendant segments ("..name", "..*", "..[...]").
 type Expression struct {
-	text []byte
+	text     []byte
+	segments []segment
 }
 
 type StringOrBytes interface {
 	string | []byte
 }
 
+// MakeExpression parses a JSONPath expression.
+//
+// It returns an error wrapping [ErrJSONPath] if the expression is invalid, or [ErrUnsupported]
+// if it uses features which are not supported yet.
 func MakeExpression[T StringOrBytes](jp T) (Expression, error) {
-	e := Expression{
-		text: []byte(jp),
+	segments, err := parse(string(jp))
+	if err != nil {
+		return Expression{}, err
 	}
-	// TODO: parse JSONPath expression
 
-	return e, nil
+	return Expression{
+		text:     []byte(jp),
+		segments: segments,
+	}, nil
 }
 
 func NewExpression[T StringOrBytes](jp T) (*Expression, error) {
@@ -48,25 +61,47 @@ func (e Expression) String() string {
 	return string(e.text)
 }
 
+// Get the nodes of a [json.Document] matched by a JSONPath [Expression].
+//
+// Nodes are yielded in document order. A node may be yielded several times,
+// e.g. when matched by several selectors of a union.
 func (p *PathFinder) Get(root json.Document, expr Expression) iter.Seq[json.Document] {
-	return nil // TODO
+	return func(yield func(json.Document) bool) {
+		for node := range evaluate(documentNavigator{}, root, expr.segments) {
+			if !yield(node.value) {
+				return
+			}
+		}
+	}
 }
 
+// GetDynamic gets the nodes of a [dynamic.JSON] structure matched by a JSONPath [Expression].
+//
+// Members of go maps are visited in the lexicographic order of their keys.
 func (p *PathFinder) GetDynamic(root dynamic.JSON, expr Expression) iter.Seq[dynamic.JSON] {
-	return nil // TODO
+	return func(yield func(dynamic.JSON) bool) {
+		for node := range evaluate(dynamicNavigator{}, root.Interface(), expr.segments) {
+			if !yield(dynamic.From(node.value)) {
+				return
+			}
+		}
+	}
 }
 
+// Pointers yields the JSON pointers to the nodes of a [json.Document] matched by a JSONPath [Expression].
 func (p *PathFinder) Pointers(root json.Document, expr Expression) iter.Seq[json.Pointer] {
-	return nil // TODO
-}
-
-/*
-func (p *PathFinder) get(rawExpr string, value any) (any, error) {
-	_ = rawExpr
-	_ = value
-	//return jsonpath.Get(rawExpr, value)
-	return nil, nil
+	return func(yield func(json.Pointer) bool) {
+		for node := range evaluate(documentNavigator{}, root, expr.segments) {
+			pointer, err := json.MakePointerFromElements(node.path...)
+			if err != nil {
+				continue // never happens: paths are made of strings and ints only
+			}
+
+			if !yield(pointer) {
+				return
+			}
+		}
+	}
 }
-*/
 
 type expressionCache struct{}
@@ -0,0 +1,131 @@
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/json/dynamic"
+)
+
+const store = `{
+  "store": {
+    "book": [
+      {"category": "reference", "author": "Nigel Rees", "price": 8.95},
+      {"category": "fiction", "author": "Evelyn Waugh", "price": 12.99},
+      {"category": "fiction", "author": "Herman Melville", "price": 8.99},
+      {"category": "fiction", "author": "J. R. R. Tolkien", "price": 22.99}
+    ],
+    "bicycle": {"color": "red", "price": 399},
+    "o'clock": {"a/b": true}
+  }
+}`
+
+func TestPointers(t *testing.T) {
+	doc := json.Make()
+	require.NoError(t, doc.UnmarshalJSON([]byte(store)))
+	finder := New()
+
+	for _, tc := range []struct {
+		expr     string
+		expected []string
+	}{
+		{expr: "$", expected: []string{""}},
+		{expr: "$.store.bicycle.color", expected: []string{"/store/bicycle/color"}},
+		{expr: "$['store']['bicycle']", expected: []string{"/store/bicycle"}},
+		{expr: `$.store["o'clock"]['a/b']`, expected: []string{"/store/o'clock/a~1b"}},
+		{expr: `$.store['o\'clock'].*`, expected: []string{"/store/o'clock/a~1b"}},
+		{expr: "$.store.book[-1].author", expected: []string{"/store/book/3/author"}},
+		{expr: "$.store.book[0,2].price", expected: []string{"/store/book/0/price", "/store/book/2/price"}},
+		{expr: "$.store.book[1:3]", expected: []string{"/store/book/1", "/store/book/2"}},
+		{expr: "$.store.book[::-2]", expected: []string{"/store/book/3", "/store/book/1"}},
+		{expr: "$.store.book[*].category", expected: []string{
+			"/store/book/0/category", "/store/book/1/category", "/store/book/2/category", "/store/book/3/category",
+		}},
+		{expr: "$..price", expected: []string{
+			"/store/book/0/price", "/store/book/1/price", "/store/book/2/price", "/store/book/3/price", "/store/bicycle/price",
+		}},
+		{expr: "$.store..[0]", expected: []string{"/store/book/0"}},
+		{expr: "$.store.missing.key"},
+		{expr: "$.store.bicycle[0]"},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			expr, err := MakeExpression(tc.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expr, expr.String())
+
+			var actual []string
+			for pointer := range finder.Pointers(doc, expr) {
+				actual = append(actual, pointer.String())
+			}
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	expr, err := MakeExpression("$.store.book[?@.price < 10]")
+	require.ErrorIs(t, err, ErrUnsupported)
+	require.Empty(t, expr.String())
+
+	expr, err = MakeExpression("$.store.book[1:].author")
+	require.NoError(t, err)
+
+	t.Run("should get nodes from a document", func(t *testing.T) {
+		doc := json.Make()
+		require.NoError(t, doc.UnmarshalJSON([]byte(store)))
+
+		var authors []string
+		for node := range New().Get(doc, expr) {
+			authors = append(authors, node.String())
+		}
+
+		assert.Equal(t, []string{"Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"}, authors)
+	})
+
+	t.Run("should get nodes from a dynamic JSON", func(t *testing.T) {
+		value := dynamic.Make()
+		require.NoError(t, value.UnmarshalJSON([]byte(store)))
+
+		var authors []any
+		for node := range New().GetDynamic(value, expr) {
+			authors = append(authors, node.Interface())
+		}
+
+		assert.Equal(t, []any{"Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"}, authors)
+	})
+
+	t.Run("should visit go maps in key order", func(t *testing.T) {
+		wildcard, err := MakeExpression("$.*")
+		require.NoError(t, err)
+
+		var values []any
+		for node := range New().GetDynamic(dynamic.From(map[string]any{"b": 2, "a": 1, "c": 3}), wildcard) {
+			values = append(values, node.Interface())
+		}
+
+		assert.Equal(t, []any{1, 2, 3}, values)
+	})
+}
+
+func TestMakeExpression(t *testing.T) {
+	for _, invalid := range []string{
+		"",
+		"store",
+		"$.",
+		"$[",
+		"$['unterminated",
+		"$[1,]",
+		"$.1abc",
+		"$[a]",
+		`$["\x"]`,
+	} {
+		t.Run(invalid, func(t *testing.T) {
+			_, err := MakeExpression(invalid)
+			require.ErrorIs(t, err, ErrJSONPath)
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// segment of a JSONPath expression, i.e. a list of selectors applied to the children of a node,
+// or to the children of the node and all its descendants.
+type segment struct {
+	descendant bool
+	selectors  []selector
+}
+
+type selectorKind uint8
+
+const (
+	selectName selectorKind = iota
+	selectWildcard
+	selectIndex
+	selectSlice
+)
+
+type selector struct {
+	kind  selectorKind
+	name  string
+	index int
+
+	// slice bounds
+	start, end       int
+	hasStart, hasEnd bool
+	step             int
+}
+
+// parser for the subset of RFC 9535 JSONPath expressions without filters nor functions.
+type parser struct {
+	text string
+	pos  int
+}
+
+func parse(text string) ([]segment, error) {
+	p := parser{text: text}
+	p.skipBlanks()
+
+	if !p.consume('$') {
+		return nil, p.errorf("expected expression to start with '$'")
+	}
+
+	var segments []segment
+	for {
+		p.skipBlanks()
+		if p.eof() {
+			return segments, nil
+		}
+
+		s, err := p.segment()
+		if err != nil {
+			return nil, err
+		}
+
+		segments = append(segments, s)
+	}
+}
+
+func (p *parser) segment() (segment, error) {
+	var s segment
+
+	switch {
+	case p.consumeString(".."):
+		s.descendant = true
+		if p.peek() == '[' {
+			return p.bracketed(s)
+		}
+	case p.consume('.'):
+	case p.peek() == '[':
+		return p.bracketed(s)
+	default:
+		return s, p.errorf("expected '.', '..' or '['")
+	}
+
+	// dot notation
+	if p.consume('*') {
+		s.selectors = []selector{{kind: selectWildcard}}
+
+		return s, nil
+	}
+
+	name := p.shorthandName()
+	if name == "" {
+		return s, p.errorf("expected a member name or '*'")
+	}
+	s.selectors = []selector{{kind: selectName, name: name}}
+
+	return s, nil
+}
+
+func (p *parser) bracketed(s segment) (segment, error) {
+	p.consume('[')
+
+	for {
+		p.skipBlanks()
+		sel, err := p.selector()
+		if err != nil {
+			return s, err
+		}
+		s.selectors = append(s.selectors, sel)
+
+		p.skipBlanks()
+		switch {
+		case p.consume(','):
+			continue
+		case p.consume(']'):
+			return s, nil
+		default:
+			return s, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *parser) selector() (selector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		name, err := p.quotedName()
+
+		return selector{kind: selectName, name: name}, err
+	case c == '*':
+		p.pos++
+
+		return selector{kind: selectWildcard}, nil
+	case c == '?':
+		return selector{}, fmt.Errorf("filter selectors at position %d: %w", p.pos, ErrUnsupported)
+	case c == ':' || c == '-' || isDigit(c):
+		return p.indexOrSlice()
+	default:
+		return selector{}, p.errorf("expected a selector")
+	}
+}
+
+func (p *parser) indexOrSlice() (selector, error) {
+	var bounds [3]int
+	var present [3]bool
+
+	for i := range bounds {
+		p.skipBlanks()
+		if n, ok, err := p.integer(); err != nil {
+			return selector{}, err
+		} else if ok {
+			bounds[i], present[i] = n, true
+		}
+
+		p.skipBlanks()
+		if i == 2 || !p.consume(':') {
+			if i == 0 {
+				if !present[0] {
+					return selector{}, p.errorf("expected an index")
+				}
+
+				return selector{kind: selectIndex, index: bounds[0]}, nil
+			}
+
+			break
+		}
+	}
+
+	sel := selector{
+		kind:     selectSlice,
+		start:    bounds[0],
+		hasStart: present[0],
+		end:      bounds[1],
+		hasEnd:   present[1],
+		step:     1,
+	}
+	if present[2] {
+		sel.step = bounds[2]
+	}
+
+	return sel, nil
+}
+
+func (p *parser) integer() (int, bool, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+
+	for !p.eof() && isDigit(p.peek()) {
+		p.pos++
+	}
+
+	if p.pos == start {
+		return 0, false, nil
+	}
+
+	n, err := strconv.Atoi(p.text[start:p.pos])
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid integer %q at position %d: %w", p.text[start:p.pos], start, ErrJSONPath)
+	}
+
+	return n, true, nil
+}
+
+func (p *parser) shorthandName() string {
+	start := p.pos
+
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.text[p.pos:])
+		if !isNameChar(r) || p.pos == start && isDigit(byte(r)) {
+			break
+		}
+
+		p.pos += size
+	}
+
+	return p.text[start:p.pos]
+}
+
+func (p *parser) quotedName() (string, error) {
+	quote := p.text[p.pos]
+	p.pos++
+
+	var w strings.Builder
+	for !p.eof() {
+		c := p.text[p.pos]
+		p.pos++
+
+		switch c {
+		case quote:
+			return w.String(), nil
+		case '\\':
+			if err := p.escaped(&w); err != nil {
+				return "", err
+			}
+		default:
+			w.WriteByte(c)
+		}
+	}
+
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) escaped(w *strings.Builder) error {
+	if p.eof() {
+		return p.errorf("unterminated escape sequence")
+	}
+
+	c := p.text[p.pos]
+	p.pos++
+
+	switch c {
+	case 'b':
+		w.WriteByte('\b')
+	case 'f':
+		w.WriteByte('\f')
+	case 'n':
+		w.WriteByte('\n')
+	case 'r':
+		w.WriteByte('\r')
+	case 't':
+		w.WriteByte('\t')
+	case '/', '\\', '\'', '"':
+		w.WriteByte(c)
+	case 'u':
+		if p.pos+4 > len(p.text) {
+			return p.errorf("invalid unicode escape sequence")
+		}
+
+		r, err := strconv.ParseUint(p.text[p.pos:p.pos+4], 16, 16)
+		if err != nil {
+			return p.errorf("invalid unicode escape sequence")
+		}
+		p.pos += 4
+		w.WriteRune(rune(r))
+	default:
+		return p.errorf("invalid escape sequence")
+	}
+
+	return nil
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.text)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+
+	return p.text[p.pos]
+}
+
+func (p *parser) consume(c byte) bool {
+	if p.peek() != c {
+		return false
+	}
+
+	p.pos++
+
+	return true
+}
+
+func (p *parser) consumeString(s string) bool {
+	if !strings.HasPrefix(p.text[p.pos:], s) {
+		return false
+	}
+
+	p.pos += len(s)
+
+	return true
+}
+
+func (p *parser) skipBlanks() {
+	for !p.eof() {
+		switch p.text[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s at position %d in %q: %w", fmt.Sprintf(format, args...), p.pos, p.text, ErrJSONPath)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameChar(r rune) bool {
+	return r == '_' || r >= 0x80 || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
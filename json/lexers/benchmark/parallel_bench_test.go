@@ -0,0 +1,147 @@
+package benchmark
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/json/lexers/benchmark/workloads"
+	deflex "github.com/fredbi/core/json/lexers/default-lexer"
+)
+
+// recordsSize is the size of the large arrays decoded by the parallel benchmarks.
+const recordsSize = 32 << 20
+
+// workerCounts yields the number of workers exercised by the parallel benchmarks: powers of 2 up to GOMAXPROCS.
+//
+// Run with e.g. -cpu 1,2,4,8 to observe how decoding scales with cores.
+func workerCounts() []int {
+	procs := runtime.GOMAXPROCS(0)
+	counts := []int{1}
+	for n := 2; n < procs; n *= 2 {
+		counts = append(counts, n)
+	}
+	if procs > 1 {
+		counts = append(counts, procs)
+	}
+
+	return counts
+}
+
+// BenchmarkParallelDecode reports input throughput (MB/s) when decoding a large top-level array of records
+// into a json.Document, sequentially then with an increasing number of workers.
+//
+// "split" measures the structural pre-scan alone, which is the sequential part of the parallel decoder.
+// "document" decodes the whole array as a single json.Document, while "elems" yields its elements in order.
+func BenchmarkParallelDecode(b *testing.B) {
+	suite, err := workloads.Records(recordsSize)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, wl := range suite {
+		b.Run(wl.Name, func(b *testing.B) {
+			b.Run("split", func(b *testing.B) {
+				b.SetBytes(int64(len(wl.Data)))
+				b.ReportAllocs()
+				b.ResetTimer()
+				for b.Loop() {
+					segments, err := deflex.SplitArray(wl.Data, runtime.GOMAXPROCS(0))
+					if err != nil {
+						b.Fatal(err)
+					}
+					sink += len(segments)
+				}
+			})
+
+			b.Run("document/sequential", func(b *testing.B) {
+				b.SetBytes(int64(len(wl.Data)))
+				b.ReportAllocs()
+				b.ResetTimer()
+				for b.Loop() {
+					doc := json.Make()
+					if err := doc.UnmarshalJSON(wl.Data); err != nil {
+						b.Fatal(err)
+					}
+					sink += doc.Len()
+				}
+			})
+
+			for _, workers := range workerCounts() {
+				b.Run(fmt.Sprintf("document/workers=%d", workers), func(b *testing.B) {
+					b.SetBytes(int64(len(wl.Data)))
+					b.ReportAllocs()
+					b.ResetTimer()
+					for b.Loop() {
+						doc := json.Make(json.WithParallelDecode(workers), json.WithParallelThreshold(1))
+						if err := doc.UnmarshalJSON(wl.Data); err != nil {
+							b.Fatal(err)
+						}
+						sink += doc.Len()
+					}
+				})
+			}
+
+			for _, workers := range workerCounts() {
+				b.Run(fmt.Sprintf("elems/workers=%d", workers), func(b *testing.B) {
+					b.SetBytes(int64(len(wl.Data)))
+					b.ReportAllocs()
+					b.ResetTimer()
+					for b.Loop() {
+						for elem, err := range json.ParallelElems(wl.Data, json.WithParallelDecode(workers)) {
+							if err != nil {
+								b.Fatal(err)
+							}
+							sink += int(elem.Kind())
+						}
+					}
+				})
+			}
+		})
+	}
+}
+
+// TestParallelDecodeAgrees checks that the parallel decoder produces the same document as the sequential one
+// on the benchmark workloads.
+func TestParallelDecodeAgrees(t *testing.T) {
+	suite, err := workloads.Records(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, wl := range suite {
+		t.Run(wl.Name, func(t *testing.T) {
+			sequential := json.Make()
+			if err := sequential.UnmarshalJSON(wl.Data); err != nil {
+				t.Fatal(err)
+			}
+
+			parallel := json.Make(json.WithParallelDecode(4), json.WithParallelThreshold(1))
+			if err := parallel.UnmarshalJSON(wl.Data); err != nil {
+				t.Fatal(err)
+			}
+
+			if sequential.String() != parallel.String() {
+				t.Error("parallel decoding differs from sequential decoding")
+			}
+
+			var count int
+			for elem, err := range json.ParallelElems(wl.Data, json.WithParallelDecode(4)) {
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				expected, _ := sequential.Elem(count)
+				if expected.String() != elem.String() {
+					t.Fatalf("element %d differs", count)
+				}
+				count++
+			}
+
+			if count != sequential.Len() {
+				t.Errorf("expected %d elements, got %d", sequential.Len(), count)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package workloads
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// records lists the corpus documents that hold an array of records, either at the top level or under some
+// key of the top-level object.
+//
+//nolint:gochecknoglobals
+var records = []struct {
+	name string
+	key  string
+}{
+	{name: "github_events"},
+	{name: "twitter_status", key: "statuses"},
+	{name: "numbers"},
+}
+
+// Records returns large top-level JSON arrays of at least minSize bytes, built by repeating the records found
+// in the corpus.
+//
+// These drive the parallel decoding benchmarks, which only apply to large top-level arrays.
+func Records(minSize int) ([]Workload, error) {
+	out := make([]Workload, 0, len(records))
+
+	for _, rec := range records {
+		data, err := readGzip("testdata/" + rec.name + ".json.gz")
+		if err != nil {
+			return nil, fmt.Errorf("loading corpus %s: %w", rec.name, err)
+		}
+
+		var elems []json.RawMessage
+		if rec.key == "" {
+			err = json.Unmarshal(data, &elems)
+		} else {
+			var wrapper map[string]json.RawMessage
+			if err = json.Unmarshal(data, &wrapper); err == nil {
+				err = json.Unmarshal(wrapper[rec.key], &elems)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("extracting records from %s: %w", rec.name, err)
+		}
+
+		if len(elems) == 0 {
+			return nil, fmt.Errorf("no records in %s", rec.name)
+		}
+
+		var buf bytes.Buffer
+		buf.Grow(minSize + minSize/10)
+		buf.WriteByte('[')
+		for i := 0; buf.Len() < minSize; i++ {
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			buf.Write(elems[i%len(elems)])
+		}
+		buf.WriteString("]\n")
+
+		out = append(out, Workload{
+			Name: "records_" + rec.name,
+			Data: buf.Bytes(),
+		})
+	}
+
+	return out, nil
+}
@@ -0,0 +1,215 @@
+package lexer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"iter"
+
+	"github.com/fredbi/core/json/lexers/default-lexer/internal/strscan"
+	"github.com/fredbi/core/json/lexers/default-lexer/internal/swar"
+	codes "github.com/fredbi/core/json/lexers/error-codes"
+)
+
+// Segment is the [Start, End) byte range of a run of JSON values within some input.
+type Segment struct {
+	Start int
+	End   int
+}
+
+// Len yields the size in bytes of the [Segment].
+func (s Segment) Len() int {
+	return s.End - s.Start
+}
+
+// SplitArray runs a structural pre-scan over a JSON input holding a top-level array, and splits the elements
+// of this array into at most parts contiguous runs of roughly equal byte size.
+//
+// Every returned [Segment] starts right after the opening bracket or a top-level comma and ends right before
+// the next top-level comma or the closing bracket: it holds at least one element, and never splits an element.
+// The elements within a run are iterated with [ArrayElements]. An empty array yields no [Segment].
+//
+// This is the preparation step to decode a large array on several goroutines, each lexing its own runs.
+//
+// The pre-scan is much cheaper than lexing: it only tracks strings, escape sequences and the nesting of
+// containers, skipping over string bodies with the same word-at-a-time kernels as the lexer.
+// It does not check the JSON grammar, which is left to the lexers that eventually decode the elements.
+//
+// It reports an error when the input is not an array, when some string is not terminated, when containers
+// are not balanced or when some non-blank data follows the array.
+func SplitArray(data []byte, parts int) ([]Segment, error) {
+	parts = max(1, parts)
+	pos := skipBlanks(data, 0)
+	if pos >= len(data) {
+		return nil, codes.ErrNoData
+	}
+
+	if data[pos] != openingSquareBracket {
+		return nil, fmt.Errorf("expected a JSON array, but got %q: %w", data[pos], codes.ErrInvalidToken)
+	}
+	pos++
+
+	if next := skipBlanks(data, pos); next < len(data) && data[next] == closingSquareBracket {
+		// empty array
+		if trailing := skipBlanks(data, next+1); trailing < len(data) {
+			return nil, fmt.Errorf("unexpected data after the JSON array at offset %d: %w", trailing, codes.ErrInvalidToken)
+		}
+
+		return nil, nil
+	}
+
+	target := max(1, (len(data)-pos)/parts)
+	segments := make([]Segment, 0, parts)
+	start := pos
+
+	for {
+		sep, err := nextSeparator(data, pos)
+		if err != nil {
+			return nil, err
+		}
+
+		if sep >= len(data) {
+			return nil, fmt.Errorf("unterminated JSON array: %w", codes.ErrNotInArray)
+		}
+
+		switch data[sep] {
+		case comma:
+			if sep-start >= target && len(segments) < parts-1 {
+				segments = append(segments, Segment{Start: start, End: sep})
+				start = sep + 1
+			}
+			pos = sep + 1
+
+			continue
+
+		case closingSquareBracket:
+			segments = append(segments, Segment{Start: start, End: sep})
+			if trailing := skipBlanks(data, sep+1); trailing < len(data) {
+				return nil, fmt.Errorf("unexpected data after the JSON array at offset %d: %w", trailing, codes.ErrInvalidToken)
+			}
+
+			return segments, nil
+
+		default:
+			return nil, fmt.Errorf("at offset %d: %w", sep, codes.ErrNotInObject)
+		}
+	}
+}
+
+// ArrayElements iterates over the elements held in a run of array elements, as produced by [SplitArray].
+//
+// Each yielded [Segment] spans one element, possibly surrounded by blank space, and excludes the separating
+// commas. An empty element (e.g. in "[1,,2]") is yielded as such, so a lexer may report it as an error.
+func ArrayElements(data []byte, run Segment) iter.Seq[Segment] {
+	return func(yield func(Segment) bool) {
+		input := data[:run.End]
+		pos := run.Start
+
+		for {
+			sep, err := nextSeparator(input, pos)
+			if err != nil || sep >= run.End {
+				// the remainder is the last element of the run. Any error is left to the lexer.
+				yield(Segment{Start: pos, End: run.End})
+
+				return
+			}
+
+			if !yield(Segment{Start: pos, End: sep}) {
+				return
+			}
+
+			pos = sep + 1
+		}
+	}
+}
+
+// nextSeparator returns the position of the next comma or closing bracket found at the current nesting level,
+// starting from pos. It returns len(data) if none is found.
+func nextSeparator(data []byte, pos int) (int, error) {
+	var depth int
+
+	for pos < len(data) {
+		switch data[pos] {
+		case doubleQuote:
+			end, ok := skipString(data, pos+1)
+			if !ok {
+				return len(data), fmt.Errorf("at offset %d: %w", pos, codes.ErrUnterminatedString)
+			}
+			pos = end
+
+			continue
+
+		case openingSquareBracket, openingBracket:
+			depth++
+
+		case closingSquareBracket, closingBracket:
+			if depth == 0 {
+				return pos, nil
+			}
+			depth--
+
+		case comma:
+			if depth == 0 {
+				return pos, nil
+			}
+		}
+
+		pos++
+	}
+
+	if depth > 0 {
+		return len(data), fmt.Errorf("unbalanced containers: %w", codes.ErrInvalidToken)
+	}
+
+	return pos, nil
+}
+
+// skipString returns the position right after the closing double quote of a string which body starts at pos.
+//
+// Like the lexer's string path, it probes the first word inline and only delegates to [strscan.ScanStop]
+// when this word is clean, i.e. when the string is likely long.
+func skipString(data []byte, pos int) (int, bool) {
+	for pos < len(data) {
+		var stop int
+
+		if pos+8 <= len(data) {
+			if m := swar.StringStopMask(binary.LittleEndian.Uint64(data[pos:])); m != 0 {
+				stop = pos + swar.FirstByte(m)
+			} else {
+				stop = pos + 8 + strscan.ScanStop(data[pos+8:])
+			}
+		} else {
+			stop = pos
+			for stop < len(data) && data[stop] != doubleQuote && data[stop] != escape {
+				stop++
+			}
+		}
+
+		if stop >= len(data) {
+			return len(data), false
+		}
+
+		switch data[stop] {
+		case doubleQuote:
+			return stop + 1, true
+		case escape:
+			pos = stop + 2 // skip the escaped character
+		default:
+			pos = stop + 1 // control character: invalid, but this is for the lexer to report
+		}
+	}
+
+	return len(data), false
+}
+
+func skipBlanks(data []byte, pos int) int {
+	for pos < len(data) {
+		switch data[pos] {
+		case blank, tab, lineFeed, carriageReturn:
+			pos++
+		default:
+			return pos
+		}
+	}
+
+	return pos
+}
@@ -0,0 +1,101 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	codes "github.com/fredbi/core/json/lexers/error-codes"
+)
+
+func TestSplitArray(t *testing.T) {
+	collect := func(t *testing.T, data []byte, parts int) ([]string, int) {
+		t.Helper()
+
+		segments, err := SplitArray(data, parts)
+		require.NoError(t, err)
+
+		var elems []string
+		for _, run := range segments {
+			for elem := range ArrayElements(data, run) {
+				elems = append(elems, strings.TrimSpace(string(data[elem.Start:elem.End])))
+			}
+		}
+
+		return elems, len(segments)
+	}
+
+	t.Run("splits elements at top-level commas only", func(t *testing.T) {
+		const doc = ` [ 1, "a,]\"b", {"x":[1,2],"y":"}"}, [[3],[4]], null ] `
+
+		for parts := 1; parts <= 8; parts++ {
+			elems, runs := collect(t, []byte(doc), parts)
+			assert.Equal(t, []string{`1`, `"a,]\"b"`, `{"x":[1,2],"y":"}"}`, `[[3],[4]]`, `null`}, elems)
+			assert.LessOrEqual(t, runs, parts)
+			assert.GreaterOrEqual(t, runs, 1)
+		}
+	})
+
+	t.Run("balances runs by size", func(t *testing.T) {
+		var w strings.Builder
+		w.WriteByte('[')
+		for i := range 1000 {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(`{"key":"a somewhat long string value, with \"escapes\" and commas"}`)
+		}
+		w.WriteByte(']')
+		data := []byte(w.String())
+
+		segments, err := SplitArray(data, 4)
+		require.NoError(t, err)
+		require.Len(t, segments, 4)
+
+		var count int
+		for _, run := range segments {
+			assert.InDelta(t, len(data)/4, run.Len(), float64(len(data)/10))
+			for range ArrayElements(data, run) {
+				count++
+			}
+		}
+		assert.Equal(t, 1000, count)
+	})
+
+	t.Run("empty array yields no segment", func(t *testing.T) {
+		segments, err := SplitArray([]byte(" [ ] "), 4)
+		require.NoError(t, err)
+		assert.Empty(t, segments)
+	})
+
+	t.Run("empty elements are yielded for the lexer to reject", func(t *testing.T) {
+		elems, _ := collect(t, []byte(`[1,,2,]`), 1)
+		assert.Equal(t, []string{`1`, ``, `2`, ``}, elems)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, tc := range []struct {
+			doc  string
+			want error
+		}{
+			{doc: ``, want: codes.ErrNoData},
+			{doc: `  `, want: codes.ErrNoData},
+			{doc: `{"a":1}`, want: codes.ErrInvalidToken},
+			{doc: `[1,2`, want: codes.ErrNotInArray},
+			{doc: `[1,"2]`, want: codes.ErrUnterminatedString},
+			{doc: `[1,"2\"]`, want: codes.ErrUnterminatedString},
+			{doc: `[1,[2]`, want: codes.ErrNotInArray},
+			{doc: `[1,[2`, want: codes.ErrInvalidToken},
+			{doc: `[1,2}`, want: codes.ErrNotInObject},
+			{doc: `[1,2] 3`, want: codes.ErrInvalidToken},
+			{doc: `[] 3`, want: codes.ErrInvalidToken},
+		} {
+			t.Run(tc.doc, func(t *testing.T) {
+				_, err := SplitArray([]byte(tc.doc), 2)
+				require.ErrorIs(t, err, tc.want)
+			})
+		}
+	})
+}
@@ -190,6 +190,39 @@ func (b *Builder) Swap(i, j int) *Builder {
 	return b
 }
 
+// ReplaceChild replaces the child node at position in an object or array.
+//
+// In an object, the replacement node keeps the key of the replaced member.
+func (b *Builder) ReplaceChild(position int, value Node) *Builder {
+	if !b.Ok() {
+		return b
+	}
+
+	if b.n.kind != nodes.KindArray && b.n.kind != nodes.KindObject {
+		b.err = fmt.Errorf(
+			"can't replace a child of a non-container node. Node kind is %v: %w",
+			b.n.kind, nodecodes.ErrBuilder,
+		)
+
+		return b
+	}
+
+	if position < 0 || position >= len(b.n.children) {
+		b.err = fmt.Errorf(
+			"can't replace an out of range child. Index %d out of [0,%d): %w",
+			position, len(b.n.children), nodecodes.ErrBuilder,
+		)
+
+		return b
+	}
+
+	b.cloneForWrite()
+	value.key = b.n.children[position].key
+	b.n.children[position] = value
+
+	return b
+}
+
 func (b *Builder) AppendKey(key string, value Node) *Builder {
 	if !b.Ok() {
 		return b
@@ -64,6 +64,7 @@ func TestBuilderCopyOnWrite(t *testing.T) {
 			{"InsertKey", func(b *Builder) *Builder { return b.InsertKey("z", 1, scalar("Z")) }},
 			{"RemoveKey", func(b *Builder) *Builder { return b.RemoveKey("a") }},
 			{"Swap", func(b *Builder) *Builder { return b.Swap(0, 2) }},
+			{"ReplaceChild", func(b *Builder) *Builder { return b.ReplaceChild(1, scalar("Z")) }},
 		} {
 			t.Run(tc.name, func(t *testing.T) {
 				orig := makeObject()
@@ -92,6 +93,7 @@ func TestBuilderCopyOnWrite(t *testing.T) {
 			{"InsertElem", func(b *Builder) *Builder { return b.InsertElem(1, scalar("X")) }},
 			{"RemoveElem", func(b *Builder) *Builder { return b.RemoveElem(0) }},
 			{"Swap", func(b *Builder) *Builder { return b.Swap(0, 2) }},
+			{"ReplaceChild", func(b *Builder) *Builder { return b.ReplaceChild(1, scalar("X")) }},
 		} {
 			t.Run(tc.name, func(t *testing.T) {
 				orig := makeArray()
@@ -119,6 +121,21 @@ func TestBuilderCopyOnWrite(t *testing.T) {
 		assert.Equal(t, 4, clone.Len())
 	})
 
+	t.Run("a replaced member keeps its key", func(t *testing.T) {
+		orig := makeObject()
+		clone := NewBuilder(s).From(orig).ReplaceChild(1, scalar("Z")).Node()
+
+		member, ok := clone.AtKey("b")
+		require.True(t, ok)
+		v, ok := member.Value(s)
+		require.True(t, ok)
+		assert.Equal(t, "Z", v.String())
+		assertObjectIndexConsistent(t, clone)
+
+		assert.False(t, NewBuilder(s).From(orig).ReplaceChild(3, scalar("Z")).Ok())
+		assert.False(t, NewBuilder(s).StringValue("x").ReplaceChild(0, scalar("Z")).Ok())
+	})
+
 	t.Run("a long mutation chain still protects the original", func(t *testing.T) {
 		orig := makeObject()
 		wantKeys, wantIdx := snapshotObject(orig)
@@ -97,6 +97,19 @@ func addElemToPath(original, p Path, idx int) Path {
 	return p
 }
 
+// AppendIndex appends an array index to the [Path].
+func (p Path) AppendIndex(idx int) Path {
+	return append(p, stringOrInt{
+		kind: pathElemInt,
+		i:    idx,
+	})
+}
+
+// MakeContext builds the [Context] of a [Node] found at some offset in the original JSON stream.
+func MakeContext(offset uint64) Context {
+	return Context{offset: offset}
+}
+
 // Offset of this node in the original JSON stream.
 func (c Context) Offset() uint64 {
 	return c.offset
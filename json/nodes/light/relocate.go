@@ -0,0 +1,26 @@
+package light
+
+import (
+	"github.com/fredbi/core/json/stores"
+)
+
+// Relocate rewrites in place the decode context and the [stores.Handle] s of a node hierarchy.
+//
+// Every decode offset is shifted by offset, and every value handle is translated by remap (if not nil).
+//
+// This is needed whenever a hierarchy has been decoded from a slice of the original input, or into a
+// [stores.Store] that has since been merged into another one — e.g. when decoding large arrays in parallel.
+//
+// Relocate mutates the hierarchy and thus breaks the immutability of the [Node]: it must only be applied to
+// a freshly decoded hierarchy which is not shared yet.
+func (n *Node) Relocate(offset uint64, remap func(stores.Handle) stores.Handle) {
+	n.ctx.offset += offset
+
+	if remap != nil && !n.value.IsZero() {
+		n.value = remap(n.value)
+	}
+
+	for i := range n.children {
+		n.children[i].Relocate(offset, remap)
+	}
+}
@@ -0,0 +1,59 @@
+package light
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	lexer "github.com/fredbi/core/json/lexers/default-lexer"
+	store "github.com/fredbi/core/json/stores/default-store"
+)
+
+func TestRelocate(t *testing.T) {
+	const (
+		prefix = `[0, `
+		elem   = `{"a": "a string long enough for the arena", "b": [1, 2.5e300, true]}`
+	)
+
+	// decode the full array as a reference: this puts values in the target store, so relocated handles change
+	target := store.New()
+	ref := mustDecode(t, target, prefix+elem+`]`)
+	want, ok := ref.Elem(1)
+	require.True(t, ok)
+
+	// decode the element alone, in a fork, then relocate it
+	fork := target.Fork()
+	got := mustDecode(t, fork, elem)
+	relocation := target.Merge(fork)
+	got.Relocate(uint64(len(prefix)), relocation.Handle)
+
+	assert.Equal(t, want.Dump(target), got.Dump(target))
+
+	var check func(want, got Node)
+	check = func(want, got Node) {
+		assert.Equal(t, want.Context().Offset(), got.Context().Offset())
+		for i, child := range want.IndexedElems() {
+			other, _ := got.Elem(i)
+			check(child, other)
+		}
+		for key, child := range want.Pairs() {
+			other, _ := got.AtInternedKey(key)
+			check(child, other)
+		}
+	}
+	check(want, got)
+}
+
+func mustDecode(t *testing.T, s *store.Store, data string) Node {
+	t.Helper()
+
+	lex := lexer.NewWithBytes([]byte(data))
+	ctx := &ParentContext{L: lex, S: s}
+
+	var n Node
+	n.Decode(ctx)
+	require.NoError(t, lex.Err())
+
+	return n
+}
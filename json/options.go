@@ -28,6 +28,7 @@ func WithLexer(l lexers.Lexer) Option {
 		o.lexerFromReaderFactory = func(_ io.Reader) (lexers.Lexer, func()) {
 			return l, noop
 		}
+		o.hasCustomLexer = true
 	}
 }
 
@@ -44,6 +45,10 @@ type options struct {
 	lexerFactory           func([]byte) (lexers.Lexer, func())
 	lexerFromReaderFactory func(io.Reader) (lexers.Lexer, func())
 	writerToWriterFactory  func(io.Writer) (writers.StoreWriter, func())
+	hasCustomLexer         bool
+
+	// for parallel decoding
+	parallelOptions
 
 	// for light nodes
 	light.DecodeOptions
@@ -0,0 +1,315 @@
+package json
+
+import (
+	"iter"
+	"runtime"
+	"sync"
+
+	lexer "github.com/fredbi/core/json/lexers/default-lexer"
+	"github.com/fredbi/core/json/nodes/light"
+	store "github.com/fredbi/core/json/stores/default-store"
+)
+
+const (
+	defaultParallelThreshold = 1 << 20 // 1 MiB
+	runsPerWorker            = 4       // when yielding elements, the input is split into smaller runs to pipeline work
+)
+
+type parallelOptions struct {
+	parallelWorkers   int
+	parallelThreshold int
+}
+
+// WithParallelDecode enables the parallel decoding of large top-level JSON arrays by [Document.UnmarshalJSON].
+//
+// A large input holding a top-level array is split at element boundaries by a quick structural pre-scan
+// (see [lexer.SplitArray]). Runs of elements are then decoded on up to workers goroutines, each into its own
+// fork of the [stores.Store], before being stitched together into a single [Document].
+//
+// When workers is 0 or less, the number of workers is [runtime.GOMAXPROCS].
+//
+// Only inputs larger than a threshold are decoded in parallel (see [WithParallelThreshold]).
+// Smaller inputs, inputs which are not arrays, and documents configured with options that cannot be honored
+// in parallel are decoded sequentially:
+//
+//   - a custom lexer ([WithLexer]): the parallel decoder always uses the default lexer
+//   - decode hooks, which expect to observe the whole document in order
+//   - a [stores.Store] which does not support forks, like the [store.Store] and [store.ConcurrentStore]
+//     do with Fork and Merge
+//
+// The result is the same as a sequential decoding. However, a decode error may not be the first one that a
+// sequential decoding would have found.
+//
+// Decoding from an [io.Reader] with [Document.Decode] is always sequential.
+func WithParallelDecode(workers int) Option {
+	return func(o *options) {
+		if workers <= 0 {
+			workers = runtime.GOMAXPROCS(0)
+		}
+
+		o.parallelWorkers = workers
+	}
+}
+
+// WithParallelThreshold sets the minimum size in bytes of an input to be decoded in parallel, when
+// [WithParallelDecode] is enabled.
+//
+// The default is 1 MiB: below that, the overhead of spreading the work is not worth it.
+func WithParallelThreshold(size int) Option {
+	return func(o *options) {
+		o.parallelThreshold = size
+	}
+}
+
+// ParallelElems decodes the elements of a top-level JSON array on several goroutines, and yields them in order
+// as [Document] s.
+//
+// This is intended to ingest large arrays of records, without building the whole array as a single [Document].
+// The input is split into runs of elements (see [lexer.SplitArray]), which are decoded concurrently into
+// their own [stores.Store] while the caller consumes the elements already decoded. All the elements of a run
+// share the same [stores.Store], forked from the configured one (see [WithParallelDecode] about forks).
+//
+// The number of workers is set by [WithParallelDecode] (the default is [runtime.GOMAXPROCS]). The threshold
+// set with [WithParallelThreshold] is ignored.
+//
+// Iteration stops after the first error. Decode hooks are not supported and are ignored.
+func ParallelElems(data []byte, opts ...Option) iter.Seq2[Document, error] {
+	o := optionsWithDefaults(opts)
+	workers := o.parallelWorkers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	o.OnEnter = nil
+	o.OnExit = nil
+
+	return func(yield func(Document, error) bool) {
+		segments, err := lexer.SplitArray(data, workers*runsPerWorker)
+		if err != nil {
+			yield(EmptyDocument, err)
+
+			return
+		}
+
+		fork := forkerOf(o)
+		done := make(chan struct{})
+		results := make([]chan parallelRun, len(segments))
+		for i := range results {
+			results[i] = make(chan parallelRun, 1)
+		}
+
+		// the producer keeps at most 2 runs per worker ahead of the consumer
+		inFlight := make(chan struct{}, 2*workers)
+		go func() {
+			for i, segment := range segments {
+				select {
+				case inFlight <- struct{}{}:
+				case <-done:
+					return
+				}
+
+				go func() {
+					results[i] <- o.decodeRun(data, segment, fork())
+				}()
+			}
+		}()
+		defer close(done)
+
+		var index int
+		for i := range segments {
+			run := <-results[i]
+			<-inFlight
+
+			if run.err != nil {
+				yield(EmptyDocument, run.decodeError(index))
+
+				return
+			}
+
+			runOptions := o
+			runOptions.store = run.store
+			for _, node := range run.nodes {
+				if !yield(Document{options: runOptions, document: document{root: node}}, nil) {
+					return
+				}
+			}
+			index += len(run.nodes)
+		}
+	}
+}
+
+// parallelRun is the outcome of decoding a run of array elements.
+type parallelRun struct {
+	store  *store.Store
+	nodes  []light.Node
+	err    *DecodeError
+	offset uint64 // offset of the failed element in the input
+}
+
+// decodeError reports the decode error of this run, given the index in the array of the first element of
+// the run.
+func (r parallelRun) decodeError(first int) *DecodeError {
+	pth := light.Path{}.AppendIndex(first + len(r.nodes))
+	pth = append(pth, r.err.Path...)
+
+	errContext := r.err.ErrContext
+	errContext.Offset += r.offset
+	errContext.Path = pth.String()
+
+	return &DecodeError{
+		ErrContext: errContext,
+		Path:       pth,
+	}
+}
+
+// canDecodeParallel tells if the input should be decoded in parallel.
+func (d *Document) canDecodeParallel(data []byte) bool {
+	if d.parallelWorkers <= 1 || d.hasCustomLexer || d.OnEnter != nil || d.OnExit != nil {
+		return false
+	}
+
+	threshold := d.parallelThreshold
+	if threshold <= 0 {
+		threshold = defaultParallelThreshold
+	}
+
+	if len(data) < threshold {
+		return false
+	}
+
+	if _, ok := d.store.(storeForker); !ok {
+		return false
+	}
+
+	for _, c := range data {
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case '[':
+			return true
+		default:
+			return false
+		}
+	}
+
+	return false
+}
+
+// decodeParallel decodes a top-level array in parallel, then stitches the decoded elements together.
+func (d *Document) decodeParallel(data []byte) error {
+	segments, err := lexer.SplitArray(data, d.parallelWorkers)
+	if err != nil {
+		return err
+	}
+
+	if len(segments) == 0 {
+		// empty array
+		lex, redeem := d.lexerFactory(data)
+		defer redeem()
+
+		return d.decode(lex)
+	}
+
+	target := d.store.(storeForker) //nolint:forcetypeassert // already checked by canDecodeParallel
+	runs := make([]parallelRun, len(segments))
+	var wg sync.WaitGroup
+	for i, segment := range segments {
+		wg.Go(func() {
+			runs[i] = d.decodeRun(data, segment, target.Fork())
+		})
+	}
+	wg.Wait()
+
+	var count int
+	for _, run := range runs {
+		if run.err != nil {
+			return run.decodeError(count)
+		}
+
+		count += len(run.nodes)
+	}
+
+	// stitch: merge the forked stores, then relocate the handles of every run
+	for i, run := range runs {
+		relocation := target.Merge(run.store)
+		if relocation == 0 {
+			continue
+		}
+
+		wg.Go(func() {
+			for j := range runs[i].nodes {
+				runs[i].nodes[j].Relocate(0, relocation.Handle)
+			}
+		})
+	}
+	wg.Wait()
+
+	elems := make([]light.Node, 0, count)
+	for _, run := range runs {
+		elems = append(elems, run.nodes...)
+	}
+
+	// the offset of the array is the one of its opening bracket, as reported by a sequential decoding
+	b := light.NewBuilder(d.store)
+	d.root = b.Array().WithContext(light.MakeContext(uint64(segments[0].Start))).AppendElems(elems...).Node() //nolint:gosec // offsets are positive
+
+	return b.Err()
+}
+
+// decodeRun decodes a run of array elements into a forked [store.Store].
+func (o options) decodeRun(data []byte, segment lexer.Segment, s *store.Store) parallelRun {
+	lex, redeemLexer := lexer.BorrowLexerWithBytes(nil)
+	ctx, redeemContext := light.BorrowParentContext()
+	pth, redeemPath := light.BorrowPath()
+	defer func() {
+		redeemPath()
+		redeemContext()
+		redeemLexer()
+	}()
+
+	ctx.L = lex
+	ctx.S = s
+	ctx.DO = o.DecodeOptions
+	ctx.P = pth
+
+	run := parallelRun{
+		store: s,
+	}
+
+	for elem := range lexer.ArrayElements(data, segment) {
+		lex.ResetWithBytes(data[elem.Start:elem.End])
+		ctx.C = nil
+		ctx.P = ctx.P[:0]
+
+		var node light.Node
+		node.Decode(ctx)
+		if !lex.Ok() {
+			run.err = makeDecodeError(ctx)
+			errContext := *run.err.ErrContext // the lexer owns this context: copy it before the lexer is recycled
+			run.err.ErrContext = &errContext
+			run.offset = uint64(elem.Start) //nolint:gosec // offsets are positive
+
+			return run
+		}
+
+		node.Relocate(uint64(elem.Start), nil) //nolint:gosec // offsets are positive
+		run.nodes = append(run.nodes, node)
+	}
+
+	return run
+}
+
+// storeForker is a [stores.Store] that supports forks, like [store.Store] and [store.ConcurrentStore].
+type storeForker interface {
+	Fork() *store.Store
+	Merge(*store.Store) store.Relocation
+}
+
+// forkerOf returns a function that produces forks of the configured [stores.Store], or new default stores
+// if the configured store does not support forks.
+func forkerOf(o options) func() *store.Store {
+	if forker, ok := o.store.(storeForker); ok {
+		return forker.Fork
+	}
+
+	return func() *store.Store { return store.New() }
+}
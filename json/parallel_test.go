@@ -0,0 +1,211 @@
+package json
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/json/nodes/light"
+	store "github.com/fredbi/core/json/stores/default-store"
+)
+
+func parallelFixture(n int) []byte {
+	var w strings.Builder
+	w.WriteString("[\n")
+	for i := range n {
+		if i > 0 {
+			w.WriteString(",\n")
+		}
+		fmt.Fprintf(&w,
+			`  {"id": %d, "name": "record #%d, with a \"quoted\" part", "tags": ["a", "b,c", "]"], "score": %d.125e2, "active": %t, "extra": null, "description": %q}`,
+			i, i, i*7, i%2 == 0, strings.Repeat("some long text to push values to the arena. ", i%5),
+		)
+	}
+	w.WriteString("\n]\n")
+
+	return []byte(w.String())
+}
+
+func TestParallelDecode(t *testing.T) {
+	data := parallelFixture(500)
+
+	sequential := Make()
+	require.NoError(t, sequential.UnmarshalJSON(data))
+	want, err := sequential.MarshalJSON()
+	require.NoError(t, err)
+
+	for _, workers := range []int{2, 3, 8, 64} {
+		t.Run(fmt.Sprintf("with %d workers", workers), func(t *testing.T) {
+			doc := Make(WithParallelDecode(workers), WithParallelThreshold(1))
+			require.True(t, doc.canDecodeParallel(data))
+			require.NoError(t, doc.UnmarshalJSON(data))
+
+			t.Run("should produce the same document as a sequential decoding", func(t *testing.T) {
+				got, err := doc.MarshalJSON()
+				require.NoError(t, err)
+				assert.Equal(t, string(want), string(got))
+				assert.Equal(t, sequential.Len(), doc.Len())
+			})
+
+			t.Run("should report the same decode offsets", func(t *testing.T) {
+				assertSameOffsets(t, *sequential.Node(), *doc.Node())
+			})
+
+			t.Run("should keep values in a single store", func(t *testing.T) {
+				for elem := range doc.Elems() {
+					assert.Equal(t, doc.Store(), elem.Store())
+				}
+			})
+		})
+	}
+
+	t.Run("with a concurrent store", func(t *testing.T) {
+		doc := Make(WithParallelDecode(4), WithParallelThreshold(1), WithStore(store.NewConcurrent()))
+		require.True(t, doc.canDecodeParallel(data))
+		require.NoError(t, doc.UnmarshalJSON(data))
+
+		got, err := doc.MarshalJSON()
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got))
+	})
+
+	t.Run("with an empty array", func(t *testing.T) {
+		doc := Make(WithParallelDecode(4), WithParallelThreshold(1))
+		require.NoError(t, doc.UnmarshalJSON([]byte(` [ ] `)))
+		assert.True(t, doc.IsArray())
+		assert.Zero(t, doc.Len())
+	})
+
+	t.Run("should fall back to sequential decoding", func(t *testing.T) {
+		for _, tc := range []struct {
+			name string
+			doc  Document
+			data []byte
+		}{
+			{name: "when not enabled", doc: Make(WithParallelThreshold(1)), data: data},
+			{name: "with a small input", doc: Make(WithParallelDecode(4)), data: data},
+			{name: "with an object", doc: Make(WithParallelDecode(4), WithParallelThreshold(1)), data: []byte(`{"a":[1,2]}`)},
+			{name: "with a custom lexer", doc: Make(WithParallelDecode(4), WithParallelThreshold(1), WithLexer(nil)), data: data},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.False(t, tc.doc.canDecodeParallel(tc.data))
+			})
+		}
+	})
+
+	t.Run("should report decode errors with their path and offset", func(t *testing.T) {
+		invalid := []byte(`[1, 2, {"a": [true, nul]}, 4]`)
+
+		sequential := Make()
+		seqErr := sequential.UnmarshalJSON(invalid)
+		require.Error(t, seqErr)
+
+		doc := Make(WithParallelDecode(4), WithParallelThreshold(1))
+		err := doc.UnmarshalJSON(invalid)
+		require.Error(t, err)
+
+		var seqDecodeErr, decodeErr *DecodeError
+		require.ErrorAs(t, seqErr, &seqDecodeErr)
+		require.ErrorAs(t, err, &decodeErr)
+		assert.Equal(t, seqDecodeErr.Path.String(), decodeErr.Path.String())
+		assert.Equal(t, seqDecodeErr.ErrContext.Path, decodeErr.ErrContext.Path)
+		assert.Equal(t, seqDecodeErr.ErrContext.Offset, decodeErr.ErrContext.Offset)
+		assert.Equal(t, seqErr.Error(), err.Error())
+	})
+
+	t.Run("should report grammar errors between elements", func(t *testing.T) {
+		for _, invalid := range []string{`[1,,2]`, `[1,2,]`, `[1 2]`, `[1,2`, `[1,2]]`} {
+			t.Run(invalid, func(t *testing.T) {
+				doc := Make(WithParallelDecode(2), WithParallelThreshold(1))
+				require.Error(t, doc.UnmarshalJSON([]byte(invalid)))
+			})
+		}
+	})
+}
+
+func TestParallelElems(t *testing.T) {
+	data := parallelFixture(300)
+
+	sequential := Make()
+	require.NoError(t, sequential.UnmarshalJSON(data))
+
+	t.Run("should yield all elements in order", func(t *testing.T) {
+		var i int
+		for elem, err := range ParallelElems(data, WithParallelDecode(4)) {
+			require.NoError(t, err)
+
+			expected, ok := sequential.Elem(i)
+			require.True(t, ok)
+			assert.Equal(t, expected.String(), elem.String())
+			assert.Equal(t, expected.Context().Offset(), elem.Context().Offset())
+			i++
+		}
+		assert.Equal(t, sequential.Len(), i)
+	})
+
+	t.Run("should stop early", func(t *testing.T) {
+		var i int
+		for _, err := range ParallelElems(data, WithParallelDecode(4)) {
+			require.NoError(t, err)
+			i++
+			if i == 10 {
+				break
+			}
+		}
+		assert.Equal(t, 10, i)
+	})
+
+	t.Run("should yield an error", func(t *testing.T) {
+		t.Run("when the input is not an array", func(t *testing.T) {
+			for _, err := range ParallelElems([]byte(`{}`)) {
+				require.Error(t, err)
+			}
+		})
+
+		t.Run("when an element is invalid", func(t *testing.T) {
+			var (
+				count   int
+				lastErr error
+			)
+			for _, err := range ParallelElems([]byte(`[1,2,{"a":x},4]`), WithParallelDecode(2)) {
+				if err != nil {
+					lastErr = err
+					break
+				}
+				count++
+			}
+			require.Error(t, lastErr)
+			assert.Equal(t, 2, count)
+
+			var decodeErr *DecodeError
+			require.ErrorAs(t, lastErr, &decodeErr)
+			assert.Equal(t, "/2/a", decodeErr.Path.String())
+		})
+	})
+}
+
+func assertSameOffsets(t *testing.T, want, got light.Node) {
+	t.Helper()
+
+	require.Equal(t, want.Context().Offset(), got.Context().Offset())
+	require.Equal(t, want.Len(), got.Len())
+
+	if want.IsObject() {
+		for key, child := range want.Pairs() {
+			other, ok := got.AtInternedKey(key)
+			require.True(t, ok)
+			assertSameOffsets(t, child, other)
+		}
+
+		return
+	}
+
+	for i, child := range want.IndexedElems() {
+		other, ok := got.Elem(i)
+		require.True(t, ok)
+		assertSameOffsets(t, child, other)
+	}
+}
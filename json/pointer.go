@@ -32,6 +32,17 @@ var (
 	)
 )
 
+// EscapeToken escapes a reference token of a JSON [Pointer], as defined by RFC 6901:
+// "~" is escaped as "~0" and "/" as "~1".
+func EscapeToken(token string) string {
+	return pthEscaper.Replace(token)
+}
+
+// UnescapeToken reverts [EscapeToken].
+func UnescapeToken(token string) string {
+	return pthUnescaper.Replace(token)
+}
+
 type pointerError string
 
 func (e pointerError) Error() string {
@@ -112,39 +123,52 @@ func (d Document) getNodePointer(root light.Node, p Pointer) (current light.Node
 	return current, nil
 }
 
-// TODO: delegate to light.Node?
-func (d *Document) setNodePointer(root light.Node, p Pointer, value light.Node) (current light.Node, err error) {
-	current = root
-
-	for _, e := range p {
-		if current.Kind() == nodes.KindObject {
-			if e.kind&pathElemString == 0 {
-				return current, errPointerGotIndex(e.i)
-			}
-
-			n, ok := current.AtInternedKey(e.s)
-			if !ok {
-				return current, errPointerNoKey(e.s)
-			}
+// updateNodePointer applies an update to the node resolved by a [Pointer], and returns the updated root node.
+//
+// Nodes along the path to the updated node are cloned: the original root node is left unaltered.
+func updateNodePointer(b *light.Builder, root light.Node, p Pointer, update func(light.Node) (light.Node, error)) (light.Node, error) {
+	if len(p) == 0 {
+		return update(root)
+	}
 
-			current = n
+	e := p[0]
+	var (
+		position int
+		child    light.Node
+	)
 
-			continue
+	switch {
+	case root.Kind() == nodes.KindObject:
+		if e.kind&pathElemString == 0 {
+			return root, errPointerGotIndex(e.i)
 		}
 
-		if current.Kind() != nodes.KindArray || e.kind&pathElemInt == 0 {
-			return current, errPointerGotKey(e.s)
+		index, ok := root.KeyIndex(e.s.String())
+		if !ok {
+			return root, errPointerNoKey(e.s)
 		}
-
-		n, ok := current.Elem(e.i)
+		position = index
+		child, _ = root.AtInternedKey(e.s)
+	case root.Kind() == nodes.KindArray && e.kind&pathElemInt != 0:
+		elem, ok := root.Elem(e.i)
 		if !ok {
-			return current, errPointerNoIndex(e.i)
+			return root, errPointerNoIndex(e.i)
 		}
+		position = e.i
+		child = elem
+	default:
+		return root, errPointerGotKey(e.s)
+	}
 
-		current = n
+	updated, err := updateNodePointer(b, child, p[1:], update)
+	if err != nil {
+		return root, err
 	}
 
-	return current, nil
+	b.Reset()
+	node := b.From(root).ReplaceChild(position, updated).Node()
+
+	return node, b.Err()
 }
 
 // JSONLookup implements the classical [github.com/go-openapi/jsonpointer.JSONPointable] interface, so users
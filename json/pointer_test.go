@@ -110,4 +110,10 @@ func TestPointer(t *testing.T) {
 			)
 		}
 	})
+	t.Run("should escape and unescape reference tokens", func(t *testing.T) {
+		require.Equal(t, "a~1b~0c", EscapeToken("a/b~c"))
+		require.Equal(t, "a/b~c", UnescapeToken("a~1b~0c"))
+		require.Equal(t, "~01", EscapeToken("~1"))
+		require.Equal(t, "~1", UnescapeToken(EscapeToken("~1")))
+	})
 }
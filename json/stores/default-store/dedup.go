@@ -0,0 +1,230 @@
+package store
+
+import (
+	"bytes"
+	"hash/maphash"
+
+	"github.com/fredbi/core/json/lexers/token"
+	"github.com/fredbi/core/json/stores"
+	"github.com/fredbi/core/json/stores/values"
+)
+
+const defaultDedupMinLength = maxInlineBytes + 2 // shorter values are inlined in the handle and take no room in the arena
+
+// WithDedupMinLength sets the minimum length in bytes of the string and number values deduplicated by a [DedupStore].
+//
+// Shorter values are stored without being looked up. The default is 9 bytes: shorter values are usually packed
+// in the [stores.Handle] itself and do not use any memory in the arena.
+//
+// This option is ignored by other stores.
+func WithDedupMinLength(size int) Option {
+	return func(o options) options {
+		o.dedupMinLength = size
+
+		return o
+	}
+}
+
+// DedupStatistics reports how values have been deduplicated by a [DedupStore].
+type DedupStatistics struct {
+	// Lookups is the number of string and number values looked up for deduplication.
+	Lookups int
+	// Hits is the number of values served from an existing arena slot.
+	Hits int
+	// Unique is the number of distinct values stored in the arena.
+	Unique int
+	// Collisions is the number of values which hash matched a different value (these are stored again).
+	Collisions int
+	// SavedBytes is the number of arena bytes saved by deduplication.
+	SavedBytes int
+}
+
+// HitRatio is the proportion of looked up values which have been deduplicated.
+func (s DedupStatistics) HitRatio() float64 {
+	if s.Lookups == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(s.Lookups)
+}
+
+// DedupStore is a [stores.Store] just like [Store], which stores identical string and number values only once.
+//
+// Documents such as API specifications repeat the same strings many times (descriptions, $ref targets, enum values...).
+// A [DedupStore] hashes the string and number values put into the store, and returns the [stores.Handle] of the value
+// already stored whenever a value is repeated. The memory arena holds a single copy of every distinct value.
+//
+// Only values of at least some minimum length are deduplicated (see [WithDedupMinLength]).
+// [DedupStore.Statistics] reports how values have been deduplicated so far.
+//
+// See also [Store]: the [DedupStore] exposes identical behavior and lifecycle constraints.
+//
+// # Concurrency
+//
+// Like [Store], it is safe to retrieve values concurrently, but it is unsafe to have several go routines storing
+// content concurrently.
+//
+// The forks of a [DedupStore] (see [Store.Fork]), e.g. used for parallel decoding, are regular [Store] s: values
+// merged from a fork are not deduplicated.
+type DedupStore struct {
+	*Store
+
+	seed    maphash.Seed
+	index   map[uint64]stores.Handle
+	stats   DedupStatistics
+	scratch []byte
+	_       struct{}
+}
+
+var _ stores.Store = &DedupStore{} // DedupStore implements [stores.Store]
+
+// NewDedup builds a [DedupStore].
+func NewDedup(opts ...Option) *DedupStore {
+	return &DedupStore{
+		Store: New(opts...),
+		seed:  maphash.MakeSeed(),
+		index: make(map[uint64]stores.Handle),
+	}
+}
+
+// Statistics about the deduplication of values since the [DedupStore] was created or reset.
+func (s *DedupStore) Statistics() DedupStatistics {
+	return s.stats
+}
+
+// PutToken puts a value inside a [token.T] and returns its [stores.Handle] for later retrieval.
+//
+// The [stores.Handle] of an identical value already in the store is returned whenever possible.
+func (s *DedupStore) PutToken(tok token.T) stores.Handle {
+	kind := tok.Kind()
+	if kind != token.Number && kind != token.String && kind != token.Key {
+		return s.Store.PutToken(tok)
+	}
+
+	h, key, indexable := s.lookup(kind, tok.Value())
+	if !h.IsZero() {
+		return h
+	}
+
+	h = s.Store.PutToken(tok)
+	if indexable {
+		s.remember(key, h)
+	}
+
+	return h
+}
+
+// PutValue puts a [values.Value] and returns its [stores.Handle] for later retrieval.
+//
+// The [stores.Handle] of an identical value already in the store is returned whenever possible.
+func (s *DedupStore) PutValue(v values.Value) stores.Handle {
+	var value []byte
+	switch kind := v.Kind(); kind {
+	case token.Number:
+		value = v.NumberValue().Value
+	case token.String, token.Key:
+		value = v.StringValue().Value
+	default:
+		return s.Store.PutValue(v)
+	}
+
+	h, key, indexable := s.lookup(v.Kind(), value)
+	if !h.IsZero() {
+		return h
+	}
+
+	h = s.Store.PutValue(v)
+	if indexable {
+		s.remember(key, h)
+	}
+
+	return h
+}
+
+// Reset the [DedupStore] to its initial state, like [Store.Reset] does.
+//
+// Statistics are reset as well.
+func (s *DedupStore) Reset() {
+	s.Store.Reset()
+	clear(s.index)
+	s.stats = DedupStatistics{}
+	s.scratch = s.scratch[:0]
+}
+
+// UnmarshalBinary restores a [DedupStore] like [Store.UnmarshalBinary] does.
+//
+// The deduplication index is not serialized: values restored this way are not deduplicated against new values.
+func (s *DedupStore) UnmarshalBinary(data []byte) error {
+	clear(s.index)
+	s.stats = DedupStatistics{}
+
+	return s.Store.UnmarshalBinary(data)
+}
+
+// lookup the handle of a value already stored.
+//
+// If the value is not found, it returns the key to remember the handle of the value once stored, when applicable.
+func (s *DedupStore) lookup(kind token.Kind, value []byte) (h stores.Handle, key uint64, indexable bool) {
+	if len(value) < s.dedupMinLength {
+		return stores.HandleZero, 0, false
+	}
+
+	if kind == token.Key {
+		kind = token.String // keys and strings are stored alike
+	}
+
+	s.stats.Lookups++
+	key = s.hash(kind, value)
+
+	h, found := s.index[key]
+	if !found {
+		return stores.HandleZero, key, true
+	}
+
+	if !s.holds(h, kind, value) {
+		// a collision: the value is stored again, but the first one remains indexed
+		s.stats.Collisions++
+
+		return stores.HandleZero, 0, false
+	}
+
+	s.stats.Hits++
+	size, _ := withOffset(h)
+	s.stats.SavedBytes += size
+
+	return h, 0, false
+}
+
+// remember the handle of a newly stored value.
+func (s *DedupStore) remember(key uint64, h stores.Handle) {
+	if !inArena(h) {
+		// inlined values take no room in the arena
+		return
+	}
+
+	s.index[key] = h
+	s.stats.Unique++
+}
+
+func (s *DedupStore) hash(kind token.Kind, value []byte) uint64 {
+	// the kind of value is mixed in: a number and a string with the same text are different values
+	return maphash.Bytes(s.seed, value) ^ uint64(kind)
+}
+
+// holds verifies that the value stored for h is the expected one.
+func (s *DedupStore) holds(h stores.Handle, kind token.Kind, value []byte) bool {
+	var v values.Value
+	v, s.scratch = s.Store.AppendValueBytes(s.scratch[:0], h)
+
+	return v.Kind() == kind && bytes.Equal(v.Bytes(), value)
+}
+
+// inArena tells if a [stores.Handle] points to a value stored in the arena.
+func inArena(h stores.Handle) bool {
+	switch uint8(h & headerMask) {
+	case headerNumber, headerString, headerCompressedString:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/json/lexers/token"
+	"github.com/fredbi/core/json/stores/values"
+	"github.com/fredbi/core/json/types"
+)
+
+func TestDedupStore(t *testing.T) {
+	t.Run("should behave like a Store", testEdgeCases(NewDedup()))
+
+	t.Run("should store repeated strings once", func(t *testing.T) {
+		s := NewDedup()
+		const str = "#/components/schemas/Pet"
+
+		h1 := s.PutToken(token.MakeWithValue(token.String, []byte(str)))
+		l := s.Len()
+		h2 := s.PutToken(token.MakeWithValue(token.String, []byte(str)))
+		h3 := s.PutValue(values.MakeStringValue(str))
+
+		assert.Equal(t, h1, h2)
+		assert.Equal(t, h1, h3)
+		assert.Equal(t, l, s.Len(), "repeated values should not add to arena")
+		assert.Equal(t, str, s.Get(h2).String())
+
+		stats := s.Statistics()
+		assert.Equal(t, 3, stats.Lookups)
+		assert.Equal(t, 2, stats.Hits)
+		assert.Equal(t, 1, stats.Unique)
+		assert.Zero(t, stats.Collisions)
+		assert.Positive(t, stats.SavedBytes)
+		assert.InDelta(t, 2.0/3.0, stats.HitRatio(), 1e-9)
+	})
+
+	t.Run("should store repeated numbers once", func(t *testing.T) {
+		s := NewDedup()
+		const n = "12345678901234567890.12345e-7"
+
+		h1 := s.PutToken(token.MakeWithValue(token.Number, []byte(n)))
+		h2 := s.PutValue(values.MakeNumberValue(types.Number{Value: []byte(n)}))
+
+		assert.Equal(t, h1, h2)
+		assert.Equal(t, 1, s.Statistics().Hits)
+
+		v := s.Get(h2)
+		assert.Equal(t, token.Number, v.Kind())
+		assert.Equal(t, n, string(v.NumberValue().Value))
+	})
+
+	t.Run("should not confuse numbers and strings", func(t *testing.T) {
+		s := NewDedup()
+		const n = "1234567890.0987654321"
+
+		hn := s.PutToken(token.MakeWithValue(token.Number, []byte(n)))
+		hs := s.PutToken(token.MakeWithValue(token.String, []byte(n)))
+
+		assert.NotEqual(t, hn, hs)
+		assert.Equal(t, token.Number, s.Get(hn).Kind())
+		assert.Equal(t, token.String, s.Get(hs).Kind())
+	})
+
+	t.Run("should store repeated compressed strings once", func(t *testing.T) {
+		s := NewDedup(WithEnableCompression(true))
+		str := strings.Repeat("ab", 200)
+
+		h1 := s.PutToken(token.MakeWithValue(token.String, []byte(str)))
+		l := s.Len()
+		h2 := s.PutToken(token.MakeWithValue(token.String, []byte(str)))
+
+		assert.Equal(t, h1, h2)
+		assert.Equal(t, l, s.Len())
+		assert.Equal(t, str, s.Get(h2).String())
+	})
+
+	t.Run("should not look up values shorter than the minimum length", func(t *testing.T) {
+		s := NewDedup(WithDedupMinLength(32))
+		const str = "a medium-sized string"
+
+		_ = s.PutToken(token.MakeWithValue(token.String, []byte(str)))
+		l := s.Len()
+		h := s.PutToken(token.MakeWithValue(token.String, []byte(str)))
+
+		assert.Greater(t, s.Len(), l, "a short value should be stored again")
+		assert.Equal(t, str, s.Get(h).String())
+		assert.Zero(t, s.Statistics().Lookups)
+	})
+
+	t.Run("should reset statistics and index", func(t *testing.T) {
+		s := NewDedup()
+		const str = "a repeated string value"
+
+		_ = s.PutToken(token.MakeWithValue(token.String, []byte(str)))
+		_ = s.PutToken(token.MakeWithValue(token.String, []byte(str)))
+		require.Equal(t, 1, s.Statistics().Hits)
+
+		s.Reset()
+		assert.Equal(t, DedupStatistics{}, s.Statistics())
+		assert.Zero(t, s.Len())
+
+		h := s.PutToken(token.MakeWithValue(token.String, []byte(str)))
+		assert.Equal(t, str, s.Get(h).String())
+		assert.Zero(t, s.Statistics().Hits)
+	})
+}
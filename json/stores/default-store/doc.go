@@ -7,4 +7,7 @@
 //
 // The [VerbatimStore] implements [stores.VerbatimStore]: it allow users keeping non-significant blank space
 // and reconstruct JSON documents verbatim.
+//
+// The [DedupStore] stores identical string and number values only once, which saves memory on large documents
+// with many repeated values.
 package store
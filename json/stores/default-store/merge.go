@@ -0,0 +1,70 @@
+package store
+
+import (
+	"github.com/fredbi/core/json/stores"
+)
+
+// Fork returns a new, empty [Store] configured like s.
+//
+// Values put into a fork are brought back into s with [Store.Merge]. This is how several goroutines may fill
+// a [Store] concurrently without any lock: each goroutine works on its own fork, and forks are merged
+// once done.
+func (s *Store) Fork() *Store {
+	f := &Store{
+		options: s.options,
+	}
+	f.cw = nil // the DEFLATE writer is stateful: a fork builds its own on demand
+	f.arena = make([]byte, 0, f.minArenaSize)
+
+	return f
+}
+
+// Merge appends the values of a fork of s to s and returns the [Relocation] that translates the handles
+// issued by this fork into handles valid for s.
+//
+// The fork must have been obtained with [Store.Fork] (or be configured with the same compression settings
+// as s). It is left untouched and may be discarded after the merge.
+//
+// Merge copies the arena of the fork: the peak memory usage is about twice the size of the merged values.
+func (s *Store) Merge(fork *Store) Relocation {
+	offset := len(s.arena)
+	if len(fork.arena) > 0 {
+		assertOffsetAddressable(offset + len(fork.arena))
+		s.arena = append(s.arena, fork.arena...)
+	}
+
+	return Relocation(offset)
+}
+
+// Merge appends the values of a fork to the [ConcurrentStore]. See [Store.Merge].
+func (s *ConcurrentStore) Merge(fork *Store) Relocation {
+	s.rwx.Lock()
+	defer s.rwx.Unlock()
+
+	return s.Store.Merge(fork)
+}
+
+// Relocation translates the [stores.Handle] s issued by a [Store] that has been merged into another one.
+//
+// It is the offset at which the merged values have been appended.
+type Relocation int
+
+// Handle translates a [stores.Handle] issued by a merged [Store] into a handle valid for the target store.
+//
+// Only values held in the arena are affected: inlined values and constants are returned unchanged.
+func (r Relocation) Handle(h stores.Handle) stores.Handle {
+	if r == 0 {
+		return h
+	}
+
+	switch uint8(h & headerMask) {
+	case headerNumber, headerString, headerCompressedString:
+		size, offset := withOffset(h)
+		offset += int(r)
+		assertOffsetAddressable(offset)
+
+		return (h & headerMask) | stores.Handle(uint64(size)<<headerBits) | stores.Handle(uint64(offset)<<(headerBits+lengthBits)) //nolint:gosec // offsets are positive
+	default:
+		return h
+	}
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/json/stores"
+	"github.com/fredbi/core/json/stores/values"
+	"github.com/fredbi/core/json/types"
+)
+
+type mergeableStore interface {
+	stores.Store
+	Fork() *Store
+	Merge(*Store) Relocation
+}
+
+func TestForkMerge(t *testing.T) {
+	t.Run("with Store", testForkMerge(New()))
+	t.Run("with ConcurrentStore", testForkMerge(NewConcurrent()))
+
+	t.Run("fork keeps the configuration", func(t *testing.T) {
+		s := New(WithEnableCompression(false))
+		fork := s.Fork()
+		require.False(t, fork.enableCompression)
+		require.Zero(t, fork.Len())
+
+		long := values.MakeStringValue(strings.Repeat("x", 200))
+		h := fork.PutValue(long)
+		assert.Equal(t, 200, fork.Len()) // not compressed
+
+		r := s.Merge(fork)
+		assertSameValue(t, long, s.Get(r.Handle(h)))
+	})
+
+	t.Run("relocation leaves inlined values untouched", func(t *testing.T) {
+		s := New()
+		h := s.PutValue(values.MakeStringValue("inline"))
+		assert.Equal(t, h, Relocation(1000).Handle(h))
+		assert.Equal(t, s.PutNull(), Relocation(1000).Handle(s.PutNull()))
+	})
+}
+
+func testForkMerge(s mergeableStore) func(*testing.T) {
+	inputs := []values.Value{
+		values.NullValue,
+		values.TrueValue,
+		values.MakeStringValue("short"),
+		values.MakeStringValue("ascii123"),
+		values.MakeStringValue("a larger string that goes to the arena"),
+		values.MakeStringValue(strings.Repeat("a compressible string. ", 20)),
+		values.MakeIntegerValue(12),
+		values.MakeNumberValue(types.Number{Value: []byte("1234567890123456789012345678901234567890")}),
+	}
+
+	return func(t *testing.T) {
+		// some values already in the target store
+		before := make([]stores.Handle, 0, len(inputs))
+		for _, v := range inputs {
+			before = append(before, s.PutValue(v))
+		}
+
+		// forks are filled concurrently
+		const forks = 4
+		forked := make([]*Store, forks)
+		handles := make([][]stores.Handle, forks)
+		var wg sync.WaitGroup
+		for i := range forks {
+			forked[i] = s.Fork()
+			wg.Go(func() {
+				for _, v := range inputs {
+					handles[i] = append(handles[i], forked[i].PutValue(v))
+				}
+			})
+		}
+		wg.Wait()
+
+		for i, fork := range forked {
+			relocation := s.Merge(fork)
+			for j, h := range handles[i] {
+				assertSameValue(t, inputs[j], s.Get(relocation.Handle(h)))
+			}
+		}
+
+		for j, h := range before {
+			assertSameValue(t, inputs[j], s.Get(h))
+		}
+	}
+}
+
+func assertSameValue(t *testing.T, want, got values.Value) {
+	t.Helper()
+
+	require.Equal(t, want.Kind(), got.Kind())
+	require.Equal(t, want.String(), got.String())
+}
@@ -5,7 +5,7 @@ const (
 	defaultEnableCompression = true
 )
 
-// Option configures a store ([Store], [ConcurrentStore] or [VerbatimStore]).
+// Option configures a store ([Store], [ConcurrentStore], [VerbatimStore] or [DedupStore]).
 //
 // An option threads the configuration value through — it receives a copy and returns it — so a call
 // like
@@ -15,7 +15,7 @@ const (
 // builds and applies the configuration entirely on the stack: no allocation, and the configuration
 // type stays unexported.
 //
-// Pass options to [New], [NewConcurrent], [NewVerbatim] or [BorrowStore]. Those constructors are
+// Pass options to [New], [NewConcurrent], [NewVerbatim], [NewDedup] or [BorrowStore]. Those constructors are
 // variadic for convenience — calling them with no options yields the defaults — and apply the options
 // left to right.
 type Option func(options) options
@@ -26,6 +26,7 @@ type options struct {
 
 	enableCompression bool
 	minArenaSize      int
+	dedupMinLength    int
 }
 
 // WithArenaSize sets the initial capacity of the inner arena that stores large values.
@@ -60,6 +61,7 @@ func WithEnableCompression(enabled bool) Option {
 var defaultStoreOptions = options{ //nolint:gochecknoglobals
 	enableCompression: defaultEnableCompression,
 	minArenaSize:      defaultMinArenaSize,
+	dedupMinLength:    defaultDedupMinLength,
 	compressionOptions: compressionOptions{
 		compressionThreshold: defaultCompressionThreshold,
 		compressionLevel:     defaultCompressionLevel,
@@ -0,0 +1,283 @@
+package json
+
+import (
+	"slices"
+
+	"github.com/fredbi/core/json/nodes/light"
+	"github.com/fredbi/core/json/stores/values"
+)
+
+// WalkAction tells a [Walk] or a [Transform] how to proceed after visiting a node.
+type WalkAction uint8
+
+const (
+	// WalkContinue proceeds with the walk.
+	WalkContinue WalkAction = iota
+
+	// WalkSkip does not descend into the children of the visited node.
+	//
+	// It only makes sense in a pre-order callback: the post-order callback is still called for the skipped node.
+	WalkSkip
+
+	// WalkStop stops the walk. No other node is visited.
+	WalkStop
+)
+
+// WalkFunc is a callback invoked by [Walk] or [Transform] for every visited node.
+//
+// A non-nil error stops the walk and is returned to the caller.
+type WalkFunc func(v Visit) (WalkAction, error)
+
+// Visit describes a node visited by [Walk] or [Transform].
+type Visit struct {
+	// Node is the visited node, as a [Document].
+	//
+	// In a post-order callback of a [Transform], the children of the node are already transformed.
+	Node Document
+
+	// Pointer is the JSON [Pointer] to the visited node, from the root of the walked [Document].
+	//
+	// The [Pointer] is reused during the walk: use [Pointer.Clone] to retain it after the callback returns.
+	Pointer Pointer
+
+	// Depth is 0 for the root of the walked [Document], 1 for its members or elements, and so on.
+	Depth int
+
+	// Key is the key of an object member, or the empty string.
+	Key string
+
+	// Index is the position of an array element, or -1.
+	Index int
+
+	transform *transformState
+}
+
+// HasKey tells if the visited node is an object member.
+func (v Visit) HasKey() bool {
+	return v.Depth > 0 && v.Index < 0
+}
+
+// IsElem tells if the visited node is an array element.
+func (v Visit) IsElem() bool {
+	return v.Index >= 0
+}
+
+// Replace the visited node by another [Document], when running a [Transform].
+//
+// A node replaced by a pre-order callback is not walked into: the post-order callback is called
+// with the replacement.
+//
+// The replacement should share the [stores.Store] of the transformed [Document].
+//
+// Replace has no effect with [Walk].
+func (v Visit) Replace(d Document) {
+	if v.transform == nil {
+		return
+	}
+
+	v.transform.replacement = d.root
+	v.transform.replaced = true
+}
+
+// Walk visits all the nodes of a [Document], depth-first.
+//
+// The pre callback is called before visiting the children of a node (pre-order), and the post callback
+// after visiting them (post-order). Either callback may be nil.
+//
+// Object members and array elements are visited in order.
+//
+// This is an alternative to recursing over [Document.Pairs] and [Document.Elems], which keeps track of the
+// JSON [Pointer] to every visited node.
+func Walk(d Document, pre, post WalkFunc) error {
+	w := walker{
+		doc:     d,
+		pre:     pre,
+		post:    post,
+		pointer: make(Pointer, 0, defaultWalkDepth),
+	}
+
+	_, err := w.walk(d.root, values.InternedKey{}, -1)
+
+	return err
+}
+
+// Transform walks a [Document] like [Walk] does, and returns a new [Document] in which the nodes
+// replaced by the callbacks (see [Visit.Replace]) are substituted.
+//
+// The original [Document] is not altered. The transformed [Document] is a copy-on-write clone:
+// it shares all unchanged nodes with the original, and only the containers on the path to a replaced node
+// are copied, each at most once whatever the number of replaced children.
+//
+// A [Transform] interrupted by [WalkStop] returns the nodes transformed so far.
+// On error, the original [Document] is returned.
+func Transform(d Document, pre, post WalkFunc) (Document, error) {
+	w := walker{
+		doc:       d,
+		pre:       pre,
+		post:      post,
+		pointer:   make(Pointer, 0, defaultWalkDepth),
+		transform: &transformState{},
+	}
+
+	result, err := w.walk(d.root, values.InternedKey{}, -1)
+	if err != nil {
+		return d, err
+	}
+
+	if !result.changed {
+		return d, nil
+	}
+
+	return d.fromNode(result.node), nil
+}
+
+// Clone the [Pointer].
+func (p Pointer) Clone() Pointer {
+	return slices.Clone(p)
+}
+
+const defaultWalkDepth = 16
+
+type transformState struct {
+	replacement light.Node
+	replaced    bool
+}
+
+type walker struct {
+	doc       Document
+	pre       WalkFunc
+	post      WalkFunc
+	pointer   Pointer
+	transform *transformState
+}
+
+type walkResult struct {
+	node    light.Node
+	changed bool
+	stop    bool
+}
+
+// walk a node and its children, and returns the possibly transformed node.
+func (w *walker) walk(n light.Node, key values.InternedKey, index int) (walkResult, error) {
+	result := walkResult{node: n}
+	visit := Visit{
+		Node:      w.doc.fromNode(n),
+		Pointer:   w.pointer,
+		Depth:     len(w.pointer),
+		Index:     index,
+		transform: w.transform,
+	}
+	if index < 0 && visit.Depth > 0 {
+		visit.Key = key.String()
+	}
+
+	descend := true
+	if w.pre != nil {
+		action, err := w.call(w.pre, visit, &result)
+		if err != nil || action == WalkStop {
+			result.stop = true
+
+			return result, err
+		}
+
+		// a replaced node is not walked into
+		descend = action != WalkSkip && !result.changed
+	}
+
+	if descend {
+		children, err := w.walkChildren(result.node)
+		if children.changed {
+			result.node = children.node
+			result.changed = true
+		}
+
+		if err != nil || children.stop {
+			result.stop = true
+
+			return result, err
+		}
+	}
+
+	if w.post == nil {
+		return result, nil
+	}
+
+	visit.Node = w.doc.fromNode(result.node)
+	action, err := w.call(w.post, visit, &result)
+	result.stop = err != nil || action == WalkStop
+
+	return result, err
+}
+
+// walkChildren walks the members of an object or the elements of an array.
+//
+// The container is cloned on the first replaced child.
+func (w *walker) walkChildren(n light.Node) (walkResult, error) {
+	var (
+		b      *light.Builder
+		result walkResult
+		err    error
+	)
+
+	child := func(position int, node light.Node, key values.InternedKey, index int, elem stringOrInt) bool {
+		w.pointer = append(w.pointer, elem)
+		result, err = w.walk(node, key, index)
+		w.pointer = w.pointer[:len(w.pointer)-1]
+
+		if result.changed {
+			if b == nil {
+				b = light.NewBuilder(w.doc.store).From(n)
+			}
+			b.ReplaceChild(position, result.node)
+		}
+
+		return err == nil && !result.stop
+	}
+
+	switch {
+	case n.IsObject():
+		position := 0
+		for key, member := range n.Pairs() {
+			if !child(position, member, key, -1, stringOrInt{kind: pathElemString, s: key}) {
+				break
+			}
+			position++
+		}
+	case n.IsArray():
+		for i, elem := range n.IndexedElems() {
+			if !child(i, elem, values.InternedKey{}, i, stringOrInt{kind: pathElemInt, i: i}) {
+				break
+			}
+		}
+	}
+
+	stop := err != nil || result.stop
+	if b == nil {
+		return walkResult{node: n, stop: stop}, err
+	}
+
+	if !b.Ok() {
+		return walkResult{node: n, stop: true}, b.Err()
+	}
+
+	return walkResult{node: b.Node(), changed: true, stop: stop}, err
+}
+
+// call a callback and collects any replacement of the visited node.
+func (w *walker) call(fn WalkFunc, visit Visit, result *walkResult) (WalkAction, error) {
+	if w.transform != nil {
+		*w.transform = transformState{}
+	}
+
+	action, err := fn(visit)
+	if err != nil {
+		return WalkStop, err
+	}
+
+	if w.transform != nil && w.transform.replaced {
+		result.node = w.transform.replacement
+		result.changed = true
+	}
+
+	return action, nil
+}
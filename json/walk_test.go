@@ -0,0 +1,296 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalk(t *testing.T) {
+	const jazon = `{"a":[1,{"b":true}],"c":"x","d~/":{}}`
+
+	t.Run("should visit nodes in pre-order and post-order", func(t *testing.T) {
+		d := mustDoc(t, jazon)
+		var pre, post []string
+
+		require.NoError(t, Walk(d,
+			func(v Visit) (WalkAction, error) {
+				pre = append(pre, v.Pointer.String())
+
+				return WalkContinue, nil
+			},
+			func(v Visit) (WalkAction, error) {
+				post = append(post, v.Pointer.String())
+
+				return WalkContinue, nil
+			},
+		))
+
+		assert.Equal(t, []string{"", "/a", "/a/0", "/a/1", "/a/1/b", "/c", "/d~0~1"}, pre)
+		assert.Equal(t, []string{"/a/0", "/a/1/b", "/a/1", "/a", "/c", "/d~0~1", ""}, post)
+	})
+
+	t.Run("should describe visited nodes", func(t *testing.T) {
+		d := mustDoc(t, jazon)
+		visits := make(map[string]Visit)
+
+		require.NoError(t, Walk(d, func(v Visit) (WalkAction, error) {
+			v.Pointer = v.Pointer.Clone()
+			visits[v.Pointer.String()] = v
+
+			return WalkContinue, nil
+		}, nil))
+
+		root := visits[""]
+		assert.Zero(t, root.Depth)
+		assert.False(t, root.HasKey())
+		assert.False(t, root.IsElem())
+
+		member := visits["/a/1/b"]
+		assert.Equal(t, 3, member.Depth)
+		assert.True(t, member.HasKey())
+		assert.Equal(t, "b", member.Key)
+		assert.Equal(t, -1, member.Index)
+		assert.True(t, member.Node.IsBool())
+
+		elem := visits["/a/1"]
+		assert.Equal(t, 2, elem.Depth)
+		assert.True(t, elem.IsElem())
+		assert.Equal(t, 1, elem.Index)
+		assert.Empty(t, elem.Key)
+
+		for pointer, v := range visits {
+			resolved, err := d.GetPointer(v.Pointer)
+			require.NoErrorf(t, err, "pointer %q should resolve", pointer)
+
+			want, err := v.Node.MarshalJSON()
+			require.NoError(t, err)
+			got, err := resolved.MarshalJSON()
+			require.NoError(t, err)
+			assert.JSONEqf(t, string(want), string(got), "pointer %q should resolve the visited node", pointer)
+		}
+	})
+
+	t.Run("should skip subtrees", func(t *testing.T) {
+		d := mustDoc(t, jazon)
+		var pre, post []string
+
+		require.NoError(t, Walk(d,
+			func(v Visit) (WalkAction, error) {
+				pre = append(pre, v.Pointer.String())
+				if v.Key == "a" {
+					return WalkSkip, nil
+				}
+
+				return WalkContinue, nil
+			},
+			func(v Visit) (WalkAction, error) {
+				post = append(post, v.Pointer.String())
+
+				return WalkContinue, nil
+			},
+		))
+
+		assert.Equal(t, []string{"", "/a", "/c", "/d~0~1"}, pre)
+		assert.Equal(t, []string{"/a", "/c", "/d~0~1", ""}, post)
+	})
+
+	t.Run("should stop early", func(t *testing.T) {
+		d := mustDoc(t, jazon)
+		var visited []string
+
+		require.NoError(t, Walk(d, func(v Visit) (WalkAction, error) {
+			visited = append(visited, v.Pointer.String())
+			if v.Node.IsBool() {
+				return WalkStop, nil
+			}
+
+			return WalkContinue, nil
+		}, nil))
+
+		assert.Equal(t, []string{"", "/a", "/a/0", "/a/1", "/a/1/b"}, visited)
+	})
+
+	t.Run("should stop on error", func(t *testing.T) {
+		d := mustDoc(t, jazon)
+		errTest := errors.New("test")
+		var visited int
+
+		err := Walk(d, nil, func(v Visit) (WalkAction, error) {
+			visited++
+			if v.IsElem() {
+				return WalkContinue, fmt.Errorf("at %v: %w", v.Pointer, errTest)
+			}
+
+			return WalkContinue, nil
+		})
+
+		require.ErrorIs(t, err, errTest)
+		assert.Equal(t, 1, visited)
+	})
+
+	t.Run("should ignore replacements", func(t *testing.T) {
+		d := mustDoc(t, jazon)
+		b := NewBuilder(d.Store())
+
+		require.NoError(t, Walk(d, func(v Visit) (WalkAction, error) {
+			v.Replace(b.MakeNull())
+
+			return WalkContinue, nil
+		}, nil))
+
+		output, err := d.MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, jazon, string(output))
+	})
+}
+
+func TestTransform(t *testing.T) {
+	const jazon = `{"a":[1,{"b":"y"}],"c":"x","d":{"e":[true,false]}}`
+
+	upper := func(d Document) func(Visit) (WalkAction, error) {
+		b := NewBuilder(d.Store())
+
+		return func(v Visit) (WalkAction, error) {
+			if !v.Node.IsString() {
+				return WalkContinue, nil
+			}
+
+			value, _ := v.Node.Value()
+			v.Replace(b.MakeString(strings.ToUpper(value.String())))
+
+			return WalkContinue, nil
+		}
+	}
+
+	t.Run("should replace nodes in pre-order", func(t *testing.T) {
+		d := mustDoc(t, jazon)
+
+		transformed, err := Transform(d, upper(d), nil)
+		require.NoError(t, err)
+
+		output, err := transformed.MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"a":[1,{"b":"Y"}],"c":"X","d":{"e":[true,false]}}`, string(output))
+
+		t.Run("original should be unaltered", func(t *testing.T) {
+			original, err := d.MarshalJSON()
+			require.NoError(t, err)
+			assert.JSONEq(t, jazon, string(original))
+		})
+
+		t.Run("unchanged subtrees should be shared", func(t *testing.T) {
+			before, ok := d.AtKey("d")
+			require.True(t, ok)
+			after, ok := transformed.AtKey("d")
+			require.True(t, ok)
+
+			assert.Equal(t, before.Node(), after.Node())
+		})
+	})
+
+	t.Run("should replace nodes in post-order", func(t *testing.T) {
+		d := mustDoc(t, jazon)
+		b := NewBuilder(d.Store())
+		var seen string
+
+		transformed, err := Transform(d, upper(d), func(v Visit) (WalkAction, error) {
+			if v.Key != "a" {
+				return WalkContinue, nil
+			}
+
+			// children are already transformed
+			output, err := v.Node.MarshalJSON()
+			if err != nil {
+				return WalkStop, err
+			}
+			seen = string(output)
+			v.Replace(b.MakeNumber(len(seen)))
+
+			return WalkContinue, nil
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `[1,{"b":"Y"}]`, seen)
+
+		output, err := transformed.MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, fmt.Sprintf(`{"a":%d,"c":"X","d":{"e":[true,false]}}`, len(seen)), string(output))
+	})
+
+	t.Run("should not walk into a replaced node", func(t *testing.T) {
+		d := mustDoc(t, jazon)
+		b := NewBuilder(d.Store())
+		var visited []string
+
+		transformed, err := Transform(d, func(v Visit) (WalkAction, error) {
+			visited = append(visited, v.Pointer.String())
+			if v.Key == "d" {
+				v.Replace(b.Array().AppendElem(b.MakeString("z")).Document())
+			}
+
+			return WalkContinue, nil
+		}, nil)
+		require.NoError(t, err)
+		assert.NotContains(t, visited, "/d/e")
+
+		output, err := transformed.MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"a":[1,{"b":"y"}],"c":"x","d":["z"]}`, string(output))
+	})
+
+	t.Run("should replace the root", func(t *testing.T) {
+		d := mustDoc(t, jazon)
+		b := NewBuilder(d.Store())
+
+		transformed, err := Transform(d, nil, func(v Visit) (WalkAction, error) {
+			if v.Depth == 0 {
+				v.Replace(b.MakeBool(true))
+			}
+
+			return WalkContinue, nil
+		})
+		require.NoError(t, err)
+		assert.True(t, transformed.IsBool())
+	})
+
+	t.Run("should keep the nodes transformed before stopping", func(t *testing.T) {
+		d := mustDoc(t, jazon)
+		replace := upper(d)
+
+		transformed, err := Transform(d, func(v Visit) (WalkAction, error) {
+			if v.Key == "c" {
+				return WalkStop, nil
+			}
+
+			return replace(v)
+		}, nil)
+		require.NoError(t, err)
+
+		output, err := transformed.MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"a":[1,{"b":"Y"}],"c":"x","d":{"e":[true,false]}}`, string(output))
+	})
+
+	t.Run("should return the original document on error", func(t *testing.T) {
+		d := mustDoc(t, jazon)
+		errTest := errors.New("test")
+		replace := upper(d)
+
+		transformed, err := Transform(d, func(v Visit) (WalkAction, error) {
+			if v.Key == "d" {
+				return WalkContinue, errTest
+			}
+
+			return replace(v)
+		}, nil)
+		require.ErrorIs(t, err, errTest)
+
+		output, err := transformed.MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, jazon, string(output))
+	})
+}
@@ -0,0 +1,115 @@
+package canonical
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
+)
+
+// Analyzer builds the canonical AST of [jsonschema.Schema] s.
+type Analyzer struct {
+	*options
+}
+
+// New canonical [Analyzer].
+func New(opts ...Option) *Analyzer {
+	return &Analyzer{
+		options: optionsWithDefaults(opts),
+	}
+}
+
+// Analyze a [jsonschema.Schema] and build its canonical [ast.Tree].
+//
+// References are resolved: the analysis fails with an error wrapping [ErrCanonical] whenever
+// a reference may not be resolved.
+func (a *Analyzer) Analyze(sch jsonschema.Schema) (ast.Tree, error) {
+	c := a.canonicalizer(sch.UsesDollarData())
+	if err := c.add(a.baseURI, sch); err != nil {
+		return ast.Tree{}, err
+	}
+
+	root, err := c.document(a.baseURI, sch)
+	if err != nil {
+		return ast.Tree{}, err
+	}
+
+	return ast.NewTree(root), nil
+}
+
+// AnalyzeCollection analyzes all the [jsonschema.Schema] s in a [jsonschema.Collection] and builds
+// a canonical [ast.Forest].
+//
+// Schemas in the collection may refer to each other, using their "$id".
+// Equivalent subschemas across the collection are found with [ast.Forest.Duplicates].
+func (a *Analyzer) AnalyzeCollection(collection jsonschema.Collection) (ast.Forest, error) {
+	trees := make([]ast.Tree, 0, collection.Len())
+	uris := make([]string, 0, collection.Len())
+	c := a.canonicalizer(false)
+
+	for i := range collection.Len() {
+		uri, err := a.documentURI(i)
+		if err != nil {
+			return ast.Forest{}, err
+		}
+
+		if err := c.add(uri, collection.Schema(i)); err != nil {
+			return ast.Forest{}, err
+		}
+		uris = append(uris, uri)
+	}
+
+	for i, uri := range uris {
+		sch := collection.Schema(i)
+		c.dollarData = sch.UsesDollarData()
+
+		root, err := c.document(uri, sch)
+		if err != nil {
+			return ast.Forest{}, err
+		}
+
+		trees = append(trees, ast.NewTree(root))
+	}
+
+	return ast.NewForest(trees...), nil
+}
+
+// Hash computes the [SchemaHash] of a [jsonschema.Schema].
+func (a *Analyzer) Hash(sch jsonschema.Schema) (SchemaHash, error) {
+	tree, err := a.Analyze(sch)
+	if err != nil {
+		return SchemaHash{}, err
+	}
+
+	return tree.Root().Hash(), nil
+}
+
+func (a *Analyzer) canonicalizer(dollarData bool) *canonicalizer {
+	resolver := a.resolver
+	if resolver == nil {
+		resolver = jsonschema.NewResolver()
+	}
+
+	return &canonicalizer{
+		options:    a.options,
+		resolver:   resolver,
+		nodes:      make(map[string]*ast.Node),
+		dollarData: dollarData,
+	}
+}
+
+// documentURI locates the schema at index i in a collection.
+func (a *Analyzer) documentURI(i int) (string, error) {
+	name := fmt.Sprintf("schema-%d.json", i)
+	if a.baseURI == "" {
+		return name, nil
+	}
+
+	base, err := url.Parse(a.baseURI)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URI %q: %w: %w", a.baseURI, err, ErrCanonical)
+	}
+
+	return base.ResolveReference(&url.URL{Path: name}).String(), nil
+}
@@ -0,0 +1,337 @@
+package canonical
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
+)
+
+func TestHash(t *testing.T) {
+	t.Run("should hash equivalent schemas alike", func(t *testing.T) {
+		for _, tc := range []struct {
+			name string
+			a, b string
+		}{
+			{
+				name: "ordering of keywords",
+				a:    `{"type": "string", "maxLength": 10}`,
+				b:    `{"maxLength": 10, "type": "string"}`,
+			},
+			{
+				name: "ordering of values in sets",
+				a:    `{"type": "object", "required": ["a", "b"], "enum": [{"x": 1, "y": 2}, 3]}`,
+				b:    `{"type": "object", "required": ["b", "a", "b"], "enum": [3, {"y": 2, "x": 1.0}]}`,
+			},
+			{
+				name: "const and single-valued enum",
+				a:    `{"const": "a"}`,
+				b:    `{"enum": ["a"]}`,
+			},
+			{
+				name: "multiple types",
+				a:    `{"type": ["string", "null"], "maxLength": 3}`,
+				b:    `{"anyOf": [{"type": "null"}, {"type": "string", "maxLength": 3}]}`,
+			},
+			{
+				name: "redundant integer type",
+				a:    `{"type": ["integer", "number"]}`,
+				b:    `{"type": "number"}`,
+			},
+			{
+				name: "keywords that don't apply to the type",
+				a:    `{"type": "string", "minimum": 1, "properties": {"a": true}}`,
+				b:    `{"type": "string"}`,
+			},
+			{
+				name: "ordering and nesting of compositions",
+				a:    `{"allOf": [{"minimum": 1}, {"allOf": [{"maximum": 2}, true]}]}`,
+				b:    `{"allOf": [{"maximum": 2}, {"minimum": 1}]}`,
+			},
+			{
+				name: "single member composition",
+				a:    `{"oneOf": [{"type": "string"}]}`,
+				b:    `{"type": "string"}`,
+			},
+			{
+				name: "empty schema",
+				a:    `{"$comment": "anything", "$defs": {"unused": false}}`,
+				b:    `true`,
+			},
+			{
+				name: "draft 4 exclusive bound",
+				a:    `{"$schema": "http://json-schema.org/draft-04/schema#", "maximum": 10, "exclusiveMaximum": true}`,
+				b:    `{"exclusiveMaximum": 10}`,
+			},
+			{
+				name: "legacy array items",
+				a:    `{"$schema": "http://json-schema.org/draft-07/schema#", "items": [{"type": "string"}], "additionalItems": false}`,
+				b:    `{"prefixItems": [{"type": "string"}], "items": false}`,
+			},
+			{
+				name: "legacy dependencies",
+				a:    `{"dependencies": {"a": ["b"], "c": {"required": ["d"]}}}`,
+				b:    `{"dependentRequired": {"a": ["b"]}, "dependentSchemas": {"c": {"required": ["d"]}}}`,
+			},
+			{
+				name: "references to equivalent schemas",
+				a:    `{"properties": {"a": {"$ref": "#/$defs/A"}}, "$defs": {"A": {"type": "string"}}}`,
+				b:    `{"properties": {"a": {"$ref": "#/definitions/B"}}, "definitions": {"B": {"type": "string"}}}`,
+			},
+			{
+				name: "equivalent recursive schemas",
+				a:    `{"type": "object", "properties": {"next": {"$ref": "#"}}}`,
+				b:    `{"$ref": "#/$defs/node", "$defs": {"node": {"type": "object", "properties": {"next": {"$ref": "#/$defs/node"}}}}}`,
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				a := New()
+				hashA, err := a.Hash(mustSchema(t, tc.a))
+				require.NoError(t, err)
+				hashB, err := a.Hash(mustSchema(t, tc.b))
+				require.NoError(t, err)
+
+				assert.Equal(t, hashA, hashB)
+				assert.False(t, hashA.IsZero())
+			})
+		}
+	})
+
+	t.Run("should hash different schemas differently", func(t *testing.T) {
+		for _, tc := range []struct {
+			name string
+			a, b string
+		}{
+			{
+				name: "different bounds",
+				a:    `{"type": "string", "maxLength": 10}`,
+				b:    `{"type": "string", "maxLength": 11}`,
+			},
+			{
+				name: "ordered tuples",
+				a:    `{"prefixItems": [{"type": "string"}, {"type": "number"}]}`,
+				b:    `{"prefixItems": [{"type": "number"}, {"type": "string"}]}`,
+			},
+			{
+				name: "different annotations",
+				a:    `{"type": "string", "description": "a"}`,
+				b:    `{"type": "string", "description": "b"}`,
+			},
+			{
+				name: "duplicate oneOf members",
+				a:    `{"oneOf": [{"type": "string"}, {"type": "string"}]}`,
+				b:    `{"oneOf": [{"type": "string"}]}`,
+			},
+			{
+				name: "different recursive schemas",
+				a:    `{"type": "object", "properties": {"next": {"$ref": "#"}}}`,
+				b:    `{"type": "object", "properties": {"next": {"type": "object"}}}`,
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				a := New()
+				hashA, err := a.Hash(mustSchema(t, tc.a))
+				require.NoError(t, err)
+				hashB, err := a.Hash(mustSchema(t, tc.b))
+				require.NoError(t, err)
+
+				assert.NotEqual(t, hashA, hashB)
+			})
+		}
+	})
+
+	t.Run("should ignore annotations", func(t *testing.T) {
+		a := New(WithIgnoreAnnotations(true))
+		hashA, err := a.Hash(mustSchema(t, `{"type": "string", "title": "A", "x-go-name": "A"}`))
+		require.NoError(t, err)
+		hashB, err := a.Hash(mustSchema(t, `{"type": "string", "description": "B"}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, hashA, hashB)
+	})
+
+	t.Run("should ignore keywords next to a $ref prior to draft 2019", func(t *testing.T) {
+		a := New()
+		hashA, err := a.Hash(mustSchema(t,
+			`{"$schema": "http://json-schema.org/draft-07/schema#", "$ref": "#/definitions/A", "maxLength": 1, "definitions": {"A": {"type": "string"}}}`,
+		))
+		require.NoError(t, err)
+		hashB, err := a.Hash(mustSchema(t, `{"type": "string"}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, hashA, hashB)
+	})
+}
+
+func TestAnalyze(t *testing.T) {
+	t.Run("should build a canonical tree", func(t *testing.T) {
+		tree, err := New(WithBaseURI("https://example.com/root.json")).Analyze(mustSchema(t, `{
+			"type": ["object", "null"],
+			"properties": {
+				"a": {"$ref": "#/$defs/name"},
+				"b": {"$ref": "#/$defs/name"},
+				"c": {"type": "string", "maxLength": 10}
+			},
+			"$defs": {"name": {"maxLength": 10, "type": "string"}}
+		}`))
+		require.NoError(t, err)
+
+		root := tree.Root()
+		require.NotNil(t, root)
+		assert.Equal(t, "https://example.com/root.json#", root.ID())
+		assert.Equal(t, ast.KindSchema, root.Kind())
+		assert.Empty(t, root.Type())
+
+		var branches []string
+		for edge := range root.Children() {
+			require.Equal(t, "anyOf", edge.Keyword)
+			branches = append(branches, edge.Node.Type())
+		}
+		assert.ElementsMatch(t, []string{"null", "object"}, branches)
+
+		object, ok := tree.Node("https://example.com/root.json#/type/object")
+		require.True(t, ok)
+
+		properties := make(map[string]*ast.Node)
+		for edge := range object.Children() {
+			assert.Equal(t, "properties", edge.Keyword)
+			properties[edge.Key] = edge.Node
+		}
+		require.Len(t, properties, 3)
+		assert.Equal(t, ast.KindRef, properties["a"].Kind())
+		assert.Equal(t, "https://example.com/root.json#/$defs/name", properties["a"].Target().ID())
+		assert.Same(t, properties["a"].Target(), properties["b"].Target())
+
+		keyword, ok := properties["c"].Keyword("maxLength")
+		require.True(t, ok)
+		assert.JSONEq(t, `10`, string(keyword.Value))
+
+		var duplicates [][]string
+		for group := range tree.Duplicates() {
+			var ids []string
+			for _, n := range group {
+				ids = append(ids, n.ID())
+			}
+			duplicates = append(duplicates, ids)
+		}
+		assert.Equal(t, [][]string{{
+			"https://example.com/root.json#/$defs/name",
+			"https://example.com/root.json#/properties/c",
+		}}, duplicates)
+	})
+
+	t.Run("should keep $data references as dynamic keywords", func(t *testing.T) {
+		sch := jsonschema.Make(jsonschema.WithDollarData(true))
+		require.NoError(t, sch.UnmarshalJSON([]byte(`{"type": "number", "maximum": {"$data": "1/max"}, "enum": {"$data": "1/choices"}}`)))
+
+		tree, err := New().Analyze(sch)
+		require.NoError(t, err)
+
+		for _, name := range []string{"maximum", "enum"} {
+			keyword, ok := tree.Root().Keyword(name)
+			require.Truef(t, ok, "expected keyword %q", name)
+			assert.True(t, keyword.Dynamic)
+		}
+	})
+
+	t.Run("should fail on unresolved references", func(t *testing.T) {
+		_, err := New().Analyze(mustSchema(t, `{"$ref": "#/$defs/missing"}`))
+		require.ErrorIs(t, err, ErrCanonical)
+	})
+}
+
+func TestAnalyzeCollection(t *testing.T) {
+	collection := jsonschema.MakeCollection(2)
+	collection.Append(mustSchema(t, `{
+		"$id": "https://example.com/pet.json",
+		"type": "object",
+		"properties": {"name": {"type": "string", "minLength": 1}}
+	}`))
+	collection.Append(mustSchema(t, `{
+		"type": "object",
+		"properties": {
+			"pet": {"$ref": "https://example.com/pet.json"},
+			"owner": {"minLength": 1, "type": "string"}
+		}
+	}`))
+
+	forest, err := New().AnalyzeCollection(collection)
+	require.NoError(t, err)
+	require.Equal(t, 2, forest.Len())
+
+	pet := forest.Tree(0).Root()
+	owner, ok := forest.Tree(1).Node("schema-1.json#/properties/owner")
+	require.True(t, ok)
+
+	name, ok := forest.Tree(0).Node("schema-0.json#/properties/name")
+	require.True(t, ok)
+	assert.Equal(t, name.Hash(), owner.Hash())
+	assert.Contains(t, forest.Lookup(pet.Hash()), pet)
+
+	var found bool
+	for group := range forest.Duplicates() {
+		if assert.NotEmpty(t, group) && group[0].Hash() == name.Hash() {
+			found = true
+			assert.Contains(t, group, owner)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestRender(t *testing.T) {
+	t.Run("should render equivalent schemas alike", func(t *testing.T) {
+		render := func(jazon string) string {
+			tree, err := New().Analyze(mustSchema(t, jazon))
+			require.NoError(t, err)
+
+			sch, err := Render(tree)
+			require.NoError(t, err)
+
+			data, err := sch.MarshalJSON()
+			require.NoError(t, err)
+
+			return string(data)
+		}
+
+		a := render(`{"type": "object", "properties": {"next": {"$ref": "#/$defs/node"}, "value": {"const": 1.0}}, "$defs": {"node": {"type": ["string", "null"]}}}`)
+		b := render(`{"properties": {"value": {"enum": [1]}, "next": {"$ref": "#/definitions/other"}}, "type": "object", "definitions": {"other": {"anyOf": [{"type": "null"}, {"type": "string"}]}}}`)
+		assert.Equal(t, a, b)
+
+		sch := mustSchema(t, a)
+		assert.Equal(t, jsonschema.VersionDraft2020, sch.Version())
+
+		hashA, err := New().Hash(sch)
+		require.NoError(t, err)
+		hashB, err := New().Hash(mustSchema(t, b))
+		require.NoError(t, err)
+		assert.Equal(t, hashA, hashB)
+	})
+
+	t.Run("should render recursive schemas", func(t *testing.T) {
+		tree, err := New().Analyze(mustSchema(t, `{"type": "object", "properties": {"next": {"$ref": "#"}}}`))
+		require.NoError(t, err)
+
+		sch, err := Render(tree)
+		require.NoError(t, err)
+
+		data, err := sch.MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type": "object",
+			"properties": {"next": {"$ref": "#"}}
+		}`, string(data))
+	})
+}
+
+func mustSchema(t *testing.T, input string) jsonschema.Schema {
+	t.Helper()
+
+	s := jsonschema.Make()
+	require.NoError(t, s.UnmarshalJSON([]byte(input)))
+
+	return s
+}
@@ -1,13 +1,202 @@
+// Package ast defines the canonical abstract syntax tree of a JSON schema.
+//
+// A canonical [Node] is dialect-independent: it holds at most a single type, a sorted set of [Keyword] s
+// with canonical JSON values and a sorted set of [Edge] s to its subschemas. "$ref" s are resolved as [KindRef]
+// nodes linked to their target, so the [Tree] of a schema is actually a graph, possibly with cycles.
+//
+// Every node of a [Tree] is identified by a stable ID, i.e. the location of the schema it originates from,
+// and by a content [Hash]: nodes with the same [Hash] are equivalent schemas.
 package ast
 
-type Tree struct {
-	root Node
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// Kind of canonical [Node].
+type Kind uint8
+
+const (
+	// KindTrue is the schema that accepts any value, e.g. true or {}.
+	KindTrue Kind = iota
+	// KindFalse is the schema that rejects any value.
+	KindFalse
+	// KindSchema is a schema object.
+	KindSchema
+	// KindRef is a reference to another [Node].
+	KindRef
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindTrue:
+		return "true"
+	case KindFalse:
+		return "false"
+	case KindSchema:
+		return "schema"
+	case KindRef:
+		return "ref"
+	default:
+		return "unknown"
+	}
+}
+
+// Keyword is a keyword of a canonical [Node] which value is not a schema, e.g. "maxLength" or "enum".
+type Keyword struct {
+	// Name of the keyword
+	Name string
+
+	// Value of the keyword as canonical JSON, i.e. compact, with sorted object keys and normalized numbers.
+	Value []byte
+
+	// Dynamic is true when the value is a "$data" reference, only known at validation time.
+	Dynamic bool
 }
 
-type Forest struct {
-	roots []Node
+// Edge from a [Node] to one of its subschemas.
+type Edge struct {
+	// Keyword holding the subschema, e.g. "properties" or "allOf"
+	Keyword string
+
+	// Key of the subschema in a map of schemas, e.g. the name of a property
+	Key string
+
+	// Index of the subschema in an ordered array of schemas, i.e. "prefixItems"
+	Index int
+
+	// Node of the subschema
+	Node *Node
 }
 
+// Node of a canonical [Tree].
 type Node struct {
-	children []Node
+	id       string
+	kind     Kind
+	typ      string
+	keywords []Keyword
+	children []Edge
+	target   *Node
+	hash     Hash
+	hashed   bool
+}
+
+// NewBool builds a [Node] for the true or false schema.
+func NewBool(id string, value bool) *Node {
+	if value {
+		return &Node{id: id, kind: KindTrue}
+	}
+
+	return &Node{id: id, kind: KindFalse}
+}
+
+// NewRef builds a [Node] for a reference. The target of the reference is set with [Node.Link].
+func NewRef(id string) *Node {
+	return &Node{id: id, kind: KindRef}
+}
+
+// NewSchema builds a [Node] for a schema object with a single type (which may be empty).
+//
+// Keywords are sorted by name. Edges are sorted by keyword, then key, then index.
+func NewSchema(id, typ string, keywords []Keyword, children []Edge) *Node {
+	slices.SortFunc(keywords, func(a, b Keyword) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	slices.SortStableFunc(children, compareEdges)
+
+	return &Node{
+		id:       id,
+		kind:     KindSchema,
+		typ:      typ,
+		keywords: keywords,
+		children: children,
+	}
+}
+
+// Link a [KindRef] node to its target.
+func (n *Node) Link(target *Node) {
+	n.target = target
+}
+
+// ID of the node, i.e. the location of the schema it originates from, as a URI with a JSON pointer fragment.
+//
+// Nodes introduced by the canonicalization have IDs derived from the location of the original schema.
+func (n *Node) ID() string {
+	return n.id
+}
+
+// Kind of node.
+func (n *Node) Kind() Kind {
+	return n.kind
+}
+
+// Type of the node, e.g. "string". It is empty if the node is not restricted to a single type.
+func (n *Node) Type() string {
+	return n.typ
+}
+
+// Keywords yields the keywords of the node, sorted by name.
+func (n *Node) Keywords() iter.Seq[Keyword] {
+	return slices.Values(n.keywords)
+}
+
+// Keyword of the node, by name.
+func (n *Node) Keyword(name string) (Keyword, bool) {
+	i, found := slices.BinarySearchFunc(n.keywords, name, func(k Keyword, name string) int {
+		return cmp.Compare(k.Name, name)
+	})
+	if !found {
+		return Keyword{}, false
+	}
+
+	return n.keywords[i], true
+}
+
+// Children yields the edges to the subschemas of the node.
+func (n *Node) Children() iter.Seq[Edge] {
+	return slices.Values(n.children)
+}
+
+// Target of a [KindRef] node, or nil.
+func (n *Node) Target() *Node {
+	return n.target
+}
+
+// Resolved follows references until a node which is not a [KindRef] is found.
+//
+// It returns nil when references form a cycle or are not linked.
+func (n *Node) Resolved() *Node {
+	visited := make(map[*Node]struct{})
+
+	for n != nil && n.kind == KindRef {
+		if _, seen := visited[n]; seen {
+			return nil
+		}
+		visited[n] = struct{}{}
+		n = n.target
+	}
+
+	return n
+}
+
+// Hash of the content of the node. The hash of a reference is the hash of its target.
+//
+// The hash is only known for the nodes of a [Tree].
+func (n *Node) Hash() Hash {
+	return n.hash
+}
+
+// IsEmpty tells if a [KindSchema] node has neither type, keywords nor subschemas, i.e. is equivalent to true.
+func (n *Node) IsEmpty() bool {
+	return n.kind == KindSchema && n.typ == "" && len(n.keywords) == 0 && len(n.children) == 0
+}
+
+func compareEdges(a, b Edge) int {
+	return cmp.Or(
+		cmp.Compare(a.Keyword, b.Keyword),
+		cmp.Compare(a.Key, b.Key),
+		cmp.Compare(a.Index, b.Index),
+	)
 }
@@ -0,0 +1,201 @@
+package ast
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"math"
+	"slices"
+)
+
+// Hash is the content hash of a canonical [Node], as a SHA-256 sum.
+type Hash [32]byte
+
+// String representation of the hash, in hexadecimal.
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// IsZero tells if the hash is undefined.
+func (h Hash) IsZero() bool {
+	return h == Hash{}
+}
+
+// setKeywords are applicators which subschemas form a set: their order doesn't matter.
+// The value tells if duplicate subschemas may be ignored.
+//
+//nolint:gochecknoglobals // declaration of keywords
+var setKeywords = map[string]bool{
+	"allOf": true,
+	"anyOf": true,
+	"oneOf": false,
+}
+
+//nolint:gochecknoglobals // hashes of the nodes that don't depend on their content
+var (
+	trueHash     = sha256.Sum256([]byte{'T'})
+	falseHash    = sha256.Sum256([]byte{'F'})
+	refCycleHash = sha256.Sum256([]byte{'C'})
+)
+
+// hasher computes the hashes of nodes.
+//
+// The hash of a node is computed Merkle-style from the hashes of its subschemas, references being transparent.
+// A reference to a node being hashed, i.e. a cycle, is hashed after its distance to that node, so equivalent
+// recursive schemas get the same hash.
+//
+// Hashes which don't depend on the path from which a node is reached are memoized.
+type hasher struct {
+	stack []*Node
+	memo  map[*Node]Hash
+}
+
+func newHasher() *hasher {
+	return &hasher{
+		memo: make(map[*Node]Hash),
+	}
+}
+
+// hash of a node, considered as the root of the schema.
+func (h *hasher) hash(n *Node) Hash {
+	sum, _ := h.digest(n)
+
+	return sum
+}
+
+// digest yields the hash of a node in the context of the current stack,
+// with the lowest position in the stack that this hash depends on.
+func (h *hasher) digest(n *Node) (Hash, int) {
+	const independent = math.MaxInt
+
+	if n.kind == KindRef {
+		n = n.Resolved()
+		if n == nil {
+			return refCycleHash, independent
+		}
+	}
+
+	switch n.kind {
+	case KindTrue:
+		return trueHash, independent
+	case KindFalse:
+		return falseHash, independent
+	}
+
+	if sum, ok := h.memo[n]; ok {
+		return sum, independent
+	}
+
+	if i := slices.Index(h.stack, n); i >= 0 {
+		var buf [binary.MaxVarintLen64 + 1]byte
+		buf[0] = 'R'
+		l := binary.PutUvarint(buf[1:], uint64(len(h.stack)-i)) //nolint:gosec // a stack depth is positive
+
+		return sha256.Sum256(buf[:l+1]), i
+	}
+
+	position := len(h.stack)
+	h.stack = append(h.stack, n)
+	lowest := independent
+
+	w := sha256.New()
+	w.Write([]byte{'S'})
+	writeString(w, n.typ)
+
+	writeLen(w, len(n.keywords))
+	for _, keyword := range n.keywords {
+		writeString(w, keyword.Name)
+		writeBytes(w, keyword.Value)
+		if keyword.Dynamic {
+			w.Write([]byte{'D'})
+		} else {
+			w.Write([]byte{'-'})
+		}
+	}
+
+	for keyword, edges := range edgesByKeyword(n.children) {
+		writeString(w, keyword)
+		dedupe, isSet := setKeywords[keyword]
+
+		sums := make([]Hash, 0, len(edges))
+		for _, edge := range edges {
+			sum, depends := h.digest(edge.Node)
+			lowest = min(lowest, depends)
+
+			if !isSet {
+				writeString(w, edge.Key)
+				writeLen(w, edge.Index)
+				w.Write(sum[:])
+
+				continue
+			}
+
+			sums = append(sums, sum)
+		}
+
+		if !isSet {
+			continue
+		}
+
+		slices.SortFunc(sums, func(a, b Hash) int { return bytes.Compare(a[:], b[:]) })
+		if dedupe {
+			sums = slices.Compact(sums)
+		}
+
+		writeLen(w, len(sums))
+		for _, sum := range sums {
+			w.Write(sum[:])
+		}
+	}
+
+	h.stack = h.stack[:position]
+
+	var sum Hash
+	w.Sum(sum[:0])
+
+	if lowest >= position {
+		// the hash only depends on the node and its descendants
+		h.memo[n] = sum
+
+		return sum, independent
+	}
+
+	return sum, lowest
+}
+
+// edgesByKeyword yields the sorted edges grouped by keyword.
+func edgesByKeyword(edges []Edge) func(func(string, []Edge) bool) {
+	return func(yield func(string, []Edge) bool) {
+		for len(edges) > 0 {
+			keyword := edges[0].Keyword
+			end := 1
+			for end < len(edges) && edges[end].Keyword == keyword {
+				end++
+			}
+
+			if !yield(keyword, edges[:end]) {
+				return
+			}
+
+			edges = edges[end:]
+		}
+	}
+}
+
+func writeLen(w hash.Hash, l int) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(l)) //nolint:gosec // lengths and indices are positive
+	w.Write(buf[:n])
+}
+
+func writeString(w hash.Hash, s string) {
+	writeLen(w, len(s))
+	w.Write([]byte(s))
+}
+
+func writeBytes(w hash.Hash, b []byte) {
+	writeLen(w, len(b))
+	w.Write(b)
+}
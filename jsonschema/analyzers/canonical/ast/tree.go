@@ -0,0 +1,203 @@
+package ast
+
+import (
+	"bytes"
+	"cmp"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// Tree is the canonical AST of a schema.
+//
+// Since references are resolved, the nodes of a tree actually form a graph, possibly with cycles.
+type Tree struct {
+	root  *Node
+	nodes map[string]*Node
+	index map[Hash][]*Node
+}
+
+// NewTree builds the [Tree] of all nodes reachable from root.
+//
+// The hash of every node is computed, and the subschemas of "allOf", "anyOf" and "oneOf" are sorted by hash.
+func NewTree(root *Node) Tree {
+	t := Tree{
+		root:  root,
+		nodes: make(map[string]*Node),
+		index: make(map[Hash][]*Node),
+	}
+
+	if root == nil {
+		return t
+	}
+
+	var reachable []*Node
+	visited := make(map[*Node]struct{})
+	stack := []*Node{root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if _, seen := visited[n]; seen {
+			continue
+		}
+		visited[n] = struct{}{}
+		reachable = append(reachable, n)
+
+		if _, exists := t.nodes[n.id]; !exists {
+			t.nodes[n.id] = n
+		}
+
+		if n.target != nil {
+			stack = append(stack, n.target)
+		}
+
+		for _, edge := range slices.Backward(n.children) {
+			stack = append(stack, edge.Node)
+		}
+	}
+
+	h := newHasher()
+	for _, n := range reachable {
+		n.hash = h.hash(n)
+	}
+
+	for _, n := range reachable {
+		sortSets(n.children)
+
+		if n.kind == KindSchema {
+			t.index[n.hash] = append(t.index[n.hash], n)
+		}
+	}
+
+	for _, nodes := range t.index {
+		slices.SortFunc(nodes, compareIDs)
+	}
+
+	return t
+}
+
+// Root node of the tree.
+func (t Tree) Root() *Node {
+	return t.root
+}
+
+// Len is the number of nodes in the tree.
+func (t Tree) Len() int {
+	return len(t.nodes)
+}
+
+// Node of the tree, by ID.
+func (t Tree) Node(id string) (*Node, bool) {
+	n, ok := t.nodes[id]
+
+	return n, ok
+}
+
+// Nodes yields all the nodes of the tree, sorted by ID.
+func (t Tree) Nodes() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		for _, id := range slices.Sorted(maps.Keys(t.nodes)) {
+			if !yield(t.nodes[id]) {
+				return
+			}
+		}
+	}
+}
+
+// Lookup all the schema nodes with a given hash, sorted by ID.
+func (t Tree) Lookup(h Hash) []*Node {
+	return t.index[h]
+}
+
+// Duplicates yields the groups of equivalent schema nodes, i.e. nodes with the same [Hash].
+func (t Tree) Duplicates() iter.Seq[[]*Node] {
+	return duplicates(t.index)
+}
+
+// Forest is the canonical AST of a collection of schemas.
+type Forest struct {
+	trees []Tree
+	index map[Hash][]*Node
+}
+
+// NewForest builds a [Forest] of [Tree] s.
+func NewForest(trees ...Tree) Forest {
+	f := Forest{
+		trees: trees,
+		index: make(map[Hash][]*Node),
+	}
+
+	for _, t := range trees {
+		for h, nodes := range t.index {
+			f.index[h] = append(f.index[h], nodes...)
+		}
+	}
+
+	return f
+}
+
+// Len is the number of trees in the forest.
+func (f Forest) Len() int {
+	return len(f.trees)
+}
+
+// Tree at index i in the forest.
+func (f Forest) Tree(i int) Tree {
+	return f.trees[i]
+}
+
+// Trees yields the trees of the forest, with their index.
+func (f Forest) Trees() iter.Seq2[int, Tree] {
+	return slices.All(f.trees)
+}
+
+// Lookup all the schema nodes of the forest with a given hash.
+func (f Forest) Lookup(h Hash) []*Node {
+	return f.index[h]
+}
+
+// Duplicates yields the groups of equivalent schema nodes across all trees.
+func (f Forest) Duplicates() iter.Seq[[]*Node] {
+	return duplicates(f.index)
+}
+
+func duplicates(index map[Hash][]*Node) iter.Seq[[]*Node] {
+	return func(yield func([]*Node) bool) {
+		groups := make([][]*Node, 0, len(index))
+		for _, nodes := range index {
+			if len(nodes) > 1 {
+				groups = append(groups, nodes)
+			}
+		}
+
+		slices.SortFunc(groups, func(a, b []*Node) int {
+			return compareIDs(a[0], b[0])
+		})
+
+		for _, group := range groups {
+			if !yield(group) {
+				return
+			}
+		}
+	}
+}
+
+// sortSets sorts the subschemas of "allOf", "anyOf" and "oneOf" by hash.
+func sortSets(edges []Edge) {
+	for keyword, group := range edgesByKeyword(edges) {
+		if _, isSet := setKeywords[keyword]; !isSet {
+			continue
+		}
+
+		slices.SortStableFunc(group, func(a, b Edge) int {
+			ha, hb := a.Node.hash, b.Node.hash
+
+			return bytes.Compare(ha[:], hb[:])
+		})
+	}
+}
+
+func compareIDs(a, b *Node) int {
+	return cmp.Compare(a.id, b.id)
+}
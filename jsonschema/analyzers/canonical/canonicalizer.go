@@ -0,0 +1,633 @@
+package canonical
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
+)
+
+// location of a schema, as a JSON pointer in a document.
+type location struct {
+	doc     json.Document
+	uri     string
+	pointer string
+	version jsonschema.Version
+}
+
+func (l location) id() string {
+	return l.uri + "#" + l.pointer
+}
+
+func (l location) at(doc json.Document, tokens ...string) location {
+	for _, token := range tokens {
+		l.pointer += "/" + json.EscapeToken(token)
+	}
+	l.doc = doc
+
+	return l
+}
+
+// entry is the value of a keyword, with the keyword that holds it in the original schema.
+type entry struct {
+	doc json.Document
+	key string
+}
+
+// pendingRef is a reference node, to be linked to its target once all schemas are canonicalized.
+type pendingRef struct {
+	node *ast.Node
+	ref  string
+	from location
+}
+
+// canonicalizer builds the canonical nodes of the schemas in a [jsonschema.Resolver].
+type canonicalizer struct {
+	*options
+
+	resolver   *jsonschema.Resolver
+	nodes      map[string]*ast.Node
+	pending    []pendingRef
+	dollarData bool
+}
+
+// add a document to the resolver.
+func (c *canonicalizer) add(uri string, sch jsonschema.Schema) error {
+	if err := c.resolver.AddDocument(uri, sch); err != nil {
+		return fmt.Errorf("%w: %w", err, ErrCanonical)
+	}
+
+	return nil
+}
+
+// document yields the canonical node of a document added to the resolver.
+func (c *canonicalizer) document(uri string, sch jsonschema.Schema) (*ast.Node, error) {
+	version := sch.Version()
+	if version == jsonschema.VersionUndefined {
+		version = c.version
+	}
+
+	uri, _, _ = strings.Cut(uri, "#")
+	root, err := c.schema(location{doc: sch.Document, uri: uri, version: version})
+	if err != nil {
+		return nil, err
+	}
+
+	return root, c.link()
+}
+
+// schema yields the canonical node of the schema at a location.
+func (c *canonicalizer) schema(loc location) (*ast.Node, error) {
+	id := loc.id()
+	if n, ok := c.nodes[id]; ok {
+		return n, nil
+	}
+
+	n, err := c.build(id, loc)
+	if err != nil {
+		return nil, err
+	}
+	c.nodes[id] = n
+
+	return n, nil
+}
+
+// link resolves the pending references.
+func (c *canonicalizer) link() error {
+	for len(c.pending) > 0 {
+		p := c.pending[0]
+		c.pending = c.pending[1:]
+
+		base, err := c.resolver.Resolve((&url.URL{Fragment: p.from.pointer}).String(), p.from.uri)
+		if err != nil {
+			return fmt.Errorf("cannot locate %s: %w: %w", p.from.id(), err, ErrCanonical)
+		}
+
+		resolved, err := c.resolver.Resolve(p.ref, base.BaseURI())
+		if err != nil {
+			return fmt.Errorf("cannot resolve %q in %s: %w: %w", p.ref, p.from.id(), err, ErrCanonical)
+		}
+
+		version := resolved.Version()
+		if version == jsonschema.VersionUndefined {
+			version = p.from.version
+		}
+
+		target, err := c.schema(location{
+			doc:     resolved.Document,
+			uri:     resolved.DocumentURI(),
+			pointer: resolved.Pointer(),
+			version: version,
+		})
+		if err != nil {
+			return err
+		}
+
+		p.node.Link(target)
+	}
+
+	return nil
+}
+
+func (c *canonicalizer) ref(id string, loc location, value json.Document) (*ast.Node, error) {
+	ref, ok := stringOf(value)
+	if !ok {
+		return nil, fmt.Errorf("invalid reference at %s: %w", id, ErrCanonical)
+	}
+
+	n := ast.NewRef(id)
+	c.pending = append(c.pending, pendingRef{node: n, ref: ref, from: loc})
+
+	return n, nil
+}
+
+// build the canonical node of a schema.
+//
+//nolint:gocognit,gocyclo,cyclop // a dispatch over all keywords
+func (c *canonicalizer) build(id string, loc location) (*ast.Node, error) {
+	if value, isBool := boolOf(loc.doc); isBool {
+		return ast.NewBool(id, value), nil
+	}
+
+	if !loc.doc.IsObject() {
+		return nil, fmt.Errorf("invalid schema at %s: %w", id, ErrCanonical)
+	}
+
+	if dollarSchema, ok := loc.doc.AtKey("$schema"); ok {
+		if uri, isString := stringOf(dollarSchema); isString {
+			if version := jsonschema.VersionFromMetaSchemaURL(uri); version != jsonschema.VersionUndefined {
+				loc.version = version
+			}
+		}
+	}
+
+	if ref, ok := loc.doc.AtKey("$ref"); ok && isLegacy(loc.version) {
+		// prior to draft 2019, keywords next to a "$ref" are ignored
+		return c.ref(id, loc, ref)
+	}
+
+	kw := make(map[string]entry, loc.doc.Len())
+	for key, value := range loc.doc.Pairs() {
+		kw[key] = entry{doc: value, key: key}
+	}
+
+	// "$ref" s are applicators like "allOf"
+	var members []*ast.Node
+	for _, key := range []string{"$ref", "$dynamicRef", "$recursiveRef"} {
+		value, ok := kw[key]
+		if !ok {
+			continue
+		}
+		delete(kw, key)
+
+		member, err := c.ref(id+"/"+key, loc, value.doc)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	// normalize dialects
+	exclusiveBound(kw, "maximum", "exclusiveMaximum")
+	exclusiveBound(kw, "minimum", "exclusiveMinimum")
+
+	if items, ok := kw["items"]; ok && items.doc.IsArray() {
+		kw["prefixItems"] = items
+		delete(kw, "items")
+
+		if additional, hasAdditional := kw["additionalItems"]; hasAdditional {
+			kw["items"] = additional
+		}
+	}
+	delete(kw, "additionalItems")
+
+	nullable := false
+	for _, key := range []string{"nullable", "x-nullable"} {
+		if value, ok := kw[key]; ok {
+			isNullable, _ := boolOf(value.doc)
+			nullable = nullable || isNullable
+			delete(kw, key)
+		}
+	}
+
+	types := typesOf(kw, nullable)
+
+	var (
+		keywords []ast.Keyword
+		edges    []ast.Edge
+	)
+
+	dependentRequired := make(map[string][][]byte)
+	if dependencies, ok := kw["dependencies"]; ok {
+		delete(kw, "dependencies")
+
+		for name, value := range dependencies.doc.Pairs() {
+			if value.IsArray() {
+				dependentRequired[name] = append(dependentRequired[name], elemsOf(value)...)
+
+				continue
+			}
+
+			child, err := c.schema(loc.at(value, "dependencies", name))
+			if err != nil {
+				return nil, err
+			}
+			edges = append(edges, ast.Edge{Keyword: "dependentSchemas", Key: name, Node: child})
+		}
+	}
+
+	if value, ok := kw["dependentRequired"]; ok {
+		delete(kw, "dependentRequired")
+
+		for name, required := range value.doc.Pairs() {
+			dependentRequired[name] = append(dependentRequired[name], elemsOf(required)...)
+		}
+	}
+
+	if keyword, ok := dependentRequiredKeyword(dependentRequired); ok {
+		keywords = append(keywords, keyword)
+	}
+
+	if value, ok := c.enum(kw); ok {
+		if value == nil {
+			// no value may be valid
+			return ast.NewBool(id, false), nil
+		}
+
+		keywords = append(keywords, *value)
+	}
+
+	for name, value := range kw {
+		switch class := classOf(name); class {
+		case classIgnored:
+		case classAnnotation:
+			if c.ignoreAnnotations {
+				continue
+			}
+
+			keywords = append(keywords, ast.Keyword{Name: name, Value: canonicalJSON(value.doc)})
+		case classValue:
+			keywords = append(keywords, c.keyword(name, value.doc))
+		case classSchema:
+			child, err := c.schema(loc.at(value.doc, value.key))
+			if err != nil {
+				return nil, err
+			}
+			edges = append(edges, ast.Edge{Keyword: name, Node: child})
+		case classSchemaSet, classSchemaArray:
+			for i, elem := range value.doc.IndexedElems() {
+				child, err := c.schema(loc.at(elem, value.key, strconv.Itoa(i)))
+				if err != nil {
+					return nil, err
+				}
+
+				edge := ast.Edge{Keyword: name, Node: child}
+				if class == classSchemaArray {
+					edge.Index = i
+				}
+				edges = append(edges, edge)
+			}
+		case classSchemaMap:
+			for key, member := range value.doc.Pairs() {
+				child, err := c.schema(loc.at(member, value.key, key))
+				if err != nil {
+					return nil, err
+				}
+				edges = append(edges, ast.Edge{Keyword: name, Key: key, Node: child})
+			}
+		}
+	}
+
+	for _, member := range members {
+		edges = append(edges, ast.Edge{Keyword: "allOf", Node: member})
+	}
+
+	// a single type per node
+	var typ string
+	switch len(types) {
+	case 0:
+	case 1:
+		typ = types[0]
+		keywords, edges = typedOnly(keywords, edges, typ)
+	default:
+		var branches []ast.Edge
+		for _, t := range types {
+			branchKeywords, branchEdges := typedOnly(slices.Clone(keywords), slices.Clone(edges), t)
+			branchKeywords, branchEdges = onlyTyped(branchKeywords), onlyTyped(branchEdges)
+			branches = append(branches, ast.Edge{
+				Keyword: "anyOf",
+				Node:    ast.NewSchema(id+"/type/"+t, t, branchKeywords, branchEdges),
+			})
+		}
+
+		keywords, edges = untyped(keywords), untyped(edges)
+		if slices.ContainsFunc(edges, func(e ast.Edge) bool { return e.Keyword == "anyOf" }) {
+			edges = append(edges, ast.Edge{Keyword: "allOf", Node: ast.NewSchema(id+"/type", "", nil, branches)})
+		} else {
+			edges = append(edges, branches...)
+		}
+	}
+
+	edges, isFalse := compositions(edges)
+	if isFalse {
+		return ast.NewBool(id, false), nil
+	}
+
+	n := ast.NewSchema(id, typ, keywords, edges)
+	if n.IsEmpty() {
+		return ast.NewBool(id, true), nil
+	}
+
+	if typ == "" && len(keywords) == 0 && len(edges) == 1 && edges[0].Keyword == "allOf" {
+		// a single allOf member is equivalent to the schema
+		return edges[0].Node, nil
+	}
+
+	return n, nil
+}
+
+// types yields the sorted types of a schema, without redundant "integer".
+func typesOf(kw map[string]entry, nullable bool) []string {
+	value, ok := kw["type"]
+	if !ok {
+		return nil
+	}
+	delete(kw, "type")
+
+	var types []string
+	if t, isString := stringOf(value.doc); isString {
+		types = append(types, t)
+	} else {
+		for elem := range value.doc.Elems() {
+			if t, isString := stringOf(elem); isString {
+				types = append(types, t)
+			}
+		}
+	}
+
+	if nullable && len(types) > 0 {
+		types = append(types, "null")
+	}
+
+	slices.Sort(types)
+	types = slices.Compact(types)
+
+	if slices.Contains(types, "number") {
+		types = slices.DeleteFunc(types, func(t string) bool { return t == "integer" })
+	}
+
+	return types
+}
+
+// enum merges "const" and "enum" as a sorted set of values.
+//
+// It yields a nil keyword if no value may be valid.
+func (c *canonicalizer) enum(kw map[string]entry) (*ast.Keyword, bool) {
+	constValue, hasConst := kw["const"]
+	enumValue, hasEnum := kw["enum"]
+
+	if hasConst && c.isDynamic("const", constValue.doc) || hasEnum && c.isDynamic("enum", enumValue.doc) {
+		// "$data" references are left untouched
+		return nil, false
+	}
+
+	delete(kw, "const")
+	delete(kw, "enum")
+
+	switch {
+	case hasConst && hasEnum:
+		value := canonicalJSON(constValue.doc)
+		if !slices.ContainsFunc(elemsOf(enumValue.doc), func(elem []byte) bool { return bytes.Equal(elem, value) }) {
+			return nil, true
+		}
+
+		return &ast.Keyword{Name: "enum", Value: joinArray([][]byte{value})}, true
+	case hasConst:
+		return &ast.Keyword{Name: "enum", Value: joinArray([][]byte{canonicalJSON(constValue.doc)})}, true
+	case hasEnum:
+		values := sortedSet(elemsOf(enumValue.doc))
+		if len(values) == 0 {
+			return nil, true
+		}
+
+		return &ast.Keyword{Name: "enum", Value: joinArray(values)}, true
+	default:
+		return nil, false
+	}
+}
+
+func (c *canonicalizer) keyword(name string, value json.Document) ast.Keyword {
+	if c.isDynamic(name, value) {
+		return ast.Keyword{Name: name, Value: canonicalJSON(value), Dynamic: true}
+	}
+
+	if name == "required" && value.IsArray() {
+		return ast.Keyword{Name: name, Value: joinArray(sortedSet(elemsOf(value)))}
+	}
+
+	return ast.Keyword{Name: name, Value: canonicalJSON(value)}
+}
+
+func (c *canonicalizer) isDynamic(name string, value json.Document) bool {
+	return c.dollarData && jsonschema.IsDataRef(name, value)
+}
+
+func dependentRequiredKeyword(dependentRequired map[string][][]byte) (ast.Keyword, bool) {
+	names := make([]string, 0, len(dependentRequired))
+	for name, required := range dependentRequired {
+		if len(required) > 0 {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return ast.Keyword{}, false
+	}
+	slices.Sort(names)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeString(&buf, name)
+		buf.WriteByte(':')
+		buf.Write(joinArray(sortedSet(dependentRequired[name])))
+	}
+	buf.WriteByte('}')
+
+	return ast.Keyword{Name: "dependentRequired", Value: buf.Bytes()}, true
+}
+
+// exclusiveBound rewrites a draft 4 boolean "exclusiveMaximum" (resp. "exclusiveMinimum") as a numerical bound.
+func exclusiveBound(kw map[string]entry, bound, exclusive string) {
+	value, ok := kw[exclusive]
+	if !ok {
+		return
+	}
+
+	isExclusive, isBool := boolOf(value.doc)
+	if !isBool {
+		return
+	}
+	delete(kw, exclusive)
+
+	if limit, hasBound := kw[bound]; hasBound && isExclusive {
+		kw[exclusive] = limit
+		delete(kw, bound)
+	}
+}
+
+// compositions normalizes "allOf", "anyOf" and "oneOf".
+//
+// Trivial members are removed, nested compositions of the same kind are flattened and single-member compositions
+// become "allOf" members. It tells if the compositions reject any value.
+func compositions(edges []ast.Edge) ([]ast.Edge, bool) {
+	result := make([]ast.Edge, 0, len(edges))
+	counts := make(map[string]int)
+
+	for _, edge := range edges {
+		n := edge.Node
+		switch edge.Keyword {
+		case "allOf":
+			switch {
+			case n.Kind() == ast.KindFalse:
+				return nil, true
+			case n.Kind() == ast.KindTrue:
+				continue
+			case isPure(n, "allOf"):
+				for member := range n.Children() {
+					result = append(result, ast.Edge{Keyword: "allOf", Node: member.Node})
+					counts["allOf"]++
+				}
+
+				continue
+			}
+		case "anyOf":
+			switch {
+			case n.Kind() == ast.KindFalse:
+				continue
+			case n.Kind() == ast.KindTrue:
+				counts["anyOf:true"]++
+
+				continue
+			case isPure(n, "anyOf"):
+				for member := range n.Children() {
+					result = append(result, ast.Edge{Keyword: "anyOf", Node: member.Node})
+					counts["anyOf"]++
+				}
+
+				continue
+			}
+		case "oneOf":
+			if n.Kind() == ast.KindFalse {
+				counts["oneOf:false"]++
+
+				continue
+			}
+		}
+
+		result = append(result, edge)
+		counts[edge.Keyword]++
+	}
+
+	if counts["anyOf:true"] > 0 {
+		// any value is valid against the "anyOf" composition
+		result = slices.DeleteFunc(result, func(e ast.Edge) bool { return e.Keyword == "anyOf" })
+		counts["anyOf"] = 0
+	} else if counts["anyOf"] == 0 && hasKeyword(edges, "anyOf") {
+		return nil, true
+	}
+
+	if counts["oneOf"] == 0 && counts["oneOf:false"] > 0 {
+		return nil, true
+	}
+
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		if counts[keyword] != 1 {
+			continue
+		}
+
+		for i := range result {
+			if result[i].Keyword == keyword {
+				result[i].Keyword = "allOf"
+			}
+		}
+	}
+
+	return result, false
+}
+
+// isPure tells if a node is only made of a composition.
+func isPure(n *ast.Node, keyword string) bool {
+	if n.Kind() != ast.KindSchema || n.Type() != "" {
+		return false
+	}
+
+	for range n.Keywords() {
+		return false
+	}
+
+	for edge := range n.Children() {
+		if edge.Keyword != keyword {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasKeyword(edges []ast.Edge, keyword string) bool {
+	return slices.ContainsFunc(edges, func(e ast.Edge) bool { return e.Keyword == keyword })
+}
+
+type named interface {
+	ast.Keyword | ast.Edge
+}
+
+func nameOf[T named](item T) string {
+	switch v := any(item).(type) {
+	case ast.Keyword:
+		return v.Name
+	case ast.Edge:
+		return v.Keyword
+	default:
+		return ""
+	}
+}
+
+// typedOnly removes keywords and edges which don't apply to a type.
+func typedOnly(keywords []ast.Keyword, edges []ast.Edge, typ string) ([]ast.Keyword, []ast.Edge) {
+	return slices.DeleteFunc(keywords, func(k ast.Keyword) bool { return !appliesTo(k.Name, typ) }),
+		slices.DeleteFunc(edges, func(e ast.Edge) bool { return !appliesTo(e.Keyword, typ) })
+}
+
+// onlyTyped retains the items specific to a type.
+func onlyTyped[T named](items []T) []T {
+	return slices.DeleteFunc(items, func(item T) bool {
+		_, isTyped := typedKeywords[nameOf(item)]
+
+		return !isTyped
+	})
+}
+
+// untyped retains the items that apply to all types.
+func untyped[T named](items []T) []T {
+	return slices.DeleteFunc(items, func(item T) bool {
+		_, isTyped := typedKeywords[nameOf(item)]
+
+		return isTyped
+	})
+}
+
+// isLegacy tells if keywords next to a "$ref" are ignored.
+func isLegacy(version jsonschema.Version) bool {
+	return version != jsonschema.VersionUndefined && version.Less(jsonschema.VersionDraft2019)
+}
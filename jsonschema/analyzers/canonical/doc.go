@@ -0,0 +1,25 @@
+// Package canonical normalizes JSON schemas into a canonical AST.
+//
+// The canonical [Analyzer] normalizes any supported dialect of JSON schema into a single representation,
+// defined by package [github.com/fredbi/core/jsonschema/analyzers/canonical/ast]:
+//
+//   - every node holds at most a single type: multiple types are split into an "anyOf" of single-typed schemas,
+//     and keywords that don't apply to the type of a node are removed
+//   - "const" is rewritten as a single-valued "enum", and "enum" values are sorted and deduplicated
+//   - compositions are normalized: trivial members are removed, nested compositions are flattened
+//     and single-member "anyOf" or "oneOf" become "allOf"
+//   - "$ref" s are resolved into a graph of nodes, identified by the location of the schema they originate from
+//   - legacy keywords are rewritten with their latest form, e.g. boolean "exclusiveMaximum", array "items",
+//     "dependencies" or OpenAPI "nullable"
+//   - keywords are sorted, and their values are canonical JSON
+//
+// Every subschema gets a content [SchemaHash]: equivalent schemas across files have the same hash, which
+// may be used to deduplicate schemas or as a cache key.
+//
+// When the "$data" extension is enabled, keywords with a "$data" reference are kept as dynamic keywords.
+//
+// "$dynamicRef" and "$recursiveRef" are resolved statically, like a "$ref".
+//
+// A canonical [ast.Tree] may be rendered back as a draft 2020 [github.com/fredbi/core/jsonschema.Schema]
+// with [Render].
+package canonical
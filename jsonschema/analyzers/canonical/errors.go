@@ -0,0 +1,13 @@
+package canonical
+
+// Error is an error raised by the canonical [Analyzer].
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrCanonical is the generic error raised by this package.
+	ErrCanonical Error = "canonical error"
+)
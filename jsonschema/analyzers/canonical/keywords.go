@@ -0,0 +1,126 @@
+package canonical
+
+import "github.com/fredbi/core/jsonschema"
+
+// keywordClass tells how a keyword is canonicalized.
+type keywordClass uint8
+
+const (
+	classValue       keywordClass = iota // a keyword with a value, e.g. "maxLength"
+	classAnnotation                      // an annotation, e.g. "description"
+	classIgnored                         // a keyword that doesn't contribute to the canonical schema, e.g. "$id"
+	classSchema                          // a keyword with a single schema, e.g. "not"
+	classSchemaSet                       // a keyword with an unordered array of schemas, e.g. "allOf"
+	classSchemaArray                     // a keyword with an ordered array of schemas, i.e. "prefixItems"
+	classSchemaMap                       // a keyword with a map of schemas, e.g. "properties"
+)
+
+// keywordClasses are the classes of keywords which don't follow the default rules of classOf.
+//
+//nolint:gochecknoglobals // keywords specifications
+var keywordClasses = map[string]keywordClass{
+	"$schema":          classIgnored,
+	"$vocabulary":      classIgnored,
+	"$id":              classIgnored,
+	"id":               classIgnored,
+	"$anchor":          classIgnored,
+	"$dynamicAnchor":   classIgnored,
+	"$recursiveAnchor": classIgnored,
+	"$comment":         classIgnored,
+	"$defs":            classIgnored,
+	"definitions":      classIgnored,
+	"title":            classAnnotation,
+	"description":      classAnnotation,
+	"default":          classAnnotation,
+	"examples":         classAnnotation,
+	"example":          classAnnotation,
+	"deprecated":       classAnnotation,
+	"readOnly":         classAnnotation,
+	"writeOnly":        classAnnotation,
+	"contentMediaType": classAnnotation,
+	"contentEncoding":  classAnnotation,
+	"discriminator":    classAnnotation,
+	"xml":              classAnnotation,
+	"externalDocs":     classAnnotation,
+	"prefixItems":      classSchemaArray,
+}
+
+// typedKeywords are the keywords which only apply to values of a given type.
+//
+//nolint:gochecknoglobals // keywords specifications
+var typedKeywords = map[string]string{
+	"maximum":               "number",
+	"minimum":               "number",
+	"exclusiveMaximum":      "number",
+	"exclusiveMinimum":      "number",
+	"multipleOf":            "number",
+	"maxLength":             "string",
+	"minLength":             "string",
+	"pattern":               "string",
+	"contentMediaType":      "string",
+	"contentEncoding":       "string",
+	"contentSchema":         "string",
+	"items":                 "array",
+	"prefixItems":           "array",
+	"contains":              "array",
+	"maxItems":              "array",
+	"minItems":              "array",
+	"uniqueItems":           "array",
+	"maxContains":           "array",
+	"minContains":           "array",
+	"unevaluatedItems":      "array",
+	"properties":            "object",
+	"patternProperties":     "object",
+	"additionalProperties":  "object",
+	"required":              "object",
+	"dependentRequired":     "object",
+	"dependentSchemas":      "object",
+	"propertyNames":         "object",
+	"maxProperties":         "object",
+	"minProperties":         "object",
+	"unevaluatedProperties": "object",
+}
+
+// appliesTo tells if a keyword applies to values of a type.
+func appliesTo(keyword, typ string) bool {
+	required, isTyped := typedKeywords[keyword]
+	if !isTyped {
+		return true
+	}
+
+	if typ == "integer" {
+		typ = "number"
+	}
+
+	return required == typ
+}
+
+// classOf tells how a keyword is canonicalized.
+//
+// Keywords holding subschemas are classified after [jsonschema.SubschemaKeyword]: arrays of schemas are unordered
+// sets, but for "prefixItems". Unknown keywords are values, but for extensions which are annotations.
+func classOf(keyword string) keywordClass {
+	if class, ok := keywordClasses[keyword]; ok {
+		return class
+	}
+
+	switch jsonschema.SubschemaKeyword(keyword) {
+	case jsonschema.SubschemaSingle:
+		return classSchema
+	case jsonschema.SubschemaArray:
+		return classSchemaSet
+	case jsonschema.SubschemaMap:
+		return classSchemaMap
+	case jsonschema.NoSubschema:
+	}
+
+	if isExtension(keyword) {
+		return classAnnotation
+	}
+
+	return classValue
+}
+
+func isExtension(keyword string) bool {
+	return len(keyword) > 2 && keyword[0] == 'x' && keyword[1] == '-'
+}
@@ -0,0 +1,62 @@
+package canonical
+
+import "github.com/fredbi/core/jsonschema"
+
+// Option customizes the behavior of the canonical [Analyzer].
+type Option func(*options)
+
+type options struct {
+	resolver          *jsonschema.Resolver
+	baseURI           string
+	version           jsonschema.Version
+	ignoreAnnotations bool
+}
+
+// WithResolver equips the [Analyzer] with a [jsonschema.Resolver] to resolve "$ref" s.
+//
+// By default, a new [jsonschema.Resolver] is used for every analysis.
+func WithResolver(resolver *jsonschema.Resolver) Option {
+	return func(o *options) {
+		o.resolver = resolver
+	}
+}
+
+// WithBaseURI sets the URI of the analyzed schema, against which relative "$ref" s are resolved.
+//
+// The IDs of the canonical nodes are located relative to this URI.
+//
+// The schemas of a [jsonschema.Collection] are located as "schema-{index}.json" relative to this URI.
+func WithBaseURI(uri string) Option {
+	return func(o *options) {
+		o.baseURI = uri
+	}
+}
+
+// WithDefaultVersion sets the version of JSON schema assumed for schemas that don't declare one.
+//
+// By default, the latest version is assumed.
+func WithDefaultVersion(version jsonschema.Version) Option {
+	return func(o *options) {
+		o.version = version
+	}
+}
+
+// WithIgnoreAnnotations removes annotations such as "title", "description", "default" or "x-*" extensions
+// from the canonical schema.
+//
+// Schemas that only differ by their annotations then get the same [SchemaHash].
+func WithIgnoreAnnotations(enabled bool) Option {
+	return func(o *options) {
+		o.ignoreAnnotations = enabled
+	}
+}
+
+func optionsWithDefaults(opts []Option) *options {
+	o := &options{}
+
+	for _, apply := range opts {
+		apply(o)
+	}
+
+	return o
+}
@@ -0,0 +1,177 @@
+package canonical
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
+)
+
+// Render a canonical [ast.Tree] as a draft 2020 [jsonschema.Schema].
+//
+// Every referenced schema is rendered once, under "$defs", named after its [SchemaHash]:
+// equivalent schemas are thereby deduplicated. The result is deterministic: equivalent schemas render the
+// same JSON.
+func Render(tree ast.Tree) (jsonschema.Schema, error) {
+	root := tree.Root()
+	if root != nil {
+		root = root.Resolved()
+	}
+
+	r := renderer{
+		root: root,
+		defs: make(map[string][]byte),
+	}
+
+	var buf bytes.Buffer
+	if root == nil || root.Kind() != ast.KindSchema {
+		r.node(&buf, root)
+	} else {
+		members := r.members(root)
+		members["$schema"] = quoted(jsonschema.VersionDraft2020.MetaSchemaURL())
+		if len(r.defs) > 0 {
+			var defs bytes.Buffer
+			writeObject(&defs, r.defs)
+			members["$defs"] = defs.Bytes()
+		}
+		writeObject(&buf, members)
+	}
+
+	opts := []jsonschema.Option{jsonschema.WithVersion(jsonschema.VersionDraft2020)}
+	if r.dynamic {
+		opts = append(opts, jsonschema.WithDollarData(true))
+	}
+
+	sch := jsonschema.Make(opts...)
+	if err := sch.UnmarshalJSON(buf.Bytes()); err != nil {
+		return sch, fmt.Errorf("cannot render canonical schema: %w: %w", err, ErrCanonical)
+	}
+
+	return sch, nil
+}
+
+type renderer struct {
+	root    *ast.Node
+	defs    map[string][]byte
+	dynamic bool
+}
+
+func (r *renderer) node(buf *bytes.Buffer, n *ast.Node) {
+	if n == nil {
+		buf.WriteString("true")
+
+		return
+	}
+
+	switch n.Kind() {
+	case ast.KindTrue:
+		buf.WriteString("true")
+	case ast.KindFalse:
+		buf.WriteString("false")
+	case ast.KindRef:
+		r.ref(buf, n)
+	default:
+		writeObject(buf, r.members(n))
+	}
+}
+
+func (r *renderer) ref(buf *bytes.Buffer, n *ast.Node) {
+	target := n.Resolved()
+	switch {
+	case target == nil || target.Kind() != ast.KindSchema:
+		r.node(buf, target)
+
+		return
+	case target == r.root:
+		buf.WriteString(`{"$ref":"#"}`)
+
+		return
+	}
+
+	name := target.Hash().String()
+	if _, rendered := r.defs[name]; !rendered {
+		r.defs[name] = nil // guards against cycles
+
+		var def bytes.Buffer
+		r.node(&def, target)
+		r.defs[name] = def.Bytes()
+	}
+
+	buf.WriteString(`{"$ref":`)
+	buf.Write(quoted("#/$defs/" + name))
+	buf.WriteByte('}')
+}
+
+// members of a schema node, as canonical JSON by keyword.
+func (r *renderer) members(n *ast.Node) map[string][]byte {
+	members := make(map[string][]byte)
+
+	if n.Type() != "" {
+		members["type"] = quoted(n.Type())
+	}
+
+	for keyword := range n.Keywords() {
+		members[keyword.Name] = keyword.Value
+		r.dynamic = r.dynamic || keyword.Dynamic
+	}
+
+	var (
+		schemaMaps = make(map[string]map[string][]byte)
+		arrays     = make(map[string][][]byte)
+		current    bytes.Buffer
+	)
+
+	for edge := range n.Children() {
+		current.Reset()
+		r.node(&current, edge.Node)
+		value := bytes.Clone(current.Bytes())
+
+		switch classOf(edge.Keyword) {
+		case classSchemaMap:
+			if schemaMaps[edge.Keyword] == nil {
+				schemaMaps[edge.Keyword] = make(map[string][]byte)
+			}
+			schemaMaps[edge.Keyword][edge.Key] = value
+		case classSchemaSet, classSchemaArray:
+			arrays[edge.Keyword] = append(arrays[edge.Keyword], value)
+		default:
+			members[edge.Keyword] = value
+		}
+	}
+
+	for keyword, schemas := range schemaMaps {
+		var buf bytes.Buffer
+		writeObject(&buf, schemas)
+		members[keyword] = buf.Bytes()
+	}
+
+	for keyword, schemas := range arrays {
+		members[keyword] = joinArray(schemas)
+	}
+
+	return members
+}
+
+// writeObject writes a JSON object with sorted keys.
+func writeObject(buf *bytes.Buffer, members map[string][]byte) {
+	buf.WriteByte('{')
+	for i, key := range slices.Sorted(maps.Keys(members)) {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeString(buf, key)
+		buf.WriteByte(':')
+		buf.Write(members[key])
+	}
+	buf.WriteByte('}')
+}
+
+func quoted(s string) []byte {
+	var buf bytes.Buffer
+	writeString(&buf, s)
+
+	return buf.Bytes()
+}
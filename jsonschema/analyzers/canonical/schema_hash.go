@@ -1,3 +1,9 @@
 package canonical
 
-type SchemaHash [32]byte
+import "github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
+
+// SchemaHash is the content hash of a canonical schema.
+//
+// Equivalent schemas have the same hash, regardless of their dialect, of the ordering of their keywords
+// or of the location of the subschemas they refer to. It is suitable as a cache key.
+type SchemaHash = ast.Hash
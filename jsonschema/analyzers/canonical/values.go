@@ -0,0 +1,131 @@
+package canonical
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"math/big"
+	"slices"
+	"strconv"
+
+	"github.com/fredbi/core/json"
+)
+
+// canonicalJSON writes the canonical JSON representation of a value: compact, with object keys sorted
+// and numbers normalized, so that equal JSON values yield the same bytes.
+func canonicalJSON(d json.Document) []byte {
+	var buf bytes.Buffer
+	writeCanonical(&buf, d)
+
+	return buf.Bytes()
+}
+
+func writeCanonical(buf *bytes.Buffer, d json.Document) {
+	switch {
+	case d.IsObject():
+		keys := make([]string, 0, d.Len())
+		for key := range d.Pairs() {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeString(buf, key)
+			buf.WriteByte(':')
+			value, _ := d.AtKey(key)
+			writeCanonical(buf, value)
+		}
+		buf.WriteByte('}')
+	case d.IsArray():
+		buf.WriteByte('[')
+		for i, elem := range d.IndexedElems() {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonical(buf, elem)
+		}
+		buf.WriteByte(']')
+	case d.IsString():
+		v, _ := d.Value()
+		writeString(buf, v.String())
+	case d.IsNumber():
+		buf.WriteString(canonicalNumber(text(d)))
+	default:
+		buf.WriteString(text(d))
+	}
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	quoted, _ := stdjson.Marshal(s) // never fails for a string
+	buf.Write(quoted)
+}
+
+// canonicalNumber normalizes a JSON number, e.g. 1.0 and 1e0 yield 1.
+func canonicalNumber(number string) string {
+	r, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return number
+	}
+
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	f, _ := r.Float64()
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// text is the compact JSON representation of a value.
+func text(d json.Document) string {
+	data, err := d.MarshalJSON()
+	if err != nil {
+		return d.String()
+	}
+
+	return string(data)
+}
+
+func stringOf(d json.Document) (string, bool) {
+	if !d.IsString() {
+		return "", false
+	}
+
+	v, _ := d.Value()
+
+	return v.String(), true
+}
+
+func boolOf(d json.Document) (bool, bool) {
+	if !d.IsBool() {
+		return false, false
+	}
+
+	v, _ := d.Value()
+
+	return v.Bool(), true
+}
+
+// sortedSet sorts canonical JSON values and removes duplicates.
+func sortedSet(values [][]byte) [][]byte {
+	slices.SortFunc(values, bytes.Compare)
+
+	return slices.CompactFunc(values, bytes.Equal)
+}
+
+// joinArray builds a JSON array from canonical JSON values.
+func joinArray(values [][]byte) []byte {
+	return append(append([]byte{'['}, bytes.Join(values, []byte{','})...), ']')
+}
+
+func elemsOf(d json.Document) [][]byte {
+	elems := make([][]byte, 0, d.Len())
+	for elem := range d.Elems() {
+		elems = append(elems, canonicalJSON(elem))
+	}
+
+	return elems
+}
@@ -1,5 +1,10 @@
 package graph
 
+import (
+	"iter"
+	"slices"
+)
+
 type ErrGraph string
 
 func (e ErrGraph) Error() string {
@@ -8,7 +13,9 @@ func (e ErrGraph) Error() string {
 
 const (
 	ErrNodeNotFound ErrGraph = "node not found"
-	ErrCycleFound   ErrGraph = "a cycle is being created in a graph that borbids cycles"
+	ErrNodeExists   ErrGraph = "node already exists"
+	ErrCycleFound   ErrGraph = "a cycle is being created in a graph that forbids cycles"
+	ErrNotATree     ErrGraph = "a node is being given a second parent in a tree"
 )
 
 type payload[V any] struct {
@@ -23,316 +30,416 @@ func (p *payload[V]) SetValue(v V) {
 	p.payload = v
 }
 
+// Node of a graph, with a payload.
 type Node[K comparable, V any] struct {
 	id K
 	payload[V]
 }
 
+func (n *Node[K, V]) ID() K {
+	return n.id
+}
+
+// Edge of a graph, with a payload.
 type Edge[K comparable, V any] struct {
 	From K
 	To   K
 	payload[V]
 }
 
-type DAG[K comparable, N any, E any] struct {
-	DiGraph[K, N, E]
-}
-
-type Tree[K comparable, N any, E any] struct {
-	DAG[K, N, E]
+// DiGraph is a directed graph with payloads on nodes and edges.
+//
+// Nodes and edges are iterated over in the order they were added. Cycles are allowed.
+type DiGraph[K comparable, N any, E any] struct {
+	nodes []*Node[K, N]
+	index map[K]int
+	edges []*Edge[K, E]
+	out   map[K][]*Edge[K, E]
+	in    map[K][]*Edge[K, E]
+}
+
+func NewDiGraph[K comparable, N any, E any]() *DiGraph[K, N, E] {
+	return &DiGraph[K, N, E]{
+		index: make(map[K]int),
+		out:   make(map[K][]*Edge[K, E]),
+		in:    make(map[K][]*Edge[K, E]),
+	}
 }
 
-type Forest[K comparable, N any, E any] struct {
-	DAG[K, N, E]
+func (d *DiGraph[K, N, E]) Len() int {
+	return len(d.nodes)
 }
 
-type DiGraph[K comparable, N any, E any] struct {
-}
+// AddNode adds a new node to the graph.
+func (d *DiGraph[K, N, E]) AddNode(id K, payload N) (*Node[K, N], error) {
+	if _, exists := d.index[id]; exists {
+		return nil, ErrNodeExists
+	}
 
-func (d DiGraph[K, N, E]) Len() int {
-	return 0
-}
+	n := &Node[K, N]{id: id}
+	n.SetValue(payload)
+	d.index[id] = len(d.nodes)
+	d.nodes = append(d.nodes, n)
 
-func (d DiGraph[K, N, E]) AddNode(id K, payload N) (*Node[K, N], error) {
-	return nil, nil
+	return n, nil
 }
 
-func (d DiGraph[K, N, E]) AddEdge(from, to N, payload E) (*Edge[K, E], error) {
-	return nil, nil
-}
+func (d *DiGraph[K, N, E]) Node(id K) (*Node[K, N], bool) {
+	i, ok := d.index[id]
+	if !ok {
+		return nil, false
+	}
 
-/*
-func (d *Tree[T]) IsTree() bool {
-	return !d.HasMultipleRoots()
+	return d.nodes[i], true
 }
 
-func (d *Tree[T]) Clone() *Tree[T] {
-	clone := NewTree[T]()
-
-	for _, vertex := range d.inner.GetAllVertices() {
-		clone.inner.AddVertex(vertex)
+// AddEdge adds an edge between two nodes of the graph.
+func (d *DiGraph[K, N, E]) AddEdge(from, to K, payload E) (*Edge[K, E], error) {
+	if _, ok := d.index[from]; !ok {
+		return nil, ErrNodeNotFound
 	}
 
-	for _, edge := range d.inner.AllEdges() {
-		_, _ = clone.inner.AddEdge(edge.Destination(), edge.Source())
+	if _, ok := d.index[to]; !ok {
+		return nil, ErrNodeNotFound
 	}
 
-	return clone
-}
+	e := &Edge[K, E]{From: from, To: to}
+	e.SetValue(payload)
+	d.edges = append(d.edges, e)
+	d.out[from] = append(d.out[from], e)
+	d.in[to] = append(d.in[to], e)
 
-func (d *DiGraph[T]) AddNode(id T) {
-	d.inner.AddVertexByLabel(id)
+	return e, nil
 }
 
-func (d *DiGraph[T]) RemoveNode(id T) {
-	v := d.inner.GetVertexByID(id)
-	if v == nil {
-		return
-	}
-
-	d.inner.RemoveVertices(v)
+func (d *DiGraph[K, N, E]) Nodes() iter.Seq[*Node[K, N]] {
+	return slices.Values(d.nodes)
 }
 
-func (d *DiGraph[T]) RemoveEdge(e Edge[T]) {
-	source := d.inner.GetVertexByID(e.From)
-	if source == nil {
-		return
-	}
-	dest := d.inner.GetVertexByID(e.To)
-	if dest == nil {
-		return
-	}
+func (d *DiGraph[K, N, E]) Edges() iter.Seq[*Edge[K, E]] {
+	return slices.Values(d.edges)
+}
 
-	d.inner.RemoveEdges(gograph.NewEdge(source, dest))
+// Out yields the edges from a node.
+func (d *DiGraph[K, N, E]) Out(id K) iter.Seq[*Edge[K, E]] {
+	return slices.Values(d.out[id])
 }
 
-func (d *DiGraph[T]) AddEdge(e Edge[T]) error {
-	source := d.inner.GetVertexByID(e.From)
-	if source == nil {
-		return ErrNodeNotFound
-	}
-	dest := d.inner.GetVertexByID(e.To)
-	if dest == nil {
-		return ErrNodeNotFound
-	}
+// In yields the edges to a node.
+func (d *DiGraph[K, N, E]) In(id K) iter.Seq[*Edge[K, E]] {
+	return slices.Values(d.in[id])
+}
 
-	_, err := d.inner.AddEdge(source, dest)
+// Leaves yields the nodes without outgoing edges.
+func (d *DiGraph[K, N, E]) Leaves() iter.Seq[*Node[K, N]] {
+	return d.filter(func(n *Node[K, N]) bool { return len(d.out[n.id]) == 0 })
+}
 
-	return err
+// Roots yields the nodes without incoming edges.
+func (d *DiGraph[K, N, E]) Roots() iter.Seq[*Node[K, N]] {
+	return d.filter(func(n *Node[K, N]) bool { return len(d.in[n.id]) == 0 })
 }
 
-func (d *DiGraph[T]) Clone() *DiGraph[T] {
-	clone := NewDiGraph[T]()
+// Inverted yields a new graph with the same nodes, and all edges reversed.
+func (d *DiGraph[K, N, E]) Inverted() *DiGraph[K, N, E] {
+	inverted := NewDiGraph[K, N, E]()
 
-	for _, vertex := range d.inner.GetAllVertices() {
-		clone.inner.AddVertex(vertex)
+	for _, n := range d.nodes {
+		_, _ = inverted.AddNode(n.id, n.Value())
 	}
 
-	for _, edge := range d.inner.AllEdges() {
-		clone.inner.AddEdge(edge.Destination(), edge.Source())
+	for _, e := range d.edges {
+		_, _ = inverted.AddEdge(e.To, e.From, e.Value())
 	}
 
-	return clone
-}
-
-func (d *DiGraph[T]) Nodes() iter.Seq[T] {
-	return func(yield func(T) bool) {
-		for _, vertex := range d.inner.GetAllVertices() {
-			if !yield(vertex.Label()) {
-				return
-			}
-		}
-	}
+	return inverted
 }
 
-func (d *DiGraph[T]) Edges() iter.Seq[Edge[T]] {
-	return func(yield func(Edge[T]) bool) {
-		for _, edge := range d.inner.AllEdges() {
-			var from, to T
+// TraverseDFS yields all nodes depth-first, from the roots then from nodes in cycles which are not reachable
+// from a root.
+//
+// Every node is visited once, before the nodes it leads to (pre-order).
+func (d *DiGraph[K, N, E]) TraverseDFS() iter.Seq[*Node[K, N]] {
+	return func(yield func(*Node[K, N]) bool) {
+		visited := make([]bool, len(d.nodes))
 
-			source := edge.Source()
-			if source != nil {
-				from = source.Label()
-			}
-			destination := edge.Destination()
-			if destination != nil {
-				to = destination.Label()
+		var visit func(i int) bool
+		visit = func(i int) bool {
+			visited[i] = true
+			if !yield(d.nodes[i]) {
+				return false
 			}
 
-			e := Edge[T]{
-				From: from,
-				To:   to,
+			for _, e := range d.out[d.nodes[i].id] {
+				if j := d.index[e.To]; !visited[j] && !visit(j) {
+					return false
+				}
 			}
 
-			if !yield(e) {
+			return true
+		}
+
+		for i := range d.startingPoints() {
+			if !visited[i] && !visit(i) {
 				return
 			}
 		}
 	}
 }
 
-func (d *DiGraph[T]) Revert() *DiGraph[T] {
-	reverted := NewDiGraph[T]()
+// TraverseBFS yields all nodes breadth-first, from the roots then from nodes in cycles which are not reachable
+// from a root.
+func (d *DiGraph[K, N, E]) TraverseBFS() iter.Seq[*Node[K, N]] {
+	return func(yield func(*Node[K, N]) bool) {
+		visited := make([]bool, len(d.nodes))
 
-	for _, vertex := range d.inner.GetAllVertices() {
-		reverted.inner.AddVertex(vertex)
-	}
+		for start := range d.startingPoints() {
+			if visited[start] {
+				continue
+			}
 
-	for _, edge := range d.inner.AllEdges() {
-		reverted.inner.AddEdge(edge.Destination(), edge.Source())
+			visited[start] = true
+			queue := []int{start}
+			for len(queue) > 0 {
+				i := queue[0]
+				queue = queue[1:]
+				if !yield(d.nodes[i]) {
+					return
+				}
+
+				for _, e := range d.out[d.nodes[i].id] {
+					if j := d.index[e.To]; !visited[j] {
+						visited[j] = true
+						queue = append(queue, j)
+					}
+				}
+			}
+		}
 	}
-
-	return reverted
 }
 
-// TODO: find cycles?
-
-func (d *DiGraph[T]) HasCycle() bool {
-	_, err := gograph.TopologySort(d.inner)
-
-	return err != nil
-}
+// TraverseTopological yields all nodes so that a node comes before the nodes it leads to.
+//
+// Nodes in a cycle are yielded in the order they are reached depth-first.
+func (d *DiGraph[K, N, E]) TraverseTopological() iter.Seq[*Node[K, N]] {
+	return func(yield func(*Node[K, N]) bool) {
+		visited := make([]bool, len(d.nodes))
+		sorted := make([]int, 0, len(d.nodes))
+
+		var visit func(i int)
+		visit = func(i int) {
+			visited[i] = true
+			for _, e := range d.out[d.nodes[i].id] {
+				if j := d.index[e.To]; !visited[j] {
+					visit(j)
+				}
+			}
+			sorted = append(sorted, i)
+		}
 
-func (d *DiGraph[T]) Leaves() iter.Seq[T] {
-	return func(yield func(T) bool) {
-		for _, vertex := range d.inner.GetAllVertices() {
-			if vertex.OutDegree() > 0 {
-				continue
+		for i := range d.startingPoints() {
+			if !visited[i] {
+				visit(i)
 			}
+		}
 
-			if !yield(vertex.Label()) {
+		for _, i := range slices.Backward(sorted) {
+			if !yield(d.nodes[i]) {
 				return
 			}
 		}
 	}
 }
 
-func (d *DiGraph[T]) Roots() iter.Seq[T] {
-	// TODO: wrong if cycle to root, then its no longer a root.
-	return func(yield func(T) bool) {
-		for _, vertex := range d.inner.GetAllVertices() {
-			if vertex.InDegree() > 0 {
-				continue
+// Cycles yields the strongly connected components of the graph which form a cycle, i.e. with more than one node,
+// or with a node that leads to itself (Tarjan's algorithm).
+//
+// Nodes in a cycle are sorted in the order they were added to the graph, and so are cycles.
+func (d *DiGraph[K, N, E]) Cycles() [][]K {
+	var (
+		counter    int
+		stack      []int
+		components [][]int
+	)
+	order := make([]int, len(d.nodes))
+	low := make([]int, len(d.nodes))
+	onStack := make([]bool, len(d.nodes))
+
+	var connect func(v int)
+	connect = func(v int) {
+		counter++
+		order[v], low[v] = counter, counter
+		stack = append(stack, v)
+		onStack[v] = true
+
+		selfLoop := false
+		for _, e := range d.out[d.nodes[v].id] {
+			w := d.index[e.To]
+			switch {
+			case w == v:
+				selfLoop = true
+			case order[w] == 0:
+				connect(w)
+				low[v] = min(low[v], low[w])
+			case onStack[w]:
+				low[v] = min(low[v], order[w])
 			}
+		}
 
-			if !yield(vertex.Label()) {
-				return
+		if low[v] != order[v] {
+			return
+		}
+
+		var component []int
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component = append(component, w)
+			if w == v {
+				break
 			}
 		}
+
+		if len(component) > 1 || selfLoop {
+			slices.Sort(component)
+			components = append(components, component)
+		}
 	}
-}
 
-func (d *DiGraph[T]) HasMultipleRoots() bool {
-	var roots int
-	for range d.Roots() {
-		roots++
-		if roots > 1 {
-			return true
+	for v := range d.nodes {
+		if order[v] == 0 {
+			connect(v)
 		}
 	}
 
-	return false
-}
+	slices.SortFunc(components, func(x, y []int) int { return x[0] - y[0] })
 
-func (d *DiGraph[T]) TraverseBFS(start T) (iter.Seq[T], error) {
-	iter, err := traverse.NewBreadthFirstIterator(d.inner, start)
-	if err != nil {
-		return nil, err
+	cycles := make([][]K, 0, len(components))
+	for _, component := range components {
+		ids := make([]K, 0, len(component))
+		for _, i := range component {
+			ids = append(ids, d.nodes[i].id)
+		}
+		cycles = append(cycles, ids)
 	}
 
-	return func(yield func(T) bool) {
-		for iter.HasNext() {
-			vertex := iter.Next()
-			id := vertex.Label()
+	return cycles
+}
 
-			if !yield(id) {
-				return
-			}
-		}
-	}, nil
+// HasCycle tells if the graph has cycles.
+func (d *DiGraph[K, N, E]) HasCycle() bool {
+	return len(d.Cycles()) > 0
 }
 
-func (d *DiGraph[T]) TraverseBFSRoots() (iter.Seq[T], error) {
-	iterators := make([]iter.Seq[T], 0)
+// reaches tells if there is a path from a node to another.
+func (d *DiGraph[K, N, E]) reaches(from, to K) bool {
+	visited := map[K]struct{}{from: {}}
+	stack := []K{from}
 
-	for root := range d.Roots() {
-		iter, err := d.TraverseBFS(root)
-		if err != nil {
-			return nil, err
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == to {
+			return true
 		}
 
-		iterators = append(iterators, typeutils.VisitOnceIter(iter))
+		for _, e := range d.out[id] {
+			if _, seen := visited[e.To]; !seen {
+				visited[e.To] = struct{}{}
+				stack = append(stack, e.To)
+			}
+		}
 	}
 
-	return typeutils.ConcatIter(iterators...), nil
+	return false
 }
 
-func (d *DiGraph[T]) TraverseDFS(start T) (iter.Seq[T], error) {
-	iter, err := traverse.NewDepthFirstIterator(d.inner, start)
-	if err != nil {
-		return nil, err
+func (d *DiGraph[K, N, E]) filter(keep func(*Node[K, N]) bool) iter.Seq[*Node[K, N]] {
+	return func(yield func(*Node[K, N]) bool) {
+		for _, n := range d.nodes {
+			if keep(n) && !yield(n) {
+				return
+			}
+		}
 	}
+}
 
-	return func(yield func(T) bool) {
-		for iter.HasNext() {
-			vertex := iter.Next()
-			id := vertex.Label()
+// startingPoints of traversals: the roots first, then all nodes, to reach cycles which are not reachable from a root.
+func (d *DiGraph[K, N, E]) startingPoints() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i, n := range d.nodes {
+			if len(d.in[n.id]) == 0 && !yield(i) {
+				return
+			}
+		}
 
-			if !yield(id) {
+		for i := range d.nodes {
+			if !yield(i) {
 				return
 			}
 		}
-	}, nil
+	}
 }
 
-func (d *DiGraph[T]) TraverseDFSRoots() (iter.Seq[T], error) {
-	iterators := make([]iter.Seq[T], 0)
+// DAG is a directed acyclic graph.
+type DAG[K comparable, N any, E any] struct {
+	*DiGraph[K, N, E]
+}
 
-	for root := range d.Roots() {
-		iter, err := d.TraverseBFS(root)
-		if err != nil {
-			return nil, err
-		}
+func NewDAG[K comparable, N any, E any]() *DAG[K, N, E] {
+	return &DAG[K, N, E]{
+		DiGraph: NewDiGraph[K, N, E](),
+	}
+}
 
-		iterators = append(iterators, typeutils.VisitOnceIter(iter))
+// AddEdge adds an edge between two nodes of the graph, unless it creates a cycle.
+func (d *DAG[K, N, E]) AddEdge(from, to K, payload E) (*Edge[K, E], error) {
+	if _, ok := d.index[to]; ok && d.reaches(to, from) {
+		return nil, ErrCycleFound
 	}
 
-	return typeutils.ConcatIter(iterators...), nil
+	return d.DiGraph.AddEdge(from, to, payload)
+}
+
+// Tree is a [DAG] in which every node has at most one parent.
+type Tree[K comparable, N any, E any] struct {
+	*DAG[K, N, E]
 }
 
-func (d *DiGraph[T]) TraverseTopological() (iter.Seq[T], error) {
-	iter, err := traverse.NewTopologicalIterator(d.inner)
-	if err != nil {
-		return nil, err
+func NewTree[K comparable, N any, E any]() *Tree[K, N, E] {
+	return &Tree[K, N, E]{
+		DAG: NewDAG[K, N, E](),
 	}
+}
 
-	return func(yield func(T) bool) {
-		for iter.HasNext() {
-			vertex := iter.Next()
-			id := vertex.Label()
+// AddEdge adds an edge from a parent node to a child node, unless the child already has a parent.
+func (d *Tree[K, N, E]) AddEdge(from, to K, payload E) (*Edge[K, E], error) {
+	if len(d.in[to]) > 0 {
+		return nil, ErrNotATree
+	}
 
-			if !yield(id) {
-				return
-			}
-		}
-	}, nil
+	return d.DAG.AddEdge(from, to, payload)
 }
 
-func (d *DiGraph[T]) TraverseClosestFirst(start T) (iter.Seq[T], error) {
-	iter, err := traverse.NewClosestFirstIterator(d.inner, start)
-	if err != nil {
-		return nil, err
+// Parent of a node, or false if the node is a root.
+func (d *Tree[K, N, E]) Parent(id K) (*Node[K, N], bool) {
+	in := d.in[id]
+	if len(in) == 0 {
+		return nil, false
 	}
 
-	return func(yield func(T) bool) {
-		for iter.HasNext() {
-			vertex := iter.Next()
-			id := vertex.Label()
+	return d.Node(in[0].From)
+}
 
-			if !yield(id) {
+// Children of a node.
+func (d *Tree[K, N, E]) Children(id K) iter.Seq[*Node[K, N]] {
+	return func(yield func(*Node[K, N]) bool) {
+		for _, e := range d.out[id] {
+			child, _ := d.Node(e.To)
+			if !yield(child) {
 				return
 			}
 		}
-	}, nil
+	}
 }
-*/
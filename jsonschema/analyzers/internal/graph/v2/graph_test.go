@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiGraph(t *testing.T) {
+	// a -> b -> c -> b, a -> d, e -> e
+	d := NewDiGraph[string, int, string]()
+	for i, id := range []string{"a", "b", "c", "d", "e"} {
+		_, err := d.AddNode(id, i)
+		require.NoError(t, err)
+	}
+	for _, e := range [][2]string{{"a", "b"}, {"b", "c"}, {"c", "b"}, {"a", "d"}, {"e", "e"}} {
+		_, err := d.AddEdge(e[0], e[1], e[0]+e[1])
+		require.NoError(t, err)
+	}
+
+	t.Run("should refuse unknown or duplicate nodes", func(t *testing.T) {
+		_, err := d.AddNode("a", 0)
+		require.ErrorIs(t, err, ErrNodeExists)
+
+		_, err = d.AddEdge("a", "z", "")
+		require.ErrorIs(t, err, ErrNodeNotFound)
+	})
+
+	t.Run("should find roots, leaves and cycles", func(t *testing.T) {
+		assert.Equal(t, []string{"a"}, ids(d.Roots()))
+		assert.Equal(t, []string{"d"}, ids(d.Leaves()))
+		assert.Equal(t, [][]string{{"b", "c"}, {"e"}}, d.Cycles())
+		assert.True(t, d.HasCycle())
+	})
+
+	t.Run("should traverse all nodes once", func(t *testing.T) {
+		assert.Equal(t, []string{"a", "b", "c", "d", "e"}, ids(d.TraverseDFS()))
+		assert.Equal(t, []string{"a", "b", "d", "c", "e"}, ids(d.TraverseBFS()))
+		assert.Equal(t, []string{"e", "a", "d", "b", "c"}, ids(d.TraverseTopological()))
+		assert.Equal(t, []string{"d"}, ids(d.Inverted().Roots()))
+	})
+}
+
+func TestTree(t *testing.T) {
+	tree := NewTree[string, struct{}, struct{}]()
+	for _, id := range []string{"root", "a", "b"} {
+		_, err := tree.AddNode(id, struct{}{})
+		require.NoError(t, err)
+	}
+
+	_, err := tree.AddEdge("root", "a", struct{}{})
+	require.NoError(t, err)
+	_, err = tree.AddEdge("a", "b", struct{}{})
+	require.NoError(t, err)
+
+	_, err = tree.AddEdge("root", "b", struct{}{})
+	require.ErrorIs(t, err, ErrNotATree)
+
+	_, err = tree.DAG.AddEdge("b", "root", struct{}{})
+	require.ErrorIs(t, err, ErrCycleFound)
+
+	parent, ok := tree.Parent("b")
+	require.True(t, ok)
+	assert.Equal(t, "a", parent.ID())
+	assert.Equal(t, []string{"a"}, ids(tree.Children("root")))
+}
+
+func ids[N any](nodes iter.Seq[*Node[string, N]]) []string {
+	var result []string
+	for n := range nodes {
+		result = append(result, n.ID())
+	}
+
+	return result
+}
@@ -2,15 +2,20 @@ package structural
 
 import (
 	"bytes"
-	"errors"
+	"cmp"
+	"fmt"
 	"io"
 	"iter"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
 
 	"github.com/fredbi/core/jsonschema"
 	"github.com/fredbi/core/jsonschema/analyzers"
-	"github.com/fredbi/core/jsonschema/analyzers/internal/graph/v2"
 	"github.com/fredbi/core/jsonschema/analyzers/structural/order"
 	"github.com/fredbi/core/jsonschema/analyzers/validations"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
 	"github.com/fredbi/core/swag/typeutils"
 )
 
@@ -129,35 +134,38 @@ var _ Analyzer = &SchemaAnalyzer{}
 // [SchemaAnalyzer.LogAudit] to document their action.
 type SchemaAnalyzer struct {
 	options
-	bundleOptions
 
-	// indexes
-	schemas  *schemasGraph // all discovered schemas
-	packages *packageTree  // all discovered packages
-	// redundant namespaces map[string]namespace                                     // all namespaces
-	// redundant contexts   map[graph.Edge[analyzers.UniqueID]]AnalyzedSchemaContext // all dependency contexts (e.g. this schema is used as property etc)
-	// redundant readOrder  *list.List                                               // the original ordering of analyzed schemas when read from the json schema (used when marshaling, dumping)
+	resolver *jsonschema.Resolver // resolves "$ref" s, possibly to remote documents
 
-	// graphs
-	// schemaGraph *graph.DiGraph[analyzers.UniqueID] // dependency graph of schema. TODO: find a nice way to deal with cyclic deps
-	// packageTree *graph.Tree[analyzers.UniqueID]    //  tree of the discovered packages
+	// indexes
+	schemas   *schemaGraph                 // all discovered schemas
+	packages  *packageTree                 // all discovered packages
+	roots     []analyzers.UniqueID         // all analyzed root schemas
+	rootURIs  []string                     // the URIs of the documents of the analyzed root schemas
+	documents map[string]struct{}          // the URIs of the documents which definitions have been explored
+	sources   map[string]jsonschema.Source // the sources of the analyzed root documents, by URI
 
 	validationsAnalyzer *validations.Analyzer // inner analyzer for validations (e.g. when validating zero value, enum value, default value)
 }
 
 // NewAnalyzer builds a [SchemaAnalyzer] ready to analyze JSON schemas.
 func NewAnalyzer(opts ...Option) *SchemaAnalyzer {
-	o = applyOptionsWithDefaults(opts)
+	return newAnalyzer(applyOptionsWithDefaults(opts))
+}
+
+func newAnalyzer(o options) *SchemaAnalyzer {
+	resolver := o.resolver
+	if resolver == nil {
+		resolver = jsonschema.NewResolver()
+	}
 
 	a := &SchemaAnalyzer{
-		options:  applyOptionsWithDefaults(opts),
-		schemas:  newSchemaGraph(),
-		packages: newPackageTree(),
-		// contexts:    make(map[graph.Edge[analyzers.UniqueID]]AnalyzedSchemaContext),
-		// readOrder:   list.New(),
-		// namespaces:  make(map[string]namespace),
-		// schemaGraph: graph.NewDiGraph[analyzers.UniqueID](),
-		// packageTree: graph.NewTree[analyzers.UniqueID](),
+		options:             o,
+		resolver:            resolver,
+		schemas:             newSchemaGraph(),
+		packages:            newPackageTree(),
+		documents:           make(map[string]struct{}),
+		sources:             make(map[string]jsonschema.Source),
 		validationsAnalyzer: validations.New(o.validationOptions...),
 	}
 
@@ -191,7 +199,7 @@ func (a *SchemaAnalyzer) orderedPackageIterator(f filters) iter.Seq[AnalyzedPack
 	switch f.Ordering {
 
 	case order.BottomUp:
-		return a.packages.Inverted().TraverseBFS()
+		return a.packages.TraverseBottomUp()
 
 	case order.TopDown:
 		fallthrough
@@ -216,176 +224,379 @@ func (a *SchemaAnalyzer) Packages(filterSpecs ...Filter) iter.Seq[AnalyzedPackag
 }
 
 // Analyze a single JSON schema.
+//
+// The first analyzed schema is located at the base URI of the analyzer (see [WithBaseURI]), and subsequent ones
+// as "schema-{index}.json" relative to this URI. [SchemaAnalyzer.AnalyzeDocument] locates a schema explicitly.
 func (a *SchemaAnalyzer) Analyze(schema jsonschema.Schema) error {
-	// 1. package analysis
-	for analyzed := range a.exploreSchemas(schema) { // all analyzed schemas, depth-first
-		schemaNode, err := a.schemas.AddArc(analyzed)
-		//edge := a.schemas.NewEdge(analyzed, analyzed, analyzed.Context())
-		if err != nil {
-			if errors.Is(graph.ErrCycleFound) {
-				// a cycle is found : keep the cycle for later processing
-				//a.schema.AddCycle()
-			}
+	uri, err := a.documentURI(len(a.roots))
+	if err != nil {
+		return err
+	}
 
-			return err // should not happen
-		}
+	return a.AnalyzeDocument(uri, schema)
+}
 
-		pth := analyzed.Path()
-		if pth == "" {
-			continue
-		}
+// AnalyzeDocument analyzes a JSON schema document, located at some URI against which its "$ref" s are resolved.
+//
+// All the schemas which are reachable from the root schema are analyzed, following "$ref" s to other documents,
+// as well as all the definitions of these documents.
+// Boolean schemas are not analyzed.
+//
+// Refactoring options are not supported yet.
+func (a *SchemaAnalyzer) AnalyzeDocument(uri string, schema jsonschema.Schema) error {
+	uri, _, _ = strings.Cut(uri, "#")
+	if err := a.resolver.AddDocument(uri, schema); err != nil {
+		return fmt.Errorf("%w: %w", err, ErrAnalyze)
+	}
+	a.rootURIs = append(a.rootURIs, uri)
+	if source := schema.Source(); !source.IsZero() {
+		a.sources[uri] = source
+	}
+	a.documents[uri] = struct{}{}
 
-		// found a path: add package
-		packageNode, found := a.packages.AddPath(pth)
+	root, err := a.resolver.Resolve("#", uri)
+	if err != nil {
+		return fmt.Errorf("%w: %w", err, ErrAnalyze)
+	}
 
-		if err = packageNode.AddDependency(analyzed); err != nil {
-			// a cycle is found in dependencies: keep the package-level cycle for later refact
-		}
+	analyzed, err := a.explore(root, a.documentName(uri))
+	if err != nil {
+		return err
+	}
 
-		if found {
-			continue
-		}
+	if analyzed != nil {
+		a.roots = append(a.roots, analyzed.id)
 	}
 
-	// 2. package refactoring : may invoke callback
-	if err := a.refactorPackages(); err != nil {
-		// TODO: log / audit
-		return err
+	a.analyzeSubTypes()
+	a.analyzeSchemaCycles()
+
+	return nil
+}
+
+// documentURI locates the root schema at index i.
+func (a *SchemaAnalyzer) documentURI(i int) (string, error) {
+	if i == 0 {
+		return a.baseURI, nil
 	}
 
-	// now packages form a tree and packagesDependencies is a DAG
+	name := fmt.Sprintf("schema-%d.json", i)
+	if a.baseURI == "" {
+		return name, nil
+	}
 
-	// 3. schema cycles processing
-	if err := a.analyzeSchemaCycles(); err != nil {
-		// TODO: log / audit
-		return err
+	base, err := url.Parse(a.baseURI)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URI %q: %w: %w", a.baseURI, err, ErrAnalyze)
 	}
 
-	// 4. schema refactoring actions
-	if err := a.refactorSchemas(); err != nil {
-		// TODO: log / audit
-		return err
+	return base.ResolveReference(&url.URL{Path: name}).String(), nil
+}
+
+// explore a schema and its dependencies, depth-first.
+//
+// A schema is named when it is a definition, the target of a "$ref" or when it has an "$id".
+func (a *SchemaAnalyzer) explore(resolved jsonschema.ResolvedSchema, name string) (*AnalyzedSchema, error) {
+	if !resolved.IsObject() {
+		return nil, nil
 	}
 
-	// 5. schema naming callbacks
-	if err := a.visitSchemas(); err != nil {
-		// TODO: log / audit
-		return err
+	id := analyzers.UniqueID(resolved.String())
+	if analyzed, found := a.schemas.node(id); found {
+		if analyzed.IsAnonymous() && name != "" {
+			analyzed.name = name
+		}
+
+		return analyzed, nil
 	}
 
-	return nil
+	analyzed := a.newSchema(id, resolved, name)
+	_, _ = a.schemas.AddNode(id, analyzed)
+	pkg, _ := a.packages.AddPath(analyzed.path)
+	pkg.schemas = append(pkg.schemas, analyzed)
+
+	if err := a.exploreRefs(analyzed, resolved); err != nil {
+		return nil, err
+	}
+
+	if err := a.exploreSubschemas(analyzed, resolved); err != nil {
+		return nil, err
+	}
+
+	if err := a.exploreMapping(analyzed, resolved); err != nil {
+		return nil, err
+	}
+
+	return analyzed, nil
 }
 
-func (a *SchemaAnalyzer) refPath(schema jsonschema.Schema) string {
-	return ""
+// exploreRefs explores the targets of "$ref" and "$dynamicRef".
+func (a *SchemaAnalyzer) exploreRefs(analyzed *AnalyzedSchema, resolved jsonschema.ResolvedSchema) error {
+	for _, keyword := range []string{"$ref", "$dynamicRef"} {
+		ref, ok := keywords.StringAt(resolved.Document, keyword)
+		if !ok {
+			continue
+		}
+
+		dependency, err := a.exploreRef(analyzed, resolved, ref)
+		if err != nil {
+			return err
+		}
+
+		if dependency != nil {
+			a.schemas.link(analyzed, dependency, AnalyzedSchemaContext{linkType: SchemaRelationRef, key: keyword, index: -1})
+		}
+	}
+
+	return nil
 }
 
-func (a *SchemaAnalyzer) analyzeMetadata(schema jsonschema.Schema, analyzed *AnalyzedSchema) {
-	if !schema.HasMetadata() {
-		return
+func (a *SchemaAnalyzer) exploreRef(analyzed *AnalyzedSchema, resolved jsonschema.ResolvedSchema, ref string) (*AnalyzedSchema, error) {
+	target, err := a.resolver.Resolve(ref, resolved.BaseURI())
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %q in %s: %w: %w", ref, a.describe(analyzed.id), err, ErrAnalyze)
 	}
 
-	meta := Metadata{
-		//ID:
-		// Path:
-		Metadata: schema.Metadata(),
+	if err := a.exploreDefinitions(target.DocumentURI()); err != nil {
+		return nil, err
 	}
-	analyzed.meta = meta
+
+	return a.explore(target, a.nameOf(target))
 }
 
-func (a *SchemaAnalyzer) refIsAlreadyAnalyzed(schema jsonschema.Schema) bool {
-	if !schema.HasRef() {
-		return false
+// exploreDefinitions explores the definitions of a document reached by a "$ref", e.g. to find subtypes
+// of a base type in the same document.
+func (a *SchemaAnalyzer) exploreDefinitions(uri string) error {
+	if _, explored := a.documents[uri]; explored {
+		return nil
+	}
+	a.documents[uri] = struct{}{}
+
+	root, err := a.resolver.Resolve("#", uri)
+	if err != nil {
+		return fmt.Errorf("cannot locate %s: %w: %w", uri, err, ErrAnalyze)
 	}
 
-	ref := schema.Ref()
-	_, found := a.schemas.SchemaByPath(ref.String())
+	for sub := range jsonschema.Subschemas(root.Document, root.Version()) {
+		if !isDefinitions(sub.Keyword) || !sub.Schema.IsObject() {
+			continue
+		}
+
+		definition, err := a.resolver.Resolve(keywords.FragmentRef(sub.Pointer()), uri)
+		if err != nil {
+			return fmt.Errorf("cannot locate %s: %w: %w", uri, err, ErrAnalyze)
+		}
+
+		if _, err := a.explore(definition, sub.Name); err != nil {
+			return err
+		}
+	}
 
-	return found
+	return nil
 }
 
-func (a *SchemaAnalyzer) exploreSchemas(schema jsonschema.Schema) iter.Seq[AnalyzedSchema] {
-	return func(yield func(AnalyzedSchema) bool) {
-		// TODO: options if we want to explore unused stuff (e.g. subtypes in allOf)
-		var analyzed AnalyzedSchema
+// exploreSubschemas explores the subschemas of a schema. Definitions are explored, but they are not dependencies.
+func (a *SchemaAnalyzer) exploreSubschemas(analyzed *AnalyzedSchema, resolved jsonschema.ResolvedSchema) error {
+	version := resolved.Version()
 
-		// TODO: do we have it already
+	for sub := range jsonschema.Subschemas(resolved.Document, version) {
+		if !sub.Schema.IsObject() {
+			continue
+		}
 
-		// TODO handle extensions
+		child, err := a.resolver.Resolve(keywords.FragmentRef(resolved.Pointer()+sub.Pointer()), resolved.DocumentURI())
+		if err != nil {
+			return fmt.Errorf("cannot locate %s: %w: %w", analyzed.id, err, ErrAnalyze)
+		}
 
-		if a.refIsAlreadyAnalyzed(schema) {
-			if !yield(analyzed) {
-				return
+		if isDefinitions(sub.Keyword) {
+			if _, err := a.explore(child, sub.Name); err != nil {
+				return err
 			}
-		} else {
-			if schema.HasRef()
 
-			for subschema := range a.exploreSchemas(refSchema) {
-				if !yield(subschema) {
-					return
-				}
-			}
+			continue
 		}
 
-		a.analyzeMetadata(schema, &analyzed)
+		dependency, err := a.explore(child, "")
+		if err != nil {
+			return err
+		}
 
-		for propertyName, propertySchema := range schema.Properties() {
-			if !yield(analyzed) {
-				return
-			}
+		c := AnalyzedSchemaContext{
+			linkType: relationOf(sub, resolved.Document, version),
+			key:      sub.Name,
+			index:    sub.Index,
 		}
+		a.schemas.link(analyzed, dependency, c)
 
-		for propertyName, propertySchema := range schema.PatternProperties() {
-			if !yield(analyzed) {
-				return
+		switch c.linkType {
+		case SchemaRelationProperty:
+			analyzed.properties = append(analyzed.properties, dependency)
+			if dependency.parentProperty == "" {
+				dependency.parentProperty = sub.Name
 			}
+		case SchemaRelationAllOf:
+			dependency.parentAllOf = cmp.Or(dependency.parentAllOf, analyzed)
+		case SchemaRelationAnyOf:
+			dependency.parentAnyOf = cmp.Or(dependency.parentAnyOf, analyzed)
+		case SchemaRelationOneOf:
+			dependency.parentOneOf = cmp.Or(dependency.parentOneOf, analyzed)
 		}
+	}
 
-		for propertyName, propertySchema := range schema.DependentSchemas() {
-			if !yield(analyzed) {
-				return
-			}
+	return nil
+}
+
+// exploreMapping explores the targets of the mapping of a discriminator, which are subtypes of the schema.
+//
+// Bare schema names in the mapping are not references: such subtypes are found with "allOf" (see [SchemaAnalyzer.analyzeSubTypes]).
+func (a *SchemaAnalyzer) exploreMapping(analyzed *AnalyzedSchema, resolved jsonschema.ResolvedSchema) error {
+	discriminator, ok := resolved.AtKey("discriminator")
+	if !ok {
+		return nil
+	}
+
+	mapping, ok := discriminator.AtKey("mapping")
+	if !ok || !mapping.IsObject() {
+		return nil
+	}
+
+	for _, value := range mapping.Pairs() {
+		if !value.IsString() {
+			continue
 		}
 
-		if schema.HasAdditionalProperties() {
-			if !yield(analyzed) {
-				return
-			}
+		v, _ := value.Value()
+		ref := v.String()
+		if !strings.ContainsAny(ref, "#/") {
+			continue
 		}
 
-		for index, itemsSchema := range schema.TupleItems() {
-			if !yield(analyzed) {
-				return
-			}
+		subType, err := a.exploreRef(analyzed, resolved, ref)
+		if err != nil {
+			return err
 		}
 
-		if schema.HasTupleAdditionalItems() {
-			if !yield(analyzed) {
-				return
-			}
+		if subType != nil {
+			addSubType(analyzed, subType)
 		}
+	}
 
-		for index, allOfSchema := range schema.AllOf() {
-			if !yield(analyzed) {
-				return
-			}
+	return nil
+}
+
+// analyzeSubTypes finds the subtypes of base types with a discriminator: the schemas with a base type as a member
+// of an "allOf", possibly through a "$ref".
+func (a *SchemaAnalyzer) analyzeSubTypes() {
+	for e := range a.schemas.Edges() {
+		c := e.Value()
+		if c.linkType != SchemaRelationAllOf {
+			continue
 		}
 
-		for index, allOfSchema := range schema.AnyOf() {
-			if !yield(analyzed) {
-				return
-			}
+		if base, ok := a.baseType(c.to); ok && base != c.from {
+			addSubType(base, c.from)
 		}
+	}
+}
 
-		for index, allOfSchema := range schema.OneOf() {
-			if !yield(analyzed) {
-				return
+// baseType yields the schema with a discriminator that is a member of an "allOf", possibly through a "$ref".
+func (a *SchemaAnalyzer) baseType(member *AnalyzedSchema) (*AnalyzedSchema, bool) {
+	if _, ok := member.document.AtKey("discriminator"); ok {
+		return member, true
+	}
+
+	for c := range a.schemas.Dependencies(member.id) {
+		if c.linkType != SchemaRelationRef {
+			continue
+		}
+
+		if _, ok := c.to.document.AtKey("discriminator"); ok {
+			return c.to, true
+		}
+	}
+
+	return nil, false
+}
+
+func addSubType(base, subType *AnalyzedSchema) {
+	if slices.Contains(base.subTypes, subType) {
+		return
+	}
+
+	base.subTypes = append(base.subTypes, subType)
+	if subType.parentBaseType == nil {
+		subType.parentBaseType = base
+	}
+}
+
+// sourceOf a schema located by "uri#pointer", if its document has been loaded from a known source.
+func (a *SchemaAnalyzer) sourceOf(location string) jsonschema.Source {
+	uri, pointer, _ := strings.Cut(location, "#")
+	source, ok := a.sources[uri]
+	if !ok {
+		return jsonschema.Source{}
+	}
+
+	return source.At(pointer)
+}
+
+// describe a schema in error messages, with its source when known.
+func (a *SchemaAnalyzer) describe(id analyzers.UniqueID) string {
+	if source := a.sourceOf(string(id)); !source.IsZero() {
+		return string(id) + " (" + source.String() + ")"
+	}
+
+	return string(id)
+}
+
+// analyzeSchemaCycles marks the schemas which belong to a cycle of dependencies.
+func (a *SchemaAnalyzer) analyzeSchemaCycles() {
+	for _, cycle := range a.schemas.Cycles() {
+		for _, id := range cycle {
+			if analyzed, found := a.schemas.node(id); found {
+				analyzed.isCircular = true
 			}
 		}
 	}
 }
 
-// Analyze a collection of JSON schemas to reason about their structure.
+// newSchema builds an analyzed schema.
+func (a *SchemaAnalyzer) newSchema(id analyzers.UniqueID, resolved jsonschema.ResolvedSchema, name string) *AnalyzedSchema {
+	analyzed := &AnalyzedSchema{
+		analyzedObject: analyzedObject{
+			id:            id,
+			name:          name,
+			path:          a.packageOf(resolved.DocumentURI(), resolved.Pointer()),
+			RequiredIndex: -1,
+		},
+		document: resolved.Schema,
+	}
+
+	analyzed.dollarID, _ = keywords.StringAt(resolved.Document, "$id")
+	analyzed.ref, _ = keywords.StringAt(resolved.Document, "$ref")
+	analyzed.pattern, _ = keywords.StringAt(resolved.Document, "pattern")
+	analyzed.format, _ = keywords.StringAt(resolved.Document, "format")
+	if analyzed.name == "" && analyzed.dollarID != "" {
+		analyzed.name = a.nameOf(resolved)
+	}
+	analyzed.originalName = analyzed.name
+
+	analyzed.kind, analyzed.scalarKind, analyzed.polymorphism = kindOf(resolved.Document, resolved.Version())
+	analyzed.meta = Metadata{
+		ID:       id,
+		Path:     analyzed.path,
+		Metadata: resolved.Metadata(),
+	}
+
+	if resolved.HasExtensions() {
+		analyzed.extensions = Extensions(maps.Clone(resolved.Extensions()))
+	}
+
+	return analyzed
+}
+
+// AnalyzeCollection analyzes a collection of JSON schemas to reason about their structure.
 func (a *SchemaAnalyzer) AnalyzeCollection(schemas jsonschema.Collection) error {
 	// TODO: merge collection
 	for schema := range schemas.Schemas() {
@@ -405,7 +616,7 @@ func (a *SchemaAnalyzer) orderedSchemaIterator(f filters) iter.Seq[AnalyzedSchem
 	switch f.Ordering {
 
 	case order.BottomUp:
-		return a.schemas.Inverted().TraverseTopological()
+		return a.schemas.TraverseBottomUp()
 
 	case order.TopDown:
 		return a.schemas.TraverseDFS()
@@ -420,14 +631,20 @@ func (a *SchemaAnalyzer) orderedSchemaIterator(f filters) iter.Seq[AnalyzedSchem
 // AnalyzedSchemas yields the analyzed schemas according to some filter expression.
 func (a *SchemaAnalyzer) AnalyzedSchemas(filterSpecs ...Filter) iter.Seq[AnalyzedSchema] {
 	filter := applyFiltersWithDefault(filterSpecs)
-	iterator := a.orderedSchemaIterator(filter)
 
-	if filter.FilterFunc != nil {
-		iterator = typeutils.FilterIter(iterator, filter.FilterFunc)
-	}
+	return typeutils.FilterIter(a.orderedSchemaIterator(filter), filter.keeps)
+}
 
-	// TODO: add index
-	return iterator
+// Schemas yields the analyzed root schemas.
+func (a *SchemaAnalyzer) Schemas() iter.Seq[jsonschema.Schema] {
+	return func(yield func(jsonschema.Schema) bool) {
+		for _, id := range a.roots {
+			root, _ := a.schemas.node(id)
+			if !yield(root.document) {
+				return
+			}
+		}
+	}
 }
 
 // Len indicates how many unitary schemas are held by the analyzer.
@@ -435,12 +652,13 @@ func (a *SchemaAnalyzer) Len() int {
 	return a.schemas.Len()
 }
 
+// Encode writes out the analyzed root schemas.
 func (a *SchemaAnalyzer) Encode(w io.Writer) error {
 	// TODO: options to remove extensions, etc.
-	for node := range a.schemas.Nodes() {
-		schema := node.document
-		if err := schema.document.Encode(&w); err != nil {
-			return nil, err
+	for _, id := range a.roots {
+		root, _ := a.schemas.node(id)
+		if err := root.document.Encode(w); err != nil {
+			return err
 		}
 	}
 
@@ -0,0 +1,182 @@
+package structural
+
+import (
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/analyzers"
+	"github.com/fredbi/core/jsonschema/analyzers/structural/order"
+)
+
+const (
+	testBase   = "https://example.com/api/main.json"
+	testRemote = "https://example.com/api/models/pet.json"
+)
+
+const testMain = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"properties": {
+		"pet": {"$ref": "models/pet.json#/$defs/pet"},
+		"owner": {"$ref": "#/$defs/owner"},
+		"tags": {"type": "array", "items": {"type": "string"}}
+	},
+	"$defs": {
+		"owner": {
+			"type": "object",
+			"properties": {
+				"friends": {"type": "array", "items": {"$ref": "#/$defs/owner"}}
+			}
+		}
+	}
+}`
+
+const testPets = `{
+	"$defs": {
+		"pet": {
+			"type": "object",
+			"discriminator": {"propertyName": "kind"},
+			"properties": {
+				"kind": {"$ref": "#/$defs/kind"}
+			}
+		},
+		"cat": {
+			"allOf": [
+				{"$ref": "#/$defs/pet"},
+				{"type": "object", "properties": {"lives": {"type": "integer"}}}
+			]
+		},
+		"kind": {"enum": ["cat", "dog"], "type": "string"}
+	}
+}`
+
+func TestAnalyze(t *testing.T) {
+	analyze := func(t *testing.T) *SchemaAnalyzer {
+		t.Helper()
+
+		resolver := jsonschema.NewResolver()
+		require.NoError(t, resolver.AddDocument(testRemote, mustSchema(t, testPets)))
+
+		a := NewAnalyzer(WithResolver(resolver), WithBaseURI(testBase))
+		require.NoError(t, a.Analyze(mustSchema(t, testMain)))
+
+		return a
+	}
+
+	t.Run("should identify schemas by their location", func(t *testing.T) {
+		a := analyze(t)
+
+		root, ok := a.SchemaByID(analyzers.UniqueID(testBase + "#"))
+		require.True(t, ok)
+		assert.Equal(t, "main", root.Name())
+		assert.True(t, root.IsObject())
+		assert.True(t, root.IsRoot())
+		assert.Equal(t, 3, root.NumProperties())
+
+		tags, ok := a.SchemaByID(analyzers.UniqueID(testBase + "#/properties/tags"))
+		require.True(t, ok)
+		assert.True(t, tags.IsAnonymous())
+		assert.True(t, tags.IsArray())
+		assert.Equal(t, "tags", tags.ParentProperty())
+
+		items, ok := a.SchemaByID(analyzers.UniqueID(testBase + "#/properties/tags/items"))
+		require.True(t, ok)
+		assert.True(t, items.IsScalar())
+		assert.Equal(t, analyzers.ScalarKindString, items.ScalarKind())
+	})
+
+	t.Run("should name definitions and the targets of $ref", func(t *testing.T) {
+		a := analyze(t)
+
+		names := slices.Sorted(schemaNames(a.AnalyzedSchemas(OnlyNamedSchemas())))
+		assert.Equal(t, []string{"cat", "kind", "main", "owner", "pet"}, names)
+
+		kinds := slices.Collect(schemaNames(a.AnalyzedSchemas(OnlyEnumSchemas())))
+		assert.Equal(t, []string{"kind"}, kinds)
+	})
+
+	t.Run("should place remote documents in packages", func(t *testing.T) {
+		a := analyze(t)
+
+		pet, ok := a.SchemaByID(analyzers.UniqueID(testRemote + "#/$defs/pet"))
+		require.True(t, ok)
+		assert.Equal(t, "models/pet", pet.Path())
+
+		owner, ok := a.SchemaByID(analyzers.UniqueID(testBase + "#/$defs/owner"))
+		require.True(t, ok)
+		assert.Empty(t, owner.Path())
+
+		paths := slices.Collect(a.PackagePaths())
+		assert.Equal(t, []string{"", "models", "models/pet"}, paths)
+	})
+
+	t.Run("should mark circular schemas", func(t *testing.T) {
+		a := analyze(t)
+
+		owner, ok := a.SchemaByID(analyzers.UniqueID(testBase + "#/$defs/owner"))
+		require.True(t, ok)
+		assert.True(t, owner.IsCircular())
+
+		root, ok := a.SchemaByID(analyzers.UniqueID(testBase + "#"))
+		require.True(t, ok)
+		assert.False(t, root.IsCircular())
+	})
+
+	t.Run("should find subtypes of a base type with a discriminator", func(t *testing.T) {
+		a := analyze(t)
+
+		cat, ok := a.SchemaByID(analyzers.UniqueID(testRemote + "#/$defs/cat"))
+		require.True(t, ok)
+		require.True(t, cat.IsSubType())
+		assert.Equal(t, "pet", cat.BaseType().Name())
+	})
+
+	t.Run("should yield dependencies before the schemas that depend on them", func(t *testing.T) {
+		a := analyze(t)
+
+		names := slices.Collect(schemaNames(a.AnalyzedSchemas(OnlyNamedSchemas(), WithOrderedSchemas(order.BottomUp))))
+		assert.Less(t, slices.Index(names, "kind"), slices.Index(names, "pet"))
+		assert.Less(t, slices.Index(names, "pet"), slices.Index(names, "main"))
+	})
+
+	t.Run("should locate subsequent roots relative to the base URI", func(t *testing.T) {
+		a := NewAnalyzer(WithBaseURI(testBase))
+		require.NoError(t, a.Analyze(mustSchema(t, `{"type": "string"}`)))
+		require.NoError(t, a.Analyze(mustSchema(t, `{"type": "integer"}`)))
+
+		second, ok := a.SchemaByID(analyzers.UniqueID("https://example.com/api/schema-1.json#"))
+		require.True(t, ok)
+		assert.Equal(t, "schema-1", second.Name())
+		assert.Equal(t, 2, a.Len())
+	})
+
+	t.Run("should fail on unresolved $ref", func(t *testing.T) {
+		a := NewAnalyzer(WithBaseURI(testBase))
+		err := a.Analyze(mustSchema(t, `{"$ref": "missing.json"}`))
+		require.ErrorIs(t, err, ErrAnalyze)
+	})
+}
+
+func schemaNames(schemas iter.Seq[AnalyzedSchema]) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for s := range schemas {
+			if !yield(s.Name()) {
+				return
+			}
+		}
+	}
+}
+
+func mustSchema(t *testing.T, input string) jsonschema.Schema {
+	t.Helper()
+
+	s := jsonschema.Make()
+	require.NoError(t, s.UnmarshalJSON([]byte(input)))
+
+	return s
+}
@@ -32,7 +32,7 @@ func (a *SchemaAnalyzer) LogAudit(s AnalyzedSchema, e AuditTrailEntry) {
 		return
 	}
 
-	schema, found := a.index[s.id]
+	schema, found := a.schemas.node(s.id)
 	if !found {
 		return
 	}
@@ -45,7 +45,7 @@ func (a *SchemaAnalyzer) LogAuditPackage(p AnalyzedPackage, e AuditTrailEntry) {
 		return
 	}
 
-	pkg, found := a.pkgIndex[p.id]
+	pkg, found := a.packages.node(p.id)
 	if !found {
 		return
 	}
@@ -58,7 +58,7 @@ func (a *SchemaAnalyzer) MarkSchema(s AnalyzedSchema, e Extensions) {
 		return
 	}
 
-	schema, found := a.index[s.id]
+	schema, found := a.schemas.node(s.id)
 	if !found {
 		return
 	}
@@ -66,17 +66,17 @@ func (a *SchemaAnalyzer) MarkSchema(s AnalyzedSchema, e Extensions) {
 	schema.extensions = typeutils.MergeMaps(schema.extensions, e)
 }
 
-func (a *SchemaAnalyzer) MarkPackage(s AnalyzedPackage, e Extensions) {
+func (a *SchemaAnalyzer) MarkPackage(p AnalyzedPackage, e Extensions) {
 	if len(e) == 0 {
 		return
 	}
 
-	schema, found := a.index[s.id]
+	pkg, found := a.packages.node(p.id)
 	if !found {
 		return
 	}
 
-	schema.extensions = typeutils.MergeMaps(schema.extensions, e)
+	pkg.extensions = typeutils.MergeMaps(pkg.extensions, e)
 }
 
 // auditTrail holds all audit trail entries logged by the analyzer action or callbacks.
@@ -1,8 +1,236 @@
 package structural
 
-import "errors"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/analyzers"
+	"github.com/fredbi/core/jsonschema/analyzers/structural/bundle"
+)
 
 // Bundle reforms a new analyzer by bundling references, optionally applying namespace and naming rules.
+//
+// All analyzed root schemas are bundled into self-contained schemas, with all "$ref" s rewritten as JSON pointers
+// to "$defs". The returned [Analyzer] is built with the same options and analyzes the bundled schemas:
+// its packages reflect the namespace of the bundle.
+//
+// All naming decisions taken while bundling are recorded in the audit trail of the new [Analyzer].
+//
+// Options to discover base types and to group subtypes are not supported yet.
 func (a *SchemaAnalyzer) Bundle() (Analyzer, error) {
-	return nil, errors.New("not implemented") // TODO
+	roots := make([]string, 0, len(a.roots))
+	for _, id := range a.roots {
+		roots = append(roots, string(id))
+	}
+
+	result, err := bundle.New(a.bundlerOptions()...).Bundle(bundleGraph{a: a}, roots...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, ErrBundle)
+	}
+
+	bundled := newAnalyzer(a.options)
+	bundled.resolver = jsonschema.NewResolver()
+
+	for schema := range result.Schemas() {
+		if err := bundled.Analyze(schema); err != nil {
+			return nil, fmt.Errorf("%w: %w", err, ErrBundle)
+		}
+	}
+
+	definitions := make(map[string]bundle.Definition)
+	for def := range result.Definitions() {
+		definitions[def.Location] = def
+	}
+
+	for entry := range result.Audit() {
+		bundled.logBundleAudit(entry, definitions)
+	}
+
+	return bundled, nil
+}
+
+// bundleGraph exposes the schema graph of the analyzer to the [bundle.Bundler].
+type bundleGraph struct {
+	a *SchemaAnalyzer
+}
+
+func (g bundleGraph) Schema(location string) (jsonschema.Schema, bool) {
+	analyzed, found := g.a.schemas.node(analyzers.UniqueID(location))
+	if !found {
+		return jsonschema.Schema{}, false
+	}
+
+	return analyzed.document, true
+}
+
+func (g bundleGraph) Ref(location string) (string, bool) {
+	for c := range g.a.schemas.Dependencies(analyzers.UniqueID(location)) {
+		if c.linkType == SchemaRelationRef && c.key == "$ref" {
+			return string(c.to.id), true
+		}
+	}
+
+	return "", false
+}
+
+func (g bundleGraph) Source(location string) jsonschema.Source {
+	return g.a.sourceOf(location)
+}
+
+// bundlerOptions converts the bundle options of the analyzer into options for the [bundle.Bundler].
+func (a *SchemaAnalyzer) bundlerOptions() []bundle.Option {
+	o := a.options.bundleOptions
+
+	opts := []bundle.Option{
+		bundle.WithStrategy(o.bundleStrategy),
+		bundle.WithAggressiveness(o.bundleAggressiveness),
+		bundle.WithPruneUnused(o.pruneUnused),
+		bundle.WithSingleRoot(o.bundleSingleRoot),
+		bundle.WithEnumsPackage(o.bundleEnumsPackage),
+		bundle.WithBacktrack(o.withBacktrack),
+	}
+
+	if o.bundleNameProvider != nil {
+		opts = append(opts, bundle.WithNameProvider(func(name string, def bundle.Definition) (string, error) {
+			analyzed, _ := a.schemas.SchemaByID(analyzers.UniqueID(def.Location))
+
+			return o.bundleNameProvider.NameSchema(name, analyzed)
+		}))
+	}
+
+	if o.bundlePathProvider != nil {
+		opts = append(opts, bundle.WithPackageNameProvider(func(name, parent string) (string, error) {
+			return o.bundlePathProvider.NamePackage(name, AnalyzedPackage{
+				analyzedObject: analyzedObject{name: name, path: parent},
+			})
+		}))
+	}
+
+	if o.bundleNameIdentifier != nil {
+		opts = append(opts, bundle.WithNameIdentifier(bundleIdentifier(o.bundleNameIdentifier)))
+	}
+
+	if o.bundlePathIdentifier != nil {
+		opts = append(opts, bundle.WithPackageIdentifier(bundleIdentifier(o.bundlePathIdentifier)))
+	}
+
+	if o.bundleNameDeconflicter != nil {
+		opts = append(opts, bundle.WithNameDeconflicter(bundleDeconflicter(o.bundleNameDeconflicter, o.bundleNameIdentifier)))
+	}
+
+	if o.bundlePathDeconflicter != nil {
+		opts = append(opts, bundle.WithPackageDeconflicter(bundleDeconflicter(o.bundlePathDeconflicter, o.bundlePathIdentifier)))
+	}
+
+	return opts
+}
+
+// logBundleAudit records an entry of the audit trail of the [bundle.Bundler] on the bundled schema it applies to.
+//
+// Entries about a relocated schema apply to this schema in the bundle. Other entries, e.g. about a pruned schema,
+// apply to the bundled root schema.
+func (a *SchemaAnalyzer) logBundleAudit(entry bundle.AuditEntry, definitions map[string]bundle.Definition) {
+	var action AuditAction
+	switch entry.Action {
+	case bundle.AuditRelocate, bundle.AuditPrune:
+		action = AuditActionRefactorSchema
+	case bundle.AuditRename, bundle.AuditBacktrack:
+		action = AuditActionRenameSchema
+	case bundle.AuditRenamePackage:
+		action = AuditActionRenamePackage
+	case bundle.AuditDeconflict:
+		action = AuditActionDeconflictName
+	default:
+		return
+	}
+
+	e := AuditTrailEntry{
+		Action:      action,
+		Originator:  "bundle",
+		Description: entry.String(),
+	}
+
+	if entry.Action == bundle.AuditRenamePackage {
+		if pkg, found := a.packages.PackageByID(packageID(entry.Location)); found {
+			a.LogAuditPackage(pkg, e)
+		}
+
+		return
+	}
+
+	if entry.Index >= len(a.roots) {
+		return
+	}
+
+	id := a.roots[entry.Index]
+	if def, relocated := definitions[entry.Location]; relocated {
+		uri, _, _ := strings.Cut(string(id), "#")
+		id = analyzers.UniqueID(uri + "#" + def.Pointer)
+	}
+
+	if analyzed, found := a.schemas.SchemaByID(id); found {
+		a.LogAudit(analyzed, e)
+	}
+}
+
+func bundleIdentifier(unique UniqueIdentifier) bundle.Identifier {
+	return func(name string) string {
+		return string(unique.Hash(name))
+	}
+}
+
+func bundleDeconflicter(deconflicter Deconflicter, unique UniqueIdentifier) bundle.Deconflicter {
+	if unique == nil {
+		unique = func(name string) Ident { return Ident(name) }
+	}
+
+	return func(name string, ns bundle.Namespace) (string, error) {
+		v := bundleNamespace{ns: ns, unique: unique}
+		if backtrackable, ok := ns.(bundle.BacktrackableNamespace); ok {
+			return deconflicter(name, backtrackableBundleNamespace{bundleNamespace: v, backtrackable: backtrackable})
+		}
+
+		return deconflicter(name, v)
+	}
+}
+
+// bundleNamespace exposes a [bundle.Namespace] as a [Namespace].
+type bundleNamespace struct {
+	ns     bundle.Namespace
+	unique UniqueIdentifier
+}
+
+func (n bundleNamespace) Path() string {
+	return n.ns.Path()
+}
+
+func (n bundleNamespace) CheckNoConflict(ident Ident) bool {
+	_, taken := n.Meta(ident)
+
+	return !taken
+}
+
+func (n bundleNamespace) Meta(ident Ident) (ConflictMeta, bool) {
+	// identifiers are the names of entries in a bundle.Namespace
+	entry, ok := n.ns.Owner(string(ident))
+	if !ok {
+		return ConflictMeta{}, false
+	}
+
+	return ConflictMeta{
+		Ident:    n.unique.Hash(entry.Name),
+		Name:     entry.Name,
+		Metadata: entry,
+	}, true
+}
+
+type backtrackableBundleNamespace struct {
+	bundleNamespace
+
+	backtrackable bundle.BacktrackableNamespace
+}
+
+func (n backtrackableBundleNamespace) Backtrack(resolved ConflictMeta) bool {
+	return n.backtrackable.Backtrack(string(resolved.Ident), resolved.Name)
 }
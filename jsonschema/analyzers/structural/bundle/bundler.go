@@ -0,0 +1,531 @@
+package bundle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+)
+
+// Bundler knows how to bundle JSON schemas into self-contained documents.
+type Bundler struct {
+	*options
+}
+
+// New [Bundler].
+func New(opts ...Option) *Bundler {
+	return &Bundler{
+		options: optionsWithDefaults(opts),
+	}
+}
+
+// Bundle the root schemas of a [Graph] into self-contained documents.
+//
+// Roots are the locations of schemas in the [Graph], e.g. "https://example.com/schema.json#".
+//
+// All "$ref" s are rewritten as JSON pointers in the bundled document. Schemas from remote documents are relocated
+// under "$defs" (or "definitions" prior to draft 2019), and so are local schemas with the [Eager] aggressiveness.
+// Relocated schemas are named after their original location, unless a [NameProvider] is specified.
+//
+// Since references are rewritten as JSON pointers from the root of the bundle, "$id" s in subschemas are removed.
+// "$dynamicRef" s are left unchanged.
+//
+// It fails with an error wrapping [ErrBundle] whenever a root or the target of a reference is not in the [Graph],
+// or a name cannot be deconflicted.
+func (b *Bundler) Bundle(g Graph, roots ...string) (Result, error) {
+	locations := make([]location, 0, len(roots))
+	schemas := make([]jsonschema.Schema, 0, len(roots))
+	for _, root := range roots {
+		sch, ok := g.Schema(root)
+		if !ok {
+			return Result{}, fmt.Errorf("unknown root schema %s: %w", root, ErrBundle)
+		}
+
+		uri, pointer, _ := strings.Cut(root, "#")
+		locations = append(locations, location{uri: uri, pointer: pointer, doc: sch.Document})
+		schemas = append(schemas, sch)
+	}
+
+	var result Result
+
+	if len(roots) > 1 && b.singleRoot {
+		c := b.bundler(g)
+		if err := c.bundleRoots(locations, schemas); err != nil {
+			return Result{}, err
+		}
+
+		return c.result(result, schemas[0])
+	}
+
+	for i, sch := range schemas {
+		c := b.bundler(g)
+		if err := c.bundle(locations[i], sch); err != nil {
+			return Result{}, err
+		}
+
+		var err error
+		if result, err = c.result(result, sch); err != nil {
+			return Result{}, err
+		}
+	}
+
+	return result, nil
+}
+
+func (b *Bundler) bundler(g Graph) *bundler {
+	return &bundler{
+		options: b.options,
+		graph:   g,
+		visited: make(map[string]struct{}),
+		kept:    make(map[string]struct{}),
+		refs:    make(map[string]location),
+		defs:    make(map[string]*Definition),
+		sources: make(map[string]location),
+		root:    newPkg("", nil),
+	}
+}
+
+// location of a schema, as a JSON pointer in a document.
+type location struct {
+	uri     string
+	pointer string
+	doc     json.Document
+}
+
+func (l location) id() string {
+	return l.uri + "#" + l.pointer
+}
+
+func (l location) at(doc json.Document, tokens ...string) location {
+	for _, token := range tokens {
+		l.pointer += "/" + json.EscapeToken(token)
+	}
+	l.doc = doc
+
+	return l
+}
+
+// bundler holds the state of a single bundle.
+type bundler struct {
+	*options
+
+	graph       Graph
+	main        location
+	rootURI     string // the document which locates the packages of relocated schemas
+	synthetic   bool   // the main document is a fictitious root
+	version     jsonschema.Version
+	defsKeyword string
+
+	visited map[string]struct{}
+	kept    map[string]struct{}    // local definitions which are used, by location
+	refs    map[string]location    // the targets of "$ref" s, by location of the schema holding the "$ref"
+	defs    map[string]*Definition // relocated schemas, by original location
+	sources map[string]location    // the original locations of relocated schemas
+	order   []*Definition          // relocated schemas, in order of discovery
+	root    *pkg
+	audit   []AuditEntry
+}
+
+// bundle a single schema.
+func (b *bundler) bundle(main location, sch jsonschema.Schema) error {
+	b.main = main
+	b.rootURI = main.uri
+	b.setVersion(sch.Version())
+
+	if err := b.walk(b.main); err != nil {
+		return err
+	}
+
+	return b.name()
+}
+
+// bundleRoots bundles several schemas under a fictitious root.
+func (b *bundler) bundleRoots(roots []location, schemas []jsonschema.Schema) error {
+	b.synthetic = true
+	b.rootURI = roots[0].uri
+	b.setVersion(schemas[0].Version())
+
+	for i, sch := range schemas {
+		if err := b.target(roots[i], sch); err != nil {
+			return err
+		}
+	}
+
+	return b.name()
+}
+
+func (b *bundler) setVersion(version jsonschema.Version) {
+	b.version = version
+	b.defsKeyword = "$defs"
+	if version != jsonschema.VersionUndefined && version.Less(jsonschema.VersionDraft2019) {
+		b.defsKeyword = "definitions"
+	}
+}
+
+func (b *bundler) isLocal(loc location) bool {
+	return !b.synthetic && loc.uri == b.main.uri
+}
+
+// walk a schema to find all the "$ref" s.
+func (b *bundler) walk(loc location) error {
+	id := loc.id()
+	if _, seen := b.visited[id]; seen {
+		return nil
+	}
+	b.visited[id] = struct{}{}
+
+	if !loc.doc.IsObject() {
+		return nil
+	}
+
+	if value, ok := loc.doc.AtKey("$ref"); ok && value.IsString() {
+		target, sch, err := b.resolve(loc)
+		if err != nil {
+			return err
+		}
+
+		b.refs[id] = target
+		if err := b.target(target, sch); err != nil {
+			return err
+		}
+	}
+
+	for child, keyword := range b.children(loc) {
+		if isDefinitions(keyword) && b.pruneUnused {
+			// unused definitions are pruned: only the referenced ones are walked
+			continue
+		}
+
+		if err := b.walk(child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolve the "$ref" found in a schema, to its target in the [Graph].
+func (b *bundler) resolve(loc location) (location, jsonschema.Schema, error) {
+	ref, ok := b.graph.Ref(loc.id())
+	if !ok {
+		return location{}, jsonschema.Schema{}, fmt.Errorf("unresolved $ref in %s: %w", b.describe(loc.id()), ErrBundle)
+	}
+
+	sch, ok := b.graph.Schema(ref)
+	if !ok {
+		return location{}, jsonschema.Schema{}, fmt.Errorf("unknown target %s of the $ref in %s: %w", ref, b.describe(loc.id()), ErrBundle)
+	}
+
+	uri, pointer, _ := strings.Cut(ref, "#")
+
+	return location{uri: uri, pointer: pointer, doc: sch.Document}, sch, nil
+}
+
+// target handles the target of a "$ref", which is relocated unless it is a local schema with the [Lazy]
+// aggressiveness.
+func (b *bundler) target(target location, sch jsonschema.Schema) error {
+	if b.isLocal(target) && (b.aggressiveness == Lazy || target.pointer == "") {
+		if err := b.keep(target); err != nil {
+			return err
+		}
+
+		return b.walk(target)
+	}
+
+	id := target.id()
+	if _, relocated := b.defs[id]; !relocated {
+		def := &Definition{
+			Location: id,
+			Schema:   sch,
+			IsEnum:   isEnumOnly(target.doc),
+		}
+		b.defs[id] = def
+		b.sources[id] = target
+		b.order = append(b.order, def)
+	}
+
+	return b.walk(target)
+}
+
+// keep the local definitions that hold a used schema.
+func (b *bundler) keep(target location) error {
+	tokens := pointerTokens(target.pointer)
+	ancestor := b.main
+
+	for i, token := range tokens {
+		doc, ok := ancestor.doc.AtKey(token)
+		if !ok {
+			doc, ok = elemAt(ancestor.doc, token)
+		}
+		if !ok {
+			return nil
+		}
+
+		isDefinition := i > 0 && isDefinitions(tokens[i-1])
+		ancestor = ancestor.at(doc, token)
+
+		if isDefinition {
+			b.kept[ancestor.id()] = struct{}{}
+			if err := b.walk(ancestor); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// name all the relocated schemas.
+func (b *bundler) name() error {
+	b.registerKept()
+
+	for _, def := range b.order {
+		if err := b.nameDefinition(def); err != nil {
+			return err
+		}
+	}
+
+	for _, def := range b.order {
+		def.Package = def.pkg.path()
+		def.Pointer = b.pointerOf(def)
+
+		if target := b.main.uri + "#" + def.Pointer; target != def.Location {
+			b.log(AuditRelocate, def.Location, def.Location, def.Ref())
+		}
+	}
+
+	return nil
+}
+
+// registerKept registers the names of the definitions of the main document that remain in place.
+func (b *bundler) registerKept() {
+	if b.synthetic {
+		return
+	}
+
+	for _, keyword := range []string{"$defs", "definitions"} {
+		container, ok := b.main.doc.AtKey(keyword)
+		if !ok || !container.IsObject() {
+			continue
+		}
+
+		for name, member := range container.Pairs() {
+			loc := b.main.at(member, keyword, name)
+			if !b.stays(loc) {
+				if _, relocated := b.defs[loc.id()]; !relocated {
+					b.log(AuditPrune, loc.id(), "", "")
+				}
+
+				continue
+			}
+
+			if keyword != b.defsKeyword {
+				continue
+			}
+
+			ident := b.nameIdentifier(name)
+			if _, exists := b.root.ns.entries[ident]; !exists {
+				b.root.ns.entries[ident] = &Entry{Name: name, Ident: ident}
+			}
+		}
+	}
+}
+
+// stays tells if a definition remains at its original location.
+func (b *bundler) stays(loc location) bool {
+	if _, relocated := b.defs[loc.id()]; relocated {
+		return false
+	}
+
+	if !b.pruneUnused {
+		return true
+	}
+
+	_, kept := b.kept[loc.id()]
+
+	return kept
+}
+
+func (b *bundler) nameDefinition(def *Definition) error {
+	source := b.sources[def.Location]
+	packages, name := suggest(source.uri, b.rootURI, pointerTokens(source.pointer), b.isLocal(source))
+
+	switch {
+	case b.strategy == Flat:
+		packages = nil
+	case def.IsEnum && b.enumsPackage != "":
+		packages = append(packages, b.enumsPackage)
+	}
+
+	p := b.root
+	for _, segment := range packages {
+		var err error
+		if p, err = b.child(p, segment); err != nil {
+			return err
+		}
+	}
+	def.pkg = p
+
+	if b.nameProvider != nil {
+		provided, err := b.nameProvider(name, *def)
+		if err != nil {
+			return fmt.Errorf("cannot name %s: %w: %w", def.Location, err, ErrBundle)
+		}
+
+		if provided != name {
+			b.log(AuditRename, def.Location, name, provided)
+		}
+		name = provided
+	}
+
+	name, err := b.register(p.ns, name, &Entry{Definition: def}, b.nameIdentifier, b.nameDeconflicter, def.Location)
+	if err != nil {
+		return err
+	}
+	def.Name = name
+
+	return nil
+}
+
+// child package of a package.
+func (b *bundler) child(parent *pkg, segment string) (*pkg, error) {
+	if c, ok := parent.children[segment]; ok {
+		return c, nil
+	}
+
+	name := segment
+	if b.packageNameProvider != nil {
+		provided, err := b.packageNameProvider(segment, parent.path())
+		if err != nil {
+			return nil, fmt.Errorf("cannot name package %q: %w: %w", segment, err, ErrBundle)
+		}
+
+		if provided != segment {
+			b.log(AuditRenamePackage, parent.path(), segment, provided)
+		}
+		name = provided
+	}
+
+	c := newPkg("", parent)
+	name, err := b.register(parent.ns, name, &Entry{IsPackage: true, pkg: c}, b.packageIdentifier, b.packageDeconflicter, parent.path())
+	if err != nil {
+		return nil, err
+	}
+	c.name = name
+	parent.children[segment] = c
+
+	return c, nil
+}
+
+// pointerOf a relocated schema in the bundle.
+func (b *bundler) pointerOf(def *Definition) string {
+	var segments []string
+	for p := def.pkg; p.parent != nil; p = p.parent {
+		segments = append(segments, p.name)
+	}
+
+	var pointer strings.Builder
+	for i := len(segments) - 1; i >= 0; i-- {
+		pointer.WriteString("/" + b.defsKeyword + "/" + json.EscapeToken(segments[i]))
+	}
+	pointer.WriteString("/" + b.defsKeyword + "/" + json.EscapeToken(def.Name))
+
+	return pointer.String()
+}
+
+func (b *bundler) log(action AuditAction, loc, from, to string) {
+	b.audit = append(b.audit, AuditEntry{Action: action, Location: loc, Source: b.origin(loc), From: from, To: to})
+}
+
+// origin is the [jsonschema.Source] of a schema located by "uri#pointer", if its document has been loaded from
+// a known source.
+func (b *bundler) origin(id string) jsonschema.Source {
+	return b.graph.Source(id)
+}
+
+// describe a schema located by "uri#pointer" in error messages, with its source when known.
+func (b *bundler) describe(id string) string {
+	if source := b.origin(id); !source.IsZero() {
+		return id + " (" + source.String() + ")"
+	}
+
+	return id
+}
+
+// result appends the bundled schema to a [Result].
+func (b *bundler) result(result Result, input jsonschema.Schema) (Result, error) {
+	data := b.write()
+
+	sch := jsonschema.Make(jsonschema.WithDollarData(input.UsesDollarData()))
+	if err := sch.UnmarshalJSON(data); err != nil {
+		return Result{}, fmt.Errorf("invalid bundled schema: %w: %w", err, ErrBundle)
+	}
+
+	index := len(result.schemas)
+	result.schemas = append(result.schemas, sch)
+	for _, def := range b.order {
+		def.Index = index
+		result.definitions = append(result.definitions, *def)
+	}
+	for _, entry := range b.audit {
+		entry.Index = index
+		result.audit = append(result.audit, entry)
+	}
+
+	return result, nil
+}
+
+func pointerTokens(pointer string) []string {
+	if pointer == "" {
+		return nil
+	}
+
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = json.UnescapeToken(token)
+	}
+
+	return tokens
+}
+
+func elemAt(d json.Document, token string) (json.Document, bool) {
+	i, err := strconv.Atoi(token)
+	if err != nil || !d.IsArray() {
+		return json.EmptyDocument, false
+	}
+
+	return d.Elem(i)
+}
+
+func isDefinitions(keyword string) bool {
+	return keyword == "$defs" || keyword == "definitions"
+}
+
+//nolint:gochecknoglobals // keywords that may accompany an enum in an enum-only schema
+var enumCompanions = map[string]struct{}{
+	"enum": {}, "const": {}, "type": {}, "title": {}, "description": {}, "$comment": {}, "default": {},
+	"examples": {}, "example": {}, "deprecated": {}, "nullable": {}, "$id": {}, "$schema": {}, "$anchor": {},
+}
+
+// isEnumOnly tells if a schema only holds enum validations.
+func isEnumOnly(d json.Document) bool {
+	if !d.IsObject() {
+		return false
+	}
+
+	_, hasEnum := d.AtKey("enum")
+	_, hasConst := d.AtKey("const")
+	if !hasEnum && !hasConst {
+		return false
+	}
+
+	for key := range d.Pairs() {
+		if _, ok := enumCompanions[key]; !ok && !strings.HasPrefix(key, "x-") {
+			return false
+		}
+	}
+
+	return true
+}
@@ -1,15 +1,31 @@
+// Package bundle knows how to bundle JSON schemas into a single self-contained document.
+//
+// A [Bundler] rewrites all "$ref" s, local or remote, so they point to schemas in the bundled document.
+// It works on a [Graph] of schemas with resolved "$ref" s, as provided by the structural analyzer.
+// Referenced schemas are relocated under "$defs" (or "definitions" prior to draft 2019), either in a flat namespace
+// or in a hierarchy of packages following the location of the original schemas (see [SchemaBundlingStragegy]).
+//
+// Every relocated schema is named: names may be provided, deconflicted and even revised by callbacks.
+// All decisions are recorded in an audit trail.
 package bundle
 
+// SchemaBundlingStragegy tells how relocated schemas are organized in the bundle.
 type SchemaBundlingStragegy uint8
 
 const (
+	// Flat places all relocated schemas in a single namespace: "#/$defs/{name}".
 	Flat SchemaBundlingStragegy = iota
+	// Hierarchical places relocated schemas in packages that follow their original location:
+	// "#/$defs/{package}/$defs/{name}".
 	Hierarchical
 )
 
+// SchemaBundlingAggressiveness tells which schemas are relocated in the bundle.
 type SchemaBundlingAggressiveness uint8
 
 const (
+	// Lazy only relocates schemas from remote documents. Local "$ref" s are only rewritten as JSON pointers.
 	Lazy SchemaBundlingAggressiveness = iota
+	// Eager relocates all referenced schemas, including local ones, into the namespace of the bundle.
 	Eager
 )
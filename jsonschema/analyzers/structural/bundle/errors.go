@@ -0,0 +1,13 @@
+package bundle
+
+// Error is an error raised by the [Bundler].
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrBundle is the generic error raised by this package.
+	ErrBundle Error = "bundle error"
+)
@@ -0,0 +1,17 @@
+package bundle
+
+import "github.com/fredbi/core/jsonschema"
+
+// Graph of the schemas to bundle, with their "$ref" s resolved beforehand.
+//
+// Schemas are located by their document URI and a JSON pointer, e.g. "https://example.com/schema.json#/$defs/pet".
+type Graph interface {
+	// Schema at a location.
+	Schema(location string) (jsonschema.Schema, bool)
+
+	// Ref is the location of the target of the "$ref" of the schema at a location.
+	Ref(location string) (string, bool)
+
+	// Source of the schema at a location, if its document has been loaded from a known source.
+	Source(location string) jsonschema.Source
+}
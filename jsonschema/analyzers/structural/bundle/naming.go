@@ -0,0 +1,271 @@
+package bundle
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// NameProvider names a relocated schema, given a suggested name and the [Definition] to be named for more context.
+//
+// It may return an error if the naming operation is impossible.
+type NameProvider func(name string, def Definition) (string, error)
+
+// PackageNameProvider names a package, given a suggested name and the path of its parent package.
+//
+// It may return an error if the naming operation is impossible.
+type PackageNameProvider func(name, parent string) (string, error)
+
+// Identifier yields a unique identifier for a name: two names with the same identifier are in conflict.
+//
+// Example: an [Identifier] that yields lower-cased names makes "Pet" and "pet" conflict.
+type Identifier func(name string) string
+
+// Deconflicter finds an alternative to a conflicting name in a [Namespace].
+//
+// It may return an error if it fails to find a deconflicted solution.
+//
+// When backtracking is enabled (see [WithBacktrack]), the [Namespace] implements [BacktrackableNamespace]
+// and the [Deconflicter] may rename the current owner of the name instead.
+type Deconflicter func(name string, namespace Namespace) (string, error)
+
+// Entry is a name registered in a [Namespace], either for a package or for a relocated schema.
+type Entry struct {
+	Name      string
+	Ident     string
+	IsPackage bool
+
+	// Definition is the relocated schema, for entries which are not packages
+	Definition *Definition
+
+	pkg *pkg
+}
+
+// Namespace is a set of non-conflicting names in a package of the bundle.
+type Namespace interface {
+	// Path of the package
+	Path() string
+
+	// IsTaken tells if a name is in conflict with a registered name.
+	IsTaken(name string) bool
+
+	// Owner yields the [Entry] in conflict with a name.
+	Owner(name string) (Entry, bool)
+}
+
+// BacktrackableNamespace is a [Namespace] that knows how to backtrack on previous naming decisions.
+type BacktrackableNamespace interface {
+	Namespace
+
+	// Backtrack renames the current owner of a name.
+	//
+	// It returns false if the new name is in conflict.
+	Backtrack(name, newName string) bool
+}
+
+// pkg is a package in the bundle.
+type pkg struct {
+	name     string
+	parent   *pkg
+	ns       *namespace
+	children map[string]*pkg // by suggested name
+}
+
+func newPkg(name string, parent *pkg) *pkg {
+	p := &pkg{
+		name:     name,
+		parent:   parent,
+		children: make(map[string]*pkg),
+	}
+	p.ns = &namespace{pkg: p, entries: make(map[string]*Entry)}
+
+	return p
+}
+
+// path of the package, e.g. "models/enums".
+func (p *pkg) path() string {
+	if p.parent == nil {
+		return ""
+	}
+
+	if parent := p.parent.path(); parent != "" {
+		return parent + "/" + p.name
+	}
+
+	return p.name
+}
+
+type namespace struct {
+	pkg     *pkg
+	entries map[string]*Entry // by identifier
+}
+
+// view of a namespace, from the perspective of an [Identifier].
+type view struct {
+	*namespace
+
+	identifier Identifier
+	bundler    *bundler
+}
+
+func (v view) Path() string {
+	return v.pkg.path()
+}
+
+func (v view) IsTaken(name string) bool {
+	_, taken := v.entries[v.identifier(name)]
+
+	return taken
+}
+
+func (v view) Owner(name string) (Entry, bool) {
+	e, ok := v.entries[v.identifier(name)]
+	if !ok {
+		return Entry{}, false
+	}
+
+	return *e, true
+}
+
+type backtrackable struct {
+	view
+}
+
+func (b backtrackable) Backtrack(name, newName string) bool {
+	ident := b.identifier(name)
+	e, ok := b.entries[ident]
+	if !ok {
+		return false
+	}
+
+	identifier := b.bundler.nameIdentifier
+	if e.IsPackage {
+		identifier = b.bundler.packageIdentifier
+	}
+
+	newIdent := identifier(newName)
+	if _, taken := b.entries[newIdent]; taken && newIdent != ident {
+		return false
+	}
+
+	delete(b.entries, ident)
+	from := e.Name
+	e.Name, e.Ident = newName, newIdent
+	b.entries[newIdent] = e
+
+	location := b.Path()
+	if e.IsPackage {
+		e.pkg.name = newName
+	} else {
+		e.Definition.Name = newName
+		location = e.Definition.Location
+	}
+
+	b.bundler.log(AuditBacktrack, location, from, newName)
+
+	return true
+}
+
+func (b *bundler) viewOf(ns *namespace, identifier Identifier) Namespace {
+	v := view{namespace: ns, identifier: identifier, bundler: b}
+	if b.withBacktrack {
+		return backtrackable{view: v}
+	}
+
+	return v
+}
+
+// register a name in a namespace, deconflicting it if needed.
+func (b *bundler) register(ns *namespace, name string, e *Entry, identifier Identifier, deconflicter Deconflicter, location string) (string, error) {
+	const maxAttempts = 100
+
+	for attempt := 0; ; attempt++ {
+		ident := identifier(name)
+		if _, taken := ns.entries[ident]; !taken {
+			e.Name, e.Ident = name, ident
+			ns.entries[ident] = e
+
+			return name, nil
+		}
+
+		if attempt >= maxAttempts {
+			return "", fmt.Errorf("cannot deconflict name %q in package %q: %w", name, ns.pkg.path(), ErrBundle)
+		}
+
+		alternative, err := deconflicter(name, b.viewOf(ns, identifier))
+		if err != nil {
+			return "", fmt.Errorf("cannot deconflict name %q in package %q: %w: %w", name, ns.pkg.path(), err, ErrBundle)
+		}
+
+		if alternative != name {
+			b.log(AuditDeconflict, location, name, alternative)
+		}
+		name = alternative
+	}
+}
+
+// identity is the default [Identifier].
+func identity(name string) string {
+	return name
+}
+
+// suffixDeconflicter is the default [Deconflicter], which adds a numerical suffix to a name.
+func suffixDeconflicter(name string, ns Namespace) (string, error) {
+	for i := 2; ; i++ {
+		candidate := name + strconv.Itoa(i)
+		if !ns.IsTaken(candidate) {
+			return candidate, nil
+		}
+	}
+}
+
+// suggest a package path and a name for a schema, after its location relative to the root document.
+func suggest(uri, root string, tokens []string, local bool) ([]string, string) {
+	var packages []string
+	if !local {
+		packages = keywords.DocumentPackages(uri, root)
+	}
+
+	for i, token := range tokens {
+		if (token == "$defs" || token == "definitions") && i+2 < len(tokens) {
+			packages = append(packages, tokens[i+1])
+		}
+	}
+
+	if len(tokens) == 0 {
+		if len(packages) == 0 {
+			return nil, "schema"
+		}
+
+		return packages[:len(packages)-1], packages[len(packages)-1]
+	}
+
+	name := tokens[len(tokens)-1]
+	if len(tokens) > 1 {
+		if kind := jsonschema.SubschemaKeyword(name); kind == jsonschema.SubschemaSingle ||
+			kind == jsonschema.SubschemaArray || isIndex(name) {
+			name = tokens[len(tokens)-2] + capitalize(name)
+		}
+	}
+
+	return packages, name
+}
+
+func isIndex(token string) bool {
+	_, err := strconv.Atoi(token)
+
+	return err == nil
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+
+	return string(unicode.ToUpper(r)) + s[size:]
+}
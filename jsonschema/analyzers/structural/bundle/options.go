@@ -0,0 +1,142 @@
+package bundle
+
+// Option customizes the behavior of the [Bundler].
+type Option func(*options)
+
+type options struct {
+	strategy       SchemaBundlingStragegy
+	aggressiveness SchemaBundlingAggressiveness
+	pruneUnused    bool
+	singleRoot     bool
+	enumsPackage   string
+	withBacktrack  bool
+
+	nameProvider     NameProvider
+	nameIdentifier   Identifier
+	nameDeconflicter Deconflicter
+
+	packageNameProvider PackageNameProvider
+	packageIdentifier   Identifier
+	packageDeconflicter Deconflicter
+}
+
+// WithStrategy defines how relocated schemas are organized. The default is [Flat].
+func WithStrategy(strategy SchemaBundlingStragegy) Option {
+	return func(o *options) {
+		o.strategy = strategy
+	}
+}
+
+// WithAggressiveness defines which schemas are relocated. The default is [Lazy].
+func WithAggressiveness(aggressiveness SchemaBundlingAggressiveness) Option {
+	return func(o *options) {
+		o.aggressiveness = aggressiveness
+	}
+}
+
+// WithPruneUnused removes from the bundle the definitions which are not referenced.
+func WithPruneUnused(enabled bool) Option {
+	return func(o *options) {
+		o.pruneUnused = enabled
+	}
+}
+
+// WithSingleRoot bundles several schemas into a single document, with a fictitious root that only holds definitions.
+//
+// By default, every schema is bundled separately.
+func WithSingleRoot(enabled bool) Option {
+	return func(o *options) {
+		o.singleRoot = enabled
+	}
+}
+
+// WithEnumsPackage relocates schemas with only enum validations into a sub-package of their package.
+//
+// This only applies to the [Hierarchical] strategy.
+func WithEnumsPackage(pkg string) Option {
+	return func(o *options) {
+		o.enumsPackage = pkg
+	}
+}
+
+// WithBacktrack allows [Deconflicter] s to rename previously named schemas or packages:
+// the [Namespace] they get implements [BacktrackableNamespace].
+func WithBacktrack(enabled bool) Option {
+	return func(o *options) {
+		o.withBacktrack = enabled
+	}
+}
+
+// WithNameProvider names relocated schemas.
+//
+// By default, schemas are named after their original location, e.g. "#/$defs/pet" yields "pet".
+func WithNameProvider(provider NameProvider) Option {
+	return func(o *options) {
+		o.nameProvider = provider
+	}
+}
+
+// WithNameIdentifier sets the [Identifier] that detects conflicting schema names.
+//
+// By default, names conflict only when they are equal.
+func WithNameIdentifier(identifier Identifier) Option {
+	return func(o *options) {
+		o.nameIdentifier = identifier
+	}
+}
+
+// WithNameDeconflicter sets the [Deconflicter] for schema names.
+//
+// By default, a numerical suffix is added to conflicting names.
+func WithNameDeconflicter(deconflicter Deconflicter) Option {
+	return func(o *options) {
+		o.nameDeconflicter = deconflicter
+	}
+}
+
+// WithPackageNameProvider names packages.
+func WithPackageNameProvider(provider PackageNameProvider) Option {
+	return func(o *options) {
+		o.packageNameProvider = provider
+	}
+}
+
+// WithPackageIdentifier sets the [Identifier] that detects conflicting package names.
+func WithPackageIdentifier(identifier Identifier) Option {
+	return func(o *options) {
+		o.packageIdentifier = identifier
+	}
+}
+
+// WithPackageDeconflicter sets the [Deconflicter] for package names.
+func WithPackageDeconflicter(deconflicter Deconflicter) Option {
+	return func(o *options) {
+		o.packageDeconflicter = deconflicter
+	}
+}
+
+func optionsWithDefaults(opts []Option) *options {
+	o := &options{}
+
+	for _, apply := range opts {
+		apply(o)
+	}
+
+	if o.nameIdentifier == nil {
+		o.nameIdentifier = identity
+	}
+
+	if o.packageIdentifier == nil {
+		o.packageIdentifier = identity
+	}
+
+	if o.nameDeconflicter == nil {
+		o.nameDeconflicter = suffixDeconflicter
+	}
+
+	if o.packageDeconflicter == nil {
+		o.packageDeconflicter = suffixDeconflicter
+	}
+
+	return o
+}
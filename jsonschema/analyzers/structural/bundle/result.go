@@ -0,0 +1,145 @@
+package bundle
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// Definition is a schema relocated in the bundle.
+type Definition struct {
+	// Location of the original schema, as a document URI with a JSON pointer fragment
+	Location string
+
+	// Schema is the original schema
+	Schema jsonschema.Schema
+
+	// Package is the path of the package holding the schema in the bundle, e.g. "models/enums"
+	Package string
+
+	// Name of the schema in its package
+	Name string
+
+	// Pointer is the JSON pointer to the schema in the bundle
+	Pointer string
+
+	// IsEnum tells if the schema only holds enum validations
+	IsEnum bool
+
+	// Index of the bundled schema holding the definition (see [Result.Schemas])
+	Index int
+
+	pkg *pkg
+}
+
+// Ref is the "$ref" to the schema in the bundle.
+func (d Definition) Ref() string {
+	return keywords.FragmentRef(d.Pointer)
+}
+
+// AuditAction categorizes the actions recorded by the [Bundler].
+type AuditAction uint8
+
+const (
+	AuditNone AuditAction = iota
+	// AuditRelocate records that a schema has been moved in the bundle
+	AuditRelocate
+	// AuditRename records that a name has been changed by a [NameProvider]
+	AuditRename
+	// AuditRenamePackage records that a package name has been changed by a [PackageNameProvider]
+	AuditRenamePackage
+	// AuditDeconflict records that a conflicting name has been changed by a [Deconflicter]
+	AuditDeconflict
+	// AuditBacktrack records that a previously registered name has been changed by a [Deconflicter]
+	AuditBacktrack
+	// AuditPrune records that an unused definition has been removed
+	AuditPrune
+)
+
+func (a AuditAction) String() string {
+	switch a {
+	case AuditRelocate:
+		return "relocate"
+	case AuditRename:
+		return "rename"
+	case AuditRenamePackage:
+		return "rename package"
+	case AuditDeconflict:
+		return "deconflict"
+	case AuditBacktrack:
+		return "backtrack"
+	case AuditPrune:
+		return "prune"
+	default:
+		return "none"
+	}
+}
+
+// AuditEntry records an action of the [Bundler].
+type AuditEntry struct {
+	Action AuditAction
+
+	// Location of the original schema or path of the package
+	Location string
+
+	// Source of the original schema, if it has been loaded from a known source (see [jsonschema.Schema.Source])
+	Source jsonschema.Source
+
+	From string
+	To   string
+
+	// Index of the bundled schema the entry applies to (see [Result.Schemas])
+	Index int
+}
+
+func (e AuditEntry) String() string {
+	location := e.Location
+	if !e.Source.IsZero() {
+		location += " (" + e.Source.String() + ")"
+	}
+
+	if e.To == "" {
+		return fmt.Sprintf("%s %s", e.Action, location)
+	}
+
+	return fmt.Sprintf("%s %s: %q -> %q", e.Action, location, e.From, e.To)
+}
+
+// Result of a bundle.
+type Result struct {
+	schemas     []jsonschema.Schema
+	definitions []Definition
+	audit       []AuditEntry
+}
+
+// Schema is the first bundled schema.
+func (r Result) Schema() jsonschema.Schema {
+	if len(r.schemas) == 0 {
+		return jsonschema.Schema{}
+	}
+
+	return r.schemas[0]
+}
+
+// Len is the number of bundled schemas.
+func (r Result) Len() int {
+	return len(r.schemas)
+}
+
+// Schemas yields the bundled schemas: one per bundled schema, or a single one when bundling with [WithSingleRoot].
+func (r Result) Schemas() iter.Seq[jsonschema.Schema] {
+	return slices.Values(r.schemas)
+}
+
+// Definitions yields the schemas relocated in the bundle.
+func (r Result) Definitions() iter.Seq[Definition] {
+	return slices.Values(r.definitions)
+}
+
+// Audit yields the audit trail of the bundle.
+func (r Result) Audit() iter.Seq[AuditEntry] {
+	return slices.Values(r.audit)
+}
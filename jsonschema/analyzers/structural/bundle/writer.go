@@ -0,0 +1,286 @@
+package bundle
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// children yields the subschemas of a schema, with the keyword that holds them.
+func (b *bundler) children(loc location) iter.Seq2[location, string] {
+	return func(yield func(location, string) bool) {
+		for sub := range jsonschema.Subschemas(loc.doc, b.version) {
+			if !yield(loc.at(sub.Schema, sub.Tokens()...), sub.Keyword) {
+				return
+			}
+		}
+	}
+}
+
+// write the bundled document.
+func (b *bundler) write() []byte {
+	var buf bytes.Buffer
+
+	if b.synthetic {
+		buf.WriteByte('{')
+		if url := b.version.MetaSchemaURL(); url != "" {
+			writeString(&buf, "$schema")
+			buf.WriteByte(':')
+			writeString(&buf, url)
+			buf.WriteByte(',')
+		}
+		writeString(&buf, b.defsKeyword)
+		buf.WriteByte(':')
+		b.writePackage(&buf, b.root)
+		buf.WriteByte('}')
+
+		return buf.Bytes()
+	}
+
+	b.writeSchema(&buf, b.main, true)
+
+	return buf.Bytes()
+}
+
+// writeSchema writes a schema, replacing relocated subschemas by a "$ref".
+func (b *bundler) writeSchema(buf *bytes.Buffer, loc location, top bool) {
+	if def, relocated := b.defs[loc.id()]; relocated && !top {
+		buf.WriteByte('{')
+		writeString(buf, "$ref")
+		buf.WriteByte(':')
+		writeString(buf, def.Ref())
+		buf.WriteByte('}')
+
+		return
+	}
+
+	if !loc.doc.IsObject() {
+		writeRaw(buf, loc)
+
+		return
+	}
+
+	isRoot := b.isLocal(loc) && loc.pointer == ""
+	hasDefs := false
+	first := true
+	member := func(key string) {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		writeString(buf, key)
+		buf.WriteByte(':')
+	}
+
+	buf.WriteByte('{')
+	for keyword, value := range loc.doc.Pairs() {
+		child := loc.at(value, keyword)
+		kind := jsonschema.SubschemaKindOf(keyword, value, b.version)
+
+		switch {
+		case keyword == "$ref" && value.IsString():
+			member(keyword)
+			if target, ok := b.refs[loc.id()]; ok {
+				writeString(buf, b.refOf(target))
+			} else {
+				writeRaw(buf, child)
+			}
+		case (keyword == "$id" || keyword == "$schema") && !isRoot:
+			// the bundle is self-contained: identifiers of subschemas are irrelevant
+			continue
+		case keyword == "id" && value.IsString() && !isRoot && b.legacyID():
+			continue
+		case isDefinitions(keyword) && value.IsObject():
+			withNew := isRoot && keyword == b.defsKeyword && b.root.hasRelocated()
+			if !withNew && !b.hasStaying(child) {
+				continue
+			}
+
+			hasDefs = hasDefs || withNew
+			member(keyword)
+			b.writeDefinitions(buf, child, withNew)
+		case kind == jsonschema.SubschemaMap:
+			member(keyword)
+			b.writeMap(buf, child)
+		case kind == jsonschema.SubschemaArray:
+			member(keyword)
+			buf.WriteByte('[')
+			for i, elem := range value.IndexedElems() {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				b.writeSchema(buf, child.at(elem, strconv.Itoa(i)), false)
+			}
+			buf.WriteByte(']')
+		case kind == jsonschema.SubschemaSingle:
+			member(keyword)
+			b.writeSchema(buf, child, false)
+		default:
+			member(keyword)
+			writeRaw(buf, child)
+		}
+	}
+
+	if isRoot && !hasDefs && b.root.hasRelocated() {
+		member(b.defsKeyword)
+		b.writePackage(buf, b.root)
+	}
+
+	buf.WriteByte('}')
+}
+
+func (b *bundler) writeMap(buf *bytes.Buffer, loc location) {
+	buf.WriteByte('{')
+	first := true
+	for key, value := range loc.doc.Pairs() {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		writeString(buf, key)
+		buf.WriteByte(':')
+
+		if !jsonschema.IsSchemaValue(value) {
+			// legacy "dependencies" may hold arrays of property names
+			writeRaw(buf, loc.at(value, key))
+
+			continue
+		}
+
+		b.writeSchema(buf, loc.at(value, key), false)
+	}
+	buf.WriteByte('}')
+}
+
+// writeDefinitions writes the definitions that remain in place, followed by relocated schemas if required.
+func (b *bundler) writeDefinitions(buf *bytes.Buffer, loc location, withNew bool) {
+	buf.WriteByte('{')
+	first := true
+	for key, value := range loc.doc.Pairs() {
+		child := loc.at(value, key)
+		if !b.stays(child) {
+			continue
+		}
+
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		writeString(buf, key)
+		buf.WriteByte(':')
+		b.writeSchema(buf, child, false)
+	}
+
+	if withNew {
+		for _, e := range b.root.relocated() {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			b.writeEntry(buf, e)
+		}
+	}
+	buf.WriteByte('}')
+}
+
+// writePackage writes the relocated schemas of a package.
+func (b *bundler) writePackage(buf *bytes.Buffer, p *pkg) {
+	buf.WriteByte('{')
+	for i, e := range p.relocated() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		b.writeEntry(buf, e)
+	}
+	buf.WriteByte('}')
+}
+
+func (b *bundler) writeEntry(buf *bytes.Buffer, e *Entry) {
+	writeString(buf, e.Name)
+	buf.WriteByte(':')
+
+	if e.IsPackage {
+		buf.WriteByte('{')
+		writeString(buf, b.defsKeyword)
+		buf.WriteByte(':')
+		b.writePackage(buf, e.pkg)
+		buf.WriteByte('}')
+
+		return
+	}
+
+	b.writeSchema(buf, b.sources[e.Definition.Location], true)
+}
+
+// hasStaying tells if some definitions remain in place in a "$defs" container.
+func (b *bundler) hasStaying(loc location) bool {
+	for key, value := range loc.doc.Pairs() {
+		if b.stays(loc.at(value, key)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// refOf yields the "$ref" to the target of a reference in the bundle.
+func (b *bundler) refOf(target location) string {
+	if def, relocated := b.defs[target.id()]; relocated {
+		return def.Ref()
+	}
+
+	return keywords.FragmentRef(target.pointer)
+}
+
+// legacyID tells if schemas are identified by "id" rather than "$id".
+func (b *bundler) legacyID() bool {
+	return b.version != jsonschema.VersionUndefined && b.version.Less(jsonschema.VersionDraft6)
+}
+
+// relocated yields the entries of a package that are emitted in the bundle, in lexicographic order.
+func (p *pkg) relocated() []*Entry {
+	entries := make([]*Entry, 0, len(p.ns.entries))
+	for _, e := range p.ns.entries {
+		if e.IsPackage || e.Definition != nil {
+			entries = append(entries, e)
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b *Entry) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return entries
+}
+
+func (p *pkg) hasRelocated() bool {
+	for _, e := range p.ns.entries {
+		if e.IsPackage || e.Definition != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeRaw(buf *bytes.Buffer, loc location) {
+	data, err := loc.doc.MarshalJSON()
+	if err != nil {
+		buf.WriteString("null")
+
+		return
+	}
+
+	buf.Write(data)
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	quoted, _ := stdjson.Marshal(s) // never fails for a string
+	buf.Write(quoted)
+}
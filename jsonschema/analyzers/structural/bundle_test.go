@@ -0,0 +1,427 @@
+package structural
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/analyzers"
+	"github.com/fredbi/core/jsonschema/analyzers/structural/bundle"
+)
+
+func TestBundle(t *testing.T) {
+	const (
+		base   = "https://example.com/api/main.json"
+		remote = "https://example.com/models/pet.json"
+	)
+
+	const main = `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"pet": {"$ref": "../models/pet.json#/$defs/pet"},
+			"owner": {"$ref": "#/$defs/owner"}
+		},
+		"$defs": {
+			"owner": {"type": "string"},
+			"unused": {"type": "integer"}
+		}
+	}`
+
+	const pets = `{
+		"$id": "https://example.com/models/pet.json",
+		"$defs": {
+			"pet": {
+				"type": "object",
+				"properties": {
+					"kind": {"$ref": "#/$defs/kind"}
+				}
+			},
+			"kind": {"enum": ["cat", "dog"], "type": "string"}
+		}
+	}`
+
+	analyze := func(t *testing.T, opts ...Option) *SchemaAnalyzer {
+		t.Helper()
+
+		resolver := jsonschema.NewResolver()
+		require.NoError(t, resolver.AddDocument(remote, mustSchema(t, pets)))
+
+		a := NewAnalyzer(append(opts, WithResolver(resolver), WithBaseURI(base))...)
+		require.NoError(t, a.Analyze(mustSchema(t, main)))
+
+		return a
+	}
+
+	t.Run("should bundle remote schemas in a flat namespace", func(t *testing.T) {
+		bundled := mustBundle(t, analyze(t))
+
+		assertSchemas(t, bundled, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type": "object",
+			"properties": {
+				"pet": {"$ref": "#/$defs/pet"},
+				"owner": {"$ref": "#/$defs/owner"}
+			},
+			"$defs": {
+				"owner": {"type": "string"},
+				"unused": {"type": "integer"},
+				"kind": {"enum": ["cat", "dog"], "type": "string"},
+				"pet": {
+					"type": "object",
+					"properties": {
+						"kind": {"$ref": "#/$defs/kind"}
+					}
+				}
+			}
+		}`)
+
+		kind := mustSchemaByID(t, bundled, base+"#/$defs/kind")
+		assert.True(t, kind.IsEnum())
+		assert.Contains(t, kind.auditEntries, AuditTrailEntry{
+			Action:      AuditActionRefactorSchema,
+			Originator:  "bundle",
+			Description: `relocate ` + remote + `#/$defs/kind: "` + remote + `#/$defs/kind" -> "#/$defs/kind"`,
+		})
+	})
+
+	t.Run("should bundle remote schemas in packages", func(t *testing.T) {
+		bundled := mustBundle(t, analyze(t,
+			WithBundleStragegy(bundle.Hierarchical),
+			WithBundleEnumsPackage("enums"),
+			WithBundlePruneUnused(true),
+		))
+
+		assertSchemas(t, bundled, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type": "object",
+			"properties": {
+				"pet": {"$ref": "#/$defs/models/$defs/pet/$defs/pet"},
+				"owner": {"$ref": "#/$defs/owner"}
+			},
+			"$defs": {
+				"owner": {"type": "string"},
+				"models": {
+					"$defs": {
+						"pet": {
+							"$defs": {
+								"enums": {
+									"$defs": {
+										"kind": {"enum": ["cat", "dog"], "type": "string"}
+									}
+								},
+								"pet": {
+									"type": "object",
+									"properties": {
+										"kind": {"$ref": "#/$defs/models/$defs/pet/$defs/enums/$defs/kind"}
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}`)
+
+		pet := mustSchemaByID(t, bundled, base+"#/$defs/models/$defs/pet/$defs/pet")
+		assert.Equal(t, "models/pet", pet.Path())
+		kind := mustSchemaByID(t, bundled, base+"#/$defs/models/$defs/pet/$defs/enums/$defs/kind")
+		assert.Equal(t, "models/pet/enums", kind.Path())
+
+		root := mustSchemaByID(t, bundled, base+"#")
+		assert.Contains(t, root.auditEntries, AuditTrailEntry{
+			Action:      AuditActionRefactorSchema,
+			Originator:  "bundle",
+			Description: "prune " + base + "#/$defs/unused",
+		})
+	})
+
+	t.Run("should bundle documents in nested folders in packages relative to the root", func(t *testing.T) {
+		const root = "file:///tmp/specs/main.json"
+
+		resolver := jsonschema.NewResolver()
+		require.NoError(t, resolver.AddDocument("file:///tmp/specs/a/b.json", mustSchema(t, `{
+			"$defs": {
+				"b": {"properties": {"pet": {"$ref": "sub/pet.json#/$defs/pet"}}}
+			}
+		}`)))
+		require.NoError(t, resolver.AddDocument("file:///tmp/specs/a/sub/pet.json", mustSchema(t, `{
+			"$defs": {
+				"pet": {"type": "object"}
+			}
+		}`)))
+
+		a := NewAnalyzer(WithResolver(resolver), WithBaseURI(root), WithBundleStragegy(bundle.Hierarchical))
+		require.NoError(t, a.Analyze(mustSchema(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"properties": {"b": {"$ref": "a/b.json#/$defs/b"}}
+		}`)))
+
+		bundled := mustBundle(t, a)
+
+		assertSchemas(t, bundled, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"properties": {"b": {"$ref": "#/$defs/a/$defs/b/$defs/b"}},
+			"$defs": {
+				"a": {
+					"$defs": {
+						"b": {
+							"$defs": {
+								"b": {"properties": {"pet": {"$ref": "#/$defs/a/$defs/sub/$defs/pet/$defs/pet"}}}
+							}
+						},
+						"sub": {
+							"$defs": {
+								"pet": {
+									"$defs": {
+										"pet": {"type": "object"}
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}`)
+
+		pet := mustSchemaByID(t, bundled, root+"#/$defs/a/$defs/sub/$defs/pet/$defs/pet")
+		assert.Equal(t, "a/sub/pet", pet.Path())
+	})
+
+	t.Run("should relocate local schemas when eager", func(t *testing.T) {
+		a := NewAnalyzer(WithBaseURI(base), WithBundleAggressiveness(bundle.Eager), WithBundlePruneUnused(true))
+		require.NoError(t, a.Analyze(mustSchema(t, `{
+			"type": "object",
+			"properties": {
+				"a": {"type": "string"},
+				"b": {"$ref": "#/properties/a"}
+			}
+		}`)))
+
+		assertSchemas(t, mustBundle(t, a), `{
+			"type": "object",
+			"properties": {
+				"a": {"$ref": "#/$defs/a"},
+				"b": {"$ref": "#/$defs/a"}
+			},
+			"$defs": {
+				"a": {"type": "string"}
+			}
+		}`)
+	})
+
+	t.Run("should use definitions prior to draft 2019", func(t *testing.T) {
+		resolver := jsonschema.NewResolver()
+		require.NoError(t, resolver.AddDocument(remote, mustSchema(t, `{"definitions": {"pet": {"type": "object"}}}`)))
+
+		a := NewAnalyzer(WithResolver(resolver), WithBaseURI(base))
+		require.NoError(t, a.Analyze(mustSchema(t, `{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"$ref": "../models/pet.json#/definitions/pet"
+		}`)))
+
+		assertSchemas(t, mustBundle(t, a), `{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"$ref": "#/definitions/pet",
+			"definitions": {
+				"pet": {"type": "object"}
+			}
+		}`)
+	})
+
+	t.Run("should bundle several schemas under a single root", func(t *testing.T) {
+		a := NewAnalyzer(WithBaseURI("https://example.com/schema-0.json"), WithBundleSingleRoot(true))
+		require.NoError(t, a.Analyze(mustSchema(t, `{"type": "string"}`)))
+		require.NoError(t, a.Analyze(mustSchema(t, `{"type": "array", "items": {"$ref": "schema-0.json"}}`)))
+
+		assertSchemas(t, mustBundle(t, a), `{
+			"$defs": {
+				"schema-0": {"type": "string"},
+				"schema-1": {"type": "array", "items": {"$ref": "#/$defs/schema-0"}}
+			}
+		}`)
+	})
+
+	t.Run("should bundle several schemas separately", func(t *testing.T) {
+		a := NewAnalyzer()
+		require.NoError(t, a.Analyze(mustSchema(t, `{"type": "string"}`)))
+		require.NoError(t, a.Analyze(mustSchema(t, `{"type": "integer"}`)))
+
+		assertSchemas(t, mustBundle(t, a), `{"type": "string"}`, `{"type": "integer"}`)
+	})
+
+	t.Run("should report the sources of loaded schemas", func(t *testing.T) {
+		api := mustSchema(t, `{"properties": {"pet": {"$ref": "schema-1.json#/$defs/pet"}}}`)
+		api.SetSource(jsonschema.Source{File: "schemas.yaml"})
+		models := mustSchema(t, `{"$defs": {"pet": {"type": "object"}}}`)
+		models.SetSource(jsonschema.Source{File: "schemas.yaml", Document: 1})
+
+		resolver := jsonschema.NewResolver()
+		require.NoError(t, resolver.AddDocument("https://example.com/api/schema-1.json", models))
+		a := NewAnalyzer(WithResolver(resolver), WithBaseURI(base))
+		require.NoError(t, a.Analyze(api))
+		require.NoError(t, a.Analyze(models))
+
+		bundled := mustBundle(t, a)
+		pet := mustSchemaByID(t, bundled, base+"#/$defs/pet")
+		require.NotEmpty(t, pet.auditEntries)
+		assert.Contains(t, pet.auditEntries[0].Description, "(schemas.yaml[1]#/$defs/pet)")
+
+		broken := mustSchema(t, `{"items": {"$ref": "missing.json"}}`)
+		broken.SetSource(jsonschema.Source{File: "broken.json"})
+		err := NewAnalyzer(WithBaseURI(base)).Analyze(broken)
+		require.ErrorIs(t, err, ErrAnalyze)
+		assert.Contains(t, err.Error(), "(broken.json#/items)")
+	})
+}
+
+func TestBundleNaming(t *testing.T) {
+	const main = `{
+		"allOf": [
+			{"$ref": "https://example.com/a/pet.json"},
+			{"$ref": "https://example.com/b/Pet.json"}
+		]
+	}`
+
+	analyze := func(t *testing.T, opts ...Option) *SchemaAnalyzer {
+		t.Helper()
+
+		resolver := jsonschema.NewResolver()
+		require.NoError(t, resolver.AddDocument("https://example.com/a/pet.json", mustSchema(t, `{"type": "object"}`)))
+		require.NoError(t, resolver.AddDocument("https://example.com/b/Pet.json", mustSchema(t, `{"type": "string"}`)))
+
+		a := NewAnalyzer(append(opts, WithResolver(resolver), WithBaseURI(testBase))...)
+		require.NoError(t, a.Analyze(mustSchema(t, main)))
+
+		return a
+	}
+
+	lower := func(name string) Ident { return Ident(strings.ToLower(name)) }
+
+	t.Run("should deconflict names", func(t *testing.T) {
+		bundled := mustBundle(t, analyze(t, WithBundleNameIdentifier(lower)))
+
+		assertSchemas(t, bundled, `{
+			"allOf": [
+				{"$ref": "#/$defs/pet"},
+				{"$ref": "#/$defs/Pet2"}
+			],
+			"$defs": {
+				"Pet2": {"type": "string"},
+				"pet": {"type": "object"}
+			}
+		}`)
+
+		deconflicted := mustSchemaByID(t, bundled, testBase+"#/$defs/Pet2")
+		assert.Contains(t, deconflicted.auditEntries, AuditTrailEntry{
+			Action:      AuditActionDeconflictName,
+			Originator:  "bundle",
+			Description: `deconflict https://example.com/b/Pet.json#: "Pet" -> "Pet2"`,
+		})
+	})
+
+	t.Run("should name with a provider, given the analyzed schema", func(t *testing.T) {
+		bundled := mustBundle(t, analyze(t, WithBundleNameProvider(func(name string, analyzed AnalyzedSchema) (string, error) {
+			return fmt.Sprintf("%s_%s", name, analyzed.Metadata().ID[len("https://example.com/"):][:1]), nil
+		})))
+
+		names := slices.Sorted(schemaNames(bundled.AnalyzedSchemas(OnlyNamedSchemas())))
+		assert.Equal(t, []string{"Pet_b", "main", "pet_a"}, names)
+
+		var renames int
+		for analyzed := range bundled.AnalyzedSchemas() {
+			for _, entry := range analyzed.auditEntries {
+				if entry.Action == AuditActionRenameSchema {
+					renames++
+				}
+			}
+		}
+		assert.Equal(t, 2, renames)
+	})
+
+	t.Run("should backtrack on previous names", func(t *testing.T) {
+		bundled := mustBundle(t, analyze(t,
+			WithBundleNameIdentifier(lower),
+			WithBacktrackOnConflicts(true),
+			WithBundleNameDeconflicter(func(name string, ns Namespace) (string, error) {
+				owner, ok := ns.Meta(lower(name))
+				if !ok {
+					return name, nil
+				}
+
+				backtrackable, ok := ns.(BacktrackableNamespace)
+				if !ok {
+					return "", ErrBundle
+				}
+
+				// qualify both names with the last segment of their package
+				owner.Name = "a" + capitalize(owner.Name)
+				if !backtrackable.Backtrack(owner) {
+					return "", ErrBundle
+				}
+
+				return "b" + capitalize(name), nil
+			}),
+		))
+
+		assertSchemas(t, bundled, `{
+			"allOf": [
+				{"$ref": "#/$defs/aPet"},
+				{"$ref": "#/$defs/bPet"}
+			],
+			"$defs": {
+				"aPet": {"type": "object"},
+				"bPet": {"type": "string"}
+			}
+		}`)
+	})
+
+	t.Run("should name packages", func(t *testing.T) {
+		bundled := mustBundle(t, analyze(t,
+			WithBundleStragegy(bundle.Hierarchical),
+			WithBundlePathProvider(func(name string, _ AnalyzedPackage) (string, error) {
+				return "pkg_" + name, nil
+			}),
+		))
+
+		paths := slices.Collect(bundled.PackagePaths())
+		assert.Equal(t, []string{"", "pkg_a", "pkg_b"}, paths)
+	})
+}
+
+func mustBundle(t *testing.T, a *SchemaAnalyzer) *SchemaAnalyzer {
+	t.Helper()
+
+	bundled, err := a.Bundle()
+	require.NoError(t, err)
+	require.IsType(t, &SchemaAnalyzer{}, bundled)
+
+	return bundled.(*SchemaAnalyzer)
+}
+
+func mustSchemaByID(t *testing.T, a *SchemaAnalyzer, id string) AnalyzedSchema {
+	t.Helper()
+
+	analyzed, found := a.SchemaByID(analyzers.UniqueID(id))
+	require.Truef(t, found, "expected a schema at %s", id)
+
+	return analyzed
+}
+
+func assertSchemas(t *testing.T, a *SchemaAnalyzer, expected ...string) {
+	t.Helper()
+
+	schemas := slices.Collect(a.Schemas())
+	require.Len(t, schemas, len(expected))
+
+	for i, sch := range schemas {
+		data, err := sch.MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, expected[i], string(data))
+	}
+}
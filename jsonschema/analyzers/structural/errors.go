@@ -7,5 +7,8 @@ func (e Error) Error() string {
 }
 
 const (
-	ErrSchemaBuilder = "error in building an analyzed schema"
+	ErrSchemaBuilder Error = "error in building an analyzed schema"
+	ErrAnalyze       Error = "error in analyzing schemas"
+	ErrBundle        Error = "error in bundling schemas"
+	ErrExport        Error = "error in exporting the schema graph"
 )
@@ -0,0 +1,393 @@
+package structural
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/jsonschema/analyzers"
+	"github.com/fredbi/core/jsonschema/analyzers/internal/graph/v2"
+	"github.com/fredbi/core/jsonschema/analyzers/structural/export"
+	"github.com/fredbi/core/jsonschema/analyzers/structural/order"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// exportedGraph is the dependency graph of the schemas to export, with edges as exported.
+type exportedGraph = graph.DiGraph[analyzers.UniqueID, *AnalyzedSchema, export.Edge]
+
+// ExportGraph writes the dependency graph of the analyzed schemas, in DOT, mermaid or JSON format.
+//
+// [Filter] s restrict the exported schemas and their ordering: [OnlyNamedSchemas] collapses dependencies through
+// anonymous schemas. Nodes are identified by the location of the schema, which is also the ID of the [AnalyzedSchema].
+func (a *SchemaAnalyzer) ExportGraph(w io.Writer, format export.Format, filterSpecs ...Filter) error {
+	if err := a.exportGraph(applyFiltersWithDefault(filterSpecs)).Write(w, format); err != nil {
+		return fmt.Errorf("%w: %w", err, ErrExport)
+	}
+
+	return nil
+}
+
+// exportGraph builds the [export.Graph] of the schemas kept by the filters.
+//
+// Cycles are found without the edges from base types to their subtypes, which are not dependencies.
+func (a *SchemaAnalyzer) exportGraph(f filters) export.Graph {
+	dependencies := a.exportedDependencies(f.WantsNamed)
+	cycles := dependencies.Cycles()
+
+	cycleOf := make(map[analyzers.UniqueID]int)
+	for i, cycle := range cycles {
+		for _, id := range cycle {
+			cycleOf[id] = i
+		}
+	}
+
+	inCycle := func(from, to analyzers.UniqueID) bool {
+		i, ok := cycleOf[from]
+		j, sameCycle := cycleOf[to]
+
+		return ok && sameCycle && i == j
+	}
+
+	var nodes []export.Node
+	kept := make(map[analyzers.UniqueID]struct{})
+	for node := range exportedNodes(dependencies, f) {
+		analyzed := node.Value()
+		if !f.keeps(*analyzed) || !a.keepsPackage(f, analyzed.path) {
+			continue
+		}
+
+		kept[analyzed.id] = struct{}{}
+		nodes = append(nodes, a.exportedNode(analyzed, inCycle(analyzed.id, analyzed.id)))
+	}
+
+	isKept := func(id analyzers.UniqueID) bool {
+		_, ok := kept[id]
+
+		return ok
+	}
+
+	var edges []export.Edge
+	for e := range dependencies.Edges() {
+		if !isKept(e.From) || !isKept(e.To) {
+			continue
+		}
+
+		edge := e.Value()
+		edge.InCycle = inCycle(e.From, e.To)
+		edges = append(edges, edge)
+	}
+
+	for node := range dependencies.Nodes() {
+		base := node.Value()
+		if !isKept(base.id) {
+			continue
+		}
+
+		for _, subType := range base.subTypes {
+			if !isKept(subType.id) {
+				continue
+			}
+
+			edges = append(edges, export.Edge{
+				From:     string(base.id),
+				To:       string(subType.id),
+				Relation: export.RelationSubtype,
+				Label:    a.discriminatorValue(base, subType),
+			})
+		}
+	}
+
+	exportedCycles := make([][]string, 0, len(cycles))
+	for _, cycle := range cycles {
+		var members []string
+		for _, id := range cycle {
+			if isKept(id) {
+				members = append(members, string(id))
+			}
+		}
+
+		if len(members) > 0 {
+			exportedCycles = append(exportedCycles, members)
+		}
+	}
+
+	return export.NewGraph(nodes, edges, exportedPackages(nodes, edges), exportedCycles)
+}
+
+// exportedDependencies builds the graph of dependencies to export.
+//
+// With onlyNamed, dependencies through anonymous schemas are collapsed into dependencies between named schemas.
+func (a *SchemaAnalyzer) exportedDependencies(onlyNamed bool) *exportedGraph {
+	dependencies := graph.NewDiGraph[analyzers.UniqueID, *AnalyzedSchema, export.Edge]()
+	for node := range a.schemas.DiGraph.Nodes() {
+		if analyzed := node.Value(); !onlyNamed || analyzed.IsNamed() {
+			_, _ = dependencies.AddNode(analyzed.id, analyzed)
+		}
+	}
+
+	seen := make(map[export.Edge]struct{})
+	link := func(edge export.Edge) {
+		if _, found := seen[edge]; found {
+			return
+		}
+
+		seen[edge] = struct{}{}
+		_, _ = dependencies.AddEdge(analyzers.UniqueID(edge.From), analyzers.UniqueID(edge.To), edge)
+	}
+
+	if !onlyNamed {
+		for e := range a.schemas.Edges() {
+			c := e.Value()
+			link(export.Edge{
+				From:     string(e.From),
+				To:       string(e.To),
+				Relation: exportedRelation(c.linkType),
+				Label:    exportedLabel(c),
+			})
+		}
+
+		return dependencies
+	}
+
+	for node := range dependencies.Nodes() {
+		a.collapse(node.Value(), link)
+	}
+
+	return dependencies
+}
+
+// collapse the dependencies of a named schema through anonymous schemas.
+//
+// The relation of a collapsed dependency is the relation with the first anonymous schema, and its label is
+// the path through anonymous schemas, e.g. "pets/items".
+func (a *SchemaAnalyzer) collapse(from *AnalyzedSchema, link func(export.Edge)) {
+	visited := make(map[analyzers.UniqueID]struct{})
+
+	var walk func(id analyzers.UniqueID, relation export.Relation, segments []string)
+	walk = func(id analyzers.UniqueID, relation export.Relation, segments []string) {
+		for c := range a.schemas.Dependencies(id) {
+			r := relation
+			if r == export.RelationNone {
+				r = exportedRelation(c.linkType)
+			}
+
+			path := segments
+			if segment := segmentOf(c); segment != "" {
+				path = append(slices.Clip(segments), segment)
+			}
+
+			if c.to.IsNamed() {
+				link(export.Edge{From: string(from.id), To: string(c.to.id), Relation: r, Label: strings.Join(path, "/")})
+
+				continue
+			}
+
+			if _, found := visited[c.to.id]; found {
+				continue
+			}
+			visited[c.to.id] = struct{}{}
+
+			walk(c.to.id, r, path)
+		}
+	}
+
+	walk(from.id, export.RelationNone, nil)
+}
+
+func exportedNodes(dependencies *exportedGraph, f filters) iter.Seq[*graph.Node[analyzers.UniqueID, *AnalyzedSchema]] {
+	if f.WantsOnlyLeaves {
+		return dependencies.Leaves()
+	}
+
+	switch f.Ordering {
+	case order.BottomUp:
+		return dependencies.Inverted().TraverseTopological()
+	case order.TopDown:
+		return dependencies.TraverseDFS()
+	default:
+		return dependencies.Nodes()
+	}
+}
+
+func (a *SchemaAnalyzer) keepsPackage(f filters, pth string) bool {
+	if f.PkgFilterFunc == nil {
+		return true
+	}
+
+	pkg, found := a.packages.PackageByID(packageID(pth))
+
+	return found && f.PkgFilterFunc(pkg)
+}
+
+func (a *SchemaAnalyzer) exportedNode(analyzed *AnalyzedSchema, inCycle bool) export.Node {
+	kind := export.KindAnonymous
+	switch {
+	case slices.Contains(a.roots, analyzed.id):
+		kind = export.KindRoot
+	case analyzed.IsNamed():
+		kind = export.KindNamed
+	}
+
+	return export.Node{
+		ID:      string(analyzed.id),
+		Name:    analyzed.name,
+		Package: analyzed.path,
+		Kind:    kind,
+		Type:    strings.Join(typesOf(analyzed.document.Document), ","),
+		IsEnum:  analyzed.IsEnum(),
+		HasRef:  slices.ContainsFunc(slices.Collect(a.schemas.Dependencies(analyzed.id)), isRef),
+		InCycle: inCycle,
+	}
+}
+
+// discriminatorValue of a subtype: either "x-discriminator-value", a key in the mapping of the discriminator of
+// the base type or the name of the subtype.
+func (a *SchemaAnalyzer) discriminatorValue(base, subType *AnalyzedSchema) string {
+	if value, ok := keywords.StringAt(subType.document.Document, "x-discriminator-value"); ok {
+		return value
+	}
+
+	discriminator, _ := base.document.AtKey("discriminator")
+	mapping, ok := discriminator.AtKey("mapping")
+	if !ok || !mapping.IsObject() {
+		return subType.name
+	}
+
+	uri, pointer, _ := strings.Cut(string(base.id), "#")
+	resolved, err := a.resolver.Resolve(keywords.FragmentRef(pointer), uri)
+
+	for key, value := range mapping.Pairs() {
+		v, _ := value.Value()
+		ref := v.String()
+		if ref == subType.name {
+			return key
+		}
+
+		if err != nil || !strings.ContainsAny(ref, "#/") {
+			continue
+		}
+
+		if target, err := a.resolver.Resolve(ref, resolved.BaseURI()); err == nil && target.String() == string(subType.id) {
+			return key
+		}
+	}
+
+	return subType.name
+}
+
+// exportedPackages are the packages of the exported schemas, sorted by path, with the packages of their dependencies.
+func exportedPackages(nodes []export.Node, edges []export.Edge) []export.Package {
+	packageOf := make(map[string]string, len(nodes))
+	dependencies := make(map[string][]string)
+	for _, n := range nodes {
+		packageOf[n.ID] = n.Package
+		if _, found := dependencies[n.Package]; !found {
+			dependencies[n.Package] = nil
+		}
+	}
+
+	for _, e := range edges {
+		from, to := packageOf[e.From], packageOf[e.To]
+		if e.Relation == export.RelationSubtype || from == to || slices.Contains(dependencies[from], to) {
+			continue
+		}
+
+		dependencies[from] = append(dependencies[from], to)
+	}
+
+	packages := make([]export.Package, 0, len(dependencies))
+	for pth, dependsOn := range dependencies {
+		slices.Sort(dependsOn)
+		packages = append(packages, export.Package{Path: pth, Dependencies: dependsOn})
+	}
+
+	slices.SortFunc(packages, func(x, y export.Package) int {
+		return strings.Compare(x.Path, y.Path)
+	})
+
+	return packages
+}
+
+func exportedRelation(r SchemaRelation) export.Relation {
+	switch r {
+	case SchemaRelationProperty:
+		return export.RelationProperty
+	case SchemaRelationAdditionalProperty:
+		return export.RelationAdditionalProperties
+	case SchemaRelationPatternProperty:
+		return export.RelationPatternProperty
+	case SchemaRelationItems, SchemaRelationTupleAdditionalProperty:
+		return export.RelationItems
+	case SchemaRelationTupleItems:
+		return export.RelationPrefixItems
+	case SchemaRelationAllOf:
+		return export.RelationAllOf
+	case SchemaRelationOneOf:
+		return export.RelationOneOf
+	case SchemaRelationAnyOf:
+		return export.RelationAnyOf
+	case SchemaRelationRef:
+		return export.RelationRef
+	case SchemaRelationNot:
+		return export.RelationNot
+	case SchemaRelationPropertyNames:
+		return export.RelationPropertyNames
+	case SchemaRelationDependentSchema:
+		return export.RelationDependentSchema
+	case SchemaRelationContains:
+		return export.RelationContains
+	case SchemaRelationConditional:
+		return export.RelationConditional
+	case SchemaRelationOther:
+		return export.RelationOther
+	default:
+		return export.RelationNone
+	}
+}
+
+// exportedLabel characterizes a dependency, with a key (e.g. a property name) or an index (e.g. in an "allOf").
+func exportedLabel(c AnalyzedSchemaContext) string {
+	switch {
+	case c.linkType == SchemaRelationRef:
+		if c.key == "$dynamicRef" {
+			return c.key
+		}
+
+		return ""
+	case c.key != "":
+		return c.key
+	case c.index >= 0:
+		return strconv.Itoa(c.index)
+	default:
+		return ""
+	}
+}
+
+// segmentOf a dependency in the label of a collapsed dependency.
+func segmentOf(c AnalyzedSchemaContext) string {
+	label := exportedLabel(c)
+	relation := exportedRelation(c.linkType)
+
+	switch relation {
+	case export.RelationRef:
+		return ""
+	case export.RelationItems, export.RelationAdditionalProperties, export.RelationPropertyNames,
+		export.RelationContains, export.RelationNot:
+		if label != "" {
+			return label
+		}
+
+		return relation.String()
+	case export.RelationAllOf, export.RelationAnyOf, export.RelationOneOf, export.RelationPrefixItems:
+		return relation.String() + "/" + label
+	default:
+		return label
+	}
+}
+
+func isRef(c AnalyzedSchemaContext) bool {
+	return c.linkType == SchemaRelationRef
+}
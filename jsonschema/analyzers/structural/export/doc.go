@@ -0,0 +1,14 @@
+// Package export renders the dependency graph of JSON schemas, for humans to review.
+//
+// A [Graph] of schemas is built by the structural analyzer (see SchemaAnalyzer.ExportGraph):
+//
+//   - nodes are schemas, either roots, named schemas (definitions, schemas with an "$id" or targets of a "$ref"),
+//     or anonymous subschemas
+//   - edges tell how a schema depends on another one, e.g. as a property, as items, as a member of an "allOf",
+//     through a "$ref" or as a subtype of a base type with a discriminator
+//   - schemas are grouped in packages, after the document that holds them and their nesting in definitions
+//
+// Cycles in the graph are highlighted.
+//
+// A [Graph] may be written as a Graphviz DOT graph, a Mermaid flowchart or a JSON adjacency list (see [Format]).
+package export
@@ -0,0 +1,13 @@
+package export
+
+// Error is an error raised when exporting a [Graph].
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrExport is the generic error raised by this package.
+	ErrExport Error = "export error"
+)
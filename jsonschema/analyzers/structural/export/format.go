@@ -0,0 +1,265 @@
+package export
+
+import (
+	"bufio"
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Format of the exported [Graph].
+type Format uint8
+
+const (
+	// FormatDOT renders the graph in the graphviz DOT language
+	FormatDOT Format = iota
+	// FormatMermaid renders the graph as a mermaid flowchart
+	FormatMermaid
+	// FormatJSON renders the graph as a JSON adjacency list
+	FormatJSON
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatMermaid:
+		return "mermaid"
+	case FormatJSON:
+		return "json"
+	default:
+		return "dot"
+	}
+}
+
+// Write the [Graph] to w in the desired [Format].
+func (g Graph) Write(w io.Writer, f Format) error {
+	switch f {
+	case FormatDOT:
+		return g.WriteDOT(w)
+	case FormatMermaid:
+		return g.WriteMermaid(w)
+	case FormatJSON:
+		return g.WriteJSON(w)
+	default:
+		return fmt.Errorf("unsupported export format: %d: %w", f, ErrExport)
+	}
+}
+
+// WriteDOT renders the [Graph] in the graphviz DOT language.
+//
+// Packages are rendered as clusters. Schemas and dependencies in cycles are colored in red.
+func (g Graph) WriteDOT(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+
+	buf.WriteString("digraph schemas {\n")
+	buf.WriteString("  rankdir=LR;\n")
+	buf.WriteString("  node [shape=box];\n")
+
+	for i, p := range g.packages {
+		indent := "  "
+		if p.Path != "" {
+			fmt.Fprintf(buf, "  subgraph %s {\n", strconv.Quote("cluster_"+strconv.Itoa(i)))
+			fmt.Fprintf(buf, "    label=%s;\n", strconv.Quote(p.Path))
+			indent = "    "
+		}
+
+		for _, n := range g.nodes {
+			if n.Package != p.Path {
+				continue
+			}
+
+			fmt.Fprintf(buf, "%s%s [label=%s", indent, strconv.Quote(n.ID), strconv.Quote(n.Label()))
+			if n.Kind == KindRoot {
+				buf.WriteString(", style=bold")
+			}
+			if n.InCycle {
+				buf.WriteString(", color=red")
+			}
+			buf.WriteString("];\n")
+		}
+
+		if p.Path != "" {
+			buf.WriteString("  }\n")
+		}
+	}
+
+	for _, e := range g.edges {
+		fmt.Fprintf(buf, "  %s -> %s [label=%s", strconv.Quote(e.From), strconv.Quote(e.To), strconv.Quote(edgeLabel(e)))
+		if e.Relation == RelationSubtype {
+			buf.WriteString(", style=dashed")
+		}
+		if e.InCycle {
+			buf.WriteString(", color=red")
+		}
+		buf.WriteString("];\n")
+	}
+
+	buf.WriteString("}\n")
+
+	return flush(buf)
+}
+
+// WriteMermaid renders the [Graph] as a mermaid flowchart.
+//
+// Packages are rendered as subgraphs. Schemas and dependencies in cycles are stroked in red.
+func (g Graph) WriteMermaid(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+
+	buf.WriteString("flowchart LR\n")
+
+	for i, p := range g.packages {
+		indent := "  "
+		if p.Path != "" {
+			fmt.Fprintf(buf, "  subgraph p%d[\"%s\"]\n", i, mermaidEscape(p.Path))
+			indent = "    "
+		}
+
+		for j, n := range g.nodes {
+			if n.Package != p.Path {
+				continue
+			}
+
+			fmt.Fprintf(buf, "%sn%d[\"%s\"]\n", indent, j, mermaidEscape(n.Label()))
+		}
+
+		if p.Path != "" {
+			buf.WriteString("  end\n")
+		}
+	}
+
+	var cycleEdges []string
+	for i, e := range g.edges {
+		arrow := "-->"
+		if e.Relation == RelationSubtype {
+			arrow = "-.->"
+		}
+
+		fmt.Fprintf(buf, "  n%d %s|\"%s\"| n%d\n", g.index[e.From], arrow, mermaidEscape(edgeLabel(e)), g.index[e.To])
+		if e.InCycle {
+			cycleEdges = append(cycleEdges, strconv.Itoa(i))
+		}
+	}
+
+	if g.HasCycle() {
+		buf.WriteString("  classDef cycle stroke:red\n")
+		for i, n := range g.nodes {
+			if n.InCycle {
+				fmt.Fprintf(buf, "  class n%d cycle\n", i)
+			}
+		}
+	}
+
+	if len(cycleEdges) > 0 {
+		fmt.Fprintf(buf, "  linkStyle %s stroke:red\n", strings.Join(cycleEdges, ","))
+	}
+
+	return flush(buf)
+}
+
+// WriteJSON renders the [Graph] as a JSON adjacency list.
+func (g Graph) WriteJSON(w io.Writer) error {
+	enc := stdjson.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(g.adjacency()); err != nil {
+		return fmt.Errorf("%w: %w", err, ErrExport)
+	}
+
+	return nil
+}
+
+// MarshalJSON renders the [Graph] as a JSON adjacency list.
+func (g Graph) MarshalJSON() ([]byte, error) {
+	return stdjson.Marshal(g.adjacency())
+}
+
+type jsonGraph struct {
+	Nodes    []jsonNode    `json:"nodes"`
+	Packages []jsonPackage `json:"packages"`
+	Cycles   [][]string    `json:"cycles,omitempty"`
+}
+
+type jsonNode struct {
+	ID      string     `json:"id"`
+	Name    string     `json:"name,omitempty"`
+	Package string     `json:"package,omitempty"`
+	Kind    string     `json:"kind"`
+	Type    string     `json:"type,omitempty"`
+	IsEnum  bool       `json:"enum,omitempty"`
+	HasRef  bool       `json:"ref,omitempty"`
+	InCycle bool       `json:"cycle,omitempty"`
+	Edges   []jsonEdge `json:"edges,omitempty"`
+}
+
+type jsonEdge struct {
+	To       string `json:"to"`
+	Relation string `json:"relation"`
+	Label    string `json:"label,omitempty"`
+	InCycle  bool   `json:"cycle,omitempty"`
+}
+
+type jsonPackage struct {
+	Path         string   `json:"path"`
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+func (g Graph) adjacency() jsonGraph {
+	doc := jsonGraph{
+		Nodes:    make([]jsonNode, 0, len(g.nodes)),
+		Packages: make([]jsonPackage, 0, len(g.packages)),
+		Cycles:   g.cycles,
+	}
+
+	for _, n := range g.nodes {
+		doc.Nodes = append(doc.Nodes, jsonNode{
+			ID:      n.ID,
+			Name:    n.Name,
+			Package: n.Package,
+			Kind:    n.Kind.String(),
+			Type:    n.Type,
+			IsEnum:  n.IsEnum,
+			HasRef:  n.HasRef,
+			InCycle: n.InCycle,
+		})
+	}
+
+	for _, e := range g.edges {
+		node := &doc.Nodes[g.index[e.From]]
+		node.Edges = append(node.Edges, jsonEdge{
+			To:       e.To,
+			Relation: e.Relation.String(),
+			Label:    e.Label,
+			InCycle:  e.InCycle,
+		})
+	}
+
+	for _, p := range g.packages {
+		doc.Packages = append(doc.Packages, jsonPackage{
+			Path:         p.Path,
+			Dependencies: p.Dependencies,
+		})
+	}
+
+	return doc
+}
+
+func edgeLabel(e Edge) string {
+	if e.Label == "" {
+		return e.Relation.String()
+	}
+
+	return e.Relation.String() + " " + e.Label
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
+
+func flush(buf *bufio.Writer) error {
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("%w: %w", err, ErrExport)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package export
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	g := NewGraph(
+		[]Node{
+			{ID: "#", Name: "schema", Kind: KindRoot},
+			{ID: "#/$defs/node", Name: "node", Kind: KindNamed, InCycle: true},
+			{ID: "#/$defs/nested/$defs/label", Name: "label", Package: "nested", Kind: KindNamed, Type: "string"},
+		},
+		[]Edge{
+			{From: "#/$defs/node", To: "#/$defs/node", Relation: RelationProperty, Label: "next", InCycle: true},
+			{From: "#/$defs/node", To: "#/$defs/nested/$defs/label", Relation: RelationProperty, Label: "label"},
+			{From: "#", To: "#/$defs/node", Relation: RelationProperty, Label: "node"},
+			{From: "#", To: "#/$defs/missing", Relation: RelationRef},
+		},
+		[]Package{
+			{Path: "", Dependencies: []string{"nested"}},
+			{Path: "nested"},
+		},
+		[][]string{{"#/$defs/node"}},
+	)
+
+	t.Run("should render DOT", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, g.Write(&buf, FormatDOT))
+
+		assert.Equal(t, `digraph schemas {
+  rankdir=LR;
+  node [shape=box];
+  "#" [label="schema", style=bold];
+  "#/$defs/node" [label="node", color=red];
+  subgraph "cluster_1" {
+    label="nested";
+    "#/$defs/nested/$defs/label" [label="label"];
+  }
+  "#" -> "#/$defs/node" [label="property node"];
+  "#/$defs/node" -> "#/$defs/node" [label="property next", color=red];
+  "#/$defs/node" -> "#/$defs/nested/$defs/label" [label="property label"];
+}
+`, buf.String())
+	})
+
+	t.Run("should render mermaid", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, g.Write(&buf, FormatMermaid))
+
+		assert.Equal(t, `flowchart LR
+  n0["schema"]
+  n1["node"]
+  subgraph p1["nested"]
+    n2["label"]
+  end
+  n0 -->|"property node"| n1
+  n1 -->|"property next"| n1
+  n1 -->|"property label"| n2
+  classDef cycle stroke:red
+  class n1 cycle
+  linkStyle 1 stroke:red
+`, buf.String())
+	})
+
+	t.Run("should render JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, g.Write(&buf, FormatJSON))
+
+		assert.JSONEq(t, `{
+			"nodes": [
+				{"id": "#", "name": "schema", "kind": "root", "edges": [
+					{"to": "#/$defs/node", "relation": "property", "label": "node"}
+				]},
+				{"id": "#/$defs/node", "name": "node", "kind": "named", "cycle": true, "edges": [
+					{"to": "#/$defs/node", "relation": "property", "label": "next", "cycle": true},
+					{"to": "#/$defs/nested/$defs/label", "relation": "property", "label": "label"}
+				]},
+				{"id": "#/$defs/nested/$defs/label", "name": "label", "package": "nested", "kind": "named", "type": "string"}
+			],
+			"packages": [
+				{"path": "", "dependencies": ["nested"]},
+				{"path": "nested"}
+			],
+			"cycles": [["#/$defs/node"]]
+		}`, buf.String())
+
+		data, err := stdjson.Marshal(g)
+		require.NoError(t, err)
+		assert.JSONEq(t, buf.String(), string(data))
+	})
+
+	t.Run("should reject unknown formats", func(t *testing.T) {
+		require.ErrorIs(t, g.Write(&bytes.Buffer{}, Format(99)), ErrExport)
+	})
+}
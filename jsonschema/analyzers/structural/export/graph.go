@@ -0,0 +1,255 @@
+package export
+
+import (
+	"iter"
+	"slices"
+)
+
+// Kind of schema in the [Graph].
+type Kind uint8
+
+const (
+	// KindAnonymous is a subschema without a name
+	KindAnonymous Kind = iota
+	// KindNamed is a schema in definitions, with an "$id" or the target of a "$ref"
+	KindNamed
+	// KindRoot is the root schema of a document
+	KindRoot
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNamed:
+		return "named"
+	case KindRoot:
+		return "root"
+	default:
+		return "anonymous"
+	}
+}
+
+// Relation tells how a schema depends on another one.
+type Relation uint8
+
+const (
+	RelationNone Relation = iota
+	// RelationRef is a "$ref" (or "$dynamicRef") to the target schema
+	RelationRef
+	// RelationProperty is a schema in "properties"
+	RelationProperty
+	// RelationPatternProperty is a schema in "patternProperties"
+	RelationPatternProperty
+	// RelationAdditionalProperties is the "additionalProperties" schema
+	RelationAdditionalProperties
+	// RelationPropertyNames is the "propertyNames" schema
+	RelationPropertyNames
+	// RelationDependentSchema is a schema in "dependentSchemas" or legacy "dependencies"
+	RelationDependentSchema
+	// RelationItems is the schema of all (or additional) items, i.e. "items" or legacy "additionalItems"
+	RelationItems
+	// RelationPrefixItems is a schema in "prefixItems", or in legacy "items" as an array
+	RelationPrefixItems
+	// RelationContains is the "contains" schema
+	RelationContains
+	// RelationAllOf is a member of an "allOf"
+	RelationAllOf
+	// RelationAnyOf is a member of an "anyOf"
+	RelationAnyOf
+	// RelationOneOf is a member of a "oneOf"
+	RelationOneOf
+	// RelationNot is the "not" schema
+	RelationNot
+	// RelationConditional is a schema in "if", "then" or "else"
+	RelationConditional
+	// RelationSubtype relates a base type with a discriminator to one of its subtypes
+	RelationSubtype
+	// RelationOther is any other subschema, e.g. "unevaluatedProperties" or "contentSchema"
+	RelationOther
+)
+
+func (r Relation) String() string {
+	switch r {
+	case RelationRef:
+		return "$ref"
+	case RelationProperty:
+		return "property"
+	case RelationPatternProperty:
+		return "patternProperty"
+	case RelationAdditionalProperties:
+		return "additionalProperties"
+	case RelationPropertyNames:
+		return "propertyNames"
+	case RelationDependentSchema:
+		return "dependentSchema"
+	case RelationItems:
+		return "items"
+	case RelationPrefixItems:
+		return "prefixItems"
+	case RelationContains:
+		return "contains"
+	case RelationAllOf:
+		return "allOf"
+	case RelationAnyOf:
+		return "anyOf"
+	case RelationOneOf:
+		return "oneOf"
+	case RelationNot:
+		return "not"
+	case RelationConditional:
+		return "conditional"
+	case RelationSubtype:
+		return "subtype"
+	case RelationOther:
+		return "other"
+	default:
+		return "none"
+	}
+}
+
+// Node is a schema in the [Graph].
+type Node struct {
+	// ID of the schema, i.e. its location as a document URI with a JSON pointer fragment
+	ID string
+
+	// Name of the schema, empty for anonymous schemas
+	Name string
+
+	// Package holding the schema, e.g. "models/pet"
+	Package string
+
+	// Kind of schema
+	Kind Kind
+
+	// Type of the schema, e.g. "object" or "string,null". It is empty when no type is specified
+	Type string
+
+	// IsEnum tells if the schema has an "enum" or a "const" validation
+	IsEnum bool
+
+	// HasRef tells if the schema has a "$ref"
+	HasRef bool
+
+	// InCycle tells if the schema belongs to a cycle of dependencies
+	InCycle bool
+}
+
+// Label of the node: its name, or its location when it is anonymous.
+func (n Node) Label() string {
+	if n.Name != "" {
+		return n.Name
+	}
+
+	return n.ID
+}
+
+// IsNamed tells if the node is a root or a named schema.
+func (n Node) IsNamed() bool {
+	return n.Kind != KindAnonymous
+}
+
+// Edge is a dependency between two schemas in the [Graph].
+type Edge struct {
+	// From is the ID of the dependent schema
+	From string
+
+	// To is the ID of the dependency
+	To string
+
+	// Relation between the schemas
+	Relation Relation
+
+	// Label characterizes the relation, e.g. a property name, an index or a discriminator value
+	Label string
+
+	// InCycle tells if the edge belongs to a cycle of dependencies
+	InCycle bool
+}
+
+// Package groups schemas.
+type Package struct {
+	// Path of the package, e.g. "models/pet". The package of the root document has an empty path
+	Path string
+
+	// Dependencies are the paths of the packages holding the dependencies of the schemas in this package
+	Dependencies []string
+}
+
+// Graph of JSON schemas, with packages and cycles.
+type Graph struct {
+	nodes    []Node
+	index    map[string]int
+	edges    []Edge
+	packages []Package
+	cycles   [][]string
+}
+
+// Len is the number of schemas in the graph.
+func (g Graph) Len() int {
+	return len(g.nodes)
+}
+
+// Node yields a schema, given its ID.
+func (g Graph) Node(id string) (Node, bool) {
+	i, ok := g.index[id]
+	if !ok {
+		return Node{}, false
+	}
+
+	return g.nodes[i], true
+}
+
+// Nodes yields all schemas.
+func (g Graph) Nodes() iter.Seq[Node] {
+	return slices.Values(g.nodes)
+}
+
+// Edges yields all dependencies between schemas.
+func (g Graph) Edges() iter.Seq[Edge] {
+	return slices.Values(g.edges)
+}
+
+// Packages yields all packages, sorted by path.
+func (g Graph) Packages() iter.Seq[Package] {
+	return slices.Values(g.packages)
+}
+
+// Cycles yields the IDs of schemas which depend on each other, as strongly connected components of the graph.
+func (g Graph) Cycles() iter.Seq[[]string] {
+	return slices.Values(g.cycles)
+}
+
+// HasCycle tells if the graph has cycles.
+func (g Graph) HasCycle() bool {
+	return len(g.cycles) > 0
+}
+
+// NewGraph builds a [Graph] of schemas.
+//
+// Edges between unknown schemas are ignored. Edges are grouped by the schema they depend from, in the order of nodes.
+func NewGraph(nodes []Node, edges []Edge, packages []Package, cycles [][]string) Graph {
+	g := Graph{
+		nodes:    nodes,
+		index:    make(map[string]int, len(nodes)),
+		edges:    make([]Edge, 0, len(edges)),
+		packages: packages,
+		cycles:   cycles,
+	}
+
+	for i, n := range nodes {
+		g.index[n.ID] = i
+	}
+
+	for _, e := range edges {
+		_, hasFrom := g.index[e.From]
+		_, hasTo := g.index[e.To]
+		if hasFrom && hasTo {
+			g.edges = append(g.edges, e)
+		}
+	}
+
+	slices.SortStableFunc(g.edges, func(x, y Edge) int {
+		return g.index[x.From] - g.index[y.From]
+	})
+
+	return g
+}
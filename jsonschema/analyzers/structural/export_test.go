@@ -0,0 +1,210 @@
+package structural
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/analyzers/structural/export"
+	"github.com/fredbi/core/jsonschema/analyzers/structural/order"
+)
+
+func TestExportGraph(t *testing.T) {
+	exportGraph := func(t *testing.T, filterSpecs ...Filter) export.Graph {
+		t.Helper()
+
+		resolver := jsonschema.NewResolver()
+		require.NoError(t, resolver.AddDocument(testRemote, mustSchema(t, testPets)))
+
+		a := NewAnalyzer(WithResolver(resolver), WithBaseURI(testBase))
+		require.NoError(t, a.Analyze(mustSchema(t, testMain)))
+
+		return a.exportGraph(applyFiltersWithDefault(filterSpecs))
+	}
+
+	t.Run("should export all schemas and their dependencies", func(t *testing.T) {
+		g := exportGraph(t)
+
+		root, ok := g.Node(testBase + "#")
+		require.True(t, ok)
+		assert.Equal(t, export.KindRoot, root.Kind)
+		assert.Equal(t, "main", root.Name)
+		assert.Empty(t, root.Package)
+		assert.Equal(t, "object", root.Type)
+
+		pet, ok := g.Node(testRemote + "#/$defs/pet")
+		require.True(t, ok)
+		assert.Equal(t, export.KindNamed, pet.Kind)
+		assert.Equal(t, "models/pet", pet.Package)
+
+		kind, ok := g.Node(testRemote + "#/$defs/kind")
+		require.True(t, ok)
+		assert.True(t, kind.IsEnum)
+
+		owner, ok := g.Node(testBase + "#/properties/owner")
+		require.True(t, ok)
+		assert.Equal(t, export.KindAnonymous, owner.Kind)
+		assert.True(t, owner.HasRef)
+
+		edges := slices.Collect(g.Edges())
+		assert.Contains(t, edges, export.Edge{
+			From: testBase + "#", To: testBase + "#/properties/tags", Relation: export.RelationProperty, Label: "tags",
+		})
+		assert.Contains(t, edges, export.Edge{
+			From: testBase + "#/properties/tags", To: testBase + "#/properties/tags/items", Relation: export.RelationItems,
+		})
+		assert.Contains(t, edges, export.Edge{
+			From: testBase + "#/properties/owner", To: testBase + "#/$defs/owner", Relation: export.RelationRef,
+		})
+	})
+
+	t.Run("should relate base types to their subtypes", func(t *testing.T) {
+		g := exportGraph(t)
+
+		assert.Contains(t, slices.Collect(g.Edges()), export.Edge{
+			From: testRemote + "#/$defs/pet", To: testRemote + "#/$defs/cat", Relation: export.RelationSubtype, Label: "cat",
+		})
+
+		cat, ok := g.Node(testRemote + "#/$defs/cat")
+		require.True(t, ok)
+		assert.False(t, cat.InCycle, "subtypes are not cyclic dependencies")
+	})
+
+	t.Run("should detect cycles", func(t *testing.T) {
+		g := exportGraph(t)
+
+		require.True(t, g.HasCycle())
+		assert.Equal(t, [][]string{{
+			testBase + "#/$defs/owner",
+			testBase + "#/$defs/owner/properties/friends",
+			testBase + "#/$defs/owner/properties/friends/items",
+		}}, slices.Collect(g.Cycles()))
+
+		owner, ok := g.Node(testBase + "#/$defs/owner")
+		require.True(t, ok)
+		assert.True(t, owner.InCycle)
+
+		root, ok := g.Node(testBase + "#")
+		require.True(t, ok)
+		assert.False(t, root.InCycle)
+	})
+
+	t.Run("should collapse anonymous schemas", func(t *testing.T) {
+		g := exportGraph(t, OnlyNamedSchemas())
+
+		for n := range g.Nodes() {
+			assert.Truef(t, n.IsNamed(), "unexpected anonymous schema %s", n.ID)
+		}
+
+		edges := slices.Collect(g.Edges())
+		assert.Contains(t, edges, export.Edge{
+			From: testBase + "#", To: testRemote + "#/$defs/pet", Relation: export.RelationProperty, Label: "pet",
+		})
+		assert.Contains(t, edges, export.Edge{
+			From: testBase + "#/$defs/owner", To: testBase + "#/$defs/owner", Relation: export.RelationProperty,
+			Label: "friends/items", InCycle: true,
+		})
+		assert.Contains(t, edges, export.Edge{
+			From: testRemote + "#/$defs/cat", To: testRemote + "#/$defs/pet", Relation: export.RelationAllOf,
+			Label: "allOf/0",
+		})
+
+		assert.Equal(t, [][]string{{testBase + "#/$defs/owner"}}, slices.Collect(g.Cycles()))
+		assert.Equal(t, []export.Package{
+			{Path: "", Dependencies: []string{"models/pet"}},
+			{Path: "models/pet"},
+		}, slices.Collect(g.Packages()))
+	})
+
+	t.Run("should filter schemas", func(t *testing.T) {
+		t.Run("with enums only", func(t *testing.T) {
+			g := exportGraph(t, OnlyEnumSchemas())
+
+			assert.Equal(t, []string{testRemote + "#/$defs/kind"}, exportedIDs(g))
+			assert.Empty(t, slices.Collect(g.Edges()))
+		})
+
+		t.Run("with leaves only", func(t *testing.T) {
+			g := exportGraph(t, OnlyNamedSchemas(), OnlyLeaves())
+
+			assert.Equal(t, []string{testRemote + "#/$defs/kind"}, exportedIDs(g))
+		})
+
+		t.Run("with packages", func(t *testing.T) {
+			g := exportGraph(t,
+				OnlyNamedSchemas(),
+				WithPackageFilterFunc(func(p AnalyzedPackage) bool { return p.Path() == "models/pet" }),
+			)
+
+			assert.ElementsMatch(t, []string{
+				testRemote + "#/$defs/pet", testRemote + "#/$defs/cat", testRemote + "#/$defs/kind",
+			}, exportedIDs(g))
+			assert.Equal(t, []export.Package{{Path: "models/pet"}}, slices.Collect(g.Packages()))
+		})
+
+		t.Run("with a function", func(t *testing.T) {
+			g := exportGraph(t,
+				OnlyNamedSchemas(),
+				WithFilterFunc(func(s AnalyzedSchema) bool { return s.IsObject() }),
+			)
+
+			assert.ElementsMatch(t, []string{
+				testBase + "#", testBase + "#/$defs/owner", testRemote + "#/$defs/pet",
+			}, exportedIDs(g))
+		})
+	})
+
+	t.Run("should order schemas", func(t *testing.T) {
+		const input = `{
+			"properties": {"a": {"$ref": "#/$defs/a"}},
+			"$defs": {
+				"b": {"type": "string"},
+				"a": {"properties": {"b": {"$ref": "#/$defs/b"}}}
+			}
+		}`
+
+		a := NewAnalyzer(WithBaseURI(testBase))
+		require.NoError(t, a.Analyze(mustSchema(t, input)))
+
+		for _, tc := range []struct {
+			ordering order.SchemaOrdering
+			expected []string
+		}{
+			{ordering: order.TopDown, expected: []string{"#", "#/$defs/a", "#/$defs/b"}},
+			{ordering: order.BottomUp, expected: []string{"#/$defs/b", "#/$defs/a", "#"}},
+		} {
+			g := a.exportGraph(applyFiltersWithDefault([]Filter{OnlyNamedSchemas(), WithOrderedSchemas(tc.ordering)}))
+
+			var pointers []string
+			for _, id := range exportedIDs(g) {
+				pointers = append(pointers, strings.TrimPrefix(id, testBase))
+			}
+			assert.Equalf(t, tc.expected, pointers, "with ordering %v", tc.ordering)
+		}
+	})
+
+	t.Run("should write the graph", func(t *testing.T) {
+		a := NewAnalyzer(WithBaseURI(testBase))
+		require.NoError(t, a.Analyze(mustSchema(t, `{"type": "string"}`)))
+
+		var buf bytes.Buffer
+		require.NoError(t, a.ExportGraph(&buf, export.FormatMermaid))
+		assert.Equal(t, "flowchart LR\n  n0[\"main\"]\n", buf.String())
+
+		require.ErrorIs(t, a.ExportGraph(&bytes.Buffer{}, export.Format(99)), ErrExport)
+	})
+}
+
+func exportedIDs(g export.Graph) []string {
+	var result []string
+	for n := range g.Nodes() {
+		result = append(result, n.ID)
+	}
+
+	return result
+}
@@ -90,3 +90,21 @@ func WithPackageFilterFunc(fn func(AnalyzedPackage) bool) Filter {
 		f.PkgFilterFunc = fn
 	}
 }
+
+// keeps tells if a schema passes the filters.
+func (f filters) keeps(s AnalyzedSchema) bool {
+	switch {
+	case f.WantsNamed && !s.IsNamed():
+		return false
+	case f.WantsEnum && !s.IsEnum():
+		return false
+	case f.ExcludeEnum && s.IsEnum():
+		return false
+	case f.WantsRef && s.ref == "":
+		return false
+	case f.FilterFunc != nil && !f.FilterFunc(s):
+		return false
+	default:
+		return true
+	}
+}
@@ -2,54 +2,169 @@ package structural
 
 import (
 	"iter"
+	"path"
+	"strings"
 
 	"github.com/fredbi/core/jsonschema/analyzers"
 	"github.com/fredbi/core/jsonschema/analyzers/internal/graph/v2"
+	"github.com/fredbi/core/swag/typeutils"
 )
 
+// packageTree is the tree of the discovered packages, from the root package with an empty path.
 type packageTree struct {
-	graph.Tree[analyzers.UniqueID, AnalyzedPackage, struct{}]
+	*graph.Tree[analyzers.UniqueID, *AnalyzedPackage, struct{}]
 }
 
 func newPackageTree() *packageTree {
-	return &packageTree{}
+	return &packageTree{
+		Tree: graph.NewTree[analyzers.UniqueID, *AnalyzedPackage, struct{}](),
+	}
 }
 
-func (p *packageTree) AddPath(pth string) {
+// packageID is the unique ID of the package with a path.
+func packageID(pth string) analyzers.UniqueID {
+	return analyzers.UniqueID("/" + pth)
+}
+
+// AddPath adds a package and its ancestors to the tree. It tells if the package was already known.
+func (p *packageTree) AddPath(pth string) (*AnalyzedPackage, bool) {
+	if node, found := p.Node(packageID(pth)); found {
+		return node.Value(), true
+	}
+
+	pkg := &AnalyzedPackage{
+		analyzedObject: analyzedObject{
+			id:   packageID(pth),
+			name: path.Base(pth),
+			path: pth,
+		},
+	}
+	if pth == "" {
+		pkg.name = ""
+	}
+	_, _ = p.AddNode(pkg.id, pkg)
+
+	if pth == "" {
+		return pkg, false
+	}
+
+	var parentPath string
+	if i := strings.LastIndex(pth, "/"); i >= 0 {
+		parentPath = pth[:i]
+	}
+
+	parent, _ := p.AddPath(parentPath)
+	pkg.parent = parent
+	parent.children = append(parent.children, pkg)
+	_, _ = p.AddEdge(parent.id, pkg.id, struct{}{})
+
+	return pkg, false
+}
+
+func (p *packageTree) node(id analyzers.UniqueID) (*AnalyzedPackage, bool) {
+	node, found := p.Node(id)
+	if !found {
+		return nil, false
+	}
+
+	return node.Value(), true
 }
 
 func (p *packageTree) PackageByID(id analyzers.UniqueID) (AnalyzedPackage, bool) {
-	return AnalyzedPackage{}, false
+	pkg, found := p.node(id)
+	if !found {
+		return AnalyzedPackage{}, false
+	}
+
+	return *pkg, true
 }
 
 func (p *packageTree) Leaves() iter.Seq[AnalyzedPackage] {
-	return nil
+	return packages(p.DiGraph.Leaves())
 }
 
 func (p *packageTree) TraverseDFS() iter.Seq[AnalyzedPackage] {
-	return nil
+	return packages(p.DiGraph.TraverseDFS())
 }
 
-func (p *packageTree) TraverseBFS() iter.Seq[AnalyzedPackage] { // NO: this is for DAG
-	return nil
+// TraverseBottomUp yields the packages from the leaves up to the root package.
+func (p *packageTree) TraverseBottomUp() iter.Seq[AnalyzedPackage] {
+	return packages(p.Inverted().TraverseTopological())
 }
 
-func (p *packageTree) Inverted() *packageTree { // TODO: does not return a tree but a DAG
-	return nil
+func packages(nodes iter.Seq[*graph.Node[analyzers.UniqueID, *AnalyzedPackage]]) iter.Seq[AnalyzedPackage] {
+	return typeutils.TransformIter(nodes, func(node *graph.Node[analyzers.UniqueID, *AnalyzedPackage]) (AnalyzedPackage, bool) {
+		return *node.Value(), true
+	})
 }
 
+// schemaGraph is the dependency graph of the discovered schemas, identified by their location.
+//
+// Edges go from a schema to its dependencies: its subschemas and the targets of its "$ref" s.
+// Schemas in definitions are not dependencies of the schema holding the definitions.
 type schemaGraph struct {
-	graph.DAG[analyzers.UniqueID, AnalyzedSchema, AnalyzedSchemaContext]
+	*graph.DiGraph[analyzers.UniqueID, *AnalyzedSchema, AnalyzedSchemaContext]
 }
 
 func newSchemaGraph() *schemaGraph {
-	return &schemaGraph{}
+	return &schemaGraph{
+		DiGraph: graph.NewDiGraph[analyzers.UniqueID, *AnalyzedSchema, AnalyzedSchemaContext](),
+	}
+}
+
+func (s *schemaGraph) node(id analyzers.UniqueID) (*AnalyzedSchema, bool) {
+	node, found := s.Node(id)
+	if !found {
+		return nil, false
+	}
+
+	return node.Value(), true
 }
 
 func (s *schemaGraph) SchemaByID(id analyzers.UniqueID) (AnalyzedSchema, bool) {
-	return AnalyzedSchema{}, false
+	schema, found := s.node(id)
+	if !found {
+		return AnalyzedSchema{}, false
+	}
+
+	return *schema, true
+}
+
+// link a schema to one of its dependencies.
+func (s *schemaGraph) link(from, to *AnalyzedSchema, c AnalyzedSchemaContext) {
+	c.from, c.to = from, to
+	_, _ = s.AddEdge(from.id, to.id, c)
+
+	from.children = append(from.children, to)
+	to.parents = append(to.parents, from)
+}
+
+// Dependencies of a schema, with their context.
+func (s *schemaGraph) Dependencies(id analyzers.UniqueID) iter.Seq[AnalyzedSchemaContext] {
+	return typeutils.TransformIter(s.Out(id), func(e *graph.Edge[analyzers.UniqueID, AnalyzedSchemaContext]) (AnalyzedSchemaContext, bool) {
+		return e.Value(), true
+	})
+}
+
+func (s *schemaGraph) Nodes() iter.Seq[AnalyzedSchema] {
+	return schemas(s.DiGraph.Nodes())
+}
+
+func (s *schemaGraph) Leaves() iter.Seq[AnalyzedSchema] {
+	return schemas(s.DiGraph.Leaves())
+}
+
+func (s *schemaGraph) TraverseDFS() iter.Seq[AnalyzedSchema] {
+	return schemas(s.DiGraph.TraverseDFS())
+}
+
+// TraverseBottomUp yields the schemas so that dependencies come before the schemas that depend on them.
+func (s *schemaGraph) TraverseBottomUp() iter.Seq[AnalyzedSchema] {
+	return schemas(s.Inverted().TraverseTopological())
 }
 
-func (s *schemaGraph) SchemaByPath(pth string) (AnalyzedSchema, bool) {
-	return AnalyzedSchema{}, false
+func schemas(nodes iter.Seq[*graph.Node[analyzers.UniqueID, *AnalyzedSchema]]) iter.Seq[AnalyzedSchema] {
+	return typeutils.TransformIter(nodes, func(node *graph.Node[analyzers.UniqueID, *AnalyzedSchema]) (AnalyzedSchema, bool) {
+		return *node.Value(), true
+	})
 }
@@ -1,10 +1,12 @@
 package structural
 
 import (
+	"io"
 	"iter"
 
 	"github.com/fredbi/core/jsonschema"
 	"github.com/fredbi/core/jsonschema/analyzers"
+	"github.com/fredbi/core/jsonschema/analyzers/structural/export"
 )
 
 // Analyzer is the interface exposed by a structural analyzer.
@@ -23,6 +25,13 @@ type Analyzer interface {
 	// [Analyzer.Bundle] may mutate schemas and packages, and invokes callbacks whenever visiting a package or a schema.
 	Bundle() (Analyzer, error)
 
+	// ExportGraph writes the dependency graph of the analyzed schemas, in DOT, mermaid or JSON format.
+	//
+	// [Filter] s may be added to restrict the scope or defined a specific ordering.
+	//
+	// [Analyzer.ExportGraph] does not mutate anything and no callbacks are invoked.
+	ExportGraph(io.Writer, export.Format, ...Filter) error
+
 	// AnalyzedSchemas iterates over the analyzed schemas, possibly applying some [Filter]
 	//
 	// [Filter] s may be added to restrict the scope or defined a specific ordering.
@@ -30,6 +39,9 @@ type Analyzer interface {
 	// [Analyzer.AnalyzedSchemas] does not mutate anything and no callbacks are invoked.
 	AnalyzedSchemas(...Filter) iter.Seq[AnalyzedSchema]
 
+	// Schemas iterates over the analyzed root schemas, e.g. the bundled schemas after [Analyzer.Bundle].
+	Schemas() iter.Seq[jsonschema.Schema]
+
 	// Number of analyzed schemas, with all inner sub-schemas
 	Len() int
 
@@ -0,0 +1,249 @@
+package structural
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/analyzers"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// nameOf a schema which is not a definition: its "$id" or a name derived from its location.
+func (a *SchemaAnalyzer) nameOf(resolved jsonschema.ResolvedSchema) string {
+	if id, ok := keywords.StringAt(resolved.Document, "$id"); ok {
+		if segments := keywords.DocumentPackages(id, ""); len(segments) > 0 {
+			return segments[len(segments)-1]
+		}
+	}
+
+	tokens := pointerTokens(resolved.Pointer())
+	if len(tokens) == 0 {
+		return a.documentName(resolved.DocumentURI())
+	}
+
+	name := tokens[len(tokens)-1]
+	if len(tokens) > 1 && (jsonschema.SubschemaKeyword(name) != jsonschema.NoSubschema || isIndex(name)) {
+		name = tokens[len(tokens)-2] + capitalize(name)
+	}
+
+	return name
+}
+
+// documentName is the name of the root schema of a document, after its URI, e.g. "pet" for
+// "https://example.com/models/pet.json".
+func (a *SchemaAnalyzer) documentName(uri string) string {
+	segments := keywords.DocumentPackages(uri, "")
+	if len(segments) == 0 {
+		return "schema"
+	}
+
+	return segments[len(segments)-1]
+}
+
+// packageOf a schema: the path of its document, unless it is a root document, followed by the names of the
+// definitions which hold nested definitions, e.g. "a" for "#/$defs/a/$defs/b".
+//
+// The path of a document is relative to the folder of the first analyzed document.
+func (a *SchemaAnalyzer) packageOf(uri, pointer string) string {
+	var packages []string
+	if !slices.Contains(a.rootURIs, uri) {
+		packages = a.documentPackages(uri)
+	}
+
+	// schemas nested in a definition belong to the package of this definition
+	tokens := pointerTokens(pointer)
+	last := -1
+	for i, token := range tokens {
+		if isDefinitions(token) {
+			last = i
+		}
+	}
+
+	for i := 0; i < last; i++ {
+		if isDefinitions(tokens[i]) {
+			packages = append(packages, tokens[i+1])
+		}
+	}
+
+	return strings.Join(packages, "/")
+}
+
+// documentPackages is the package path of a document, relative to the folder of the first root document.
+func (a *SchemaAnalyzer) documentPackages(uri string) []string {
+	var root string
+	if len(a.rootURIs) > 0 {
+		root = a.rootURIs[0]
+	}
+
+	return keywords.DocumentPackages(uri, root)
+}
+
+// kindOf a schema, after its "type" or, if none is specified, its keywords.
+func kindOf(doc json.Document, version jsonschema.Version) (analyzers.SchemaKind, analyzers.ScalarKind, analyzers.PolymorphismKind) {
+	polymorphism := analyzers.PolymorphismNone
+	if _, ok := doc.AtKey("oneOf"); ok {
+		polymorphism = analyzers.PolymorphismOneOf
+	} else if _, ok := doc.AtKey("anyOf"); ok {
+		polymorphism = analyzers.PolymorphismAnyOf
+	}
+
+	types := typesOf(doc)
+	switch {
+	case len(types) > 1:
+		return analyzers.SchemaKindPolymorphic, analyzers.ScalarKindString, polymorphism
+	case len(types) == 1:
+		switch types[0] {
+		case "object":
+			return analyzers.SchemaKindObject, analyzers.ScalarKindString, polymorphism
+		case "array":
+			return arrayKindOf(doc, version), analyzers.ScalarKindString, polymorphism
+		case "number":
+			return analyzers.SchemaKindScalar, analyzers.ScalarKindNumber, polymorphism
+		case "integer":
+			return analyzers.SchemaKindScalar, analyzers.ScalarKindInteger, polymorphism
+		case "boolean":
+			return analyzers.SchemaKindScalar, analyzers.ScalarKindBool, polymorphism
+		case "null":
+			return analyzers.SchemaKindScalar, analyzers.ScalarKindNull, polymorphism
+		default:
+			return analyzers.SchemaKindScalar, analyzers.ScalarKindString, polymorphism
+		}
+	}
+
+	for _, keyword := range []string{"properties", "patternProperties", "additionalProperties"} {
+		if _, ok := doc.AtKey(keyword); ok {
+			return analyzers.SchemaKindObject, analyzers.ScalarKindString, polymorphism
+		}
+	}
+
+	for _, keyword := range []string{"items", "prefixItems"} {
+		if _, ok := doc.AtKey(keyword); ok {
+			return arrayKindOf(doc, version), analyzers.ScalarKindString, polymorphism
+		}
+	}
+
+	if polymorphism != analyzers.PolymorphismNone {
+		return analyzers.SchemaKindPolymorphic, analyzers.ScalarKindString, polymorphism
+	}
+
+	return analyzers.SchemaKindNone, analyzers.ScalarKindString, polymorphism
+}
+
+func arrayKindOf(doc json.Document, version jsonschema.Version) analyzers.SchemaKind {
+	if _, ok := doc.AtKey("prefixItems"); ok {
+		return analyzers.SchemaKindTuple
+	}
+
+	if items, ok := doc.AtKey("items"); ok && items.IsArray() && jsonschema.AllowsItemsArray(version) {
+		return analyzers.SchemaKindTuple
+	}
+
+	return analyzers.SchemaKindArray
+}
+
+// typesOf a schema, as specified by "type".
+func typesOf(doc json.Document) []string {
+	value, ok := doc.AtKey("type")
+	if !ok {
+		return nil
+	}
+
+	if value.IsString() {
+		v, _ := value.Value()
+
+		return []string{v.String()}
+	}
+
+	var types []string
+	for elem := range value.Elems() {
+		if elem.IsString() {
+			v, _ := elem.Value()
+			types = append(types, v.String())
+		}
+	}
+
+	return types
+}
+
+// relationOf a subschema with its parent, after the keyword that holds it.
+func relationOf(sub jsonschema.Subschema, parent json.Document, version jsonschema.Version) SchemaRelation {
+	switch sub.Keyword {
+	case "properties":
+		return SchemaRelationProperty
+	case "patternProperties":
+		return SchemaRelationPatternProperty
+	case "additionalProperties", "unevaluatedProperties":
+		return SchemaRelationAdditionalProperty
+	case "propertyNames":
+		return SchemaRelationPropertyNames
+	case "dependentSchemas", "dependencies":
+		return SchemaRelationDependentSchema
+	case "items":
+		if sub.Kind == jsonschema.SubschemaArray {
+			// legacy tuple
+			return SchemaRelationTupleItems
+		}
+
+		if arrayKindOf(parent, version) == analyzers.SchemaKindTuple {
+			// items after "prefixItems"
+			return SchemaRelationTupleAdditionalProperty
+		}
+
+		return SchemaRelationItems
+	case "additionalItems", "unevaluatedItems":
+		return SchemaRelationTupleAdditionalProperty
+	case "prefixItems":
+		return SchemaRelationTupleItems
+	case "contains":
+		return SchemaRelationContains
+	case "allOf":
+		return SchemaRelationAllOf
+	case "anyOf":
+		return SchemaRelationAnyOf
+	case "oneOf":
+		return SchemaRelationOneOf
+	case "not":
+		return SchemaRelationNot
+	case "if", "then", "else":
+		return SchemaRelationConditional
+	default:
+		return SchemaRelationOther
+	}
+}
+
+func pointerTokens(pointer string) []string {
+	if pointer == "" {
+		return nil
+	}
+
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = json.UnescapeToken(token)
+	}
+
+	return tokens
+}
+
+func isDefinitions(keyword string) bool {
+	return keyword == "$defs" || keyword == "definitions"
+}
+
+func isIndex(token string) bool {
+	_, err := strconv.Atoi(token)
+
+	return err == nil
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+
+	return string(unicode.ToUpper(r)) + s[size:]
+}
@@ -7,7 +7,7 @@ import (
 
 // AnnotateSchema allows to alter [Metadata] for a schema.
 func (a *SchemaAnalyzer) AnnotateSchema(s AnalyzedSchema, meta Metadata) {
-	schema, ok := a.schemas.SchemaByID(s.ID())
+	schema, ok := a.schemas.node(s.ID())
 	if !ok {
 		return
 	}
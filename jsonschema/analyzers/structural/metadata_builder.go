@@ -1,46 +1,46 @@
 package structural
 
-import "github.com/fredbi/core/json/stores"
+import "github.com/fredbi/core/jsonschema"
 
+// MetadataBuilder builds the [Metadata] of an [AnalyzedSchema], e.g. to pass to [SchemaAnalyzer.AnnotateSchema].
 type MetadataBuilder struct {
-	s   stores.Store
-	err error
-	m   Metadata
+	b *jsonschema.Builder
+	m Metadata
 }
 
 func MakeMetadataBuilder() MetadataBuilder {
-	return MetadataBuilder{}
-}
-
-func (b MetadataBuilder) WithStore(s stores.Store) MetadataBuilder {
-	b.s = s
-
-	return b
+	return MetadataBuilder{
+		b: jsonschema.NewBuilder(),
+	}
 }
 
+// From starts from the [Metadata] of an [AnalyzedSchema].
 func (b MetadataBuilder) From(analyzed AnalyzedSchema) MetadataBuilder {
-	b.s = analyzed.Metadata().Store()
+	b.b = jsonschema.NewBuilder().From(analyzed.document)
 	b.m = analyzed.Metadata()
 
 	return b
 }
 
 func (b MetadataBuilder) WithTitle(title string) MetadataBuilder {
-	b.m.title = b.s.PutValue(stores.MakeStringValue(title))
+	b.b = b.b.WithTitle(title)
 
 	return b
 }
 
 func (b MetadataBuilder) WithDescription(description string) MetadataBuilder {
-	b.m.title = b.s.PutValue(stores.MakeStringValue(description))
+	b.b = b.b.WithDescription(description)
 
 	return b
 }
 
 func (b MetadataBuilder) Metadata() Metadata {
-	if b.err == nil {
-		return b.m
+	if !b.b.Ok() {
+		return Metadata{}
 	}
 
-	return Metadata{}
+	m := b.m
+	m.Metadata = b.b.Schema().Metadata()
+
+	return m
 }
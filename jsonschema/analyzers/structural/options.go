@@ -1,6 +1,9 @@
 package structural
 
-import "github.com/fredbi/core/jsonschema/analyzers/validations"
+import (
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/analyzers/validations"
+)
 
 // Option customizes the behavior of the [SchemaAnalyzer].
 type Option func(*options)
@@ -8,6 +11,9 @@ type Option func(*options)
 type options struct {
 	bundleOptions
 
+	resolver *jsonschema.Resolver
+	baseURI  string
+
 	withValidations bool
 	withReportAudit bool // will report audit records as a "x-go-audit" extension
 
@@ -36,6 +42,25 @@ func applyOptionsWithDefaults(opts []Option) options {
 	return o
 }
 
+// WithResolver equips the [SchemaAnalyzer] with a [jsonschema.Resolver] to resolve "$ref" s.
+//
+// This is useful to resolve "$ref" s to documents which are known in advance.
+// By default, the analyzer uses a new resolver.
+func WithResolver(resolver *jsonschema.Resolver) Option {
+	return func(o *options) {
+		o.resolver = resolver
+	}
+}
+
+// WithBaseURI sets the URI of the first schema analyzed with [SchemaAnalyzer.Analyze].
+//
+// Relative "$ref" s are resolved against this URI. By default, the base URI is empty.
+func WithBaseURI(uri string) Option {
+	return func(o *options) {
+		o.baseURI = uri
+	}
+}
+
 // WithAnalyzeValidations instructs the [SchemaAnalyzer] to carry out further analysis on validations.
 func WithAnalyzeValidations(enabled bool) Option {
 	return func(o *options) {
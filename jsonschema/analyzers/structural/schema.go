@@ -66,9 +66,54 @@ const (
 	SchemaRelationAllOf
 	SchemaRelationOneOf
 	SchemaRelationAnyOf
-	// ...
+	SchemaRelationRef
+	SchemaRelationNot
+	SchemaRelationPropertyNames
+	SchemaRelationDependentSchema
+	SchemaRelationContains
+	SchemaRelationConditional
+	SchemaRelationOther
 )
 
+func (r SchemaRelation) String() string {
+	switch r {
+	case SchemaRelationProperty:
+		return "property"
+	case SchemaRelationAdditionalProperty:
+		return "additionalProperties"
+	case SchemaRelationPatternProperty:
+		return "patternProperty"
+	case SchemaRelationItems:
+		return "items"
+	case SchemaRelationTupleItems:
+		return "prefixItems"
+	case SchemaRelationTupleAdditionalProperty:
+		return "additionalItems"
+	case SchemaRelationAllOf:
+		return "allOf"
+	case SchemaRelationOneOf:
+		return "oneOf"
+	case SchemaRelationAnyOf:
+		return "anyOf"
+	case SchemaRelationRef:
+		return "$ref"
+	case SchemaRelationNot:
+		return "not"
+	case SchemaRelationPropertyNames:
+		return "propertyNames"
+	case SchemaRelationDependentSchema:
+		return "dependentSchema"
+	case SchemaRelationContains:
+		return "contains"
+	case SchemaRelationConditional:
+		return "conditional"
+	case SchemaRelationOther:
+		return "other"
+	default:
+		return "none"
+	}
+}
+
 type AnalyzedSchemaContext struct {
 	from     *AnalyzedSchema
 	to       *AnalyzedSchema
@@ -109,6 +154,7 @@ type AnalyzedSchema struct {
 	parentAnyOf    *AnalyzedSchema
 	parentOneOf    *AnalyzedSchema
 	parentBaseType *AnalyzedSchema
+	subTypes       []*AnalyzedSchema // subtypes of a base type with a discriminator
 
 	// extra audit
 	refactors []refactoringInfo
@@ -244,15 +290,18 @@ func (a AnalyzedSchema) IsItems() bool {
 	return false // TODO
 }
 
+// IsSubType indicates if the schema is a subtype of a base type with a discriminator, either as a member of an
+// "allOf" with the base type, or as a target of the mapping of the discriminator.
 func (a AnalyzedSchema) IsSubType() bool {
-	return false // TODO
+	return a.parentBaseType != nil
 }
 
 func (a AnalyzedSchema) BaseType() AnalyzedSchema {
 	if !a.IsSubType() {
-		panic("yay")
+		panic("don't call BaseType() when AnalyzedSchema is not a subtype")
 	}
-	return AnalyzedSchema{} // TODO
+
+	return *a.parentBaseType
 }
 
 func (a AnalyzedSchema) PatternPropertyIndex() int {
@@ -352,8 +401,10 @@ func (a AnalyzedSchema) IsEnumOnly() bool {
 }
 
 // IsEnum is a schema that boils down (after reduction) to a const or enum.
+//
+// Reductions are not supported yet: this is a schema with an "enum" or a "const" validation.
 func (a AnalyzedSchema) IsEnum() bool {
-	return false // TODO
+	return a.HasEnum()
 }
 
 func (a AnalyzedSchema) Extensions() Extensions {
@@ -384,8 +435,12 @@ func (a AnalyzedSchema) HasSingleParent() bool {
 	return len(a.parents) == 1
 }
 
+// HasEnum indicates if the schema has an "enum" or a "const" validation.
 func (a AnalyzedSchema) HasEnum() bool {
-	return false // TODO
+	_, hasEnum := a.document.AtKey("enum")
+	_, hasConst := a.document.AtKey("const")
+
+	return hasEnum || hasConst
 }
 
 func (a AnalyzedSchema) HasFormatValidation() bool {
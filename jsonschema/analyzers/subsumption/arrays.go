@@ -0,0 +1,146 @@
+package subsumption
+
+import (
+	"fmt"
+
+	"github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
+)
+
+// maxItems yields the maximum number of items of arrays valid against a node, either from "maxItems" or
+// because some item is rejected.
+func maxItems(n *ast.Node) (int, bool) {
+	limit, ok := keywordInt(n, "maxItems")
+
+	for i := range prefixLen(n) + 1 {
+		if ok && i >= limit {
+			break
+		}
+
+		if item := itemAt(n, i); item != nil && isFalse(item) {
+			return i, true
+		}
+	}
+
+	return limit, ok
+}
+
+func (c *checker) arrays(a, b, orig *ast.Node) []outcome {
+	minA, _ := keywordInt(a, "minItems")
+	maxA, hasMaxA := maxItems(a)
+	if hasMaxA && minA > maxA {
+		// A accepts no array
+		return nil
+	}
+
+	var outcomes []outcome
+
+	if minB, ok := keywordInt(b, "minItems"); ok && minA < minB {
+		outcomes = append(outcomes, c.refute(orig, b, "minItems",
+			fmt.Sprintf("A accepts arrays with less than %d items", minB),
+			c.arrayCandidates(a)...,
+		))
+	}
+
+	if maxB, ok := keywordInt(b, "maxItems"); ok && (!hasMaxA || maxA > maxB) {
+		outcomes = append(outcomes, c.refute(orig, b, "maxItems",
+			fmt.Sprintf("A accepts arrays with more than %d items", maxB),
+			c.arrayCandidates(a, maxB+1)...,
+		))
+	}
+
+	if keywordBool(b, "uniqueItems") && !keywordBool(a, "uniqueItems") && (!hasMaxA || maxA > 1) {
+		outcomes = append(outcomes, c.refute(orig, b, "uniqueItems",
+			"A accepts arrays with duplicate items",
+			c.duplicates(a)...,
+		))
+	}
+
+	// items, position by position
+	n := max(prefixLen(a), prefixLen(b))
+	for i := range n {
+		if hasMaxA && i >= maxA {
+			break
+		}
+
+		outcomes = append(outcomes, c.sub(orTrue(itemAt(a, i)), orTrue(itemAt(b, i))).at(index(i)))
+	}
+
+	if !hasMaxA || n < maxA {
+		outcomes = append(outcomes, c.sub(orTrue(child(a, "items")), orTrue(child(b, "items"))).at(index(n)))
+	}
+
+	if contains := child(b, "contains"); contains != nil {
+		outcomes = append(outcomes, c.contains(a, b, orig, contains, minA, maxA, hasMaxA)...)
+	}
+
+	if hasEdge(b, "unevaluatedItems") {
+		outcomes = append(outcomes, undecide(b, "unevaluatedItems", "unevaluated items are not compared"))
+	}
+
+	return outcomes
+}
+
+func (c *checker) contains(a, b, orig, contains *ast.Node, minA, maxA int, hasMaxA bool) []outcome {
+	var outcomes []outcome
+
+	minB, ok := keywordInt(b, "minContains")
+	if !ok {
+		minB = 1
+	}
+
+	if minB > 0 && !c.containsImplied(a, contains, minA, minB) {
+		keyword := "contains"
+		if ok {
+			keyword = "minContains"
+		}
+
+		outcomes = append(outcomes, c.refute(orig, b, keyword,
+			fmt.Sprintf("A accepts arrays with less than %d items valid against %q", minB, contains.ID()),
+			c.arrayCandidates(a)...,
+		))
+	}
+
+	if maxB, ok := keywordInt(b, "maxContains"); ok && (!hasMaxA || maxA > maxB) {
+		outcomes = append(outcomes, c.refute(orig, b, "maxContains",
+			fmt.Sprintf("A accepts arrays with more than %d items valid against %q", maxB, contains.ID()),
+			c.arrayCandidates(a, maxB+1)...,
+		))
+	}
+
+	return outcomes
+}
+
+// containsImplied tells if arrays valid against a contain at least minB items valid against contains.
+func (c *checker) containsImplied(a, contains *ast.Node, minA, minB int) bool {
+	if containsA := child(a, "contains"); containsA != nil {
+		minContainsA, ok := keywordInt(a, "minContains")
+		if !ok {
+			minContainsA = 1
+		}
+
+		if minContainsA >= minB && c.sub(containsA, contains).verdict == Subsumed {
+			return true
+		}
+	}
+
+	if minA < minB {
+		return false
+	}
+
+	// all items are valid against contains
+	for i := range prefixLen(a) + 1 {
+		if c.sub(orTrue(itemAt(a, i)), contains).verdict != Subsumed {
+			return false
+		}
+	}
+
+	return true
+}
+
+func orTrue(n *ast.Node) *ast.Node {
+	if n == nil {
+		return trueNode
+	}
+
+	return n
+}
@@ -0,0 +1,129 @@
+package subsumption
+
+import (
+	"fmt"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/analyzers/canonical"
+	"github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
+	"github.com/fredbi/core/jsonschema/faker"
+)
+
+// Checker decides if a [jsonschema.Schema] A is subsumed by a [jsonschema.Schema] B,
+// i.e. if every instance valid against A is valid against B.
+type Checker struct {
+	*options
+}
+
+// New subsumption [Checker].
+func New(opts ...Option) *Checker {
+	return &Checker{
+		options: optionsWithDefaults(opts),
+	}
+}
+
+// Check if the schema a is subsumed by the schema b.
+//
+// The check fails with an error wrapping [ErrSubsumption] when a schema cannot be analyzed,
+// e.g. because some "$ref" cannot be resolved.
+func (c *Checker) Check(a, b jsonschema.Schema) (Result, error) {
+	rootA, err := c.canonical(a)
+	if err != nil {
+		return Result{}, err
+	}
+
+	rootB, err := c.canonical(b)
+	if err != nil {
+		return Result{}, err
+	}
+
+	chk := newChecker()
+	o := chk.sub(rootA, rootB)
+
+	result := Result{verdict: o.verdict}
+	for _, u := range o.undecided {
+		result.undecided = append(result.undecided, Undecided{
+			Path:     u.path.String(),
+			Keyword:  u.keyword,
+			Location: u.location,
+			Reason:   u.reason,
+		})
+	}
+
+	var bases []any
+	if c.counterExamples && o.verdict == NotSubsumed {
+		bases = c.bases(chk, a, rootA)
+	}
+
+	for _, ce := range o.counterExamples {
+		counterExample := CounterExample{
+			Path:     ce.path.String(),
+			Keyword:  ce.keyword,
+			Location: ce.location,
+			Reason:   ce.reason,
+			Value:    document(ce.value),
+		}
+
+		for _, base := range bases {
+			instance := graft(base, ce.path, ce.value)
+			if chk.eval.accepts(rootA, instance) == yes && chk.eval.accepts(rootB, instance) == no {
+				counterExample.Instance = document(instance)
+				counterExample.HasInstance = true
+
+				break
+			}
+		}
+
+		result.counterExamples = append(result.counterExamples, counterExample)
+	}
+
+	return result, nil
+}
+
+func (c *Checker) canonical(sch jsonschema.Schema) (*ast.Node, error) {
+	opts := []canonical.Option{
+		canonical.WithBaseURI(c.baseURI),
+		canonical.WithIgnoreAnnotations(true),
+	}
+	if c.resolver != nil {
+		opts = append(opts, canonical.WithResolver(c.resolver))
+	}
+
+	tree, err := canonical.New(opts...).Analyze(sch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, ErrSubsumption)
+	}
+
+	return tree.Root(), nil
+}
+
+// bases yields instances valid against A, in which counter-examples are grafted to build complete instances.
+func (c *Checker) bases(chk *checker, a jsonschema.Schema, rootA *ast.Node) []any {
+	opts := []faker.DataOption{
+		faker.WithDataOnlyValid(true),
+		faker.WithDataSeed(c.seed),
+	}
+	if c.resolver != nil {
+		opts = append(opts, faker.WithDataResolver(c.resolver))
+	}
+
+	var bases []any
+	for generated := range faker.NewDataFaker(a, opts...).GenerateMany(c.attempts) {
+		if generated.Err() != nil {
+			continue
+		}
+
+		data, err := generated.Document().MarshalJSON()
+		if err != nil {
+			continue
+		}
+
+		if v, ok := decode(data); ok {
+			bases = append(bases, v)
+		}
+	}
+
+	bases = append(bases, chk.samples(rootA)...)
+
+	return append(bases, nil)
+}
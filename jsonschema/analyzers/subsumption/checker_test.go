@@ -0,0 +1,260 @@
+package subsumption
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/jsonschema"
+)
+
+func TestCheck(t *testing.T) {
+	t.Run("should prove subsumption", func(t *testing.T) {
+		for _, tc := range []struct {
+			name string
+			a, b string
+		}{
+			{
+				name: "same schema",
+				a:    `{"type": "string", "maxLength": 10}`,
+				b:    `{"maxLength": 10, "type": "string"}`,
+			},
+			{
+				name: "anything is subsumed by true",
+				a:    `{"type": "object"}`,
+				b:    `true`,
+			},
+			{
+				name: "false is subsumed by anything",
+				a:    `false`,
+				b:    `{"type": "string"}`,
+			},
+			{
+				name: "tighter numerical bounds",
+				a:    `{"type": "integer", "minimum": 1, "exclusiveMaximum": 10}`,
+				b:    `{"type": "number", "exclusiveMinimum": 0.5, "maximum": 9}`,
+			},
+			{
+				name: "multiple of a multiple",
+				a:    `{"type": "number", "multipleOf": 4}`,
+				b:    `{"type": "number", "multipleOf": 2}`,
+			},
+			{
+				name: "tighter string length",
+				a:    `{"type": "string", "minLength": 2, "maxLength": 4}`,
+				b:    `{"type": "string", "maxLength": 8}`,
+			},
+			{
+				name: "enum subset",
+				a:    `{"enum": ["a", "b"]}`,
+				b:    `{"enum": ["a", "b", "c"]}`,
+			},
+			{
+				name: "enum within constraints",
+				a:    `{"const": 3}`,
+				b:    `{"type": "integer", "minimum": 1}`,
+			},
+			{
+				name: "more required properties",
+				a: `{
+					"type": "object",
+					"required": ["a", "b"],
+					"properties": {"a": {"type": "string", "maxLength": 3}, "b": {"type": "integer"}}
+				}`,
+				b: `{
+					"type": "object",
+					"required": ["a"],
+					"properties": {"a": {"type": "string"}}
+				}`,
+			},
+			{
+				name: "closed object",
+				a:    `{"type": "object", "properties": {"a": {"type": "string"}}, "additionalProperties": false}`,
+				b:    `{"type": "object", "additionalProperties": {"type": "string"}, "maxProperties": 1}`,
+			},
+			{
+				name: "tighter items",
+				a:    `{"type": "array", "items": {"type": "integer"}, "minItems": 1, "uniqueItems": true}`,
+				b:    `{"type": "array", "items": {"type": "number"}, "contains": {"type": "number"}}`,
+			},
+			{
+				name: "branches of a union",
+				a:    `{"anyOf": [{"type": "string", "maxLength": 2}, {"type": "null"}]}`,
+				b:    `{"type": ["string", "null"]}`,
+			},
+			{
+				name: "union with a subsuming branch",
+				a:    `{"type": "integer", "minimum": 0}`,
+				b:    `{"anyOf": [{"type": "string"}, {"type": "number"}]}`,
+			},
+			{
+				name: "conjunction",
+				a:    `{"allOf": [{"type": "string"}, {"maxLength": 3}]}`,
+				b:    `{"type": "string", "maxLength": 5}`,
+			},
+			{
+				name: "references",
+				a:    `{"$defs": {"short": {"type": "string", "maxLength": 3}}, "$ref": "#/$defs/short"}`,
+				b:    `{"type": "string", "maxLength": 3}`,
+			},
+			{
+				name: "recursive schemas",
+				a: `{
+					"$defs": {"tree": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/$defs/tree"}}}, "required": ["children"]}},
+					"$ref": "#/$defs/tree"
+				}`,
+				b: `{
+					"$defs": {"node": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/$defs/node"}}}}},
+					"$ref": "#/$defs/node"
+				}`,
+			},
+			{
+				name: "negation of a disjoint type",
+				a:    `{"type": "string"}`,
+				b:    `{"not": {"type": "null"}}`,
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				result, err := New().Check(mustSchema(t, tc.a), mustSchema(t, tc.b))
+				require.NoError(t, err)
+
+				assert.Truef(t, result.IsSubsumed(), "expected subsumption, got %v: %v %v",
+					result.Verdict(), slices.Collect(result.CounterExamples()), slices.Collect(result.Undecided()),
+				)
+			})
+		}
+	})
+
+	t.Run("should refute subsumption with a counter-example", func(t *testing.T) {
+		for _, tc := range []struct {
+			name    string
+			a, b    string
+			path    string
+			keyword string
+		}{
+			{
+				name:    "wider type",
+				a:       `{"type": "number"}`,
+				b:       `{"type": "integer"}`,
+				keyword: "type",
+			},
+			{
+				name:    "looser bound",
+				a:       `{"type": "integer", "minimum": 0}`,
+				b:       `{"type": "integer", "minimum": 1}`,
+				keyword: "minimum",
+			},
+			{
+				name:    "enum superset",
+				a:       `{"enum": ["a", "b", "c"]}`,
+				b:       `{"enum": ["a", "b"]}`,
+				keyword: "enum",
+			},
+			{
+				name:    "nullable",
+				a:       `{"type": ["string", "null"]}`,
+				b:       `{"type": "string"}`,
+				keyword: "type",
+			},
+			{
+				name:    "missing required property",
+				a:       `{"type": "object", "properties": {"a": {"type": "string"}}}`,
+				b:       `{"type": "object", "required": ["a"]}`,
+				keyword: "required",
+			},
+			{
+				name:    "nested property",
+				a:       `{"type": "object", "properties": {"pets": {"type": "array", "items": {"type": "object", "properties": {"name": {"type": "string"}}}}}}`,
+				b:       `{"type": "object", "properties": {"pets": {"type": "array", "items": {"type": "object", "properties": {"name": {"type": "string", "maxLength": 10}}}}}}`,
+				path:    "/pets/0/name",
+				keyword: "maxLength",
+			},
+			{
+				name:    "additional properties",
+				a:       `{"type": "object", "properties": {"a": {"type": "string"}}}`,
+				b:       `{"type": "object", "properties": {"a": {"type": "string"}}, "additionalProperties": false}`,
+				keyword: "false",
+			},
+			{
+				name:    "pattern",
+				a:       `{"type": "string"}`,
+				b:       `{"type": "string", "pattern": "^[a-z]+$"}`,
+				keyword: "pattern",
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				a, b := mustSchema(t, tc.a), mustSchema(t, tc.b)
+				result, err := New(WithSeed(1)).Check(a, b)
+				require.NoError(t, err)
+				require.Equalf(t, NotSubsumed, result.Verdict(), "undecided: %v", slices.Collect(result.Undecided()))
+
+				counterExamples := slices.Collect(result.CounterExamples())
+				require.NotEmpty(t, counterExamples)
+				counterExample := counterExamples[0]
+				assert.Equal(t, tc.keyword, counterExample.Keyword)
+				if tc.path != "" {
+					assert.Equal(t, tc.path, counterExample.Path)
+				}
+				assert.NotEmpty(t, counterExample.Reason)
+				assert.NotEmpty(t, counterExample.Location)
+
+				require.True(t, counterExample.HasInstance)
+				instance, err := counterExample.Instance.MarshalJSON()
+				require.NoError(t, err)
+				t.Logf("counter-example at %q: %s", counterExample.Path, instance)
+			})
+		}
+	})
+
+	t.Run("should not decide on unsupported constructs", func(t *testing.T) {
+		for _, tc := range []struct {
+			name string
+			a, b string
+		}{
+			{
+				name: "different patterns",
+				a:    `{"type": "string", "pattern": "^a+$"}`,
+				b:    `{"type": "string", "pattern": "^a*$"}`,
+			},
+			{
+				name: "different formats",
+				a:    `{"type": "string"}`,
+				b:    `{"type": "string", "format": "date"}`,
+			},
+			{
+				name: "negation of a composition",
+				a:    `{"type": "integer"}`,
+				b:    `{"not": {"anyOf": [{"type": "integer", "minimum": 5}, {"type": "integer", "maximum": 0}]}}`,
+			},
+			{
+				name: "unevaluated properties",
+				a:    `{"type": "object"}`,
+				b:    `{"type": "object", "unevaluatedProperties": false}`,
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				result, err := New().Check(mustSchema(t, tc.a), mustSchema(t, tc.b))
+				require.NoError(t, err)
+
+				assert.Equalf(t, Unknown, result.Verdict(), "counter-examples: %v", slices.Collect(result.CounterExamples()))
+				assert.NotEmpty(t, slices.Collect(result.Undecided()))
+			})
+		}
+	})
+
+	t.Run("should fail on unresolved references", func(t *testing.T) {
+		_, err := New().Check(mustSchema(t, `{"$ref": "#/$defs/missing"}`), mustSchema(t, `true`))
+		require.ErrorIs(t, err, ErrSubsumption)
+	})
+}
+
+func mustSchema(t *testing.T, input string) jsonschema.Schema {
+	t.Helper()
+
+	s := jsonschema.Make()
+	require.NoError(t, s.UnmarshalJSON([]byte(input)))
+
+	return s
+}
@@ -0,0 +1,564 @@
+package subsumption
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
+)
+
+type counterExample struct {
+	path     path
+	keyword  string
+	location string
+	reason   string
+	value    any
+}
+
+type undecided struct {
+	path     path
+	keyword  string
+	location string
+	reason   string
+}
+
+// outcome of the comparison of two nodes. Paths are relative to the compared nodes.
+type outcome struct {
+	verdict         Verdict
+	counterExamples []counterExample
+	undecided       []undecided
+}
+
+// at prefixes the paths of an outcome with a step.
+func (o outcome) at(s step) outcome {
+	result := outcome{verdict: o.verdict}
+	for _, ce := range o.counterExamples {
+		ce.path = append(path{s}, ce.path...)
+		result.counterExamples = append(result.counterExamples, ce)
+	}
+
+	for _, u := range o.undecided {
+		u.path = append(path{s}, u.path...)
+		result.undecided = append(result.undecided, u)
+	}
+
+	return result
+}
+
+func subsumed() outcome {
+	return outcome{verdict: Subsumed}
+}
+
+func undecide(b *ast.Node, keyword, reason string) outcome {
+	return outcome{
+		verdict:   Unknown,
+		undecided: []undecided{{keyword: keyword, location: b.ID(), reason: reason}},
+	}
+}
+
+// both combines the outcomes of constraints which must all hold.
+func both(outcomes ...outcome) outcome {
+	result := subsumed()
+
+	for _, o := range outcomes {
+		switch o.verdict {
+		case NotSubsumed:
+			if result.verdict != NotSubsumed {
+				result = outcome{verdict: NotSubsumed}
+			}
+			result.counterExamples = append(result.counterExamples, o.counterExamples...)
+		case Unknown:
+			if result.verdict == Subsumed {
+				result.verdict = Unknown
+			}
+
+			if result.verdict == Unknown {
+				result.undecided = append(result.undecided, o.undecided...)
+			}
+		}
+	}
+
+	return result
+}
+
+type pair struct {
+	a, b *ast.Node
+}
+
+// checker compares canonical nodes.
+type checker struct {
+	eval  *evaluator
+	stack map[pair]struct{}
+	memo  map[pair]outcome
+	hits  int
+
+	sampled map[sampleKey][]any
+	depth   int
+}
+
+func newChecker() *checker {
+	return &checker{
+		eval:  newEvaluator(),
+		stack: make(map[pair]struct{}),
+		memo:  make(map[pair]outcome),
+
+		sampled: make(map[sampleKey][]any),
+	}
+}
+
+// sub decides if every value valid against a is valid against b.
+//
+// Recursive schemas are compared coinductively: a pair of nodes which is being compared is assumed to be subsumed.
+func (c *checker) sub(a, b *ast.Node) outcome {
+	ra, rb := a.Resolved(), b.Resolved()
+	if ra == nil || rb == nil {
+		return undecide(b, "$ref", "references form a cycle")
+	}
+	a, b = ra, rb
+
+	switch {
+	case b.Kind() == ast.KindTrue, a.Kind() == ast.KindFalse:
+		return subsumed()
+	case !a.Hash().IsZero() && a.Hash() == b.Hash():
+		return subsumed()
+	}
+
+	key := pair{a: a, b: b}
+	if o, ok := c.memo[key]; ok {
+		return o
+	}
+
+	if _, onStack := c.stack[key]; onStack {
+		c.hits++
+
+		return subsumed()
+	}
+
+	c.stack[key] = struct{}{}
+	hits := c.hits
+	o := c.decompose(a, b)
+	delete(c.stack, key)
+
+	if o.verdict != Subsumed || c.hits == hits {
+		// a subsumption that relies on an assumption is not memoized
+		c.memo[key] = o
+	}
+
+	return o
+}
+
+// decompose the compositions of a, so that a is reduced to a single node without compositions.
+func (c *checker) decompose(a, b *ast.Node) outcome {
+	if b.Kind() == ast.KindFalse {
+		return c.refute(a, b, "false", "B rejects any value")
+	}
+
+	if a.Kind() == ast.KindTrue {
+		return c.refute(a, b, "true", "A accepts any value", anyValues()...)
+	}
+
+	// A is a union: every branch must be subsumed
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		branches := nodes(a, keyword)
+		if len(branches) == 0 {
+			continue
+		}
+
+		base := without(a, keyword)
+		outcomes := make([]outcome, 0, len(branches))
+		for _, branch := range branches {
+			// with "oneOf", a value valid against a branch may be invalid against A
+			o := c.sub(conj(a.ID(), base, branch), b)
+			outcomes = append(outcomes, c.confirm(a, o, keyword == "anyOf"))
+		}
+
+		return both(outcomes...)
+	}
+
+	atoms, satisfiable := flatten(a)
+	if !satisfiable {
+		return subsumed()
+	}
+
+	// A is a conjunction with a union: distribute the conjunction over the union
+	for i, atom := range atoms {
+		if !hasEdge(atom, "anyOf", "oneOf") {
+			continue
+		}
+
+		children := slices.Collect(atom.Children())
+		for j, other := range atoms {
+			if j != i {
+				children = append(children, ast.Edge{Keyword: "allOf", Node: other})
+			}
+		}
+
+		return c.sub(ast.NewSchema(atom.ID(), atom.Type(), slices.Collect(atom.Keywords()), children), b)
+	}
+
+	merged, lossy, satisfiable := c.merge(a.ID(), atoms)
+	switch {
+	case !satisfiable:
+		return subsumed()
+	case isTrue(merged):
+		return c.refute(a, b, "true", "A accepts any value", anyValues()...)
+	}
+
+	o := c.versus(merged, b, a)
+
+	return c.confirm(a, o, !lossy)
+}
+
+// versus decomposes the compositions of b.
+func (c *checker) versus(a, b, orig *ast.Node) outcome {
+	if values, ok := finite(a); ok {
+		return c.enumerate(b, orig, values)
+	}
+
+	var outcomes []outcome
+	for _, member := range nodes(b, "allOf") {
+		outcomes = append(outcomes, c.sub(a, member))
+	}
+
+	if branches := nodes(b, "anyOf"); len(branches) > 0 {
+		outcomes = append(outcomes, c.anyOf(a, b, branches, orig))
+	}
+
+	if branches := nodes(b, "oneOf"); len(branches) > 0 {
+		outcomes = append(outcomes, c.oneOf(a, b, branches, orig))
+	}
+
+	if negated := child(b, "not"); negated != nil {
+		outcomes = append(outcomes, c.not(a, b, negated, orig))
+	}
+
+	if condition := child(b, "if"); condition != nil {
+		outcomes = append(outcomes, c.conditional(a, b, condition))
+	}
+
+	outcomes = append(outcomes, c.compare(a, without(b, compositionKeywords...), orig))
+
+	return both(outcomes...)
+}
+
+// finite yields all the values valid against a node, when there is a small number of them.
+func finite(n *ast.Node) ([]any, bool) {
+	if values, ok := keywordValues(n, "enum"); ok {
+		return values, true
+	}
+
+	switch n.Type() {
+	case "null":
+		return []any{nil}, true
+	case "boolean":
+		return []any{true, false}, true
+	default:
+		return nil, false
+	}
+}
+
+// enumerate evaluates all the values possibly valid against A.
+func (c *checker) enumerate(b, orig *ast.Node, values []any) outcome {
+	outcomes := make([]outcome, 0, len(values))
+
+	for _, value := range values {
+		inA := c.eval.accepts(orig, value)
+		if inA == no {
+			continue
+		}
+
+		switch c.eval.accepts(b, value) {
+		case yes:
+			continue
+		case no:
+			if inA == yes {
+				outcomes = append(outcomes, outcome{
+					verdict: NotSubsumed,
+					counterExamples: []counterExample{{
+						keyword:  c.violated(b, value),
+						location: b.ID(),
+						reason:   fmt.Sprintf("A accepts %s, which is invalid against B", encode(value)),
+						value:    value,
+					}},
+				})
+
+				continue
+			}
+		}
+
+		outcomes = append(outcomes, undecide(b, "enum", fmt.Sprintf("cannot evaluate %s", encode(value))))
+	}
+
+	return both(outcomes...)
+}
+
+func (c *checker) anyOf(a, b *ast.Node, branches []*ast.Node, orig *ast.Node) outcome {
+	var outcomes []outcome
+	for _, branch := range branches {
+		if typesDisjoint(a, branch) {
+			continue
+		}
+
+		o := c.sub(a, branch)
+		if o.verdict == Subsumed {
+			return subsumed()
+		}
+		outcomes = append(outcomes, o)
+	}
+
+	switch len(outcomes) {
+	case 0:
+		return c.refute(orig, b, "anyOf", "no branch of B accepts the types accepted by A")
+	case 1:
+		// other branches reject all values of A
+		return c.confirmB(orig, b, outcomes[0])
+	}
+
+	for _, o := range outcomes {
+		for _, ce := range o.counterExamples {
+			if len(ce.path) == 0 && c.eval.accepts(orig, ce.value) == yes && c.eval.accepts(b, ce.value) == no {
+				ce.keyword, ce.location, ce.reason = "anyOf", b.ID(), "A accepts values rejected by all branches of B"
+
+				return outcome{verdict: NotSubsumed, counterExamples: []counterExample{ce}}
+			}
+		}
+	}
+
+	return undecide(b, "anyOf", "A is not subsumed by any single branch of B")
+}
+
+func (c *checker) oneOf(a, b *ast.Node, branches []*ast.Node, orig *ast.Node) outcome {
+	var compatible []*ast.Node
+	for _, branch := range branches {
+		if !typesDisjoint(a, branch) {
+			compatible = append(compatible, branch)
+		}
+	}
+
+	switch len(compatible) {
+	case 0:
+		return c.refute(orig, b, "oneOf", "no branch of B accepts the types accepted by A")
+	case 1:
+		return c.confirmB(orig, b, c.sub(a, compatible[0]))
+	}
+
+	disjoint := true
+	for i, x := range compatible {
+		for _, y := range compatible[i+1:] {
+			disjoint = disjoint && typesDisjoint(x, y)
+		}
+	}
+
+	if disjoint {
+		return c.anyOf(a, b, compatible, orig)
+	}
+
+	return c.refute(orig, b, "oneOf", "the branches of B overlap")
+}
+
+func (c *checker) not(a, b, negated, orig *ast.Node) outcome {
+	if typesDisjoint(a, negated) {
+		return subsumed()
+	}
+
+	if n := negated.Resolved(); n != nil && hasCompositions(n) {
+		return undecide(b, "not", "negation of a composition")
+	}
+
+	if c.sub(a, negated).verdict == Subsumed {
+		return c.refute(orig, b, "not", "A is included in the negated schema")
+	}
+
+	return undecide(b, "not", "cannot decide if A and the negated schema are disjoint")
+}
+
+func (c *checker) conditional(a, b, condition *ast.Node) outcome {
+	then := child(b, "then")
+	if then == nil {
+		then = trueNode
+	}
+
+	otherwise := child(b, "else")
+	if otherwise == nil {
+		otherwise = trueNode
+	}
+
+	if c.sub(a, condition).verdict == Subsumed {
+		return c.sub(a, then)
+	}
+
+	if typesDisjoint(a, condition) {
+		return c.sub(a, otherwise)
+	}
+
+	if c.sub(a, then).verdict == Subsumed && c.sub(a, otherwise).verdict == Subsumed {
+		return subsumed()
+	}
+
+	return undecide(b, "if", "A overlaps the condition of B")
+}
+
+// compare the keywords of a node without compositions to the keywords of b.
+func (c *checker) compare(a, b, orig *ast.Node) outcome {
+	b = b.Resolved()
+	if b == nil || isTrue(b) {
+		return subsumed()
+	}
+
+	var outcomes []outcome
+
+	ta, tb := a.Type(), b.Type()
+	if !typeWithin(ta, tb) {
+		outcomes = append(outcomes, c.refute(orig, b, "type",
+			fmt.Sprintf("A accepts values which are not of type %q", tb), typeCandidates(tb)...,
+		))
+	}
+
+	if _, ok := b.Keyword("enum"); ok {
+		outcomes = append(outcomes, c.refute(orig, b, "enum", "A accepts values which are not enumerated by B"))
+	}
+
+	for k := range b.Keywords() {
+		_, isTyped := typedKeywords[k.Name]
+		switch {
+		case k.Dynamic:
+			if ka, ok := a.Keyword(k.Name); !ok || !ka.Dynamic || string(ka.Value) != string(k.Value) {
+				outcomes = append(outcomes, undecide(b, k.Name, "the value of the keyword is a $data reference"))
+			}
+		case isTyped, k.Name == "enum", isAnnotation(k.Name):
+		default:
+			if ka, ok := a.Keyword(k.Name); !ok || string(ka.Value) != string(k.Value) {
+				outcomes = append(outcomes, undecide(b, k.Name, "unsupported keyword"))
+			}
+		}
+	}
+
+	if ta == "" || ta == "number" || ta == "integer" {
+		outcomes = append(outcomes, c.numbers(a, b, orig)...)
+	}
+
+	if ta == "" || ta == "string" {
+		outcomes = append(outcomes, c.strings(a, b, orig)...)
+	}
+
+	if ta == "" || ta == "array" {
+		outcomes = append(outcomes, c.arrays(a, b, orig)...)
+	}
+
+	if ta == "" || ta == "object" {
+		outcomes = append(outcomes, c.objects(a, b, orig)...)
+	}
+
+	return both(outcomes...)
+}
+
+// confirm keeps the counter-examples valid against A, when an outcome was obtained on an over-approximation of A.
+//
+// Counter-examples for nested values cannot be confirmed: they are reported as undecided.
+func (c *checker) confirm(a *ast.Node, o outcome, exact bool) outcome {
+	if o.verdict != NotSubsumed || exact {
+		return o
+	}
+
+	return filter(o, func(ce counterExample) bool {
+		return len(ce.path) == 0 && c.eval.accepts(a, ce.value) == yes
+	})
+}
+
+// confirmB keeps the counter-examples invalid against B, when an outcome was obtained on a branch of B.
+func (c *checker) confirmB(a, b *ast.Node, o outcome) outcome {
+	if o.verdict != NotSubsumed {
+		return o
+	}
+
+	return filter(o, func(ce counterExample) bool {
+		return len(ce.path) > 0 || c.eval.accepts(a, ce.value) == yes && c.eval.accepts(b, ce.value) == no
+	})
+}
+
+// inexact reports counter-examples as undecided, e.g. when no instance may be built from them.
+func inexact(o outcome) outcome {
+	return filter(o, func(counterExample) bool { return false })
+}
+
+func filter(o outcome, keep func(counterExample) bool) outcome {
+	if o.verdict != NotSubsumed {
+		return o
+	}
+
+	result := outcome{verdict: NotSubsumed}
+	var dropped []undecided
+	for _, ce := range o.counterExamples {
+		if keep(ce) {
+			result.counterExamples = append(result.counterExamples, ce)
+
+			continue
+		}
+
+		dropped = append(dropped, undecided{
+			path:     ce.path,
+			keyword:  ce.keyword,
+			location: ce.location,
+			reason:   ce.reason + ", but the counter-example could not be confirmed",
+		})
+	}
+
+	if len(result.counterExamples) > 0 {
+		return result
+	}
+
+	return outcome{verdict: Unknown, undecided: dropped}
+}
+
+// refute looks for a value valid against a and invalid against b, among some candidates and samples of a.
+func (c *checker) refute(a, b *ast.Node, keyword, reason string, candidates ...any) outcome {
+	for _, v := range append(candidates, c.samples(a)...) {
+		if c.eval.accepts(a, v) == yes && c.eval.accepts(b, v) == no {
+			return outcome{
+				verdict: NotSubsumed,
+				counterExamples: []counterExample{{
+					keyword:  keyword,
+					location: b.ID(),
+					reason:   reason,
+					value:    v,
+				}},
+			}
+		}
+	}
+
+	return undecide(b, keyword, reason+", but no counter-example was found")
+}
+
+// violated yields the first keyword of b which rejects a value.
+func (c *checker) violated(b *ast.Node, v any) string {
+	b = b.Resolved()
+	if b == nil {
+		return ""
+	}
+
+	if b.Kind() == ast.KindFalse {
+		return "false"
+	}
+
+	if t := b.Type(); t != "" && !hasType(v, t) {
+		return "type"
+	}
+
+	for k := range b.Keywords() {
+		if c.eval.keyword(b, k, v) == no {
+			return k.Name
+		}
+	}
+
+	for edge := range b.Children() {
+		if c.eval.accepts(without(b, compositionKeywords...), v) == no {
+			break
+		}
+
+		if slices.Contains(compositionKeywords, edge.Keyword) && edge.Keyword != "not" && c.eval.accepts(edge.Node, v) == no {
+			return edge.Keyword
+		}
+	}
+
+	return "not"
+}
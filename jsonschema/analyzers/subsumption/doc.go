@@ -0,0 +1,31 @@
+// Package subsumption decides if a JSON schema A is subsumed by a JSON schema B, i.e. if every instance valid
+// against A is valid against B.
+//
+// This is the question to answer when a schema evolves: a new version of a request schema is backward-compatible
+// if it subsumes the previous one, and a new version of a response schema is backward-compatible if it is subsumed
+// by the previous one.
+//
+// Schemas are first normalized into their canonical AST (see package
+// [github.com/fredbi/core/jsonschema/analyzers/canonical]), then compared keyword by keyword.
+// Recursive schemas are supported.
+//
+// The [Checker] is sound: it never reports a wrong verdict, but it may be unable to decide.
+//
+//   - [Subsumed] is reported only when it is proved
+//   - [NotSubsumed] is always backed by a [CounterExample]: a value valid against the subschema of A and invalid
+//     against the subschema of B found at the same path. When possible, a complete instance is generated with the
+//     data faker (see package [github.com/fredbi/core/jsonschema/faker]) and verified.
+//   - [Unknown] is reported with the constructs that could not be decided (see [Result.Undecided])
+//
+// A counter-example found in a nested value assumes that the enclosing values may be built: the verdict is
+// reliable when the complete instance is available (see [CounterExample.HasInstance]).
+//
+// The following constructs generally result in an [Unknown] verdict, unless a counter-example is found:
+//
+//   - different "pattern" s or "patternProperties" (regular expressions are not compared), and patterns not supported
+//     by the [regexp] package
+//   - different "format" s
+//   - "not" over compositions, "oneOf" with overlapping branches and "if" conditions which overlap with A
+//   - "unevaluatedProperties" and "unevaluatedItems"
+//   - keywords which value is a "$data" reference
+package subsumption
@@ -0,0 +1,12 @@
+package subsumption
+
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrSubsumption is raised when schemas cannot be compared, e.g. because a "$ref" cannot be resolved.
+	ErrSubsumption Error = "subsumption error"
+)
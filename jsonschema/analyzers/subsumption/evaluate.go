@@ -0,0 +1,464 @@
+package subsumption
+
+import (
+	"math/big"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
+)
+
+const maxEvaluationDepth = 64
+
+// evaluator evaluates JSON values against canonical nodes.
+//
+// The evaluation is three-valued: constructs which cannot be evaluated, such as formats, "$data" references,
+// "unevaluated*" keywords or patterns that are not supported by the [regexp] package, yield maybe.
+type evaluator struct {
+	patterns map[string]*regexp.Regexp // nil for unsupported patterns
+}
+
+func newEvaluator() *evaluator {
+	return &evaluator{patterns: make(map[string]*regexp.Regexp)}
+}
+
+func (e *evaluator) regexp(pattern string) *regexp.Regexp {
+	re, ok := e.patterns[pattern]
+	if ok {
+		return re
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil
+	}
+	e.patterns[pattern] = re
+
+	return re
+}
+
+// matches tells if a string matches a pattern.
+func (e *evaluator) matches(pattern, s string) truth {
+	re := e.regexp(pattern)
+	if re == nil {
+		return maybe
+	}
+
+	return truthOf(re.MatchString(s))
+}
+
+// accepts tells if a value is valid against a node.
+func (e *evaluator) accepts(n *ast.Node, v any) truth {
+	return e.evaluate(n, v, 0)
+}
+
+func (e *evaluator) evaluate(n *ast.Node, v any, depth int) truth {
+	if depth > maxEvaluationDepth {
+		return maybe
+	}
+
+	n = n.Resolved()
+	if n == nil {
+		return maybe
+	}
+
+	switch n.Kind() {
+	case ast.KindTrue:
+		return yes
+	case ast.KindFalse:
+		return no
+	}
+
+	if t := n.Type(); t != "" && !hasType(v, t) {
+		return no
+	}
+
+	result := yes
+	for k := range n.Keywords() {
+		result = result.and(e.keyword(n, k, v))
+		if result == no {
+			return no
+		}
+	}
+
+	return result.and(e.subschemas(n, v, depth+1))
+}
+
+func (e *evaluator) keyword(n *ast.Node, k ast.Keyword, v any) truth {
+	if k.Dynamic {
+		return maybe
+	}
+
+	switch k.Name {
+	case "enum":
+		values, ok := keywordValues(n, k.Name)
+		if !ok {
+			return maybe
+		}
+
+		for _, value := range values {
+			if equal(value, v) {
+				return yes
+			}
+		}
+
+		return no
+	case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf":
+		return e.number(n, k.Name, v)
+	case "minLength", "maxLength", "pattern", "format":
+		s, isString := v.(string)
+		if !isString {
+			return yes
+		}
+
+		return e.string(n, k.Name, s)
+	case "minItems", "maxItems", "uniqueItems", "minContains", "maxContains":
+		arr, isArray := v.([]any)
+		if !isArray {
+			return yes
+		}
+
+		return e.array(n, k.Name, arr)
+	case "minProperties", "maxProperties", "required", "dependentRequired":
+		obj, isObject := v.(map[string]any)
+		if !isObject {
+			return yes
+		}
+
+		return e.object(n, k.Name, obj)
+	default:
+		if isAnnotation(k.Name) {
+			return yes
+		}
+
+		return maybe
+	}
+}
+
+func (e *evaluator) number(n *ast.Node, name string, v any) truth {
+	x, isNumber := ratOf(v)
+	if !isNumber {
+		return yes
+	}
+
+	limit, ok := keywordRat(n, name)
+	if !ok {
+		return maybe
+	}
+
+	switch name {
+	case "minimum":
+		return truthOf(x.Cmp(limit) >= 0)
+	case "maximum":
+		return truthOf(x.Cmp(limit) <= 0)
+	case "exclusiveMinimum":
+		return truthOf(x.Cmp(limit) > 0)
+	case "exclusiveMaximum":
+		return truthOf(x.Cmp(limit) < 0)
+	default: // multipleOf
+		if limit.Sign() == 0 {
+			return maybe
+		}
+
+		return truthOf(new(big.Rat).Quo(x, limit).IsInt())
+	}
+}
+
+func (e *evaluator) string(n *ast.Node, name string, s string) truth {
+	switch name {
+	case "minLength":
+		limit, ok := keywordInt(n, name)
+
+		return truthOf(ok && utf8.RuneCountInString(s) >= limit)
+	case "maxLength":
+		limit, ok := keywordInt(n, name)
+
+		return truthOf(ok && utf8.RuneCountInString(s) <= limit)
+	case "pattern":
+		pattern, ok := keywordString(n, name)
+		if !ok {
+			return maybe
+		}
+
+		return e.matches(pattern, s)
+	default: // format
+		return maybe
+	}
+}
+
+func (e *evaluator) array(n *ast.Node, name string, arr []any) truth {
+	switch name {
+	case "minItems":
+		limit, ok := keywordInt(n, name)
+
+		return truthOf(ok && len(arr) >= limit)
+	case "maxItems":
+		limit, ok := keywordInt(n, name)
+
+		return truthOf(ok && len(arr) <= limit)
+	case "uniqueItems":
+		if !keywordBool(n, name) {
+			return yes
+		}
+
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if equal(arr[i], arr[j]) {
+					return no
+				}
+			}
+		}
+
+		return yes
+	default: // minContains, maxContains are evaluated with "contains"
+		return yes
+	}
+}
+
+func (e *evaluator) object(n *ast.Node, name string, obj map[string]any) truth {
+	switch name {
+	case "minProperties":
+		limit, ok := keywordInt(n, name)
+
+		return truthOf(ok && len(obj) >= limit)
+	case "maxProperties":
+		limit, ok := keywordInt(n, name)
+
+		return truthOf(ok && len(obj) <= limit)
+	case "required":
+		for _, required := range keywordStrings(n, name) {
+			if _, found := obj[required]; !found {
+				return no
+			}
+		}
+
+		return yes
+	default: // dependentRequired
+		for key, value := range keywordMap(n, name) {
+			if _, found := obj[key]; !found {
+				continue
+			}
+
+			names, _ := value.([]any)
+			for _, required := range names {
+				s, _ := required.(string)
+				if _, found := obj[s]; !found {
+					return no
+				}
+			}
+		}
+
+		return yes
+	}
+}
+
+func (e *evaluator) subschemas(n *ast.Node, v any, depth int) truth {
+	result := yes
+	anyOf, hasAnyOf := no, false
+	oneOf, hasOneOf := 0, false
+	oneOfMaybe := false
+
+	for edge := range n.Children() {
+		switch edge.Keyword {
+		case "allOf":
+			result = result.and(e.evaluate(edge.Node, v, depth))
+		case "anyOf":
+			hasAnyOf = true
+			anyOf = anyOf.or(e.evaluate(edge.Node, v, depth))
+		case "oneOf":
+			hasOneOf = true
+			switch e.evaluate(edge.Node, v, depth) {
+			case yes:
+				oneOf++
+			case maybe:
+				oneOfMaybe = true
+			}
+		case "not":
+			result = result.and(e.evaluate(edge.Node, v, depth).not())
+		case "if":
+			result = result.and(e.conditional(n, edge.Node, v, depth))
+		case "properties", "patternProperties", "additionalProperties", "propertyNames", "dependentSchemas":
+			// evaluated together
+		case "prefixItems", "items", "contains":
+			// evaluated together
+		case "then", "else", "contentSchema":
+			// evaluated with "if", or annotations
+		default:
+			result = result.and(maybe)
+		}
+
+		if result == no {
+			return no
+		}
+	}
+
+	if hasAnyOf {
+		result = result.and(anyOf)
+	}
+
+	if hasOneOf {
+		switch {
+		case oneOf > 1:
+			result = result.and(no)
+		case oneOfMaybe:
+			result = result.and(maybe)
+		default:
+			result = result.and(truthOf(oneOf == 1))
+		}
+	}
+
+	if result == no {
+		return no
+	}
+
+	switch x := v.(type) {
+	case map[string]any:
+		return result.and(e.properties(n, x, depth))
+	case []any:
+		return result.and(e.items(n, x, depth))
+	default:
+		return result
+	}
+}
+
+func (e *evaluator) conditional(n, condition *ast.Node, v any, depth int) truth {
+	branch := "else"
+	switch e.evaluate(condition, v, depth) {
+	case yes:
+		branch = "then"
+	case maybe:
+		return maybe
+	}
+
+	if target := child(n, branch); target != nil {
+		return e.evaluate(target, v, depth)
+	}
+
+	return yes
+}
+
+func (e *evaluator) properties(n *ast.Node, obj map[string]any, depth int) truth {
+	result := yes
+
+	for key, value := range obj {
+		if names := child(n, "propertyNames"); names != nil {
+			result = result.and(e.evaluate(names, key, depth))
+		}
+
+		if dependent := keyed(n, "dependentSchemas", key); dependent != nil {
+			result = result.and(e.evaluate(dependent, obj, depth))
+		}
+
+		schemas, known := e.propertySchemas(n, key)
+		result = result.and(known)
+		for _, schema := range schemas {
+			result = result.and(e.evaluate(schema, value, depth))
+		}
+
+		if result == no {
+			return no
+		}
+	}
+
+	if hasEdge(n, "unevaluatedProperties") {
+		result = result.and(maybe)
+	}
+
+	return result
+}
+
+// propertySchemas yields the schemas that apply to a property: either the matching "properties" and
+// "patternProperties", or "additionalProperties".
+//
+// The returned truth is maybe when some pattern is not supported.
+func (e *evaluator) propertySchemas(n *ast.Node, name string) ([]*ast.Node, truth) {
+	var schemas []*ast.Node
+	known := yes
+	matched := false
+
+	for edge := range n.Children() {
+		switch edge.Keyword {
+		case "properties":
+			if edge.Key == name {
+				schemas = append(schemas, edge.Node)
+				matched = true
+			}
+		case "patternProperties":
+			switch e.matches(edge.Key, name) {
+			case yes:
+				schemas = append(schemas, edge.Node)
+				matched = true
+			case maybe:
+				known = maybe
+			}
+		}
+	}
+
+	if !matched {
+		if additional := child(n, "additionalProperties"); additional != nil {
+			schemas = append(schemas, additional)
+		}
+	}
+
+	return schemas, known
+}
+
+func (e *evaluator) items(n *ast.Node, arr []any, depth int) truth {
+	result := yes
+
+	for i, elem := range arr {
+		if item := itemAt(n, i); item != nil {
+			result = result.and(e.evaluate(item, elem, depth))
+		}
+
+		if result == no {
+			return no
+		}
+	}
+
+	if contains := child(n, "contains"); contains != nil {
+		count, unknown := 0, false
+		for _, elem := range arr {
+			switch e.evaluate(contains, elem, depth) {
+			case yes:
+				count++
+			case maybe:
+				unknown = true
+			}
+		}
+
+		minContains, ok := keywordInt(n, "minContains")
+		if !ok {
+			minContains = 1
+		}
+		maxContains, hasMax := keywordInt(n, "maxContains")
+
+		switch {
+		case unknown:
+			result = result.and(maybe)
+		case count < minContains, hasMax && count > maxContains:
+			return no
+		}
+	}
+
+	if hasEdge(n, "unevaluatedItems") {
+		result = result.and(maybe)
+	}
+
+	return result
+}
+
+//nolint:gochecknoglobals // keywords which never invalidate a value
+var annotations = map[string]struct{}{
+	"title": {}, "description": {}, "default": {}, "examples": {}, "example": {}, "deprecated": {},
+	"readOnly": {}, "writeOnly": {}, "contentMediaType": {}, "contentEncoding": {}, "discriminator": {},
+	"xml": {}, "externalDocs": {}, "$comment": {},
+}
+
+func isAnnotation(name string) bool {
+	if _, ok := annotations[name]; ok {
+		return true
+	}
+
+	return len(name) > 2 && name[0] == 'x' && name[1] == '-'
+}
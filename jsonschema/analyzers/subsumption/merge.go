@@ -0,0 +1,378 @@
+package subsumption
+
+import (
+	"bytes"
+	"math/big"
+	"slices"
+
+	"github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
+)
+
+// flatten the conjunction of a node into atoms, i.e. nodes without "allOf".
+//
+// It tells if the conjunction is unsatisfiable, e.g. because it has a false member.
+func flatten(n *ast.Node) ([]*ast.Node, bool) {
+	var atoms []*ast.Node
+	visited := make(map[*ast.Node]struct{})
+
+	var walk func(*ast.Node) bool
+	walk = func(n *ast.Node) bool {
+		n = n.Resolved()
+		if n == nil {
+			return true
+		}
+
+		if _, seen := visited[n]; seen {
+			return true
+		}
+		visited[n] = struct{}{}
+
+		switch n.Kind() {
+		case ast.KindTrue:
+			return true
+		case ast.KindFalse:
+			return false
+		}
+
+		if atom := without(n, "allOf"); !isTrue(atom) {
+			atoms = append(atoms, atom)
+		}
+
+		for _, member := range nodes(n, "allOf") {
+			if !walk(member) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if !walk(n) {
+		return nil, false
+	}
+
+	return atoms, true
+}
+
+// merge atoms into a single node, without compositions.
+//
+// The merged node may be lossy, i.e. accept more values than the conjunction of the atoms, when some constraints
+// cannot be merged: "not", "if", conflicting patterns or formats, etc. It tells if the conjunction is unsatisfiable.
+func (c *checker) merge(id string, atoms []*ast.Node) (merged *ast.Node, lossy bool, satisfiable bool) {
+	if len(atoms) == 0 {
+		return trueNode, false, true
+	}
+
+	if len(atoms) == 1 && !hasCompositions(atoms[0]) && !hasEdge(atoms[0], "unevaluatedProperties", "unevaluatedItems") {
+		return atoms[0], false, true
+	}
+
+	typ := ""
+	for _, atom := range atoms {
+		t := atom.Type()
+		switch {
+		case t == "" || t == typ:
+		case typ == "" || typeWithin(t, typ):
+			typ = t
+		case typeWithin(typ, t):
+		default:
+			return nil, false, false
+		}
+	}
+
+	keywords := make(map[string]ast.Keyword)
+	for _, atom := range atoms {
+		for k := range atom.Keywords() {
+			existing, found := keywords[k.Name]
+			if !found {
+				keywords[k.Name] = k
+
+				continue
+			}
+
+			mergedKeyword, exact, ok := mergeKeyword(existing, k)
+			if !ok {
+				return nil, false, false
+			}
+			lossy = lossy || !exact
+			keywords[k.Name] = mergedKeyword
+		}
+	}
+
+	var children []ast.Edge
+	children, lossy = c.mergeObjects(id, atoms, children, lossy)
+	children, lossy = mergeArrays(id, atoms, children, lossy)
+
+	for _, atom := range atoms {
+		for edge := range atom.Children() {
+			switch edge.Keyword {
+			case "properties", "patternProperties", "additionalProperties", "propertyNames", "dependentSchemas",
+				"prefixItems", "items", "contains", "contentSchema":
+			default:
+				// compositions and unknown subschemas only restrict values further
+				lossy = true
+			}
+		}
+	}
+
+	kws := make([]ast.Keyword, 0, len(keywords))
+	for _, k := range keywords {
+		if appliesTo(k.Name, typ) {
+			kws = append(kws, k)
+		}
+	}
+
+	return ast.NewSchema(id, typ, kws, children), lossy, true
+}
+
+func (c *checker) mergeObjects(id string, atoms []*ast.Node, children []ast.Edge, lossy bool) ([]ast.Edge, bool) {
+	var names []string
+	for _, atom := range atoms {
+		for _, name := range propertyNames(atom) {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	for _, name := range names {
+		var members []*ast.Node
+		for _, atom := range atoms {
+			schemas, known := c.eval.propertySchemas(atom, name)
+			if known != yes {
+				lossy = true
+			}
+			members = append(members, schemas...)
+		}
+
+		children = append(children, ast.Edge{Keyword: "properties", Key: name, Node: conj(id, members...)})
+	}
+
+	withPatterns, withAdditional := false, false
+	keyedMembers := make(map[string]map[string][]*ast.Node)
+	var additional, propertyNames []*ast.Node
+
+	for _, atom := range atoms {
+		for edge := range atom.Children() {
+			switch edge.Keyword {
+			case "patternProperties", "dependentSchemas":
+				withPatterns = withPatterns || edge.Keyword == "patternProperties"
+				if keyedMembers[edge.Keyword] == nil {
+					keyedMembers[edge.Keyword] = make(map[string][]*ast.Node)
+				}
+				keyedMembers[edge.Keyword][edge.Key] = append(keyedMembers[edge.Keyword][edge.Key], edge.Node)
+			case "additionalProperties":
+				withAdditional = true
+				additional = append(additional, edge.Node)
+			case "propertyNames":
+				propertyNames = append(propertyNames, edge.Node)
+			}
+		}
+	}
+
+	if withPatterns && withAdditional {
+		// names matching a pattern of some atom may be additional properties for another one
+		lossy = true
+	}
+
+	for _, keyword := range []string{"patternProperties", "dependentSchemas"} {
+		for key, members := range keyedMembers[keyword] {
+			children = append(children, ast.Edge{Keyword: keyword, Key: key, Node: conj(id, members...)})
+		}
+	}
+
+	if len(additional) > 0 {
+		children = append(children, ast.Edge{Keyword: "additionalProperties", Node: conj(id, additional...)})
+	}
+
+	if len(propertyNames) > 0 {
+		children = append(children, ast.Edge{Keyword: "propertyNames", Node: conj(id, propertyNames...)})
+	}
+
+	return children, lossy
+}
+
+func mergeArrays(id string, atoms []*ast.Node, children []ast.Edge, lossy bool) ([]ast.Edge, bool) {
+	prefix := 0
+	for _, atom := range atoms {
+		prefix = max(prefix, prefixLen(atom))
+	}
+
+	for i := range prefix {
+		var members []*ast.Node
+		for _, atom := range atoms {
+			if item := itemAt(atom, i); item != nil {
+				members = append(members, item)
+			}
+		}
+
+		children = append(children, ast.Edge{Keyword: "prefixItems", Index: i, Node: conj(id, members...)})
+	}
+
+	var items []*ast.Node
+	contains := 0
+	for _, atom := range atoms {
+		for edge := range atom.Children() {
+			switch edge.Keyword {
+			case "items":
+				items = append(items, edge.Node)
+			case "contains":
+				contains++
+				if contains == 1 {
+					children = append(children, edge)
+				} else {
+					// several "contains" may require distinct items
+					lossy = true
+				}
+			}
+		}
+	}
+
+	if len(items) > 0 {
+		children = append(children, ast.Edge{Keyword: "items", Node: conj(id, items...)})
+	}
+
+	return children, lossy
+}
+
+// mergeKeyword merges two values of the same keyword.
+//
+// It tells if the merge is exact and if the merged keyword may be satisfied.
+func mergeKeyword(x, y ast.Keyword) (merged ast.Keyword, exact bool, ok bool) {
+	if bytes.Equal(x.Value, y.Value) && x.Dynamic == y.Dynamic {
+		return x, true, true
+	}
+
+	if x.Dynamic || y.Dynamic {
+		return x, false, true
+	}
+
+	vx, okx := decode(x.Value)
+	vy, oky := decode(y.Value)
+	if !okx || !oky {
+		return x, false, true
+	}
+
+	switch x.Name {
+	case "enum":
+		return mergeEnum(x, vx, vy)
+	case "minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties", "minContains":
+		return pickRat(x, y, vx, vy, 1)
+	case "maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties", "maxContains":
+		return pickRat(x, y, vx, vy, -1)
+	case "uniqueItems":
+		if vx == true {
+			return x, true, true
+		}
+
+		return y, true, true
+	case "required":
+		return mergeRequired(x, vx, vy), true, true
+	case "dependentRequired":
+		return mergeDependentRequired(x, vx, vy), true, true
+	case "multipleOf":
+		rx, _ := ratOf(vx)
+		ry, _ := ratOf(vy)
+		if rx == nil || ry == nil || rx.Sign() == 0 || ry.Sign() == 0 {
+			return x, false, true
+		}
+
+		switch {
+		case new(big.Rat).Quo(rx, ry).IsInt():
+			return x, true, true
+		case new(big.Rat).Quo(ry, rx).IsInt():
+			return y, true, true
+		default:
+			return x, false, true
+		}
+	default:
+		return x, false, true
+	}
+}
+
+func mergeEnum(x ast.Keyword, vx, vy any) (ast.Keyword, bool, bool) {
+	ax, _ := vx.([]any)
+	ay, _ := vy.([]any)
+
+	var values []any
+	for _, value := range ax {
+		if slices.ContainsFunc(ay, func(other any) bool { return equal(value, other) }) {
+			values = append(values, value)
+		}
+	}
+
+	if len(values) == 0 {
+		return x, true, false
+	}
+
+	return ast.Keyword{Name: x.Name, Value: encode(values)}, true, true
+}
+
+// pickRat picks the greatest (sign > 0) or the lowest (sign < 0) numerical value.
+func pickRat(x, y ast.Keyword, vx, vy any, sign int) (ast.Keyword, bool, bool) {
+	rx, okx := ratOf(vx)
+	ry, oky := ratOf(vy)
+	if !okx || !oky {
+		return x, false, true
+	}
+
+	if rx.Cmp(ry)*sign >= 0 {
+		return x, true, true
+	}
+
+	return y, true, true
+}
+
+func mergeRequired(x ast.Keyword, vx, vy any) ast.Keyword {
+	ax, _ := vx.([]any)
+	ay, _ := vy.([]any)
+
+	names := make([]string, 0, len(ax)+len(ay))
+	for _, value := range append(ax, ay...) {
+		if s, ok := value.(string); ok {
+			names = append(names, s)
+		}
+	}
+	slices.Sort(names)
+
+	return ast.Keyword{Name: x.Name, Value: encode(slices.Compact(names))}
+}
+
+func mergeDependentRequired(x ast.Keyword, vx, vy any) ast.Keyword {
+	mx, _ := vx.(map[string]any)
+	my, _ := vy.(map[string]any)
+
+	merged := make(map[string]any, len(mx)+len(my))
+	for _, m := range []map[string]any{mx, my} {
+		for key, value := range m {
+			existing, _ := merged[key].([]any)
+			names, _ := value.([]any)
+			merged[key] = append(existing, names...)
+		}
+	}
+
+	return ast.Keyword{Name: x.Name, Value: encode(merged)}
+}
+
+//nolint:gochecknoglobals // keywords which only apply to values of a given type
+var typedKeywords = map[string]string{
+	"maximum": "number", "minimum": "number", "exclusiveMaximum": "number", "exclusiveMinimum": "number",
+	"multipleOf": "number",
+	"maxLength":  "string", "minLength": "string", "pattern": "string", "format": "string",
+	"maxItems": "array", "minItems": "array", "uniqueItems": "array", "maxContains": "array", "minContains": "array",
+	"maxProperties": "object", "minProperties": "object", "required": "object", "dependentRequired": "object",
+}
+
+// appliesTo tells if a keyword applies to values of a type. All keywords apply when the type is empty.
+func appliesTo(keyword, typ string) bool {
+	required, isTyped := typedKeywords[keyword]
+	if !isTyped || typ == "" {
+		return true
+	}
+
+	if typ == "integer" {
+		typ = "number"
+	}
+
+	return required == typ
+}
@@ -0,0 +1,205 @@
+package subsumption
+
+import (
+	"slices"
+
+	"github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
+)
+
+//nolint:gochecknoglobals // the true and false schemas
+var (
+	trueNode  = ast.NewBool("", true)
+	falseNode = ast.NewBool("", false)
+)
+
+//nolint:gochecknoglobals // keywords which combine subschemas
+var compositionKeywords = []string{"allOf", "anyOf", "oneOf", "not", "if", "then", "else"}
+
+// edges of a node with a given keyword.
+func edges(n *ast.Node, keyword string) []ast.Edge {
+	var result []ast.Edge
+	for edge := range n.Children() {
+		if edge.Keyword == keyword {
+			result = append(result, edge)
+		}
+	}
+
+	return result
+}
+
+// nodes of a node with a given keyword.
+func nodes(n *ast.Node, keyword string) []*ast.Node {
+	var result []*ast.Node
+	for edge := range n.Children() {
+		if edge.Keyword == keyword {
+			result = append(result, edge.Node)
+		}
+	}
+
+	return result
+}
+
+// child of a node with a given keyword, or nil.
+func child(n *ast.Node, keyword string) *ast.Node {
+	for edge := range n.Children() {
+		if edge.Keyword == keyword {
+			return edge.Node
+		}
+	}
+
+	return nil
+}
+
+// keyed child of a node, e.g. the schema of a property, or nil.
+func keyed(n *ast.Node, keyword, key string) *ast.Node {
+	for edge := range n.Children() {
+		if edge.Keyword == keyword && edge.Key == key {
+			return edge.Node
+		}
+	}
+
+	return nil
+}
+
+func hasEdge(n *ast.Node, keywords ...string) bool {
+	for edge := range n.Children() {
+		if slices.Contains(keywords, edge.Keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasCompositions(n *ast.Node) bool {
+	return hasEdge(n, compositionKeywords...)
+}
+
+// without derives a node without the subschemas of some keywords.
+func without(n *ast.Node, keywords ...string) *ast.Node {
+	if !hasEdge(n, keywords...) {
+		return n
+	}
+
+	children := make([]ast.Edge, 0)
+	for edge := range n.Children() {
+		if !slices.Contains(keywords, edge.Keyword) {
+			children = append(children, edge)
+		}
+	}
+
+	derived := ast.NewSchema(n.ID(), n.Type(), slices.Collect(n.Keywords()), children)
+	if derived.IsEmpty() {
+		return trueNode
+	}
+
+	return derived
+}
+
+// conj derives the conjunction of some nodes, i.e. an "allOf".
+func conj(id string, members ...*ast.Node) *ast.Node {
+	children := make([]ast.Edge, 0, len(members))
+	for _, member := range members {
+		if member == nil {
+			continue
+		}
+
+		resolved := member.Resolved()
+		if resolved != nil && resolved.Kind() == ast.KindTrue {
+			continue
+		}
+
+		children = append(children, ast.Edge{Keyword: "allOf", Node: member})
+	}
+
+	switch len(children) {
+	case 0:
+		return trueNode
+	case 1:
+		return children[0].Node
+	default:
+		return ast.NewSchema(id, "", nil, children)
+	}
+}
+
+// itemAt yields the schema of the i-th item of an array, or nil when it is unconstrained.
+func itemAt(n *ast.Node, i int) *ast.Node {
+	for edge := range n.Children() {
+		if edge.Keyword == "prefixItems" && edge.Index == i {
+			return edge.Node
+		}
+	}
+
+	return child(n, "items")
+}
+
+func prefixLen(n *ast.Node) int {
+	l := 0
+	for edge := range n.Children() {
+		if edge.Keyword == "prefixItems" && edge.Index+1 > l {
+			l = edge.Index + 1
+		}
+	}
+
+	return l
+}
+
+// propertyNames declared by "properties".
+func propertyNames(n *ast.Node) []string {
+	var names []string
+	for edge := range n.Children() {
+		if edge.Keyword == "properties" {
+			names = append(names, edge.Key)
+		}
+	}
+
+	return names
+}
+
+// patterns declared by "patternProperties".
+func patterns(n *ast.Node) map[string]*ast.Node {
+	result := make(map[string]*ast.Node)
+	for edge := range n.Children() {
+		if edge.Keyword == "patternProperties" {
+			result[edge.Key] = edge.Node
+		}
+	}
+
+	return result
+}
+
+// typeWithin tells if values of type ta are of type tb.
+func typeWithin(ta, tb string) bool {
+	return tb == "" || ta == tb || ta == "integer" && tb == "number"
+}
+
+// typesDisjoint tells if no value may be valid against both nodes, judging by their types.
+func typesDisjoint(a, b *ast.Node) bool {
+	a, b = a.Resolved(), b.Resolved()
+	if a == nil || b == nil {
+		return false
+	}
+
+	if a.Kind() == ast.KindFalse || b.Kind() == ast.KindFalse {
+		return true
+	}
+
+	ta, tb := a.Type(), b.Type()
+	if ta == "" || tb == "" {
+		return false
+	}
+
+	return !typeWithin(ta, tb) && !typeWithin(tb, ta)
+}
+
+func isTrue(n *ast.Node) bool {
+	n = n.Resolved()
+
+	return n != nil && (n.Kind() == ast.KindTrue || n.IsEmpty())
+}
+
+func isFalse(n *ast.Node) bool {
+	n = n.Resolved()
+
+	return n != nil && n.Kind() == ast.KindFalse
+}
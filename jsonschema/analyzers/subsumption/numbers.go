@@ -0,0 +1,184 @@
+package subsumption
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
+)
+
+// bound of a numerical interval.
+type bound struct {
+	value     *big.Rat
+	exclusive bool
+}
+
+// lowerBound of the numbers valid against a node, or nil.
+//
+// Bounds are normalized for integers, e.g. "exclusiveMinimum": 1.5 is the inclusive bound 2.
+func lowerBound(n *ast.Node, integer bool) *bound {
+	var result *bound
+
+	for _, name := range []string{"minimum", "exclusiveMinimum"} {
+		r, ok := keywordRat(n, name)
+		if !ok {
+			continue
+		}
+
+		b := &bound{value: r, exclusive: name == "exclusiveMinimum"}
+		if integer {
+			b = ceilBound(b)
+		}
+
+		if result == nil || b.value.Cmp(result.value) > 0 || b.value.Cmp(result.value) == 0 && b.exclusive {
+			result = b
+		}
+	}
+
+	return result
+}
+
+// upperBound of the numbers valid against a node, or nil.
+func upperBound(n *ast.Node, integer bool) *bound {
+	var result *bound
+
+	for _, name := range []string{"maximum", "exclusiveMaximum"} {
+		r, ok := keywordRat(n, name)
+		if !ok {
+			continue
+		}
+
+		b := &bound{value: r, exclusive: name == "exclusiveMaximum"}
+		if integer {
+			b = floorBound(b)
+		}
+
+		if result == nil || b.value.Cmp(result.value) < 0 || b.value.Cmp(result.value) == 0 && b.exclusive {
+			result = b
+		}
+	}
+
+	return result
+}
+
+// ceilBound yields the lowest integer within a lower bound.
+func ceilBound(b *bound) *bound {
+	f := floor(b.value)
+	if b.exclusive || f.Cmp(b.value) != 0 {
+		f.Add(f, big.NewRat(1, 1))
+	}
+
+	return &bound{value: f}
+}
+
+// floorBound yields the greatest integer within an upper bound.
+func floorBound(b *bound) *bound {
+	f := floor(b.value)
+	if b.exclusive && f.Cmp(b.value) == 0 {
+		f.Sub(f, big.NewRat(1, 1))
+	}
+
+	return &bound{value: f}
+}
+
+func floor(r *big.Rat) *big.Rat {
+	// the denominator of a rational is positive: the euclidean division rounds down
+	return new(big.Rat).SetInt(new(big.Int).Div(r.Num(), r.Denom()))
+}
+
+// lowerImplies tells if a lower bound x implies the lower bound y.
+func lowerImplies(x, y *bound) bool {
+	switch {
+	case y == nil:
+		return true
+	case x == nil:
+		return false
+	}
+
+	c := x.value.Cmp(y.value)
+
+	return c > 0 || c == 0 && (!y.exclusive || x.exclusive)
+}
+
+// upperImplies tells if an upper bound x implies the upper bound y.
+func upperImplies(x, y *bound) bool {
+	switch {
+	case y == nil:
+		return true
+	case x == nil:
+		return false
+	}
+
+	c := x.value.Cmp(y.value)
+
+	return c < 0 || c == 0 && (!y.exclusive || x.exclusive)
+}
+
+// emptyInterval tells if no number lies within two bounds.
+func emptyInterval(lower, upper *bound) bool {
+	if lower == nil || upper == nil {
+		return false
+	}
+
+	c := lower.value.Cmp(upper.value)
+
+	return c > 0 || c == 0 && (lower.exclusive || upper.exclusive)
+}
+
+// multipleImplied tells if the numbers valid against a are multiples of m.
+func multipleImplied(a *ast.Node, m *big.Rat) bool {
+	if m.Sign() == 0 {
+		return false
+	}
+
+	if k, ok := keywordRat(a, "multipleOf"); ok && new(big.Rat).Quo(k, m).IsInt() {
+		return true
+	}
+
+	// integers are multiples of 1/n
+	return a.Type() == "integer" && new(big.Rat).Inv(m).IsInt()
+}
+
+func (c *checker) numbers(a, b, orig *ast.Node) []outcome {
+	integer := a.Type() == "integer"
+	lowerA, upperA := lowerBound(a, integer), upperBound(a, integer)
+	if emptyInterval(lowerA, upperA) {
+		// A accepts no number
+		return nil
+	}
+
+	var outcomes []outcome
+
+	if lowerB := lowerBound(b, integer); !lowerImplies(lowerA, lowerB) {
+		keyword := "minimum"
+		if lowerB.exclusive {
+			keyword = "exclusiveMinimum"
+		}
+
+		outcomes = append(outcomes, c.refute(orig, b, keyword,
+			fmt.Sprintf("A accepts numbers lower than %s", number(lowerB.value)),
+			numberCandidates(a, lowerB.value)...,
+		))
+	}
+
+	if upperB := upperBound(b, integer); !upperImplies(upperA, upperB) {
+		keyword := "maximum"
+		if upperB.exclusive {
+			keyword = "exclusiveMaximum"
+		}
+
+		outcomes = append(outcomes, c.refute(orig, b, keyword,
+			fmt.Sprintf("A accepts numbers greater than %s", number(upperB.value)),
+			numberCandidates(a, upperB.value)...,
+		))
+	}
+
+	if m, ok := keywordRat(b, "multipleOf"); ok && !multipleImplied(a, m) {
+		outcomes = append(outcomes, c.refute(orig, b, "multipleOf",
+			fmt.Sprintf("A accepts numbers which are not multiples of %s", number(m)),
+			numberCandidates(a)...,
+		))
+	}
+
+	return outcomes
+}
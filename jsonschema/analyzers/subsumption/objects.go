@@ -0,0 +1,304 @@
+package subsumption
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
+)
+
+//nolint:gochecknoglobals // property names are strings
+var stringNode = ast.NewSchema("", "string", nil, nil)
+
+// forbids tells if objects valid against a node never have a property.
+func (c *checker) forbids(n *ast.Node, name string) bool {
+	schemas, known := c.eval.propertySchemas(n, name)
+	if known == yes && slices.ContainsFunc(schemas, isFalse) {
+		return true
+	}
+
+	if names := child(n, "propertyNames"); names != nil && c.eval.accepts(names, name) == no {
+		return true
+	}
+
+	limit, ok := keywordInt(n, "maxProperties")
+
+	return ok && limit == 0
+}
+
+// closed tells if objects valid against a node only have the properties declared by "properties".
+func closed(n *ast.Node) bool {
+	additional := child(n, "additionalProperties")
+
+	return additional != nil && isFalse(additional) && len(patterns(n)) == 0
+}
+
+func (c *checker) objects(a, b, orig *ast.Node) []outcome {
+	requiredA := keywordStrings(a, "required")
+	if slices.ContainsFunc(requiredA, func(name string) bool { return c.forbids(a, name) }) {
+		// A accepts no object
+		return nil
+	}
+
+	minA, _ := keywordInt(a, "minProperties")
+	minA = max(minA, len(requiredA))
+	maxA, hasMaxA := keywordInt(a, "maxProperties")
+	if closed(a) {
+		declared := 0
+		for _, name := range propertyNames(a) {
+			if !c.forbids(a, name) {
+				declared++
+			}
+		}
+
+		if !hasMaxA || declared < maxA {
+			maxA, hasMaxA = declared, true
+		}
+	}
+
+	if hasMaxA && minA > maxA {
+		return nil
+	}
+
+	var outcomes []outcome
+
+	for _, name := range keywordStrings(b, "required") {
+		if !slices.Contains(requiredA, name) {
+			outcomes = append(outcomes, c.refute(orig, b, "required",
+				fmt.Sprintf("A accepts objects without the property %q", name),
+				c.objectCandidates(a)...,
+			))
+		}
+	}
+
+	if minB, ok := keywordInt(b, "minProperties"); ok && minA < minB {
+		outcomes = append(outcomes, c.refute(orig, b, "minProperties",
+			fmt.Sprintf("A accepts objects with less than %d properties", minB),
+			c.objectCandidates(a)...,
+		))
+	}
+
+	if maxB, ok := keywordInt(b, "maxProperties"); ok && (!hasMaxA || maxA > maxB) {
+		outcomes = append(outcomes, c.refute(orig, b, "maxProperties",
+			fmt.Sprintf("A accepts objects with more than %d properties", maxB),
+			c.objectCandidates(a)...,
+		))
+	}
+
+	outcomes = append(outcomes, c.dependentRequired(a, b, orig, requiredA)...)
+	outcomes = append(outcomes, c.properties(a, b)...)
+	outcomes = append(outcomes, c.patternProperties(a, b)...)
+	outcomes = append(outcomes, c.additionalProperties(a, b)...)
+	outcomes = append(outcomes, c.propertyNames(a, b, orig)...)
+	outcomes = append(outcomes, c.dependentSchemas(a, b, orig)...)
+
+	if hasEdge(b, "unevaluatedProperties") {
+		outcomes = append(outcomes, undecide(b, "unevaluatedProperties", "unevaluated properties are not compared"))
+	}
+
+	return outcomes
+}
+
+func (c *checker) dependentRequired(a, b, orig *ast.Node, requiredA []string) []outcome {
+	var outcomes []outcome
+	dependentA := keywordMap(a, "dependentRequired")
+
+	for key, value := range keywordMap(b, "dependentRequired") {
+		if c.forbids(a, key) {
+			continue
+		}
+
+		names, _ := value.([]any)
+		implied, _ := dependentA[key].([]any)
+		for _, name := range names {
+			s, _ := name.(string)
+			if slices.Contains(requiredA, s) || slices.ContainsFunc(implied, func(v any) bool { return v == name }) {
+				continue
+			}
+
+			outcomes = append(outcomes, c.refute(orig, b, "dependentRequired",
+				fmt.Sprintf("A accepts objects with the property %q, but without %q", key, s),
+				c.objectCandidates(a, key)...,
+			))
+		}
+	}
+
+	return outcomes
+}
+
+// properties compares the schemas of the properties declared by A or B.
+func (c *checker) properties(a, b *ast.Node) []outcome {
+	names := propertyNames(a)
+	for _, name := range propertyNames(b) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	outcomes := make([]outcome, 0, len(names))
+	for _, name := range names {
+		if c.forbids(a, name) {
+			continue
+		}
+
+		schemasA, knownA := c.eval.propertySchemas(a, name)
+		schemasB, knownB := c.eval.propertySchemas(b, name)
+		o := c.sub(conj(a.ID(), schemasA...), conj(b.ID(), schemasB...))
+
+		switch {
+		case knownB != yes && o.verdict == Subsumed:
+			// B may have more constraints on this property
+			o = undecide(b, "patternProperties", "unsupported pattern")
+		case knownA != yes:
+			// A may have more constraints on this property
+			o = inexact(o)
+		}
+
+		outcomes = append(outcomes, o.at(property(name)))
+	}
+
+	return outcomes
+}
+
+// patternProperties compares the schemas of the properties which are not declared, and match a pattern of B.
+func (c *checker) patternProperties(a, b *ast.Node) []outcome {
+	var outcomes []outcome
+	patternsA := patterns(a)
+
+	for pattern, schemaB := range patterns(b) {
+		if closed(a) {
+			break
+		}
+
+		if schemaA, ok := patternsA[pattern]; ok {
+			// other patterns of A may match the same properties
+			o := c.sub(schemaA, schemaB)
+			if len(patternsA) > 1 {
+				o = inexact(o)
+			}
+			outcomes = append(outcomes, c.atName(o, a, b, pattern))
+
+			continue
+		}
+
+		if len(patternsA) > 0 {
+			outcomes = append(outcomes, undecide(b, "patternProperties",
+				fmt.Sprintf("patterns are not compared: %q", pattern),
+			))
+
+			continue
+		}
+
+		o := c.sub(orTrue(child(a, "additionalProperties")), schemaB)
+		outcomes = append(outcomes, c.atName(o, a, b, pattern))
+	}
+
+	return outcomes
+}
+
+// additionalProperties compares the schemas of the properties which are neither declared nor match a pattern of B.
+func (c *checker) additionalProperties(a, b *ast.Node) []outcome {
+	additionalB := child(b, "additionalProperties")
+	if additionalB == nil || closed(a) {
+		return nil
+	}
+
+	patternsB := patterns(b)
+	outcomes := make([]outcome, 0, 1)
+
+	for pattern, schemaA := range patterns(a) {
+		if _, ok := patternsB[pattern]; ok {
+			continue
+		}
+
+		// properties matching this pattern may not match any pattern of B
+		outcomes = append(outcomes, c.atName(inexact(c.sub(schemaA, additionalB)), a, b, pattern))
+	}
+
+	o := c.sub(orTrue(child(a, "additionalProperties")), additionalB)
+	outcomes = append(outcomes, c.atName(o, a, b, ""))
+
+	return outcomes
+}
+
+// atName locates an outcome on a property which is not declared by A or B, and matches a pattern (or no pattern
+// when it is empty).
+//
+// Counter-examples are kept only when such a name is found.
+func (c *checker) atName(o outcome, a, b *ast.Node, pattern string) outcome {
+	name, ok := c.freshName(a, b, pattern)
+	if !ok {
+		return inexact(o)
+	}
+
+	return o.at(property(name))
+}
+
+func (c *checker) propertyNames(a, b, orig *ast.Node) []outcome {
+	namesB := child(b, "propertyNames")
+	if namesB == nil {
+		return nil
+	}
+
+	if closed(a) {
+		var outcomes []outcome
+		for _, name := range propertyNames(a) {
+			if c.forbids(a, name) {
+				continue
+			}
+
+			switch c.eval.accepts(namesB, name) {
+			case no:
+				outcomes = append(outcomes, c.refute(orig, b, "propertyNames",
+					fmt.Sprintf("A accepts objects with the property %q", name),
+					c.objectCandidates(a, name)...,
+				))
+			case maybe:
+				outcomes = append(outcomes, undecide(b, "propertyNames", fmt.Sprintf("cannot evaluate %q", name)))
+			}
+		}
+
+		return outcomes
+	}
+
+	namesA := conj(a.ID(), child(a, "propertyNames"), stringNode)
+	o := c.sub(namesA, namesB)
+	if o.verdict != NotSubsumed {
+		return []outcome{o}
+	}
+
+	var candidates []any
+	for _, ce := range o.counterExamples {
+		if name, ok := ce.value.(string); ok {
+			candidates = append(candidates, c.objectCandidates(a, name)...)
+		}
+	}
+
+	return []outcome{c.refute(orig, b, "propertyNames", "A accepts objects with property names rejected by B", candidates...)}
+}
+
+func (c *checker) dependentSchemas(a, b, orig *ast.Node) []outcome {
+	var outcomes []outcome
+
+	for _, edge := range edges(b, "dependentSchemas") {
+		if c.forbids(a, edge.Key) {
+			continue
+		}
+
+		if dependentA := keyed(a, "dependentSchemas", edge.Key); dependentA != nil &&
+			c.sub(dependentA, edge.Node).verdict == Subsumed {
+			continue
+		}
+
+		if c.sub(a, edge.Node).verdict == Subsumed {
+			continue
+		}
+
+		outcomes = append(outcomes, c.refute(orig, b, "dependentSchemas",
+			fmt.Sprintf("A accepts objects with the property %q, which are invalid against %q", edge.Key, edge.Node.ID()),
+			c.objectCandidates(a, edge.Key)...,
+		))
+	}
+
+	return outcomes
+}
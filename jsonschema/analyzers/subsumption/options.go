@@ -0,0 +1,72 @@
+package subsumption
+
+import "github.com/fredbi/core/jsonschema"
+
+// Option customizes the behavior of the [Checker].
+type Option func(*options)
+
+type options struct {
+	resolver        *jsonschema.Resolver
+	baseURI         string
+	counterExamples bool
+	seed            int64
+	attempts        int
+}
+
+// WithResolver equips the [Checker] with a [jsonschema.Resolver] to resolve "$ref" s.
+//
+// By default, a new [jsonschema.Resolver] is used for every schema.
+func WithResolver(resolver *jsonschema.Resolver) Option {
+	return func(o *options) {
+		o.resolver = resolver
+	}
+}
+
+// WithBaseURI sets the URI of the compared schemas, against which relative "$ref" s are resolved.
+func WithBaseURI(uri string) Option {
+	return func(o *options) {
+		o.baseURI = uri
+	}
+}
+
+// WithCounterExamples enables the generation of complete counter-example instances (see [CounterExample.Instance]).
+//
+// This is enabled by default.
+func WithCounterExamples(enabled bool) Option {
+	return func(o *options) {
+		o.counterExamples = enabled
+	}
+}
+
+// WithSeed sets the seed of the data faker used to generate counter-example instances.
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.seed = seed
+	}
+}
+
+// WithAttempts sets the number of instances generated by the data faker to build a counter-example instance.
+//
+// The default is 5.
+func WithAttempts(attempts int) Option {
+	return func(o *options) {
+		if attempts > 0 {
+			o.attempts = attempts
+		}
+	}
+}
+
+const defaultAttempts = 5
+
+func optionsWithDefaults(opts []Option) *options {
+	o := &options{
+		counterExamples: true,
+		attempts:        defaultAttempts,
+	}
+
+	for _, apply := range opts {
+		apply(o)
+	}
+
+	return o
+}
@@ -0,0 +1,106 @@
+package subsumption
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+
+	"github.com/fredbi/core/json"
+)
+
+// Verdict of a subsumption check.
+type Verdict uint8
+
+const (
+	// Unknown means that the [Checker] could not decide.
+	Unknown Verdict = iota
+	// Subsumed means that every instance valid against A is valid against B.
+	Subsumed
+	// NotSubsumed means that some instance valid against A is invalid against B.
+	NotSubsumed
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case Subsumed:
+		return "subsumed"
+	case NotSubsumed:
+		return "not subsumed"
+	default:
+		return "unknown"
+	}
+}
+
+// CounterExample proves that A is not subsumed by B.
+type CounterExample struct {
+	// Path is the JSON pointer to the offending value in instances, e.g. "/pets/0/name"
+	Path string
+
+	// Keyword of B which is violated, e.g. "maxLength"
+	Keyword string
+
+	// Location of the violated schema in B, as a URI with a JSON pointer fragment
+	Location string
+
+	// Reason explains why A is not subsumed by B
+	Reason string
+
+	// Value at Path, which is valid against A and invalid against B
+	Value json.Document
+
+	// Instance is a complete instance valid against A and invalid against B, if one could be generated
+	Instance json.Document
+
+	// HasInstance tells if Instance is set
+	HasInstance bool
+}
+
+func (e CounterExample) String() string {
+	return fmt.Sprintf("%q at %q: %s", e.Keyword, e.Path, e.Reason)
+}
+
+// Undecided describes a construct for which the [Checker] could not decide.
+type Undecided struct {
+	// Path is the JSON pointer to the undecided value in instances
+	Path string
+
+	// Keyword of B which could not be decided, e.g. "pattern"
+	Keyword string
+
+	// Location of the undecided schema in B, as a URI with a JSON pointer fragment
+	Location string
+
+	// Reason explains why no decision could be made
+	Reason string
+}
+
+func (u Undecided) String() string {
+	return fmt.Sprintf("%q at %q: %s", u.Keyword, u.Path, u.Reason)
+}
+
+// Result of a subsumption check.
+type Result struct {
+	verdict         Verdict
+	counterExamples []CounterExample
+	undecided       []Undecided
+}
+
+// Verdict of the check.
+func (r Result) Verdict() Verdict {
+	return r.verdict
+}
+
+// IsSubsumed tells if A is proved to be subsumed by B.
+func (r Result) IsSubsumed() bool {
+	return r.verdict == Subsumed
+}
+
+// CounterExamples yields the [CounterExample] s found when the verdict is [NotSubsumed].
+func (r Result) CounterExamples() iter.Seq[CounterExample] {
+	return slices.Values(r.counterExamples)
+}
+
+// Undecided yields the constructs that could not be decided when the verdict is [Unknown].
+func (r Result) Undecided() iter.Seq[Undecided] {
+	return slices.Values(r.undecided)
+}
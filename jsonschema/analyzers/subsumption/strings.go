@@ -0,0 +1,50 @@
+package subsumption
+
+import (
+	"fmt"
+
+	"github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
+)
+
+func (c *checker) strings(a, b, orig *ast.Node) []outcome {
+	minA, _ := keywordInt(a, "minLength")
+	maxA, hasMaxA := keywordInt(a, "maxLength")
+	if hasMaxA && minA > maxA {
+		// A accepts no string
+		return nil
+	}
+
+	var outcomes []outcome
+
+	if minB, ok := keywordInt(b, "minLength"); ok && minA < minB {
+		outcomes = append(outcomes, c.refute(orig, b, "minLength",
+			fmt.Sprintf("A accepts strings shorter than %d", minB),
+			stringCandidates(a)...,
+		))
+	}
+
+	if maxB, ok := keywordInt(b, "maxLength"); ok && (!hasMaxA || maxA > maxB) {
+		outcomes = append(outcomes, c.refute(orig, b, "maxLength",
+			fmt.Sprintf("A accepts strings longer than %d", maxB),
+			stringCandidates(a, maxB+1)...,
+		))
+	}
+
+	if patternB, ok := keywordString(b, "pattern"); ok {
+		if patternA, _ := keywordString(a, "pattern"); patternA != patternB {
+			// regular expressions are not compared: only a counter-example may decide
+			outcomes = append(outcomes, c.refute(orig, b, "pattern",
+				fmt.Sprintf("A accepts strings which may not match %q", patternB),
+				stringCandidates(a)...,
+			))
+		}
+	}
+
+	if formatB, ok := keywordString(b, "format"); ok {
+		if formatA, _ := keywordString(a, "format"); formatA != formatB {
+			outcomes = append(outcomes, undecide(b, "format", fmt.Sprintf("formats are not compared: %q", formatB)))
+		}
+	}
+
+	return outcomes
+}
@@ -0,0 +1,329 @@
+package subsumption
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
+)
+
+// truth is a three-valued logic value.
+type truth uint8
+
+const (
+	maybe truth = iota
+	yes
+	no
+)
+
+func truthOf(b bool) truth {
+	if b {
+		return yes
+	}
+
+	return no
+}
+
+func (t truth) and(u truth) truth {
+	switch {
+	case t == no || u == no:
+		return no
+	case t == maybe || u == maybe:
+		return maybe
+	default:
+		return yes
+	}
+}
+
+func (t truth) or(u truth) truth {
+	switch {
+	case t == yes || u == yes:
+		return yes
+	case t == maybe || u == maybe:
+		return maybe
+	default:
+		return no
+	}
+}
+
+func (t truth) not() truth {
+	switch t {
+	case yes:
+		return no
+	case no:
+		return yes
+	default:
+		return maybe
+	}
+}
+
+// step in the path to a value in an instance.
+type step struct {
+	token string
+	index bool
+}
+
+// path to a value in an instance.
+type path []step
+
+func (p path) String() string {
+	var pointer strings.Builder
+	for _, s := range p {
+		pointer.WriteByte('/')
+		pointer.WriteString(json.EscapeToken(s.token))
+	}
+
+	return pointer.String()
+}
+
+func property(name string) step {
+	return step{token: name}
+}
+
+func index(i int) step {
+	return step{token: strconv.Itoa(i), index: true}
+}
+
+// JSON values are represented as decoded by encoding/json, with numbers as [stdjson.Number].
+
+func decode(data []byte) (any, bool) {
+	dec := stdjson.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+
+	return v, true
+}
+
+func encode(v any) []byte {
+	data, err := stdjson.Marshal(v)
+	if err != nil {
+		return []byte("null")
+	}
+
+	return data
+}
+
+func document(v any) json.Document {
+	doc := json.Make()
+	if err := doc.UnmarshalJSON(encode(v)); err != nil {
+		return json.EmptyDocument
+	}
+
+	return doc
+}
+
+func ratOf(v any) (*big.Rat, bool) {
+	n, isNumber := v.(stdjson.Number)
+	if !isNumber {
+		return nil, false
+	}
+
+	return new(big.Rat).SetString(n.String())
+}
+
+func number(r *big.Rat) stdjson.Number {
+	if r.IsInt() {
+		return stdjson.Number(r.Num().String())
+	}
+
+	const precision = 20
+	s := strings.TrimRight(r.FloatString(precision), "0")
+
+	return stdjson.Number(s)
+}
+
+func isNumber(v any) bool {
+	_, ok := v.(stdjson.Number)
+
+	return ok
+}
+
+func isInteger(v any) bool {
+	r, ok := ratOf(v)
+
+	return ok && r.IsInt()
+}
+
+// hasType tells if a value is of a JSON schema type.
+func hasType(v any, typ string) bool {
+	switch typ {
+	case "null":
+		return v == nil
+	case "boolean":
+		_, ok := v.(bool)
+
+		return ok
+	case "integer":
+		return isInteger(v)
+	case "number":
+		return isNumber(v)
+	case "string":
+		_, ok := v.(string)
+
+		return ok
+	case "array":
+		_, ok := v.([]any)
+
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+
+		return ok
+	default:
+		return false
+	}
+}
+
+// equal tells if two values are equal, as per JSON schema: numbers are compared by value.
+func equal(a, b any) bool {
+	switch x := a.(type) {
+	case stdjson.Number:
+		ra, _ := ratOf(x)
+		rb, ok := ratOf(b)
+
+		return ok && ra != nil && ra.Cmp(rb) == 0
+	case []any:
+		y, ok := b.([]any)
+
+		return ok && slices.EqualFunc(x, y, equal)
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+
+		for key, value := range x {
+			other, found := y[key]
+			if !found || !equal(value, other) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return a == b
+	}
+}
+
+// clone a value, so it may be mutated.
+func clone(v any) any {
+	switch x := v.(type) {
+	case []any:
+		c := make([]any, len(x))
+		for i, elem := range x {
+			c[i] = clone(elem)
+		}
+
+		return c
+	case map[string]any:
+		c := make(map[string]any, len(x))
+		for key, value := range x {
+			c[key] = clone(value)
+		}
+
+		return c
+	default:
+		return v
+	}
+}
+
+// keyword values
+
+func keywordRat(n *ast.Node, name string) (*big.Rat, bool) {
+	k, ok := n.Keyword(name)
+	if !ok || k.Dynamic {
+		return nil, false
+	}
+
+	v, ok := decode(k.Value)
+	if !ok {
+		return nil, false
+	}
+
+	return ratOf(v)
+}
+
+func keywordInt(n *ast.Node, name string) (int, bool) {
+	r, ok := keywordRat(n, name)
+	if !ok || !r.IsInt() || !r.Num().IsInt64() {
+		return 0, false
+	}
+
+	return int(r.Num().Int64()), true
+}
+
+func keywordBool(n *ast.Node, name string) bool {
+	k, ok := n.Keyword(name)
+
+	return ok && !k.Dynamic && string(k.Value) == "true"
+}
+
+func keywordString(n *ast.Node, name string) (string, bool) {
+	k, ok := n.Keyword(name)
+	if !ok || k.Dynamic {
+		return "", false
+	}
+
+	v, ok := decode(k.Value)
+	if !ok {
+		return "", false
+	}
+
+	s, ok := v.(string)
+
+	return s, ok
+}
+
+func keywordValues(n *ast.Node, name string) ([]any, bool) {
+	k, ok := n.Keyword(name)
+	if !ok || k.Dynamic {
+		return nil, false
+	}
+
+	v, ok := decode(k.Value)
+	if !ok {
+		return nil, false
+	}
+
+	values, ok := v.([]any)
+
+	return values, ok
+}
+
+func keywordStrings(n *ast.Node, name string) []string {
+	values, _ := keywordValues(n, name)
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+
+	return strs
+}
+
+// keywordMap yields an object-valued keyword, e.g. "dependentRequired".
+func keywordMap(n *ast.Node, name string) map[string]any {
+	k, ok := n.Keyword(name)
+	if !ok || k.Dynamic {
+		return nil
+	}
+
+	v, ok := decode(k.Value)
+	if !ok {
+		return nil
+	}
+
+	m, _ := v.(map[string]any)
+
+	return m
+}
@@ -0,0 +1,474 @@
+package subsumption
+
+import (
+	stdjson "encoding/json"
+	"math/big"
+	"regexp/syntax"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
+)
+
+const (
+	maxSampleDepth  = 4
+	maxSamples      = 16
+	maxSampleLength = 64
+)
+
+// samples yields values valid against a node.
+//
+// Candidate values are generated from the constraints of the node, then evaluated against it:
+// the samples are a (possibly empty) subset of the valid values.
+func (c *checker) samples(n *ast.Node) []any {
+	n = n.Resolved()
+	if n == nil {
+		return nil
+	}
+
+	if c.depth >= maxSampleDepth {
+		return nil
+	}
+
+	key := sampleKey{node: n, depth: c.depth}
+	if values, ok := c.sampled[key]; ok {
+		return values
+	}
+
+	c.depth++
+	defer func() { c.depth-- }()
+
+	var values []any
+	for _, v := range c.candidates(n) {
+		if len(values) >= maxSamples {
+			break
+		}
+
+		if c.eval.accepts(n, v) == yes && !slices.ContainsFunc(values, func(other any) bool { return equal(v, other) }) {
+			values = append(values, v)
+		}
+	}
+
+	c.sampled[key] = values
+
+	return values
+}
+
+// sampleKey memoizes samples: deeper samples are truncated.
+type sampleKey struct {
+	node  *ast.Node
+	depth int
+}
+
+// first sample of a node.
+func (c *checker) first(n *ast.Node) (any, bool) {
+	values := c.samples(n)
+	if len(values) == 0 {
+		return nil, false
+	}
+
+	return values[0], true
+}
+
+// candidates for the values valid against a node.
+func (c *checker) candidates(n *ast.Node) []any {
+	switch n.Kind() {
+	case ast.KindTrue:
+		return anyValues()
+	case ast.KindFalse:
+		return nil
+	}
+
+	atoms, satisfiable := flatten(n)
+	if !satisfiable {
+		return nil
+	}
+
+	// unions are sampled branch by branch
+	for i, atom := range atoms {
+		for _, keyword := range []string{"anyOf", "oneOf"} {
+			branches := nodes(atom, keyword)
+			if len(branches) == 0 {
+				continue
+			}
+
+			base := without(atom, keyword)
+			var values []any
+			for _, branch := range branches {
+				members := slices.Concat(atoms[:i], []*ast.Node{base, branch}, atoms[i+1:])
+				values = append(values, c.samples(conj(n.ID(), members...))...)
+			}
+
+			return values
+		}
+	}
+
+	g, _, satisfiable := c.merge(n.ID(), atoms)
+	if !satisfiable {
+		return nil
+	}
+
+	if values, ok := keywordValues(g, "enum"); ok {
+		return values
+	}
+
+	types := []string{g.Type()}
+	if g.Type() == "" {
+		types = []string{"null", "boolean", "integer", "number", "string", "array", "object"}
+	}
+
+	var values []any
+	for _, typ := range types {
+		switch typ {
+		case "null":
+			values = append(values, nil)
+		case "boolean":
+			values = append(values, true, false)
+		case "integer", "number":
+			values = append(values, numberCandidates(g)...)
+		case "string":
+			values = append(values, stringCandidates(g)...)
+		case "array":
+			values = append(values, c.arrayCandidates(g)...)
+		case "object":
+			values = append(values, c.objectCandidates(g)...)
+		}
+	}
+
+	return values
+}
+
+// numberCandidates yields numbers around the bounds of a node and around some other values.
+func numberCandidates(n *ast.Node, around ...*big.Rat) []any {
+	integer := n.Type() == "integer"
+	points := []*big.Rat{
+		big.NewRat(0, 1), big.NewRat(1, 1), big.NewRat(-1, 1), big.NewRat(1, 2), big.NewRat(-1, 2),
+		big.NewRat(10, 1), big.NewRat(-10, 1), big.NewRat(1_000_000, 1), big.NewRat(-1_000_000, 1),
+	}
+
+	if lower := lowerBound(n, integer); lower != nil {
+		around = append(around, lower.value)
+	}
+
+	if upper := upperBound(n, integer); upper != nil {
+		around = append(around, upper.value)
+	}
+
+	deltas := []*big.Rat{big.NewRat(1, 1), big.NewRat(1, 2), big.NewRat(1, 1000)}
+	for _, r := range around {
+		points = append(points, r)
+		for _, delta := range deltas {
+			points = append(points, new(big.Rat).Add(r, delta), new(big.Rat).Sub(r, delta))
+		}
+	}
+
+	if k, ok := keywordRat(n, "multipleOf"); ok && k.Sign() != 0 {
+		multiples := make([]*big.Rat, 0, 2*len(points))
+		for _, p := range points {
+			m := new(big.Rat).Mul(floor(new(big.Rat).Quo(p, k)), k)
+			multiples = append(multiples, m, new(big.Rat).Add(m, new(big.Rat).Abs(k)))
+		}
+		points = multiples
+	}
+
+	values := make([]any, 0, len(points))
+	for _, p := range points {
+		if integer && !p.IsInt() {
+			f := floor(p)
+			values = append(values, number(f), number(f.Add(f, big.NewRat(1, 1))))
+
+			continue
+		}
+
+		values = append(values, number(p))
+	}
+
+	return values
+}
+
+//nolint:gochecknoglobals // strings commonly found in instances
+var commonStrings = []string{
+	"x-a", "a b", "a0", "_", "-", "2006-01-02", "2006-01-02T15:04:05Z", "15:04:05", "user@example.com",
+	"https://example.com", "00000000-0000-0000-0000-000000000000", "127.0.0.1",
+}
+
+// stringCandidates yields strings with lengths around the bounds of a node, and some other lengths.
+func stringCandidates(n *ast.Node, lengths ...int) []any {
+	lengths = append(lengths, 0, 1, 2, 3, 8, maxSampleLength)
+	if limit, ok := keywordInt(n, "minLength"); ok {
+		lengths = append(lengths, limit, limit+1)
+	}
+
+	if limit, ok := keywordInt(n, "maxLength"); ok {
+		lengths = append(lengths, limit, limit-1)
+	}
+
+	values := make([]any, 0, 4*len(lengths)+len(commonStrings))
+	for _, l := range lengths {
+		if l < 0 || l > 16*maxSampleLength {
+			continue
+		}
+
+		for _, s := range []string{"a", "A", "0", " "} {
+			values = append(values, strings.Repeat(s, l))
+		}
+	}
+
+	for _, s := range commonStrings {
+		values = append(values, s)
+	}
+
+	return values
+}
+
+// arrayCandidates yields arrays with lengths around the bounds of a node, and some other lengths.
+func (c *checker) arrayCandidates(n *ast.Node, lengths ...int) []any {
+	lengths = append(lengths, 0, 1, 2, prefixLen(n), prefixLen(n)+1)
+	if limit, ok := keywordInt(n, "minItems"); ok {
+		lengths = append(lengths, limit, limit+1)
+	}
+
+	if limit, ok := maxItems(n); ok {
+		lengths = append(lengths, limit)
+	}
+
+	contains := child(n, "contains")
+	var values []any
+
+	for _, l := range lengths {
+		if l < 0 || l > maxSampleLength {
+			continue
+		}
+
+		if arr, ok := c.arrayOf(n, l, nil); ok {
+			values = append(values, arr)
+		}
+
+		if contains != nil {
+			if arr, ok := c.arrayOf(n, l, contains); ok {
+				values = append(values, arr)
+			}
+		}
+	}
+
+	return values
+}
+
+// arrayOf builds an array of some length with samples of its items, possibly restricted to another schema.
+func (c *checker) arrayOf(n *ast.Node, length int, restriction *ast.Node) ([]any, bool) {
+	arr := make([]any, 0, length)
+
+	for i := range length {
+		item, ok := c.first(conj(n.ID(), orTrue(itemAt(n, i)), restriction))
+		if !ok {
+			return nil, false
+		}
+		arr = append(arr, item)
+	}
+
+	return arr, true
+}
+
+// duplicates yields arrays with duplicate items.
+func (c *checker) duplicates(n *ast.Node) []any {
+	minLength, _ := keywordInt(n, "minItems")
+	minLength = max(minLength, 2)
+	if minLength > maxSampleLength {
+		return nil
+	}
+
+	// the duplicate item must be valid at every position
+	members := make([]*ast.Node, 0, minLength)
+	for i := range max(minLength, prefixLen(n)) {
+		members = append(members, orTrue(itemAt(n, i)))
+	}
+
+	var values []any
+	for _, item := range c.samples(conj(n.ID(), members...)) {
+		values = append(values, slices.Repeat([]any{item}, minLength))
+	}
+
+	return values
+}
+
+// objectCandidates yields objects with the required properties of a node and some other properties.
+func (c *checker) objectCandidates(n *ast.Node, names ...string) []any {
+	required := slices.Concat(keywordStrings(n, "required"), names)
+	declared := slices.Concat(required, propertyNames(n))
+
+	values := []any{map[string]any{}}
+	for _, set := range [][]string{required, declared} {
+		if obj, ok := c.objectOf(n, set); ok {
+			values = append(values, obj)
+		}
+	}
+
+	if name, ok := c.freshName(n, n, ""); ok {
+		if obj, ok := c.objectOf(n, append(declared, name)); ok {
+			values = append(values, obj)
+		}
+	}
+
+	return values
+}
+
+// objectOf builds an object with some properties, and samples as values.
+//
+// Properties required by "dependentRequired" are added.
+func (c *checker) objectOf(n *ast.Node, names []string) (map[string]any, bool) {
+	names = slices.Clone(names)
+	dependencies := keywordMap(n, "dependentRequired")
+	for i := 0; i < len(names); i++ {
+		dependent, _ := dependencies[names[i]].([]any)
+		for _, value := range dependent {
+			if name, ok := value.(string); ok && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	obj := make(map[string]any, len(names))
+
+	for _, name := range names {
+		if _, found := obj[name]; found {
+			continue
+		}
+
+		schemas, _ := c.eval.propertySchemas(n, name)
+		value, ok := c.first(conj(n.ID(), schemas...))
+		if !ok {
+			return nil, false
+		}
+		obj[name] = value
+	}
+
+	return obj, true
+}
+
+// freshName finds a property name which is not declared by a or b, and only matches a given pattern (or no
+// pattern when it is empty).
+func (c *checker) freshName(a, b *ast.Node, pattern string) (string, bool) {
+	declared := slices.Concat(propertyNames(a), propertyNames(b))
+	others := make([]string, 0)
+	for _, n := range []*ast.Node{a, b} {
+		for p := range patterns(n) {
+			if p != pattern {
+				others = append(others, p)
+			}
+		}
+	}
+
+	for _, name := range nameCandidates(pattern) {
+		if slices.Contains(declared, name) || c.forbids(a, name) {
+			continue
+		}
+
+		if pattern != "" && c.eval.matches(pattern, name) != yes {
+			continue
+		}
+
+		if slices.ContainsFunc(others, func(p string) bool { return c.eval.matches(p, name) != no }) {
+			continue
+		}
+
+		return name, true
+	}
+
+	return "", false
+}
+
+//nolint:gochecknoglobals // property names commonly found in instances
+var commonNames = []string{"x", "a", "extra", "name", "id", "A", "X", "0", "_", "x-a", "a0", "foo", "bar"}
+
+// nameCandidates yields property names, starting with names derived from the literal prefix of a pattern.
+func nameCandidates(pattern string) []string {
+	var names []string
+
+	if prefix := literalPrefix(pattern); prefix != "" {
+		for _, suffix := range []string{"", "a", "0", "_", "A"} {
+			names = append(names, prefix+suffix)
+		}
+	}
+
+	for i := range 3 {
+		for _, name := range commonNames {
+			if i > 0 {
+				name += strconv.Itoa(i)
+			}
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// literalPrefix of an anchored pattern, e.g. "x-" for "^x-".
+func literalPrefix(pattern string) string {
+	if !strings.HasPrefix(pattern, "^") {
+		return ""
+	}
+
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return ""
+	}
+
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return ""
+	}
+
+	prefix, _ := prog.Prefix()
+
+	return prefix
+}
+
+// typeCandidates yields values of all types but one.
+func typeCandidates(typ string) []any {
+	values := make([]any, 0)
+	for _, v := range anyValues() {
+		if typ == "" || !hasType(v, typ) {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// anyValues yields values of all types.
+func anyValues() []any {
+	return []any{
+		nil, true, false, stdjson.Number("0"), stdjson.Number("1.5"), stdjson.Number("-1"), "", "a",
+		[]any{}, map[string]any{},
+	}
+}
+
+// graft a value in a copy of an instance, at some path.
+//
+// Missing objects and arrays are created on the way, and arrays are padded with null values.
+func graft(base any, p path, v any) any {
+	if len(p) == 0 {
+		return clone(v)
+	}
+
+	s := p[0]
+	if s.index {
+		i, _ := strconv.Atoi(s.token)
+		arr, _ := clone(base).([]any)
+		for len(arr) <= i {
+			arr = append(arr, nil)
+		}
+		arr[i] = graft(arr[i], p[1:], v)
+
+		return arr
+	}
+
+	obj, ok := clone(base).(map[string]any)
+	if !ok {
+		obj = make(map[string]any)
+	}
+	obj[s.token] = graft(obj[s.token], p[1:], v)
+
+	return obj
+}
@@ -3,6 +3,7 @@ package validations
 import (
 	"github.com/fredbi/core/json"
 	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/analyzers/canonical"
 	"github.com/fredbi/core/jsonschema/analyzers/canonical/ast"
 )
 
@@ -10,25 +11,32 @@ type DocumentValidatorFunc func(json.Document) error
 type JSONValidatorFunc func([]byte) error
 
 type Analyzer struct {
+	options
+
 	tree ast.Tree
 }
 
-func New(_ ...Option) *Analyzer {
-	return &Analyzer{}
+func New(opts ...Option) *Analyzer {
+	return &Analyzer{
+		options: optionsWithDefaults(opts),
+	}
 }
 
 type AnalyzedSchema struct {
 }
 
-type Option func(*options)
-
-type options struct{}
-
 type CollectionAnalyzer struct {
 	forest ast.Forest
 }
 
-func (a *Analyzer) Analyze(_ jsonschema.Schema) error {
+// Analyze a [jsonschema.Schema], starting with building its canonical AST.
+func (a *Analyzer) Analyze(sch jsonschema.Schema) error {
+	tree, err := canonical.New().Analyze(sch)
+	if err != nil {
+		return err
+	}
+	a.tree = tree
+
 	return nil
 }
 
@@ -44,10 +52,17 @@ func (a *Analyzer) JSONValidator() JSONValidatorFunc {
 	return nil
 }
 
+// AST is the canonical AST of the analyzed schema.
 func (a *Analyzer) AST() *ast.Tree {
-	return nil
+	return &a.tree
 }
 
+// CanonicalSchema renders the canonical AST of the analyzed schema as a [jsonschema.Schema].
 func (a *Analyzer) CanonicalSchema() jsonschema.Schema {
-	return jsonschema.Schema{}
+	sch, err := canonical.Render(a.tree)
+	if err != nil {
+		return jsonschema.Schema{}
+	}
+
+	return sch
 }
@@ -0,0 +1,755 @@
+package validations
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// Compile a [jsonschema.Schema] into a [Program].
+//
+// The semantics of every keyword follow the version of JSON schema declared by the schema document
+// (see [WithDefaultVersion] for schemas that don't declare any).
+//
+// "$ref" s are resolved at compile time. "$dynamicRef" and "$recursiveRef" are compiled as a "$ref" when they
+// don't refer to a dynamic anchor, and are otherwise not supported. "$data" references are not supported either.
+// Custom keywords are ignored.
+func (a *Analyzer) Compile(sch jsonschema.Schema) (Program, error) {
+	resolver := a.resolver
+	if resolver == nil {
+		resolver = jsonschema.NewResolver()
+	}
+
+	c := &compiler{
+		options:    a.options,
+		resolver:   resolver,
+		dollarData: sch.UsesDollarData(),
+		functions:  make(map[string]int),
+		numbers:    make(map[string]string),
+		values:     make(map[string]string),
+		patterns:   make(map[string]string),
+	}
+
+	return c.compile(sch)
+}
+
+// frame locates a compiled schema.
+type frame struct {
+	schema  json.Document
+	uri     string // URI of the document holding the schema
+	pointer string // JSON pointer to the schema in its document
+	base    string // base URI to resolve references
+	version jsonschema.Version
+}
+
+func (f frame) location() string {
+	return f.uri + "#" + f.pointer
+}
+
+// since tells if the schema follows the semantics of some version of JSON schema, or a later one.
+func (f frame) since(version jsonschema.Version) bool {
+	return keywords.Since(f.version, version)
+}
+
+type compiler struct {
+	options
+
+	resolver   *jsonschema.Resolver
+	dollarData bool // "$data" references are enabled
+	program    Program
+	functions  map[string]int // index of functions by schema location
+	queue      []frame
+	numbers    map[string]string
+	values     map[string]string
+	patterns   map[string]string
+}
+
+func (c *compiler) compile(sch jsonschema.Schema) (Program, error) {
+	if err := c.resolver.AddDocument(c.baseURI, sch); err != nil {
+		return Program{}, fmt.Errorf("%w: %w", err, ErrCompile)
+	}
+
+	root, err := c.resolver.Resolve("#", c.baseURI)
+	if err != nil {
+		return Program{}, fmt.Errorf("%w: %w", err, ErrCompile)
+	}
+
+	c.program.Root = c.function(c.frameOf(root, c.version))
+
+	for i := 0; i < len(c.queue); i++ {
+		// compiling a function may declare new functions: the compiled function is updated when done
+		function := c.program.Functions[i]
+		if err := c.compileFunction(&function, c.queue[i]); err != nil {
+			return Program{}, err
+		}
+		c.program.Functions[i] = function
+	}
+
+	c.markEvaluated()
+
+	return c.program, nil
+}
+
+// markEvaluated sets the checks that record evaluated properties and items, when the program needs them.
+func (c *compiler) markEvaluated() {
+	for i := range c.program.Functions {
+		function := &c.program.Functions[i]
+		function.Marks = c.program.UsesMarks
+
+		for _, checks := range [][]Check{function.Arrays, function.Objects} {
+			for j := range checks {
+				switch checks[j].Kind {
+				case CheckItems, CheckProperties:
+					checks[j].Mark = c.program.UsesMarks
+				case CheckContains:
+					checks[j].Mark = checks[j].Mark && c.program.UsesMarks
+				}
+			}
+		}
+	}
+}
+
+func (c *compiler) frameOf(resolved jsonschema.ResolvedSchema, version jsonschema.Version) frame {
+	if declared := resolved.Version(); declared != jsonschema.VersionUndefined {
+		version = declared
+	}
+
+	return frame{
+		schema:  resolved.Document,
+		uri:     resolved.DocumentURI(),
+		pointer: resolved.Pointer(),
+		base:    resolved.BaseURI(),
+		version: version,
+	}
+}
+
+// function is the name of the function of a schema, which is compiled later on.
+func (c *compiler) function(f frame) string {
+	if index, ok := c.functions[f.location()]; ok {
+		return c.program.Functions[index].Name
+	}
+
+	index := len(c.program.Functions)
+	name := "validate" + strconv.Itoa(index)
+	c.functions[f.location()] = index
+	c.program.Functions = append(c.program.Functions, Function{Name: name, Location: f.location()})
+	c.queue = append(c.queue, f)
+
+	return name
+}
+
+// child frame for the subschema of a keyword, followed by some tokens.
+//
+// A subschema that declares an identifier gets a new base URI.
+func (c *compiler) child(f frame, schema json.Document, tokens ...string) frame {
+	child := frame{
+		schema:  schema,
+		uri:     f.uri,
+		pointer: f.pointer + suffix(tokens...),
+		base:    f.base,
+		version: f.version,
+	}
+
+	if !schema.IsObject() {
+		return child
+	}
+
+	_, hasID := schema.AtKey("$id")
+	if _, hasDraft4ID := schema.AtKey("id"); !hasID && !hasDraft4ID {
+		return child
+	}
+
+	if resolved, err := c.resolver.Resolve(keywords.FragmentRef(child.pointer), f.uri); err == nil {
+		child.base = resolved.BaseURI()
+	}
+
+	return child
+}
+
+// call of the function of a subschema.
+func (c *compiler) call(f frame, schema json.Document, tokens ...string) *Call {
+	return &Call{
+		Function: c.function(c.child(f, schema, tokens...)),
+		Suffix:   suffix(tokens...),
+	}
+}
+
+// check of a keyword of a schema.
+func (c *compiler) check(f frame, kind CheckKind, keyword string) Check {
+	return Check{
+		Kind:    kind,
+		Keyword: keyword,
+		Suffix:  suffix(keyword),
+		Schema:  f.location(),
+	}
+}
+
+// checkDataRefs refuses "$data" references, which are resolved from the validated data and cannot be compiled
+// into constants.
+func (c *compiler) checkDataRefs(f frame) error {
+	if !c.dollarData {
+		return nil
+	}
+
+	for keyword, value := range f.schema.Pairs() {
+		if jsonschema.IsDataRef(keyword, value) {
+			return fmt.Errorf("%q at %q is a $data reference: %w", keyword, f.location(), ErrUnsupported)
+		}
+	}
+
+	return nil
+}
+
+func (c *compiler) compileFunction(function *Function, f frame) error {
+	if f.schema.IsBool() {
+		value, _ := f.schema.Value()
+		function.Accepts = value.Bool()
+		function.Rejects = !value.Bool()
+
+		return nil
+	}
+
+	if !f.schema.IsObject() {
+		function.Accepts = true
+
+		return nil
+	}
+
+	if err := c.checkDataRefs(f); err != nil {
+		return err
+	}
+
+	references, err := c.compileReferences(f)
+	if err != nil {
+		return err
+	}
+	function.References = references
+
+	if _, hasRef := f.schema.AtKey("$ref"); hasRef && !f.since(jsonschema.VersionDraft2019) {
+		// keywords next to a "$ref" are ignored
+		return nil
+	}
+
+	function.Generic = c.compileGeneric(f)
+	function.Numbers = c.compileNumbers(f)
+
+	if function.Strings, err = c.compileStrings(f); err != nil {
+		return err
+	}
+
+	function.Arrays = c.compileArrays(f)
+	function.Objects = c.compileObjects(f)
+	function.Applicators = c.compileApplicators(f)
+
+	return nil
+}
+
+// compileReferences compiles "$ref", "$dynamicRef" and "$recursiveRef".
+func (c *compiler) compileReferences(f frame) ([]Check, error) {
+	var checks []Check
+
+	reference := func(keyword, anchor string) error {
+		ref, ok := keywords.StringAt(f.schema, keyword)
+		if !ok {
+			return nil
+		}
+
+		resolved, err := c.resolver.Resolve(ref, f.base)
+		if err != nil {
+			return fmt.Errorf("cannot resolve %q at %q: %w: %w", ref, f.location(), err, ErrCompile)
+		}
+
+		if anchor != "" {
+			if _, dynamic := resolved.Document.AtKey(anchor); dynamic {
+				return fmt.Errorf("%s %q at %q refers to a dynamic anchor: %w", keyword, ref, f.location(), ErrUnsupported)
+			}
+		}
+
+		check := c.check(f, CheckRef, keyword)
+		check.Call = &Call{
+			Function: c.function(c.frameOf(resolved, f.version)),
+			Suffix:   suffix(keyword),
+		}
+		checks = append(checks, check)
+
+		return nil
+	}
+
+	if err := reference("$ref", ""); err != nil {
+		return nil, err
+	}
+
+	if f.since(jsonschema.VersionDraft2020) {
+		if err := reference("$dynamicRef", "$dynamicAnchor"); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.version == jsonschema.VersionDraft2019 {
+		if err := reference("$recursiveRef", "$recursiveAnchor"); err != nil {
+			return nil, err
+		}
+	}
+
+	return checks, nil
+}
+
+// compileGeneric compiles "type", "enum" and "const".
+func (c *compiler) compileGeneric(f frame) []Check {
+	var checks []Check
+
+	if types, ok := f.schema.AtKey("type"); ok && (types.IsString() || types.IsArray()) {
+		check := c.check(f, CheckType, "type")
+		if types.IsString() {
+			check.Types = []string{types.String()}
+		} else {
+			for elem := range types.Elems() {
+				if elem.IsString() {
+					check.Types = append(check.Types, elem.String())
+				}
+			}
+		}
+		check.Message = "expected " + strings.Join(check.Types, " or ")
+		checks = append(checks, check)
+	}
+
+	if enum, ok := f.schema.AtKey("enum"); ok && enum.IsArray() {
+		check := c.check(f, CheckEnum, "enum")
+		for value := range enum.Elems() {
+			check.Constants = append(check.Constants, c.value(value))
+		}
+		checks = append(checks, check)
+	}
+
+	if value, ok := f.schema.AtKey("const"); ok && f.since(jsonschema.VersionDraft6) {
+		check := c.check(f, CheckConst, "const")
+		check.Constant = c.value(value)
+		check.Message = "the value is not equal to the constant " + keywords.Text(value)
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// compileNumbers compiles the keywords that apply to numbers.
+func (c *compiler) compileNumbers(f frame) []Check {
+	var checks []Check
+
+	if divisor, ok := keywords.RatAt(f.schema, "multipleOf"); ok && divisor.Sign() > 0 {
+		check := c.check(f, CheckMultipleOf, "multipleOf")
+		check.Constant = c.number(divisor)
+		check.Message = " is not a multiple of " + divisor.RatString()
+		checks = append(checks, check)
+	}
+
+	bound := func(keyword string, exclusive bool, sign int) {
+		value, ok := keywords.RatAt(f.schema, keyword)
+		if !ok {
+			return
+		}
+
+		check := c.check(f, CheckBound, keyword)
+		check.Constant = c.number(value)
+
+		relation := map[int]string{1: "greater than", -1: "less than"}[sign]
+		operator := map[int]string{1: "<", -1: ">"}[sign]
+		if exclusive {
+			operator += "="
+		} else {
+			relation += " or equal to"
+		}
+		check.Operator = operator + " 0"
+		check.Message = " is not " + relation + " " + value.RatString()
+		checks = append(checks, check)
+	}
+
+	if f.since(jsonschema.VersionDraft6) {
+		bound("minimum", false, 1)
+		bound("maximum", false, -1)
+		bound("exclusiveMinimum", true, 1)
+		bound("exclusiveMaximum", true, -1)
+	} else {
+		// draft 4: "exclusiveMinimum" and "exclusiveMaximum" are booleans that qualify "minimum" and "maximum"
+		bound("minimum", keywords.IsTrue(f.schema, "exclusiveMinimum"), 1)
+		bound("maximum", keywords.IsTrue(f.schema, "exclusiveMaximum"), -1)
+	}
+
+	return checks
+}
+
+// compileStrings compiles the keywords that apply to strings.
+func (c *compiler) compileStrings(f frame) ([]Check, error) {
+	var checks []Check
+
+	for _, keyword := range []struct {
+		name string
+		kind CheckKind
+	}{
+		{name: "minLength", kind: CheckMinLength},
+		{name: "maxLength", kind: CheckMaxLength},
+	} {
+		if value, ok := keywords.IntAt(f.schema, keyword.name); ok {
+			check := c.check(f, keyword.kind, keyword.name)
+			check.Integer = value
+			checks = append(checks, check)
+		}
+	}
+
+	if pattern, ok := keywords.StringAt(f.schema, "pattern"); ok {
+		name, err := c.pattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf(`invalid "pattern" at %q: %w: %w`, f.location(), err, ErrCompile)
+		}
+
+		check := c.check(f, CheckPattern, "pattern")
+		check.Constant = name
+		check.Text = pattern
+		checks = append(checks, check)
+	}
+
+	if format, ok := keywords.StringAt(f.schema, "format"); ok {
+		check := c.check(f, CheckFormat, "format")
+		check.Text = format
+		checks = append(checks, check)
+
+		if !slices.Contains(c.program.Formats, format) {
+			c.program.Formats = append(c.program.Formats, format)
+		}
+	}
+
+	return checks, nil
+}
+
+// compileArrays compiles the keywords that apply to arrays.
+func (c *compiler) compileArrays(f frame) []Check {
+	var checks []Check
+
+	for _, keyword := range []struct {
+		name string
+		kind CheckKind
+	}{
+		{name: "minItems", kind: CheckMinItems},
+		{name: "maxItems", kind: CheckMaxItems},
+	} {
+		if value, ok := keywords.IntAt(f.schema, keyword.name); ok {
+			check := c.check(f, keyword.kind, keyword.name)
+			check.Integer = value
+			checks = append(checks, check)
+		}
+	}
+
+	if keywords.IsTrue(f.schema, "uniqueItems") {
+		checks = append(checks, c.check(f, CheckUniqueItems, "uniqueItems"))
+	}
+
+	if check, ok := c.compileItems(f); ok {
+		checks = append(checks, check)
+	}
+
+	if contains, ok := f.schema.AtKey("contains"); ok && f.since(jsonschema.VersionDraft6) {
+		check := c.check(f, CheckContains, "contains")
+		check.Call = c.call(f, contains, "contains")
+		check.Minimum = 1
+		if f.since(jsonschema.VersionDraft2019) {
+			if value, ok := keywords.IntAt(f.schema, "minContains"); ok {
+				check.Minimum = value
+			}
+			check.Maximum, check.HasMaximum = keywords.IntAt(f.schema, "maxContains")
+		}
+
+		// since draft 2020, items matched by "contains" are evaluated
+		check.Mark = f.since(jsonschema.VersionDraft2020)
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// compileItems compiles "prefixItems", "items" and "additionalItems".
+func (c *compiler) compileItems(f frame) (Check, bool) {
+	check := c.check(f, CheckItems, "items")
+
+	if prefix, ok := f.schema.AtKey("prefixItems"); ok && prefix.IsArray() && f.since(jsonschema.VersionDraft2020) {
+		for i, schema := range prefix.IndexedElems() {
+			check.Prefix = append(check.Prefix, *c.call(f, schema, "prefixItems", strconv.Itoa(i)))
+		}
+	}
+
+	items, hasItems := f.schema.AtKey("items")
+	switch {
+	case !hasItems:
+	case items.IsArray() && !f.since(jsonschema.VersionDraft2020):
+		for i, schema := range items.IndexedElems() {
+			check.Prefix = append(check.Prefix, *c.call(f, schema, "items", strconv.Itoa(i)))
+		}
+
+		if additional, ok := f.schema.AtKey("additionalItems"); ok {
+			check.Rest = c.call(f, additional, "additionalItems")
+		}
+	case !items.IsArray():
+		check.Rest = c.call(f, items, "items")
+	}
+
+	return check, len(check.Prefix) > 0 || check.Rest != nil
+}
+
+// compileObjects compiles the keywords that apply to objects.
+func (c *compiler) compileObjects(f frame) []Check {
+	var checks []Check
+
+	for _, keyword := range []struct {
+		name string
+		kind CheckKind
+	}{
+		{name: "minProperties", kind: CheckMinProperties},
+		{name: "maxProperties", kind: CheckMaxProperties},
+	} {
+		if value, ok := keywords.IntAt(f.schema, keyword.name); ok {
+			check := c.check(f, keyword.kind, keyword.name)
+			check.Integer = value
+			checks = append(checks, check)
+		}
+	}
+
+	if required, ok := f.schema.AtKey("required"); ok && required.IsArray() {
+		check := c.check(f, CheckRequired, "required")
+		for name := range required.Elems() {
+			if name.IsString() {
+				check.Names = append(check.Names, name.String())
+			}
+		}
+		checks = append(checks, check)
+	}
+
+	if check, ok := c.compileProperties(f); ok {
+		checks = append(checks, check)
+	}
+
+	if schema, ok := f.schema.AtKey("propertyNames"); ok && f.since(jsonschema.VersionDraft6) {
+		check := c.check(f, CheckPropertyNames, "propertyNames")
+		check.Call = c.call(f, schema, "propertyNames")
+		checks = append(checks, check)
+	}
+
+	if check := c.compileDependencies(f); len(check.Dependencies) > 0 {
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// compileProperties compiles "properties", "patternProperties" and "additionalProperties".
+func (c *compiler) compileProperties(f frame) (Check, bool) {
+	properties, hasProperties := f.schema.AtKey("properties")
+	patterns, hasPatterns := f.schema.AtKey("patternProperties")
+	additional, hasAdditional := f.schema.AtKey("additionalProperties")
+	if !hasProperties && !hasPatterns && !hasAdditional {
+		return Check{}, false
+	}
+
+	check := c.check(f, CheckProperties, "properties")
+
+	if hasProperties && properties.IsObject() {
+		for name, schema := range properties.Pairs() {
+			if slices.ContainsFunc(check.Properties, func(p PropertyCall) bool { return p.Name == name }) {
+				continue
+			}
+
+			check.Properties = append(check.Properties, PropertyCall{
+				Name: name,
+				Call: *c.call(f, schema, "properties", name),
+			})
+		}
+	}
+
+	if hasPatterns && patterns.IsObject() {
+		for pattern, schema := range patterns.Pairs() {
+			name, err := c.pattern(pattern)
+			if err != nil {
+				// like the interpreted validator, properties are not checked against invalid patterns
+				continue
+			}
+
+			check.Patterns = append(check.Patterns, PatternCall{
+				Constant: name,
+				Call:     *c.call(f, schema, "patternProperties", pattern),
+			})
+		}
+	}
+
+	if hasAdditional {
+		check.Additional = c.call(f, additional, "additionalProperties")
+	}
+
+	return check, len(check.Properties) > 0 || len(check.Patterns) > 0 || check.Additional != nil
+}
+
+// compileDependencies compiles "dependentRequired", "dependentSchemas" and their draft 4 to 7 counterpart,
+// "dependencies".
+func (c *compiler) compileDependencies(f frame) Check {
+	check := c.check(f, CheckDependencies, "dependencies")
+
+	compile := func(keyword string, allowRequired, allowSchemas bool) {
+		dependencies, ok := f.schema.AtKey(keyword)
+		if !ok || !dependencies.IsObject() {
+			return
+		}
+
+		for name, dependency := range dependencies.Pairs() {
+			switch {
+			case dependency.IsArray() && allowRequired:
+				var required []string
+				for elem := range dependency.Elems() {
+					if elem.IsString() {
+						required = append(required, elem.String())
+					}
+				}
+
+				check.Dependencies = append(check.Dependencies, Dependency{Keyword: keyword, Name: name, Required: required})
+			case !dependency.IsArray() && allowSchemas:
+				check.Dependencies = append(check.Dependencies, Dependency{
+					Keyword: keyword,
+					Name:    name,
+					Call:    c.call(f, dependency, keyword, name),
+				})
+			}
+		}
+	}
+
+	if f.since(jsonschema.VersionDraft2019) {
+		compile("dependentRequired", true, false)
+		compile("dependentSchemas", false, true)
+	} else {
+		compile("dependencies", true, true)
+	}
+
+	return check
+}
+
+// compileApplicators compiles "allOf", "anyOf", "oneOf", "not", "if", "then", "else",
+// "unevaluatedProperties" and "unevaluatedItems".
+func (c *compiler) compileApplicators(f frame) []Check {
+	var checks []Check
+
+	for _, keyword := range []struct {
+		name string
+		kind CheckKind
+	}{
+		{name: "allOf", kind: CheckAllOf},
+		{name: "anyOf", kind: CheckAnyOf},
+		{name: "oneOf", kind: CheckOneOf},
+	} {
+		members, ok := f.schema.AtKey(keyword.name)
+		if !ok || !members.IsArray() {
+			continue
+		}
+
+		check := c.check(f, keyword.kind, keyword.name)
+		for i, schema := range members.IndexedElems() {
+			check.Calls = append(check.Calls, *c.call(f, schema, keyword.name, strconv.Itoa(i)))
+		}
+		checks = append(checks, check)
+	}
+
+	if schema, ok := f.schema.AtKey("not"); ok {
+		check := c.check(f, CheckNot, "not")
+		check.Call = c.call(f, schema, "not")
+		checks = append(checks, check)
+	}
+
+	if condition, ok := f.schema.AtKey("if"); ok && f.since(jsonschema.VersionDraft7) {
+		check := c.check(f, CheckConditional, "if")
+		check.If = c.call(f, condition, "if")
+		if schema, ok := f.schema.AtKey("then"); ok {
+			check.Then = c.call(f, schema, "then")
+		}
+		if schema, ok := f.schema.AtKey("else"); ok {
+			check.Else = c.call(f, schema, "else")
+		}
+		checks = append(checks, check)
+	}
+
+	if !f.since(jsonschema.VersionDraft2019) {
+		return checks
+	}
+
+	for _, keyword := range []struct {
+		name string
+		kind CheckKind
+	}{
+		{name: "unevaluatedProperties", kind: CheckUnevaluatedProperties},
+		{name: "unevaluatedItems", kind: CheckUnevaluatedItems},
+	} {
+		if schema, ok := f.schema.AtKey(keyword.name); ok {
+			check := c.check(f, keyword.kind, keyword.name)
+			check.Call = c.call(f, schema, keyword.name)
+			checks = append(checks, check)
+			c.program.UsesMarks = true
+		}
+	}
+
+	return checks
+}
+
+// number constant.
+func (c *compiler) number(value *big.Rat) string {
+	text := value.RatString()
+	if name, ok := c.numbers[text]; ok {
+		return name
+	}
+
+	name := "number" + strconv.Itoa(len(c.program.Numbers))
+	c.numbers[text] = name
+	c.program.Numbers = append(c.program.Numbers, Constant{Name: name, Value: text})
+
+	return name
+}
+
+// value constant.
+func (c *compiler) value(value json.Document) string {
+	text := keywords.Text(value)
+	if name, ok := c.values[text]; ok {
+		return name
+	}
+
+	name := "value" + strconv.Itoa(len(c.program.Values))
+	c.values[text] = name
+	c.program.Values = append(c.program.Values, Constant{Name: name, Value: text})
+
+	return name
+}
+
+// pattern constant, checked at compile time.
+func (c *compiler) pattern(pattern string) (string, error) {
+	if name, ok := c.patterns[pattern]; ok {
+		return name, nil
+	}
+
+	if _, err := regexp.Compile(pattern); err != nil {
+		return "", err
+	}
+
+	name := "pattern" + strconv.Itoa(len(c.program.Patterns))
+	c.patterns[pattern] = name
+	c.program.Patterns = append(c.program.Patterns, Constant{Name: name, Value: pattern})
+
+	return name, nil
+}
+
+// suffix is a JSON pointer made of some tokens.
+func suffix(tokens ...string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString("/")
+		b.WriteString(json.EscapeToken(token))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package validations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/jsonschema"
+)
+
+func TestCompile(t *testing.T) {
+	t.Run("should compile a schema into functions", func(t *testing.T) {
+		const schema = `{
+			"$defs": {"name": {"type": "string", "pattern": "^[a-z]+$"}},
+			"type": "object",
+			"properties": {"name": {"$ref": "#/$defs/name"}, "alias": {"$ref": "#/$defs/name"}},
+			"minimum": 1.5,
+			"enum": [{"a": 1}, 2]
+		}`
+
+		program, err := New(WithBaseURI("https://example.com/schema.json")).Compile(mustSchema(t, schema))
+		require.NoError(t, err)
+
+		assert.Equal(t, "validate0", program.Root)
+		require.Len(t, program.Functions, 4)
+
+		locations := make([]string, 0, len(program.Functions))
+		for _, function := range program.Functions {
+			locations = append(locations, function.Location)
+		}
+		assert.Equal(t, []string{
+			"https://example.com/schema.json#",
+			"https://example.com/schema.json#/properties/name",
+			"https://example.com/schema.json#/properties/alias",
+			"https://example.com/schema.json#/$defs/name",
+		}, locations)
+
+		root := program.Functions[0]
+		require.Len(t, root.Numbers, 1)
+		assert.Equal(t, "< 0", root.Numbers[0].Operator)
+		assert.Equal(t, " is not greater than or equal to 3/2", root.Numbers[0].Message)
+		assert.Equal(t, []Constant{{Name: "number0", Value: "3/2"}}, program.Numbers)
+		assert.Equal(t, []Constant{{Name: "value0", Value: `{"a":1}`}, {Name: "value1", Value: "2"}}, program.Values)
+		assert.Equal(t, []Constant{{Name: "pattern0", Value: "^[a-z]+$"}}, program.Patterns)
+
+		require.Len(t, root.Objects, 1)
+		properties := root.Objects[0]
+		assert.True(t, properties.Is("properties"))
+		assert.Equal(t, "/properties/name", properties.Properties[0].Suffix)
+		assert.False(t, program.UsesMarks)
+	})
+
+	t.Run("should track evaluated properties and items with unevaluated keywords", func(t *testing.T) {
+		program, err := New().Compile(mustSchema(t, `{"prefixItems": [true], "contains": true, "unevaluatedItems": false}`))
+		require.NoError(t, err)
+
+		assert.True(t, program.UsesMarks)
+		for _, check := range program.Functions[0].Arrays {
+			assert.True(t, check.Mark, check.Kind.String())
+		}
+	})
+
+	t.Run("should refuse dynamic anchors", func(t *testing.T) {
+		_, err := New().Compile(mustSchema(t, `{"$dynamicAnchor": "node", "items": {"$dynamicRef": "#node"}}`))
+		require.ErrorIs(t, err, ErrUnsupported)
+	})
+
+	t.Run("should refuse $data references", func(t *testing.T) {
+		sch := jsonschema.Make(jsonschema.WithDollarData(true))
+		require.NoError(t, sch.UnmarshalJSON([]byte(`{"properties": {"a": {"maximum": {"$data": "1/b"}}}}`)))
+
+		_, err := New().Compile(sch)
+		require.ErrorIs(t, err, ErrUnsupported)
+		require.ErrorContains(t, err, "$data")
+
+		_, err = New().Compile(mustSchema(t, `{"const": {"$data": "1/b"}}`))
+		require.NoError(t, err, "a constant object when $data is disabled")
+	})
+
+	t.Run("should fail on invalid patterns and unresolved references", func(t *testing.T) {
+		_, err := New().Compile(mustSchema(t, `{"pattern": "("}`))
+		require.ErrorIs(t, err, ErrCompile)
+
+		_, err = New().Compile(mustSchema(t, `{"$ref": "#/$defs/missing"}`))
+		require.ErrorIs(t, err, ErrCompile)
+	})
+}
+
+func mustSchema(t *testing.T, input string) jsonschema.Schema {
+	t.Helper()
+
+	s := jsonschema.Make()
+	require.NoError(t, s.UnmarshalJSON([]byte(input)))
+
+	return s
+}
@@ -0,0 +1,16 @@
+package validations
+
+// Error is an error raised by the validations [Analyzer].
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrCompile is raised when a schema cannot be compiled into a [Program].
+	ErrCompile Error = "validations compile error"
+
+	// ErrUnsupported is raised when a schema uses a feature that cannot be compiled into a [Program].
+	ErrUnsupported Error = "unsupported by the validations compiler"
+)
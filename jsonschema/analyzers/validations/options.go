@@ -0,0 +1,52 @@
+package validations
+
+import "github.com/fredbi/core/jsonschema"
+
+// Option customizes the behavior of the validations [Analyzer].
+type Option func(*options)
+
+type options struct {
+	resolver *jsonschema.Resolver
+	baseURI  string
+	version  jsonschema.Version
+}
+
+// WithResolver equips the [Analyzer] with a [jsonschema.Resolver] to resolve "$ref" s.
+//
+// By default, a new [jsonschema.Resolver] is used for every compilation.
+func WithResolver(resolver *jsonschema.Resolver) Option {
+	return func(o *options) {
+		o.resolver = resolver
+	}
+}
+
+// WithBaseURI sets the URI of the compiled schema, against which relative "$ref" s are resolved.
+//
+// The default is "schema.json".
+func WithBaseURI(uri string) Option {
+	return func(o *options) {
+		o.baseURI = uri
+	}
+}
+
+// WithDefaultVersion sets the version of JSON schema assumed for schemas that don't declare one.
+//
+// By default, the latest version is assumed.
+func WithDefaultVersion(version jsonschema.Version) Option {
+	return func(o *options) {
+		o.version = version
+	}
+}
+
+func optionsWithDefaults(opts []Option) options {
+	o := options{
+		baseURI: "schema.json",
+		version: jsonschema.VersionDraft2020,
+	}
+
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	return o
+}
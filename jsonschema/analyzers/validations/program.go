@@ -0,0 +1,275 @@
+package validations
+
+// Program is a schema compiled into a flat list of validation [Function] s, with all checks unrolled.
+//
+// A [Program] is intended to be rendered as source code by a code generator: it is made of plain data,
+// suitable for templates. Every subschema that is met becomes a [Function], and every keyword becomes a [Check]
+// which refers to constants, such as numbers, JSON values or regular expressions, that are evaluated once.
+//
+// The keyword locations of violations are rebuilt at runtime: they follow the references evaluated from the root
+// schema, like a [github.com/fredbi/core/jsonschema/validator.Validator] does.
+type Program struct {
+	// Root is the name of the function that validates the root schema.
+	Root string
+
+	Functions []Function
+
+	// Numbers are numerical constants, as rational numbers.
+	Numbers []Constant
+
+	// Values are JSON constants, e.g. the values of "enum" or "const".
+	Values []Constant
+
+	// Patterns are regular expressions.
+	Patterns []Constant
+
+	// Formats lists the formats asserted by "format".
+	Formats []string
+
+	// UsesMarks tells if evaluated properties and items are tracked, as needed by
+	// "unevaluatedProperties" and "unevaluatedItems".
+	UsesMarks bool
+}
+
+// Constant is a named constant of a [Program].
+type Constant struct {
+	Name string
+
+	// Value is the text of the constant: a number, some JSON or a regular expression.
+	Value string
+}
+
+// Function validates some data against a schema.
+type Function struct {
+	Name string
+
+	// Location is the absolute location of the schema, as a URI with a JSON pointer fragment.
+	Location string
+
+	// Accepts is true for a schema that accepts any value, such as the true schema.
+	Accepts bool
+
+	// Rejects is true for the false schema.
+	Rejects bool
+
+	// Checks are grouped by the type of data they apply to, in the order they are evaluated.
+	References  []Check
+	Generic     []Check // "type", "enum" and "const"
+	Numbers     []Check
+	Strings     []Check
+	Arrays      []Check
+	Objects     []Check
+	Applicators []Check
+
+	// Marks tells if the function tracks evaluated properties and items (see [Program.UsesMarks]).
+	Marks bool
+}
+
+// Call invokes a [Function] for a subschema.
+type Call struct {
+	Function string
+
+	// Suffix is the keyword location of the subschema, relative to the calling schema,
+	// e.g. "/properties/name" or "/$ref".
+	Suffix string
+}
+
+// CheckKind is the kind of a [Check].
+type CheckKind uint8
+
+const (
+	CheckRef CheckKind = iota + 1
+	CheckType
+	CheckEnum
+	CheckConst
+	CheckMultipleOf
+	CheckBound
+	CheckMinLength
+	CheckMaxLength
+	CheckPattern
+	CheckFormat
+	CheckMinItems
+	CheckMaxItems
+	CheckUniqueItems
+	CheckItems
+	CheckContains
+	CheckMinProperties
+	CheckMaxProperties
+	CheckRequired
+	CheckProperties
+	CheckPropertyNames
+	CheckDependencies
+	CheckAllOf
+	CheckAnyOf
+	CheckOneOf
+	CheckNot
+	CheckConditional
+	CheckUnevaluatedProperties
+	CheckUnevaluatedItems
+)
+
+func (k CheckKind) String() string {
+	switch k {
+	case CheckRef:
+		return "ref"
+	case CheckType:
+		return "type"
+	case CheckEnum:
+		return "enum"
+	case CheckConst:
+		return "const"
+	case CheckMultipleOf:
+		return "multipleOf"
+	case CheckBound:
+		return "bound"
+	case CheckMinLength:
+		return "minLength"
+	case CheckMaxLength:
+		return "maxLength"
+	case CheckPattern:
+		return "pattern"
+	case CheckFormat:
+		return "format"
+	case CheckMinItems:
+		return "minItems"
+	case CheckMaxItems:
+		return "maxItems"
+	case CheckUniqueItems:
+		return "uniqueItems"
+	case CheckItems:
+		return "items"
+	case CheckContains:
+		return "contains"
+	case CheckMinProperties:
+		return "minProperties"
+	case CheckMaxProperties:
+		return "maxProperties"
+	case CheckRequired:
+		return "required"
+	case CheckProperties:
+		return "properties"
+	case CheckPropertyNames:
+		return "propertyNames"
+	case CheckDependencies:
+		return "dependencies"
+	case CheckAllOf:
+		return "allOf"
+	case CheckAnyOf:
+		return "anyOf"
+	case CheckOneOf:
+		return "oneOf"
+	case CheckNot:
+		return "not"
+	case CheckConditional:
+		return "conditional"
+	case CheckUnevaluatedProperties:
+		return "unevaluatedProperties"
+	case CheckUnevaluatedItems:
+		return "unevaluatedItems"
+	default:
+		return "unknown"
+	}
+}
+
+// Check is a compiled keyword, or a group of related keywords.
+//
+// Only the fields that are relevant to the [CheckKind] are set.
+type Check struct {
+	Kind CheckKind
+
+	// Keyword is the checked keyword.
+	Keyword string
+
+	// Suffix is the keyword location of the keyword, relative to its schema.
+	Suffix string
+
+	// Schema is the absolute location of the schema of the keyword, as a URI with a JSON pointer fragment.
+	//
+	// The absolute location of the keyword is Schema + Suffix.
+	Schema string
+
+	// Message is the static part of the message of a violation.
+	Message string
+
+	// Operator compares a value to a bound, and tells when the bound is violated, e.g. "< 0".
+	Operator string
+
+	// Constant is the name of a [Constant] used by the check.
+	Constant string
+
+	// Constants are the names of several [Constant] s used by the check, e.g. by "enum".
+	Constants []string
+
+	// Types are the types allowed by "type".
+	Types []string
+
+	// Text is some text rendered in messages, e.g. a format or a pattern.
+	Text string
+
+	// Integer is the integer value of keywords such as "minLength".
+	Integer int64
+
+	// Minimum and Maximum are the bounds of "contains".
+	Minimum    int64
+	Maximum    int64
+	HasMaximum bool
+
+	// Names are the names of the properties of "required".
+	Names []string
+
+	// Call is the subschema of a single-schema applicator.
+	Call *Call
+
+	// Calls are the subschemas of an applicator of several schemas, such as "allOf".
+	Calls []Call
+
+	// Prefix are the subschemas of the leading items of an array, and Rest the subschema of other items.
+	Prefix []Call
+	Rest   *Call
+
+	// Properties, Patterns and Additional are the subschemas of the properties of an object.
+	Properties []PropertyCall
+	Patterns   []PatternCall
+	Additional *Call
+
+	// Dependencies are the dependencies of the properties of an object.
+	Dependencies []Dependency
+
+	// If, Then and Else are the subschemas of a conditional.
+	If   *Call
+	Then *Call
+	Else *Call
+
+	// Mark tells if the check records the properties or items it evaluates.
+	Mark bool
+}
+
+// Is tells if the check is of some kind, given by name (see [CheckKind.String]).
+func (c Check) Is(kind string) bool {
+	return c.Kind.String() == kind
+}
+
+// PropertyCall is the subschema of a named property.
+type PropertyCall struct {
+	Name string
+	Call
+}
+
+// PatternCall is the subschema of the properties that match a regular expression.
+type PatternCall struct {
+	// Constant is the name of the compiled regular expression.
+	Constant string
+	Call
+}
+
+// Dependency of a property, as declared by "dependentRequired", "dependentSchemas" or "dependencies".
+type Dependency struct {
+	Keyword string
+
+	// Name of the property that has dependencies.
+	Name string
+
+	// Required are the properties required by the property, and Call the schema applied when it is present.
+	Required []string
+	Call     *Call
+}
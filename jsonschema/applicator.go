@@ -205,12 +205,7 @@ func (s Applicator) IfThenElse() (ifSchema Schema, thenSchema Schema, elseSchema
 	return
 }
 
-func (s *Applicator) decode(ctx *light.ParentContext, key values.InternedKey, vr *VersionRequirements) error {
-	octx, ok := ctx.X.(*schemaContext)
-	if !ok {
-		panic("bug")
-	}
-
+func (s *Applicator) decode(_ *light.ParentContext, key values.InternedKey, _ light.Node, _ *VersionRequirements) error {
 	switch key {
 	case additionalPropertiesKey:
 	case allOfKey:
@@ -1,20 +1,51 @@
 package jsonschema
 
 import (
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+
 	"github.com/fredbi/core/json"
 	"github.com/fredbi/core/json/nodes/light"
+	"github.com/fredbi/core/json/stores"
+	"github.com/fredbi/core/json/stores/values"
 )
 
 // Builder constructs a [Schema].
 //
-// TODO
+// You may either use it directly, starting from the empty schema, or clone from an existing [Schema]
+// using [Builder.From].
+//
+// The [Builder] exposes fluent building methods covering the JSON schema draft 2020-12 vocabulary,
+// which may be chained to construct a [Schema] programmatically. Subschemas are passed as [Schema] s,
+// e.g. produced by another [Builder].
+//
+// Every keyword is checked against the version of the built schema, i.e. the version enforced
+// with [WithVersion] or declared with [Builder.WithSchemaVersion]. Keywords that this version does not support
+// yield an error wrapping [ErrVersion] (see [VersionRequirements]). If the version is undefined,
+// the requirements of the built schema may be retrieved with [Builder.VersionRequirements].
+//
+// Schemas are built with the [stores.Store] of the [Builder]: subschemas from other stores are copied.
+//
+// Since a [Schema] is immutable, the [Builder] always produces a shallow clone of the original [Schema]:
+// further building does not alter the schemas produced so far.
+//
+// You should always check the final error state, since the building ceases to be effective
+// as soon as an error is encountered.
 type Builder struct {
-	err error
-	sch Schema
+	err     error
+	sch     Schema
+	options *options
 }
 
-func NewBuilder() *Builder {
-	return &Builder{}
+// NewBuilder produces a [Builder] of [Schema] s, starting from the empty schema "{}".
+//
+// The options apply to all the schemas produced by the [Builder].
+func NewBuilder(opts ...Option) *Builder {
+	b := (&Builder{}).From(Make(opts...))
+
+	return b.rebuild(json.NewBuilder(b.Store()).Object().Document(), false)
 }
 
 func (b Builder) Err() error {
@@ -25,46 +56,719 @@ func (b Builder) Ok() bool {
 	return b.err == nil
 }
 
+// Schema returns the [Schema] produced by the [Builder].
 func (b Builder) Schema() Schema {
 	return b.sch
 }
 
+// Store shared by the [Schema] s produced by the [Builder].
+func (b Builder) Store() stores.Store {
+	return b.sch.Store()
+}
+
+// VersionRequirements of the built schema, i.e. the versions of JSON schema that support all its keywords,
+// including the keywords of its subschemas.
+func (b Builder) VersionRequirements() VersionRequirements {
+	var requirements VersionRequirements
+	_ = checkVersions(b.sch.Document, VersionUndefined, &requirements)
+
+	return requirements
+}
+
+// From makes a builder that will clone a [Schema], possibly with mutations.
+//
+// The options of the [Schema] apply to the schemas produced by the [Builder].
 func (b *Builder) From(sch Schema) *Builder {
+	o := optionsWithDefaults(nil)
+	if sch.options != nil {
+		*o = *sch.options
+	}
+
+	if sch.Store() == nil {
+		sch = Make(withOptions(o))
+	}
+
+	o.documentOptions = append(slices.Clip(o.documentOptions), json.WithStore(sch.Store()))
+	b.options = o
 	b.sch = sch
+	b.err = nil
 
 	return b
 }
 
+// WithRoot makes a builder that will clone the [Schema] rooted at a node of the [Builder] 's store.
 func (b *Builder) WithRoot(root light.Node) *Builder {
-	// TODO
-	return b
+	b.err = nil
+
+	return b.rebuild(json.NewBuilder(b.Store()).WithRoot(root).Document(), false)
 }
 
 // AtPointer replaces a value at the location pointed at.
+//
+// No replacement is made if the [json.Pointer] is not found.
 func (b *Builder) AtPointer(p json.Pointer, value json.Document) *Builder {
-	return b // TODO
+	return b.updateDocument(func(jb *json.Builder) *json.Builder {
+		return jb.AtPointer(p, value)
+	})
 }
 
+// AtPointerMerge merges a value at the location pointed at.
+//
+// See [json.Builder.AtPointerMerge] for the merge rules.
 func (b *Builder) AtPointerMerge(p json.Pointer, value json.Document) *Builder {
-	return b // TODO
+	return b.updateDocument(func(jb *json.Builder) *json.Builder {
+		return jb.AtPointerMerge(p, value)
+	})
+}
+
+// AtPointerRemove removes the value at the location pointed at.
+func (b *Builder) AtPointerRemove(p json.Pointer) *Builder {
+	return b.updateDocument(func(jb *json.Builder) *json.Builder {
+		return jb.AtPointerRemove(p)
+	})
+}
+
+// Bool replaces the schema by a boolean schema (>= draft 6).
+//
+// Keywords added afterwards replace the boolean schema by an object schema.
+func (b *Builder) Bool(value bool) *Builder {
+	if !b.Ok() {
+		return b
+	}
+
+	return b.rebuild(json.NewBuilder(b.Store()).BoolValue(value).Document(), true)
+}
+
+// WithSchemaVersion declares the version of JSON schema with the "$schema" keyword.
+//
+// It should be called first, so keywords are checked against this version.
+func (b *Builder) WithSchemaVersion(version Version) *Builder {
+	url := version.MetaSchemaURL()
+	if url == "" {
+		return b.fail(fmt.Errorf("no meta-schema is defined for version %v: %w", version, ErrSchema))
+	}
+
+	return b.setKeyword("$schema", b.stringValue(url))
+}
+
+// WithID sets the identifier of the schema, i.e. "$id" (or "id" for schemas prior to draft 6).
+func (b *Builder) WithID(id string) *Builder {
+	key := "$id"
+	if v := b.sch.Version(); v != VersionUndefined && v.Less(VersionDraft6) {
+		key = "id"
+	}
+
+	return b.setKeyword(key, b.stringValue(id))
+}
+
+// WithAnchor sets the "$anchor" keyword (>= draft 2019).
+func (b *Builder) WithAnchor(anchor string) *Builder {
+	return b.setKeyword("$anchor", b.stringValue(anchor))
+}
+
+// WithDynamicAnchor sets the "$dynamicAnchor" keyword (>= draft 2020).
+func (b *Builder) WithDynamicAnchor(anchor string) *Builder {
+	return b.setKeyword("$dynamicAnchor", b.stringValue(anchor))
+}
+
+// WithRef sets the "$ref" keyword.
+func (b *Builder) WithRef(ref string) *Builder {
+	return b.setKeyword("$ref", b.stringValue(ref))
+}
+
+// WithDynamicRef sets the "$dynamicRef" keyword (>= draft 2020).
+func (b *Builder) WithDynamicRef(ref string) *Builder {
+	return b.setKeyword("$dynamicRef", b.stringValue(ref))
+}
+
+// WithDef adds a named definition to "$defs" (or "definitions" for schemas prior to draft 2019).
+func (b *Builder) WithDef(name string, sch Schema) *Builder {
+	key := "$defs"
+	if v := b.sch.Version(); v != VersionUndefined && v.Less(VersionDraft2019) {
+		key = "definitions"
+	}
+
+	return b.setMember(key, name, b.schemaValue(sch))
 }
 
-func (b *Builder) WithProperties(properties []Schema) *Builder {
-	return b // TODO
+// WithComment sets the "$comment" keyword (>= draft 7).
+func (b *Builder) WithComment(comment string) *Builder {
+	return b.setKeyword("$comment", b.stringValue(comment))
 }
 
+// WithTypes sets the "type" keyword from the names of simple types, e.g. "string", "null".
 func (b *Builder) WithTypes(types []string) *Builder {
-	return b // TODO
+	for _, name := range types {
+		if !isSimpleType(name) {
+			return b.fail(fmt.Errorf("invalid type %q: %w", name, ErrSchema))
+		}
+	}
+
+	if len(types) == 1 {
+		return b.setKeyword("type", b.stringValue(types[0]))
+	}
+
+	return b.setKeyword("type", b.stringsValue(types))
+}
+
+// WithType sets the "type" keyword.
+func (b *Builder) WithType(types ...SchemaType) *Builder {
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		if t > SchemaTypeBool {
+			return b.fail(fmt.Errorf("invalid type %v: %w", t, ErrSchema))
+		}
+
+		names = append(names, t.String())
+	}
+
+	return b.WithTypes(names)
+}
+
+// WithEnum sets the "enum" keyword.
+func (b *Builder) WithEnum(values ...json.Document) *Builder {
+	return b.setKeyword("enum", b.arrayValue(values...))
+}
+
+// WithConst sets the "const" keyword (>= draft 6).
+func (b *Builder) WithConst(value json.Document) *Builder {
+	return b.setKeyword("const", value)
+}
+
+// WithMultipleOf sets the "multipleOf" keyword. The value may be any go numerical type.
+func (b *Builder) WithMultipleOf(value any) *Builder {
+	return b.setKeyword("multipleOf", b.numberValue(value))
+}
+
+// WithMaximum sets the "maximum" keyword. The value may be any go numerical type.
+func (b *Builder) WithMaximum(value any) *Builder {
+	return b.setKeyword("maximum", b.numberValue(value))
+}
+
+// WithExclusiveMaximum sets the "exclusiveMaximum" keyword as a number (>= draft 6).
+func (b *Builder) WithExclusiveMaximum(value any) *Builder {
+	return b.setKeyword("exclusiveMaximum", b.numberValue(value))
+}
+
+// WithMinimum sets the "minimum" keyword. The value may be any go numerical type.
+func (b *Builder) WithMinimum(value any) *Builder {
+	return b.setKeyword("minimum", b.numberValue(value))
+}
+
+// WithExclusiveMinimum sets the "exclusiveMinimum" keyword as a number (>= draft 6).
+func (b *Builder) WithExclusiveMinimum(value any) *Builder {
+	return b.setKeyword("exclusiveMinimum", b.numberValue(value))
+}
+
+// WithMaxLength sets the "maxLength" keyword.
+func (b *Builder) WithMaxLength(value int) *Builder {
+	return b.setKeyword("maxLength", b.countValue("maxLength", value))
+}
+
+// WithMinLength sets the "minLength" keyword.
+func (b *Builder) WithMinLength(value int) *Builder {
+	return b.setKeyword("minLength", b.countValue("minLength", value))
+}
+
+// WithPattern sets the "pattern" keyword.
+func (b *Builder) WithPattern(pattern string) *Builder {
+	return b.setKeyword("pattern", b.stringValue(pattern))
+}
+
+// WithFormat sets the "format" keyword.
+func (b *Builder) WithFormat(format string) *Builder {
+	return b.setKeyword("format", b.stringValue(format))
+}
+
+// WithContentEncoding sets the "contentEncoding" keyword (>= draft 7).
+func (b *Builder) WithContentEncoding(encoding string) *Builder {
+	return b.setKeyword("contentEncoding", b.stringValue(encoding))
+}
+
+// WithContentMediaType sets the "contentMediaType" keyword (>= draft 7).
+func (b *Builder) WithContentMediaType(mediaType string) *Builder {
+	return b.setKeyword("contentMediaType", b.stringValue(mediaType))
+}
+
+// WithContentSchema sets the "contentSchema" keyword (>= draft 2019).
+func (b *Builder) WithContentSchema(sch Schema) *Builder {
+	return b.setKeyword("contentSchema", b.schemaValue(sch))
+}
+
+// WithMaxItems sets the "maxItems" keyword.
+func (b *Builder) WithMaxItems(value int) *Builder {
+	return b.setKeyword("maxItems", b.countValue("maxItems", value))
+}
+
+// WithMinItems sets the "minItems" keyword.
+func (b *Builder) WithMinItems(value int) *Builder {
+	return b.setKeyword("minItems", b.countValue("minItems", value))
+}
+
+// WithUniqueItems sets the "uniqueItems" keyword.
+func (b *Builder) WithUniqueItems(enabled bool) *Builder {
+	return b.setKeyword("uniqueItems", b.boolValue(enabled))
+}
+
+// WithMaxContains sets the "maxContains" keyword (>= draft 2019).
+func (b *Builder) WithMaxContains(value int) *Builder {
+	return b.setKeyword("maxContains", b.countValue("maxContains", value))
+}
+
+// WithMinContains sets the "minContains" keyword (>= draft 2019).
+func (b *Builder) WithMinContains(value int) *Builder {
+	return b.setKeyword("minContains", b.countValue("minContains", value))
+}
+
+// WithMaxProperties sets the "maxProperties" keyword.
+func (b *Builder) WithMaxProperties(value int) *Builder {
+	return b.setKeyword("maxProperties", b.countValue("maxProperties", value))
+}
+
+// WithMinProperties sets the "minProperties" keyword.
+func (b *Builder) WithMinProperties(value int) *Builder {
+	return b.setKeyword("minProperties", b.countValue("minProperties", value))
+}
+
+// WithRequired adds property names to the "required" keyword.
+//
+// Names which are already required are ignored.
+func (b *Builder) WithRequired(names ...string) *Builder {
+	if !b.Ok() {
+		return b
+	}
+
+	current, _ := b.sch.AtKey("required")
+	required := make([]string, 0, current.Len()+len(names))
+	if current.IsArray() {
+		for elem := range current.Elems() {
+			required = append(required, elem.String())
+		}
+	}
+
+	for _, name := range names {
+		if !slices.Contains(required, name) {
+			required = append(required, name)
+		}
+	}
+
+	return b.setKeyword("required", b.stringsValue(required))
+}
+
+// WithDependentRequired adds the properties required when a property is present to the "dependentRequired" keyword
+// (>= draft 2019).
+func (b *Builder) WithDependentRequired(name string, required ...string) *Builder {
+	return b.setMember("dependentRequired", name, b.stringsValue(required))
+}
+
+// WithProperty adds a property to the "properties" keyword.
+//
+// A property with the same name is replaced.
+func (b *Builder) WithProperty(name string, sch Schema) *Builder {
+	return b.setMember("properties", name, b.schemaValue(sch))
+}
+
+// WithRequiredProperty adds a property to the "properties" keyword and its name to the "required" keyword.
+func (b *Builder) WithRequiredProperty(name string, sch Schema) *Builder {
+	return b.WithProperty(name, sch).WithRequired(name)
+}
+
+// WithProperties adds properties to the "properties" keyword, in the order of iteration.
+func (b *Builder) WithProperties(properties iter.Seq2[string, Schema]) *Builder {
+	for name, sch := range properties {
+		if !b.WithProperty(name, sch).Ok() {
+			break
+		}
+	}
+
+	return b
+}
+
+// WithPatternProperty adds a schema for the properties matching a regular expression to
+// the "patternProperties" keyword.
+func (b *Builder) WithPatternProperty(pattern string, sch Schema) *Builder {
+	return b.setMember("patternProperties", pattern, b.schemaValue(sch))
+}
+
+// WithAdditionalProperties sets the "additionalProperties" keyword.
+func (b *Builder) WithAdditionalProperties(sch Schema) *Builder {
+	return b.setKeyword("additionalProperties", b.schemaValue(sch))
+}
+
+// WithPropertyNames sets the "propertyNames" keyword (>= draft 6).
+func (b *Builder) WithPropertyNames(sch Schema) *Builder {
+	return b.setKeyword("propertyNames", b.schemaValue(sch))
+}
+
+// WithUnevaluatedProperties sets the "unevaluatedProperties" keyword (>= draft 2019).
+func (b *Builder) WithUnevaluatedProperties(sch Schema) *Builder {
+	return b.setKeyword("unevaluatedProperties", b.schemaValue(sch))
+}
+
+// WithDependentSchema adds the schema that applies when a property is present to the "dependentSchemas" keyword
+// (>= draft 2019).
+func (b *Builder) WithDependentSchema(name string, sch Schema) *Builder {
+	return b.setMember("dependentSchemas", name, b.schemaValue(sch))
+}
+
+// WithItems sets the "items" keyword to a single schema.
+//
+// Since draft 2020, "items" applies to the elements after the ones validated by "prefixItems".
+func (b *Builder) WithItems(sch Schema) *Builder {
+	return b.setKeyword("items", b.schemaValue(sch))
+}
+
+// WithPrefixItems adds schemas to the "prefixItems" keyword (>= draft 2020).
+func (b *Builder) WithPrefixItems(schemas ...Schema) *Builder {
+	return b.appendSchemas("prefixItems", schemas)
 }
 
+// WithContains sets the "contains" keyword (>= draft 6).
+func (b *Builder) WithContains(sch Schema) *Builder {
+	return b.setKeyword("contains", b.schemaValue(sch))
+}
+
+// WithUnevaluatedItems sets the "unevaluatedItems" keyword (>= draft 2019).
+func (b *Builder) WithUnevaluatedItems(sch Schema) *Builder {
+	return b.setKeyword("unevaluatedItems", b.schemaValue(sch))
+}
+
+// WithAllOf adds schemas to the "allOf" keyword.
 func (b *Builder) WithAllOf(schemas ...Schema) *Builder {
-	return b // TODO
+	return b.appendSchemas("allOf", schemas)
 }
 
+// WithOneOf adds schemas to the "oneOf" keyword.
 func (b *Builder) WithOneOf(schemas ...Schema) *Builder {
-	return b // TODO
+	return b.appendSchemas("oneOf", schemas)
 }
 
+// WithAnyOf adds schemas to the "anyOf" keyword.
 func (b *Builder) WithAnyOf(schemas ...Schema) *Builder {
-	return b // TODO
+	return b.appendSchemas("anyOf", schemas)
+}
+
+// WithNot sets the "not" keyword.
+func (b *Builder) WithNot(sch Schema) *Builder {
+	return b.setKeyword("not", b.schemaValue(sch))
+}
+
+// WithIf sets the "if" keyword (>= draft 7).
+func (b *Builder) WithIf(sch Schema) *Builder {
+	return b.setKeyword("if", b.schemaValue(sch))
+}
+
+// WithThen sets the "then" keyword (>= draft 7).
+func (b *Builder) WithThen(sch Schema) *Builder {
+	return b.setKeyword("then", b.schemaValue(sch))
+}
+
+// WithElse sets the "else" keyword (>= draft 7).
+func (b *Builder) WithElse(sch Schema) *Builder {
+	return b.setKeyword("else", b.schemaValue(sch))
+}
+
+// WithTitle sets the "title" keyword.
+func (b *Builder) WithTitle(title string) *Builder {
+	return b.setKeyword("title", b.stringValue(title))
+}
+
+// WithDescription sets the "description" keyword.
+func (b *Builder) WithDescription(description string) *Builder {
+	return b.setKeyword("description", b.stringValue(description))
+}
+
+// WithDefault sets the "default" keyword.
+func (b *Builder) WithDefault(value json.Document) *Builder {
+	return b.setKeyword("default", value)
+}
+
+// WithExamples adds values to the "examples" keyword (>= draft 6).
+func (b *Builder) WithExamples(values ...json.Document) *Builder {
+	return b.appendElems("examples", values)
+}
+
+// WithDeprecated sets the "deprecated" keyword (>= draft 2019).
+func (b *Builder) WithDeprecated(enabled bool) *Builder {
+	return b.setKeyword("deprecated", b.boolValue(enabled))
+}
+
+// WithReadOnly sets the "readOnly" keyword (>= draft 7).
+func (b *Builder) WithReadOnly(enabled bool) *Builder {
+	return b.setKeyword("readOnly", b.boolValue(enabled))
+}
+
+// WithWriteOnly sets the "writeOnly" keyword (>= draft 7).
+func (b *Builder) WithWriteOnly(enabled bool) *Builder {
+	return b.setKeyword("writeOnly", b.boolValue(enabled))
+}
+
+// WithExtension sets an extension, i.e. a key starting with "x-".
+func (b *Builder) WithExtension(key string, value json.Document) *Builder {
+	if !strings.HasPrefix(key, "x-") {
+		return b.fail(fmt.Errorf("extension %q should start with %q: %w", key, "x-", ErrSchema))
+	}
+
+	return b.setKeyword(key, value)
+}
+
+// updateDocument transforms the JSON document of the schema, then decodes the result as a new [Schema].
+func (b *Builder) updateDocument(update func(*json.Builder) *json.Builder) *Builder {
+	if !b.Ok() {
+		return b
+	}
+
+	jb := update(json.NewBuilder(b.Store()).From(b.sch.Document))
+	if !jb.Ok() {
+		return b.fail(jb.Err())
+	}
+
+	return b.rebuild(jb.Document(), false)
+}
+
+// rebuild decodes a JSON document as the new built [Schema], possibly checking the version requirements
+// of its keywords.
+func (b *Builder) rebuild(doc json.Document, checked bool) *Builder {
+	if !b.Ok() {
+		return b
+	}
+
+	data, err := doc.MarshalJSON()
+	if err != nil {
+		return b.fail(err)
+	}
+
+	rebuilt := Make(withOptions(b.options))
+	if err := rebuilt.UnmarshalJSON(data); err != nil {
+		return b.fail(err)
+	}
+
+	if checked {
+		var requirements VersionRequirements
+		if err := checkVersions(rebuilt.Document, rebuilt.Version(), &requirements); err != nil {
+			return b.fail(err)
+		}
+	}
+
+	b.sch = rebuilt
+
+	return b
+}
+
+func (b *Builder) fail(err error) *Builder {
+	if b.err == nil {
+		b.err = err
+	}
+
+	return b
+}
+
+// setKeyword sets a keyword of the schema: an existing keyword is replaced in place, a new one is appended.
+func (b *Builder) setKeyword(key string, value json.Document) *Builder {
+	if !b.Ok() {
+		return b
+	}
+
+	return b.rebuild(b.withMember(b.sch.Document, key, value), true)
+}
+
+// setMember sets a member of a keyword holding an object, e.g. "properties".
+func (b *Builder) setMember(key, name string, value json.Document) *Builder {
+	if !b.Ok() {
+		return b
+	}
+
+	members, _ := b.sch.AtKey(key)
+
+	return b.setKeyword(key, b.withMember(members, name, value))
+}
+
+// appendElems appends elements to a keyword holding an array, e.g. "examples".
+func (b *Builder) appendElems(key string, elems []json.Document) *Builder {
+	if !b.Ok() {
+		return b
+	}
+
+	current, _ := b.sch.AtKey(key)
+	if current.IsArray() {
+		elems = append(slices.Collect(current.Elems()), elems...)
+	}
+
+	return b.setKeyword(key, b.arrayValue(elems...))
+}
+
+// appendSchemas appends schemas to a keyword holding an array of schemas, e.g. "allOf".
+func (b *Builder) appendSchemas(key string, schemas []Schema) *Builder {
+	elems := make([]json.Document, 0, len(schemas))
+	for _, sch := range schemas {
+		elems = append(elems, b.schemaValue(sch))
+	}
+
+	return b.appendElems(key, elems)
+}
+
+// withMember returns a copy of an object with a member set. If the document is not an object,
+// a new object is returned.
+func (b *Builder) withMember(object json.Document, key string, value json.Document) json.Document {
+	value = b.own(value)
+	jb := json.NewBuilder(b.Store()).Object()
+	replaced := false
+
+	if object.IsObject() {
+		for k, v := range object.Pairs() {
+			if k == key {
+				v = value
+				replaced = true
+			}
+
+			jb.AppendKey(k, v)
+		}
+	}
+
+	if !replaced {
+		jb.AppendKey(key, value)
+	}
+
+	if !jb.Ok() {
+		b.fail(jb.Err())
+	}
+
+	return jb.Document()
+}
+
+// own returns a [json.Document] which shares the store of the [Builder].
+//
+// Documents with another store are re-encoded with the store of the [Builder].
+func (b *Builder) own(doc json.Document) json.Document {
+	if !b.Ok() || doc.Store() == b.Store() {
+		return doc
+	}
+
+	if doc.Store() == nil {
+		b.fail(fmt.Errorf("cannot build from an undefined JSON document: %w", ErrSchema))
+
+		return doc
+	}
+
+	data, err := doc.MarshalJSON()
+	if err != nil {
+		b.fail(err)
+
+		return doc
+	}
+
+	owned := json.Make(json.WithStore(b.Store()))
+	if err := owned.UnmarshalJSON(data); err != nil {
+		b.fail(err)
+
+		return doc
+	}
+
+	return owned
+}
+
+func (b *Builder) schemaValue(sch Schema) json.Document {
+	if sch.Store() == nil || (!sch.IsObject() && !sch.IsBool()) {
+		b.fail(fmt.Errorf("a subschema should be an object or a boolean: %w", ErrSchema))
+
+		return sch.Document
+	}
+
+	return b.own(sch.Document)
+}
+
+func (b *Builder) stringValue(value string) json.Document {
+	return json.NewBuilder(b.Store()).StringValue(value).Document()
+}
+
+func (b *Builder) stringsValue(values []string) json.Document {
+	elems := make([]json.Document, 0, len(values))
+	for _, value := range values {
+		elems = append(elems, b.stringValue(value))
+	}
+
+	return b.arrayValue(elems...)
+}
+
+func (b *Builder) boolValue(value bool) json.Document {
+	return json.NewBuilder(b.Store()).BoolValue(value).Document()
+}
+
+func (b *Builder) numberValue(value any) json.Document {
+	jb := json.NewBuilder(b.Store()).NumericalValue(value)
+	if !jb.Ok() {
+		b.fail(fmt.Errorf("invalid number %v: %w: %w", value, jb.Err(), ErrSchema))
+	}
+
+	return jb.Document()
+}
+
+func (b *Builder) countValue(key string, value int) json.Document {
+	if value < 0 {
+		b.fail(fmt.Errorf("%q should be a non-negative integer, but got %d: %w", key, value, ErrSchema))
+	}
+
+	return b.numberValue(value)
+}
+
+func (b *Builder) arrayValue(elems ...json.Document) json.Document {
+	jb := json.NewBuilder(b.Store()).Array()
+	for _, elem := range elems {
+		jb.AppendElem(b.own(elem))
+	}
+
+	if !jb.Ok() {
+		b.fail(jb.Err())
+	}
+
+	return jb.Document()
+}
+
+func isSimpleType(name string) bool {
+	for t := SchemaTypeNull; t <= SchemaTypeBool; t++ {
+		if t.String() == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkVersions checks that the keywords of a schema and its subschemas are supported by a version of JSON schema,
+// and merges their [VersionRequirements].
+//
+// Keywords are checked against all versions if the version is undefined.
+func checkVersions(root json.Document, version Version, requirements *VersionRequirements) error {
+	if root.Store() == nil {
+		return nil
+	}
+
+	return WalkSchemas(root, version, func(v SchemaVisit) (json.WalkAction, error) {
+		if v.Node.IsBool() {
+			if !boolSchemaVersions.Allows(version) {
+				return json.WalkStop, fmt.Errorf(
+					"boolean schema at %q is not supported by JSON schema %v: %w", v.Pointer.String(), version, ErrVersion,
+				)
+			}
+			*requirements = requirements.Merge(boolSchemaVersions)
+
+			return json.WalkContinue, nil
+		}
+
+		for key := range v.Node.Pairs() {
+			keyword, ok := keywordVersions[values.MakeInternedKey(key)]
+			if !ok {
+				continue
+			}
+
+			if !keyword.Allows(version) {
+				return json.WalkStop, fmt.Errorf(
+					"keyword %q at %q is not supported by JSON schema %v: %w", key, v.Pointer.String(), version, ErrVersion,
+				)
+			}
+			*requirements = requirements.Merge(keyword)
+		}
+
+		return json.WalkContinue, nil
+	}, nil)
 }
@@ -0,0 +1,234 @@
+package jsonschema
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/fredbi/core/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Run("should build a draft 2020 schema", func(t *testing.T) {
+		b := NewBuilder(WithVersion(VersionDraft2020))
+		str := NewBuilder().WithType(SchemaTypeString).WithMinLength(1).Schema()
+
+		sch := b.
+			WithSchemaVersion(VersionDraft2020).
+			WithID("https://example.com/pet.json").
+			WithTitle("pet").
+			WithDescription("a pet").
+			WithType(SchemaTypeObject).
+			WithDef("name", str).
+			WithRequiredProperty("name", NewBuilder().WithRef("#/$defs/name").Schema()).
+			WithProperty("age", NewBuilder().WithType(SchemaTypeInteger).WithMinimum(0).WithExclusiveMaximum(100).Schema()).
+			WithProperty("tags", NewBuilder().
+				WithType(SchemaTypeArray).
+				WithPrefixItems(str).
+				WithItems(NewBuilder().Bool(false).Schema()).
+				WithUniqueItems(true).
+				Schema(),
+			).
+			WithProperties(maps.All(map[string]Schema{"kind": NewBuilder().WithEnum(mustDocument(t, `"cat"`), mustDocument(t, `"dog"`)).Schema()})).
+			WithRequired("name", "kind").
+			WithAdditionalProperties(NewBuilder().Bool(false).Schema()).
+			WithIf(NewBuilder().WithRequiredProperty("kind", NewBuilder().WithConst(mustDocument(t, `"cat"`)).Schema()).Schema()).
+			WithThen(NewBuilder().WithRequired("age").Schema()).
+			WithAnyOf(NewBuilder().WithMinProperties(1).Schema()).
+			WithDeprecated(false).
+			WithExamples(mustDocument(t, `{"name": "felix", "kind": "cat"}`)).
+			WithExtension("x-go-name", mustDocument(t, `"Pet"`)).
+			Schema()
+		require.NoError(t, b.Err())
+
+		output, err := sch.MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"$id": "https://example.com/pet.json",
+			"title": "pet",
+			"description": "a pet",
+			"type": "object",
+			"$defs": {"name": {"type": "string", "minLength": 1}},
+			"properties": {
+				"name": {"$ref": "#/$defs/name"},
+				"age": {"type": "integer", "minimum": 0, "exclusiveMaximum": 100},
+				"tags": {
+					"type": "array",
+					"prefixItems": [{"type": "string", "minLength": 1}],
+					"items": false,
+					"uniqueItems": true
+				},
+				"kind": {"enum": ["cat", "dog"]}
+			},
+			"required": ["name", "kind"],
+			"additionalProperties": false,
+			"if": {"properties": {"kind": {"const": "cat"}}, "required": ["kind"]},
+			"then": {"required": ["age"]},
+			"anyOf": [{"minProperties": 1}],
+			"deprecated": false,
+			"examples": [{"name": "felix", "kind": "cat"}],
+			"x-go-name": "Pet"
+		}`, string(output))
+
+		t.Run("should share the store of the builder", func(t *testing.T) {
+			require.Equal(t, b.Store(), sch.Store())
+		})
+
+		t.Run("should require draft 2020", func(t *testing.T) {
+			requirements := b.VersionRequirements()
+			require.Equal(t, VersionDraft2020, requirements.MinVersion)
+			require.True(t, requirements.Allows(VersionDraft2020))
+			require.False(t, requirements.Allows(VersionDraft7))
+		})
+
+		t.Run("should leave built schemas unaltered", func(t *testing.T) {
+			b.WithProperty("name", str).WithTitle("cat")
+
+			unaltered, err := sch.MarshalJSON()
+			require.NoError(t, err)
+			require.JSONEq(t, string(output), string(unaltered))
+		})
+	})
+
+	t.Run("should use the keywords of earlier versions", func(t *testing.T) {
+		b := NewBuilder().
+			WithSchemaVersion(VersionDraft4).
+			WithID("http://example.com/schema.json").
+			WithDef("a", NewBuilder().WithType(SchemaTypeNull).Schema())
+		require.NoError(t, b.Err())
+
+		output, err := b.Schema().MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"$schema": "http://json-schema.org/draft-04/schema#",
+			"id": "http://example.com/schema.json",
+			"definitions": {"a": {"type": "null"}}
+		}`, string(output))
+	})
+
+	t.Run("should replace properties and merge required properties", func(t *testing.T) {
+		b := NewBuilder().
+			WithRequiredProperty("a", NewBuilder().WithType(SchemaTypeString).Schema()).
+			WithRequiredProperty("a", NewBuilder().WithType(SchemaTypeNumber).Schema()).
+			WithRequired("b", "a")
+		require.NoError(t, b.Err())
+
+		output, err := b.Schema().MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"properties": {"a": {"type": "number"}}, "required": ["a", "b"]}`, string(output))
+	})
+
+	t.Run("should check version requirements", func(t *testing.T) {
+		for _, tc := range []struct {
+			name  string
+			build func() *Builder
+		}{
+			{
+				name: "with a keyword from a later version",
+				build: func() *Builder {
+					return NewBuilder(WithVersion(VersionDraft7)).WithPrefixItems(NewBuilder().Schema())
+				},
+			},
+			{
+				name: "with a keyword from a later version declared by $schema",
+				build: func() *Builder {
+					return NewBuilder().WithSchemaVersion(VersionDraft4).WithConst(mustDocument(t, `1`))
+				},
+			},
+			{
+				name: "with a keyword from a later version in a subschema",
+				build: func() *Builder {
+					return NewBuilder(WithVersion(VersionDraft2019)).
+						WithProperty("a", NewBuilder().WithDynamicRef("#node").Schema())
+				},
+			},
+			{
+				name: "with a boolean schema",
+				build: func() *Builder {
+					return NewBuilder(WithVersion(VersionDraft4)).Bool(true)
+				},
+			},
+			{
+				name: "with a keyword removed by a later version",
+				build: func() *Builder {
+					return NewBuilder(WithVersion(VersionDraft2020)).
+						AtPointerMerge(json.EmptyPointer, mustDocument(t, `{"id": "x"}`)).
+						WithTitle("x")
+				},
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				b := tc.build()
+				require.ErrorIs(t, b.Err(), ErrVersion)
+			})
+		}
+	})
+
+	t.Run("should fail on invalid input", func(t *testing.T) {
+		require.Equal(t, "SchemaType(42)", SchemaType(42).String())
+
+		for _, tc := range []struct {
+			name  string
+			build func() *Builder
+		}{
+			{
+				name:  "with an unknown type",
+				build: func() *Builder { return NewBuilder().WithTypes([]string{"string", "date"}) },
+			},
+			{
+				name:  "with an invalid type",
+				build: func() *Builder { return NewBuilder().WithType(SchemaTypeString, SchemaType(42)) },
+			},
+			{
+				name:  "with an invalid extension",
+				build: func() *Builder { return NewBuilder().WithExtension("go-name", mustDocument(t, `"x"`)) },
+			},
+			{
+				name:  "with a negative count",
+				build: func() *Builder { return NewBuilder().WithMinItems(-1) },
+			},
+			{
+				name:  "with an undefined subschema",
+				build: func() *Builder { return NewBuilder().WithNot(EmptySchema) },
+			},
+			{
+				name:  "with an empty subschema",
+				build: func() *Builder { return NewBuilder().WithAllOf(Make()) },
+			},
+			{
+				name:  "with an unknown version",
+				build: func() *Builder { return NewBuilder().WithSchemaVersion(VersionOpenAPIv4Draft) },
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				b := tc.build().WithTitle("ignored")
+				require.ErrorIs(t, b.Err(), ErrSchema)
+			})
+		}
+	})
+
+	t.Run("should build from a node", func(t *testing.T) {
+		original := mustSchema(t, `{"$defs": {"a": {"type": "string"}}}`)
+		defs, ok := original.AtKey("$defs")
+		require.True(t, ok)
+		a, ok := defs.AtKey("a")
+		require.True(t, ok)
+
+		sch := schemaFromNode(original.Store(), a.Node())
+		output, err := sch.MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"type": "string"}`, string(output))
+		assert.Equal(t, original.Store(), sch.Store())
+	})
+}
+
+func mustDocument(t *testing.T, jazon string) json.Document {
+	t.Helper()
+
+	doc := json.Make()
+	require.NoError(t, doc.UnmarshalJSON([]byte(jazon)))
+
+	return doc
+}
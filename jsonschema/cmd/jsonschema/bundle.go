@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/analyzers/structural"
+	"github.com/fredbi/core/jsonschema/analyzers/structural/bundle"
+)
+
+// bundle schemas into self-contained documents.
+func (c *cli) bundle(args []string) error {
+	fs := c.flags("bundle", "[-strategy flat|hierarchical] [-eager] [-prune] [-single-root] [schema...]")
+	strategy := fs.String("strategy", "flat", "how relocated schemas are organized: flat or hierarchical")
+	eager := fs.Bool("eager", false, "relocate local definitions as well as remote ones")
+	prune := fs.Bool("prune", false, "remove the definitions which are not referenced")
+	singleRoot := fs.Bool("single-root", false, "bundle several schemas into a single document")
+
+	names, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if err := oneOf("strategy", *strategy, "flat", "hierarchical"); err != nil {
+		return err
+	}
+
+	schemas, inputs, err := c.readSchemas(names)
+	if err != nil {
+		return err
+	}
+
+	opts := []structural.Option{
+		structural.WithBundlePruneUnused(*prune),
+		structural.WithBundleSingleRoot(*singleRoot),
+	}
+	if *strategy == "hierarchical" {
+		opts = append(opts, structural.WithBundleStragegy(bundle.Hierarchical))
+	}
+	if *eager {
+		opts = append(opts, structural.WithBundleAggressiveness(bundle.Eager))
+	}
+
+	bundled, err := bundleSchemas(schemas, inputs, opts...)
+	if err != nil {
+		return err
+	}
+
+	for sch := range bundled.Schemas() {
+		if err := c.write(sch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bundleSchemas analyzes the schemas read from the command line, then bundles them.
+func bundleSchemas(schemas []jsonschema.Schema, inputs []input, opts ...structural.Option) (structural.Analyzer, error) {
+	a, err := analyze(schemas, inputs, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.Bundle()
+}
+
+// analyze the schemas read from the command line, located by their URI.
+func analyze(schemas []jsonschema.Schema, inputs []input, opts ...structural.Option) (*structural.SchemaAnalyzer, error) {
+	resolver, err := resolverFor(schemas, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	a := structural.NewAnalyzer(append(opts, structural.WithResolver(resolver))...)
+	for i, sch := range schemas {
+		if err := a.AnalyzeDocument(inputs[i].uri, sch); err != nil {
+			return nil, fmt.Errorf("%s: %w", inputs[i].name, err)
+		}
+	}
+
+	return a, nil
+}
+
+// resolverFor builds a [jsonschema.Resolver] that knows about the schemas read from the command line,
+// so that "$ref" s between them are resolved.
+func resolverFor(schemas []jsonschema.Schema, inputs []input) (*jsonschema.Resolver, error) {
+	resolver := jsonschema.NewResolver()
+	for i, sch := range schemas {
+		if err := resolver.AddDocument(inputs[i].uri, sch); err != nil {
+			return nil, fmt.Errorf("%s: %w", inputs[i].name, err)
+		}
+	}
+
+	return resolver, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/converter"
+)
+
+// convert a schema to another version of JSON schema.
+func (c *cli) convert(args []string) error {
+	fs := c.flags("convert", "-to <version> [-from <version>] [-skip-incompatible] [schema]")
+	to := fs.String("to", "", "the target version, e.g. draft7 or draft2020 (required)")
+	from := fs.String("from", "", "the version of a schema that doesn't declare its meta-schema")
+	skip := fs.Bool("skip-incompatible", false, "drop the constructs that can't be converted instead of failing")
+
+	names, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(names) > 1 {
+		return fmt.Errorf("expected a single schema: %w", ErrUsage)
+	}
+
+	target, err := parseVersion("to", *to)
+	if err != nil {
+		return err
+	}
+
+	opts := []converter.Option{converter.WithSkipIncompatible(*skip)}
+	if *from != "" {
+		source, err := parseVersion("from", *from)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, converter.WithSourceVersion(source))
+	}
+
+	sch, _, err := c.readSchema(firstOrStdin(names))
+	if err != nil {
+		return err
+	}
+
+	converted, report, err := converter.New(opts...).ConvertWithReport(sch, target)
+	if err != nil {
+		return err
+	}
+
+	for _, issue := range report.Issues {
+		fmt.Fprintln(c.stderr, issue)
+	}
+
+	return c.write(converted)
+}
+
+// parseVersion parses the name of a version of JSON schema, as rendered by [jsonschema.Version.String].
+func parseVersion(flagName, name string) (jsonschema.Version, error) {
+	names := make([]string, 0, int(jsonschema.VersionOpenAPIv4Draft))
+	for version := jsonschema.VersionDraft4; version <= jsonschema.VersionOpenAPIv4Draft; version++ {
+		if version.String() == name {
+			return version, nil
+		}
+		names = append(names, fmt.Sprintf("%q", version.String()))
+	}
+
+	return jsonschema.VersionUndefined, fmt.Errorf("invalid version %q for -%s, expected one of %s: %w",
+		name, flagName, strings.Join(names, ", "), ErrUsage,
+	)
+}
+
+func firstOrStdin(names []string) string {
+	if len(names) == 0 {
+		return stdinName
+	}
+
+	return names[0]
+}
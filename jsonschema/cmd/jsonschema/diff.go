@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/fredbi/core/jsonschema/differ"
+)
+
+// diff reports the changes between two versions of a schema.
+func (c *cli) diff(args []string) error {
+	fs := c.flags("diff", "[-fail-on breaking|minor|patch|doc-only|cosmetic] [-output table|markdown|html|json] <old> <new>")
+	failOn := fs.String("fail-on", "", "the minimum severity of changes that fails the command, e.g. breaking")
+	threshold := fs.String("threshold", "none", "the minimum severity of reported changes")
+	output := fs.String("output", "table", "the output format: table, markdown, html or json")
+
+	names, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(names) != 2 { //nolint:mnd // old and new
+		return fmt.Errorf("expected the old and the new schema: %w", ErrUsage)
+	}
+
+	modes := map[string]differ.ReportOutputMode{
+		"table":    differ.ReportOutputTable,
+		"markdown": differ.ReportOutputMarkdown,
+		"html":     differ.ReportOutputHTML,
+		"json":     differ.ReportOutputJSON,
+	}
+	if err := oneOf("output", *output, "table", "markdown", "html", "json"); err != nil {
+		return err
+	}
+
+	minSeverity, err := differ.ParseSeverity(*threshold)
+	if err != nil {
+		return fmt.Errorf("-threshold: %w: %w", err, ErrUsage)
+	}
+
+	failSeverity := differ.SeverityNone
+	if *failOn != "" {
+		if failSeverity, err = differ.ParseSeverity(*failOn); err != nil {
+			return fmt.Errorf("-fail-on: %w: %w", err, ErrUsage)
+		}
+	}
+
+	schemas, _, err := c.readSchemas(names)
+	if err != nil {
+		return err
+	}
+
+	result := differ.New().Diff(schemas[0], schemas[1])
+	if err := differ.WriteReport(c.stdout, result,
+		differ.WithOutputMode(modes[*output]),
+		differ.WithThreshold(minSeverity),
+	); err != nil {
+		return err
+	}
+
+	if failSeverity != differ.SeverityNone && !result.MaxSeverity().Less(failSeverity) {
+		return fmt.Errorf("changes with severity %v: %w", result.MaxSeverity(), ErrFailed)
+	}
+
+	return nil
+}
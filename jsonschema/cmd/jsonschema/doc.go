@@ -0,0 +1,27 @@
+// Command jsonschema exposes the tools of the jsonschema library on the command line.
+//
+// Usage:
+//
+//	jsonschema <command> [flags] [arguments]
+//
+// Commands:
+//
+//	validate  validate JSON or YAML data against a schema
+//	lint      lint schemas
+//	convert   convert a schema to another version of JSON schema
+//	diff      report the changes between two versions of a schema
+//	bundle    bundle schemas into self-contained documents
+//	overlay   apply an overlay to a schema
+//	fake      generate fake data that validates against a schema
+//	infer     infer a schema from samples of JSON or YAML data
+//	graph     export the dependency graph of schemas
+//
+// Documents are read from files, or from the standard input when the file is "-" or omitted.
+// YAML documents are recognized by their ".yaml" or ".yml" extension, or by their content on the standard input.
+//
+// The exit code is suitable for CI:
+//
+//	0  success
+//	1  the check failed: invalid data, lint findings, breaking changes...
+//	2  usage error, or a document could not be read
+package main
@@ -0,0 +1,23 @@
+package main
+
+// Error is an error raised by a command.
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrUsage is raised when a command is invoked with invalid flags or arguments.
+	ErrUsage Error = "usage error"
+
+	// ErrFailed is raised when the check performed by a command fails, e.g. when data is invalid.
+	ErrFailed Error = "check failed"
+)
+
+// exit codes
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitError  = 2
+)
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/faker"
+)
+
+// fake generates fake data that validates against a schema.
+func (c *cli) fake(args []string) error {
+	fs := c.flags("fake", "[-count N] [-seed S] [-invalid] [schema]")
+	count := fs.Int("count", 1, "the number of documents to generate")
+	seed := fs.Int64("seed", 0, "the seed of the random generator, to generate the same documents again")
+	invalid := fs.Bool("invalid", false, "generate documents which are invalid against the schema")
+
+	names, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(names) > 1 {
+		return fmt.Errorf("expected a single schema: %w", ErrUsage)
+	}
+
+	if *count < 0 {
+		return fmt.Errorf("-count must be positive: %w", ErrUsage)
+	}
+
+	schemas, inputs, err := c.readSchemas(names)
+	if err != nil {
+		return err
+	}
+
+	// the schema is bundled first, since the faker doesn't know where the schema is located
+	bundled, err := bundleSchemas(schemas[:1], inputs[:1])
+	if err != nil {
+		return err
+	}
+
+	var schema jsonschema.Schema
+	for sch := range bundled.Schemas() {
+		schema = sch
+	}
+
+	opts := []faker.DataOption{faker.WithDataSeed(*seed)}
+	if *invalid {
+		opts = append(opts, faker.WithDataOnlyInvalid(true, faker.DistorsionLow))
+	}
+
+	for generated := range faker.NewDataFaker(schema, opts...).GenerateMany(*count) {
+		if err := generated.Err(); err != nil {
+			return err
+		}
+
+		if err := c.write(generated.Document()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
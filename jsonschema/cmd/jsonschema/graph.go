@@ -0,0 +1,44 @@
+package main
+
+import (
+	"github.com/fredbi/core/jsonschema/analyzers/structural"
+	"github.com/fredbi/core/jsonschema/analyzers/structural/export"
+)
+
+// graph exports the dependency graph of schemas.
+func (c *cli) graph(args []string) error {
+	fs := c.flags("graph", "[-format dot|mermaid|json] [-only-named] [schema...]")
+	format := fs.String("format", "dot", "the output format: dot, mermaid or json")
+	onlyNamed := fs.Bool("only-named", false, "only export named schemas, i.e. definitions and roots")
+
+	names, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	formats := map[string]export.Format{
+		"dot":     export.FormatDOT,
+		"mermaid": export.FormatMermaid,
+		"json":    export.FormatJSON,
+	}
+	if err := oneOf("format", *format, "dot", "mermaid", "json"); err != nil {
+		return err
+	}
+
+	schemas, inputs, err := c.readSchemas(names)
+	if err != nil {
+		return err
+	}
+
+	a, err := analyze(schemas, inputs)
+	if err != nil {
+		return err
+	}
+
+	var filters []structural.Filter
+	if *onlyNamed {
+		filters = append(filters, structural.OnlyNamedSchemas())
+	}
+
+	return a.ExportGraph(c.stdout, formats[*format], filters...)
+}
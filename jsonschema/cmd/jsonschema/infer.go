@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"fmt"
+	"math/big"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+)
+
+// infer a schema from samples of JSON or YAML data.
+func (c *cli) infer(args []string) error {
+	fs := c.flags("infer", "[-elements] [-no-required] [-no-formats] [sample...]")
+	elements := fs.Bool("elements", false, "use the elements of arrays as samples, rather than the arrays")
+	noRequired := fs.Bool("no-required", false, `don't infer "required" properties`)
+	noFormats := fs.Bool("no-formats", false, `don't infer the "format" of strings`)
+
+	names, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		names = []string{stdinName}
+	}
+
+	s := &shape{}
+	for _, name := range names {
+		doc, _, err := c.readDocument(name)
+		if err != nil {
+			return err
+		}
+
+		if *elements && doc.IsArray() {
+			for elem := range doc.Elems() {
+				s.merge(elem)
+			}
+
+			continue
+		}
+
+		s.merge(doc)
+	}
+
+	var buf bytes.Buffer
+	r := renderer{buf: &buf, required: !*noRequired, formats: !*noFormats}
+	if err := r.render(s, true); err != nil {
+		return err
+	}
+
+	return c.write(stdjson.RawMessage(buf.Bytes()))
+}
+
+//nolint:gochecknoglobals // types of JSON values, in the order they are rendered
+var inferredTypes = []string{"null", "boolean", "integer", "number", "string", "array", "object"}
+
+// shape of the samples merged at some location.
+type shape struct {
+	types map[string]struct{}
+
+	// objects
+	objects    int
+	names      []string // names of properties, in order of appearance
+	properties map[string]*shape
+	seen       map[string]int // number of objects with a property
+
+	// arrays
+	items *shape
+
+	// strings
+	format    string
+	formatSet bool
+}
+
+func (s *shape) merge(value json.Document) {
+	if s.types == nil {
+		s.types = make(map[string]struct{})
+	}
+
+	switch {
+	case value.IsNull():
+		s.types["null"] = struct{}{}
+	case value.IsBool():
+		s.types["boolean"] = struct{}{}
+	case value.IsNumber():
+		s.types[numberType(value)] = struct{}{}
+	case value.IsString():
+		s.types["string"] = struct{}{}
+		s.mergeFormat(value.String())
+	case value.IsArray():
+		s.types["array"] = struct{}{}
+		if s.items == nil {
+			s.items = &shape{}
+		}
+
+		for elem := range value.Elems() {
+			s.items.merge(elem)
+		}
+	case value.IsObject():
+		s.types["object"] = struct{}{}
+		s.mergeObject(value)
+	}
+}
+
+func (s *shape) mergeObject(value json.Document) {
+	if s.properties == nil {
+		s.properties = make(map[string]*shape)
+		s.seen = make(map[string]int)
+	}
+	s.objects++
+
+	for name, member := range value.Pairs() {
+		property, ok := s.properties[name]
+		if !ok {
+			property = &shape{}
+			s.properties[name] = property
+			s.names = append(s.names, name)
+		}
+
+		s.seen[name]++
+		property.merge(member)
+	}
+}
+
+// mergeFormat keeps the format of strings only if all strings have the same format.
+func (s *shape) mergeFormat(value string) {
+	format := formatOf(value)
+	if !s.formatSet {
+		s.format, s.formatSet = format, true
+
+		return
+	}
+
+	if s.format != format {
+		s.format = ""
+	}
+}
+
+func numberType(value json.Document) string {
+	v, _ := value.Value()
+	if r, ok := new(big.Rat).SetString(string(v.NumberValue().Value)); ok && r.IsInt() {
+		return "integer"
+	}
+
+	return "number"
+}
+
+//nolint:gochecknoglobals // pattern of UUIDs
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// formatOf recognizes the format of a string, among the most common formats defined by JSON schema.
+func formatOf(value string) string {
+	if _, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return "date-time"
+	}
+
+	if _, err := time.Parse(time.DateOnly, value); err == nil {
+		return "date"
+	}
+
+	if uuidPattern.MatchString(value) {
+		return "uuid"
+	}
+
+	if addr, err := netip.ParseAddr(value); err == nil {
+		if addr.Is4() {
+			return "ipv4"
+		}
+
+		return "ipv6"
+	}
+
+	if address, err := mail.ParseAddress(value); err == nil && address.Name == "" && address.Address == value {
+		return "email"
+	}
+
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.Host != "" {
+		return "uri"
+	}
+
+	return ""
+}
+
+// renderer renders the inferred schema as JSON.
+type renderer struct {
+	buf      *bytes.Buffer
+	required bool
+	formats  bool
+}
+
+func (r renderer) render(s *shape, root bool) error {
+	fields := make([]func() error, 0)
+	field := func(key string, value func() error) {
+		fields = append(fields, func() error {
+			if err := r.value(key); err != nil {
+				return err
+			}
+			r.buf.WriteByte(':')
+
+			return value()
+		})
+	}
+
+	if root {
+		field("$schema", func() error { return r.value(jsonschema.VersionDraft2020.MetaSchemaURL()) })
+	}
+
+	if types := s.typeNames(); len(types) == 1 {
+		field("type", func() error { return r.value(types[0]) })
+	} else if len(types) > 1 {
+		field("type", func() error { return r.value(types) })
+	}
+
+	if _, isString := s.types["string"]; isString && r.formats && s.format != "" {
+		field("format", func() error { return r.value(s.format) })
+	}
+
+	if len(s.names) > 0 {
+		field("properties", func() error { return r.properties(s) })
+
+		if required := s.requiredNames(); r.required && len(required) > 0 {
+			field("required", func() error { return r.value(required) })
+		}
+	}
+
+	if s.items != nil && len(s.items.types) > 0 {
+		field("items", func() error { return r.render(s.items, false) })
+	}
+
+	r.buf.WriteByte('{')
+	for i, write := range fields {
+		if i > 0 {
+			r.buf.WriteByte(',')
+		}
+
+		if err := write(); err != nil {
+			return err
+		}
+	}
+	r.buf.WriteByte('}')
+
+	return nil
+}
+
+func (r renderer) properties(s *shape) error {
+	r.buf.WriteByte('{')
+	for i, name := range s.names {
+		if i > 0 {
+			r.buf.WriteByte(',')
+		}
+
+		if err := r.value(name); err != nil {
+			return err
+		}
+		r.buf.WriteByte(':')
+
+		if err := r.render(s.properties[name], false); err != nil {
+			return err
+		}
+	}
+	r.buf.WriteByte('}')
+
+	return nil
+}
+
+func (r renderer) value(value any) error {
+	data, err := stdjson.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("cannot render the inferred schema: %w", err)
+	}
+	r.buf.Write(data)
+
+	return nil
+}
+
+// typeNames lists the types of the samples. Integers are numbers when some samples are not integers.
+func (s *shape) typeNames() []string {
+	names := make([]string, 0, len(s.types))
+	_, hasNumber := s.types["number"]
+
+	for _, name := range inferredTypes {
+		if _, ok := s.types[name]; !ok || (name == "integer" && hasNumber) {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// requiredNames lists the properties found in all objects.
+func (s *shape) requiredNames() []string {
+	return slices.DeleteFunc(slices.Clone(s.names), func(name string) bool {
+		return s.seen[name] < s.objects
+	})
+}
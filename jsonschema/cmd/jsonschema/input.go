@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/swag/loading"
+)
+
+const (
+	// stdinName is the name of the standard input, as a file argument.
+	stdinName = "-"
+
+	// stdinDisplayName is the name of the standard input in reports.
+	stdinDisplayName = "<stdin>"
+)
+
+// input is a document read from a file or from the standard input, converted to JSON.
+type input struct {
+	name string // as given on the command line
+	uri  string // absolute location, against which relative references are resolved
+	data []byte
+}
+
+// read a JSON or YAML document from a file, or from the standard input.
+func (c *cli) read(name string) (input, error) {
+	if name == "" {
+		name = stdinName
+	}
+
+	var (
+		in     = input{name: name}
+		isYAML bool
+		err    error
+	)
+
+	if name == stdinName {
+		if c.stdinRead {
+			return in, fmt.Errorf("the standard input can only be read once: %w", ErrUsage)
+		}
+		c.stdinRead = true
+		in.name = stdinDisplayName
+
+		if in.data, err = io.ReadAll(c.stdin); err != nil {
+			return in, fmt.Errorf("cannot read the standard input: %w", err)
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return in, err
+		}
+
+		in.uri = filepath.ToSlash(filepath.Join(cwd, "stdin.json"))
+		isYAML = !looksLikeJSON(in.data)
+	} else {
+		if in.data, err = os.ReadFile(name); err != nil {
+			return in, err
+		}
+
+		abs, err := filepath.Abs(name)
+		if err != nil {
+			return in, err
+		}
+
+		in.uri = filepath.ToSlash(abs)
+		isYAML = loading.YAMLMatcher(name)
+	}
+
+	if isYAML {
+		if in.data, err = yamlToJSON(in.data); err != nil {
+			return in, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return in, nil
+}
+
+// looksLikeJSON tells if some data starts like a JSON document.
+func looksLikeJSON(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return true
+	}
+
+	return stdjson.Valid(trimmed)
+}
+
+// readSchema reads a [jsonschema.Schema] from a file, or from the standard input.
+func (c *cli) readSchema(name string) (jsonschema.Schema, input, error) {
+	in, err := c.read(name)
+	if err != nil {
+		return jsonschema.Schema{}, in, err
+	}
+
+	sch := jsonschema.Make()
+	if err := sch.UnmarshalJSON(in.data); err != nil {
+		return sch, in, fmt.Errorf("%s: invalid schema: %w", in.name, err)
+	}
+	sch.SetSource(jsonschema.Source{File: in.name})
+
+	return sch, in, nil
+}
+
+// readSchemas reads several schemas, from the standard input when there is none.
+func (c *cli) readSchemas(names []string) ([]jsonschema.Schema, []input, error) {
+	if len(names) == 0 {
+		names = []string{stdinName}
+	}
+
+	schemas := make([]jsonschema.Schema, 0, len(names))
+	inputs := make([]input, 0, len(names))
+	for _, name := range names {
+		sch, in, err := c.readSchema(name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		schemas = append(schemas, sch)
+		inputs = append(inputs, in)
+	}
+
+	return schemas, inputs, nil
+}
+
+// readDocument reads a JSON document from a file, or from the standard input.
+func (c *cli) readDocument(name string) (json.Document, input, error) {
+	in, err := c.read(name)
+	if err != nil {
+		return json.Document{}, in, err
+	}
+
+	doc := json.Make()
+	if err := doc.UnmarshalJSON(in.data); err != nil {
+		return doc, in, fmt.Errorf("%s: invalid JSON: %w", in.name, err)
+	}
+
+	return doc, in, nil
+}
+
+// write a JSON value, indented, followed by a new line.
+func (c *cli) write(value stdjson.Marshaler) error {
+	data, err := value.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := stdjson.Indent(&buf, data, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+
+	_, err = c.stdout.Write(buf.Bytes())
+
+	return err
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/linter"
+)
+
+// lint schemas.
+func (c *cli) lint(args []string) error {
+	fs := c.flags("lint", "[-fail-on error|warning|info|none] [-disable rule,...] [-output text|json] [schema...]")
+	failOn := fs.String("fail-on", "error", "the minimum severity of findings that fails the command, or none")
+	threshold := fs.String("threshold", "info", "the minimum severity of reported findings")
+	disabled := fs.String("disable", "", "a comma-separated list of rules to disable")
+	output := fs.String("output", "text", "the output format: text or json")
+
+	names, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if err := oneOf("output", *output, "text", "json"); err != nil {
+		return err
+	}
+
+	failSeverity, err := linter.ParseSeverity(*failOn)
+	if err != nil {
+		return fmt.Errorf("-fail-on: %w: %w", err, ErrUsage)
+	}
+
+	minSeverity, err := linter.ParseSeverity(*threshold)
+	if err != nil {
+		return fmt.Errorf("-threshold: %w: %w", err, ErrUsage)
+	}
+
+	schemas, inputs, err := c.readSchemas(names)
+	if err != nil {
+		return err
+	}
+
+	opts := make([]linter.Option, 0, 2) //nolint:mnd
+	if *disabled != "" {
+		opts = append(opts, linter.WithDisabledRules(strings.Split(*disabled, ",")...))
+	}
+
+	var result linter.Result
+	if len(schemas) == 1 {
+		result, err = linter.New(append(opts, linter.WithBaseURI(inputs[0].uri))...).Lint(schemas[0])
+	} else {
+		collection := jsonschema.MakeCollection(len(schemas))
+		for _, sch := range schemas {
+			collection.Append(sch)
+		}
+		result, err = linter.New(opts...).LintCollection(collection)
+	}
+	if err != nil {
+		return err
+	}
+
+	findings := make([]lintFinding, 0, result.Len())
+	for finding := range result.Findings(linter.WithSeverityThreshold(minSeverity)) {
+		findings = append(findings, lintFinding{
+			Rule:     finding.RuleID,
+			Severity: finding.Severity.String(),
+			Document: inputs[finding.Index].name,
+			Pointer:  finding.Pointer,
+			Message:  finding.Message,
+		})
+	}
+
+	if *output == "json" {
+		encoder := stdjson.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(findings); err != nil {
+			return err
+		}
+	} else {
+		for _, finding := range findings {
+			fmt.Fprintf(c.stdout, "%s#%s: %s: %s [%s]\n",
+				finding.Document, finding.Pointer, finding.Severity, finding.Message, finding.Rule,
+			)
+		}
+	}
+
+	if failSeverity != linter.SeverityNone && !result.MaxSeverity().Less(failSeverity) {
+		return fmt.Errorf("findings with severity %v: %w", result.MaxSeverity(), ErrFailed)
+	}
+
+	return nil
+}
+
+type lintFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Document string `json:"document"`
+	Pointer  string `json:"pointer"`
+	Message  string `json:"message"`
+}
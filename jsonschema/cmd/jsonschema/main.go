@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// command of the CLI.
+type command struct {
+	name     string
+	synopsis string
+	run      func(c *cli, args []string) error
+}
+
+//nolint:gochecknoglobals // commands of the CLI
+var commands = []command{
+	{name: "validate", synopsis: "validate JSON or YAML data against a schema", run: (*cli).validate},
+	{name: "lint", synopsis: "lint schemas", run: (*cli).lint},
+	{name: "convert", synopsis: "convert a schema to another version of JSON schema", run: (*cli).convert},
+	{name: "diff", synopsis: "report the changes between two versions of a schema", run: (*cli).diff},
+	{name: "bundle", synopsis: "bundle schemas into self-contained documents", run: (*cli).bundle},
+	{name: "overlay", synopsis: "apply an overlay to a schema", run: (*cli).overlay},
+	{name: "fake", synopsis: "generate fake data that validates against a schema", run: (*cli).fake},
+	{name: "infer", synopsis: "infer a schema from samples of JSON or YAML data", run: (*cli).infer},
+	{name: "graph", synopsis: "export the dependency graph of schemas", run: (*cli).graph},
+}
+
+// cli holds the streams used by commands.
+type cli struct {
+	stdin     io.Reader
+	stdout    io.Writer
+	stderr    io.Writer
+	stdinRead bool
+}
+
+// run the CLI with its arguments, and return the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := &cli{stdin: stdin, stdout: stdout, stderr: stderr}
+
+	if len(args) == 0 {
+		c.usage(stderr)
+
+		return exitError
+	}
+
+	name := args[0]
+	switch name {
+	case "-h", "-help", "--help", "help":
+		c.usage(stdout)
+
+		return exitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		err := cmd.run(c, args[1:])
+		switch {
+		case err == nil, errors.Is(err, flag.ErrHelp):
+			return exitOK
+		case errors.Is(err, ErrFailed):
+			fmt.Fprintf(stderr, "jsonschema %s: %v\n", name, err)
+
+			return exitFailed
+		default:
+			fmt.Fprintf(stderr, "jsonschema %s: %v\n", name, err)
+
+			return exitError
+		}
+	}
+
+	fmt.Fprintf(stderr, "jsonschema: unknown command %q\n", name)
+	c.usage(stderr)
+
+	return exitError
+}
+
+func (c *cli) usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: jsonschema <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s%s\n", cmd.name, cmd.synopsis)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "jsonschema <command> -h" for the flags of a command.`)
+}
+
+// flags builds the flag set of a command.
+func (c *cli) flags(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: jsonschema %s %s\n", name, usage)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parse the arguments of a command, with flags interspersed with positional arguments.
+//
+// It returns the positional arguments.
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+
+			return nil, fmt.Errorf("%w: %w", err, ErrUsage)
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// oneOf checks the value of a flag against its allowed values.
+func oneOf(flagName, value string, allowed ...string) error {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid value %q for -%s, expected one of %s: %w",
+		value, flagName, strings.Join(allowed, ", "), ErrUsage,
+	)
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	petSchema = `$schema: https://json-schema.org/draft/2020-12/schema
+description: a pet
+type: object
+required: [name]
+properties:
+  name: {type: string, minLength: 2, description: the name}
+  tag: {$ref: "defs.json#/$defs/tag", description: a tag}
+`
+	defsSchema = `{"$defs": {"tag": {"type": "string", "description": "a tag"}}}`
+)
+
+func TestCLI(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "pet.yaml", petSchema)
+	writeFile(t, dir, "defs.json", defsSchema)
+	writeFile(t, dir, "valid.yaml", "name: rex\ntag: dog\n")
+	writeFile(t, dir, "invalid.json", `{"name": "r", "tag": 1}`)
+	writeFile(t, dir, "v2.json", `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"description": "a pet",
+		"type": "object",
+		"required": ["name", "age"],
+		"properties": {"name": {"type": "string"}, "age": {"type": "integer"}}
+	}`)
+	writeFile(t, dir, "overlay.yaml", `overlay: 1.0.0
+info: {title: limits, version: "1"}
+actions:
+  - target: $.properties.name
+    update: {maxLength: 10}
+`)
+
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	t.Run("should print the usage", func(t *testing.T) {
+		stdout, _, code := runCLI(t, "", "help")
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, "validate")
+
+		_, stderr, code := runCLI(t, "", "unknown")
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, `unknown command "unknown"`)
+	})
+
+	t.Run("should validate data", func(t *testing.T) {
+		stdout, _, code := runCLI(t, "", "validate", "-schema", path("pet.yaml"), path("valid.yaml"))
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, "valid.yaml: valid")
+
+		stdout, _, code = runCLI(t, "", "validate", path("invalid.json"), "-schema", path("pet.yaml"), "-output", "json")
+		assert.Equal(t, exitFailed, code)
+
+		var outcomes []validationOutcome
+		require.NoError(t, stdjson.Unmarshal([]byte(stdout), &outcomes))
+		require.Len(t, outcomes, 1)
+		assert.False(t, outcomes[0].Valid)
+
+		locations := make([]string, 0, len(outcomes[0].Errors))
+		for _, e := range outcomes[0].Errors {
+			locations = append(locations, e.KeywordLocation)
+		}
+		assert.Equal(t, []string{"/properties/name/minLength", "/properties/tag/$ref/type"}, locations)
+	})
+
+	t.Run("should validate data from the standard input", func(t *testing.T) {
+		_, _, code := runCLI(t, `{"name": "rex"}`, "validate", "-schema", path("pet.yaml"))
+		assert.Equal(t, exitOK, code)
+
+		_, stderr, code := runCLI(t, `{}`, "validate", "-schema", path("pet.yaml"), "-", "-")
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, "only be read once")
+	})
+
+	t.Run("should fail on usage errors", func(t *testing.T) {
+		_, stderr, code := runCLI(t, "", "validate", path("valid.yaml"))
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, "-schema is required")
+
+		_, _, code = runCLI(t, "", "convert", "-to", "draft9", path("pet.yaml"))
+		assert.Equal(t, exitError, code)
+
+		_, _, code = runCLI(t, "", "lint", path("missing.json"))
+		assert.Equal(t, exitError, code)
+	})
+
+	t.Run("should lint schemas", func(t *testing.T) {
+		writeFile(t, dir, "bounds.json", `{"description": "d", "minLength": 3, "maxLength": 2}`)
+
+		stdout, _, code := runCLI(t, "", "lint", path("pet.yaml"))
+		assert.Equal(t, exitOK, code)
+		assert.Empty(t, stdout)
+
+		stdout, _, code = runCLI(t, "", "lint", path("bounds.json"))
+		assert.Equal(t, exitFailed, code)
+		assert.Contains(t, stdout, "[contradictory-bounds]")
+
+		_, _, code = runCLI(t, "", "lint", "-fail-on", "none", path("bounds.json"))
+		assert.Equal(t, exitOK, code)
+	})
+
+	t.Run("should convert a schema", func(t *testing.T) {
+		stdout, _, code := runCLI(t, "", "convert", "-to", "draft7", path("pet.yaml"))
+		require.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, `"$schema": "http://json-schema.org/draft-07/schema#"`)
+		assert.Contains(t, stdout, `"$ref": "defs.json#/definitions/tag"`)
+	})
+
+	t.Run("should diff schemas", func(t *testing.T) {
+		stdout, _, code := runCLI(t, "", "diff", path("pet.yaml"), path("v2.json"))
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, "properties now required: age")
+
+		_, stderr, code := runCLI(t, "", "diff", path("pet.yaml"), path("v2.json"), "-fail-on", "breaking")
+		assert.Equal(t, exitFailed, code)
+		assert.Contains(t, stderr, "breaking")
+	})
+
+	t.Run("should bundle a schema", func(t *testing.T) {
+		stdout, _, code := runCLI(t, "", "bundle", path("pet.yaml"))
+		require.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, `"$ref": "#/$defs/tag"`)
+	})
+
+	t.Run("should apply an overlay", func(t *testing.T) {
+		stdout, _, code := runCLI(t, "", "overlay", "apply", path("overlay.yaml"), path("pet.yaml"))
+		require.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, `"maxLength": 10`)
+
+		_, _, code = runCLI(t, "", "overlay", "remove", path("overlay.yaml"))
+		assert.Equal(t, exitError, code)
+	})
+
+	t.Run("should generate fake data", func(t *testing.T) {
+		first, stderr, code := runCLI(t, "", "fake", "-count", "3", "-seed", "7", path("pet.yaml"))
+		require.Equal(t, exitOK, code, stderr)
+
+		second, _, _ := runCLI(t, "", "fake", "-count", "3", "-seed", "7", path("pet.yaml"))
+		assert.Equal(t, first, second)
+
+		decoder := stdjson.NewDecoder(strings.NewReader(first))
+		for range 3 {
+			var generated map[string]any
+			require.NoError(t, decoder.Decode(&generated))
+			assert.Contains(t, generated, "name")
+		}
+	})
+
+	t.Run("should infer a schema", func(t *testing.T) {
+		const samples = `[
+			{"id": "6ba7b810-9dad-11d1-80b4-00c04fd430c8", "count": 1, "tags": ["a"]},
+			{"id": "6ba7b811-9dad-11d1-80b4-00c04fd430c8", "count": 1.5}
+		]`
+
+		stdout, _, code := runCLI(t, samples, "infer", "-elements")
+		require.Equal(t, exitOK, code)
+
+		assert.JSONEq(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"type": "object",
+			"properties": {
+				"id": {"type": "string", "format": "uuid"},
+				"count": {"type": "number"},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			},
+			"required": ["id", "count"]
+		}`, stdout)
+	})
+
+	t.Run("should export the dependency graph", func(t *testing.T) {
+		stdout, _, code := runCLI(t, "", "graph", "--format", "dot", path("pet.yaml"))
+		require.Equal(t, exitOK, code)
+		assert.True(t, strings.HasPrefix(stdout, "digraph"))
+		assert.Contains(t, stdout, `"$ref"`)
+	})
+}
+
+func TestYAMLToJSON(t *testing.T) {
+	data, err := yamlToJSON([]byte("b: 1\na: [0x10, 1.5e3, true, ~, 'x']\nc: &anchor {d: 1}\ne: *anchor\n"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"b":1,"a":[16,1500,true,null,"x"],"c":{"d":1},"e":{"d":1}}`, string(data))
+
+	_, err = yamlToJSON([]byte("a: 1\n---\nb: 2\n"))
+	require.Error(t, err)
+}
+
+func runCLI(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+
+	return stdout.String(), stderr.String(), code
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	"github.com/fredbi/core/jsonschema"
+)
+
+// overlay applies overlays to a schema.
+func (c *cli) overlay(args []string) error {
+	const usage = "apply [-strict] <overlay> [schema]"
+
+	if len(args) == 0 || args[0] != "apply" {
+		if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+			c.flags("overlay", usage).Usage()
+
+			return nil
+		}
+
+		return fmt.Errorf(`expected "overlay apply": %w`, ErrUsage)
+	}
+
+	fs := c.flags("overlay", usage)
+	strict := fs.Bool("strict", false, "fail when an action matches no node")
+
+	names, err := parse(fs, args[1:])
+	if err != nil {
+		return err
+	}
+
+	if len(names) < 1 || len(names) > 2 { //nolint:mnd // overlay and schema
+		return fmt.Errorf("expected an overlay and a schema: %w", ErrUsage)
+	}
+
+	in, err := c.read(names[0])
+	if err != nil {
+		return err
+	}
+
+	o := jsonschema.MakeOverlay(jsonschema.WithOverlayStrict(*strict))
+	if err := o.Decode(bytes.NewReader(in.data)); err != nil {
+		return fmt.Errorf("%s: invalid overlay: %w", in.name, err)
+	}
+
+	sch, _, err := c.readSchema(firstOrStdin(names[1:]))
+	if err != nil {
+		return err
+	}
+
+	applied, report, err := o.Apply(sch)
+	if err != nil {
+		return err
+	}
+
+	for action := range report.Actions() {
+		fmt.Fprintf(c.stderr, "action %d with target %q: %d nodes touched\n",
+			action.Index(), action.Action().Target(), len(slices.Collect(action.Touched())),
+		)
+	}
+
+	return c.write(applied)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/fredbi/core/jsonschema/validator"
+)
+
+// validate JSON or YAML documents against a schema.
+func (c *cli) validate(args []string) error {
+	fs := c.flags("validate", "-schema <schema> [-output text|json] [data...]")
+	schemaName := fs.String("schema", "", "the schema to validate against (required)")
+	output := fs.String("output", "text", "the output format: text or json")
+
+	names, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *schemaName == "" {
+		return fmt.Errorf("-schema is required: %w", ErrUsage)
+	}
+
+	if err := oneOf("output", *output, "text", "json"); err != nil {
+		return err
+	}
+
+	sch, in, err := c.readSchema(*schemaName)
+	if err != nil {
+		return err
+	}
+
+	v, err := validator.New(sch, validator.WithBaseURI(in.uri))
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		names = []string{stdinName}
+	}
+
+	outcomes := make([]validationOutcome, 0, len(names))
+	invalid := 0
+	for _, name := range names {
+		doc, data, err := c.readDocument(name)
+		if err != nil {
+			return err
+		}
+
+		outcome := validationOutcome{Document: data.name, Valid: true, Errors: []validationError{}}
+
+		var violations validator.Violations
+		switch err := v.Validate(doc); {
+		case err == nil:
+		case errors.As(err, &violations):
+			outcome.Valid = false
+			for _, violation := range violations {
+				outcome.Errors = append(outcome.Errors, validationError{
+					InstanceLocation:        violation.InstanceLocation,
+					KeywordLocation:         violation.KeywordLocation,
+					AbsoluteKeywordLocation: violation.AbsoluteKeywordLocation,
+					Error:                   violation.Message,
+				})
+			}
+			invalid++
+		default:
+			return err
+		}
+
+		outcomes = append(outcomes, outcome)
+	}
+
+	if err := c.writeOutcomes(*output, outcomes); err != nil {
+		return err
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d documents are invalid: %w", invalid, len(outcomes), ErrFailed)
+	}
+
+	return nil
+}
+
+// validationOutcome is the outcome of the validation of a document, in the "basic" output format
+// of JSON schema.
+type validationOutcome struct {
+	Document string            `json:"document"`
+	Valid    bool              `json:"valid"`
+	Errors   []validationError `json:"errors"`
+}
+
+type validationError struct {
+	InstanceLocation        string `json:"instanceLocation"`
+	KeywordLocation         string `json:"keywordLocation"`
+	AbsoluteKeywordLocation string `json:"absoluteKeywordLocation"`
+	Error                   string `json:"error"`
+}
+
+func (c *cli) writeOutcomes(output string, outcomes []validationOutcome) error {
+	if output == "json" {
+		encoder := stdjson.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(outcomes)
+	}
+
+	for _, outcome := range outcomes {
+		if outcome.Valid {
+			fmt.Fprintf(c.stdout, "%s: valid\n", outcome.Document)
+
+			continue
+		}
+
+		fmt.Fprintf(c.stdout, "%s: invalid\n", outcome.Document)
+		for _, e := range outcome.Errors {
+			fmt.Fprintf(c.stdout, "  at %q: %s (%s)\n", e.InstanceLocation, e.Error, e.KeywordLocation)
+		}
+	}
+
+	return nil
+}
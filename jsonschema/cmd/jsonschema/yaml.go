@@ -0,0 +1,28 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fredbi/core/jsonschema"
+)
+
+// yamlToJSON converts a YAML document to JSON, preserving the order of keys.
+//
+// Streams of several YAML documents are not supported.
+func yamlToJSON(data []byte) ([]byte, error) {
+	documents, err := jsonschema.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(documents) {
+	case 0:
+		return nil, fmt.Errorf("invalid YAML: %w", io.EOF)
+	case 1:
+		return documents[0], nil
+	default:
+		return nil, errors.New("streams of several YAML documents are not supported")
+	}
+}
@@ -1,14 +1,28 @@
 package jsonschema
 
 import (
+	"bytes"
+	"fmt"
 	"io"
+	"io/fs"
 	"iter"
+	"path"
 	"slices"
+	"strconv"
 
+	lexer "github.com/fredbi/core/json/lexers/default-lexer"
 	"github.com/fredbi/core/json/stores"
+	swagfs "github.com/fredbi/core/swag/fs"
+	"github.com/fredbi/core/swag/loading"
 )
 
 // Collection holds a collection of [Schema] s that share the same document settings (store, etc.).
+//
+// Large collections, such as the schemas of an OpenAPI specification, repeat many values.
+// A deduplicating store shared by all schemas uses a fraction of the memory
+// (see [github.com/fredbi/core/json/stores/default-store.NewDedup]), e.g.:
+//
+//	c := MakeCollection(n, WithDocumentOptions(json.WithStore(store.NewDedup())))
 type Collection struct {
 	*options
 	schemas []Schema
@@ -40,6 +54,7 @@ func (c Collection) Len() int {
 	return len(c.schemas)
 }
 
+// Store returns the [stores.Store] shared by the schemas in this [Collection], or nil if the [Collection] is empty.
 func (c *Collection) Store() stores.Store {
 	if len(c.schemas) == 0 {
 		return nil
@@ -60,24 +75,161 @@ func (c *Collection) Schema(index int) Schema {
 	return c.schemas[index]
 }
 
-func (c *Collection) Reset() {
+// Reset empties the [Collection] and resets its options, which may be set again like with [MakeCollection].
+func (c *Collection) Reset(opts ...Option) {
 	c.schemas = c.schemas[:0]
-	// TODO: reset options
+	c.options = optionsWithDefaults(opts)
 }
 
 // DecodeAppend decodes a JSON bytes stream from an [io.Reader] an appends it to the [Collection] of schemas.
 //
-// If the input JSON is an array of schemas, the collection will contain several schemas (TODO).
+// If the input JSON is an array of schemas, the collection will contain several schemas, located by
+// the JSON pointer to their index in the array (see [Schema.Source]).
 //
 // If the input is a JSON schema object, a single schema will be appended.
 //
 // Notice that JSON boolean values "true" and "false" are valid JSON schemas.
 func (c *Collection) DecodeAppend(reader io.Reader) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("%w: %w", err, ErrLoad)
+	}
+
+	return c.appendJSON(data, Source{})
+}
+
+// DecodeAppendYAML decodes a stream of YAML documents from an [io.Reader] and appends them to the [Collection].
+//
+// Every document of the stream is a schema or an array of schemas, like with [Collection.DecodeAppend].
+func (c *Collection) DecodeAppendYAML(reader io.Reader) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("%w: %w", err, ErrLoad)
+	}
+
+	return c.appendYAML(data, Source{})
+}
+
+// LoadFile appends the schemas found in a file of the file system (see [WithFS]).
+//
+// Files with a ".yaml" or ".yml" extension are decoded as streams of YAML documents, other files as JSON.
+// The [Source] of every appended schema holds the name of the file.
+func (c *Collection) LoadFile(name string) error {
+	data, err := fs.ReadFile(c.filesystem(), name)
+	if err != nil {
+		return fmt.Errorf("%w: %w", err, ErrLoad)
+	}
+
+	if loading.YAMLMatcher(name) {
+		return c.appendYAML(data, Source{File: name})
+	}
+
+	return c.appendJSON(data, Source{File: name})
+}
+
+// LoadDir walks a directory of the file system (see [WithFS]) and appends the schemas found in all files
+// with a name matching any of the glob patterns (see [path.Match]), e.g. "*.schema.json".
+//
+// The default patterns are "*.json", "*.yaml" and "*.yml". Files are loaded with [Collection.LoadFile],
+// in lexical order, so that loading the same directory always yields the same collection.
+func (c *Collection) LoadDir(root string, patterns ...string) error {
+	if len(patterns) == 0 {
+		patterns = []string{"*.json", "*.yaml", "*.yml"}
+	}
+
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w: %w", pattern, err, ErrLoad)
+		}
+	}
+
+	return fs.WalkDir(c.filesystem(), root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("%w: %w", err, ErrLoad)
+		}
+
+		if entry.IsDir() || !slices.ContainsFunc(patterns, func(pattern string) bool {
+			matched, _ := path.Match(pattern, entry.Name())
+
+			return matched
+		}) {
+			return nil
+		}
+
+		return c.LoadFile(name)
+	})
+}
+
+func (c *Collection) filesystem() fs.FS {
+	if c.fsys == nil {
+		return swagfs.NewReadOnlyOsFS()
+	}
+
+	return c.fsys
+}
+
+// appendYAML appends the schemas from a stream of YAML documents.
+func (c *Collection) appendYAML(data []byte, source Source) error {
+	documents, err := YAMLToJSON(data)
+	if err != nil {
+		if source.File != "" {
+			err = fmt.Errorf("%s: %w", source.File, err)
+		}
+
+		return fmt.Errorf("%w: %w", err, ErrLoad)
+	}
+
+	for i, document := range documents {
+		source.Document = i
+		if err := c.appendJSON(document, source); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// appendJSON appends a schema, or an array of schemas.
+func (c *Collection) appendJSON(data []byte, source Source) error {
+	if trimmed := bytes.TrimLeft(data, " \t\r\n"); len(trimmed) == 0 || trimmed[0] != '[' {
+		return c.appendSchema(data, source)
+	}
+
+	// elements are split with a structural pre-scan, and only lexed when decoding each schema
+	runs, err := lexer.SplitArray(data, 1)
+	if err != nil {
+		return fmt.Errorf("%w: %w", located(source, err), ErrLoad)
+	}
+
+	i := 0
+	for _, run := range runs {
+		for elem := range lexer.ArrayElements(data, run) {
+			if err := c.appendSchema(data[elem.Start:elem.End], source.At("/"+strconv.Itoa(i))); err != nil {
+				return err
+			}
+			i++
+		}
+	}
+
+	return nil
+}
+
+func (c *Collection) appendSchema(data []byte, source Source) error {
 	sch := Make(withOptions(c.options))
-	if err := sch.Decode(reader); err != nil {
-		return err
+	if err := sch.UnmarshalJSON(data); err != nil {
+		return located(source, err)
 	}
+	sch.SetSource(source)
 	c.schemas = append(c.schemas, sch)
 
 	return nil
 }
+
+// located prefixes an error with the [Source] of the schema it applies to, when known.
+func located(source Source, err error) error {
+	if source.IsZero() {
+		return err
+	}
+
+	return fmt.Errorf("%s: %w", source, err)
+}
@@ -0,0 +1,128 @@
+package jsonschema
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/json"
+	store "github.com/fredbi/core/json/stores/default-store"
+)
+
+func TestCollection(t *testing.T) {
+	t.Run("should share a deduplicating store", func(t *testing.T) {
+		s := store.NewDedup()
+		c := MakeCollection(2, WithDocumentOptions(json.WithStore(s)))
+
+		const schema = `{
+			"description": "a pet in the store",
+			"properties": {
+				"kind": {"enum": ["domestic animal", "wild animal"]},
+				"owner": {"description": "a pet in the store"}
+			}
+		}`
+		require.NoError(t, c.DecodeAppend(strings.NewReader(schema)))
+		require.NoError(t, c.DecodeAppend(strings.NewReader(schema)))
+
+		require.Equal(t, 2, c.Len())
+		assert.Same(t, s, c.Store())
+
+		stats := s.Statistics()
+		assert.Equal(t, 3, stats.Unique)
+		assert.Equal(t, 5, stats.Hits)
+	})
+
+	t.Run("should decode an array of schemas", func(t *testing.T) {
+		c := MakeCollection(3)
+		require.NoError(t, c.DecodeAppend(strings.NewReader(` [{"type": "string"}, true, {"minimum": 1}]`)))
+
+		require.Equal(t, 3, c.Len())
+		assert.Equal(t, Source{Pointer: "/1"}, c.Schema(1).Source())
+		assert.Equal(t, "#/2", c.Schema(2).Source().String())
+
+		require.NoError(t, c.DecodeAppend(strings.NewReader(`[{"enum": [[1, "a,b"], {"x": "]"}]}, {"items": [true]}]`)))
+		require.Equal(t, 5, c.Len())
+		assert.Equal(t, "#/1", c.Schema(4).Source().String())
+
+		require.NoError(t, c.DecodeAppend(strings.NewReader(`[]`)))
+		require.Equal(t, 5, c.Len())
+
+		require.ErrorIs(t, c.DecodeAppend(strings.NewReader(`[{}, {"a": "]`)), ErrLoad)
+		require.ErrorIs(t, c.DecodeAppend(strings.NewReader(`[{}] {}`)), ErrLoad)
+	})
+
+	t.Run("should report the source of an invalid schema", func(t *testing.T) {
+		c := MakeCollection(1)
+		err := c.DecodeAppend(strings.NewReader(`[true, {"required": 1}]`))
+		require.Error(t, err)
+		assert.True(t, strings.HasPrefix(err.Error(), "#/1: "), err.Error())
+	})
+
+	t.Run("should decode a stream of YAML documents", func(t *testing.T) {
+		c := MakeCollection(3)
+		require.NoError(t, c.DecodeAppendYAML(strings.NewReader("type: string\n---\n- minimum: 1\n- false\n")))
+
+		require.Equal(t, 3, c.Len())
+		sources := make([]string, 0, c.Len())
+		for sch := range c.Schemas() {
+			sources = append(sources, sch.Source().String())
+		}
+		assert.Equal(t, []string{"#", "[1]#/0", "[1]#/1"}, sources)
+
+		require.ErrorIs(t, c.DecodeAppendYAML(strings.NewReader("a: [")), ErrLoad)
+	})
+
+	t.Run("should load the schemas of a directory", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"schemas/b.json":          {Data: []byte(`{"type": "string"}`)},
+			"schemas/a.yaml":          {Data: []byte("type: integer\n---\n[true, false]\n")},
+			"schemas/nested/c.yml":    {Data: []byte("minimum: 1\n")},
+			"schemas/README.md":       {Data: []byte("not a schema")},
+			"schemas/nested/d.schema": {Data: []byte(`{}`)},
+		}
+
+		c := MakeCollection(5, WithFS(fsys))
+		require.NoError(t, c.LoadDir("schemas"))
+
+		sources := make([]string, 0, c.Len())
+		for sch := range c.Schemas() {
+			sources = append(sources, sch.Source().String())
+		}
+		assert.Equal(t, []string{
+			"schemas/a.yaml#",
+			"schemas/a.yaml[1]#/0",
+			"schemas/a.yaml[1]#/1",
+			"schemas/b.json#",
+			"schemas/nested/c.yml#",
+		}, sources)
+
+		c.Reset(WithFS(fsys))
+		require.NoError(t, c.LoadDir("schemas", "*.schema", "b.*"))
+		assert.Equal(t, 2, c.Len())
+		assert.True(t, slices.ContainsFunc(slices.Collect(c.Schemas()), func(sch Schema) bool {
+			return sch.Source().File == "schemas/nested/d.schema"
+		}))
+
+		require.ErrorIs(t, c.LoadDir("schemas", "["), ErrLoad)
+		require.ErrorIs(t, c.LoadDir("missing"), ErrLoad)
+	})
+
+	t.Run("should reset schemas and options", func(t *testing.T) {
+		c := MakeCollection(1, WithVersion(VersionDraft4))
+		require.NoError(t, c.DecodeAppend(strings.NewReader(`{}`)))
+		c.Reset()
+		require.Zero(t, c.Len())
+
+		require.NoError(t, c.DecodeAppend(strings.NewReader(`{"minimum": 1}`)))
+		assert.Equal(t, VersionUndefined, c.Schema(0).Version())
+
+		c.Reset(WithVersion(VersionDraft7))
+		require.NoError(t, c.DecodeAppend(strings.NewReader(`{"const": 1}`)))
+		require.Equal(t, 1, c.Len())
+		assert.Equal(t, VersionDraft7, c.Schema(0).Version())
+	})
+}
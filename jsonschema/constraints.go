@@ -0,0 +1,101 @@
+package jsonschema
+
+import (
+	"github.com/fredbi/core/json/constrained"
+	"github.com/fredbi/core/json/stores/values"
+	"github.com/fredbi/core/swag/typeutils"
+)
+
+// constraints on the shape of schemas and keywords, checked when decoding.
+//
+//nolint:gochecknoglobals // declarations of constraints
+var (
+	// a schema is a boolean or an object
+	schemaConstraints = constrained.Constraints{
+		Root: constrained.KindBool | constrained.KindObject,
+	}
+
+	// an overlay is an object
+	overlayConstraints = constrained.Constraints{
+		Root: constrained.KindObject,
+		Keys: map[string]constrained.Kinds{
+			"overlay": constrained.KindString,
+			"info":    constrained.KindObject,
+			"extends": constrained.KindString,
+			"actions": constrained.KindArray,
+		},
+		Description: "an overlay object",
+	}
+
+	// "type" is a simple type or an array of simple types
+	typeConstraints = constrained.Constraints{
+		Root:  constrained.KindString | constrained.KindArray,
+		Elems: constrained.KindString,
+	}
+
+	// "items" is a schema or an array of schemas (before draft 2020)
+	itemsConstraints = constrained.Constraints{
+		Root:  constrained.KindBool | constrained.KindObject | constrained.KindArray,
+		Elems: constrained.KindBool | constrained.KindObject,
+	}
+
+	// e.g. "allOf", "anyOf", "oneOf", "prefixItems"
+	schemaArrayConstraints = constrained.Constraints{
+		Root:  constrained.KindArray,
+		Elems: constrained.KindBool | constrained.KindObject,
+	}
+
+	// e.g. "properties", "$defs"
+	schemaMapConstraints = constrained.Constraints{
+		Root: constrained.KindObject,
+	}
+
+	// e.g. "required"
+	stringArrayConstraints = constrained.Constraints{
+		Root:  constrained.KindArray,
+		Elems: constrained.KindString,
+	}
+
+	// "enum"
+	arrayConstraints = constrained.Constraints{
+		Root: constrained.KindArray,
+	}
+
+	// keywordConstraints indexes the constraints that apply to the value of a keyword.
+	keywordConstraints = map[values.InternedKey]*constrained.Constraints{
+		typeKey:                  &typeConstraints,
+		itemsKey:                 &itemsConstraints,
+		allOfKey:                 &schemaArrayConstraints,
+		anyOfKey:                 &schemaArrayConstraints,
+		oneOfKey:                 &schemaArrayConstraints,
+		prefixItemsKey:           &schemaArrayConstraints,
+		notKey:                   &schemaConstraints,
+		ifKey:                    &schemaConstraints,
+		thenKey:                  &schemaConstraints,
+		elseKey:                  &schemaConstraints,
+		additionalPropertiesKey:  &schemaConstraints,
+		additionalItemsKey:       &schemaConstraints,
+		containsKey:              &schemaConstraints,
+		propertyNamesKey:         &schemaConstraints,
+		unEvaluatedItemsKey:      &schemaConstraints,
+		unEvaluatedPropertiesKey: &schemaConstraints,
+		propertiesKey:            &schemaMapConstraints,
+		patternPropertiesKey:     &schemaMapConstraints,
+		dependentSchemasKey:      &schemaMapConstraints,
+		defsKey:                  &schemaMapConstraints,
+		definitionsKey:           &schemaMapConstraints,
+		requiredKey:              &stringArrayConstraints,
+		enumKey:                  &arrayConstraints,
+	}
+
+	// keywordVersions indexes the versions of JSON schema that support a keyword.
+	keywordVersions = typeutils.MergeMaps(nil,
+		coreConstraints,
+		applicatorConstraints,
+		validationConstraints,
+		metadataConstraints,
+	)
+
+	// boolean schemas are supported since draft 6
+	boolSchemaVersions = VersionRequirements{MinVersion: VersionDraft6}
+)
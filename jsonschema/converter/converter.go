@@ -2,23 +2,237 @@ package converter
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/json/nodes/light"
+	"github.com/fredbi/core/json/stores"
 	"github.com/fredbi/core/jsonschema"
 )
 
 // Converter knows how to convert a JSON schema from one version to another.
+//
+// Conversions are supported between all JSON schema drafts (draft 4 to draft 2020-12)
+// and OpenAPI schema objects (OpenAPI v2, v3.0 and v3.1), in both directions.
+//
+// Constructs that are renamed or restructured across versions are converted exactly, e.g.:
+//
+//   - "definitions" and "$defs", and the "$ref" pointers to definitions
+//   - boolean and numerical "exclusiveMinimum" and "exclusiveMaximum"
+//   - "items" as an array and "prefixItems", "additionalItems" and "items"
+//   - "dependencies" and "dependentRequired" or "dependentSchemas"
+//   - "nullable" (OpenAPI v3.0) or "x-nullable" (OpenAPI v2) and type arrays
+//   - the semantics of keywords next to "$ref", ignored until draft 7 and in OpenAPI v2 and v3.0
+//   - "if", "then", "else" with older drafts, as an equivalent combination of "anyOf", "allOf" and "not"
+//   - the OpenAPI "discriminator", "example" and "examples"
+//
+// Constructs that can't be converted exactly are reported as an [Issue], with the JSON pointer to
+// the construct in the source schema (see [Converter.ConvertWithReport]).
 type Converter struct {
+	options
 }
 
+// New [Converter] with options.
 func New(opts ...Option) *Converter {
-	return &Converter{} // TODO: pool, as usual
+	return &Converter{
+		options: optionsWithDefaults(opts),
+	}
 }
 
 // Convert a schema into another JSON schema version.
 //
-// This may error if some constructs that are not portable to the target version are found.
+// This errors if some constructs that are not portable to the target version are found,
+// unless [WithSkipIncompatible] is enabled.
 //
-// TODO: examples
+// The version of the source schema is determined by [jsonschema.Schema.Version], or by [WithSourceVersion].
 func (c *Converter) Convert(s jsonschema.Schema, to jsonschema.Version) (jsonschema.Schema, error) {
-	return jsonschema.Make(jsonschema.WithVersion(to)), errors.New("not implemented")
+	converted, _, err := c.ConvertWithReport(s, to)
+
+	return converted, err
+}
+
+// ConvertWithReport converts a schema into another JSON schema version, like [Converter.Convert] does,
+// and reports all the constructs that could not be converted exactly.
+func (c *Converter) ConvertWithReport(s jsonschema.Schema, to jsonschema.Version) (jsonschema.Schema, Report, error) {
+	from := s.Version()
+	if from == jsonschema.VersionUndefined {
+		from = c.sourceVersion
+	}
+
+	report := Report{From: from, To: to}
+	empty := jsonschema.Make(jsonschema.WithVersion(to))
+
+	if from == jsonschema.VersionUndefined {
+		return empty, report, fmt.Errorf("can't determine the version of the source schema: %w", ErrUndefinedVersion)
+	}
+
+	fromDialect, err := dialectOf(from)
+	if err != nil {
+		return empty, report, errors.Join(err, ErrConvert)
+	}
+
+	toDialect, err := dialectOf(to)
+	if err != nil {
+		return empty, report, errors.Join(err, ErrConvert)
+	}
+
+	store := s.Store()
+	if store == nil {
+		store = json.Make().Store()
+	}
+
+	conv := conversion{
+		options: c.options,
+		from:    fromDialect,
+		to:      toDialect,
+		store:   store,
+		report:  &report,
+	}
+
+	root, err := conv.schema(s.Document)
+	if err != nil && !errors.Is(err, errAbort) {
+		return empty, report, errors.Join(err, ErrConvert)
+	}
+
+	if report.HasUnportable() && !c.skipIncompatible {
+		return empty, report, unportableError(report)
+	}
+
+	converted := jsonschema.Make(jsonschema.WithVersion(to), jsonschema.WithDocumentOptions(json.WithStore(store)))
+	data, err := json.NewBuilder(store).WithRoot(root).Document().MarshalJSON()
+	if err != nil {
+		return empty, report, errors.Join(err, ErrConvert)
+	}
+
+	if err := converted.UnmarshalJSON(data); err != nil {
+		return empty, report, errors.Join(err, ErrConvert)
+	}
+
+	return converted, report, nil
+}
+
+func unportableError(report Report) error {
+	errs := make([]error, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		if issue.Kind == IssueUnportable {
+			errs = append(errs, issue)
+		}
+	}
+
+	return fmt.Errorf("converting from %v to %v: %w: %w", report.From, report.To, errors.Join(errs...), ErrUnportable)
+}
+
+// errAbort interrupts a conversion on the first unportable construct
+const errAbort Error = "conversion aborted"
+
+// conversion holds the state of a single conversion.
+type conversion struct {
+	options
+
+	from   dialect
+	to     dialect
+	store  stores.Store
+	report *Report
+	path   []string
+}
+
+func (c *conversion) push(segments ...string) {
+	c.path = append(c.path, segments...)
+}
+
+func (c *conversion) pop(n int) {
+	c.path = c.path[:len(c.path)-n]
+}
+
+// pointer to a keyword of the current schema.
+func (c *conversion) pointer(keyword string) string {
+	var w strings.Builder
+
+	for _, segment := range c.path {
+		w.WriteByte('/')
+		w.WriteString(json.EscapeToken(segment))
+	}
+
+	if keyword != "" {
+		w.WriteByte('/')
+		w.WriteString(json.EscapeToken(keyword))
+	}
+
+	return w.String()
+}
+
+// lossy reports a construct converted with some loss of information.
+func (c *conversion) lossy(keyword, format string, args ...any) {
+	c.report.Issues = append(c.report.Issues, Issue{
+		Kind:    IssueLossy,
+		Pointer: c.pointer(keyword),
+		Keyword: keyword,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// unportable reports a construct that can't be converted.
+//
+// It returns an error to interrupt the conversion, unless the conversion should carry on.
+func (c *conversion) unportable(keyword, format string, args ...any) error {
+	c.report.Issues = append(c.report.Issues, Issue{
+		Kind:    IssueUnportable,
+		Pointer: c.pointer(keyword),
+		Keyword: keyword,
+		Message: fmt.Sprintf(format, args...),
+	})
+
+	if c.skipIncompatible || c.continueOnError {
+		return nil
+	}
+
+	return errAbort
+}
+
+// unsupported reports a keyword that is not supported by the target version.
+func (c *conversion) unsupported(keyword string) error {
+	return c.unportable(keyword, "not supported by %v", c.to)
+}
+
+func (c *conversion) stringNode(value string) light.Node {
+	return light.NewBuilder(c.store).StringValue(value).Node()
+}
+
+func (c *conversion) boolNode(value bool) light.Node {
+	return light.NewBuilder(c.store).BoolValue(value).Node()
+}
+
+func (c *conversion) arrayNode(elems ...light.Node) light.Node {
+	return light.NewBuilder(c.store).Array().AppendElems(elems...).Node()
+}
+
+// objectNode builds an object with a single key.
+func (c *conversion) objectNode(key string, value light.Node) light.Node {
+	return light.NewBuilder(c.store).Object().AppendKey(key, value).Node()
+}
+
+// nodeOf yields the root node of a [json.Document].
+func nodeOf(d json.Document) light.Node {
+	return *d.Node()
+}
+
+func stringOf(d json.Document) (string, bool) {
+	if !d.IsString() {
+		return "", false
+	}
+
+	v, _ := d.Value()
+
+	return v.String(), true
+}
+
+func boolOf(d json.Document) (bool, bool) {
+	if !d.IsBool() {
+		return false, false
+	}
+
+	v, _ := d.Value()
+
+	return v.Bool(), true
 }
@@ -0,0 +1,312 @@
+package converter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/faker"
+)
+
+func TestConvert(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		from   jsonschema.Version
+		to     jsonschema.Version
+		input  string
+		output string
+		issues []string
+	}{
+		{
+			name: "draft 4 to draft 2020",
+			from: jsonschema.VersionDraft4,
+			to:   jsonschema.VersionDraft2020,
+			input: `{
+				"$schema": "http://json-schema.org/draft-04/schema#",
+				"id": "http://example.com/pet.json#pet",
+				"definitions": {"name": {"type": "string"}},
+				"properties": {
+					"name": {"$ref": "#/definitions/name"},
+					"age": {"type": "integer", "minimum": 0, "exclusiveMinimum": true, "maximum": 100},
+					"tags": {"items": [{"type": "string"}, {"type": "integer"}], "additionalItems": false},
+					"first": {"$ref": "#/properties/tags/items/0"}
+				},
+				"dependencies": {"age": ["name"], "tags": {"required": ["age"]}}
+			}`,
+			output: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"$id": "http://example.com/pet.json",
+				"$anchor": "pet",
+				"$defs": {"name": {"type": "string"}},
+				"properties": {
+					"name": {"$ref": "#/$defs/name"},
+					"age": {"type": "integer", "exclusiveMinimum": 0, "maximum": 100},
+					"tags": {"prefixItems": [{"type": "string"}, {"type": "integer"}], "items": false},
+					"first": {"$ref": "#/properties/tags/prefixItems/0"}
+				},
+				"dependentRequired": {"age": ["name"]},
+				"dependentSchemas": {"tags": {"required": ["age"]}}
+			}`,
+		},
+		{
+			name:   "draft 4 to draft 2020, with keywords next to $ref",
+			from:   jsonschema.VersionDraft4,
+			to:     jsonschema.VersionDraft2020,
+			input:  `{"properties": {"a": {"$ref": "#/definitions/a", "description": "a", "minLength": 1}}}`,
+			output: `{"properties": {"a": {"$ref": "#/$defs/a", "description": "a"}}}`,
+			issues: []string{"/properties/a/minLength"},
+		},
+		{
+			name: "draft 2020 to draft 4",
+			from: jsonschema.VersionDraft2020,
+			to:   jsonschema.VersionDraft4,
+			input: `{
+				"$defs": {"positive": {"exclusiveMinimum": 0, "minimum": -1}},
+				"properties": {
+					"a": {"$ref": "#/$defs/positive", "maximum": 10},
+					"b": {"const": "x", "enum": ["x", "y"]},
+					"c": {"if": {"minLength": 2}, "then": {"maxLength": 4}},
+					"d": {"prefixItems": [true, false], "items": {"type": "integer"}}
+				},
+				"dependentRequired": {"a": ["b"]},
+				"dependentSchemas": {"a": {"required": ["c"]}}
+			}`,
+			output: `{
+				"definitions": {"positive": {"minimum": 0, "exclusiveMinimum": true}},
+				"properties": {
+					"a": {"maximum": 10, "allOf": [{"$ref": "#/definitions/positive"}]},
+					"b": {"enum": ["x", "y"], "allOf": [{"enum": ["x"]}]},
+					"c": {"anyOf": [{"allOf": [{"minLength": 2}, {"maxLength": 4}]}, {"not": {"minLength": 2}}]},
+					"d": {"items": [{}, {"not": {}}], "additionalItems": {"type": "integer"}}
+				},
+				"dependencies": {"a": {"required": ["b"], "allOf": [{"required": ["c"]}]}}
+			}`,
+		},
+		{
+			name:   "draft 7 to draft 6: dropped annotations",
+			from:   jsonschema.VersionDraft7,
+			to:     jsonschema.VersionDraft6,
+			input:  `{"$comment": "x", "readOnly": true, "exclusiveMaximum": 5}`,
+			output: `{"exclusiveMaximum": 5}`,
+			issues: []string{"/$comment", "/readOnly"},
+		},
+		{
+			name: "OpenAPI v3.0 to v3.1",
+			from: jsonschema.VersionOpenAPIv303,
+			to:   jsonschema.VersionOpenAPIv310,
+			input: `{
+				"type": "object",
+				"discriminator": {"propertyName": "kind"},
+				"properties": {
+					"name": {"type": "string", "nullable": true, "example": "fido"},
+					"owner": {"$ref": "#/components/schemas/Owner"}
+				}
+			}`,
+			output: `{
+				"type": "object",
+				"discriminator": {"propertyName": "kind"},
+				"properties": {
+					"name": {"type": ["string", "null"], "examples": ["fido"]},
+					"owner": {"$ref": "#/components/schemas/Owner"}
+				}
+			}`,
+		},
+		{
+			name: "OpenAPI v2 to v3.0",
+			from: jsonschema.VersionOpenAPIv2,
+			to:   jsonschema.VersionOpenAPIv303,
+			input: `{
+				"discriminator": "kind",
+				"properties": {
+					"kind": {"type": "string", "x-nullable": true},
+					"owner": {"$ref": "#/definitions/Owner"}
+				}
+			}`,
+			output: `{
+				"discriminator": {"propertyName": "kind"},
+				"properties": {
+					"kind": {"type": "string", "nullable": true},
+					"owner": {"$ref": "#/components/schemas/Owner"}
+				}
+			}`,
+		},
+		{
+			name: "OpenAPI v3.1 to v3.0",
+			from: jsonschema.VersionOpenAPIv310,
+			to:   jsonschema.VersionOpenAPIv303,
+			input: `{
+				"$schema": "https://spec.openapis.org/oas/3.1/dialect/base",
+				"properties": {
+					"a": {"type": ["string", "integer", "null"]},
+					"b": {"examples": [1, 2], "exclusiveMinimum": 1}
+				}
+			}`,
+			output: `{
+				"properties": {
+					"a": {"anyOf": [{"type": "string", "nullable": true}, {"type": "integer", "nullable": true}]},
+					"b": {"example": 1, "minimum": 1, "exclusiveMinimum": true}
+				}
+			}`,
+			issues: []string{"/$schema", "/properties/b/examples"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := mustSchema(t, tc.from, tc.input)
+
+			converted, report, err := New().ConvertWithReport(s, tc.to)
+			require.NoError(t, err)
+
+			output, err := converted.MarshalJSON()
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.output, string(output))
+
+			assert.Equal(t, tc.from, report.From)
+			assert.Equal(t, tc.to, report.To)
+			assert.False(t, report.HasUnportable())
+			assert.Equal(t, len(tc.issues) == 0, report.IsExact())
+			assert.Equal(t, tc.issues, pointers(report))
+		})
+	}
+}
+
+func TestConvertUnportable(t *testing.T) {
+	const input = `{
+		"type": "object",
+		"properties": {
+			"a": {"type": "null"},
+			"b": {"items": [{"type": "string"}]},
+			"c": {"minimum": 1}
+		}
+	}`
+
+	t.Run("should fail on the first unportable construct", func(t *testing.T) {
+		s := mustSchema(t, jsonschema.VersionDraft7, input)
+
+		_, report, err := New().ConvertWithReport(s, jsonschema.VersionOpenAPIv2)
+		require.ErrorIs(t, err, ErrUnportable)
+		assert.True(t, report.HasUnportable())
+		assert.Equal(t, []string{"/properties/a/type"}, pointers(report))
+	})
+
+	t.Run("should report all unportable constructs", func(t *testing.T) {
+		s := mustSchema(t, jsonschema.VersionDraft7, input)
+
+		_, report, err := New(WithContinueOnError(true)).ConvertWithReport(s, jsonschema.VersionOpenAPIv2)
+		require.ErrorIs(t, err, ErrUnportable)
+		assert.Equal(t, []string{"/properties/a/type", "/properties/b/items"}, pointers(report))
+
+		for _, issue := range report.Issues {
+			assert.Equal(t, IssueUnportable, issue.Kind)
+		}
+	})
+
+	t.Run("should skip unportable constructs", func(t *testing.T) {
+		s := mustSchema(t, jsonschema.VersionDraft7, input)
+
+		converted, err := New(WithSkipIncompatible(true)).Convert(s, jsonschema.VersionOpenAPIv2)
+		require.NoError(t, err)
+
+		output, err := converted.MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"type": "object", "properties": {"a": {}, "b": {}, "c": {"minimum": 1}}}`, string(output))
+	})
+}
+
+func TestConvertVersion(t *testing.T) {
+	t.Run("should detect the version from $schema", func(t *testing.T) {
+		s := mustSchema(t, jsonschema.VersionUndefined,
+			`{"$schema": "http://json-schema.org/draft-07/schema#", "if": {"type": "string"}}`,
+		)
+		require.Equal(t, jsonschema.VersionDraft7, s.Version())
+
+		converted, err := New().Convert(s, jsonschema.VersionDraft2019)
+		require.NoError(t, err)
+		assert.Equal(t, jsonschema.VersionDraft2019, converted.Version())
+	})
+
+	t.Run("should use the source version from options", func(t *testing.T) {
+		s := mustSchema(t, jsonschema.VersionUndefined, `{"definitions": {}}`)
+
+		converted, err := New(WithSourceVersion(jsonschema.VersionDraft4)).Convert(s, jsonschema.VersionDraft2020)
+		require.NoError(t, err)
+
+		output, err := converted.MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"$defs": {}}`, string(output))
+	})
+
+	t.Run("should emit the canonical $schema of the target version", func(t *testing.T) {
+		for _, tc := range []struct {
+			to       jsonschema.Version
+			expected string
+		}{
+			{to: jsonschema.VersionDraft4, expected: "http://json-schema.org/draft-04/schema#"},
+			{to: jsonschema.VersionDraft6, expected: "http://json-schema.org/draft-06/schema#"},
+			{to: jsonschema.VersionDraft7, expected: "http://json-schema.org/draft-07/schema#"},
+			{to: jsonschema.VersionDraft2019, expected: "https://json-schema.org/draft/2019-09/schema"},
+		} {
+			s := mustSchema(t, jsonschema.VersionUndefined,
+				`{"$schema": "https://json-schema.org/draft/2020-12/schema", "type": "string"}`,
+			)
+
+			converted, err := New().Convert(s, tc.to)
+			require.NoError(t, err)
+
+			output, err := converted.MarshalJSON()
+			require.NoError(t, err)
+			assert.JSONEqf(t, `{"$schema": "`+tc.expected+`", "type": "string"}`, string(output), "to %v", tc.to)
+			assert.Equal(t, tc.to, jsonschema.VersionFromMetaSchemaURL(tc.expected))
+		}
+	})
+
+	t.Run("should fail when the source version is unknown", func(t *testing.T) {
+		s := mustSchema(t, jsonschema.VersionUndefined, `{}`)
+
+		_, err := New().Convert(s, jsonschema.VersionDraft2020)
+		require.ErrorIs(t, err, ErrUndefinedVersion)
+	})
+}
+
+func mustSchema(t *testing.T, version jsonschema.Version, input string) jsonschema.Schema {
+	t.Helper()
+
+	s := jsonschema.Make(jsonschema.WithVersion(version))
+	require.NoError(t, s.UnmarshalJSON([]byte(input)))
+
+	return s
+}
+
+func pointers(report Report) []string {
+	var result []string
+	for _, issue := range report.Issues {
+		result = append(result, issue.Pointer)
+	}
+
+	return result
+}
+
+func TestConvertGenerated(t *testing.T) {
+	const samples = 20
+
+	for _, from := range []jsonschema.Version{jsonschema.VersionDraft4, jsonschema.VersionDraft6, jsonschema.VersionDraft7} {
+		t.Run(fmt.Sprintf("should upgrade generated %v schemas to draft 2020", from), func(t *testing.T) {
+			f := faker.NewSchemaFaker(faker.WithSchemaVersion(from), faker.UseRefCycles(true))
+
+			for generated := range f.GenerateMany(samples) {
+				require.NoError(t, generated.Err())
+				input, err := generated.Document().MarshalJSON()
+				require.NoError(t, err)
+
+				converted, err := New().Convert(generated.Schema(), jsonschema.VersionDraft2020)
+				require.NoErrorf(t, err, "%s", input)
+				require.Equal(t, jsonschema.VersionDraft2020, converted.Version())
+
+				requirements := jsonschema.NewBuilder().From(converted).VersionRequirements()
+				require.Truef(t, requirements.Allows(jsonschema.VersionDraft2020), "%s", input)
+			}
+		})
+	}
+}
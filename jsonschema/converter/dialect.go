@@ -0,0 +1,168 @@
+package converter
+
+import (
+	"fmt"
+
+	"github.com/fredbi/core/jsonschema"
+)
+
+// dialect describes the features of a version of JSON schema.
+type dialect struct {
+	version jsonschema.Version
+
+	// draft is the JSON schema draft the dialect builds upon: one of VersionDraft4, VersionDraft6,
+	// VersionDraft7, VersionDraft2019 or VersionDraft2020.
+	draft jsonschema.Version
+
+	// openAPI is one of VersionOpenAPIv2, VersionOpenAPIv300 or VersionOpenAPIv310,
+	// or VersionUndefined for a plain JSON schema.
+	openAPI jsonschema.Version
+}
+
+func dialectOf(v jsonschema.Version) (dialect, error) {
+	d := dialect{version: v}
+
+	switch v {
+	case jsonschema.VersionDraft4, jsonschema.VersionDraft5:
+		d.draft = jsonschema.VersionDraft4
+	case jsonschema.VersionDraft6, jsonschema.VersionDraft7, jsonschema.VersionDraft2019, jsonschema.VersionDraft2020:
+		d.draft = v
+	case jsonschema.VersionOpenAPIv2, jsonschema.VersionOpenAPIv2Simple:
+		d.draft = jsonschema.VersionDraft4
+		d.openAPI = jsonschema.VersionOpenAPIv2
+	case jsonschema.VersionOpenAPIv300, jsonschema.VersionOpenAPIv301, jsonschema.VersionOpenAPIv302,
+		jsonschema.VersionOpenAPIv303, jsonschema.VersionOpenAPIv304:
+		d.draft = jsonschema.VersionDraft4
+		d.openAPI = jsonschema.VersionOpenAPIv300
+	case jsonschema.VersionOpenAPIv310, jsonschema.VersionOpenAPIv311, jsonschema.VersionOpenAPIv4Draft:
+		d.draft = jsonschema.VersionDraft2020
+		d.openAPI = jsonschema.VersionOpenAPIv310
+	default:
+		return d, fmt.Errorf("unsupported version %d: %w", v, ErrUndefinedVersion)
+	}
+
+	return d, nil
+}
+
+func (d dialect) String() string {
+	return d.version.String()
+}
+
+// isOpenAPI tells if the dialect is an OpenAPI schema object.
+func (d dialect) isOpenAPI() bool {
+	return d.openAPI != jsonschema.VersionUndefined
+}
+
+// restricted tells if the dialect is an OpenAPI v2 or v3.0 schema object, which only supports a subset of
+// JSON schema draft 4 (or 5) with some extensions.
+func (d dialect) restricted() bool {
+	return d.openAPI == jsonschema.VersionOpenAPIv2 || d.openAPI == jsonschema.VersionOpenAPIv300
+}
+
+// since tells if the dialect supports the JSON schema keywords introduced by some draft.
+//
+// Restricted OpenAPI dialects only support the keywords explicitly listed by the OpenAPI specification.
+func (d dialect) since(draft jsonschema.Version) bool {
+	return !d.restricted() && d.draft >= draft
+}
+
+// numericExclusive tells if "exclusiveMinimum" and "exclusiveMaximum" are numbers (since draft 6),
+// rather than booleans.
+func (d dialect) numericExclusive() bool {
+	return d.draft >= jsonschema.VersionDraft6
+}
+
+// idKey is the keyword for schema identifiers.
+func (d dialect) idKey() string {
+	if d.draft == jsonschema.VersionDraft4 {
+		return "id"
+	}
+
+	return "$id"
+}
+
+// defsKey is the keyword for schema definitions.
+func (d dialect) defsKey() string {
+	if d.since(jsonschema.VersionDraft2019) {
+		return "$defs"
+	}
+
+	return "definitions"
+}
+
+// nullableKey is the keyword of the OpenAPI extension that adds the "null" type to a schema.
+func (d dialect) nullableKey() string {
+	switch d.openAPI {
+	case jsonschema.VersionOpenAPIv2:
+		return "x-nullable"
+	case jsonschema.VersionOpenAPIv300:
+		return "nullable"
+	default:
+		return ""
+	}
+}
+
+// supports tells if the dialect supports a keyword.
+func (d dialect) supports(spec keywordSpec) bool {
+	switch d.openAPI {
+	case jsonschema.VersionOpenAPIv2:
+		return spec.openAPIv2
+	case jsonschema.VersionOpenAPIv300:
+		return spec.openAPIv30
+	default:
+		return d.draft >= spec.since
+	}
+}
+
+// keywordSpec describes a keyword that does not require any special conversion.
+type keywordSpec struct {
+	// since is the first draft which supports the keyword.
+	since jsonschema.Version
+
+	// annotation tells that dropping this keyword is lossy, but does not alter validation.
+	annotation bool
+
+	// openAPIv2 and openAPIv30 tell if OpenAPI schema objects support this keyword.
+	openAPIv2  bool
+	openAPIv30 bool
+}
+
+//nolint:gochecknoglobals // keywords specifications
+var keywords = map[string]keywordSpec{
+	"title":                 {since: jsonschema.VersionDraft4, annotation: true, openAPIv2: true, openAPIv30: true},
+	"description":           {since: jsonschema.VersionDraft4, annotation: true, openAPIv2: true, openAPIv30: true},
+	"default":               {since: jsonschema.VersionDraft4, annotation: true, openAPIv2: true, openAPIv30: true},
+	"format":                {since: jsonschema.VersionDraft4, openAPIv2: true, openAPIv30: true},
+	"multipleOf":            {since: jsonschema.VersionDraft4, openAPIv2: true, openAPIv30: true},
+	"maxLength":             {since: jsonschema.VersionDraft4, openAPIv2: true, openAPIv30: true},
+	"minLength":             {since: jsonschema.VersionDraft4, openAPIv2: true, openAPIv30: true},
+	"pattern":               {since: jsonschema.VersionDraft4, openAPIv2: true, openAPIv30: true},
+	"maxItems":              {since: jsonschema.VersionDraft4, openAPIv2: true, openAPIv30: true},
+	"minItems":              {since: jsonschema.VersionDraft4, openAPIv2: true, openAPIv30: true},
+	"uniqueItems":           {since: jsonschema.VersionDraft4, openAPIv2: true, openAPIv30: true},
+	"maxProperties":         {since: jsonschema.VersionDraft4, openAPIv2: true, openAPIv30: true},
+	"minProperties":         {since: jsonschema.VersionDraft4, openAPIv2: true, openAPIv30: true},
+	"required":              {since: jsonschema.VersionDraft4, openAPIv2: true, openAPIv30: true},
+	"enum":                  {since: jsonschema.VersionDraft4, openAPIv2: true, openAPIv30: true},
+	"properties":            {since: jsonschema.VersionDraft4, openAPIv2: true, openAPIv30: true},
+	"additionalProperties":  {since: jsonschema.VersionDraft4, openAPIv2: true, openAPIv30: true},
+	"allOf":                 {since: jsonschema.VersionDraft4, openAPIv2: true, openAPIv30: true},
+	"anyOf":                 {since: jsonschema.VersionDraft4, openAPIv30: true},
+	"oneOf":                 {since: jsonschema.VersionDraft4, openAPIv30: true},
+	"not":                   {since: jsonschema.VersionDraft4, openAPIv30: true},
+	"patternProperties":     {since: jsonschema.VersionDraft4},
+	"contains":              {since: jsonschema.VersionDraft6},
+	"propertyNames":         {since: jsonschema.VersionDraft6},
+	"$comment":              {since: jsonschema.VersionDraft7, annotation: true},
+	"readOnly":              {since: jsonschema.VersionDraft7, annotation: true, openAPIv2: true, openAPIv30: true},
+	"writeOnly":             {since: jsonschema.VersionDraft7, annotation: true, openAPIv30: true},
+	"contentMediaType":      {since: jsonschema.VersionDraft7, annotation: true},
+	"contentEncoding":       {since: jsonschema.VersionDraft7, annotation: true},
+	"contentSchema":         {since: jsonschema.VersionDraft2019, annotation: true},
+	"deprecated":            {since: jsonschema.VersionDraft2019, annotation: true, openAPIv30: true},
+	"$vocabulary":           {since: jsonschema.VersionDraft2019, annotation: true},
+	"minContains":           {since: jsonschema.VersionDraft2019},
+	"maxContains":           {since: jsonschema.VersionDraft2019},
+	"unevaluatedProperties": {since: jsonschema.VersionDraft2019},
+	"unevaluatedItems":      {since: jsonschema.VersionDraft2019},
+}
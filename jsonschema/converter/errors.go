@@ -0,0 +1,23 @@
+package converter
+
+// Error is an error raised by the [Converter].
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrConvert is the generic error raised when converting a schema.
+	ErrConvert Error = "schema conversion error"
+
+	// ErrUnportable is raised when the source schema uses constructs that have no equivalent in the target version.
+	//
+	// See [WithSkipIncompatible] to drop these constructs instead.
+	ErrUnportable Error = "unportable schema construct"
+
+	// ErrUndefinedVersion is raised when the version of the source schema cannot be determined.
+	//
+	// See [WithSourceVersion].
+	ErrUndefinedVersion Error = "undefined schema version"
+)
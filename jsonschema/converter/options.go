@@ -1,16 +1,50 @@
 package converter
 
+import "github.com/fredbi/core/jsonschema"
+
+// Option configures the [Converter].
 type Option func(*options)
 
 type options struct {
+	skipIncompatible bool
+	continueOnError  bool
+	sourceVersion    jsonschema.Version
+}
+
+func optionsWithDefaults(opts []Option) options {
+	var o options
+
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	return o
 }
 
+// WithSkipIncompatible drops the constructs of the source schema that can't be converted to the target version,
+// instead of failing.
+//
+// Dropped constructs are listed in the [Report] as [IssueUnportable].
 func WithSkipIncompatible(enabled bool) Option {
 	return func(o *options) {
+		o.skipIncompatible = enabled
 	}
 }
 
+// WithContinueOnError carries on the conversion after the first unportable construct is found,
+// so all unportable constructs are reported at once.
+//
+// This option has no effect when [WithSkipIncompatible] is enabled.
 func WithContinueOnError(enabled bool) Option {
 	return func(o *options) {
+		o.continueOnError = enabled
+	}
+}
+
+// WithSourceVersion sets the version of the source schema, when it is not enforced by the schema itself
+// (see [jsonschema.Schema.Version]).
+func WithSourceVersion(version jsonschema.Version) Option {
+	return func(o *options) {
+		o.sourceVersion = version
 	}
 }
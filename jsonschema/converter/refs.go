@@ -0,0 +1,128 @@
+package converter
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/jsonschema"
+)
+
+const (
+	openAPIv2Definitions = "#/definitions/"
+	openAPIv3Definitions = "#/components/schemas/"
+)
+
+// position in a JSON pointer to a subschema.
+type position uint8
+
+const (
+	atSchema position = iota
+	atSchemaMap
+	atSchemaArray
+	atValue
+)
+
+// rewriteRef rewrites the JSON pointer in the fragment of a "$ref" with the keywords of the target version.
+//
+// References to the definitions of an OpenAPI document are rewritten as well, when converting between
+// OpenAPI v2 and v3.
+func (c *conversion) rewriteRef(ref string) string {
+	base, fragment, found := strings.Cut(ref, "#")
+	if !found || !strings.HasPrefix(fragment, "/") {
+		// no fragment, or a named anchor
+		return ref
+	}
+
+	if c.from.isOpenAPI() && c.to.isOpenAPI() && base == "" {
+		if name, isDefinition := strings.CutPrefix(ref, openAPIDefinitions(c.from)); isDefinition {
+			return openAPIDefinitions(c.to) + c.rewritePointer(name, atSchemaMap)
+		}
+	}
+
+	return base + "#/" + c.rewritePointer(strings.TrimPrefix(fragment, "/"), atSchema)
+}
+
+// openAPIDefinitions is the prefix of references to the schemas defined by an OpenAPI document.
+func openAPIDefinitions(d dialect) string {
+	if d.openAPI == jsonschema.VersionOpenAPIv2 {
+		return openAPIv2Definitions
+	}
+
+	return openAPIv3Definitions
+}
+
+// rewritePointer rewrites the tokens of a JSON pointer to a subschema, starting from some position.
+func (c *conversion) rewritePointer(pointer string, state position) string {
+	tokens := strings.Split(pointer, "/")
+
+	for i, token := range tokens {
+		switch state {
+		case atSchemaMap, atSchemaArray:
+			state = atSchema
+
+			continue
+		case atValue:
+			return strings.Join(tokens, "/")
+		}
+
+		var next string
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+
+		tokens[i], state = c.rewriteToken(token, next)
+	}
+
+	return strings.Join(tokens, "/")
+}
+
+// rewriteToken rewrites a keyword in a JSON pointer to a subschema.
+func (c *conversion) rewriteToken(token, next string) (string, position) {
+	toDraft2019 := c.to.since(jsonschema.VersionDraft2019)
+	toDraft2020 := c.to.since(jsonschema.VersionDraft2020)
+
+	switch token {
+	case "definitions", "$defs":
+		return c.to.defsKey(), atSchemaMap
+	case "dependencies", "dependentSchemas":
+		if toDraft2019 {
+			return "dependentSchemas", atSchemaMap
+		}
+
+		return "dependencies", atSchemaMap
+	case "prefixItems":
+		if toDraft2020 {
+			return token, atSchemaArray
+		}
+
+		return "items", atSchemaArray
+	case "items":
+		if _, err := strconv.Atoi(next); err == nil && c.from.draft < jsonschema.VersionDraft2020 {
+			// a tuple
+			if toDraft2020 {
+				return "prefixItems", atSchemaArray
+			}
+
+			return token, atSchemaArray
+		}
+
+		return token, atSchema
+	case "additionalItems":
+		if toDraft2020 {
+			return "items", atSchema
+		}
+
+		return token, atSchema
+	}
+
+	switch jsonschema.SubschemaKeyword(token) {
+	case jsonschema.SubschemaSingle:
+		return token, atSchema
+	case jsonschema.SubschemaArray:
+		return token, atSchemaArray
+	case jsonschema.SubschemaMap:
+		return token, atSchemaMap
+	default:
+		return token, atValue
+	}
+}
@@ -0,0 +1,79 @@
+package converter
+
+import (
+	"fmt"
+
+	"github.com/fredbi/core/jsonschema"
+)
+
+// IssueKind qualifies an [Issue] found when converting a schema.
+type IssueKind uint8
+
+const (
+	// IssueLossy is a construct that is converted with some loss of information, such as a dropped annotation.
+	//
+	// The validation semantics of the schema are preserved.
+	IssueLossy IssueKind = iota + 1
+
+	// IssueUnportable is a construct that has no equivalent in the target version.
+	//
+	// Unportable constructs are dropped, so the validation semantics of the converted schema differ.
+	IssueUnportable
+)
+
+func (k IssueKind) String() string {
+	switch k {
+	case IssueLossy:
+		return "lossy"
+	case IssueUnportable:
+		return "unportable"
+	default:
+		return "unknown"
+	}
+}
+
+// Issue describes a construct of the source schema that can't be converted exactly.
+type Issue struct {
+	Kind IssueKind
+
+	// Pointer is the JSON pointer to the construct in the source schema.
+	Pointer string
+
+	// Keyword is the keyword of the construct.
+	Keyword string
+
+	// Message explains the issue.
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%v: %q at %q: %s", i.Kind, i.Keyword, i.Pointer, i.Message)
+}
+
+// Error renders an [Issue] as an error.
+func (i Issue) Error() string {
+	return i.String()
+}
+
+// Report lists the [Issue] s found when converting a schema.
+type Report struct {
+	From   jsonschema.Version
+	To     jsonschema.Version
+	Issues []Issue
+}
+
+// IsExact tells if the conversion did not raise any [Issue].
+func (r Report) IsExact() bool {
+	return len(r.Issues) == 0
+}
+
+// HasUnportable tells if the conversion found some unportable constructs.
+func (r Report) HasUnportable() bool {
+	for _, issue := range r.Issues {
+		if issue.Kind == IssueUnportable {
+			return true
+		}
+	}
+
+	return false
+}
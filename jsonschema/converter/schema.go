@@ -0,0 +1,1166 @@
+package converter
+
+import (
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/json/nodes/light"
+	"github.com/fredbi/core/jsonschema"
+)
+
+// dynamicAnchor is the name of the "$dynamicAnchor" equivalent to "$recursiveAnchor": true
+const dynamicAnchor = "meta"
+
+// schema converts a schema.
+func (c *conversion) schema(d json.Document) (light.Node, error) {
+	if value, ok := boolOf(d); ok {
+		return c.boolSchema(value)
+	}
+
+	if !d.IsObject() {
+		return nodeOf(d), nil
+	}
+
+	o := object{
+		c:     c,
+		src:   d,
+		index: make(map[string]int, d.Len()),
+	}
+
+	return o.convert()
+}
+
+// subschema converts a schema located under the current schema.
+func (c *conversion) subschema(d json.Document, segments ...string) (light.Node, error) {
+	c.push(segments...)
+	defer c.pop(len(segments))
+
+	return c.schema(d)
+}
+
+func (c *conversion) schemaArray(d json.Document, keyword string) (light.Node, error) {
+	if !d.IsArray() {
+		return nodeOf(d), nil
+	}
+
+	elems := make([]light.Node, 0, d.Len())
+	for i, elem := range d.IndexedElems() {
+		n, err := c.subschema(elem, keyword, strconv.Itoa(i))
+		if err != nil {
+			return n, err
+		}
+
+		elems = append(elems, n)
+	}
+
+	return c.arrayNode(elems...), nil
+}
+
+func (c *conversion) schemaMap(d json.Document, keyword string) (light.Node, error) {
+	if !d.IsObject() {
+		return nodeOf(d), nil
+	}
+
+	b := light.NewBuilder(c.store).Object()
+	for name, elem := range d.Pairs() {
+		n, err := c.subschema(elem, keyword, name)
+		if err != nil {
+			return n, err
+		}
+
+		b.AppendKey(name, n)
+	}
+
+	return b.Node(), b.Err()
+}
+
+// boolSchema converts the true or false schemas, which are not supported before draft 6.
+func (c *conversion) boolSchema(value bool) (light.Node, error) {
+	if c.to.since(jsonschema.VersionDraft6) {
+		return c.boolNode(value), nil
+	}
+
+	empty := light.NewBuilder(c.store).Object().Node()
+	if value {
+		return empty, nil
+	}
+
+	if !c.to.supports(keywords["not"]) {
+		return empty, c.unportable("", "the false schema is not supported by %v", c.to)
+	}
+
+	return c.objectNode("not", empty), nil
+}
+
+type member struct {
+	key  string
+	node light.Node
+}
+
+// group of keywords converted together.
+type group uint16
+
+const (
+	groupIdentity group = 1 << iota
+	groupDefinitions
+	groupMinimum
+	groupMaximum
+	groupItems
+	groupDependencies
+	groupType
+	groupExamples
+	groupCondition
+)
+
+// object converts a schema object.
+type object struct {
+	c     *conversion
+	src   json.Document
+	done  group
+	index map[string]int
+
+	members []member
+
+	// extra constraints, merged into "allOf"
+	allOf []light.Node
+
+	// refSiblingsIgnored is set when the keywords next to "$ref" are ignored by the source version,
+	// but not by the target version.
+	refSiblingsIgnored bool
+
+	// wrapRef is set when the keywords next to "$ref" apply with the source version, but are ignored
+	// by the target version.
+	wrapRef bool
+}
+
+func (o *object) convert() (light.Node, error) {
+	o.prepareRef()
+
+	for key, value := range o.src.Pairs() {
+		if err := o.keyword(key, value); err != nil {
+			return light.Node{}, err
+		}
+	}
+
+	o.mergeAllOf()
+
+	b := light.NewBuilder(o.c.store).Object()
+	for _, m := range o.members {
+		b.AppendKey(m.key, m.node)
+	}
+
+	return b.Node(), b.Err()
+}
+
+func (o *object) keyword(key string, value json.Document) error {
+	c := o.c
+
+	if o.refSiblingsIgnored && !isRefCompanion(key) {
+		c.lossy(key, "ignored next to \"$ref\" by %v: removed", c.from)
+
+		return nil
+	}
+
+	if spec, ok := keywords[key]; ok {
+		if !c.from.restricted() && !c.from.supports(spec) {
+			return o.unknown(key, value)
+		}
+
+		return o.generic(key, spec, value)
+	}
+
+	switch key {
+	case "$schema":
+		return o.dollarSchema(value)
+	case "$ref":
+		return o.ref(value)
+	case "id", "$id":
+		if key != c.from.idKey() {
+			return o.unknown(key, value)
+		}
+
+		return o.once(groupIdentity, o.identity)
+	case "$anchor":
+		if c.from.draft < jsonschema.VersionDraft2019 {
+			return o.unknown(key, value)
+		}
+
+		return o.once(groupIdentity, o.identity)
+	case "definitions", "$defs":
+		if key == "$defs" && c.from.draft < jsonschema.VersionDraft2019 {
+			return o.unknown(key, value)
+		}
+
+		return o.once(groupDefinitions, o.definitions)
+	case "minimum", "exclusiveMinimum":
+		return o.once(groupMinimum, func() error { return o.bound(lowerBound) })
+	case "maximum", "exclusiveMaximum":
+		return o.once(groupMaximum, func() error { return o.bound(upperBound) })
+	case "items":
+		return o.once(groupItems, o.items)
+	case "prefixItems":
+		if c.from.draft < jsonschema.VersionDraft2020 {
+			return o.unknown(key, value)
+		}
+
+		return o.once(groupItems, o.items)
+	case "additionalItems":
+		if c.from.draft >= jsonschema.VersionDraft2020 {
+			return o.unknown(key, value)
+		}
+
+		return o.once(groupItems, o.items)
+	case "dependencies":
+		if c.from.draft >= jsonschema.VersionDraft2019 {
+			return o.unknown(key, value)
+		}
+
+		return o.once(groupDependencies, o.dependencies)
+	case "dependentRequired", "dependentSchemas":
+		if c.from.draft < jsonschema.VersionDraft2019 {
+			return o.unknown(key, value)
+		}
+
+		return o.once(groupDependencies, o.dependencies)
+	case "type":
+		return o.once(groupType, o.types)
+	case "nullable", "x-nullable":
+		if key != c.from.nullableKey() {
+			return o.unknown(key, value)
+		}
+
+		return o.once(groupType, o.types)
+	case "example":
+		if !c.from.isOpenAPI() && !c.to.isOpenAPI() {
+			return o.unknown(key, value)
+		}
+
+		return o.once(groupExamples, o.examples)
+	case "examples":
+		return o.once(groupExamples, o.examples)
+	case "const":
+		if !c.from.restricted() && c.from.draft < jsonschema.VersionDraft6 {
+			return o.unknown(key, value)
+		}
+
+		return o.constant(value)
+	case "if", "then", "else":
+		if !c.from.restricted() && c.from.draft < jsonschema.VersionDraft7 {
+			return o.unknown(key, value)
+		}
+
+		return o.once(groupCondition, o.condition)
+	case "$recursiveRef", "$recursiveAnchor", "$dynamicRef", "$dynamicAnchor":
+		return o.dynamic(key, value)
+	case "discriminator":
+		return o.discriminator(value)
+	case "xml", "externalDocs":
+		return o.openAPIAnnotation(key, value)
+	default:
+		return o.unknown(key, value)
+	}
+}
+
+// once converts a group of keywords the first time one of them is found.
+func (o *object) once(g group, convert func() error) error {
+	if o.done&g != 0 {
+		return nil
+	}
+
+	o.done |= g
+
+	return convert()
+}
+
+func (o *object) emit(key string, value light.Node) {
+	if _, exists := o.index[key]; exists {
+		o.c.lossy(key, "conflicting keywords with %v: the first one is kept", o.c.to)
+
+		return
+	}
+
+	o.index[key] = len(o.members)
+	o.members = append(o.members, member{key: key, node: value})
+}
+
+// addConstraint adds a keyword to the schema, or to "allOf" if the keyword is already used.
+func (o *object) addConstraint(key string, value light.Node) {
+	_, conflict := o.src.AtKey(key)
+	if _, emitted := o.index[key]; !conflict && !emitted {
+		o.emit(key, value)
+
+		return
+	}
+
+	o.allOf = append(o.allOf, o.c.objectNode(key, value))
+}
+
+func (o *object) mergeAllOf() {
+	if len(o.allOf) == 0 {
+		return
+	}
+
+	i, exists := o.index["allOf"]
+	if !exists {
+		o.emit("allOf", o.c.arrayNode(o.allOf...))
+
+		return
+	}
+
+	existing := o.members[i].node
+	elems := slices.Grow(o.allOf, existing.Len())
+	for elem := range existing.Elems() {
+		elems = append(elems, elem)
+	}
+	o.members[i].node = o.c.arrayNode(elems...)
+}
+
+func (o *object) generic(key string, spec keywordSpec, value json.Document) error {
+	c := o.c
+
+	if !c.to.supports(spec) {
+		if spec.annotation {
+			c.lossy(key, "not supported by %v: removed", c.to)
+
+			return nil
+		}
+
+		return c.unsupported(key)
+	}
+
+	if key == "required" && value.Len() == 0 && c.to.draft == jsonschema.VersionDraft4 {
+		// draft 4 requires at least one required property
+		return nil
+	}
+
+	var (
+		n   light.Node
+		err error
+	)
+
+	switch jsonschema.SubschemaKeyword(key) {
+	case jsonschema.SubschemaSingle:
+		n, err = c.subschema(value, key)
+	case jsonschema.SubschemaArray:
+		n, err = c.schemaArray(value, key)
+	case jsonschema.SubschemaMap:
+		n, err = c.schemaMap(value, key)
+	case jsonschema.NoSubschema:
+		n = nodeOf(value)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	o.emit(key, n)
+
+	return nil
+}
+
+// unknown keywords are copied, but for OpenAPI schema objects which only support "x-" extensions.
+func (o *object) unknown(key string, value json.Document) error {
+	if o.c.to.restricted() && !strings.HasPrefix(key, "x-") {
+		return o.c.unportable(key, "unknown keyword: not supported by %v", o.c.to)
+	}
+
+	o.emit(key, nodeOf(value))
+
+	return nil
+}
+
+func (o *object) dollarSchema(value json.Document) error {
+	c := o.c
+
+	if c.to.restricted() {
+		c.lossy("$schema", "not supported by %v: removed", c.to)
+
+		return nil
+	}
+
+	url := c.to.version.MetaSchemaURL()
+	if url == "" {
+		o.emit("$schema", nodeOf(value))
+
+		return nil
+	}
+
+	o.emit("$schema", c.stringNode(url))
+
+	return nil
+}
+
+func (o *object) prepareRef() {
+	if _, hasRef := o.src.AtKey("$ref"); !hasRef {
+		return
+	}
+
+	fromIgnores := !o.c.from.since(jsonschema.VersionDraft2019)
+	toIgnores := !o.c.to.since(jsonschema.VersionDraft2019)
+
+	switch {
+	case fromIgnores && !toIgnores:
+		o.refSiblingsIgnored = true
+	case !fromIgnores && toIgnores:
+		for key := range o.src.Pairs() {
+			if !isRefCompanion(key) {
+				o.wrapRef = true
+
+				return
+			}
+		}
+	}
+}
+
+// isRefCompanion tells if a keyword may be kept next to "$ref" with all versions.
+func isRefCompanion(key string) bool {
+	if strings.HasPrefix(key, "x-") {
+		return true
+	}
+
+	switch key {
+	case "$ref", "$schema", "id", "$id", "$anchor", "definitions", "$defs", "$comment",
+		"title", "description", "default", "example", "examples":
+		return true
+	default:
+		return false
+	}
+}
+
+func (o *object) ref(value json.Document) error {
+	ref, ok := stringOf(value)
+	if !ok {
+		o.emit("$ref", nodeOf(value))
+
+		return nil
+	}
+
+	n := o.c.stringNode(o.c.rewriteRef(ref))
+	if o.wrapRef {
+		// other keywords apply next to the "$ref"
+		o.allOf = slices.Insert(o.allOf, 0, o.c.objectNode("$ref", n))
+
+		return nil
+	}
+
+	o.emit("$ref", n)
+
+	return nil
+}
+
+func (o *object) identity() error {
+	c := o.c
+
+	var (
+		id, anchor        string
+		hasID, hasAnchor  bool
+		idKey, anchorKey  = c.from.idKey(), "$anchor"
+		reportedKeyword   = idKey
+		fromAnchorKeyword bool
+	)
+
+	if v, ok := o.src.AtKey(idKey); ok {
+		id, hasID = stringOf(v)
+	}
+
+	if c.from.draft >= jsonschema.VersionDraft2019 {
+		if v, ok := o.src.AtKey(anchorKey); ok {
+			anchor, hasAnchor = stringOf(v)
+			fromAnchorKeyword = hasAnchor
+		}
+	}
+
+	if hasID && !hasAnchor {
+		// before draft 2019, a plain name fragment is an anchor
+		if base, fragment, found := strings.Cut(id, "#"); found && fragment != "" && !strings.HasPrefix(fragment, "/") {
+			id, anchor, hasAnchor = base, fragment, true
+			hasID = base != ""
+		}
+	}
+
+	if !hasID && !hasAnchor {
+		return nil
+	}
+
+	if fromAnchorKeyword && !hasID {
+		reportedKeyword = anchorKey
+	}
+
+	if c.to.restricted() {
+		return c.unportable(reportedKeyword, "schema identifiers are not supported by %v", c.to)
+	}
+
+	if c.to.since(jsonschema.VersionDraft2019) {
+		if hasID {
+			o.emit("$id", c.stringNode(strings.TrimSuffix(id, "#")))
+		}
+
+		if hasAnchor {
+			o.emit(anchorKey, c.stringNode(anchor))
+		}
+
+		return nil
+	}
+
+	// before draft 2019, an anchor is a fragment of the identifier
+	if hasAnchor {
+		id = strings.TrimSuffix(id, "#") + "#" + anchor
+	}
+
+	o.emit(c.to.idKey(), c.stringNode(id))
+
+	return nil
+}
+
+func (o *object) definitions() error {
+	c := o.c
+
+	sources := []string{"definitions"}
+	if c.from.draft >= jsonschema.VersionDraft2019 {
+		sources = []string{"$defs", "definitions"}
+	}
+
+	b := light.NewBuilder(c.store).Object()
+	seen := make(map[string]struct{})
+	var found bool
+
+	for _, keyword := range sources {
+		defs, ok := o.src.AtKey(keyword)
+		if !ok || !defs.IsObject() {
+			continue
+		}
+
+		if c.to.restricted() {
+			if err := c.unportable(keyword, "definitions are not supported by %v", c.to); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		found = true
+		for name, def := range defs.Pairs() {
+			if _, duplicate := seen[name]; duplicate {
+				c.lossy(keyword, "definition %q is defined twice: the first one is kept", name)
+
+				continue
+			}
+			seen[name] = struct{}{}
+
+			n, err := c.subschema(def, keyword, name)
+			if err != nil {
+				return err
+			}
+
+			b.AppendKey(name, n)
+		}
+	}
+
+	if found {
+		o.emit(c.to.defsKey(), b.Node())
+	}
+
+	return b.Err()
+}
+
+type bound struct {
+	limit     string
+	exclusive string
+	lower     bool
+}
+
+//nolint:gochecknoglobals // constant descriptors
+var (
+	lowerBound = bound{limit: "minimum", exclusive: "exclusiveMinimum", lower: true}
+	upperBound = bound{limit: "maximum", exclusive: "exclusiveMaximum"}
+)
+
+// stricter tells if the exclusive limit is stricter than the inclusive one.
+func (b bound) stricter(exclusive, inclusive json.Document) bool {
+	cmp := numberOf(exclusive).Cmp(numberOf(inclusive))
+	if b.lower {
+		return cmp >= 0
+	}
+
+	return cmp <= 0
+}
+
+func numberOf(d json.Document) *big.Float {
+	v, _ := d.Value()
+	f, _, err := big.ParseFloat(string(v.NumberValue().Value), 10, 0, big.ToNearestEven)
+	if err != nil {
+		return new(big.Float)
+	}
+
+	return f
+}
+
+// bound converts a lower or upper bound: "exclusiveMinimum" and "exclusiveMaximum" are booleans until draft 5,
+// and numbers afterwards.
+func (o *object) bound(b bound) error {
+	c := o.c
+	limit, hasLimit := o.src.AtKey(b.limit)
+	exclusive, hasExclusive := o.src.AtKey(b.exclusive)
+
+	switch {
+	case hasExclusive && exclusive.IsBool():
+		isExclusive, _ := boolOf(exclusive)
+
+		switch {
+		case !hasLimit:
+			if isExclusive {
+				c.lossy(b.exclusive, "has no effect without %q: removed", b.limit)
+			}
+		case !c.to.numericExclusive():
+			o.emit(b.limit, nodeOf(limit))
+			o.emit(b.exclusive, nodeOf(exclusive))
+		case isExclusive:
+			o.emit(b.exclusive, nodeOf(limit))
+		default:
+			o.emit(b.limit, nodeOf(limit))
+		}
+
+	case hasExclusive && exclusive.IsNumber():
+		if c.to.numericExclusive() {
+			if hasLimit {
+				o.emit(b.limit, nodeOf(limit))
+			}
+			o.emit(b.exclusive, nodeOf(exclusive))
+
+			break
+		}
+
+		if hasLimit && !b.stricter(exclusive, limit) {
+			// the inclusive limit is stricter: the exclusive one is redundant
+			o.emit(b.limit, nodeOf(limit))
+
+			break
+		}
+
+		o.emit(b.limit, nodeOf(exclusive))
+		o.emit(b.exclusive, c.boolNode(true))
+
+	default:
+		if hasLimit {
+			o.emit(b.limit, nodeOf(limit))
+		}
+
+		if hasExclusive {
+			o.emit(b.exclusive, nodeOf(exclusive))
+		}
+	}
+
+	return nil
+}
+
+// items converts arrays: tuples are specified by an array of "items" until draft 2019,
+// and by "prefixItems" afterwards.
+func (o *object) items() error {
+	c := o.c
+
+	var (
+		tuple, rest         json.Document
+		hasTuple, hasRest   bool
+		tupleKey, restKey   string
+		items, hasItems     = o.src.AtKey("items")
+		_, hasAdditional    = o.src.AtKey("additionalItems")
+		additionalIsIgnored bool
+	)
+
+	if c.from.draft >= jsonschema.VersionDraft2020 {
+		tuple, hasTuple = o.src.AtKey("prefixItems")
+		tupleKey = "prefixItems"
+		rest, hasRest, restKey = items, hasItems, "items"
+	} else {
+		switch {
+		case hasItems && items.IsArray():
+			tuple, hasTuple, tupleKey = items, true, "items"
+			rest, hasRest = o.src.AtKey("additionalItems")
+			restKey = "additionalItems"
+		case hasItems:
+			rest, hasRest, restKey = items, true, "items"
+			additionalIsIgnored = hasAdditional
+		default:
+			additionalIsIgnored = hasAdditional
+		}
+	}
+
+	if additionalIsIgnored {
+		c.lossy("additionalItems", "has no effect without an array of \"items\": removed")
+	}
+
+	hasTuple = hasTuple && tuple.IsArray()
+
+	if hasTuple && c.to.restricted() {
+		if err := c.unportable(tupleKey, "tuples are not supported by %v", c.to); err != nil {
+			return err
+		}
+
+		if hasRest {
+			return c.unportable(restKey, "tuples are not supported by %v", c.to)
+		}
+
+		return nil
+	}
+
+	var tupleNode, restNode light.Node
+	if hasTuple {
+		n, err := c.schemaArray(tuple, tupleKey)
+		if err != nil {
+			return err
+		}
+		tupleNode = n
+	}
+
+	if hasRest {
+		n, err := c.subschema(rest, restKey)
+		if err != nil {
+			return err
+		}
+		restNode = n
+	}
+
+	switch {
+	case c.to.since(jsonschema.VersionDraft2020):
+		if hasTuple {
+			o.emit("prefixItems", tupleNode)
+		}
+
+		if hasRest {
+			o.emit("items", restNode)
+		}
+	case hasTuple:
+		o.emit("items", tupleNode)
+
+		if hasRest {
+			o.emit("additionalItems", restNode)
+		}
+	case hasRest:
+		o.emit("items", restNode)
+	}
+
+	return nil
+}
+
+type dependency struct {
+	name                   string
+	required, schema       json.Document
+	hasRequired, hasSchema bool
+	schemaKey              string
+}
+
+// dependencies converts "dependencies" (until draft 7) into "dependentRequired" and "dependentSchemas"
+// (since draft 2019), or conversely.
+func (o *object) dependencies() error {
+	c := o.c
+
+	sources := []string{"dependencies"}
+	if c.from.draft >= jsonschema.VersionDraft2019 {
+		sources = []string{"dependentRequired", "dependentSchemas"}
+	}
+
+	var deps []dependency
+	index := make(map[string]int)
+
+	for _, keyword := range sources {
+		source, ok := o.src.AtKey(keyword)
+		if !ok || !source.IsObject() {
+			continue
+		}
+
+		if c.to.restricted() {
+			if err := c.unportable(keyword, "dependencies are not supported by %v", c.to); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		for name, value := range source.Pairs() {
+			i, exists := index[name]
+			if !exists {
+				i = len(deps)
+				index[name] = i
+				deps = append(deps, dependency{name: name})
+			}
+
+			if value.IsArray() {
+				deps[i].required, deps[i].hasRequired = value, true
+			} else {
+				deps[i].schema, deps[i].hasSchema, deps[i].schemaKey = value, true, keyword
+			}
+		}
+	}
+
+	if len(deps) == 0 {
+		return nil
+	}
+
+	if c.to.since(jsonschema.VersionDraft2019) {
+		required := light.NewBuilder(c.store).Object()
+		schemas := light.NewBuilder(c.store).Object()
+
+		for _, dep := range deps {
+			if dep.hasRequired {
+				required.AppendKey(dep.name, nodeOf(dep.required))
+			}
+
+			if dep.hasSchema {
+				n, err := c.subschema(dep.schema, dep.schemaKey, dep.name)
+				if err != nil {
+					return err
+				}
+				schemas.AppendKey(dep.name, n)
+			}
+		}
+
+		if n := required.Node(); n.Len() > 0 {
+			o.emit("dependentRequired", n)
+		}
+
+		if n := schemas.Node(); n.Len() > 0 {
+			o.emit("dependentSchemas", n)
+		}
+
+		return nil
+	}
+
+	b := light.NewBuilder(c.store).Object()
+	for _, dep := range deps {
+		var n light.Node
+
+		switch {
+		case dep.hasSchema:
+			schema, err := c.subschema(dep.schema, dep.schemaKey, dep.name)
+			if err != nil {
+				return err
+			}
+
+			n = schema
+			if dep.hasRequired {
+				// a property with both required properties and a schema as dependencies
+				n = light.NewBuilder(c.store).Object().
+					AppendKey("required", nodeOf(dep.required)).
+					AppendKey("allOf", c.arrayNode(schema)).
+					Node()
+			}
+		case dep.required.Len() == 0 && c.to.draft == jsonschema.VersionDraft4:
+			// draft 4 requires at least one required property
+			continue
+		default:
+			n = nodeOf(dep.required)
+		}
+
+		b.AppendKey(dep.name, n)
+	}
+
+	o.emit("dependencies", b.Node())
+
+	return b.Err()
+}
+
+// types converts "type", with the "nullable" extension of OpenAPI v3.0 (or "x-nullable" with OpenAPI v2)
+// to represent the "null" type.
+func (o *object) types() error {
+	c := o.c
+
+	var types []string
+	typeValue, hasType := o.src.AtKey("type")
+	if hasType {
+		if t, ok := stringOf(typeValue); ok {
+			types = append(types, t)
+		} else {
+			for elem := range typeValue.Elems() {
+				if t, ok := stringOf(elem); ok {
+					types = append(types, t)
+				}
+			}
+		}
+	}
+
+	if nullableKey := c.from.nullableKey(); nullableKey != "" {
+		if value, ok := o.src.AtKey(nullableKey); ok {
+			if nullable, _ := boolOf(value); nullable {
+				switch {
+				case !hasType:
+					c.lossy(nullableKey, "has no effect without \"type\": removed")
+				case !slices.Contains(types, "null"):
+					types = append(types, "null")
+				}
+			}
+		}
+	}
+
+	if !hasType {
+		return nil
+	}
+
+	if !c.to.restricted() {
+		if len(types) == 1 {
+			o.emit("type", c.stringNode(types[0]))
+
+			return nil
+		}
+
+		elems := make([]light.Node, 0, len(types))
+		for _, t := range types {
+			elems = append(elems, c.stringNode(t))
+		}
+		o.emit("type", c.arrayNode(elems...))
+
+		return nil
+	}
+
+	// OpenAPI v2 and v3.0 don't support the "null" type, nor arrays of types
+	nonNull := slices.DeleteFunc(slices.Clone(types), func(t string) bool { return t == "null" })
+	nullable := len(nonNull) < len(types)
+	nullableKey := c.to.nullableKey()
+
+	switch {
+	case len(nonNull) == 0:
+		return c.unportable("type", "the null type is not supported by %v", c.to)
+	case len(nonNull) == 1:
+		o.emit("type", c.stringNode(nonNull[0]))
+		if nullable {
+			o.emit(nullableKey, c.boolNode(true))
+		}
+	case c.to.supports(keywords["anyOf"]):
+		alternatives := make([]light.Node, 0, len(nonNull))
+		for _, t := range nonNull {
+			b := light.NewBuilder(c.store).Object().AppendKey("type", c.stringNode(t))
+			if nullable {
+				b.AppendKey(nullableKey, c.boolNode(true))
+			}
+			alternatives = append(alternatives, b.Node())
+		}
+		o.addConstraint("anyOf", c.arrayNode(alternatives...))
+	default:
+		return c.unportable("type", "multiple types are not supported by %v", c.to)
+	}
+
+	return nil
+}
+
+// examples converts the OpenAPI "example" and "examples" (since draft 6).
+func (o *object) examples() error {
+	c := o.c
+
+	var (
+		values  []light.Node
+		keyword = "examples"
+	)
+
+	if c.from.isOpenAPI() || c.to.isOpenAPI() {
+		if example, ok := o.src.AtKey("example"); ok {
+			values = append(values, nodeOf(example))
+			keyword = "example"
+		}
+	}
+
+	examples, hasExamples := o.src.AtKey("examples")
+	if hasExamples && !examples.IsArray() {
+		return o.unknown("examples", examples)
+	}
+
+	for elem := range examples.Elems() {
+		values = append(values, nodeOf(elem))
+	}
+
+	if c.to.restricted() {
+		if len(values) > 1 {
+			c.lossy(keyword, "only a single example is supported by %v: other examples are removed", c.to)
+		}
+
+		if len(values) > 0 {
+			o.emit("example", values[0])
+		}
+
+		return nil
+	}
+
+	o.emit("examples", c.arrayNode(values...))
+
+	return nil
+}
+
+// constant converts "const", which is equivalent to an "enum" with a single value before draft 6.
+func (o *object) constant(value json.Document) error {
+	if o.c.to.since(jsonschema.VersionDraft6) {
+		o.emit("const", nodeOf(value))
+
+		return nil
+	}
+
+	o.addConstraint("enum", o.c.arrayNode(nodeOf(value)))
+
+	return nil
+}
+
+// condition converts "if", "then" and "else", which are equivalent to:
+//
+//	{"anyOf": [ {"allOf": [ if, then ]}, {"allOf": [ {"not": if}, else ]} ]}
+//
+// before draft 7.
+func (o *object) condition() error {
+	c := o.c
+	ifValue, hasIf := o.src.AtKey("if")
+	thenValue, hasThen := o.src.AtKey("then")
+	elseValue, hasElse := o.src.AtKey("else")
+
+	if c.to.since(jsonschema.VersionDraft7) {
+		for _, keyword := range []string{"if", "then", "else"} {
+			value, ok := o.src.AtKey(keyword)
+			if !ok {
+				continue
+			}
+
+			n, err := c.subschema(value, keyword)
+			if err != nil {
+				return err
+			}
+			o.emit(keyword, n)
+		}
+
+		return nil
+	}
+
+	if !hasIf {
+		// "then" and "else" have no effect without "if"
+		return nil
+	}
+
+	if !c.to.supports(keywords["anyOf"]) || !c.to.supports(keywords["not"]) {
+		return c.unportable("if", "conditional schemas are not supported by %v", c.to)
+	}
+
+	ifNode, err := c.subschema(ifValue, "if")
+	if err != nil {
+		return err
+	}
+
+	yes := ifNode
+	if hasThen {
+		thenNode, err := c.subschema(thenValue, "then")
+		if err != nil {
+			return err
+		}
+		yes = c.objectNode("allOf", c.arrayNode(ifNode, thenNode))
+	}
+
+	no := c.objectNode("not", ifNode)
+	if hasElse {
+		elseNode, err := c.subschema(elseValue, "else")
+		if err != nil {
+			return err
+		}
+		no = c.objectNode("allOf", c.arrayNode(no, elseNode))
+	}
+
+	o.addConstraint("anyOf", c.arrayNode(yes, no))
+
+	return nil
+}
+
+// dynamic converts "$recursiveRef" and "$recursiveAnchor" (draft 2019) or "$dynamicRef" and "$dynamicAnchor"
+// (since draft 2020).
+//
+// The conversion is exact only for the common use of these keywords, i.e. extending a meta-schema.
+func (o *object) dynamic(key string, value json.Document) error {
+	c := o.c
+
+	switch {
+	case c.to.since(jsonschema.VersionDraft2020):
+		switch key {
+		case "$recursiveRef":
+			if ref, _ := stringOf(value); ref != "#" {
+				return c.unportable(key, "only \"#\" may be converted into a \"$dynamicRef\"")
+			}
+
+			c.lossy(key, "converted into \"$dynamicRef\": \"#%s\"", dynamicAnchor)
+			o.emit("$dynamicRef", c.stringNode("#"+dynamicAnchor))
+		case "$recursiveAnchor":
+			if enabled, _ := boolOf(value); enabled {
+				c.lossy(key, "converted into \"$dynamicAnchor\": %q", dynamicAnchor)
+				o.emit("$dynamicAnchor", c.stringNode(dynamicAnchor))
+			}
+		default:
+			o.emit(key, nodeOf(value))
+		}
+
+	case c.to.since(jsonschema.VersionDraft2019):
+		switch key {
+		case "$dynamicRef":
+			c.lossy(key, "converted into \"$recursiveRef\": \"#\"")
+			o.emit("$recursiveRef", c.stringNode("#"))
+		case "$dynamicAnchor":
+			c.lossy(key, "converted into \"$recursiveAnchor\": true")
+			o.emit("$recursiveAnchor", c.boolNode(true))
+		default:
+			o.emit(key, nodeOf(value))
+		}
+
+	default:
+		return c.unsupported(key)
+	}
+
+	return nil
+}
+
+// discriminator converts the OpenAPI "discriminator": a property name with OpenAPI v2, an object since v3.
+func (o *object) discriminator(value json.Document) error {
+	const key = "discriminator"
+	c := o.c
+
+	switch {
+	case !c.from.isOpenAPI():
+		return o.unknown(key, value)
+
+	case !c.to.isOpenAPI():
+		c.lossy(key, "not a JSON schema keyword: ignored by %v", c.to)
+
+	case c.to.openAPI == jsonschema.VersionOpenAPIv2:
+		if !value.IsObject() {
+			break
+		}
+
+		if _, hasMapping := value.AtKey("mapping"); hasMapping {
+			c.lossy(key, "mappings are not supported by %v: removed", c.to)
+		}
+
+		propertyName, _ := value.AtKey("propertyName")
+		o.emit(key, nodeOf(propertyName))
+
+		return nil
+
+	default:
+		if name, ok := stringOf(value); ok {
+			o.emit(key, c.objectNode("propertyName", c.stringNode(name)))
+
+			return nil
+		}
+	}
+
+	o.emit(key, nodeOf(value))
+
+	return nil
+}
+
+// openAPIAnnotation converts OpenAPI keywords such as "xml" or "externalDocs".
+func (o *object) openAPIAnnotation(key string, value json.Document) error {
+	c := o.c
+
+	if !c.from.isOpenAPI() {
+		return o.unknown(key, value)
+	}
+
+	if !c.to.isOpenAPI() {
+		c.lossy(key, "not a JSON schema keyword: ignored by %v", c.to)
+	}
+
+	o.emit(key, nodeOf(value))
+
+	return nil
+}
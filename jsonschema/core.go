@@ -3,14 +3,15 @@ package jsonschema
 import (
 	"iter"
 
+	"github.com/fredbi/core/json"
 	"github.com/fredbi/core/json/nodes/light"
 	"github.com/fredbi/core/json/stores"
 	"github.com/fredbi/core/json/stores/values"
 )
 
+// schemaFromNode builds the [Schema] rooted at a node of a store.
 func schemaFromNode(s stores.Store, n *light.Node) Schema {
-	_ = s                      // TODO
-	b := NewBuilder( /* s */ ) // TODO: borrow
+	b := NewBuilder(WithDocumentOptions(json.WithStore(s))) // TODO: borrow from pool
 
 	return b.WithRoot(*n).Schema()
 }
@@ -59,8 +60,8 @@ var (
 	dynamicAnchorKey    = values.MakeInternedKey("$dynamicAnchor")
 	dynamicRefKey       = values.MakeInternedKey("$dynamicRef")
 	idKey               = values.MakeInternedKey("$id")
-	recursiveAnchorKey  = values.MakeInternedKey("recursiveAnchor")
-	recursiveRefKey     = values.MakeInternedKey("recursiveRef")
+	recursiveAnchorKey  = values.MakeInternedKey("$recursiveAnchor")
+	recursiveRefKey     = values.MakeInternedKey("$recursiveRef")
 	refKey              = values.MakeInternedKey("$ref")
 	schemaKey           = values.MakeInternedKey("$schema")
 
@@ -181,16 +182,9 @@ func (c Core) VersionRequirements() VersionRequirements {
 	return c.version
 }
 
-func (c *Core) decode(ctx *light.ParentContext, key values.InternedKey) error {
-	octx, ok := ctx.X.(*schemaContext)
-	if !ok {
-		panic("bug")
-	}
-
+func (c *Core) decode(_ *light.ParentContext, key values.InternedKey, _ light.Node) error {
 	switch key {
 	case anchorKey:
-		// consume node
-		//octx.anchor =  ...
 	case contentEncodingKey:
 	case contentMediaTypeKey:
 	case definitionsKey:
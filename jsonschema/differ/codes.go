@@ -1,5 +1,10 @@
 package differ
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Severity qualifies the impact of a change, from cosmetic to breaking change.
 type Severity uint8
 
@@ -27,6 +32,36 @@ func (s Severity) Less(other Severity) bool {
 	return int(s) < int(other)
 }
 
+func (s Severity) String() string {
+	switch s {
+	case SeverityCosmetic:
+		return "cosmetic"
+	case SeverityDocOnly:
+		return "doc-only"
+	case SeverityPatch:
+		return "patch"
+	case SeverityMinor:
+		return "minor"
+	case SeverityBreaking:
+		return "breaking"
+	case SeverityNone:
+		fallthrough
+	default:
+		return "none"
+	}
+}
+
+// ParseSeverity parses the string representation of a [Severity], e.g. "breaking".
+func ParseSeverity(s string) (Severity, error) {
+	for severity := SeverityNone; severity <= SeverityBreaking; severity++ {
+		if severity.String() == s {
+			return severity, nil
+		}
+	}
+
+	return SeverityNone, fmt.Errorf("unknown severity %q: %w", s, ErrDiffer)
+}
+
 const (
 	CategoryNone    CategoryMode = 1 << iota
 	CategoryVersion              // JSON schema version
@@ -36,6 +71,34 @@ const (
 	CategoryDataType
 )
 
+func (c CategoryMode) Has(category CategoryMode) bool {
+	return c&category != 0
+}
+
+func (c CategoryMode) String() string {
+	names := make([]string, 0, 1)
+	for _, category := range []struct {
+		mode CategoryMode
+		name string
+	}{
+		{CategoryVersion, "version"},
+		{CategoryMetadata, "metadata"},
+		{CategoryLocation, "location"},
+		{CategoryValidation, "validation"},
+		{CategoryDataType, "type"},
+	} {
+		if c.Has(category.mode) {
+			names = append(names, category.name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "none"
+	}
+
+	return strings.Join(names, ",")
+}
+
 const (
 	NumberValidation ValidationCategory = iota
 	StringValidation
@@ -44,9 +107,41 @@ const (
 	EnumValidation
 )
 
+func (v ValidationCategory) String() string {
+	switch v {
+	case StringValidation:
+		return "string"
+	case ObjectValidation:
+		return "object"
+	case ArrayValidation:
+		return "array"
+	case EnumValidation:
+		return "enum"
+	case NumberValidation:
+		fallthrough
+	default:
+		return "number"
+	}
+}
+
 const (
 	TypeNoChange Type = iota
 	Deleted
 	Added
 	Updated
 )
+
+func (t Type) String() string {
+	switch t {
+	case Deleted:
+		return "deleted"
+	case Added:
+		return "added"
+	case Updated:
+		return "updated"
+	case TypeNoChange:
+		fallthrough
+	default:
+		return "unchanged"
+	}
+}
@@ -0,0 +1,947 @@
+package differ
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// keywordClass tells how the changes to a keyword are qualified.
+type keywordClass uint8
+
+const (
+	classUnknown keywordClass = iota
+	classSkip
+	classCosmetic
+	classDocOnly
+	classMetadata
+	classVersion
+	classLocation
+	classUpperBound
+	classLowerBound
+	classMultipleOf
+	classTightenOnAdd
+	classFlag
+	classLoosenFlag
+	classEnum
+	classType
+	classRequired
+	classDependentRequired
+	classDependencies
+	classProperties
+	classSchemaMap
+	classSchema
+	classContains
+	classCondition
+	classNot
+	classAllOf
+	classAnyOf
+	classOneOf
+	classItems
+	classTuple
+)
+
+type keywordSpec struct {
+	class      keywordClass
+	validation ValidationCategory
+}
+
+// knownKeywords are the specifications of known keywords.
+//
+// Keywords holding subschemas which don't need any specific comparison are classified after
+// [jsonschema.SubschemaKeyword] (see specOf).
+//
+//nolint:gochecknoglobals // keywords specifications
+var knownKeywords = map[string]keywordSpec{
+	"$ref":                  {class: classSkip},
+	"$defs":                 {class: classSkip},
+	"definitions":           {class: classSkip},
+	"$comment":              {class: classCosmetic},
+	"title":                 {class: classDocOnly},
+	"description":           {class: classDocOnly},
+	"examples":              {class: classDocOnly},
+	"example":               {class: classDocOnly},
+	"default":               {class: classMetadata},
+	"deprecated":            {class: classMetadata},
+	"readOnly":              {class: classMetadata},
+	"writeOnly":             {class: classMetadata},
+	"contentMediaType":      {class: classMetadata},
+	"contentEncoding":       {class: classMetadata},
+	"contentSchema":         {class: classMetadata},
+	"discriminator":         {class: classMetadata},
+	"xml":                   {class: classMetadata},
+	"externalDocs":          {class: classMetadata},
+	"$schema":               {class: classVersion},
+	"$vocabulary":           {class: classVersion},
+	"id":                    {class: classLocation},
+	"$id":                   {class: classLocation},
+	"$anchor":               {class: classLocation},
+	"$dynamicAnchor":        {class: classLocation},
+	"$recursiveAnchor":      {class: classLocation},
+	"$dynamicRef":           {class: classLocation},
+	"$recursiveRef":         {class: classLocation},
+	"maximum":               {class: classUpperBound, validation: NumberValidation},
+	"exclusiveMaximum":      {class: classUpperBound, validation: NumberValidation},
+	"maxLength":             {class: classUpperBound, validation: StringValidation},
+	"maxItems":              {class: classUpperBound, validation: ArrayValidation},
+	"maxContains":           {class: classUpperBound, validation: ArrayValidation},
+	"maxProperties":         {class: classUpperBound, validation: ObjectValidation},
+	"minimum":               {class: classLowerBound, validation: NumberValidation},
+	"exclusiveMinimum":      {class: classLowerBound, validation: NumberValidation},
+	"minLength":             {class: classLowerBound, validation: StringValidation},
+	"minItems":              {class: classLowerBound, validation: ArrayValidation},
+	"minContains":           {class: classLowerBound, validation: ArrayValidation},
+	"minProperties":         {class: classLowerBound, validation: ObjectValidation},
+	"multipleOf":            {class: classMultipleOf, validation: NumberValidation},
+	"pattern":               {class: classTightenOnAdd, validation: StringValidation},
+	"format":                {class: classTightenOnAdd, validation: StringValidation},
+	"const":                 {class: classTightenOnAdd, validation: EnumValidation},
+	"uniqueItems":           {class: classFlag, validation: ArrayValidation},
+	"nullable":              {class: classLoosenFlag},
+	"x-nullable":            {class: classLoosenFlag},
+	"enum":                  {class: classEnum, validation: EnumValidation},
+	"type":                  {class: classType},
+	"required":              {class: classRequired, validation: ObjectValidation},
+	"dependentRequired":     {class: classDependentRequired, validation: ObjectValidation},
+	"dependencies":          {class: classDependencies, validation: ObjectValidation},
+	"properties":            {class: classProperties, validation: ObjectValidation},
+	"patternProperties":     {validation: ObjectValidation},
+	"dependentSchemas":      {validation: ObjectValidation},
+	"additionalProperties":  {validation: ObjectValidation},
+	"propertyNames":         {validation: ObjectValidation},
+	"unevaluatedProperties": {validation: ObjectValidation},
+	"additionalItems":       {validation: ArrayValidation},
+	"unevaluatedItems":      {validation: ArrayValidation},
+	"contains":              {class: classContains, validation: ArrayValidation},
+	"if":                    {class: classCondition},
+	"not":                   {class: classNot},
+	"allOf":                 {class: classAllOf},
+	"anyOf":                 {class: classAnyOf},
+	"oneOf":                 {class: classOneOf},
+	"items":                 {class: classItems, validation: ArrayValidation},
+	"prefixItems":           {class: classTuple, validation: ArrayValidation},
+}
+
+// location of a schema in the old and the new documents, as JSON pointers.
+type location struct {
+	old string
+	new string
+}
+
+func (l location) at(segments ...string) location {
+	for _, segment := range segments {
+		escaped := json.EscapeToken(segment)
+		l.old += "/" + escaped
+		l.new += "/" + escaped
+	}
+
+	return l
+}
+
+// values of a keyword in the old and the new schemas.
+type values struct {
+	old    json.Document
+	new    json.Document
+	hasOld bool
+	hasNew bool
+}
+
+func (v values) difftype() Type {
+	switch {
+	case !v.hasOld:
+		return Added
+	case !v.hasNew:
+		return Deleted
+	default:
+		return Updated
+	}
+}
+
+func (v values) equal() bool {
+	if v.hasOld != v.hasNew {
+		return false
+	}
+
+	return !v.hasOld || keywords.Equal(v.old, v.new)
+}
+
+// comparison holds the state of a single diff.
+type comparison struct {
+	*options
+
+	oldRoot json.Document
+	newRoot json.Document
+	changes []Change
+	visited map[location]struct{}
+
+	// negated is set when comparing schemas under "not": tightening and loosening are swapped
+	negated bool
+
+	// dollarData is set when keywords may be "$data" references, which values are only known at validation time
+	dollarData bool
+}
+
+func (c *comparison) report(key string, v values, at location, severity Severity, categories CategoryMode, validation ValidationCategory, format string, args ...any) {
+	if severity == SeverityCosmetic && c.ignoreCosmetic {
+		return
+	}
+
+	if key != "" {
+		at = at.at(key)
+	}
+
+	c.changes = append(c.changes, Change{
+		severity:           severity,
+		categories:         categories,
+		validationCategory: validation,
+		difftype:           v.difftype(),
+		keyword:            key,
+		oldPointer:         at.old,
+		newPointer:         at.new,
+		oldValue:           v.old,
+		newValue:           v.new,
+		hasOld:             v.hasOld,
+		hasNew:             v.hasNew,
+		message:            fmt.Sprintf(format, args...),
+	})
+}
+
+// effect of a change on the validation.
+type effect uint8
+
+const (
+	effectLoosened  effect = iota // more data is valid
+	effectTightened               // less data is valid
+	effectAltered                 // some data may become valid, and some other data invalid
+)
+
+func effectOf(tightened bool) effect {
+	if tightened {
+		return effectTightened
+	}
+
+	return effectLoosened
+}
+
+// severityOf a change to the validation, depending on the [Direction] of the validated data.
+//
+// Under "not", tightening and loosening are swapped.
+func (c *comparison) severityOf(e effect) Severity {
+	if e == effectAltered {
+		return SeverityBreaking
+	}
+
+	tightened := (e == effectTightened) != c.negated
+
+	var breaking bool
+	switch c.direction {
+	case DirectionResponse:
+		breaking = !tightened
+	case DirectionBoth:
+		breaking = true
+	default:
+		breaking = tightened
+	}
+
+	if breaking {
+		return SeverityBreaking
+	}
+
+	return SeverityMinor
+}
+
+// validation reports a change to a validation keyword, which either tightens or loosens the validation.
+func (c *comparison) validation(key string, v values, at location, spec keywordSpec, tightened bool, format string, args ...any) {
+	c.validationEffect(key, v, at, spec, effectOf(tightened), format, args...)
+}
+
+// altered reports a change to a validation keyword, which may both tighten and loosen the validation.
+func (c *comparison) altered(key string, v values, at location, spec keywordSpec, format string, args ...any) {
+	c.validationEffect(key, v, at, spec, effectAltered, format, args...)
+}
+
+func (c *comparison) validationEffect(key string, v values, at location, spec keywordSpec, e effect, format string, args ...any) {
+	categories := CategoryValidation
+	if spec.class == classType {
+		categories = CategoryDataType
+	}
+
+	c.report(key, v, at, c.severityOf(e), categories, spec.validation, format, args...)
+}
+
+// schema compares two schemas.
+func (c *comparison) schema(old, new json.Document, at location) {
+	oldRef, oldTarget, oldAt, oldFollowed := c.follow(c.oldRoot, old, at.old)
+	newRef, newTarget, newAt, newFollowed := c.follow(c.newRoot, new, at.new)
+
+	if oldRef != "" && newRef != "" && oldRef != newRef {
+		v := values{old: old, new: new, hasOld: true, hasNew: true}
+		v.old, _ = old.AtKey("$ref")
+		v.new, _ = new.AtKey("$ref")
+		c.report("$ref", v, at, SeverityPatch, CategoryLocation, 0, "reference changed from %q to %q", oldRef, newRef)
+	}
+
+	if oldFollowed || newFollowed {
+		target := at
+		o, n := old, new
+		if oldFollowed {
+			o, target.old = oldTarget, oldAt
+		}
+		if newFollowed {
+			n, target.new = newTarget, newAt
+		}
+
+		if _, visited := c.visited[target]; !visited {
+			c.visited[target] = struct{}{}
+			c.schema(o, n, target)
+		}
+
+		if !oldFollowed || !newFollowed {
+			// an inline schema is replaced by a reference, or conversely
+			return
+		}
+	}
+
+	oldBool, oldIsBool := boolOf(old)
+	newBool, newIsBool := boolOf(new)
+	v := values{old: old, new: new, hasOld: true, hasNew: true}
+
+	switch {
+	case oldIsBool && newIsBool:
+		if oldBool != newBool {
+			c.validation("", v, at, keywordSpec{}, !newBool, "schema changed from %t to %t", oldBool, newBool)
+		}
+
+		return
+	case oldIsBool && !oldBool:
+		c.validation("", v, at, keywordSpec{}, false, "schema changed from false to a schema")
+
+		return
+	case newIsBool && !newBool:
+		c.validation("", v, at, keywordSpec{}, true, "schema changed to false")
+
+		return
+	}
+
+	// the true schema is equivalent to the empty schema
+	c.keywords(old, new, at)
+}
+
+// follow a local "$ref" in a schema.
+func (c *comparison) follow(root, d json.Document, at string) (ref string, target json.Document, targetAt string, ok bool) {
+	value, hasRef := d.AtKey("$ref")
+	if !hasRef {
+		return "", d, at, false
+	}
+
+	ref, isString := stringOf(value)
+	if !isString {
+		return "", d, at, false
+	}
+
+	fragment, isLocal := strings.CutPrefix(ref, "#")
+	if !isLocal {
+		// only local references are followed
+		return ref, d, at, false
+	}
+
+	fragment, err := url.PathUnescape(fragment)
+	if err != nil {
+		return ref, d, at, false
+	}
+
+	pointer, err := json.MakePointer(fragment)
+	if err != nil {
+		return ref, d, at, false
+	}
+
+	target, err = root.GetPointer(pointer)
+	if err != nil {
+		return ref, d, at, false
+	}
+
+	return ref, target, fragment, true
+}
+
+func (c *comparison) keywords(old, new json.Document, at location) {
+	for key, oldValue := range old.Pairs() {
+		newValue, hasNew := new.AtKey(key)
+		c.keyword(key, values{old: oldValue, new: newValue, hasOld: true, hasNew: hasNew}, new, at)
+	}
+
+	for key, newValue := range new.Pairs() {
+		if _, hasOld := old.AtKey(key); hasOld {
+			continue
+		}
+
+		c.keyword(key, values{new: newValue, hasNew: true}, new, at)
+	}
+}
+
+//nolint:gocyclo,cyclop // a dispatch over all keywords
+func (c *comparison) keyword(key string, v values, newParent json.Document, at location) {
+	spec := specOf(key)
+
+	if c.dollarData && (v.hasOld && jsonschema.IsDataRef(key, v.old) || v.hasNew && jsonschema.IsDataRef(key, v.new)) {
+		if !v.equal() {
+			c.altered(key, v, at, spec, "dynamic %q %s", key, describe(v))
+		}
+
+		return
+	}
+
+	switch spec.class {
+	case classSkip:
+	case classCosmetic:
+		c.value(key, v, at, SeverityCosmetic, CategoryMetadata)
+	case classDocOnly:
+		c.value(key, v, at, SeverityDocOnly, CategoryMetadata)
+	case classMetadata, classUnknown:
+		c.value(key, v, at, SeverityPatch, CategoryMetadata)
+	case classVersion:
+		c.value(key, v, at, SeverityMinor, CategoryVersion)
+	case classLocation:
+		c.value(key, v, at, SeverityPatch, CategoryLocation)
+	case classUpperBound, classLowerBound:
+		c.bound(key, v, at, spec)
+	case classMultipleOf:
+		c.multipleOf(key, v, at, spec)
+	case classTightenOnAdd:
+		c.tightenOnAdd(key, v, at, spec)
+	case classFlag:
+		c.flag(key, v, at, spec, true)
+	case classLoosenFlag:
+		c.flag(key, v, at, spec, false)
+	case classEnum:
+		c.enum(key, v, at, spec)
+	case classType:
+		c.types(key, v, at, spec)
+	case classRequired:
+		c.required(key, v, at, spec)
+	case classDependentRequired:
+		c.dependentRequired(key, v, at, spec)
+	case classDependencies:
+		c.dependencies(key, v, at, spec)
+	case classProperties:
+		c.properties(key, v, newParent, at, spec)
+	case classSchemaMap:
+		c.schemaMap(key, v, at, spec)
+	case classSchema:
+		c.schema(c.orTrue(v.old, v.hasOld, c.oldRoot), c.orTrue(v.new, v.hasNew, c.newRoot), at.at(key))
+	case classContains:
+		c.contains(key, v, at, spec)
+	case classCondition:
+		if !v.equal() {
+			c.altered(key, v, at, spec, "condition %s", describe(v))
+		}
+	case classNot:
+		c.not(key, v, at, spec)
+	case classAllOf, classAnyOf, classOneOf:
+		c.composition(key, v, at, spec)
+	case classItems:
+		c.items(key, v, at, spec)
+	case classTuple:
+		c.tuple(key, v, at, spec)
+	}
+}
+
+// specOf returns the specification of a keyword.
+func specOf(key string) keywordSpec {
+	spec := knownKeywords[key]
+	if spec.class != classUnknown {
+		return spec
+	}
+
+	switch jsonschema.SubschemaKeyword(key) {
+	case jsonschema.SubschemaSingle:
+		spec.class = classSchema
+	case jsonschema.SubschemaMap:
+		spec.class = classSchemaMap
+	case jsonschema.SubschemaArray, jsonschema.NoSubschema:
+	}
+
+	return spec
+}
+
+// value compares keywords which don't affect validation.
+func (c *comparison) value(key string, v values, at location, severity Severity, categories CategoryMode) {
+	if v.equal() {
+		return
+	}
+
+	c.report(key, v, at, severity, categories, 0, "%q %s", key, describe(v))
+}
+
+func (c *comparison) bound(key string, v values, at location, spec keywordSpec) {
+	if v.equal() {
+		return
+	}
+
+	oldIsBool := v.hasOld && v.old.IsBool()
+	newIsBool := v.hasNew && v.new.IsBool()
+
+	switch {
+	case (oldIsBool || !v.hasOld) && (newIsBool || !v.hasNew):
+		// draft 4 boolean "exclusiveMinimum" or "exclusiveMaximum"
+		c.flag(key, v, at, spec, true)
+	case oldIsBool || newIsBool:
+		c.report(key, v, at, SeverityPatch, CategoryVersion, spec.validation,
+			"%q %s: exclusive bounds are booleans until draft 4, numbers afterwards", key, describe(v),
+		)
+	case !v.hasOld:
+		c.validation(key, v, at, spec, true, "%q added: %s", key, keywords.Text(v.new))
+	case !v.hasNew:
+		c.validation(key, v, at, spec, false, "%q removed: %s", key, keywords.Text(v.old))
+	default:
+		cmp := numberOf(v.new).Cmp(numberOf(v.old))
+		if cmp == 0 {
+			return
+		}
+
+		verb := "increased"
+		if cmp < 0 {
+			verb = "decreased"
+		}
+		tightened := (spec.class == classUpperBound) == (cmp < 0)
+
+		c.validation(key, v, at, spec, tightened, "%q %s from %s to %s", key, verb, keywords.Text(v.old), keywords.Text(v.new))
+	}
+}
+
+func (c *comparison) multipleOf(key string, v values, at location, spec keywordSpec) {
+	switch {
+	case v.equal():
+	case !v.hasOld:
+		c.validation(key, v, at, spec, true, "%q added: %s", key, keywords.Text(v.new))
+	case !v.hasNew:
+		c.validation(key, v, at, spec, false, "%q removed: %s", key, keywords.Text(v.old))
+	default:
+		oldFactor, newFactor := numberOf(v.old), numberOf(v.new)
+		if oldFactor.Cmp(newFactor) == 0 {
+			return
+		}
+
+		// multiples of the old factor remain valid when the old factor is a multiple of the new one
+		loosened := isMultiple(newFactor, oldFactor)
+		c.validation(key, v, at, spec, !loosened, "%q changed from %s to %s", key, keywords.Text(v.old), keywords.Text(v.new))
+	}
+}
+
+func (c *comparison) tightenOnAdd(key string, v values, at location, spec keywordSpec) {
+	if v.equal() {
+		return
+	}
+
+	c.validation(key, v, at, spec, v.hasNew, "%q %s", key, describe(v))
+}
+
+// flag compares boolean keywords: a missing keyword is false.
+func (c *comparison) flag(key string, v values, at location, spec keywordSpec, tightening bool) {
+	oldEnabled := v.hasOld && isTrue(v.old)
+	newEnabled := v.hasNew && isTrue(v.new)
+	if oldEnabled == newEnabled {
+		return
+	}
+
+	categories := CategoryValidation
+	if !tightening {
+		categories = CategoryDataType
+	}
+
+	severity := c.severityOf(effectOf(newEnabled == tightening))
+
+	c.report(key, v, at, severity, categories, spec.validation, "%q changed from %t to %t", key, oldEnabled, newEnabled)
+}
+
+func (c *comparison) enum(key string, v values, at location, spec keywordSpec) {
+	switch {
+	case v.equal():
+	case !v.hasOld:
+		c.validation(key, v, at, spec, true, "%q added: %s", key, keywords.Text(v.new))
+	case !v.hasNew:
+		c.validation(key, v, at, spec, false, "%q removed: %s", key, keywords.Text(v.old))
+	default:
+		if removed := differentValues(v.old, v.new); len(removed) > 0 {
+			c.validation(key, v, at, spec, true, "values removed from %q: %s", key, strings.Join(removed, ", "))
+		}
+
+		if added := differentValues(v.new, v.old); len(added) > 0 {
+			c.validation(key, v, at, spec, false, "values added to %q: %s", key, strings.Join(added, ", "))
+		}
+	}
+}
+
+func (c *comparison) types(key string, v values, at location, spec keywordSpec) {
+	switch {
+	case v.equal():
+	case !v.hasOld:
+		c.validation(key, v, at, spec, true, "%q added: %s", key, keywords.Text(v.new))
+	case !v.hasNew:
+		c.validation(key, v, at, spec, false, "%q removed: %s", key, keywords.Text(v.old))
+	default:
+		oldTypes, newTypes := typesOf(v.old), typesOf(v.new)
+		if removed := uncoveredTypes(oldTypes, newTypes); len(removed) > 0 {
+			c.validation(key, v, at, spec, true, "types removed: %s", strings.Join(removed, ", "))
+		}
+
+		if added := uncoveredTypes(newTypes, oldTypes); len(added) > 0 {
+			c.validation(key, v, at, spec, false, "types added: %s", strings.Join(added, ", "))
+		}
+	}
+}
+
+// required compares arrays of required property names: a missing keyword is an empty array.
+func (c *comparison) required(key string, v values, at location, spec keywordSpec) {
+	if v.equal() {
+		return
+	}
+
+	oldNames, newNames := strs(v.old), strs(v.new)
+	if added := difference(newNames, oldNames); len(added) > 0 {
+		c.validation(key, v, at, spec, true, "properties now required: %s", strings.Join(added, ", "))
+	}
+
+	if removed := difference(oldNames, newNames); len(removed) > 0 {
+		c.validation(key, v, at, spec, false, "properties no longer required: %s", strings.Join(removed, ", "))
+	}
+}
+
+func (c *comparison) dependentRequired(key string, v values, at location, spec keywordSpec) {
+	if v.equal() {
+		return
+	}
+
+	for name, pair := range pairs(v) {
+		c.required(name, pair, at.at(key), spec)
+	}
+}
+
+// dependencies compares the draft 4 to draft 7 "dependencies", with either required properties or schemas.
+func (c *comparison) dependencies(key string, v values, at location, spec keywordSpec) {
+	for name, pair := range pairs(v) {
+		oldIsArray := pair.hasOld && pair.old.IsArray()
+		newIsArray := pair.hasNew && pair.new.IsArray()
+
+		switch {
+		case (oldIsArray || !pair.hasOld) && (newIsArray || !pair.hasNew):
+			c.required(name, pair, at.at(key), spec)
+		case !pair.hasOld:
+			c.validation(name, pair, at.at(key), spec, true, "dependency added on %q", name)
+		case !pair.hasNew:
+			c.validation(name, pair, at.at(key), spec, false, "dependency removed on %q", name)
+		case oldIsArray || newIsArray:
+			c.validation(name, pair, at.at(key), spec, true, "dependency on %q %s", name, describe(pair))
+		default:
+			c.schema(pair.old, pair.new, at.at(key, name))
+		}
+	}
+}
+
+func (c *comparison) properties(key string, v values, newParent json.Document, at location, spec keywordSpec) {
+	additional, hasAdditional := newParent.AtKey("additionalProperties")
+	closed := hasAdditional && isFalse(additional)
+	requiredNames := strs(valueAt(newParent, "required"))
+
+	for name, pair := range pairs(v) {
+		switch {
+		case pair.hasOld && pair.hasNew:
+			c.schema(pair.old, pair.new, at.at(key, name))
+		case !pair.hasOld && slices.Contains(requiredNames, name):
+			c.validation(name, pair, at.at(key), spec, false, "property %q added", name)
+		case !pair.hasOld:
+			c.validation(name, pair, at.at(key), spec, false, "optional property %q added", name)
+		case closed:
+			c.validation(name, pair, at.at(key), spec, true, "property %q removed, and additional properties are not allowed", name)
+		default:
+			c.validation(name, pair, at.at(key), spec, false, "property %q removed", name)
+		}
+	}
+}
+
+// schemaMap compares "patternProperties" or "dependentSchemas": added schemas tighten the validation.
+func (c *comparison) schemaMap(key string, v values, at location, spec keywordSpec) {
+	for name, pair := range pairs(v) {
+		switch {
+		case pair.hasOld && pair.hasNew:
+			c.schema(pair.old, pair.new, at.at(key, name))
+		default:
+			c.validation(name, pair, at.at(key), spec, pair.hasNew, "schema for %q %s", name, describe(pair))
+		}
+	}
+}
+
+func (c *comparison) contains(key string, v values, at location, spec keywordSpec) {
+	if v.hasOld && v.hasNew {
+		c.schema(v.old, v.new, at.at(key))
+
+		return
+	}
+
+	c.validation(key, v, at, spec, v.hasNew, "%q %s", key, describe(v))
+}
+
+func (c *comparison) not(key string, v values, at location, spec keywordSpec) {
+	if !v.hasOld || !v.hasNew {
+		c.validation(key, v, at, spec, v.hasNew, "%q %s", key, describe(v))
+
+		return
+	}
+
+	c.negated = !c.negated
+	c.schema(v.old, v.new, at.at(key))
+	c.negated = !c.negated
+}
+
+// composition compares "allOf", "anyOf" or "oneOf", element by element.
+func (c *comparison) composition(key string, v values, at location, spec keywordSpec) {
+	if !v.hasOld || !v.hasNew {
+		c.validation(key, v, at, spec, v.hasNew, "%q %s", key, describe(v))
+
+		return
+	}
+
+	oldLen, newLen := v.old.Len(), v.new.Len()
+	for i := range max(oldLen, newLen) {
+		index := strconv.Itoa(i)
+		oldElem, hasOld := v.old.Elem(i)
+		newElem, hasNew := v.new.Elem(i)
+
+		if hasOld && hasNew {
+			c.schema(oldElem, newElem, at.at(key, index))
+
+			continue
+		}
+
+		var e effect
+		switch spec.class {
+		case classAllOf:
+			e = effectOf(hasNew)
+		case classAnyOf:
+			e = effectOf(hasOld)
+		default:
+			// any change in the alternatives of "oneOf" may validate or invalidate data
+			e = effectAltered
+		}
+
+		c.validationEffect(index, values{old: oldElem, new: newElem, hasOld: hasOld, hasNew: hasNew}, at.at(key), spec,
+			e, "schema %s %q", describeAddition(hasNew), key,
+		)
+	}
+}
+
+func (c *comparison) items(key string, v values, at location, spec keywordSpec) {
+	oldIsTuple := v.hasOld && v.old.IsArray()
+	newIsTuple := v.hasNew && v.new.IsArray()
+
+	switch {
+	case !oldIsTuple && !newIsTuple:
+		c.schema(c.orTrue(v.old, v.hasOld, c.oldRoot), c.orTrue(v.new, v.hasNew, c.newRoot), at.at(key))
+	case oldIsTuple && newIsTuple, !v.hasOld, !v.hasNew:
+		c.tuple(key, v, at, spec)
+	default:
+		c.altered(key, v, at, spec, "%q changed from %s to %s", key, shapeOf(v.old), shapeOf(v.new))
+	}
+}
+
+// tuple compares arrays of schemas applied to array items by position.
+func (c *comparison) tuple(key string, v values, at location, spec keywordSpec) {
+	oldLen, newLen := v.old.Len(), v.new.Len()
+
+	for i := range max(oldLen, newLen) {
+		index := strconv.Itoa(i)
+		oldElem, hasOld := v.old.Elem(i)
+		newElem, hasNew := v.new.Elem(i)
+		hasOld = hasOld && v.hasOld
+		hasNew = hasNew && v.hasNew
+
+		if hasOld && hasNew {
+			c.schema(oldElem, newElem, at.at(key, index))
+
+			continue
+		}
+
+		c.validation(index, values{old: oldElem, new: newElem, hasOld: hasOld, hasNew: hasNew}, at.at(key), spec,
+			hasNew, "schema for item %d %s", i, describeAddition(hasNew),
+		)
+	}
+}
+
+// orTrue yields the true schema for a missing schema.
+func (c *comparison) orTrue(d json.Document, ok bool, root json.Document) json.Document {
+	if ok {
+		return d
+	}
+
+	return json.NewBuilder(root.Store()).MakeBool(true)
+}
+
+// pairs yields the members of two objects by key, first in the old order then the added ones.
+func pairs(v values) func(func(string, values) bool) {
+	return func(yield func(string, values) bool) {
+		for name, oldValue := range v.old.Pairs() {
+			newValue, hasNew := v.new.AtKey(name)
+			if !yield(name, values{old: oldValue, new: newValue, hasOld: true, hasNew: hasNew && v.hasNew}) {
+				return
+			}
+		}
+
+		for name, newValue := range v.new.Pairs() {
+			if _, hasOld := v.old.AtKey(name); hasOld && v.hasOld {
+				continue
+			}
+
+			if !yield(name, values{new: newValue, hasNew: true}) {
+				return
+			}
+		}
+	}
+}
+
+func describe(v values) string {
+	switch {
+	case !v.hasOld:
+		return "added: " + keywords.Text(v.new)
+	case !v.hasNew:
+		return "removed: " + keywords.Text(v.old)
+	default:
+		return "changed from " + keywords.Text(v.old) + " to " + keywords.Text(v.new)
+	}
+}
+
+func describeAddition(added bool) string {
+	if added {
+		return "added to"
+	}
+
+	return "removed from"
+}
+
+func shapeOf(d json.Document) string {
+	if d.IsArray() {
+		return "an array of schemas"
+	}
+
+	return "a schema"
+}
+
+func strs(d json.Document) []string {
+	result := make([]string, 0, d.Len())
+	for elem := range d.Elems() {
+		if s, ok := stringOf(elem); ok {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+// differentValues yields the elements of the array a which are not equal to any element of the array b.
+func differentValues(a, b json.Document) []string {
+	var result []string
+	for elem := range a.Elems() {
+		if !slices.ContainsFunc(slices.Collect(b.Elems()), func(other json.Document) bool {
+			return keywords.Equal(elem, other)
+		}) {
+			result = append(result, keywords.Text(elem))
+		}
+	}
+
+	return result
+}
+
+// difference yields the elements of a not in b.
+func difference(a, b []string) []string {
+	var result []string
+	for _, elem := range a {
+		if !slices.Contains(b, elem) {
+			result = append(result, elem)
+		}
+	}
+
+	return result
+}
+
+func typesOf(d json.Document) []string {
+	if s, ok := stringOf(d); ok {
+		return []string{s}
+	}
+
+	return strs(d)
+}
+
+// uncoveredTypes yields the types in a not accepted by b.
+func uncoveredTypes(a, b []string) []string {
+	var result []string
+	for _, t := range a {
+		if slices.Contains(b, t) || t == "integer" && slices.Contains(b, "number") {
+			continue
+		}
+
+		result = append(result, t)
+	}
+
+	return result
+}
+
+func valueAt(d json.Document, key string) json.Document {
+	value, _ := d.AtKey(key)
+
+	return value
+}
+
+func stringOf(d json.Document) (string, bool) {
+	if !d.IsString() {
+		return "", false
+	}
+
+	v, _ := d.Value()
+
+	return v.String(), true
+}
+
+func boolOf(d json.Document) (bool, bool) {
+	if !d.IsBool() {
+		return false, false
+	}
+
+	v, _ := d.Value()
+
+	return v.Bool(), true
+}
+
+func isTrue(d json.Document) bool {
+	value, ok := boolOf(d)
+
+	return ok && value
+}
+
+func isFalse(d json.Document) bool {
+	value, ok := boolOf(d)
+
+	return ok && !value
+}
+
+func numberOf(d json.Document) *big.Rat {
+	r, ok := keywords.Rat(d)
+	if !ok || !d.IsNumber() {
+		return new(big.Rat)
+	}
+
+	return r
+}
+
+// isMultiple tells if b is a multiple of a.
+func isMultiple(a, b *big.Rat) bool {
+	if a.Sign() == 0 {
+		return false
+	}
+
+	return new(big.Rat).Quo(b, a).IsInt()
+}
@@ -1,6 +1,10 @@
 package differ
 
-import "github.com/fredbi/core/jsonschema"
+import (
+	stdjson "encoding/json"
+
+	"github.com/fredbi/core/jsonschema"
+)
 
 // Differ knows how to compare two versions of a json schema.
 type Differ struct {
@@ -8,15 +12,62 @@ type Differ struct {
 }
 
 func New(opts ...Option) *Differ {
-	return &Differ{}
+	return &Differ{
+		options: optionsWithDefaults(opts),
+	}
 }
 
 // Diff computes the differences between new and old [jsonschema.Schema] as a diff [Result].
+//
+// Every change is qualified by a [Severity]: by default, a change is breaking whenever some data valid against the
+// old schema may be invalid against the new one, e.g. a tightened "maxLength", a removed "enum" value or a new required
+// property. Changes that only accept more data, such as an added optional property, are minor.
+// Changes to annotations such as "description" are documentation-only.
+//
+// This is the point of view of the producers of the data, e.g. for a request schema. For a response schema,
+// [WithDirection]([DirectionResponse]) takes the point of view of the consumers: loosening the validation is breaking,
+// tightening it is minor.
+//
+// Local "$ref"s are followed: the changes found in a referenced schema are located by JSON pointers to the
+// referenced schema.
+//
+// When the "$data" extension is enabled (see [jsonschema.WithDollarData]), keywords which value is a "$data" reference
+// are only known at validation time: any change to such a keyword is considered breaking.
 func (d *Differ) Diff(old, new jsonschema.Schema) Result {
-	return Result{} // TODO
+	c := comparison{
+		options:    d.options,
+		oldRoot:    old.Document,
+		newRoot:    new.Document,
+		visited:    make(map[location]struct{}),
+		dollarData: old.UsesDollarData() || new.UsesDollarData(),
+	}
+
+	root := location{}
+	c.visited[root] = struct{}{}
+	c.schema(old.Document, new.Document, root)
+
+	return Result{changes: c.changes}
 }
 
-// Diff computes a [jsonschema.Overlay] patch that when applied to old, produces the new schema.
+// Patch computes a [jsonschema.Overlay] that when applied to old, produces the new schema.
+//
+// The overlay is made of "remove" and "update" actions with JSONPath targets:
+// objects are patched member by member and arrays element by element whenever possible,
+// so the actions remain as local as possible.
+//
+// Applying the overlay to old yields a schema equal to new as a JSON value, with possibly a different
+// ordering of object keys.
 func (d *Differ) Patch(old, new jsonschema.Schema) jsonschema.Overlay {
-	return jsonschema.MakeOverlay() // TODO
+	p := patch{actions: []patchAction{}}
+	p.document(old.Document, new.Document, "$")
+
+	o := jsonschema.MakeOverlay()
+	data, err := stdjson.Marshal(patchOverlay{Overlay: "1.0.0", Actions: p.actions})
+	if err != nil {
+		return o
+	}
+
+	_ = o.UnmarshalJSON(data) // never fails: actions are built with valid targets
+
+	return o
 }
@@ -0,0 +1,324 @@
+package differ
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/jsonschema"
+)
+
+type expectedChange struct {
+	severity Severity
+	difftype Type
+	pointer  string
+}
+
+func TestDiff(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		old, new string
+		expected []expectedChange
+	}{
+		{
+			name: "no change",
+			old:  `{"type": "string", "maxLength": 10}`,
+			new:  `{"maxLength": 10, "type": "string"}`,
+		},
+		{
+			name: "tightened and loosened bounds",
+			old:  `{"maxLength": 10, "minimum": 1, "maxItems": 3}`,
+			new:  `{"maxLength": 5, "minimum": 0, "minItems": 1}`,
+			expected: []expectedChange{
+				{SeverityBreaking, Updated, "/maxLength"},
+				{SeverityMinor, Updated, "/minimum"},
+				{SeverityMinor, Deleted, "/maxItems"},
+				{SeverityBreaking, Added, "/minItems"},
+			},
+		},
+		{
+			name: "draft 4 exclusive bounds",
+			old:  `{"minimum": 1, "exclusiveMinimum": true}`,
+			new:  `{"minimum": 1}`,
+			expected: []expectedChange{
+				{SeverityMinor, Deleted, "/exclusiveMinimum"},
+			},
+		},
+		{
+			name: "multipleOf",
+			old:  `{"properties": {"a": {"multipleOf": 0.1}, "b": {"multipleOf": 2}}}`,
+			new:  `{"properties": {"a": {"multipleOf": 0.3}, "b": {"multipleOf": 1}}}`,
+			expected: []expectedChange{
+				{SeverityBreaking, Updated, "/properties/a/multipleOf"},
+				{SeverityMinor, Updated, "/properties/b/multipleOf"},
+			},
+		},
+		{
+			name: "enum values",
+			old:  `{"enum": ["a", "b"]}`,
+			new:  `{"enum": ["a", "c"]}`,
+			expected: []expectedChange{
+				{SeverityBreaking, Updated, "/enum"},
+				{SeverityMinor, Updated, "/enum"},
+			},
+		},
+		{
+			name: "equivalent values",
+			old:  `{"const": {"a": 1, "b": [2.0]}, "enum": [1.0, 2, {"x": 1, "y": 2}], "default": 1e2}`,
+			new:  `{"const": {"b": [2], "a": 1}, "enum": [2, 1, {"y": 2, "x": 1.00}], "default": 100}`,
+		},
+		{
+			name: "enum values with equivalent numbers",
+			old:  `{"enum": [1.0, 2]}`,
+			new:  `{"enum": [1, 3]}`,
+			expected: []expectedChange{
+				{SeverityBreaking, Updated, "/enum"},
+				{SeverityMinor, Updated, "/enum"},
+			},
+		},
+		{
+			name: "types",
+			old:  `{"type": ["integer", "string"]}`,
+			new:  `{"type": ["number", "null"]}`,
+			expected: []expectedChange{
+				{SeverityBreaking, Updated, "/type"},
+				{SeverityMinor, Updated, "/type"},
+			},
+		},
+		{
+			name: "properties",
+			old:  `{"properties": {"a": {}, "b": {}}, "required": ["a"]}`,
+			new:  `{"properties": {"a": {}, "c": {}, "d": {}}, "required": ["d"]}`,
+			expected: []expectedChange{
+				{SeverityMinor, Deleted, "/properties/b"},
+				{SeverityMinor, Added, "/properties/c"},
+				{SeverityMinor, Added, "/properties/d"},
+				{SeverityBreaking, Updated, "/required"},
+				{SeverityMinor, Updated, "/required"},
+			},
+		},
+		{
+			name: "removed property with closed objects",
+			old:  `{"properties": {"a": {}}, "additionalProperties": false}`,
+			new:  `{"additionalProperties": false}`,
+			expected: []expectedChange{
+				{SeverityBreaking, Deleted, "/properties/a"},
+			},
+		},
+		{
+			name: "documentation and cosmetic changes",
+			old:  `{"description": "a", "$comment": "x"}`,
+			new:  `{"description": "b", "title": "t"}`,
+			expected: []expectedChange{
+				{SeverityDocOnly, Updated, "/description"},
+				{SeverityCosmetic, Deleted, "/$comment"},
+				{SeverityDocOnly, Added, "/title"},
+			},
+		},
+		{
+			name: "boolean schemas",
+			old:  `{"additionalProperties": true, "items": {"type": "string"}}`,
+			new:  `{"additionalProperties": false, "items": {}}`,
+			expected: []expectedChange{
+				{SeverityBreaking, Updated, "/additionalProperties"},
+				{SeverityMinor, Deleted, "/items/type"},
+			},
+		},
+		{
+			name: "negated schemas",
+			old:  `{"not": {"maxLength": 5}}`,
+			new:  `{"not": {"maxLength": 3}}`,
+			expected: []expectedChange{
+				{SeverityMinor, Updated, "/not/maxLength"},
+			},
+		},
+		{
+			name: "compositions",
+			old:  `{"anyOf": [{"type": "string"}], "allOf": [{"minLength": 1}]}`,
+			new:  `{"anyOf": [{"type": "string"}, {"type": "integer"}], "allOf": [{"minLength": 1}, {"maxLength": 4}]}`,
+			expected: []expectedChange{
+				{SeverityMinor, Added, "/anyOf/1"},
+				{SeverityBreaking, Added, "/allOf/1"},
+			},
+		},
+		{
+			name: "tuples",
+			old:  `{"prefixItems": [{"type": "string"}]}`,
+			new:  `{"prefixItems": [{"type": "string", "format": "date"}, true]}`,
+			expected: []expectedChange{
+				{SeverityBreaking, Added, "/prefixItems/0/format"},
+				{SeverityBreaking, Added, "/prefixItems/1"},
+			},
+		},
+		{
+			name: "references",
+			old: `{
+				"$defs": {"name": {"type": "string", "maxLength": 10}},
+				"properties": {"name": {"$ref": "#/$defs/name"}, "alias": {"$ref": "#/$defs/name"}}
+			}`,
+			new: `{
+				"$defs": {"label": {"type": "string", "maxLength": 20}},
+				"properties": {"name": {"$ref": "#/$defs/label"}, "alias": {"$ref": "#/$defs/label"}}
+			}`,
+			expected: []expectedChange{
+				{SeverityPatch, Updated, "/properties/name/$ref"},
+				{SeverityMinor, Updated, "/$defs/label/maxLength"},
+				{SeverityPatch, Updated, "/properties/alias/$ref"},
+			},
+		},
+		{
+			name: "recursive references",
+			old:  `{"properties": {"next": {"$ref": "#"}, "value": {"type": "string"}}}`,
+			new:  `{"properties": {"next": {"$ref": "#"}, "value": {"type": "integer"}}}`,
+			expected: []expectedChange{
+				{SeverityBreaking, Updated, "/properties/value/type"},
+				{SeverityMinor, Updated, "/properties/value/type"},
+			},
+		},
+		{
+			name: "inline schema replaced by a reference",
+			old:  `{"$defs": {"a": {"minimum": 2}}, "properties": {"a": {"minimum": 1}}}`,
+			new:  `{"$defs": {"a": {"minimum": 2}}, "properties": {"a": {"$ref": "#/$defs/a"}}}`,
+			expected: []expectedChange{
+				{SeverityBreaking, Updated, "/$defs/a/minimum"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result := New().Diff(mustSchema(t, tc.old), mustSchema(t, tc.new))
+
+			var actual []expectedChange
+			for change := range result.Changes() {
+				actual = append(actual, expectedChange{change.Severity(), change.Type(), change.NewPointer()})
+				assert.NotEmpty(t, change.Description())
+			}
+
+			assert.Equal(t, tc.expected, actual, "unexpected changes: %v", result.changes)
+		})
+	}
+
+	t.Run("should locate changes in both documents", func(t *testing.T) {
+		result := New().Diff(
+			mustSchema(t, `{"properties": {"a": {"$ref": "#/definitions/a"}}, "definitions": {"a": {"pattern": "^x"}}}`),
+			mustSchema(t, `{"properties": {"a": {"$ref": "#/definitions/a"}}, "definitions": {"a": {"pattern": "^y"}}}`),
+		)
+		require.Equal(t, 1, result.Len())
+
+		for change := range result.Changes() {
+			assert.Equal(t, "/definitions/a/pattern", change.OldPointer())
+			assert.Equal(t, "/definitions/a/pattern", change.NewPointer())
+			assert.Equal(t, "pattern", change.Keyword())
+			assert.True(t, change.Categories().Has(CategoryValidation))
+			assert.Equal(t, StringValidation, change.ValidationCategory())
+
+			old, ok := change.OldValue()
+			require.True(t, ok)
+			assert.Equal(t, "^x", old.String())
+		}
+	})
+
+	t.Run("should ignore cosmetic changes", func(t *testing.T) {
+		result := New(WithIgnoreCosmeticChanges(true)).Diff(
+			mustSchema(t, `{"$comment": "a"}`),
+			mustSchema(t, `{"$comment": "b"}`),
+		)
+		assert.Zero(t, result.Len())
+		assert.Equal(t, SeverityNone, result.MaxSeverity())
+	})
+
+	t.Run("should consider $data references as dynamic", func(t *testing.T) {
+		dollarData := func(input string) jsonschema.Schema {
+			s := jsonschema.Make(jsonschema.WithDollarData(true))
+			require.NoError(t, s.UnmarshalJSON([]byte(input)))
+
+			return s
+		}
+
+		result := New().Diff(
+			dollarData(`{"maximum": 10, "minimum": {"$data": "1/low"}}`),
+			dollarData(`{"maximum": {"$data": "1/high"}, "minimum": {"$data": "1/low"}}`),
+		)
+		require.Equal(t, 1, result.Len())
+
+		for change := range result.Changes() {
+			assert.Equal(t, "/maximum", change.NewPointer())
+			assert.Equal(t, SeverityBreaking, change.Severity())
+			assert.Equal(t, NumberValidation, change.ValidationCategory())
+		}
+	})
+
+	t.Run("should qualify changes by the direction of the data", func(t *testing.T) {
+		const (
+			old = `{"maxLength": 10, "enum": ["a", "b"], "not": {"minimum": 1}, "oneOf": [{"type": "string"}]}`
+			new = `{"maxLength": 5, "enum": ["a", "b", "c"], "not": {"minimum": 2}, "oneOf": [{"type": "string"}, {}]}`
+		)
+
+		for _, tc := range []struct {
+			direction Direction
+			expected  []expectedChange
+		}{
+			{
+				direction: DirectionRequest,
+				expected: []expectedChange{
+					{SeverityBreaking, Updated, "/maxLength"},
+					{SeverityMinor, Updated, "/enum"},
+					{SeverityMinor, Updated, "/not/minimum"},
+					{SeverityBreaking, Added, "/oneOf/1"},
+				},
+			},
+			{
+				direction: DirectionResponse,
+				expected: []expectedChange{
+					{SeverityMinor, Updated, "/maxLength"},
+					{SeverityBreaking, Updated, "/enum"},
+					{SeverityBreaking, Updated, "/not/minimum"},
+					{SeverityBreaking, Added, "/oneOf/1"},
+				},
+			},
+			{
+				direction: DirectionBoth,
+				expected: []expectedChange{
+					{SeverityBreaking, Updated, "/maxLength"},
+					{SeverityBreaking, Updated, "/enum"},
+					{SeverityBreaking, Updated, "/not/minimum"},
+					{SeverityBreaking, Added, "/oneOf/1"},
+				},
+			},
+		} {
+			t.Run(tc.direction.String(), func(t *testing.T) {
+				result := New(WithDirection(tc.direction)).Diff(mustSchema(t, old), mustSchema(t, new))
+
+				var actual []expectedChange
+				for change := range result.Changes() {
+					actual = append(actual, expectedChange{change.Severity(), change.Type(), change.NewPointer()})
+				}
+
+				assert.ElementsMatch(t, tc.expected, actual)
+			})
+		}
+	})
+
+	t.Run("should order and filter changes", func(t *testing.T) {
+		result := New().Diff(
+			mustSchema(t, `{"description": "a", "maxLength": 3, "minLength": 1}`),
+			mustSchema(t, `{"description": "b", "maxLength": 2}`),
+		)
+		require.Equal(t, SeverityBreaking, result.MaxSeverity())
+
+		var severities []Severity
+		for change := range result.Changes(WithOrderBySeverityDesc(true), WithSeverityThreshold(SeverityMinor)) {
+			severities = append(severities, change.Severity())
+		}
+		assert.Equal(t, []Severity{SeverityBreaking, SeverityMinor}, severities)
+	})
+}
+
+func mustSchema(t *testing.T, input string) jsonschema.Schema {
+	t.Helper()
+
+	s := jsonschema.Make()
+	require.NoError(t, s.UnmarshalJSON([]byte(input)))
+
+	return s
+}
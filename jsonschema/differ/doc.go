@@ -4,6 +4,8 @@
 //
 // Differences are qualified to detect breaking changes or backward-compatible changes.
 //
+// A [Result] may be rendered as a text, markdown, HTML or JSON report with [WriteReport].
+//
 // # Patch
 //
 // A [jsonschema.Overlay] is produced that when applied to the first [jsonschema.Schema], yields the second one.
@@ -0,0 +1,13 @@
+package differ
+
+// Error is an error raised by the [Differ] or when rendering a report.
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrDiffer is the generic error raised by this package.
+	ErrDiffer Error = "differ error"
+)
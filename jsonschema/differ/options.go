@@ -3,9 +3,58 @@ package differ
 type Option func(*options)
 
 type options struct {
+	ignoreCosmetic bool
+	direction      Direction
 }
 
+// Direction tells which way the data validated by a schema flows, which decides which changes break clients.
+type Direction uint8
+
+const (
+	// DirectionRequest is for schemas which validate data sent by clients, e.g. requests: changes that reject data
+	// which used to be valid break the producers of this data. This is the default.
+	DirectionRequest Direction = iota
+
+	// DirectionResponse is for schemas which describe data sent to clients, e.g. responses: changes that accept data
+	// which used to be invalid break the consumers of this data.
+	DirectionResponse
+
+	// DirectionBoth is for schemas which describe data flowing both ways: any change to the validation is breaking.
+	DirectionBoth
+)
+
+func (d Direction) String() string {
+	switch d {
+	case DirectionResponse:
+		return "response"
+	case DirectionBoth:
+		return "both"
+	default:
+		return "request"
+	}
+}
+
+// WithDirection qualifies changes to the validation as breaking or not, depending on the [Direction]
+// of the data validated by the schema. The default is [DirectionRequest].
+func WithDirection(direction Direction) Option {
+	return func(o *options) {
+		o.direction = direction
+	}
+}
+
+// WithIgnoreCosmeticChanges omits changes with [SeverityCosmetic] from the diff [Result].
 func WithIgnoreCosmeticChanges(enabled bool) Option {
 	return func(o *options) {
+		o.ignoreCosmetic = enabled
+	}
+}
+
+func optionsWithDefaults(opts []Option) *options {
+	o := &options{}
+
+	for _, apply := range opts {
+		apply(o)
 	}
+
+	return o
 }
@@ -0,0 +1,194 @@
+package differ
+
+import (
+	stdjson "encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// patchAction is the JSON representation of an overlay action.
+type patchAction struct {
+	Target string             `json:"target"`
+	Update stdjson.RawMessage `json:"update,omitempty"`
+	Remove bool               `json:"remove,omitempty"`
+}
+
+type patchOverlay struct {
+	Overlay string        `json:"overlay"`
+	Actions []patchAction `json:"actions"`
+}
+
+// patch collects the overlay actions that transform a JSON document into another.
+type patch struct {
+	actions []patchAction
+}
+
+// document patches the old document at target into the new one.
+func (p *patch) document(old, new json.Document, target string) {
+	switch {
+	case old.IsObject() && new.IsObject():
+		p.object(old, new, target)
+	case old.IsArray() && new.IsArray() && arrayPatchable(old, new):
+		p.array(old, new, target)
+	case !keywords.Equal(old, new):
+		// the root of the document is replaced: an update on a non-object replaces the target
+		p.update(target, new)
+	}
+}
+
+// object patches the members of an object.
+//
+// Removed and replaced members are removed first, then a single update action on the object adds
+// the new and replaced members. Members which are objects or arrays on both sides are patched recursively.
+func (p *patch) object(old, new json.Document, target string) {
+	var (
+		members   []string
+		values    []json.Document
+		recursive []string
+	)
+
+	for key, oldValue := range old.Pairs() {
+		newValue, ok := new.AtKey(key)
+		switch {
+		case !ok:
+			p.remove(memberPath(target, key))
+		case oldValue.IsObject() && newValue.IsObject(),
+			oldValue.IsArray() && newValue.IsArray() && arrayPatchable(oldValue, newValue):
+			recursive = append(recursive, key)
+		case !keywords.Equal(oldValue, newValue):
+			p.remove(memberPath(target, key))
+			members = append(members, key)
+			values = append(values, newValue)
+		}
+	}
+
+	for key, newValue := range new.Pairs() {
+		if _, ok := old.AtKey(key); !ok {
+			members = append(members, key)
+			values = append(values, newValue)
+		}
+	}
+
+	if len(members) > 0 {
+		var w strings.Builder
+		w.WriteByte('{')
+		for i, key := range members {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			name, _ := stdjson.Marshal(key)
+			w.Write(name)
+			w.WriteByte(':')
+			w.WriteString(keywords.Text(values[i]))
+		}
+		w.WriteByte('}')
+
+		p.actions = append(p.actions, patchAction{Target: target, Update: stdjson.RawMessage(w.String())})
+	}
+
+	for _, key := range recursive {
+		oldValue, _ := old.AtKey(key)
+		newValue, _ := new.AtKey(key)
+		p.document(oldValue, newValue, memberPath(target, key))
+	}
+}
+
+// array patches the elements of an array, which must be patchable (see arrayPatchable).
+//
+// Common elements are patched recursively, trailing elements are removed and extra elements are appended.
+func (p *patch) array(old, new json.Document, target string) {
+	common := min(old.Len(), new.Len())
+
+	for i := range common {
+		oldElem, _ := old.Elem(i)
+		newElem, _ := new.Elem(i)
+		if !keywords.Equal(oldElem, newElem) {
+			p.document(oldElem, newElem, target+"["+strconv.Itoa(i)+"]")
+		}
+	}
+
+	if old.Len() > common {
+		p.remove(target + "[" + strconv.Itoa(common) + ":]")
+	}
+
+	for i := common; i < new.Len(); i++ {
+		newElem, _ := new.Elem(i)
+		p.update(target, newElem)
+	}
+}
+
+func (p *patch) remove(target string) {
+	p.actions = append(p.actions, patchAction{Target: target, Remove: true})
+}
+
+func (p *patch) update(target string, value json.Document) {
+	p.actions = append(p.actions, patchAction{Target: target, Update: stdjson.RawMessage(keywords.Text(value))})
+}
+
+// arrayPatchable tells if an array may be patched element by element.
+//
+// Overlay actions cannot replace an array element by a scalar: this is only possible when the common elements
+// are equal, or objects, or patchable arrays.
+func arrayPatchable(old, new json.Document) bool {
+	common := min(old.Len(), new.Len())
+
+	for i := range common {
+		oldElem, _ := old.Elem(i)
+		newElem, _ := new.Elem(i)
+
+		switch {
+		case oldElem.IsObject() && newElem.IsObject():
+		case oldElem.IsArray() && newElem.IsArray() && arrayPatchable(oldElem, newElem):
+		case keywords.Equal(oldElem, newElem):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// memberPath is the JSONPath expression to the member of an object.
+//
+// Names which are not valid identifiers use the bracket notation.
+func memberPath(target, key string) string {
+	if isIdentifier(key) {
+		return target + "." + key
+	}
+
+	var w strings.Builder
+	w.WriteString(target)
+	w.WriteString("['")
+	for _, r := range key {
+		switch {
+		case r == '\'' || r == '\\':
+			w.WriteByte('\\')
+			w.WriteRune(r)
+		case r < 0x20:
+			w.WriteString(`\u`)
+			w.WriteString(strconv.FormatInt(int64(r)+0x10000, 16)[1:])
+		default:
+			w.WriteRune(r)
+		}
+	}
+	w.WriteString("']")
+
+	return w.String()
+}
+
+func isIdentifier(key string) bool {
+	if key == "" || key[0] >= '0' && key[0] <= '9' {
+		return false
+	}
+
+	for _, r := range key {
+		if r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,112 @@
+package differ
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatch(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		old, new string
+		actions  int
+		overlay  string
+	}{
+		{
+			name: "no change",
+			old:  `{"type": "string", "maxLength": 10}`,
+			new:  `{"maxLength": 10, "type": "string"}`,
+		},
+		{
+			name:    "updated keywords",
+			old:     `{"maxLength": 10, "minimum": 1, "maxItems": 3}`,
+			new:     `{"maxLength": 5, "minimum": 1, "minItems": 1}`,
+			actions: 3,
+			overlay: `{"overlay": "1.0.0", "actions": [
+				{"target": "$.maxLength", "remove": true},
+				{"target": "$.maxItems", "remove": true},
+				{"target": "$", "update": {"maxLength": 5, "minItems": 1}}
+			]}`,
+		},
+		{
+			name:    "nested properties",
+			old:     `{"properties": {"a": {"type": "string"}, "b": {}}, "required": ["a"]}`,
+			new:     `{"properties": {"a": {"type": ["string", "null"]}, "c": {"$ref": "#/$defs/c"}}, "required": ["a", "c"], "$defs": {"c": true}}`,
+			actions: 6,
+		},
+		{
+			name:    "arrays",
+			old:     `{"allOf": [{"minLength": 1}, {"maxLength": 4}, {"pattern": "^a"}], "enum": ["a", "b"], "examples": [[1, 2], [3]]}`,
+			new:     `{"allOf": [{"minLength": 2}, {"maxLength": 4}], "enum": ["a", "c", "d"], "examples": [[1, 2, 5], [3], [4]]}`,
+			actions: 7,
+			overlay: `{"overlay": "1.0.0", "actions": [
+				{"target": "$.enum", "remove": true},
+				{"target": "$", "update": {"enum": ["a", "c", "d"]}},
+				{"target": "$.allOf[0].minLength", "remove": true},
+				{"target": "$.allOf[0]", "update": {"minLength": 2}},
+				{"target": "$.allOf[2:]", "remove": true},
+				{"target": "$.examples[0]", "update": 5},
+				{"target": "$.examples", "update": [4]}
+			]}`,
+		},
+		{
+			name: "equivalent values",
+			old:  `{"const": {"a": 1, "b": 2}, "enum": [1.0, 2], "examples": [{"x": [1e1]}]}`,
+			new:  `{"const": {"b": 2, "a": 1}, "enum": [1, 2], "examples": [{"x": [10]}]}`,
+		},
+		{
+			name:    "replaced values",
+			old:     `{"items": {"type": "string"}, "additionalProperties": {}, "const": "object"}`,
+			new:     `{"items": [{"type": "string"}], "additionalProperties": false, "const": null}`,
+			actions: 4,
+		},
+		{
+			name:    "keys requiring escaping",
+			old:     `{"properties": {"a.b": {}, "it's": {"type": "string"}, "x-y": {}, "0": {}}}`,
+			new:     `{"properties": {"it's": {"type": "integer"}, "x-y": {"title": "\\"}, "0": {"title": "zero"}}}`,
+			actions: 5,
+			overlay: `{"overlay": "1.0.0", "actions": [
+				{"target": "$.properties['a.b']", "remove": true},
+				{"target": "$.properties['it\\'s'].type", "remove": true},
+				{"target": "$.properties['it\\'s']", "update": {"type": "integer"}},
+				{"target": "$.properties['x-y']", "update": {"title": "\\"}},
+				{"target": "$.properties['0']", "update": {"title": "zero"}}
+			]}`,
+		},
+		{
+			name:    "boolean schemas",
+			old:     `true`,
+			new:     `{"not": {}}`,
+			actions: 1,
+		},
+		{
+			name:    "object replaced by a boolean schema",
+			old:     `{"type": "string"}`,
+			new:     `false`,
+			actions: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			old, new := mustSchema(t, tc.old), mustSchema(t, tc.new)
+			o := New().Patch(old, new)
+
+			var actions int
+			for range o.Actions() {
+				actions++
+			}
+			assert.Equal(t, tc.actions, actions)
+
+			overlay, err := o.MarshalJSON()
+			require.NoError(t, err)
+			if tc.overlay != "" {
+				assert.JSONEq(t, tc.overlay, string(overlay))
+			}
+
+			patched, err := o.ApplyTo(old).MarshalJSON()
+			require.NoError(t, err)
+			assert.JSONEqf(t, tc.new, string(patched), "with overlay: %s", overlay)
+		})
+	}
+}
@@ -1,8 +1,198 @@
 package differ
 
-import "io"
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
 
 // WriteReport knows how to render a diff [Result] as a human readable report.
+//
+// The report is a plain text table by default: see [WithOutputMode] for other formats,
+// and [WithThreshold] to omit changes with a low [Severity].
 func WriteReport(w io.Writer, r Result, opts ...ReportOption) error {
-	return nil
+	o := reportOptionsWithDefaults(opts)
+	changes := r.changesWithOptions([]ChangesOption{WithSeverityThreshold(o.severityThreshold)})
+
+	switch o.reportMode {
+	case ReportOutputTable:
+		return writeTable(w, changes)
+	case ReportOutputMarkdown:
+		return writeMarkdown(w, changes)
+	case ReportOutputHTML:
+		return writeHTML(w, changes)
+	case ReportOutputJSON:
+		return writeJSON(w, changes)
+	default:
+		return fmt.Errorf("unsupported report output mode %d: %w", o.reportMode, ErrDiffer)
+	}
+}
+
+func writeTable(w io.Writer, changes []Change) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if len(changes) > 0 {
+		fmt.Fprintln(tw, "SEVERITY\tCHANGE\tLOCATION\tDESCRIPTION")
+	}
+
+	for _, c := range changes {
+		fmt.Fprintf(tw, "%v\t%v\t%s\t%s\n", c.severity, c.difftype, locationOf(c, " -> "), c.message)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintln(w, summary(changes))
+
+	return err
+}
+
+func writeMarkdown(w io.Writer, changes []Change) error {
+	var b strings.Builder
+
+	if len(changes) > 0 {
+		b.WriteString("| Severity | Change | Location | Description |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+	}
+
+	cell := strings.NewReplacer("|", `\|`, "\n", " ")
+	for _, c := range changes {
+		location := "`" + c.newPointer + "`"
+		if c.oldPointer != c.newPointer {
+			location = "`" + c.oldPointer + "` → " + location
+		}
+
+		fmt.Fprintf(&b, "| %v | %v | %s | %s |\n", c.severity, c.difftype, cell.Replace(location), cell.Replace(c.message))
+	}
+
+	if len(changes) > 0 {
+		b.WriteByte('\n')
+	}
+	b.WriteString("**" + summary(changes) + "**\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func writeHTML(w io.Writer, changes []Change) error {
+	var b strings.Builder
+
+	if len(changes) > 0 {
+		b.WriteString("<table>\n<thead><tr><th>Severity</th><th>Change</th><th>Location</th><th>Description</th></tr></thead>\n<tbody>\n")
+
+		for _, c := range changes {
+			fmt.Fprintf(&b, "<tr><td>%v</td><td>%v</td><td><code>%s</code></td><td>%s</td></tr>\n",
+				c.severity, c.difftype, html.EscapeString(locationOf(c, " → ")), html.EscapeString(c.message),
+			)
+		}
+
+		b.WriteString("</tbody>\n</table>\n")
+	}
+
+	b.WriteString("<p>" + html.EscapeString(summary(changes)) + "</p>\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+type jsonReport struct {
+	MaxSeverity string         `json:"maxSeverity"`
+	Summary     map[string]int `json:"summary"`
+	Changes     []jsonChange   `json:"changes"`
+}
+
+type jsonChange struct {
+	Severity           string             `json:"severity"`
+	Type               string             `json:"type"`
+	Categories         []string           `json:"categories"`
+	ValidationCategory string             `json:"validationCategory,omitempty"`
+	Keyword            string             `json:"keyword,omitempty"`
+	OldPointer         string             `json:"oldPointer"`
+	NewPointer         string             `json:"newPointer"`
+	OldValue           stdjson.RawMessage `json:"oldValue,omitempty"`
+	NewValue           stdjson.RawMessage `json:"newValue,omitempty"`
+	Description        string             `json:"description"`
+}
+
+func writeJSON(w io.Writer, changes []Change) error {
+	report := jsonReport{
+		MaxSeverity: Result{changes: changes}.MaxSeverity().String(),
+		Summary:     make(map[string]int),
+		Changes:     make([]jsonChange, 0, len(changes)),
+	}
+
+	for _, c := range changes {
+		report.Summary[c.severity.String()]++
+
+		change := jsonChange{
+			Severity:    c.severity.String(),
+			Type:        c.difftype.String(),
+			Categories:  strings.Split(c.categories.String(), ","),
+			Keyword:     c.keyword,
+			OldPointer:  c.oldPointer,
+			NewPointer:  c.newPointer,
+			Description: c.message,
+		}
+
+		if c.categories.Has(CategoryValidation) {
+			change.ValidationCategory = c.validationCategory.String()
+		}
+
+		if c.hasOld {
+			change.OldValue = stdjson.RawMessage(keywords.Text(c.oldValue))
+		}
+
+		if c.hasNew {
+			change.NewValue = stdjson.RawMessage(keywords.Text(c.newValue))
+		}
+
+		report.Changes = append(report.Changes, change)
+	}
+
+	enc := stdjson.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(report)
+}
+
+func locationOf(c Change, arrow string) string {
+	if c.oldPointer == c.newPointer {
+		return c.newPointer
+	}
+
+	return c.oldPointer + arrow + c.newPointer
+}
+
+// summary counts changes by severity, e.g. "3 changes: 1 breaking, 2 minor".
+func summary(changes []Change) string {
+	if len(changes) == 0 {
+		return "no change"
+	}
+
+	var counts [SeverityBreaking + 1]int
+	for _, c := range changes {
+		counts[c.severity]++
+	}
+
+	parts := make([]string, 0, len(counts))
+	for severity := SeverityBreaking; severity > SeverityNone; severity-- {
+		if counts[severity] > 0 {
+			parts = append(parts, strconv.Itoa(counts[severity])+" "+severity.String())
+		}
+	}
+
+	noun := "changes"
+	if len(changes) == 1 {
+		noun = "change"
+	}
+
+	return strconv.Itoa(len(changes)) + " " + noun + ": " + strings.Join(parts, ", ")
 }
@@ -5,9 +5,17 @@ type ReportOption func(*reportOptions)
 type ReportOutputMode uint8
 
 const (
+	// ReportOutputTable renders a plain text table.
 	ReportOutputTable ReportOutputMode = 1 << iota
+
+	// ReportOutputMarkdown renders a markdown table.
 	ReportOutputMarkdown
+
+	// ReportOutputHTML renders an HTML table.
 	ReportOutputHTML
+
+	// ReportOutputJSON renders a JSON document, for machine consumption.
+	ReportOutputJSON
 )
 
 type reportOptions struct {
@@ -15,8 +23,30 @@ type reportOptions struct {
 	severityThreshold Severity
 }
 
+// WithThreshold only reports changes with a [Severity] greater than or equal to minSeverity.
 func WithThreshold(minSeverity Severity) ReportOption {
 	return func(o *reportOptions) {
 		o.severityThreshold = minSeverity
 	}
 }
+
+// WithOutputMode selects the format of the report.
+//
+// The default is [ReportOutputTable].
+func WithOutputMode(mode ReportOutputMode) ReportOption {
+	return func(o *reportOptions) {
+		o.reportMode = mode
+	}
+}
+
+func reportOptionsWithDefaults(opts []ReportOption) reportOptions {
+	o := reportOptions{
+		reportMode: ReportOutputTable,
+	}
+
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	return o
+}
@@ -0,0 +1,86 @@
+package differ
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteReport(t *testing.T) {
+	result := New().Diff(
+		mustSchema(t, `{"description": "a pet", "properties": {"name": {"maxLength": 10}}}`),
+		mustSchema(t, `{"description": "a pet | a dog", "properties": {"name": {"maxLength": 5}}}`),
+	)
+	require.Equal(t, 2, result.Len())
+
+	t.Run("should render a text table", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteReport(&buf, result))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 4)
+		assert.True(t, strings.HasPrefix(lines[0], "SEVERITY"))
+		assert.Contains(t, lines[1], "doc-only")
+		assert.Contains(t, lines[2], "/properties/name/maxLength")
+		assert.Equal(t, "2 changes: 1 breaking, 1 doc-only", lines[3])
+	})
+
+	t.Run("should render markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteReport(&buf, result, WithOutputMode(ReportOutputMarkdown)))
+
+		output := buf.String()
+		assert.Contains(t, output, "| Severity | Change | Location | Description |")
+		assert.Contains(t, output, "| breaking | updated | `/properties/name/maxLength` |")
+		assert.Contains(t, output, `a pet \| a dog`)
+	})
+
+	t.Run("should render HTML", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteReport(&buf, result, WithOutputMode(ReportOutputHTML)))
+
+		assert.Contains(t, buf.String(), "<td>breaking</td>")
+	})
+
+	t.Run("should render JSON above a threshold", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteReport(&buf, result, WithOutputMode(ReportOutputJSON), WithThreshold(SeverityMinor)))
+
+		var report struct {
+			MaxSeverity string `json:"maxSeverity"`
+			Changes     []struct {
+				Severity   string             `json:"severity"`
+				NewPointer string             `json:"newPointer"`
+				OldValue   stdjson.Number     `json:"oldValue"`
+				NewValue   stdjson.Number     `json:"newValue"`
+				Categories []string           `json:"categories"`
+				Validation stdjson.RawMessage `json:"validationCategory"`
+			} `json:"changes"`
+		}
+		require.NoError(t, stdjson.Unmarshal(buf.Bytes(), &report))
+
+		assert.Equal(t, "breaking", report.MaxSeverity)
+		require.Len(t, report.Changes, 1)
+		change := report.Changes[0]
+		assert.Equal(t, "/properties/name/maxLength", change.NewPointer)
+		assert.Equal(t, stdjson.Number("10"), change.OldValue)
+		assert.Equal(t, stdjson.Number("5"), change.NewValue)
+		assert.Equal(t, []string{"validation"}, change.Categories)
+		assert.JSONEq(t, `"string"`, string(change.Validation))
+	})
+
+	t.Run("should report no change", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteReport(&buf, result, WithThreshold(SeverityBreaking+1)))
+
+		assert.Equal(t, "no change\n", buf.String())
+	})
+
+	t.Run("should fail on unsupported output mode", func(t *testing.T) {
+		require.ErrorIs(t, WriteReport(&bytes.Buffer{}, result, WithOutputMode(0)), ErrDiffer)
+	})
+}
@@ -1,16 +1,93 @@
 package differ
 
 import (
+	"cmp"
 	"iter"
 	"slices"
+
+	"github.com/fredbi/core/json"
 )
 
+// Change is a single difference between two schemas.
 type Change struct {
 	severity           Severity
 	categories         CategoryMode
 	validationCategory ValidationCategory
 	difftype           Type
-	context            struct{} // TODO: Document context: where is the change located
+
+	keyword    string
+	oldPointer string
+	newPointer string
+	oldValue   json.Document
+	newValue   json.Document
+	hasOld     bool
+	hasNew     bool
+	message    string
+}
+
+// Severity of the change.
+func (c Change) Severity() Severity {
+	return c.severity
+}
+
+// Categories of the change.
+func (c Change) Categories() CategoryMode {
+	return c.categories
+}
+
+// ValidationCategory tells which kind of validation is affected by the change.
+//
+// This is only relevant for changes with [CategoryValidation].
+func (c Change) ValidationCategory() ValidationCategory {
+	return c.validationCategory
+}
+
+// Type of the change.
+func (c Change) Type() Type {
+	return c.difftype
+}
+
+// Keyword affected by the change, i.e. the last token of its pointers.
+//
+// This is a property name or an index for changes to the members of "properties" or "allOf" for instance,
+// and the empty string when a whole schema is changed.
+func (c Change) Keyword() string {
+	return c.keyword
+}
+
+// OldPointer is the JSON pointer to the changed keyword in the old schema.
+//
+// For an added keyword, it points to where the keyword would be in the old schema.
+//
+// When a change is found by following a "$ref", the pointer locates the referenced schema.
+func (c Change) OldPointer() string {
+	return c.oldPointer
+}
+
+// NewPointer is the JSON pointer to the changed keyword in the new schema.
+//
+// For a deleted keyword, it points to where the keyword would be in the new schema.
+func (c Change) NewPointer() string {
+	return c.newPointer
+}
+
+// OldValue is the value of the keyword in the old schema, if any.
+func (c Change) OldValue() (json.Document, bool) {
+	return c.oldValue, c.hasOld
+}
+
+// NewValue is the value of the keyword in the new schema, if any.
+func (c Change) NewValue() (json.Document, bool) {
+	return c.newValue, c.hasNew
+}
+
+// Description of the change, in plain English.
+func (c Change) Description() string {
+	return c.message
+}
+
+func (c Change) String() string {
+	return c.severity.String() + ": " + c.newPointer + ": " + c.message
 }
 
 // Result of the differences analysis between two schemas.
@@ -18,6 +95,23 @@ type Result struct {
 	changes []Change
 }
 
+// Len is the number of changes.
+func (r Result) Len() int {
+	return len(r.changes)
+}
+
+// MaxSeverity is the highest [Severity] of all changes, or [SeverityNone] if there is no change.
+//
+// A CI pipeline may for instance reject new schemas with [SeverityBreaking] changes.
+func (r Result) MaxSeverity() Severity {
+	var highest Severity
+	for _, change := range r.changes {
+		highest = max(highest, change.severity)
+	}
+
+	return highest
+}
+
 type ChangesOption func(*changesOptions)
 
 type changesOptions struct {
@@ -25,6 +119,23 @@ type changesOptions struct {
 	filterSeverityThreshold Severity
 }
 
+// WithOrderBySeverityDesc yields changes with the highest severity first.
+//
+// Changes with the same severity are yielded in the order they have been found.
+func WithOrderBySeverityDesc(enabled bool) ChangesOption {
+	return func(o *changesOptions) {
+		o.orderBySeverityDesc = enabled
+	}
+}
+
+// WithSeverityThreshold only yields changes with a [Severity] greater than or equal to minSeverity.
+func WithSeverityThreshold(minSeverity Severity) ChangesOption {
+	return func(o *changesOptions) {
+		o.filterSeverityThreshold = minSeverity
+	}
+}
+
+// Changes yields the changes, by default in the order they have been found.
 func (r Result) Changes(opts ...ChangesOption) iter.Seq[Change] {
 	return slices.Values(r.changesWithOptions(opts))
 }
@@ -34,5 +145,20 @@ func (r Result) changesWithOptions(opts []ChangesOption) []Change {
 		return r.changes
 	}
 
-	return nil // TODO
+	var o changesOptions
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	changes := slices.DeleteFunc(slices.Clone(r.changes), func(c Change) bool {
+		return c.severity.Less(o.filterSeverityThreshold)
+	})
+
+	if o.orderBySeverityDesc {
+		slices.SortStableFunc(changes, func(a, b Change) int {
+			return cmp.Compare(b.severity, a.severity)
+		})
+	}
+
+	return changes
 }
@@ -45,26 +45,46 @@
 //
 // [Ref] handles cyclical references, JSON schemas "$id", "$anchor" and "$dynamicAnchor".
 //
+// References are resolved by a [Resolver], which may be configured to redirect remote URLs to local
+// directories (see [WithOfflineMirror]) and to share loaded documents through a [ResolverCache].
+//
+// # Meta-schemas and vocabularies
+//
+// A [MetaValidator] checks a [Schema] against the meta-schema of its version of JSON schema, honoring the
+// "$vocabulary" declared by custom meta-schemas.
+//
+// Custom keywords are declared by a [Vocabulary] registered in a [VocabularyRegistry]: each [Keyword] tells the
+// shape of its value, its [VersionRequirements], if it is an annotation or an assertion, and how it validates data.
+//
 // # Collections
 //
 // [Schema] s and [Overlay] s can be regrouped in collections, [Collection] and [OverlayCollection] respectively,
 // to share settings and perform operations on the whole collection of items.
 //
+// A [Collection] loads schemas from JSON arrays of schemas, YAML streams of several documents, files and directories
+// (see [Collection.LoadDir]). Every loaded [Schema] is located by a [Source], which analyzers report in their
+// findings and error messages.
+//
 // # YAML support
 //
-// # TODO
+// Schemas may be written in YAML: [YAMLToJSON] converts the documents of a YAML stream to JSON, preserving the order
+// of keys.
 //
 // # Other tools
 //
+//   - Package [github.com/fredbi/core/jsonschema/analyzers/canonical] normalizes a [Schema] into a canonical AST, with a content hash per subschema.
 //   - Package [github.com/fredbi/core/jsonschema/analyzers/structural] analyzes a [Schema] to build model generators from a JSON schema specification.
+//   - Package [github.com/fredbi/core/jsonschema/analyzers/structural/export] renders the dependency graph of a [Schema] as DOT, Mermaid or JSON.
+//   - Package [github.com/fredbi/core/jsonschema/analyzers/subsumption] decides if every instance valid against a [Schema] is valid against another one.
 //   - Package [github.com/fredbi/core/jsonschema/analyzers/validations] analyzes a [Schema] to build validators or generators.
 //   - Package [github.com/fredbi/core/jsonschema/cmd/jsonschema] provides a CLI to use the tools exposed by the jsonschema packages library.
 //   - Package [github.com/fredbi/core/jsonschema/converter] provides a Converter type to convert a [Schema] to another json schema version.
 //   - Package [github.com/fredbi/core/jsonschema/differ] provides a Differ type to analyze changes between two [Schema] s.
 //   - Package [github.com/fredbi/core/jsonschema/faker] provides types to generate fake [Schema] s or fake data that
+//     validate against a given [Schema].
 //   - Package [github.com/fredbi/core/jsonschema/linter] provides a linter for json schemas.
+//   - Package [github.com/fredbi/core/jsonschema/transformer] applies defaults, removes additional properties and coerces types in data, as directed by a [Schema].
 //   - Package [github.com/fredbi/core/jsonschema/validator] wraps all needed analysis to provide a data validator against a [Schema].
-//     validate against a given [Schema].
 //
 // # TODOs
 //
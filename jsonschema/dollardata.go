@@ -0,0 +1,202 @@
+package jsonschema
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/json/stores/values"
+)
+
+// dollarDataKeys are the keywords which value may be a "$data" reference, as supported by ajv.
+//
+//nolint:gochecknoglobals // declaration of keywords
+var dollarDataKeys = map[values.InternedKey]struct{}{
+	constKey:            {},
+	enumKey:             {},
+	formatKey:           {},
+	maximumKey:          {},
+	minimumKey:          {},
+	exclusiveMaximumKey: {},
+	exclusiveMinimumKey: {},
+	maxLengthKey:        {},
+	minLengthKey:        {},
+	maxItemsKey:         {},
+	minItemsKey:         {},
+	maxPropertiesKey:    {},
+	minPropertiesKey:    {},
+	multipleOfKey:       {},
+	patternKey:          {},
+	requiredKey:         {},
+	uniqueItemsKey:      {},
+}
+
+// SupportsDollarData tells if the value of a keyword may be a "$data" reference.
+//
+// Like ajv, "$data" is supported by "const", "enum", "format", "maximum", "minimum", "exclusiveMaximum",
+// "exclusiveMinimum", "maxLength", "minLength", "maxItems", "minItems", "maxProperties", "minProperties",
+// "multipleOf", "pattern", "required" and "uniqueItems".
+func SupportsDollarData(keyword string) bool {
+	_, ok := dollarDataKeys[values.MakeInternedKey(keyword)]
+
+	return ok
+}
+
+// IsDataRef tells if the value of a keyword is a "$data" reference, i.e. an object with a "$data" member.
+//
+// The value of such keywords is only known at validation time: analyzers should consider them as dynamic
+// rather than reason on them statically.
+func IsDataRef(keyword string, value json.Document) bool {
+	if !value.IsObject() || !SupportsDollarData(keyword) {
+		return false
+	}
+
+	_, ok := value.AtKey("$data")
+
+	return ok
+}
+
+// DataRef is a "$data" reference, which resolves the value of a keyword from the JSON data under validation.
+//
+// A "$data" reference is a relative JSON pointer, evaluated from the location of the data validated
+// by the schema that holds the keyword, e.g.:
+//
+//	{
+//	  "properties": {
+//	    "smaller": {"maximum": {"$data": "1/larger"}},
+//	    "larger": {"type": "number"}
+//	  }
+//	}
+//
+// See https://datatracker.ietf.org/doc/html/draft-handrews-relative-json-pointer-01.
+type DataRef struct {
+	keyword string
+	pointer string
+	up      int
+	index   bool
+	tail    json.Pointer
+}
+
+// MakeDataRef builds a [DataRef] from the value of a keyword, i.e. an object like {"$data": "1/foo"}.
+//
+// It fails with [ErrDollarData] if the keyword doesn't support "$data" (see [SupportsDollarData]) or if the value
+// is not a valid relative JSON pointer.
+func MakeDataRef(keyword string, value json.Document) (DataRef, error) {
+	if !SupportsDollarData(keyword) {
+		return DataRef{}, fmt.Errorf("keyword %q does not support $data: %w", keyword, ErrDollarData)
+	}
+
+	data, ok := value.AtKey("$data")
+	if !ok || !data.IsString() {
+		return DataRef{}, fmt.Errorf(`keyword %q: $data must be a string: %w`, keyword, ErrDollarData)
+	}
+
+	v, _ := data.Value()
+
+	return parseDataRef(keyword, v.String())
+}
+
+func parseDataRef(keyword, pointer string) (DataRef, error) {
+	ref := DataRef{keyword: keyword, pointer: pointer}
+
+	digits := strings.IndexFunc(pointer, func(r rune) bool { return r < '0' || r > '9' })
+	if digits < 0 {
+		digits = len(pointer)
+	}
+
+	prefix, rest := pointer[:digits], pointer[digits:]
+	if prefix == "" || len(prefix) > 1 && prefix[0] == '0' {
+		return DataRef{}, fmt.Errorf("keyword %q: invalid relative JSON pointer %q: %w", keyword, pointer, ErrDollarData)
+	}
+
+	up, err := strconv.Atoi(prefix)
+	if err != nil {
+		return DataRef{}, fmt.Errorf("keyword %q: invalid relative JSON pointer %q: %w: %w", keyword, pointer, err, ErrDollarData)
+	}
+	ref.up = up
+
+	if rest == "#" {
+		ref.index = true
+
+		return ref, nil
+	}
+
+	ref.tail, err = json.MakePointer(rest)
+	if err != nil {
+		return DataRef{}, fmt.Errorf("keyword %q: invalid relative JSON pointer %q: %w: %w", keyword, pointer, err, ErrDollarData)
+	}
+
+	return ref, nil
+}
+
+// Keyword which value is resolved by this reference.
+func (r DataRef) Keyword() string {
+	return r.keyword
+}
+
+// String representation of the reference, as a relative JSON pointer.
+func (r DataRef) String() string {
+	return r.pointer
+}
+
+// Up is the number of levels to walk up from the current location in the data.
+func (r DataRef) Up() int {
+	return r.up
+}
+
+// IsIndex tells if the reference resolves to the key or the index of a value rather than to the value itself,
+// e.g. "1#".
+func (r DataRef) IsIndex() bool {
+	return r.index
+}
+
+// Pointer is the JSON pointer to walk down from the location reached after walking up.
+func (r DataRef) Pointer() json.Pointer {
+	return r.tail
+}
+
+// Resolve the reference against JSON data, where location is the JSON pointer to the value validated by the schema
+// holding the keyword.
+//
+// A reference that doesn't resolve yields an error wrapping [json.ErrPointerNotFound]: as specified by ajv,
+// the validator should then ignore the keyword.
+func (r DataRef) Resolve(root json.Document, location json.Pointer) (json.Document, error) {
+	if r.up > len(location) {
+		return json.EmptyDocument, fmt.Errorf("$data %q walks up beyond the root of the data: %w", r.pointer, json.ErrPointerNotFound)
+	}
+
+	target := location[:len(location)-r.up]
+
+	if !r.index {
+		value, err := root.GetPointer(append(slices.Clone(target), r.tail...))
+		if err != nil {
+			return json.EmptyDocument, fmt.Errorf("$data %q: %w", r.pointer, err)
+		}
+
+		return value, nil
+	}
+
+	if len(target) == 0 {
+		return json.EmptyDocument, fmt.Errorf("$data %q: the root of the data has no key or index: %w", r.pointer, json.ErrPointerNotFound)
+	}
+
+	parent, err := root.GetPointer(target[:len(target)-1])
+	if err != nil {
+		return json.EmptyDocument, fmt.Errorf("$data %q: %w", r.pointer, err)
+	}
+
+	token := []byte(json.UnescapeToken(strings.TrimPrefix(target[len(target)-1:].String(), "/")))
+	if !parent.IsArray() {
+		token, _ = stdjson.Marshal(string(token))
+	}
+
+	value := json.Make()
+	if err := value.UnmarshalJSON(token); err != nil {
+		return json.EmptyDocument, fmt.Errorf("$data %q: %w: %w", r.pointer, err, ErrDollarData)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,151 @@
+package jsonschema
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/fredbi/core/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataRef(t *testing.T) {
+	t.Run("should parse relative JSON pointers", func(t *testing.T) {
+		for _, tc := range []struct {
+			pointer         string
+			expectedUp      int
+			expectedIndex   bool
+			expectedPointer string
+		}{
+			{"0", 0, false, ""},
+			{"1/limit", 1, false, "/limit"},
+			{"2/a~1b/0", 2, false, "/a~1b/0"},
+			{"1#", 1, true, ""},
+		} {
+			t.Run(tc.pointer, func(t *testing.T) {
+				ref, err := MakeDataRef("maximum", mustDocument(t, `{"$data": "`+tc.pointer+`"}`))
+				require.NoError(t, err)
+
+				assert.Equal(t, "maximum", ref.Keyword())
+				assert.Equal(t, tc.pointer, ref.String())
+				assert.Equal(t, tc.expectedUp, ref.Up())
+				assert.Equal(t, tc.expectedIndex, ref.IsIndex())
+				assert.Equal(t, tc.expectedPointer, ref.Pointer().String())
+			})
+		}
+	})
+
+	t.Run("should refuse invalid references", func(t *testing.T) {
+		for _, tc := range []struct {
+			keyword string
+			value   string
+		}{
+			{"maximum", `{"$data": "/limit"}`},
+			{"maximum", `{"$data": "01/limit"}`},
+			{"maximum", `{"$data": "1limit"}`},
+			{"maximum", `{"$data": 1}`},
+			{"type", `{"$data": "1/type"}`},
+		} {
+			_, err := MakeDataRef(tc.keyword, mustDocument(t, tc.value))
+			require.ErrorIsf(t, err, ErrDollarData, "%s: %s", tc.keyword, tc.value)
+		}
+	})
+
+	t.Run("should resolve against data", func(t *testing.T) {
+		data := mustDocument(t, `{"limit": 10, "items": [{"a/b": 1, "sibling": "x"}, {"a/b": 2}]}`)
+
+		for _, tc := range []struct {
+			pointer  string
+			location string
+			expected string
+		}{
+			{"1/limit", "/items", `10`},
+			{"0", "/limit", `10`},
+			{"1/sibling", "/items/0/a~1b", `"x"`},
+			{"2/limit", "/items/0", `10`},
+			{"0#", "/items/1", `1`},
+			{"0#", "/items/0/a~1b", `"a/b"`},
+			{"1#", "/items/0/sibling", `0`},
+		} {
+			t.Run(tc.pointer+" at "+tc.location, func(t *testing.T) {
+				ref, err := MakeDataRef("const", mustDocument(t, `{"$data": "`+tc.pointer+`"}`))
+				require.NoError(t, err)
+
+				location, err := json.MakePointer(tc.location)
+				require.NoError(t, err)
+
+				value, err := ref.Resolve(data, location)
+				require.NoError(t, err)
+
+				actual, err := value.MarshalJSON()
+				require.NoError(t, err)
+				assert.JSONEq(t, tc.expected, string(actual))
+			})
+		}
+	})
+
+	t.Run("should not resolve missing data", func(t *testing.T) {
+		data := mustDocument(t, `{"limit": 10}`)
+
+		for _, pointer := range []string{"0/missing", "2/limit", "0#"} {
+			ref, err := MakeDataRef("const", mustDocument(t, `{"$data": "`+pointer+`"}`))
+			require.NoError(t, err)
+
+			location, err := json.MakePointer("/limit")
+			require.NoError(t, err)
+			if pointer == "0#" {
+				location = json.EmptyPointer
+			}
+
+			_, err = ref.Resolve(data, location)
+			require.ErrorIs(t, err, json.ErrPointerNotFound)
+		}
+	})
+}
+
+func TestSchemaWithDollarData(t *testing.T) {
+	const jazon = `{"minimum": {"$data": "1/min"}, "enum": {"$data": "1/choices"}, "maxLength": 3, "const": {"data": 1}}`
+
+	t.Run("should decode $data references", func(t *testing.T) {
+		sch := Make(WithDollarData(true))
+		require.NoError(t, sch.UnmarshalJSON([]byte(jazon)))
+		require.True(t, sch.UsesDollarData())
+
+		validation := sch.Validation()
+		require.True(t, validation.HasDataRefs())
+		assert.True(t, validation.IsDynamic("minimum"))
+		assert.True(t, validation.IsDynamic("enum"))
+		assert.False(t, validation.IsDynamic("maxLength"))
+		assert.False(t, validation.IsDynamic("const"))
+
+		ref, ok := validation.DataRef("enum")
+		require.True(t, ok)
+		assert.Equal(t, "1/choices", ref.String())
+
+		keywords := slices.Collect(func(yield func(string) bool) {
+			for ref := range validation.DataRefs() {
+				if !yield(ref.Keyword()) {
+					return
+				}
+			}
+		})
+		assert.ElementsMatch(t, []string{"minimum", "enum"}, keywords)
+
+		require.NoError(t, NewMetaValidator().Validate(sch))
+	})
+
+	t.Run("should refuse invalid $data references", func(t *testing.T) {
+		sch := Make(WithDollarData(true))
+		require.ErrorIs(t, sch.UnmarshalJSON([]byte(`{"maximum": {"$data": "/max"}}`)), ErrDollarData)
+	})
+
+	t.Run("should not decode $data references when disabled", func(t *testing.T) {
+		sch := Make()
+		require.Error(t, sch.UnmarshalJSON([]byte(jazon)))
+
+		sch = Make()
+		require.NoError(t, sch.UnmarshalJSON([]byte(`{"minimum": {"$data": "1/min"}}`)))
+		assert.False(t, sch.Validation().HasDataRefs())
+		require.ErrorIs(t, NewMetaValidator().Validate(sch), ErrMetaSchema)
+	})
+}
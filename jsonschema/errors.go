@@ -8,4 +8,28 @@ func (e Error) Error() string {
 
 const (
 	ErrSchema Error = "error in schema"
+
+	// ErrVersion is raised when a keyword is not supported by the version of JSON schema.
+	ErrVersion Error = "keyword not supported by this JSON schema version"
+
+	// ErrRef is raised when a JSON reference cannot be resolved.
+	ErrRef Error = "cannot resolve JSON reference"
+
+	// ErrRefCycle is raised when resolving cyclic JSON references.
+	ErrRefCycle Error = "cyclic JSON reference"
+
+	// ErrMetaSchema is raised when a schema does not validate against its meta-schema.
+	ErrMetaSchema Error = "schema does not validate against its meta-schema"
+
+	// ErrVocabulary is raised when a vocabulary cannot be registered, or when a meta-schema requires an unknown vocabulary.
+	ErrVocabulary Error = "vocabulary error"
+
+	// ErrDollarData is raised when a "$data" reference is invalid.
+	ErrDollarData Error = "invalid $data reference"
+
+	// ErrYAML is raised when a YAML document cannot be converted to JSON.
+	ErrYAML Error = "invalid YAML"
+
+	// ErrLoad is raised when a collection of schemas cannot be loaded.
+	ErrLoad Error = "cannot load schemas"
 )
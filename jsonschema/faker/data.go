@@ -1,26 +1,213 @@
 package faker
 
 import (
+	"errors"
+	"fmt"
 	"iter"
+	"math/rand/v2"
 
 	"github.com/fredbi/core/json"
 	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/validator"
 )
 
 // DataFaker generates random JSON data based on a json schema.
+//
+// Generated data honors types, formats, patterns, numeric bounds, the cardinality of arrays and objects,
+// "enum" and "const", compositions with "allOf", "anyOf", "oneOf" and "if" and follows "$ref" s
+// up to some maximum depth.
+//
+// Every generated value is checked against the schema with a [validator.Validator]: constraints which are not
+// known to the generator, such as a "not" or overlapping branches of a "oneOf", are honored by retrying
+// the generation a few times before giving up with [ErrUnsatisfiable].
+//
+// Keywords which value is a "$data" reference (see [jsonschema.WithDollarData]) depend on the generated data:
+// they are ignored by the generator, but checked like other constraints.
+//
+// Data generation is deterministic for a given seed (see [WithDataSeed]).
 type DataFaker struct {
 	*dataOptions
-	schema jsonschema.Schema
+	schema    jsonschema.Schema
+	g         *generator
+	validator *validator.Validator
 }
 
+// maxAttempts is the number of attempts to generate a value which is valid against the schema.
+const maxAttempts = 20
+
+// NewDataFaker builds a [DataFaker] for a schema.
 func NewDataFaker(schema jsonschema.Schema, opts ...DataOption) DataFaker {
-	return DataFaker{}
+	o := dataOptionsWithDefaults(opts)
+	f := DataFaker{
+		dataOptions: o,
+		schema:      schema,
+		g: &generator{
+			dataOptions: o,
+			rng:         rand.New(rand.NewPCG(uint64(o.seed), 0)), //nolint:gosec // fake data doesn't need a secure random generator
+			root:        node{doc: schema.Document},
+			version:     schema.Version(),
+			dollarData:  schema.UsesDollarData(),
+		},
+	}
+
+	if err := o.resolver.AddDocument("", schema); err != nil {
+		f.g.err = fmt.Errorf("%w: %w", err, ErrDataFaker)
+
+		return f
+	}
+
+	validatorOpts := []validator.Option{validator.WithResolver(o.resolver), validator.WithBaseURI("")}
+	if version := schema.Version(); version != jsonschema.VersionUndefined {
+		validatorOpts = append(validatorOpts, validator.WithVersion(version))
+	}
+	if o.formats != nil {
+		validatorOpts = append(validatorOpts, validator.WithFormats(o.formats))
+	}
+
+	v, err := validator.New(schema, validatorOpts...)
+	if err != nil {
+		f.g.err = fmt.Errorf("%w: %w", err, ErrDataFaker)
+
+		return f
+	}
+	f.validator = v
+
+	return f
 }
 
+// Generate a JSON document.
+//
+// When generation fails, e.g. because the schema cannot be satisfied, [Generated.Err] reports the error.
 func (f DataFaker) Generate() Generated {
-	return Generated{kind: generatedKindData, schema: f.schema, doc: json.Make()} // TODO
+	generated := Generated{kind: generatedKindData, schema: f.schema, doc: json.Make(), valid: true}
+	if f.g.err != nil {
+		generated.err = f.g.err
+
+		return generated
+	}
+
+	wantValid := f.onlyValid || !f.onlyInvalid && f.g.rng.IntN(2) == 0
+
+	var (
+		fallback    json.Document // valid data, when no violation could be produced
+		hasFallback bool
+		lastErr     error
+	)
+
+	for range maxAttempts {
+		s, v, err := f.generateValid()
+		if err != nil {
+			if !errors.Is(err, validator.ErrInvalid) {
+				generated.err = err
+
+				return generated
+			}
+			lastErr = err
+
+			continue
+		}
+
+		if wantValid {
+			generated.doc, generated.err = f.document(v)
+
+			return generated
+		}
+
+		doc, err := f.document(v)
+		if err != nil {
+			generated.err = err
+
+			return generated
+		}
+		fallback, hasFallback = doc, true
+
+		altered, violation, ok := f.g.violate(s, v, "", 0)
+		if !ok {
+			break
+		}
+
+		doc, err = f.document(altered)
+		if err != nil {
+			generated.err = err
+
+			return generated
+		}
+
+		if f.validator.Validate(doc) == nil {
+			// the alteration didn't break the schema, e.g. another branch of an "anyOf" accepts it
+			continue
+		}
+
+		generated.doc = doc
+		generated.valid = false
+		generated.violation = violation
+
+		return generated
+	}
+
+	if hasFallback {
+		generated.doc = fallback
+
+		return generated
+	}
+
+	generated.err = fmt.Errorf("no valid data after %d attempts: %w: %w", maxAttempts, lastErr, ErrUnsatisfiable)
+
+	return generated
+}
+
+// generateValid generates a value, with the shape it was generated from, and checks that it is valid
+// against the schema.
+//
+// An invalid value is reported with an error wrapping [validator.ErrInvalid].
+func (f DataFaker) generateValid() (*shape, value, error) {
+	s, err := f.g.shapeOf(0, f.g.root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v, err := f.g.generate(s, "", 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, err := f.document(v)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := f.validator.Validate(doc); err != nil {
+		if errors.Is(err, validator.ErrInvalid) {
+			return nil, nil, err
+		}
+
+		return nil, nil, fmt.Errorf("%w: %w", err, ErrDataFaker)
+	}
+
+	return s, v, nil
+}
+
+func (f DataFaker) document(v value) (json.Document, error) {
+	data, err := encode(v)
+	if err != nil {
+		return json.Make(), err
+	}
+
+	doc := json.Make()
+	if err := doc.UnmarshalJSON(data); err != nil {
+		return json.Make(), fmt.Errorf("%w: %w", err, ErrDataFaker)
+	}
+
+	return doc, nil
 }
 
+// GenerateMany generates n JSON documents.
 func (f DataFaker) GenerateMany(n int) iter.Seq[Generated] {
-	return nil // TODO
+	return func(yield func(Generated) bool) {
+		for range n {
+			if !yield(f.Generate()) {
+				return
+			}
+		}
+	}
 }
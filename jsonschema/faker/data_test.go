@@ -0,0 +1,465 @@
+package faker
+
+import (
+	stdjson "encoding/json"
+	"math"
+	"net/mail"
+	"net/netip"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/validator"
+	"github.com/fredbi/core/strfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const samples = 50
+
+func TestDataFaker(t *testing.T) {
+	t.Run("should generate deterministic data for a seed", func(t *testing.T) {
+		sch := mustSchema(t, `{
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"tags": {"type": "array", "items": {"type": "string", "format": "uuid"}},
+				"any": {}
+			}
+		}`)
+
+		first := collectData(t, NewDataFaker(sch, WithDataSeed(42)), 5)
+		second := collectData(t, NewDataFaker(sch, WithDataSeed(42)), 5)
+		other := collectData(t, NewDataFaker(sch, WithDataSeed(43)), 5)
+
+		require.Equal(t, first, second)
+		require.NotEqual(t, first, other)
+	})
+
+	t.Run("should honor numeric constraints", func(t *testing.T) {
+		t.Run("with integers", func(t *testing.T) {
+			f := NewDataFaker(mustSchema(t, `{"type": "integer", "minimum": 3, "exclusiveMaximum": 50, "multipleOf": 7}`))
+
+			for _, v := range collectData(t, f, samples) {
+				n, ok := v.(float64)
+				require.True(t, ok)
+				assert.Equal(t, math.Trunc(n), n)
+				assert.GreaterOrEqual(t, n, 7.0)
+				assert.Less(t, n, 50.0)
+				assert.Zero(t, math.Mod(n, 7))
+			}
+		})
+
+		t.Run("with decimal numbers", func(t *testing.T) {
+			f := NewDataFaker(mustSchema(t, `{"type": "number", "exclusiveMinimum": 0.5, "maximum": 2.5, "multipleOf": 0.25}`))
+
+			for _, v := range collectData(t, f, samples) {
+				n, ok := v.(float64)
+				require.True(t, ok)
+				assert.Greater(t, n, 0.5)
+				assert.LessOrEqual(t, n, 2.5)
+				assert.Zero(t, math.Mod(n, 0.25))
+			}
+		})
+
+		t.Run("with draft 4 exclusive bounds", func(t *testing.T) {
+			f := NewDataFaker(mustSchema(t, `{
+				"$schema": "http://json-schema.org/draft-04/schema#",
+				"type": "integer",
+				"minimum": 1,
+				"exclusiveMinimum": true,
+				"maximum": 3
+			}`))
+
+			for _, v := range collectData(t, f, samples) {
+				assert.Contains(t, []any{2.0, 3.0}, v)
+			}
+		})
+	})
+
+	t.Run("should honor string constraints", func(t *testing.T) {
+		t.Run("with a pattern", func(t *testing.T) {
+			const pattern = `^[A-Z]{2}-(\d{3}|x+)$`
+			f := NewDataFaker(mustSchema(t, `{"type": "string", "pattern": "`+jsonEscape(pattern)+`"}`))
+			re := regexp.MustCompile(pattern)
+
+			for _, v := range collectData(t, f, samples) {
+				str, ok := v.(string)
+				require.True(t, ok)
+				assert.Regexp(t, re, str)
+			}
+		})
+
+		t.Run("with lengths", func(t *testing.T) {
+			f := NewDataFaker(mustSchema(t, `{"type": "string", "minLength": 3, "maxLength": 5}`))
+
+			for _, v := range collectData(t, f, samples) {
+				str, ok := v.(string)
+				require.True(t, ok)
+				assert.GreaterOrEqual(t, len(str), 3)
+				assert.LessOrEqual(t, len(str), 5)
+			}
+		})
+
+		t.Run("with formats", func(t *testing.T) {
+			for format, check := range map[string]func(string) error{
+				"date-time": func(s string) error { _, err := time.Parse(time.RFC3339, s); return err },
+				"date":      func(s string) error { _, err := time.Parse(time.DateOnly, s); return err },
+				"email":     func(s string) error { _, err := mail.ParseAddress(s); return err },
+				"ipv4":      func(s string) error { _, err := netip.ParseAddr(s); return err },
+				"ipv6":      func(s string) error { _, err := netip.ParseAddr(s); return err },
+				"regex":     func(s string) error { _, err := regexp.Compile(s); return err },
+			} {
+				t.Run(format, func(t *testing.T) {
+					f := NewDataFaker(mustSchema(t, `{"type": "string", "format": "`+format+`"}`))
+
+					for _, v := range collectData(t, f, samples) {
+						str, ok := v.(string)
+						require.True(t, ok)
+						require.NoError(t, check(str))
+					}
+				})
+			}
+
+			t.Run("uuid", func(t *testing.T) {
+				f := NewDataFaker(mustSchema(t, `{"type": "string", "format": "uuid"}`))
+				re := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+				for _, v := range collectData(t, f, samples) {
+					assert.Regexp(t, re, v)
+				}
+			})
+		})
+
+		t.Run("with a format supported by a registry", func(t *testing.T) {
+			registry := stubRegistry{"uuid", "custom"}
+
+			f := NewDataFaker(mustSchema(t, `{"type": "string", "format": "uuid"}`), WithDataFormats(registry))
+			require.NoError(t, f.Generate().Err())
+
+			f = NewDataFaker(mustSchema(t, `{"type": "string", "format": "custom"}`), WithDataFormats(registry))
+			require.ErrorIs(t, f.Generate().Err(), ErrDataFaker)
+
+			f = NewDataFaker(mustSchema(t, `{"type": "string", "format": "unknown"}`), WithDataFormats(registry))
+			require.NoError(t, f.Generate().Err())
+		})
+	})
+
+	t.Run("should honor enum and const", func(t *testing.T) {
+		f := NewDataFaker(mustSchema(t, `{"enum": ["a", 1, {"b": null}], "type": ["string", "object"]}`))
+		for _, v := range collectData(t, f, samples) {
+			assert.Contains(t, []any{"a", map[string]any{"b": nil}}, v)
+		}
+
+		f = NewDataFaker(mustSchema(t, `{"const": [1, "x"]}`))
+		for _, v := range collectData(t, f, 3) {
+			assert.Equal(t, []any{1.0, "x"}, v)
+		}
+	})
+
+	t.Run("should ignore $data references", func(t *testing.T) {
+		sch := jsonschema.Make(jsonschema.WithDollarData(true))
+		require.NoError(t, sch.UnmarshalJSON([]byte(`{
+			"type": "object",
+			"properties": {
+				"low": {"type": "integer", "maximum": {"$data": "1/high"}, "enum": {"$data": "1/choices"}},
+				"high": {"type": "integer", "minimum": 10}
+			},
+			"required": ["low", "high"]
+		}`)))
+
+		for _, v := range collectData(t, NewDataFaker(sch), samples) {
+			object, ok := v.(map[string]any)
+			require.True(t, ok)
+			assert.Contains(t, object, "low")
+			assert.Contains(t, object, "high")
+		}
+	})
+
+	t.Run("should honor object constraints", func(t *testing.T) {
+		f := NewDataFaker(mustSchema(t, `{
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer", "minimum": 1},
+				"name": {"type": "string"},
+				"email": {"type": "string", "format": "email"}
+			},
+			"required": ["id"],
+			"dependentRequired": {"name": ["email"]},
+			"additionalProperties": false
+		}`))
+
+		for _, v := range collectData(t, f, samples) {
+			obj, ok := v.(map[string]any)
+			require.True(t, ok)
+			require.Contains(t, obj, "id")
+			for key := range obj {
+				assert.Contains(t, []string{"id", "name", "email"}, key)
+			}
+			if _, ok := obj["name"]; ok {
+				assert.Contains(t, obj, "email")
+			}
+		}
+
+		f = NewDataFaker(mustSchema(t, `{
+			"type": "object",
+			"patternProperties": {"^x-": {"type": "boolean"}},
+			"additionalProperties": false,
+			"minProperties": 2,
+			"maxProperties": 3
+		}`))
+
+		for _, v := range collectData(t, f, samples) {
+			obj, ok := v.(map[string]any)
+			require.True(t, ok)
+			assert.GreaterOrEqual(t, len(obj), 2)
+			assert.LessOrEqual(t, len(obj), 3)
+			for key, prop := range obj {
+				assert.Regexp(t, `^x-`, key)
+				assert.IsType(t, true, prop)
+			}
+		}
+	})
+
+	t.Run("should honor array constraints", func(t *testing.T) {
+		f := NewDataFaker(mustSchema(t, `{
+			"type": "array",
+			"items": {"type": "integer", "minimum": 0, "maximum": 3},
+			"minItems": 4,
+			"uniqueItems": true
+		}`))
+
+		for _, v := range collectData(t, f, samples) {
+			elems, ok := v.([]any)
+			require.True(t, ok)
+			require.Len(t, elems, 4)
+			assert.ElementsMatch(t, []any{0.0, 1.0, 2.0, 3.0}, elems)
+		}
+
+		f = NewDataFaker(mustSchema(t, `{
+			"type": "array",
+			"prefixItems": [{"const": "first"}],
+			"items": false,
+			"minItems": 1
+		}`))
+
+		for _, v := range collectData(t, f, samples) {
+			assert.Equal(t, []any{"first"}, v)
+		}
+	})
+
+	t.Run("should honor compositions", func(t *testing.T) {
+		f := NewDataFaker(mustSchema(t, `{
+			"allOf": [{"type": "string"}, {"minLength": 2}],
+			"anyOf": [{"maxLength": 2}, {"pattern": "^a+$"}]
+		}`))
+
+		for _, v := range collectData(t, f, samples) {
+			str, ok := v.(string)
+			require.True(t, ok)
+			require.GreaterOrEqual(t, len(str), 2)
+			assert.True(t, len(str) == 2 || regexp.MustCompile(`^a+$`).MatchString(str))
+		}
+	})
+
+	t.Run("should limit the depth of recursive schemas", func(t *testing.T) {
+		f := NewDataFaker(mustSchema(t, `{
+			"$ref": "#/$defs/node",
+			"$defs": {
+				"node": {
+					"type": "object",
+					"required": ["children"],
+					"properties": {
+						"value": {"type": "integer"},
+						"children": {"type": "array", "items": {"$ref": "#/$defs/node"}}
+					},
+					"additionalProperties": false
+				}
+			}
+		}`), WithDataMaxDepth(2), WithDataComplexity(ComplexityHigh))
+
+		for _, v := range collectData(t, f, samples) {
+			assert.LessOrEqual(t, depthOf(v), 2*(2+1))
+		}
+	})
+
+	t.Run("should fail on unsatisfiable schemas", func(t *testing.T) {
+		for _, jazon := range []string{
+			`{"allOf": [{"type": "string"}, {"type": "integer"}]}`,
+			`{"type": "integer", "minimum": 1, "maximum": 2, "multipleOf": 5}`,
+			`{"type": "string", "minLength": 3, "maxLength": 2}`,
+			`{"properties": {"a": false}, "required": ["a"]}`,
+			`{"$ref": "#/$defs/a", "$defs": {"a": {"required": ["a"], "properties": {"a": {"$ref": "#/$defs/a"}}}}}`,
+			`{"type": "integer", "not": {"type": "integer"}}`,
+			`{"oneOf": [{"type": "string"}, {"type": "string"}]}`,
+		} {
+			t.Run(jazon, func(t *testing.T) {
+				generated := NewDataFaker(mustSchema(t, jazon)).Generate()
+				require.ErrorIs(t, generated.Err(), ErrUnsatisfiable)
+			})
+		}
+	})
+
+	t.Run("should honor not and exclusive oneOf branches", func(t *testing.T) {
+		for _, jazon := range []string{
+			`{"type": "integer", "minimum": 0, "maximum": 20, "not": {"multipleOf": 3}}`,
+			`{"oneOf": [{"type": "integer", "minimum": 0, "maximum": 10}, {"type": "integer", "minimum": 5, "maximum": 15}]}`,
+			`{"type": "object", "properties": {"a": {"type": "string", "not": {"maxLength": 3}}}, "required": ["a"]}`,
+		} {
+			t.Run(jazon, func(t *testing.T) {
+				sch := mustSchema(t, jazon)
+				v, err := validator.New(sch)
+				require.NoError(t, err)
+
+				const seeds = 200
+				for seed := range int64(seeds) {
+					generated := NewDataFaker(sch, WithDataSeed(seed), WithDataOnlyValid(false)).Generate()
+					require.NoError(t, generated.Err())
+
+					err := v.Validate(generated.Document())
+					if generated.ShouldBeValid() {
+						require.NoErrorf(t, err, "seed %d: %s", seed, generated.Document().String())
+					} else {
+						require.ErrorIsf(t, err, validator.ErrInvalid, "seed %d: %s", seed, generated.Document().String())
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("should report values which cannot be encoded", func(t *testing.T) {
+		_, err := encode([]value{int64(1), struct{}{}})
+		require.ErrorIs(t, err, ErrDataFaker)
+
+		_, err = encode(&object{keys: []string{"a"}, values: []value{math.Inf(1)}})
+		require.ErrorIs(t, err, ErrDataFaker)
+
+		assert.Empty(t, kindOf(struct{}{}))
+	})
+
+	t.Run("should generate invalid data", func(t *testing.T) {
+		t.Run("with a violation at the root", func(t *testing.T) {
+			f := NewDataFaker(mustSchema(t, `{"type": "integer", "minimum": 10}`), WithDataOnlyInvalid(true, DistorsionLow))
+
+			for generated := range f.GenerateMany(samples) {
+				require.NoError(t, generated.Err())
+				require.False(t, generated.ShouldBeValid())
+				require.Contains(t, []string{"type", "minimum"}, generated.Violation())
+
+				v := decode(t, generated)
+				n, isNumber := v.(float64)
+				assert.True(t, !isNumber || n != math.Trunc(n) || n < 10)
+			}
+		})
+
+		t.Run("with nested violations", func(t *testing.T) {
+			f := NewDataFaker(mustSchema(t, `{
+				"type": "object",
+				"properties": {"items": {"type": "array", "items": {"type": "string", "maxLength": 3}}},
+				"required": ["items"]
+			}`), WithDataOnlyInvalid(true, DistorsionHigh))
+
+			for generated := range f.GenerateMany(samples) {
+				require.NoError(t, generated.Err())
+				require.False(t, generated.ShouldBeValid())
+				require.NotEmpty(t, generated.Violation())
+			}
+		})
+
+		t.Run("with a schema that accepts anything", func(t *testing.T) {
+			generated := NewDataFaker(mustSchema(t, `{}`), WithDataOnlyInvalid(true, DistorsionLow)).Generate()
+			require.NoError(t, generated.Err())
+			require.True(t, generated.ShouldBeValid())
+			require.Empty(t, generated.Violation())
+		})
+
+		t.Run("with valid and invalid data", func(t *testing.T) {
+			f := NewDataFaker(mustSchema(t, `{"type": "string"}`), WithDataOnlyValid(false))
+
+			var valid, invalid int
+			for generated := range f.GenerateMany(samples) {
+				if generated.ShouldBeValid() {
+					valid++
+					assert.IsType(t, "", decode(t, generated))
+				} else {
+					invalid++
+				}
+			}
+
+			assert.Positive(t, valid)
+			assert.Positive(t, invalid)
+		})
+	})
+}
+
+func collectData(t *testing.T, f DataFaker, n int) []any {
+	t.Helper()
+
+	values := make([]any, 0, n)
+	for generated := range f.GenerateMany(n) {
+		require.NoError(t, generated.Err())
+		require.True(t, generated.ShouldBeValid())
+		values = append(values, decode(t, generated))
+	}
+
+	return values
+}
+
+func decode(t *testing.T, generated Generated) any {
+	t.Helper()
+
+	data, err := generated.Document().MarshalJSON()
+	require.NoError(t, err)
+
+	var v any
+	require.NoError(t, stdjson.Unmarshal(data, &v))
+
+	return v
+}
+
+func depthOf(v any) int {
+	switch tv := v.(type) {
+	case []any:
+		depth := 0
+		for _, elem := range tv {
+			depth = max(depth, depthOf(elem))
+		}
+
+		return 1 + depth
+	case map[string]any:
+		depth := 0
+		for _, prop := range tv {
+			depth = max(depth, depthOf(prop))
+		}
+
+		return 1 + depth
+	default:
+		return 0
+	}
+}
+
+func jsonEscape(s string) string {
+	data, _ := stdjson.Marshal(s)
+
+	return string(data[1 : len(data)-1])
+}
+
+func mustSchema(t *testing.T, jazon string) jsonschema.Schema {
+	t.Helper()
+
+	sch := jsonschema.Make()
+	require.NoError(t, sch.UnmarshalJSON([]byte(jazon)))
+
+	return sch
+}
+
+// stubRegistry supports some formats and validates any string.
+type stubRegistry []string
+
+func (r stubRegistry) Parse(string, string) (strfmt.Format, error) { return nil, nil }
+
+func (r stubRegistry) Validate(string, string) error { return nil }
+
+func (r stubRegistry) SupportedFormats() []string { return slices.Clone(r) }
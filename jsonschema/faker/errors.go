@@ -0,0 +1,18 @@
+package faker
+
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrDataFaker is raised when no data can be generated from a schema.
+	ErrDataFaker Error = "cannot generate data"
+
+	// ErrSchemaFaker is raised when no schema can be generated.
+	ErrSchemaFaker Error = "cannot generate schema"
+
+	// ErrUnsatisfiable is raised when the constraints of a schema cannot be satisfied.
+	ErrUnsatisfiable Error = "unsatisfiable schema"
+)
@@ -0,0 +1,31 @@
+package faker
+
+import "testing"
+
+// SeedCorpus adds n schemas generated by a [SchemaFaker] to the seed corpus of a fuzz test, as JSON bytes.
+//
+// The fuzz target must take a single []byte argument, e.g.:
+//
+//	func FuzzMyAnalyzer(f *testing.F) {
+//		faker.SeedCorpus(f, 100, faker.WithSchemaVersion(jsonschema.VersionDraft7))
+//
+//		f.Fuzz(func(t *testing.T, schema []byte) {
+//			// ...
+//		})
+//	}
+func SeedCorpus(f *testing.F, n int, opts ...SchemaOption) {
+	f.Helper()
+
+	for generated := range NewSchemaFaker(opts...).GenerateMany(n) {
+		if err := generated.Err(); err != nil {
+			f.Fatalf("generating the seed corpus: %v", err)
+		}
+
+		data, err := generated.Document().MarshalJSON()
+		if err != nil {
+			f.Fatalf("generating the seed corpus: %v", err)
+		}
+
+		f.Add(data)
+	}
+}
@@ -7,16 +7,29 @@ import (
 
 // Generated outcome of the [SchemaFaker] or the [DataFaker]
 type Generated struct {
-	kind   generatedKind
-	schema jsonschema.Schema
-	doc    json.Document
-	valid  bool
+	kind      generatedKind
+	schema    jsonschema.Schema
+	doc       json.Document
+	valid     bool
+	violation string
+	err       error
 }
 
+// ShouldBeValid tells if the generated data should be valid against its schema.
 func (g Generated) ShouldBeValid() bool {
 	return g.valid
 }
 
+// Violation describes the constraint violated by invalid generated data, e.g. "/items/0: minLength".
+func (g Generated) Violation() string {
+	return g.violation
+}
+
+// Err is the error that occurred during the generation, if any.
+func (g Generated) Err() error {
+	return g.err
+}
+
 func (g Generated) Schema() jsonschema.Schema {
 	return g.schema
 }
@@ -0,0 +1,607 @@
+package faker
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/stubs"
+)
+
+// generator generates data from the shapes of a schema.
+type generator struct {
+	*dataOptions
+
+	rng        *rand.Rand
+	root       node
+	version    jsonschema.Version
+	dollarData bool  // keywords with a "$data" reference are not known statically
+	err        error // initialization error
+}
+
+// sizes of generated values, when unconstrained.
+type sizes struct {
+	items    int // extra items
+	length   int // extra characters
+	extra    int // additional properties
+	repeat   int // repetitions in patterns
+	anyLevel int // nesting of values of any type
+}
+
+func (g *generator) sizes() sizes {
+	switch g.complexity {
+	case ComplexityLow:
+		return sizes{items: 2, length: 8, extra: 1, repeat: 2, anyLevel: 1}
+	case ComplexityHigh:
+		return sizes{items: 8, length: 32, extra: 4, repeat: 8, anyLevel: 3}
+	default:
+		return sizes{items: 4, length: 16, extra: 2, repeat: 4, anyLevel: 2}
+	}
+}
+
+// hardDepth is the number of references beyond which data generation gives up.
+func (g *generator) hardDepth() int {
+	return 4*g.maxDepth + 8
+}
+
+func (g *generator) shapeOf(depth int, nodes ...node) (*shape, error) {
+	s := newShape(depth)
+	for _, n := range nodes {
+		if err := g.collect(s, n); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// minimal tells if a shape is beyond the maximum depth: only required content is then generated.
+func (g *generator) minimal(s *shape) bool {
+	return s.depth > g.maxDepth
+}
+
+func (g *generator) generate(s *shape, pointer string, level int) (value, error) {
+	if s.unsatisfiable != "" {
+		return nil, fmt.Errorf("at %q: %s: %w", pointer, s.unsatisfiable, ErrUnsatisfiable)
+	}
+
+	if s.hasConst {
+		if s.types != nil && !typeAllowed(s.types, kindOfRaw(s.constValue)) {
+			return nil, fmt.Errorf("at %q: const does not satisfy type: %w", pointer, ErrUnsatisfiable)
+		}
+
+		return s.constValue, nil
+	}
+
+	if s.hasEnum {
+		values := s.enum
+		if s.types != nil {
+			values = slices.DeleteFunc(slices.Clone(values), func(raw rawValue) bool {
+				return !typeAllowed(s.types, kindOfRaw(raw))
+			})
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("at %q: no enum value satisfies type: %w", pointer, ErrUnsatisfiable)
+		}
+
+		return values[g.rng.IntN(len(values))], nil
+	}
+
+	switch g.pickType(s, level) {
+	case typeNull:
+		return nil, nil
+	case typeBoolean:
+		if g.baseFaker != nil {
+			return g.baseFaker.Bool(g.fakerContext()), nil
+		}
+
+		return g.rng.IntN(2) == 0, nil
+	case typeInteger:
+		return g.number(s, pointer, true)
+	case typeNumber:
+		return g.number(s, pointer, false)
+	case typeString:
+		return g.str(s, pointer)
+	case typeArray:
+		return g.array(s, pointer, level)
+	default:
+		return g.object(s, pointer, level)
+	}
+}
+
+// pickType picks the type of the generated value, among the types allowed by the shape or inferred from its keywords.
+func (g *generator) pickType(s *shape, level int) string {
+	candidates := s.types
+	if candidates == nil {
+		candidates = s.inferredTypes()
+	}
+
+	if candidates == nil {
+		candidates = allTypes
+		if level >= g.sizes().anyLevel || g.minimal(s) {
+			candidates = allTypes[:5] // scalars only
+		}
+	}
+
+	return candidates[g.rng.IntN(len(candidates))]
+}
+
+func (s *shape) inferredTypes() []string {
+	var types []string
+
+	if len(s.properties) > 0 || len(s.required) > 0 || len(s.patternProperties) > 0 || len(s.additional) > 0 ||
+		s.noAdditional || s.minProperties > 0 || s.maxProperties >= 0 || len(s.propertyNames) > 0 {
+		types = append(types, typeObject)
+	}
+
+	if len(s.prefixItems) > 0 || len(s.items) > 0 || s.noMoreItems || s.minItems > 0 || s.maxItems >= 0 ||
+		s.uniqueItems || len(s.contains) > 0 {
+		types = append(types, typeArray)
+	}
+
+	if s.minLength > 0 || s.maxLength >= 0 || len(s.patterns) > 0 || s.format != "" {
+		types = append(types, typeString)
+	}
+
+	if s.minimum.defined || s.maximum.defined || len(s.multipleOf) > 0 {
+		types = append(types, typeNumber)
+	}
+
+	return types
+}
+
+func (g *generator) fakerContext() stubs.FakerContext {
+	return stubs.FakerContext{Rand: g.rng}
+}
+
+// number generates a number within the bounds of the shape, and a multiple of all its "multipleOf".
+func (g *generator) number(s *shape, pointer string, integer bool) (value, error) {
+	const span = 1000
+
+	lo, hi := math.Inf(-1), math.Inf(1)
+	if s.minimum.defined {
+		lo = s.minimum.value
+	}
+	if s.maximum.defined {
+		hi = s.maximum.value
+	}
+
+	switch {
+	case math.IsInf(lo, -1) && math.IsInf(hi, 1):
+		lo, hi = -span, span
+	case math.IsInf(lo, -1):
+		lo = hi - span
+	case math.IsInf(hi, 1):
+		hi = lo + span
+	}
+
+	inBounds := func(v float64) bool {
+		return s.allows(v) && (!integer || v == math.Trunc(v))
+	}
+
+	if g.baseFaker != nil && len(s.multipleOf) == 0 {
+		if v, err := strconv.ParseFloat(string(g.baseFaker.Number(g.fakerContext())), 64); err == nil && inBounds(v) {
+			if integer {
+				return int64(v), nil
+			}
+
+			return v, nil
+		}
+	}
+
+	const attempts = 20
+
+	for range attempts {
+		v, ok := g.pickNumber(s, lo, hi, integer)
+		if !ok {
+			break
+		}
+
+		if inBounds(v) {
+			if integer {
+				return int64(v), nil
+			}
+
+			return v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("at %q: no number satisfies all numeric constraints: %w", pointer, ErrUnsatisfiable)
+}
+
+func (g *generator) pickNumber(s *shape, lo, hi float64, integer bool) (float64, bool) {
+	if len(s.multipleOf) == 0 {
+		if integer {
+			kmin, kmax := math.Ceil(lo), math.Floor(hi)
+			if kmin > kmax {
+				return 0, false
+			}
+
+			return kmin + float64(g.rng.Int64N(int64(kmax-kmin)+1)), true
+		}
+
+		v := lo + g.rng.Float64()*(hi-lo)
+		if rounded := math.Round(v*100) / 100; s.allows(rounded) {
+			return rounded, true
+		}
+
+		return v, true
+	}
+
+	step := s.multipleOf[g.rng.IntN(len(s.multipleOf))]
+	if integer && step != math.Trunc(step) {
+		step = integralMultiple(step)
+	}
+
+	kmin, kmax := math.Ceil(lo/step), math.Floor(hi/step)
+	if kmin > kmax {
+		return 0, false
+	}
+
+	k := kmin + float64(g.rng.Int64N(int64(kmax-kmin)+1))
+
+	return roundTo(k*step, step), true
+}
+
+// allows tells if a number satisfies the bounds and the "multipleOf" of the shape.
+func (s *shape) allows(v float64) bool {
+	if s.minimum.defined && (v < s.minimum.value || s.minimum.exclusive && v == s.minimum.value) {
+		return false
+	}
+
+	if s.maximum.defined && (v > s.maximum.value || s.maximum.exclusive && v == s.maximum.value) {
+		return false
+	}
+
+	for _, m := range s.multipleOf {
+		if !isMultiple(v, m) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isMultiple(v, m float64) bool {
+	const epsilon = 1e-9
+	q := v / m
+
+	return math.Abs(q-math.Round(q)) < epsilon
+}
+
+// integralMultiple returns the smallest integral multiple of a step.
+func integralMultiple(step float64) float64 {
+	const maxFactor = 1000
+
+	for k := 1.0; k <= maxFactor; k++ {
+		if v := roundTo(k*step, step); v == math.Trunc(v) {
+			return v
+		}
+	}
+
+	return math.Ceil(step)
+}
+
+// roundTo rounds a value to the number of decimals of a step, to avoid floating point representation noise.
+func roundTo(v, step float64) float64 {
+	decimals := 0
+	if repr := strconv.FormatFloat(step, 'f', -1, 64); slices.Contains([]byte(repr), '.') {
+		decimals = len(repr) - 1 - slices.Index([]byte(repr), '.')
+	}
+
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(v, 'f', decimals, 64), 64)
+	if err != nil {
+		return v
+	}
+
+	return rounded
+}
+
+// array generates an array satisfying the item constraints of the shape.
+func (g *generator) array(s *shape, pointer string, level int) (value, error) {
+	minItems, maxItems := s.minItems, s.maxItems
+	if s.noMoreItems && (maxItems < 0 || maxItems > len(s.prefixItems)) {
+		maxItems = len(s.prefixItems)
+	}
+
+	containsCount := 0
+	if len(s.contains) > 0 {
+		containsCount = max(s.minContains, 1)
+		if s.minContains == 0 {
+			containsCount = 0
+		}
+		minItems = max(minItems, containsCount)
+	}
+
+	n := minItems
+	if !g.minimal(s) {
+		upper := minItems + g.sizes().items
+		if maxItems >= 0 {
+			upper = min(upper, maxItems)
+		}
+		if upper > minItems {
+			n += g.rng.IntN(upper - minItems + 1)
+		}
+	}
+
+	if maxItems >= 0 && n > maxItems {
+		return nil, fmt.Errorf("at %q: the number of items cannot satisfy all array constraints: %w", pointer, ErrUnsatisfiable)
+	}
+
+	elems := make([]value, 0, n)
+	seen := make(map[string]struct{}, n)
+
+	for i := range n {
+		nodes := s.itemNodes(i)
+		if i >= n-containsCount {
+			nodes = append(nodes, s.contains...)
+		}
+
+		elem, err := g.element(s, nodes, fmt.Sprintf("%s/%d", pointer, i), level, seen)
+		if err != nil {
+			if len(elems) >= minItems && s.uniqueItems {
+				break
+			}
+
+			return nil, err
+		}
+
+		elems = append(elems, elem)
+	}
+
+	return elems, nil
+}
+
+// element generates an array element, retrying to get unique items.
+func (g *generator) element(s *shape, nodes []node, pointer string, level int, seen map[string]struct{}) (value, error) {
+	const attempts = 64
+
+	child, err := g.shapeOf(s.depth, nodes...)
+	if err != nil {
+		return nil, err
+	}
+
+	for range attempts {
+		elem, err := g.generate(child, pointer, level+1)
+		if err != nil {
+			return nil, err
+		}
+
+		if !s.uniqueItems {
+			return elem, nil
+		}
+
+		data, err := encode(elem)
+		if err != nil {
+			return nil, err
+		}
+
+		key := string(data)
+		if _, duplicate := seen[key]; !duplicate {
+			seen[key] = struct{}{}
+
+			return elem, nil
+		}
+	}
+
+	return nil, fmt.Errorf("at %q: cannot generate unique items: %w", pointer, ErrUnsatisfiable)
+}
+
+// itemNodes are the schemas that apply to the item at index i.
+func (s *shape) itemNodes(i int) []node {
+	if i < len(s.prefixItems) {
+		return slices.Clone(s.prefixItems[i])
+	}
+
+	return slices.Clone(s.items)
+}
+
+// object generates an object satisfying the property constraints of the shape.
+func (g *generator) object(s *shape, pointer string, level int) (value, error) {
+	obj := &object{}
+	names := slices.Clone(s.required)
+
+	if !g.minimal(s) {
+		for _, name := range s.properties {
+			_, hasDependents := s.dependentSchemas[name]
+			if slices.Contains(names, name) || hasDependents || g.rng.IntN(2) == 0 {
+				continue
+			}
+
+			if !s.fits(names, name) {
+				continue
+			}
+
+			names = append(names, name)
+		}
+	}
+
+	names, err := g.mergeDependents(s, names)
+	if err != nil {
+		return nil, err
+	}
+
+	minProperties := s.minProperties
+	if !g.minimal(s) && !s.noAdditional {
+		minProperties = max(minProperties, len(names)+g.rng.IntN(g.sizes().extra+1))
+	}
+	if s.maxProperties >= 0 {
+		minProperties = min(minProperties, s.maxProperties)
+	}
+
+	for len(names) < minProperties {
+		name, err := g.propertyName(s, names, pointer)
+		if err != nil {
+			if len(names) >= s.minProperties {
+				break
+			}
+
+			return nil, err
+		}
+		if names, err = g.mergeDependents(s, append(names, name)); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.maxProperties >= 0 && len(names) > s.maxProperties {
+		return nil, fmt.Errorf("at %q: the number of properties cannot satisfy all object constraints: %w", pointer, ErrUnsatisfiable)
+	}
+
+	for _, name := range names {
+		nodes, ok := s.propertyNodes(name)
+		if !ok {
+			return nil, fmt.Errorf("at %q: property %q is not allowed: %w", pointer, name, ErrUnsatisfiable)
+		}
+
+		child, err := g.shapeOf(s.depth, nodes...)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := g.generate(child, pointer+"/"+json.EscapeToken(name), level+1)
+		if err != nil {
+			return nil, err
+		}
+
+		obj.set(name, v)
+	}
+
+	return obj, nil
+}
+
+// mergeDependents adds the properties required by the presence of other properties,
+// and merges the schemas that apply to the object when these properties are present.
+func (g *generator) mergeDependents(s *shape, names []string) ([]string, error) {
+	merged := make(map[string]struct{}, len(names))
+
+	for {
+		names = s.withDependents(names)
+		progress := false
+
+		for _, name := range names {
+			if _, done := merged[name]; done {
+				continue
+			}
+			merged[name] = struct{}{}
+
+			for _, n := range s.dependentSchemas[name] {
+				if err := g.collect(s, n); err != nil {
+					return nil, err
+				}
+				progress = true
+			}
+		}
+
+		for _, name := range s.required {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+				progress = true
+			}
+		}
+
+		if !progress {
+			return names, nil
+		}
+	}
+}
+
+// withDependents adds the properties required by the presence of other properties.
+func (s *shape) withDependents(names []string) []string {
+	for i := 0; i < len(names); i++ {
+		for _, dependent := range s.dependentRequired[names[i]] {
+			if !slices.Contains(names, dependent) {
+				names = append(names, dependent)
+			}
+		}
+	}
+
+	return names
+}
+
+// fits tells if a property may be added to some properties: with the properties they require, they stay within
+// "maxProperties".
+func (s *shape) fits(names []string, name string) bool {
+	return s.maxProperties < 0 || len(s.withDependents(append(slices.Clone(names), name))) <= s.maxProperties
+}
+
+// propertyNodes are the schemas that apply to a property. It returns false if the property is not allowed.
+func (s *shape) propertyNodes(name string) ([]node, bool) {
+	nodes := slices.Clone(s.propertySchemas[name])
+	_, declared := s.propertySchemas[name]
+
+	for _, pp := range s.patternProperties {
+		if matches(pp.pattern, name) {
+			nodes = append(nodes, pp.node)
+			declared = true
+		}
+	}
+
+	if declared {
+		return nodes, true
+	}
+
+	if s.noAdditional {
+		return nil, false
+	}
+
+	return append(nodes, s.additional...), true
+}
+
+// propertyName generates the name of an additional property.
+func (g *generator) propertyName(s *shape, names []string, pointer string) (string, error) {
+	const attempts = 10
+
+	for _, name := range s.properties {
+		if slices.Contains(names, name) {
+			continue
+		}
+		if _, hasDependents := s.dependentSchemas[name]; hasDependents {
+			continue
+		}
+		if !s.fits(names, name) {
+			continue
+		}
+
+		return name, nil
+	}
+
+	nameShape, err := g.shapeOf(s.depth, s.propertyNames...)
+	if err != nil {
+		return "", err
+	}
+	nameShape.types = []string{typeString}
+	nameShape.minLength = max(nameShape.minLength, 1)
+
+	for range attempts {
+		var name string
+
+		if s.noAdditional || len(s.propertyNames) == 0 && len(s.patternProperties) > 0 && g.rng.IntN(2) == 0 {
+			if len(s.patternProperties) == 0 {
+				break
+			}
+
+			pp := s.patternProperties[g.rng.IntN(len(s.patternProperties))]
+			name, err = g.fromPattern(pp.pattern)
+			if suffixed := name + g.word(); err == nil && slices.Contains(names, name) && matches(pp.pattern, suffixed) {
+				name = suffixed
+			}
+		} else {
+			var v value
+			v, err = g.str(nameShape, pointer)
+			name, _ = v.(string)
+		}
+		if err != nil {
+			continue
+		}
+
+		if _, allowed := s.propertyNodes(name); allowed && !slices.Contains(names, name) {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("at %q: cannot generate property names: %w", pointer, ErrUnsatisfiable)
+}
@@ -0,0 +1,380 @@
+package faker
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/json"
+)
+
+// violation alters a valid value so it violates a keyword.
+type violation struct {
+	keyword string
+	alter   func() (value, bool)
+}
+
+// violate alters a valid value generated for a shape, so that it becomes invalid.
+//
+// It returns the altered value and a description of the violated constraints,
+// or false when no constraint may be reliably violated.
+func (g *generator) violate(s *shape, v value, pointer string, level int) (value, string, bool) {
+	if s.alternatives || s.unsatisfiable != "" {
+		// a violation of the branch chosen among alternatives may satisfy another branch
+		return v, "", false
+	}
+
+	own := g.ownViolations(s, v)
+	g.rng.Shuffle(len(own), func(i, j int) { own[i], own[j] = own[j], own[i] })
+
+	var (
+		descriptions []string
+		violated     bool
+	)
+
+	for _, candidate := range own {
+		if altered, ok := candidate.alter(); ok {
+			v = altered
+			descriptions = append(descriptions, describe(pointer, candidate.keyword))
+			violated = true
+
+			break
+		}
+	}
+
+	nested := !violated ||
+		g.garbling == DistorsionHigh ||
+		g.garbling == DistorsionMedium && g.rng.IntN(2) == 0
+	if !nested {
+		return v, strings.Join(descriptions, ", "), true
+	}
+
+	altered, description, ok := g.violateNested(s, v, pointer, level)
+	if !ok {
+		return v, strings.Join(descriptions, ", "), violated
+	}
+
+	if g.garbling != DistorsionHigh {
+		// a single violation, either own or nested
+		descriptions = descriptions[:0]
+	}
+
+	return altered, strings.Join(append(descriptions, description), ", "), true
+}
+
+func describe(pointer, keyword string) string {
+	if pointer == "" {
+		return keyword
+	}
+
+	return pointer + ": " + keyword
+}
+
+// ownViolations lists the constraints of the shape which may be violated by altering a value.
+func (g *generator) ownViolations(s *shape, v value) []violation {
+	var candidates []violation
+
+	if s.types != nil {
+		candidates = append(candidates, violation{keyword: "type", alter: func() (value, bool) {
+			return pickRaw(func(raw rawValue) bool { return !typeAllowed(s.types, kindOfRaw(raw)) })
+		}})
+	}
+
+	if s.hasEnum {
+		candidates = append(candidates, violation{keyword: "enum", alter: func() (value, bool) {
+			return pickRaw(func(raw rawValue) bool {
+				return !slices.ContainsFunc(s.enum, func(e rawValue) bool { return string(e) == string(raw) })
+			})
+		}})
+	}
+
+	if s.hasConst {
+		candidates = append(candidates, violation{keyword: "const", alter: func() (value, bool) {
+			return pickRaw(func(raw rawValue) bool { return string(raw) != string(s.constValue) })
+		}})
+	}
+
+	switch kindOf(v) {
+	case typeInteger, typeNumber:
+		candidates = append(candidates, s.numberViolations()...)
+	case typeString:
+		candidates = append(candidates, g.stringViolations(s)...)
+	case typeArray:
+		if elems, ok := v.([]value); ok {
+			candidates = append(candidates, s.arrayViolations(elems)...)
+		}
+	case typeObject:
+		if obj, ok := v.(*object); ok {
+			candidates = append(candidates, g.objectViolations(s, obj)...)
+		}
+	}
+
+	return candidates
+}
+
+// invalidValues are candidate values for violations of "type", "enum" and "const".
+var invalidValues = []rawValue{
+	rawValue(`null`),
+	rawValue(`true`),
+	rawValue(`-1`),
+	rawValue(`0.5`),
+	rawValue(`"\u0000invalid"`),
+	rawValue(`[]`),
+	rawValue(`{}`),
+}
+
+func pickRaw(invalid func(rawValue) bool) (value, bool) {
+	for _, raw := range invalidValues {
+		if invalid(raw) {
+			return raw, true
+		}
+	}
+
+	return nil, false
+}
+
+func typeAllowed(types []string, kind string) bool {
+	return slices.Contains(types, kind) || kind == typeInteger && slices.Contains(types, typeNumber)
+}
+
+func (s *shape) numberViolations() []violation {
+	integer := slices.Contains(s.types, typeInteger) && !slices.Contains(s.types, typeNumber)
+	numeric := func(x float64) value {
+		if integer || x == math.Trunc(x) {
+			return int64(x)
+		}
+
+		return x
+	}
+
+	var candidates []violation
+
+	if s.minimum.defined {
+		candidates = append(candidates, violation{keyword: "minimum", alter: func() (value, bool) {
+			x := s.minimum.value
+			if !s.minimum.exclusive {
+				x--
+			}
+
+			return numeric(math.Floor(x)), true
+		}})
+	}
+
+	if s.maximum.defined {
+		candidates = append(candidates, violation{keyword: "maximum", alter: func() (value, bool) {
+			x := s.maximum.value
+			if !s.maximum.exclusive {
+				x++
+			}
+
+			return numeric(math.Ceil(x)), true
+		}})
+	}
+
+	if len(s.multipleOf) > 0 {
+		candidates = append(candidates, violation{keyword: "multipleOf", alter: func() (value, bool) {
+			m := s.multipleOf[0]
+			x := roundTo(1.5*m, m/2)
+			if integer {
+				x = math.Floor(m) + 1
+			}
+
+			return numeric(x), !isMultiple(x, m)
+		}})
+	}
+
+	return candidates
+}
+
+func (g *generator) stringViolations(s *shape) []violation {
+	var candidates []violation
+
+	if s.minLength > 0 {
+		candidates = append(candidates, violation{keyword: "minLength", alter: func() (value, bool) {
+			return g.letters(s.minLength-1, s.minLength-1), true
+		}})
+	}
+
+	if s.maxLength >= 0 {
+		candidates = append(candidates, violation{keyword: "maxLength", alter: func() (value, bool) {
+			return g.letters(s.maxLength+1, s.maxLength+1), true
+		}})
+	}
+
+	for _, pattern := range s.patterns {
+		candidates = append(candidates, violation{keyword: "pattern", alter: func() (value, bool) {
+			for _, str := range []string{"", "~", "!?", "0", "A", g.word()} {
+				if !matches(pattern, str) {
+					return str, true
+				}
+			}
+
+			return nil, false
+		}})
+	}
+
+	return candidates
+}
+
+func (s *shape) arrayViolations(elems []value) []violation {
+	var candidates []violation
+
+	if s.minItems > 0 && len(elems) >= s.minItems {
+		candidates = append(candidates, violation{keyword: "minItems", alter: func() (value, bool) {
+			return slices.Clone(elems[:s.minItems-1]), true
+		}})
+	}
+
+	if s.maxItems >= 0 {
+		candidates = append(candidates, violation{keyword: "maxItems", alter: func() (value, bool) {
+			altered := slices.Clone(elems)
+			for len(altered) <= s.maxItems {
+				var filler value
+				if len(altered) > 0 {
+					filler = altered[len(altered)-1]
+				}
+				altered = append(altered, filler)
+			}
+
+			return altered, true
+		}})
+	}
+
+	if s.uniqueItems && len(elems) > 0 && (s.maxItems < 0 || len(elems) < s.maxItems) {
+		candidates = append(candidates, violation{keyword: "uniqueItems", alter: func() (value, bool) {
+			return append(slices.Clone(elems), elems[0]), true
+		}})
+	}
+
+	return candidates
+}
+
+func (g *generator) objectViolations(s *shape, obj *object) []violation {
+	var candidates []violation
+
+	for _, name := range s.required {
+		if !obj.has(name) {
+			continue
+		}
+
+		candidates = append(candidates, violation{keyword: "required", alter: func() (value, bool) {
+			altered := obj.clone()
+			altered.remove(name)
+
+			return altered, true
+		}})
+
+		break
+	}
+
+	if s.noAdditional {
+		candidates = append(candidates, violation{keyword: "additionalProperties", alter: func() (value, bool) {
+			name, ok := g.undeclaredName(s, obj)
+			if !ok {
+				return nil, false
+			}
+
+			altered := obj.clone()
+			altered.set(name, nil)
+
+			return altered, true
+		}})
+	}
+
+	if s.minProperties > 0 && len(obj.keys) >= s.minProperties {
+		candidates = append(candidates, violation{keyword: "minProperties", alter: func() (value, bool) {
+			altered := obj.clone()
+			for len(altered.keys) >= s.minProperties {
+				altered.remove(altered.keys[len(altered.keys)-1])
+			}
+
+			return altered, true
+		}})
+	}
+
+	if s.maxProperties >= 0 {
+		candidates = append(candidates, violation{keyword: "maxProperties", alter: func() (value, bool) {
+			altered := obj.clone()
+			for len(altered.keys) <= s.maxProperties {
+				name, ok := g.undeclaredName(s, altered)
+				if !ok {
+					return nil, false
+				}
+				altered.set(name, nil)
+			}
+
+			return altered, true
+		}})
+	}
+
+	return candidates
+}
+
+// undeclaredName finds a property name which is neither declared by the shape nor already used.
+func (g *generator) undeclaredName(s *shape, obj *object) (string, bool) {
+	const attempts = 10
+
+	for i := range attempts {
+		name := "undeclared" + strconv.Itoa(i)
+		if i > 0 && i%2 == 0 {
+			name = g.word()
+		}
+
+		if _, declared := s.propertySchemas[name]; declared || obj.has(name) {
+			continue
+		}
+
+		if slices.ContainsFunc(s.patternProperties, func(pp patternNode) bool { return matches(pp.pattern, name) }) {
+			continue
+		}
+
+		return name, true
+	}
+
+	return "", false
+}
+
+// violateNested alters an item or a property of a valid array or object.
+func (g *generator) violateNested(s *shape, v value, pointer string, level int) (value, string, bool) {
+	switch tv := v.(type) {
+	case []value:
+		for _, i := range g.rng.Perm(len(tv)) {
+			child, err := g.shapeOf(s.depth, s.itemNodes(i)...)
+			if err != nil {
+				continue
+			}
+
+			elem, description, ok := g.violate(child, tv[i], pointer+"/"+strconv.Itoa(i), level+1)
+			if !ok {
+				continue
+			}
+
+			altered := slices.Clone(tv)
+			altered[i] = elem
+
+			return altered, description, true
+		}
+	case *object:
+		for _, i := range g.rng.Perm(len(tv.keys)) {
+			name := tv.keys[i]
+			nodes, _ := s.propertyNodes(name)
+			child, err := g.shapeOf(s.depth, nodes...)
+			if err != nil {
+				continue
+			}
+
+			prop, description, ok := g.violate(child, tv.values[i], pointer+"/"+json.EscapeToken(name), level+1)
+			if !ok {
+				continue
+			}
+
+			altered := tv.clone()
+			altered.values[i] = prop
+
+			return altered, description, true
+		}
+	}
+
+	return v, "", false
+}
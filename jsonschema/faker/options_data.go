@@ -1,46 +1,119 @@
 package faker
 
 import (
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/strfmt/registries"
 	"github.com/fredbi/core/stubs"
 )
 
+// DataOption customizes the behavior of the [DataFaker].
 type DataOption func(*dataOptions)
 
 type dataOptions struct {
 	commonOptions
 
-	allowInvalidJSON bool
+	maxDepth int
+	formats  registries.Registry
+	resolver *jsonschema.Resolver
 }
 
+func dataOptionsWithDefaults(opts []DataOption) *dataOptions {
+	o := &dataOptions{
+		commonOptions: commonOptions{
+			onlyValid:  true,
+			garbling:   DistorsionLow,
+			complexity: ComplexityMedium,
+		},
+		maxDepth: defaultMaxDepth,
+	}
+
+	for _, apply := range opts {
+		apply(o)
+	}
+
+	if o.resolver == nil {
+		o.resolver = jsonschema.NewResolver()
+	}
+
+	return o
+}
+
+const defaultMaxDepth = 4
+
+// WithDataSeed sets the seed of the random generator, so the generated data is deterministic.
 func WithDataSeed(seed int64) DataOption {
 	return func(o *dataOptions) {
 		o.seed = seed
 	}
 }
 
-func WithDataBaseFaker(faker stubs.Faker) SchemaOption {
-	return func(o *schemaOptions) {
+// WithDataBaseFaker provides a [stubs.Faker] to generate strings, numbers and booleans which are not
+// constrained by a format or a pattern.
+//
+// Values which do not satisfy the constraints of the schema are discarded.
+func WithDataBaseFaker(faker stubs.Faker) DataOption {
+	return func(o *dataOptions) {
 		o.baseFaker = faker
 	}
 }
 
+// WithDataOnlyValid generates only data which is valid against the schema. This is the default.
+//
+// Disabling this option generates valid and invalid data at random.
 func WithDataOnlyValid(enabled bool) DataOption {
 	return func(o *dataOptions) {
+		o.onlyValid = enabled
+		if enabled {
+			o.onlyInvalid = false
+		}
 	}
 }
 
+// WithDataOnlyInvalid generates only data which is invalid against the schema, for negative testing.
+//
+// Generated data is always valid JSON, which violates some constraint of the schema.
+//
+// Schemas that accept any value cannot produce invalid data: [Generated.ShouldBeValid] tells whether
+// a constraint could be violated.
 func WithDataOnlyInvalid(enabled bool, garbling DistorsionLevel) DataOption {
 	return func(o *dataOptions) {
+		o.onlyInvalid = enabled
+		if enabled {
+			o.onlyValid = false
+			o.garbling = garbling
+		}
 	}
 }
 
-func WithDataInvalidJSON(enabled bool, garbling DistorsionLevel) DataOption {
+// WithDataComplexity tells how large the generated arrays, objects and strings are, when the schema
+// doesn't constrain their size. The default is [ComplexityMedium].
+func WithDataComplexity(c ComplexityLevel) DataOption {
 	return func(o *dataOptions) {
-		o.allowInvalidJSON = enabled
+		o.complexity = c
 	}
 }
 
-func WithDataComplexity(c ComplexityLevel) DataOption {
+// WithDataMaxDepth limits the depth of the recursion through "$ref" s.
+//
+// Beyond this depth, only required properties and the minimum number of items are generated.
+func WithDataMaxDepth(depth int) DataOption {
+	return func(o *dataOptions) {
+		o.maxDepth = depth
+	}
+}
+
+// WithDataFormats checks generated strings with a registry of string formats.
+//
+// Formats supported by the registry are always honored: generation fails for formats with no known generator.
+func WithDataFormats(registry registries.Registry) DataOption {
+	return func(o *dataOptions) {
+		o.formats = registry
+	}
+}
+
+// WithDataResolver sets the [jsonschema.Resolver] used to resolve "$ref" s, e.g. to resolve remote references offline.
+func WithDataResolver(resolver *jsonschema.Resolver) DataOption {
 	return func(o *dataOptions) {
+		o.resolver = resolver
 	}
 }
@@ -5,11 +5,30 @@ import (
 	"github.com/fredbi/core/stubs"
 )
 
+// SchemaOption customizes the behavior of the [SchemaFaker].
 type SchemaOption func(*schemaOptions)
 
+// DistorsionLevel tells how much invalid generated data is distorted.
 type DistorsionLevel uint8
+
+const (
+	// DistorsionLow violates a single constraint, as close as possible to the root.
+	DistorsionLow DistorsionLevel = iota + 1
+	// DistorsionMedium violates a single constraint, possibly in nested values.
+	DistorsionMedium
+	// DistorsionHigh violates constraints at several levels of nesting.
+	DistorsionHigh
+)
+
+// ComplexityLevel tells how large generated items are.
 type ComplexityLevel uint8
 
+const (
+	ComplexityLow ComplexityLevel = iota + 1
+	ComplexityMedium
+	ComplexityHigh
+)
+
 type commonOptions struct {
 	seed        int64
 	onlyValid   bool
@@ -21,81 +40,211 @@ type commonOptions struct {
 
 type schemaOptions struct {
 	commonOptions
+
+	version            jsonschema.Version
+	maxDepth           int
+	compositionDensity float64
+
+	useAllOf                 bool
+	useAnyOf                 bool
+	useOneOf                 bool
+	useAdditionalItems       bool
+	useAdditionalProperties  bool
+	usePattern               bool
+	usePatternProperties     bool
+	useUnevaluatedProperties bool
+	useRef                   bool
+	useRefCycles             bool
+	useEnum                  bool
+	useExtensions            bool
+}
+
+func schemaOptionsWithDefaults(opts []SchemaOption) *schemaOptions {
+	o := &schemaOptions{
+		commonOptions: commonOptions{
+			onlyValid:  true,
+			garbling:   DistorsionLow,
+			complexity: ComplexityMedium,
+		},
+		version:                  jsonschema.VersionDraft2020,
+		compositionDensity:       -1,
+		useAllOf:                 true,
+		useAnyOf:                 true,
+		useOneOf:                 true,
+		useAdditionalItems:       true,
+		useAdditionalProperties:  true,
+		usePattern:               true,
+		usePatternProperties:     true,
+		useUnevaluatedProperties: true,
+		useRef:                   true,
+		useEnum:                  true,
+		useExtensions:            true,
+	}
+
+	for _, apply := range opts {
+		apply(o)
+	}
+
+	return o
 }
 
+// WithSchemaSeed sets the seed of the random generator, so the generated schemas are deterministic.
 func WithSchemaSeed(seed int64) SchemaOption {
 	return func(o *schemaOptions) {
 		o.seed = seed
 	}
 }
 
+// WithSchemaBaseFaker provides a [stubs.Faker] to generate titles and descriptions.
 func WithSchemaBaseFaker(faker stubs.Faker) SchemaOption {
 	return func(o *schemaOptions) {
 		o.baseFaker = faker
 	}
 }
 
+// WithSchemaOnlyValid generates only schemas which are valid against the meta-schema of their version.
+// This is the default.
+//
+// Disabling this option generates valid and invalid schemas at random.
 func WithSchemaOnlyValid(enabled bool) SchemaOption {
 	return func(o *schemaOptions) {
+		o.onlyValid = enabled
+		if enabled {
+			o.onlyInvalid = false
+		}
 	}
 }
 
+// WithSchemaOnlyInvalid generates only schemas which are invalid against the meta-schema of their version,
+// e.g. with a negative "minLength" or an unknown "type".
+//
+// Invalid schemas are not decoded: only [Generated.Document] is relevant.
 func WithSchemaOnlyInvalid(enabled bool, garbling DistorsionLevel) SchemaOption {
 	return func(o *schemaOptions) {
+		o.onlyInvalid = enabled
+		if enabled {
+			o.onlyValid = false
+			o.garbling = garbling
+		}
 	}
 }
 
+// WithSchemaComplexity tells how large and deep the generated schemas are. The default is [ComplexityMedium].
 func WithSchemaComplexity(c ComplexityLevel) SchemaOption {
 	return func(o *schemaOptions) {
+		o.complexity = c
 	}
 }
 
+// WithSchemaVersion sets the version of JSON schema of the generated schemas. The default is [jsonschema.VersionDraft2020].
+//
+// Generated schemas declare their version with "$schema" and only use keywords supported by this version.
 func WithSchemaVersion(c jsonschema.Version) SchemaOption {
 	return func(o *schemaOptions) {
+		o.version = c
+	}
+}
+
+// WithSchemaMaxDepth limits the nesting of subschemas. The default depends on the complexity.
+func WithSchemaMaxDepth(depth int) SchemaOption {
+	return func(o *schemaOptions) {
+		o.maxDepth = depth
+	}
+}
+
+// WithSchemaCompositionDensity sets the probability, between 0 and 1, for a subschema to be a composition
+// with "allOf", "anyOf", "oneOf", "not" or "if". The default depends on the complexity.
+func WithSchemaCompositionDensity(density float64) SchemaOption {
+	return func(o *schemaOptions) {
+		o.compositionDensity = min(max(density, 0), 1)
 	}
 }
 
+// UseAllOf enables "allOf" in generated schemas. This is the default.
 func UseAllOf(enabled bool) SchemaOption {
 	return func(o *schemaOptions) {
+		o.useAllOf = enabled
 	}
 }
 
+// UseAnyOf enables "anyOf" in generated schemas. This is the default.
 func UseAnyOf(enabled bool) SchemaOption {
 	return func(o *schemaOptions) {
+		o.useAnyOf = enabled
 	}
 }
 
+// UseOneOf enables "oneOf" in generated schemas. This is the default.
 func UseOneOf(enabled bool) SchemaOption {
 	return func(o *schemaOptions) {
+		o.useOneOf = enabled
 	}
 }
 
+// UseAdditionalItems enables constraints on the items beyond tuples, i.e. "additionalItems"
+// (or "items" after "prefixItems" for draft 2020). This is the default.
 func UseAdditionalItems(enabled bool) SchemaOption {
 	return func(o *schemaOptions) {
+		o.useAdditionalItems = enabled
 	}
 }
 
+// UseAdditionalProperties enables "additionalProperties" in generated schemas. This is the default.
 func UseAdditionalProperties(enabled bool) SchemaOption {
 	return func(o *schemaOptions) {
+		o.useAdditionalProperties = enabled
 	}
 }
 
+// UsePattern enables "pattern" in generated schemas. This is the default.
 func UsePattern(enabled bool) SchemaOption {
 	return func(o *schemaOptions) {
+		o.usePattern = enabled
 	}
 }
 
+// UsePatternProperties enables "patternProperties" in generated schemas. This is the default.
 func UsePatternProperties(enabled bool) SchemaOption {
 	return func(o *schemaOptions) {
+		o.usePatternProperties = enabled
 	}
 }
 
+// UseUnevaluatedProperties enables "unevaluatedProperties" in generated schemas (>= draft 2019).
+// This is the default.
 func UseUnevaluatedProperties(enabled bool) SchemaOption {
 	return func(o *schemaOptions) {
+		o.useUnevaluatedProperties = enabled
+	}
+}
+
+// UseRef enables definitions ("$defs" or "definitions") referred to by "$ref" in generated schemas.
+// This is the default.
+func UseRef(enabled bool) SchemaOption {
+	return func(o *schemaOptions) {
+		o.useRef = enabled
+	}
+}
+
+// UseRefCycles allows definitions to refer to themselves or to one another in cycles.
+//
+// This is disabled by default: definitions only refer to the definitions declared after them.
+func UseRefCycles(enabled bool) SchemaOption {
+	return func(o *schemaOptions) {
+		o.useRefCycles = enabled
+	}
+}
+
+// UseEnum enables "enum" and "const" in generated schemas. This is the default.
+func UseEnum(enabled bool) SchemaOption {
+	return func(o *schemaOptions) {
+		o.useEnum = enabled
 	}
 }
 
-func UseRef(enabled bool /* TODO: other $ref-specific schemaOptions*/) SchemaOption {
+// UseExtensions enables extensions, i.e. "x-" keywords, in generated schemas. This is the default.
+func UseExtensions(enabled bool) SchemaOption {
 	return func(o *schemaOptions) {
+		o.useExtensions = enabled
 	}
 }
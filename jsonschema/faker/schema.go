@@ -2,23 +2,79 @@ package faker
 
 import (
 	"iter"
+	"math/rand/v2"
 
 	"github.com/fredbi/core/jsonschema"
 )
 
 // SchemaFaker generates random JSON schemas.
+//
+// Generated schemas are valid against the meta-schema of the chosen version of JSON schema (see [WithSchemaVersion]),
+// and use only the keywords supported by this version.
+//
+// The size, depth, density of compositions, use of "$ref" (possibly cyclic), enums and extensions may be tuned with
+// [SchemaOption] s.
+//
+// Schema generation is deterministic for a given seed (see [WithSchemaSeed]).
 type SchemaFaker struct {
 	*schemaOptions
+	g *schemaGenerator
 }
 
+// NewSchemaFaker builds a [SchemaFaker].
 func NewSchemaFaker(opts ...SchemaOption) SchemaFaker {
-	return SchemaFaker{}
+	o := schemaOptionsWithDefaults(opts)
+
+	return SchemaFaker{
+		schemaOptions: o,
+		g: &schemaGenerator{
+			schemaOptions: o,
+			rng:           rand.New(rand.NewPCG(uint64(o.seed), 0)), //nolint:gosec // fake schemas don't need a secure random generator
+		},
+	}
 }
 
+// Generate a JSON schema.
+//
+// Invalid schemas (see [WithSchemaOnlyInvalid]) are not decoded: [Generated.Schema] only holds their JSON document.
 func (f SchemaFaker) Generate() Generated {
-	return Generated{kind: generatedKindSchema, schema: jsonschema.Make()} // TODO
+	generated := Generated{kind: generatedKindSchema, schema: jsonschema.Make(), valid: true}
+
+	wantValid := f.onlyValid || !f.onlyInvalid && f.g.rng.IntN(2) == 0
+
+	sch, err := f.g.root()
+	if err != nil {
+		generated.err = err
+
+		return generated
+	}
+	generated.schema = sch
+
+	if wantValid {
+		return generated
+	}
+
+	doc, violation, err := f.g.violate(sch)
+	if err != nil {
+		generated.err = err
+
+		return generated
+	}
+
+	generated.schema = jsonschema.Schema{Document: doc}
+	generated.valid = false
+	generated.violation = violation
+
+	return generated
 }
 
+// GenerateMany generates n JSON schemas.
 func (f SchemaFaker) GenerateMany(n int) iter.Seq[Generated] {
-	return nil // TODO
+	return func(yield func(Generated) bool) {
+		for range n {
+			if !yield(f.Generate()) {
+				return
+			}
+		}
+	}
 }
@@ -0,0 +1,670 @@
+package faker
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/stubs"
+)
+
+// schemaGenerator generates random schemas with a [jsonschema.Builder].
+type schemaGenerator struct {
+	*schemaOptions
+
+	rng  *rand.Rand
+	defs []string // names of the definitions of the schema being generated
+	err  error
+}
+
+// schemaSizes of generated schemas, by complexity.
+type schemaSizes struct {
+	depth      int
+	properties int
+	defs       int
+	branches   int
+	enum       int
+	density    float64
+}
+
+func (g *schemaGenerator) sizes() schemaSizes {
+	var sz schemaSizes
+
+	switch g.complexity {
+	case ComplexityLow:
+		sz = schemaSizes{depth: 2, properties: 2, defs: 1, branches: 2, enum: 2, density: 0.1}
+	case ComplexityHigh:
+		sz = schemaSizes{depth: 5, properties: 6, defs: 4, branches: 4, enum: 6, density: 0.3}
+	default:
+		sz = schemaSizes{depth: 3, properties: 4, defs: 2, branches: 3, enum: 4, density: 0.2}
+	}
+
+	if g.maxDepth > 0 {
+		sz.depth = g.maxDepth
+	}
+	if g.compositionDensity >= 0 {
+		sz.density = g.compositionDensity
+	}
+
+	return sz
+}
+
+// supportedVersions are the versions of JSON schema supported by the [SchemaFaker].
+var supportedVersions = []jsonschema.Version{
+	jsonschema.VersionDraft4,
+	jsonschema.VersionDraft6,
+	jsonschema.VersionDraft7,
+	jsonschema.VersionDraft2019,
+	jsonschema.VersionDraft2020,
+}
+
+// schemaTypes are the types of generated schemas, and scalarSchemaTypes the types of leaf schemas.
+var (
+	schemaTypes = []jsonschema.SchemaType{
+		jsonschema.SchemaTypeNull,
+		jsonschema.SchemaTypeObject,
+		jsonschema.SchemaTypeArray,
+		jsonschema.SchemaTypeString,
+		jsonschema.SchemaTypeNumber,
+		jsonschema.SchemaTypeInteger,
+		jsonschema.SchemaTypeBool,
+	}
+	scalarSchemaTypes = []jsonschema.SchemaType{
+		jsonschema.SchemaTypeString,
+		jsonschema.SchemaTypeNumber,
+		jsonschema.SchemaTypeInteger,
+		jsonschema.SchemaTypeBool,
+		jsonschema.SchemaTypeNull,
+	}
+)
+
+// overlaps tells if some values have both types.
+func overlaps(t, u jsonschema.SchemaType) bool {
+	isNumeric := func(t jsonschema.SchemaType) bool {
+		return t == jsonschema.SchemaTypeNumber || t == jsonschema.SchemaTypeInteger
+	}
+
+	return t == u || isNumeric(t) && isNumeric(u)
+}
+
+func (g *schemaGenerator) atLeast(v jsonschema.Version) bool {
+	return !g.version.Less(v)
+}
+
+func (g *schemaGenerator) builder() *jsonschema.Builder {
+	return jsonschema.NewBuilder(jsonschema.WithVersion(g.version))
+}
+
+// check records the first error of the builders.
+func (g *schemaGenerator) check(b *jsonschema.Builder) jsonschema.Schema {
+	if err := b.Err(); err != nil && g.err == nil {
+		g.err = err
+	}
+
+	return b.Schema()
+}
+
+// chance returns true with probability p.
+func (g *schemaGenerator) chance(p float64) bool {
+	return g.rng.Float64() < p
+}
+
+// root generates a schema with definitions, declaring its version.
+func (g *schemaGenerator) root() (jsonschema.Schema, error) {
+	g.err = nil
+	g.defs = nil
+
+	if !slices.Contains(supportedVersions, g.version) {
+		return jsonschema.Schema{}, fmt.Errorf("unsupported version %v: %w", g.version, ErrSchemaFaker)
+	}
+
+	sz := g.sizes()
+	if g.useRef {
+		for i := range g.rng.IntN(sz.defs + 1) {
+			g.defs = append(g.defs, "def"+strconv.Itoa(i))
+		}
+	}
+
+	b := g.builder().WithSchemaVersion(g.version)
+	for i, name := range g.defs {
+		minRef := i + 1
+		if g.useRefCycles {
+			minRef = 0
+		}
+
+		b.WithDef(name, g.schema(1, minRef))
+	}
+
+	g.fill(b, 0, 0, []jsonschema.SchemaType{jsonschema.SchemaTypeObject})
+	sch := g.check(b)
+
+	if g.err != nil {
+		return jsonschema.Schema{}, fmt.Errorf("%w: %w", g.err, ErrSchemaFaker)
+	}
+
+	return sch, nil
+}
+
+// schema generates a subschema at some depth. It may only refer to the definitions from minRef onwards.
+//
+// Generated subschemas are satisfiable: the false schema is only generated by closed.
+func (g *schemaGenerator) schema(depth, minRef int) jsonschema.Schema {
+	if g.atLeast(jsonschema.VersionDraft6) && g.chance(0.05) {
+		return g.check(g.builder().Bool(true))
+	}
+
+	return g.check(g.fill(g.builder(), depth, minRef, nil))
+}
+
+// typed generates a subschema with one of some types.
+func (g *schemaGenerator) typed(depth, minRef int, types []jsonschema.SchemaType) jsonschema.Schema {
+	return g.check(g.fill(g.builder(), depth, minRef, types))
+}
+
+// loose generates a subschema which only constrains the type, to be combined with other constraints on the same
+// values without contradicting them.
+func (g *schemaGenerator) loose(t jsonschema.SchemaType) jsonschema.Schema {
+	b := g.builder().WithType(t)
+	g.annotate(b)
+
+	return g.check(b)
+}
+
+// fill adds random keywords to a schema, with one of some types (any type when types is nil).
+func (g *schemaGenerator) fill(b *jsonschema.Builder, depth, minRef int, types []jsonschema.SchemaType) *jsonschema.Builder {
+	sz := g.sizes()
+	leaf := depth >= sz.depth
+
+	// the types of definitions are not known: references are only generated when types are not constrained
+	if types == nil && minRef < len(g.defs) && g.chance(0.15) {
+		b.WithRef(g.refTo(g.defs[minRef+g.rng.IntN(len(g.defs)-minRef)]))
+		if g.atLeast(jsonschema.VersionDraft2019) && g.chance(0.3) {
+			b.WithDescription(g.text())
+		}
+
+		return b
+	}
+
+	if types == nil {
+		types = schemaTypes
+		if leaf {
+			types = scalarSchemaTypes
+		}
+	}
+
+	if !leaf && g.chance(sz.density) {
+		// full branches are one level deeper: objects and arrays could exceed the maximum depth
+		nested := types
+		if depth+1 >= sz.depth {
+			nested = slices.DeleteFunc(slices.Clone(types), func(t jsonschema.SchemaType) bool {
+				return !slices.Contains(scalarSchemaTypes, t)
+			})
+		}
+
+		if len(nested) > 0 && g.chance(0.5) {
+			g.composition(b, depth, minRef, nested, false)
+
+			return b
+		}
+
+		types = []jsonschema.SchemaType{types[g.rng.IntN(len(types))]}
+		g.composition(b, depth, minRef, types, true)
+	}
+
+	t := types[g.rng.IntN(len(types))]
+	b.WithType(t)
+
+	isScalar := t != jsonschema.SchemaTypeObject && t != jsonschema.SchemaTypeArray
+	switch {
+	case g.useEnum && isScalar && g.chance(0.15):
+		// other constraints could exclude the values of the enum
+		g.enum(b, t)
+	case t == jsonschema.SchemaTypeObject:
+		g.object(b, depth, minRef)
+	case t == jsonschema.SchemaTypeArray:
+		g.array(b, depth, minRef)
+	case t == jsonschema.SchemaTypeString:
+		g.str(b)
+	case t == jsonschema.SchemaTypeNumber, t == jsonschema.SchemaTypeInteger:
+		g.number(b, t == jsonschema.SchemaTypeInteger)
+	}
+
+	g.annotate(b)
+
+	return b
+}
+
+// composition adds a composition keyword, which branches have some of the types of the schema.
+//
+// When the schema has other constraints (typed), the branches only constrain the type, so they can't
+// contradict the schema. Otherwise, one "allOf" member and all alternatives are full schemas.
+func (g *schemaGenerator) composition(b *jsonschema.Builder, depth, minRef int, types []jsonschema.SchemaType, typed bool) {
+	var kinds []string
+	if g.useAllOf {
+		kinds = append(kinds, "allOf")
+	}
+	if g.useAnyOf {
+		kinds = append(kinds, "anyOf")
+	}
+	if g.useOneOf {
+		kinds = append(kinds, "oneOf")
+	}
+	kinds = append(kinds, "not")
+	if g.atLeast(jsonschema.VersionDraft7) {
+		kinds = append(kinds, "if")
+	}
+
+	branch := func(full bool, t jsonschema.SchemaType) jsonschema.Schema {
+		if full && !typed {
+			return g.typed(depth+1, minRef, []jsonschema.SchemaType{t})
+		}
+
+		return g.loose(t)
+	}
+	n := 1 + g.rng.IntN(g.sizes().branches)
+
+	switch kinds[g.rng.IntN(len(kinds))] {
+	case "allOf":
+		// members share the same type
+		t := types[g.rng.IntN(len(types))]
+		members := make([]jsonschema.Schema, 0, n)
+		for i := range n {
+			members = append(members, branch(i == 0, t))
+		}
+		b.WithAllOf(members...)
+	case "anyOf":
+		alternatives := make([]jsonschema.Schema, 0, n)
+		for range n {
+			alternatives = append(alternatives, branch(true, types[g.rng.IntN(len(types))]))
+		}
+		b.WithAnyOf(alternatives...)
+	case "oneOf":
+		// exclusive alternatives have types which don't overlap
+		var alternatives []jsonschema.Schema
+		var used []jsonschema.SchemaType
+		for _, i := range g.rng.Perm(len(types)) {
+			if len(alternatives) == n || slices.ContainsFunc(used, func(u jsonschema.SchemaType) bool {
+				return overlaps(u, types[i])
+			}) {
+				continue
+			}
+
+			used = append(used, types[i])
+			alternatives = append(alternatives, branch(true, types[i]))
+		}
+		b.WithOneOf(alternatives...)
+	case "not":
+		// the negated schema has a type which doesn't overlap the type of the schema
+		types = g.withType(b, types, typed)
+
+		var others []jsonschema.SchemaType
+		for _, t := range schemaTypes {
+			if !slices.ContainsFunc(types, func(u jsonschema.SchemaType) bool { return overlaps(t, u) }) {
+				others = append(others, t)
+			}
+		}
+		if len(others) == 0 {
+			others = []jsonschema.SchemaType{types[g.rng.IntN(len(types))]}
+		}
+		b.WithNot(g.loose(others[g.rng.IntN(len(others))]))
+	default:
+		// values which satisfy "if" satisfy "then"
+		types = g.withType(b, types, typed)
+		t := types[0]
+		b.WithIf(branch(true, t)).WithThen(g.loose(t))
+		if g.chance(0.5) {
+			b.WithElse(g.loose(types[g.rng.IntN(len(types))]))
+		}
+	}
+}
+
+// withType adds one of some types to a schema which has no type yet: "not" and "if" don't constrain types.
+func (g *schemaGenerator) withType(b *jsonschema.Builder, types []jsonschema.SchemaType, typed bool) []jsonschema.SchemaType {
+	if typed {
+		return types
+	}
+
+	t := types[g.rng.IntN(len(types))]
+	b.WithType(t)
+
+	return []jsonschema.SchemaType{t}
+}
+
+func (g *schemaGenerator) object(b *jsonschema.Builder, depth, minRef int) {
+	sz := g.sizes()
+
+	var names []string
+	required := 0
+	for range 1 + g.rng.IntN(sz.properties) {
+		name := g.word()
+		if slices.Contains(names, name) {
+			continue
+		}
+		names = append(names, name)
+
+		if g.chance(0.5) {
+			b.WithRequiredProperty(name, g.schema(depth+1, minRef))
+			required++
+		} else {
+			b.WithProperty(name, g.schema(depth+1, minRef))
+		}
+	}
+
+	if g.usePatternProperties && g.chance(0.2) {
+		b.WithPatternProperty("^x-", g.schema(depth+1, minRef))
+	}
+
+	if g.useAdditionalProperties && g.chance(0.3) {
+		sch, _ := g.closed(depth, minRef)
+		b.WithAdditionalProperties(sch)
+	}
+
+	if g.useUnevaluatedProperties && g.atLeast(jsonschema.VersionDraft2019) && g.chance(0.1) {
+		sch, _ := g.closed(depth, minRef)
+		b.WithUnevaluatedProperties(sch)
+	}
+
+	if g.atLeast(jsonschema.VersionDraft6) && g.chance(0.1) {
+		nameSchema := g.builder().WithType(jsonschema.SchemaTypeString)
+		if g.usePattern {
+			nameSchema.WithPattern("^[a-z]+$")
+		} else {
+			nameSchema.WithMaxLength(sz.properties * 4)
+		}
+		b.WithPropertyNames(g.check(nameSchema))
+	}
+
+	dependent := 0
+	if g.atLeast(jsonschema.VersionDraft2019) && len(names) > 1 && g.chance(0.1) {
+		b.WithDependentRequired(names[0], names[1])
+		dependent = 1
+	}
+
+	if g.chance(0.2) {
+		// properties may be closed: "maxProperties" allows the required properties, and a property they depend on
+		minProperties := g.rng.IntN(len(names) + 1)
+		maxProperties := max(minProperties, required) + dependent
+		b.WithMinProperties(minProperties).WithMaxProperties(maxProperties + g.rng.IntN(sz.properties+1))
+	}
+}
+
+// closed generates a schema for "additionalProperties", "unevaluatedProperties" or additional items,
+// often the false schema. It tells if it generated the false schema.
+func (g *schemaGenerator) closed(depth, minRef int) (jsonschema.Schema, bool) {
+	if g.atLeast(jsonschema.VersionDraft6) && g.chance(0.5) {
+		return g.check(g.builder().Bool(false)), true
+	}
+
+	return g.schema(depth+1, minRef), false
+}
+
+func (g *schemaGenerator) array(b *jsonschema.Builder, depth, minRef int) {
+	sz := g.sizes()
+	maxLength := -1 // the maximum number of items allowed by closed tuples
+
+	switch {
+	case g.atLeast(jsonschema.VersionDraft6) && g.chance(0.1):
+		// items all have the type of "contains", which is more constrained
+		t := scalarSchemaTypes[g.rng.IntN(len(scalarSchemaTypes))]
+		b.WithItems(g.loose(t)).WithContains(g.typed(depth+1, minRef, []jsonschema.SchemaType{t}))
+
+		if g.atLeast(jsonschema.VersionDraft2019) && g.chance(0.5) {
+			// items may all be valid against "contains"
+			maxContains := 1 + g.rng.IntN(sz.properties)
+			b.WithMinContains(1).WithMaxContains(maxContains).WithMaxItems(maxContains)
+		}
+
+		return
+	case g.chance(0.2) && g.atLeast(jsonschema.VersionDraft2020):
+		prefix := make([]jsonschema.Schema, 0, sz.branches)
+		for range 1 + g.rng.IntN(sz.branches) {
+			prefix = append(prefix, g.schema(depth+1, minRef))
+		}
+		b.WithPrefixItems(prefix...)
+
+		if g.useAdditionalItems && g.chance(0.5) {
+			sch, isFalse := g.closed(depth, minRef)
+			if isFalse {
+				maxLength = len(prefix)
+			}
+			b.WithItems(sch)
+		}
+	case g.chance(0.2) && !g.atLeast(jsonschema.VersionDraft2020):
+		// tuples before draft 2020, with "items" as an array
+		items := make([]value, 0, sz.branches)
+		for range 1 + g.rng.IntN(sz.branches) {
+			items = append(items, g.raw(g.schema(depth+1, minRef)))
+		}
+
+		tuple := &object{}
+		tuple.set("items", items)
+		if g.useAdditionalItems && g.chance(0.5) {
+			sch, isFalse := g.closed(depth, minRef)
+			if isFalse {
+				maxLength = len(items)
+			}
+			tuple.set("additionalItems", g.raw(sch))
+		}
+
+		b.AtPointerMerge(json.EmptyPointer, g.document(g.encode(tuple)))
+	default:
+		b.WithItems(g.schema(depth+1, minRef))
+	}
+
+	// items may have a single possible value, e.g. null: unique items allow at most one of them
+	unique := g.chance(0.2)
+	if unique {
+		b.WithUniqueItems(true)
+	}
+
+	if g.chance(0.3) {
+		minItems := g.rng.IntN(sz.properties)
+		if unique {
+			minItems = min(minItems, 1)
+		}
+		if maxLength >= 0 {
+			minItems = min(minItems, maxLength)
+		}
+		b.WithMinItems(minItems).WithMaxItems(minItems + g.rng.IntN(sz.properties+1))
+	}
+}
+
+// patterns used by generated schemas.
+var schemaPatterns = []string{
+	"^[a-z]+$",
+	"^[A-Z][a-z]*$",
+	"^[0-9]{3}-[0-9]{4}$",
+	"^(foo|bar)[0-9]*$",
+	"[a-f0-9]{8}",
+}
+
+// formatsByVersion are the formats defined by each version of JSON schema.
+var formatsByVersion = map[jsonschema.Version][]string{
+	jsonschema.VersionDraft4:    {"date-time", "email", "hostname", "ipv4", "ipv6", "uri"},
+	jsonschema.VersionDraft6:    {"uri-reference", "uri-template", "json-pointer"},
+	jsonschema.VersionDraft7:    {"date", "time", "idn-email", "idn-hostname", "iri", "iri-reference", "relative-json-pointer", "regex"},
+	jsonschema.VersionDraft2019: {"duration", "uuid"},
+}
+
+func (g *schemaGenerator) formats() []string {
+	var formats []string
+	for _, v := range supportedVersions {
+		if g.atLeast(v) {
+			formats = append(formats, formatsByVersion[v]...)
+		}
+	}
+
+	return formats
+}
+
+func (g *schemaGenerator) str(b *jsonschema.Builder) {
+	sz := g.sizes()
+
+	// lengths could exclude the values of patterns and formats
+	switch {
+	case g.usePattern && g.chance(0.25):
+		b.WithPattern(schemaPatterns[g.rng.IntN(len(schemaPatterns))])
+	case g.chance(0.25):
+		formats := g.formats()
+		b.WithFormat(formats[g.rng.IntN(len(formats))])
+	case g.chance(0.4):
+		minLength := g.rng.IntN(sz.properties)
+		b.WithMinLength(minLength).WithMaxLength(minLength + 1 + g.rng.IntN(4*sz.properties))
+	}
+}
+
+func (g *schemaGenerator) number(b *jsonschema.Builder, integer bool) {
+	const (
+		span     = 1000
+		minRange = 10 // holds multiples of any generated "multipleOf"
+	)
+
+	if g.chance(0.4) {
+		minimum := g.rng.IntN(2*span) - span
+		maximum := minimum + minRange + g.rng.IntN(span)
+
+		if g.atLeast(jsonschema.VersionDraft6) && g.chance(0.3) {
+			b.WithExclusiveMinimum(minimum).WithExclusiveMaximum(maximum + 1)
+		} else {
+			b.WithMinimum(minimum).WithMaximum(maximum)
+		}
+	}
+
+	if g.chance(0.2) {
+		if integer {
+			b.WithMultipleOf(1 + g.rng.IntN(5))
+		} else {
+			b.WithMultipleOf([]float64{0.1, 0.25, 0.5, 2}[g.rng.IntN(4)])
+		}
+	}
+}
+
+// enum adds "enum" (or "const") values of a simple type.
+func (g *schemaGenerator) enum(b *jsonschema.Builder, t jsonschema.SchemaType) {
+	n := 1 + g.rng.IntN(g.sizes().enum)
+	if t == jsonschema.SchemaTypeNull || t == jsonschema.SchemaTypeBool {
+		n = 1
+	}
+
+	seen := make(map[string]struct{}, n)
+	values := make([]json.Document, 0, n)
+	for range n {
+		var v value
+		switch t {
+		case jsonschema.SchemaTypeString:
+			v = g.word()
+		case jsonschema.SchemaTypeInteger:
+			v = int64(g.rng.IntN(100))
+		case jsonschema.SchemaTypeNumber:
+			v = float64(g.rng.IntN(1000)) / 10
+		case jsonschema.SchemaTypeBool:
+			v = g.rng.IntN(2) == 0
+		}
+
+		data := g.encode(v)
+		if _, duplicate := seen[string(data)]; duplicate {
+			continue
+		}
+		seen[string(data)] = struct{}{}
+		values = append(values, g.document(data))
+	}
+
+	if len(values) == 1 && g.atLeast(jsonschema.VersionDraft6) && g.chance(0.5) {
+		b.WithConst(values[0])
+
+		return
+	}
+
+	b.WithEnum(values...)
+}
+
+// annotate adds metadata and extensions.
+func (g *schemaGenerator) annotate(b *jsonschema.Builder) {
+	if g.chance(0.3) {
+		b.WithTitle(g.word())
+	}
+
+	if g.chance(0.2) {
+		b.WithDescription(g.text())
+	}
+
+	if g.atLeast(jsonschema.VersionDraft2019) && g.chance(0.05) {
+		b.WithDeprecated(true)
+	}
+
+	if g.atLeast(jsonschema.VersionDraft7) && g.chance(0.05) {
+		b.WithReadOnly(true)
+	}
+
+	if g.useExtensions && g.chance(0.15) {
+		b.WithExtension("x-"+g.word(), g.document(g.encode(g.word())))
+	}
+}
+
+func (g *schemaGenerator) refTo(name string) string {
+	if g.atLeast(jsonschema.VersionDraft2019) {
+		return "#/$defs/" + name
+	}
+
+	return "#/definitions/" + name
+}
+
+// raw JSON of a generated subschema.
+func (g *schemaGenerator) raw(sch jsonschema.Schema) rawValue {
+	data, err := sch.MarshalJSON()
+	if err != nil && g.err == nil {
+		g.err = err
+	}
+
+	return data
+}
+
+func (g *schemaGenerator) encode(v value) []byte {
+	data, err := encode(v)
+	if err != nil && g.err == nil {
+		g.err = err
+	}
+
+	return data
+}
+
+func (g *schemaGenerator) document(data []byte) json.Document {
+	doc := json.Make()
+	if err := doc.UnmarshalJSON(data); err != nil && g.err == nil {
+		g.err = err
+	}
+
+	return doc
+}
+
+func (g *schemaGenerator) word() string {
+	const minWord, maxWord = 3, 8
+
+	n := minWord + g.rng.IntN(maxWord-minWord+1)
+	w := make([]byte, n)
+	for i := range w {
+		w[i] = lowerLetters[g.rng.IntN(len(lowerLetters))]
+	}
+
+	return string(w)
+}
+
+// text generates a short sentence, with the base faker if any.
+func (g *schemaGenerator) text() string {
+	const maxText = 40
+
+	if g.baseFaker != nil {
+		return string(g.baseFaker.String(stubs.FakerContext{Rand: g.rng}, stubs.MaxLength(maxText)))
+	}
+
+	words := make([]string, 0, 4)
+	for range 1 + g.rng.IntN(4) {
+		words = append(words, g.word())
+	}
+
+	return strings.Join(words, " ")
+}
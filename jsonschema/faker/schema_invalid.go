@@ -0,0 +1,78 @@
+package faker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+)
+
+// invalidKeywords violate the meta-schemas of all supported versions of JSON schema.
+var invalidKeywords = []struct {
+	keyword string
+	value   string
+}{
+	{"type", `"integral"`},
+	{"minLength", `-1`},
+	{"maxItems", `1.5`},
+	{"required", `[1]`},
+	{"properties", `3`},
+	{"multipleOf", `0`},
+	{"pattern", `12`},
+	{"allOf", `{}`},
+	{"enum", `"x"`},
+	{"uniqueItems", `"yes"`},
+}
+
+// violate alters a valid schema so that it becomes invalid against its meta-schema.
+func (g *schemaGenerator) violate(sch jsonschema.Schema) (json.Document, string, error) {
+	locations := subschemaLocations(sch)
+
+	n := 1
+	switch g.garbling {
+	case DistorsionLow:
+		locations = locations[:1] // the root schema
+	case DistorsionHigh:
+		n += 1 + g.rng.IntN(2)
+	}
+
+	doc := sch.Document
+	descriptions := make([]string, 0, n)
+
+	for range n {
+		location := locations[g.rng.IntN(len(locations))]
+		invalid := invalidKeywords[g.rng.IntN(len(invalidKeywords))]
+
+		p, err := json.MakePointer(location)
+		if err != nil {
+			return doc, "", fmt.Errorf("%w: %w", err, ErrSchemaFaker)
+		}
+
+		jb := json.NewBuilder(doc.Store()).From(doc).
+			AtPointerMerge(p, g.document([]byte(`{"`+invalid.keyword+`":`+invalid.value+`}`)))
+		if !jb.Ok() {
+			return doc, "", fmt.Errorf("%w: %w", jb.Err(), ErrSchemaFaker)
+		}
+
+		doc = jb.Document()
+		descriptions = append(descriptions, describe(location, invalid.keyword))
+	}
+
+	return doc, strings.Join(descriptions, ", "), nil
+}
+
+// subschemaLocations collects the JSON pointers to the object subschemas of a schema, starting with its root.
+func subschemaLocations(sch jsonschema.Schema) []string {
+	var locations []string
+
+	_ = jsonschema.WalkSchemas(sch.Document, sch.Version(), func(v jsonschema.SchemaVisit) (json.WalkAction, error) {
+		if v.Node.IsObject() {
+			locations = append(locations, v.Pointer.String())
+		}
+
+		return json.WalkContinue, nil
+	}, nil)
+
+	return locations
+}
@@ -0,0 +1,202 @@
+package faker
+
+import (
+	stdjson "encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaFaker(t *testing.T) {
+	t.Run("should generate deterministic schemas for a seed", func(t *testing.T) {
+		first := collectSchemas(t, NewSchemaFaker(WithSchemaSeed(7)), 5)
+		second := collectSchemas(t, NewSchemaFaker(WithSchemaSeed(7)), 5)
+		other := collectSchemas(t, NewSchemaFaker(WithSchemaSeed(8)), 5)
+
+		require.Equal(t, first, second)
+		require.NotEqual(t, first, other)
+	})
+
+	t.Run("should generate valid schemas for a version", func(t *testing.T) {
+		for _, version := range supportedVersions {
+			t.Run(version.String(), func(t *testing.T) {
+				f := NewSchemaFaker(WithSchemaVersion(version), WithSchemaComplexity(ComplexityHigh), UseRefCycles(true))
+
+				for _, data := range collectSchemas(t, f, samples) {
+					sch := jsonschema.Make()
+					require.NoError(t, sch.UnmarshalJSON([]byte(data)))
+					require.Equal(t, version, sch.Version())
+
+					requirements := jsonschema.NewBuilder().From(sch).VersionRequirements()
+					require.Truef(t, requirements.Allows(version), "%s", data)
+					require.NoErrorf(t, jsonschema.NewMetaValidator().Validate(sch), "%s", data)
+				}
+			})
+		}
+	})
+
+	t.Run("should generate satisfiable schemas", func(t *testing.T) {
+		for _, version := range supportedVersions {
+			for name, complexity := range map[string]ComplexityLevel{
+				"low": ComplexityLow, "medium": ComplexityMedium, "high": ComplexityHigh,
+			} {
+				t.Run(version.String()+"/"+name, func(t *testing.T) {
+					f := NewSchemaFaker(WithSchemaVersion(version), WithSchemaComplexity(complexity), WithSchemaSeed(1))
+
+					for _, data := range collectSchemas(t, f, samples) {
+						sch := jsonschema.Make()
+						require.NoError(t, sch.UnmarshalJSON([]byte(data)))
+
+						generated := NewDataFaker(sch, WithDataSeed(1)).Generate()
+						require.NoErrorf(t, generated.Err(), "%s", data)
+
+						v, err := validator.New(sch)
+						require.NoError(t, err)
+						require.NoErrorf(t, v.Validate(generated.Document()), "%s: %s", data, generated.Document().String())
+					}
+				})
+			}
+		}
+	})
+
+	t.Run("should disable features", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			opts     []SchemaOption
+			keywords []string
+		}{
+			{name: "without $ref", opts: []SchemaOption{UseRef(false)}, keywords: []string{`"$ref"`, `"$defs"`}},
+			{name: "without enum", opts: []SchemaOption{UseEnum(false)}, keywords: []string{`"enum"`, `"const"`}},
+			{name: "without extensions", opts: []SchemaOption{UseExtensions(false)}, keywords: []string{`"x-`}},
+			{name: "without pattern", opts: []SchemaOption{UsePattern(false)}, keywords: []string{`"pattern"`}},
+			{
+				name:     "without compositions",
+				opts:     []SchemaOption{WithSchemaCompositionDensity(0)},
+				keywords: []string{`"allOf"`, `"anyOf"`, `"oneOf"`, `"not"`, `"if"`},
+			},
+			{
+				name:     "without some compositions",
+				opts:     []SchemaOption{WithSchemaCompositionDensity(1), WithSchemaMaxDepth(2), UseAllOf(false), UseOneOf(false)},
+				keywords: []string{`"allOf"`, `"oneOf"`},
+			},
+			{
+				name:     "without additional properties",
+				opts:     []SchemaOption{UseAdditionalProperties(false), UseUnevaluatedProperties(false), UsePatternProperties(false)},
+				keywords: []string{`"additionalProperties"`, `"unevaluatedProperties"`, `"patternProperties"`},
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				for _, data := range collectSchemas(t, NewSchemaFaker(tc.opts...), samples) {
+					for _, keyword := range tc.keywords {
+						assert.NotContains(t, data, keyword)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("should limit the depth of schemas", func(t *testing.T) {
+		f := NewSchemaFaker(WithSchemaMaxDepth(1), WithSchemaComplexity(ComplexityHigh), UseRef(false))
+
+		for _, data := range collectSchemas(t, f, samples) {
+			var v any
+			require.NoError(t, stdjson.Unmarshal([]byte(data), &v))
+			assert.LessOrEqualf(t, depthOf(v), 4, "%s", data)
+		}
+	})
+
+	t.Run("should generate $ref cycles only when enabled", func(t *testing.T) {
+		hasCycle := func(data string) bool {
+			var sch struct {
+				Defs map[string]stdjson.RawMessage `json:"$defs"`
+			}
+			require.NoError(t, stdjson.Unmarshal([]byte(data), &sch))
+
+			for name, def := range sch.Defs {
+				index, err := strconv.Atoi(strings.TrimPrefix(name, "def"))
+				require.NoError(t, err)
+
+				for j := 0; j <= index; j++ {
+					if strings.Contains(string(def), `"#/$defs/def`+strconv.Itoa(j)+`"`) {
+						return true
+					}
+				}
+			}
+
+			return false
+		}
+
+		acyclic := collectSchemas(t, NewSchemaFaker(), samples)
+		assert.False(t, slices.ContainsFunc(acyclic, hasCycle))
+
+		cyclic := collectSchemas(t, NewSchemaFaker(UseRefCycles(true)), samples)
+		assert.True(t, slices.ContainsFunc(cyclic, hasCycle))
+	})
+
+	t.Run("should generate invalid schemas", func(t *testing.T) {
+		keywords := make([]string, 0, len(invalidKeywords))
+		for _, invalid := range invalidKeywords {
+			keywords = append(keywords, invalid.keyword)
+		}
+
+		f := NewSchemaFaker(WithSchemaOnlyInvalid(true, DistorsionLow))
+		for generated := range f.GenerateMany(samples) {
+			require.NoError(t, generated.Err())
+			require.False(t, generated.ShouldBeValid())
+			require.Contains(t, keywords, generated.Violation())
+
+			data, err := generated.Document().MarshalJSON()
+			require.NoError(t, err)
+			assert.Contains(t, string(data), `"`+generated.Violation()+`"`)
+			require.ErrorIsf(t, jsonschema.NewMetaValidator().Validate(generated.Schema()), jsonschema.ErrMetaSchema, "%s", data)
+		}
+
+		f = NewSchemaFaker(WithSchemaOnlyInvalid(true, DistorsionHigh))
+		for generated := range f.GenerateMany(samples) {
+			require.NoError(t, generated.Err())
+			require.False(t, generated.ShouldBeValid())
+			require.GreaterOrEqual(t, len(strings.Split(generated.Violation(), ", ")), 2)
+		}
+	})
+
+	t.Run("should fail with an unsupported version", func(t *testing.T) {
+		generated := NewSchemaFaker(WithSchemaVersion(jsonschema.VersionOpenAPIv2)).Generate()
+		require.ErrorIs(t, generated.Err(), ErrSchemaFaker)
+	})
+}
+
+func FuzzSchemaFaker(f *testing.F) {
+	SeedCorpus(f, 20, WithSchemaSeed(1), WithSchemaVersion(jsonschema.VersionDraft7))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		sch := jsonschema.Make()
+		if err := sch.UnmarshalJSON(data); err != nil {
+			return
+		}
+
+		_, err := sch.MarshalJSON()
+		require.NoError(t, err)
+	})
+}
+
+func collectSchemas(t *testing.T, f SchemaFaker, n int) []string {
+	t.Helper()
+
+	schemas := make([]string, 0, n)
+	for generated := range f.GenerateMany(n) {
+		require.NoError(t, generated.Err())
+		require.True(t, generated.ShouldBeValid())
+
+		data, err := generated.Document().MarshalJSON()
+		require.NoError(t, err)
+		schemas = append(schemas, string(data))
+	}
+
+	return schemas
+}
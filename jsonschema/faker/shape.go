@@ -0,0 +1,510 @@
+package faker
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+)
+
+// node is a schema in the context of its base URI and dynamic scope, used to resolve its references.
+type node struct {
+	doc   json.Document
+	base  string
+	scope jsonschema.DynamicScope
+}
+
+type bound struct {
+	value     float64
+	exclusive bool
+	defined   bool
+}
+
+type patternNode struct {
+	pattern string
+	node    node
+}
+
+// shape merges the constraints of a schema with the constraints of its "$ref" s, "allOf" members and
+// the branches chosen among alternatives ("anyOf", "oneOf", "if"), so that generated data satisfies all of them.
+type shape struct {
+	types      []string // nil when unconstrained
+	hasEnum    bool
+	enum       []rawValue
+	hasConst   bool
+	constValue rawValue
+
+	minimum    bound
+	maximum    bound
+	multipleOf []float64
+
+	minLength int
+	maxLength int // -1 when unconstrained
+	patterns  []string
+	format    string
+
+	prefixItems [][]node
+	items       []node
+	noMoreItems bool
+	minItems    int
+	maxItems    int // -1 when unconstrained
+	uniqueItems bool
+	contains    []node
+	minContains int // -1 when unconstrained
+	maxContains int // -1 when unconstrained
+
+	properties        []string
+	propertySchemas   map[string][]node
+	patternProperties []patternNode
+	additional        []node
+	noAdditional      bool
+	required          []string
+	dependentRequired map[string][]string
+	dependentSchemas  map[string][]node
+	minProperties     int
+	maxProperties     int // -1 when unconstrained
+	propertyNames     []node
+
+	depth         int  // number of "$ref" s followed from the root
+	alternatives  bool // constraints from a branch chosen among alternatives: violations are not reliable
+	unsatisfiable string
+}
+
+func newShape(depth int) *shape {
+	return &shape{
+		maxLength:     -1,
+		maxItems:      -1,
+		minContains:   -1,
+		maxContains:   -1,
+		maxProperties: -1,
+		depth:         depth,
+	}
+}
+
+// collect merges the constraints of a schema into the shape.
+func (g *generator) collect(s *shape, n node) error {
+	if n.doc.IsBool() {
+		if text(n.doc) == "false" {
+			s.unsatisfiable = "false schema"
+		}
+
+		return nil
+	}
+
+	if !n.doc.IsObject() {
+		return nil
+	}
+
+	n = g.enter(n)
+
+	if ref, ok := n.doc.AtKey("$ref"); ok && ref.IsString() {
+		if err := g.collectRef(s, n, ref.String(), false); err != nil {
+			return err
+		}
+
+		if g.version != jsonschema.VersionUndefined && g.version.Less(jsonschema.VersionDraft2019) {
+			// siblings of $ref are ignored
+			return nil
+		}
+	}
+
+	if ref, ok := n.doc.AtKey("$dynamicRef"); ok && ref.IsString() {
+		if err := g.collectRef(s, n, ref.String(), true); err != nil {
+			return err
+		}
+	}
+
+	if ref, ok := n.doc.AtKey("$recursiveRef"); ok && ref.IsString() {
+		if err := g.collectRef(s, n, ref.String(), false); err != nil {
+			return err
+		}
+	}
+
+	for key, value := range n.doc.Pairs() {
+		if err := g.collectKeyword(s, n, key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *generator) collectKeyword(s *shape, n node, key string, value json.Document) error {
+	child := func(doc json.Document) node {
+		return node{doc: doc, base: n.base, scope: n.scope}
+	}
+
+	if g.dollarData && jsonschema.IsDataRef(key, value) {
+		return nil // dynamic keyword
+	}
+
+	switch key {
+	case "type":
+		s.intersectTypes(stringsOf(value))
+	case "enum":
+		s.intersectEnum(value)
+	case "const":
+		raw := rawValue(text(value))
+		if s.hasConst && string(s.constValue) != string(raw) {
+			s.unsatisfiable = "conflicting const"
+		}
+		s.hasConst = true
+		s.constValue = raw
+
+	case "minimum", "exclusiveMinimum":
+		s.minimum = mergeBound(s.minimum, n.doc, key, "minimum", value, true)
+	case "maximum", "exclusiveMaximum":
+		s.maximum = mergeBound(s.maximum, n.doc, key, "maximum", value, false)
+	case "multipleOf":
+		if m, ok := number(value); ok && m > 0 {
+			s.multipleOf = append(s.multipleOf, m)
+		}
+
+	case "minLength":
+		s.minLength = max(s.minLength, count(value))
+	case "maxLength":
+		s.maxLength = minCount(s.maxLength, count(value))
+	case "pattern":
+		if value.IsString() {
+			s.patterns = append(s.patterns, value.String())
+		}
+	case "format":
+		if value.IsString() && s.format == "" {
+			s.format = value.String()
+		}
+
+	case "items":
+		if value.IsArray() {
+			s.mergePrefix(value, child)
+		} else {
+			s.mergeRest(child(value))
+		}
+	case "prefixItems":
+		s.mergePrefix(value, child)
+	case "additionalItems":
+		if items, ok := n.doc.AtKey("items"); ok && items.IsArray() {
+			s.mergeRest(child(value))
+		}
+	case "minItems":
+		s.minItems = max(s.minItems, count(value))
+	case "maxItems":
+		s.maxItems = minCount(s.maxItems, count(value))
+	case "uniqueItems":
+		s.uniqueItems = s.uniqueItems || text(value) == "true"
+	case "contains":
+		s.contains = append(s.contains, child(value))
+	case "minContains":
+		s.minContains = max(s.minContains, count(value))
+	case "maxContains":
+		s.maxContains = minCount(s.maxContains, count(value))
+
+	case "properties":
+		for name, schema := range value.Pairs() {
+			s.addProperty(name, child(schema))
+		}
+	case "patternProperties":
+		for pattern, schema := range value.Pairs() {
+			s.patternProperties = append(s.patternProperties, patternNode{pattern: pattern, node: child(schema)})
+		}
+	case "additionalProperties", "unevaluatedProperties":
+		// the properties of merged subschemas are evaluated: unevaluated properties are additional properties,
+		// unless "additionalProperties" evaluates them
+		if _, ok := n.doc.AtKey("additionalProperties"); ok && key == "unevaluatedProperties" {
+			break
+		}
+
+		if text(value) == "false" {
+			s.noAdditional = true
+		} else {
+			s.additional = append(s.additional, child(value))
+		}
+	case "required":
+		for _, name := range stringsOf(value) {
+			if !slices.Contains(s.required, name) {
+				s.required = append(s.required, name)
+			}
+		}
+	case "dependentRequired":
+		s.mergeDependentRequired(value)
+	case "dependentSchemas":
+		s.mergeDependentSchemas(value, child)
+	case "dependencies":
+		s.mergeDependentRequired(value)
+		s.mergeDependentSchemas(value, child)
+	case "minProperties":
+		s.minProperties = max(s.minProperties, count(value))
+	case "maxProperties":
+		s.maxProperties = minCount(s.maxProperties, count(value))
+	case "propertyNames":
+		s.propertyNames = append(s.propertyNames, child(value))
+
+	case "allOf":
+		for member := range value.Elems() {
+			if err := g.collect(s, child(member)); err != nil {
+				return err
+			}
+		}
+	case "anyOf", "oneOf":
+		if value.Len() == 0 {
+			return nil
+		}
+		if value.Len() > 1 {
+			s.alternatives = true
+		}
+		branch, _ := value.Elem(g.rng.IntN(value.Len()))
+
+		return g.collect(s, child(branch))
+	case "if":
+		// the generated data satisfies "if" and "then", hence the whole conditional
+		s.alternatives = true
+		if err := g.collect(s, child(value)); err != nil {
+			return err
+		}
+		if then, ok := n.doc.AtKey("then"); ok {
+			return g.collect(s, child(then))
+		}
+	}
+
+	return nil
+}
+
+// collectRef follows a "$ref" (or a "$dynamicRef").
+func (g *generator) collectRef(s *shape, n node, ref string, dynamic bool) error {
+	var (
+		resolved jsonschema.ResolvedSchema
+		err      error
+	)
+
+	if dynamic {
+		resolved, err = g.resolver.ResolveDynamic(ref, n.base, n.scope)
+	} else {
+		resolved, err = g.resolver.Resolve(ref, n.base)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", err, ErrDataFaker)
+	}
+
+	s.depth++
+	if s.depth > g.hardDepth() {
+		return fmt.Errorf("no finite data after following %d references to %q: %w", s.depth, ref, ErrUnsatisfiable)
+	}
+
+	return g.collect(s, node{
+		doc:   resolved.Document,
+		base:  resolved.BaseURI(),
+		scope: n.scope.Enter(resolved.BaseURI()),
+	})
+}
+
+// enter a schema: a schema with an "$id" changes the base URI.
+func (g *generator) enter(n node) node {
+	key := "$id"
+	if g.version != jsonschema.VersionUndefined && g.version.Less(jsonschema.VersionDraft6) {
+		key = "id"
+	}
+
+	id, ok := n.doc.AtKey(key)
+	if !ok || !id.IsString() || len(id.String()) == 0 || id.String()[0] == '#' {
+		return n
+	}
+
+	resolved, err := g.resolver.Resolve(id.String(), n.base)
+	if err != nil {
+		return n
+	}
+
+	n.base = resolved.BaseURI()
+	n.scope = n.scope.Enter(n.base)
+
+	return n
+}
+
+func (s *shape) intersectTypes(types []string) {
+	if s.types == nil {
+		s.types = types
+
+		return
+	}
+
+	var intersection []string
+	for _, t := range s.types {
+		switch {
+		case slices.Contains(types, t):
+			intersection = append(intersection, t)
+		case t == typeInteger && slices.Contains(types, typeNumber),
+			t == typeNumber && slices.Contains(types, typeInteger):
+			intersection = append(intersection, typeInteger)
+		}
+	}
+
+	s.types = slices.Compact(intersection)
+	if len(s.types) == 0 {
+		s.unsatisfiable = "no type satisfies all constraints"
+	}
+}
+
+func (s *shape) intersectEnum(value json.Document) {
+	var values []rawValue
+	for elem := range value.Elems() {
+		raw := rawValue(text(elem))
+		if !s.hasEnum || slices.ContainsFunc(s.enum, func(v rawValue) bool { return string(v) == string(raw) }) {
+			values = append(values, raw)
+		}
+	}
+
+	s.hasEnum = true
+	s.enum = values
+	if len(values) == 0 {
+		s.unsatisfiable = "no enum value satisfies all constraints"
+	}
+}
+
+func (s *shape) mergePrefix(value json.Document, child func(json.Document) node) {
+	for i, elem := range value.IndexedElems() {
+		if i >= len(s.prefixItems) {
+			s.prefixItems = append(s.prefixItems, nil)
+		}
+		s.prefixItems[i] = append(s.prefixItems[i], child(elem))
+	}
+}
+
+func (s *shape) mergeRest(n node) {
+	if text(n.doc) == "false" {
+		s.noMoreItems = true
+
+		return
+	}
+
+	s.items = append(s.items, n)
+}
+
+func (s *shape) addProperty(name string, n node) {
+	if s.propertySchemas == nil {
+		s.propertySchemas = make(map[string][]node)
+	}
+
+	if _, ok := s.propertySchemas[name]; !ok {
+		s.properties = append(s.properties, name)
+	}
+	s.propertySchemas[name] = append(s.propertySchemas[name], n)
+}
+
+func (s *shape) mergeDependentRequired(value json.Document) {
+	for name, dependents := range value.Pairs() {
+		if !dependents.IsArray() {
+			continue
+		}
+
+		if s.dependentRequired == nil {
+			s.dependentRequired = make(map[string][]string)
+		}
+		s.dependentRequired[name] = append(s.dependentRequired[name], stringsOf(dependents)...)
+	}
+}
+
+func (s *shape) mergeDependentSchemas(value json.Document, child func(json.Document) node) {
+	for name, schema := range value.Pairs() {
+		if schema.IsArray() {
+			continue
+		}
+
+		if s.dependentSchemas == nil {
+			s.dependentSchemas = make(map[string][]node)
+		}
+		s.dependentSchemas[name] = append(s.dependentSchemas[name], child(schema))
+	}
+}
+
+// mergeBound merges a lower or upper bound, supporting the boolean form of exclusive bounds (draft 4).
+func mergeBound(current bound, parent json.Document, key, inclusiveKey string, value json.Document, lower bool) bound {
+	var b bound
+
+	switch {
+	case value.IsBool():
+		// draft 4: exclusiveMinimum and exclusiveMaximum qualify minimum and maximum
+		if key == inclusiveKey || text(value) != "true" {
+			return current
+		}
+		limit, ok := parent.AtKey(inclusiveKey)
+		if !ok {
+			return current
+		}
+		v, ok := number(limit)
+		if !ok {
+			return current
+		}
+		b = bound{value: v, exclusive: true, defined: true}
+	default:
+		v, ok := number(value)
+		if !ok {
+			return current
+		}
+		b = bound{value: v, exclusive: key != inclusiveKey, defined: true}
+	}
+
+	switch {
+	case !current.defined:
+		return b
+	case b.value == current.value:
+		current.exclusive = current.exclusive || b.exclusive
+
+		return current
+	case lower == (b.value > current.value):
+		return b
+	default:
+		return current
+	}
+}
+
+func text(doc json.Document) string {
+	data, err := doc.MarshalJSON()
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
+
+func number(doc json.Document) (float64, bool) {
+	if !doc.IsNumber() {
+		return 0, false
+	}
+
+	v, err := strconv.ParseFloat(text(doc), 64)
+
+	return v, err == nil
+}
+
+func count(doc json.Document) int {
+	v, ok := number(doc)
+	if !ok || v < 0 {
+		return 0
+	}
+
+	return int(v)
+}
+
+func minCount(current, v int) int {
+	if current < 0 {
+		return v
+	}
+
+	return min(current, v)
+}
+
+func stringsOf(doc json.Document) []string {
+	if doc.IsString() {
+		return []string{doc.String()}
+	}
+
+	var values []string
+	for elem := range doc.Elems() {
+		if elem.IsString() {
+			values = append(values, elem.String())
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,376 @@
+package faker
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/netip"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fredbi/core/stubs"
+)
+
+// str generates a string satisfying the format, the patterns and the length constraints of the shape.
+func (g *generator) str(s *shape, pointer string) (value, error) {
+	const attempts = 20
+
+	minLength := s.minLength
+	maxLength := s.maxLength
+	if maxLength < 0 {
+		maxLength = math.MaxInt
+	}
+	if minLength > maxLength {
+		return nil, fmt.Errorf("at %q: minLength is greater than maxLength: %w", pointer, ErrUnsatisfiable)
+	}
+
+	generate, err := g.stringGenerator(s, minLength, maxLength)
+	if err != nil {
+		return nil, fmt.Errorf("at %q: %w", pointer, err)
+	}
+
+	for range attempts {
+		str, err := generate()
+		if err != nil {
+			return nil, fmt.Errorf("at %q: %w", pointer, err)
+		}
+
+		str = g.pad(s, str, minLength)
+		if s.allowsString(str, minLength, maxLength) && g.validFormat(s.format, str) {
+			return str, nil
+		}
+	}
+
+	return nil, fmt.Errorf("at %q: no string satisfies all string constraints: %w", pointer, ErrUnsatisfiable)
+}
+
+// stringGenerator selects how strings are generated: from a format, from a pattern, or from the base faker.
+func (g *generator) stringGenerator(s *shape, minLength, maxLength int) (func() (string, error), error) {
+	if maxLength == math.MaxInt {
+		maxLength = minLength + g.sizes().length
+	}
+
+	if s.format != "" {
+		if generate, ok := formatGenerators[s.format]; ok {
+			return func() (string, error) { return generate(g), nil }, nil
+		}
+
+		if g.supportsFormat(s.format) {
+			return nil, fmt.Errorf("no generator for format %q: %w", s.format, ErrDataFaker)
+		}
+		// unknown formats are mere annotations
+	}
+
+	if len(s.patterns) > 0 {
+		pattern := s.patterns[0]
+
+		return func() (string, error) { return g.fromPattern(pattern) }, nil
+	}
+
+	if g.baseFaker != nil {
+		return func() (string, error) {
+			str := string(g.baseFaker.String(g.fakerContext(), stubs.MaxLength(maxLength)))
+			if utf8.RuneCountInString(str) < minLength {
+				return g.letters(minLength, maxLength), nil
+			}
+
+			return str, nil
+		}, nil
+	}
+
+	return func() (string, error) { return g.letters(minLength, maxLength), nil }, nil
+}
+
+func (g *generator) supportsFormat(format string) bool {
+	return g.formats != nil && slices.Contains(g.formats.SupportedFormats(), format)
+}
+
+func (g *generator) validFormat(format, str string) bool {
+	if format == "" || !g.supportsFormat(format) {
+		return true
+	}
+
+	return g.formats.Validate(format, str) == nil
+}
+
+// pad a string which is too short, when its patterns allow it.
+func (g *generator) pad(s *shape, str string, minLength int) string {
+	missing := minLength - utf8.RuneCountInString(str)
+	if missing <= 0 || s.format != "" {
+		return str
+	}
+
+	padded := str + g.letters(missing, missing)
+	for _, pattern := range s.patterns {
+		if !matches(pattern, padded) {
+			return str
+		}
+	}
+
+	return padded
+}
+
+func (s *shape) allowsString(str string, minLength, maxLength int) bool {
+	length := utf8.RuneCountInString(str)
+	if length < minLength || length > maxLength {
+		return false
+	}
+
+	for _, pattern := range s.patterns {
+		if !matches(pattern, str) {
+			return false
+		}
+	}
+
+	return true
+}
+
+const (
+	lowerLetters = "abcdefghijklmnopqrstuvwxyz"
+	hexDigits    = "0123456789abcdef"
+)
+
+// letters generates a string of lower case letters, with a length in [minLength, maxLength].
+func (g *generator) letters(minLength, maxLength int) string {
+	n := minLength
+	if maxLength > minLength {
+		n += g.rng.IntN(maxLength - minLength + 1)
+	}
+
+	return g.fromAlphabet(lowerLetters, n)
+}
+
+func (g *generator) fromAlphabet(alphabet string, n int) string {
+	var w strings.Builder
+	w.Grow(n)
+	for range n {
+		w.WriteByte(alphabet[g.rng.IntN(len(alphabet))])
+	}
+
+	return w.String()
+}
+
+func (g *generator) word() string {
+	const minWord, maxWord = 3, 8
+
+	return g.letters(minWord, maxWord)
+}
+
+// formatGenerators generate strings for the formats defined by the JSON schema specification.
+var formatGenerators = map[string]func(*generator) string{
+	"date-time":             func(g *generator) string { return g.time().Format(time.RFC3339) },
+	"date":                  func(g *generator) string { return g.time().Format(time.DateOnly) },
+	"time":                  func(g *generator) string { return g.time().Format("15:04:05Z07:00") },
+	"duration":              (*generator).duration,
+	"email":                 func(g *generator) string { return g.word() + "@" + g.hostname() },
+	"idn-email":             func(g *generator) string { return g.word() + "@" + g.hostname() },
+	"hostname":              (*generator).hostname,
+	"idn-hostname":          (*generator).hostname,
+	"ipv4":                  (*generator).ipv4,
+	"ipv6":                  (*generator).ipv6,
+	"uri":                   func(g *generator) string { return "https://" + g.hostname() + "/" + g.word() },
+	"iri":                   func(g *generator) string { return "https://" + g.hostname() + "/" + g.word() },
+	"uri-reference":         func(g *generator) string { return "/" + g.word() + "#" + g.word() },
+	"iri-reference":         func(g *generator) string { return "/" + g.word() + "#" + g.word() },
+	"uri-template":          func(g *generator) string { return "https://" + g.hostname() + "/{" + g.word() + "}" },
+	"uuid":                  (*generator).uuid,
+	"json-pointer":          func(g *generator) string { return "/" + g.word() + "/" + strconv.Itoa(g.rng.IntN(10)) },
+	"relative-json-pointer": func(g *generator) string { return strconv.Itoa(g.rng.IntN(3)) + "/" + g.word() },
+	"regex":                 func(g *generator) string { return "^" + g.word() + "[0-9]*$" },
+	"byte":                  (*generator).base64,
+	"password":              func(g *generator) string { return g.fromAlphabet(lowerLetters+hexDigits+"!#$%&*+-=?@^_", 12) },
+}
+
+func (g *generator) time() time.Time {
+	const (
+		from = 0          // 1970-01-01
+		to   = 2208988800 // 2040-01-01
+	)
+
+	return time.Unix(from+g.rng.Int64N(to-from), 0).UTC()
+}
+
+func (g *generator) duration() string {
+	return fmt.Sprintf("P%dDT%dH%dM", g.rng.IntN(30), g.rng.IntN(24), g.rng.IntN(60))
+}
+
+func (g *generator) hostname() string {
+	return g.word() + ".example.com"
+}
+
+func (g *generator) ipv4() string {
+	var b [4]byte
+	for i := range b {
+		b[i] = byte(g.rng.IntN(256))
+	}
+
+	return netip.AddrFrom4(b).String()
+}
+
+func (g *generator) ipv6() string {
+	var b [16]byte
+	for i := range b {
+		b[i] = byte(g.rng.IntN(256))
+	}
+
+	return netip.AddrFrom16(b).String()
+}
+
+func (g *generator) uuid() string {
+	var b [16]byte
+	for i := range b {
+		b[i] = byte(g.rng.IntN(256))
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+
+	h := fmt.Sprintf("%x", b[:])
+
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+func (g *generator) base64() string {
+	b := make([]byte, 1+g.rng.IntN(g.sizes().length))
+	for i := range b {
+		b[i] = byte(g.rng.IntN(256))
+	}
+
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// fromPattern generates a string matching a regular expression.
+func (g *generator) fromPattern(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern %q: %w: %w", pattern, err, ErrDataFaker)
+	}
+
+	var w strings.Builder
+	if err := g.writeRegexp(&w, re.Simplify()); err != nil {
+		return "", fmt.Errorf("pattern %q: %w", pattern, err)
+	}
+
+	return w.String(), nil
+}
+
+func (g *generator) writeRegexp(w *strings.Builder, re *syntax.Regexp) error {
+	repeat := g.sizes().repeat
+
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return fmt.Errorf("the pattern matches no string: %w", ErrUnsatisfiable)
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && g.rng.IntN(2) == 0 {
+				r = swapCase(r)
+			}
+			w.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		w.WriteRune(g.fromClass(re.Rune))
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		w.WriteByte(lowerLetters[g.rng.IntN(len(lowerLetters))])
+	case syntax.OpCapture:
+		return g.writeRegexp(w, re.Sub[0])
+	case syntax.OpStar:
+		return g.writeRepeat(w, re.Sub[0], 0, repeat)
+	case syntax.OpPlus:
+		return g.writeRepeat(w, re.Sub[0], 1, 1+repeat)
+	case syntax.OpQuest:
+		return g.writeRepeat(w, re.Sub[0], 0, 1)
+	case syntax.OpRepeat:
+		upper := re.Max
+		if upper < 0 || upper > re.Min+repeat {
+			upper = re.Min + repeat
+		}
+
+		return g.writeRepeat(w, re.Sub[0], re.Min, upper)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := g.writeRegexp(w, sub); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		return g.writeRegexp(w, re.Sub[g.rng.IntN(len(re.Sub))])
+	default:
+		// empty matches, anchors and word boundaries
+	}
+
+	return nil
+}
+
+func (g *generator) writeRepeat(w *strings.Builder, re *syntax.Regexp, lower, upper int) error {
+	n := lower
+	if upper > lower {
+		n += g.rng.IntN(upper - lower + 1)
+	}
+
+	for range n {
+		if err := g.writeRegexp(w, re); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fromClass picks a rune from a character class, preferably a printable ASCII character.
+func (g *generator) fromClass(ranges []rune) rune {
+	const (
+		firstPrintable = 0x20
+		lastPrintable  = 0x7e
+	)
+
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], firstPrintable), min(ranges[i+1], lastPrintable)
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+
+	if len(printable) == 0 {
+		printable = ranges
+	}
+
+	pair := 2 * g.rng.IntN(len(printable)/2)
+	lo, hi := printable[pair], printable[pair+1]
+
+	return lo + rune(g.rng.Int64N(int64(hi-lo)+1))
+}
+
+func swapCase(r rune) rune {
+	if s := strings.ToUpper(string(r)); s != string(r) {
+		v, _ := utf8.DecodeRuneInString(s)
+
+		return v
+	}
+
+	v, _ := utf8.DecodeRuneInString(strings.ToLower(string(r)))
+
+	return v
+}
+
+var patterns sync.Map // compiled patterns, by pattern
+
+// matches tells if a string matches a pattern. Invalid patterns match nothing.
+func matches(pattern, str string) bool {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp).MatchString(str)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false
+	}
+	patterns.Store(pattern, re)
+
+	return re.MatchString(str)
+}
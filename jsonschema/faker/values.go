@@ -0,0 +1,187 @@
+package faker
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// value is a generated JSON value.
+//
+// It is one of: nil (null), bool, int64, float64, string, []value, *object or rawValue.
+type value any
+
+// object is a JSON object with ordered keys.
+type object struct {
+	keys   []string
+	values []value
+}
+
+func (o *object) has(key string) bool {
+	return o.index(key) >= 0
+}
+
+func (o *object) index(key string) int {
+	for i, k := range o.keys {
+		if k == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (o *object) set(key string, v value) {
+	if i := o.index(key); i >= 0 {
+		o.values[i] = v
+
+		return
+	}
+
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, v)
+}
+
+func (o *object) remove(key string) {
+	i := o.index(key)
+	if i < 0 {
+		return
+	}
+
+	o.keys = append(o.keys[:i], o.keys[i+1:]...)
+	o.values = append(o.values[:i], o.values[i+1:]...)
+}
+
+func (o *object) clone() *object {
+	return &object{
+		keys:   slices.Clone(o.keys),
+		values: slices.Clone(o.values),
+	}
+}
+
+// rawValue is a JSON value copied verbatim from the schema, e.g. from "enum" or "const".
+type rawValue []byte
+
+func encode(v value) ([]byte, error) {
+	var w bytes.Buffer
+	if err := encodeTo(&w, v); err != nil {
+		return nil, err
+	}
+
+	return w.Bytes(), nil
+}
+
+func encodeTo(w *bytes.Buffer, v value) error {
+	switch tv := v.(type) {
+	case nil:
+		w.WriteString("null")
+	case bool:
+		w.WriteString(strconv.FormatBool(tv))
+	case int64:
+		w.WriteString(strconv.FormatInt(tv, 10))
+	case float64:
+		if math.IsInf(tv, 0) || math.IsNaN(tv) {
+			return fmt.Errorf("cannot encode number %v as JSON: %w", tv, ErrDataFaker)
+		}
+		w.WriteString(strconv.FormatFloat(tv, 'g', -1, 64))
+	case string:
+		data, _ := stdjson.Marshal(tv)
+		w.Write(data)
+	case rawValue:
+		w.Write(tv)
+	case []value:
+		w.WriteByte('[')
+		for i, elem := range tv {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err := encodeTo(w, elem); err != nil {
+				return err
+			}
+		}
+		w.WriteByte(']')
+	case *object:
+		w.WriteByte('{')
+		for i, key := range tv.keys {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			data, _ := stdjson.Marshal(key)
+			w.Write(data)
+			w.WriteByte(':')
+			if err := encodeTo(w, tv.values[i]); err != nil {
+				return err
+			}
+		}
+		w.WriteByte('}')
+	default:
+		return fmt.Errorf("cannot encode generated value of type %T: %w", v, ErrDataFaker)
+	}
+
+	return nil
+}
+
+// kindOf returns the JSON schema type of a generated value, or the empty string for an unknown value.
+func kindOf(v value) string {
+	switch tv := v.(type) {
+	case nil:
+		return typeNull
+	case bool:
+		return typeBoolean
+	case int64:
+		return typeInteger
+	case float64:
+		return typeNumber
+	case string:
+		return typeString
+	case []value:
+		return typeArray
+	case *object:
+		return typeObject
+	case rawValue:
+		return kindOfRaw(tv)
+	default:
+		return ""
+	}
+}
+
+func kindOfRaw(raw rawValue) string {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return typeNull
+	}
+
+	switch trimmed[0] {
+	case 'n':
+		return typeNull
+	case 't', 'f':
+		return typeBoolean
+	case '"':
+		return typeString
+	case '[':
+		return typeArray
+	case '{':
+		return typeObject
+	default:
+		if bytes.ContainsAny(trimmed, ".eE") {
+			return typeNumber
+		}
+
+		return typeInteger
+	}
+}
+
+const (
+	typeNull    = "null"
+	typeBoolean = "boolean"
+	typeInteger = "integer"
+	typeNumber  = "number"
+	typeString  = "string"
+	typeArray   = "array"
+	typeObject  = "object"
+)
+
+var allTypes = []string{typeNull, typeBoolean, typeInteger, typeNumber, typeString, typeArray, typeObject}
@@ -4,7 +4,10 @@ go 1.24.2
 
 require (
 	github.com/fredbi/core/json v0.0.0-00010101000000-000000000000
+	github.com/fredbi/core/strfmt v0.0.0-00010101000000-000000000000
 	github.com/fredbi/core/stubs v0.0.0-00010101000000-000000000000
+	github.com/fredbi/core/swag/fs v0.0.0-00010101000000-000000000000
+	github.com/fredbi/core/swag/loading v0.0.0-00010101000000-000000000000
 	github.com/fredbi/core/swag/pools v0.0.0-00010101000000-000000000000
 	github.com/fredbi/core/swag/stringutils v0.0.0-00010101000000-000000000000
 	github.com/fredbi/core/swag/typeutils v0.0.0-00010101000000-000000000000
@@ -13,20 +16,23 @@ require (
 require (
 	github.com/hmdsefi/gograph v0.5.0
 	github.com/stretchr/testify v1.10.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/fredbi/core/swag/conv v0.0.0-00010101000000-000000000000 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 replace (
 	github.com/fredbi/core/json => ../json
+	github.com/fredbi/core/strfmt => ../strfmt
 	github.com/fredbi/core/stubs => ../stubs
 	github.com/fredbi/core/swag => ../swag
 	github.com/fredbi/core/swag/conv => ../swag/conv
+	github.com/fredbi/core/swag/fs => ../swag/fs
+	github.com/fredbi/core/swag/loading => ../swag/loading
 	github.com/fredbi/core/swag/pools => ../swag/pools
 	github.com/fredbi/core/swag/stringutils => ../swag/stringutils
 	github.com/fredbi/core/swag/typeutils => ../swag/typeutils
@@ -0,0 +1,3 @@
+// Package keywords provides helpers shared by the packages which interpret JSON schemas: reading the values of
+// keywords, comparing JSON values and locating subschemas.
+package keywords
@@ -0,0 +1,82 @@
+package keywords
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+)
+
+func TestEqual(t *testing.T) {
+	for _, tc := range []struct {
+		a, b     string
+		expected bool
+	}{
+		{a: `{"a":1,"b":2}`, b: `{"b":2,"a":1}`, expected: true},
+		{a: `1.0`, b: `1`, expected: true},
+		{a: `1e2`, b: `100`, expected: true},
+		{a: `[1,2]`, b: `[2,1]`, expected: false},
+		{a: `{"a":1}`, b: `{"a":1,"b":2}`, expected: false},
+		{a: `"1"`, b: `1`, expected: false},
+		{a: `null`, b: `false`, expected: false},
+	} {
+		t.Run("should compare "+tc.a+" and "+tc.b, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Equal(mustDocument(t, tc.a), mustDocument(t, tc.b)))
+		})
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	schema := mustDocument(t, `{"minimum": 1.5, "maxLength": 3, "pattern": "^a", "uniqueItems": true}`)
+
+	t.Run("should read the values of keywords", func(t *testing.T) {
+		r, ok := RatAt(schema, "minimum")
+		require.True(t, ok)
+		assert.Equal(t, "3/2", r.String())
+
+		_, ok = IntAt(schema, "minimum")
+		assert.False(t, ok)
+
+		n, ok := IntAt(schema, "maxLength")
+		require.True(t, ok)
+		assert.Equal(t, int64(3), n)
+
+		s, ok := StringAt(schema, "pattern")
+		require.True(t, ok)
+		assert.Equal(t, "^a", s)
+
+		assert.True(t, IsTrue(schema, "uniqueItems"))
+		assert.False(t, IsTrue(schema, "pattern"))
+	})
+
+	t.Run("should locate JSON pointers", func(t *testing.T) {
+		assert.Equal(t, "#", FragmentRef(""))
+		assert.Equal(t, "#/$defs/a%20b", FragmentRef("/$defs/a b"))
+	})
+
+	t.Run("should locate documents relative to the root", func(t *testing.T) {
+		const root = "file:///tmp/specs/main.json"
+
+		assert.Equal(t, []string{"models", "pet"}, DocumentPackages("file:///tmp/specs/models/pet.json", root))
+		assert.Equal(t, []string{"tmp", "shared", "pet"}, DocumentPackages("file:///tmp/shared/pet.json", root))
+		assert.Equal(t, []string{"tmp", "specs", "pet"}, DocumentPackages("file:///tmp/specs/pet.json", ""))
+		assert.Empty(t, DocumentPackages("https://example.com", root))
+	})
+
+	t.Run("should compare versions", func(t *testing.T) {
+		assert.True(t, Since(jsonschema.VersionDraft2020, jsonschema.VersionDraft2019))
+		assert.False(t, Since(jsonschema.VersionDraft7, jsonschema.VersionDraft2019))
+	})
+}
+
+func mustDocument(t *testing.T, input string) json.Document {
+	t.Helper()
+
+	d := json.Make()
+	require.NoError(t, d.UnmarshalJSON([]byte(input)))
+
+	return d
+}
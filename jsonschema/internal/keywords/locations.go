@@ -0,0 +1,63 @@
+package keywords
+
+import (
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/fredbi/core/jsonschema"
+)
+
+// FragmentRef is a "$ref" to a JSON pointer in the current document.
+func FragmentRef(pointer string) string {
+	if pointer == "" {
+		return "#"
+	}
+
+	return (&url.URL{Fragment: pointer}).String()
+}
+
+// Since tells if a schema of some version of JSON schema follows the semantics of another version, or a later one.
+func Since(v, version jsonschema.Version) bool {
+	return !v.Less(version)
+}
+
+// DocumentPackages is the package path of a document, after its URI and relative to the folder of the root
+// document, e.g. "models/pet" for "https://example.com/api/models/pet.json" with the root
+// "https://example.com/api/main.json".
+//
+// Documents outside the folder of the root document, or without a root, keep the full path of their URI.
+func DocumentPackages(uri, root string) []string {
+	packages := uriSegments(uri)
+	folder := uriSegments(root)
+	if len(folder) == 0 {
+		return packages
+	}
+
+	folder = folder[:len(folder)-1]
+	if len(packages) > len(folder) && slices.Equal(packages[:len(folder)], folder) {
+		return packages[len(folder):]
+	}
+
+	return packages
+}
+
+// uriSegments are the segments of the path of a URI, without the extension of the last one.
+func uriSegments(uri string) []string {
+	p := uri
+	if u, err := url.Parse(uri); err == nil {
+		p = u.Path
+	}
+
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if p == "" || p == "." {
+		return nil
+	}
+
+	segments := strings.Split(p, "/")
+	last := segments[len(segments)-1]
+	segments[len(segments)-1] = strings.TrimSuffix(last, path.Ext(last))
+
+	return segments
+}
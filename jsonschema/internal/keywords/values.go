@@ -0,0 +1,121 @@
+package keywords
+
+import (
+	"math/big"
+
+	"github.com/fredbi/core/json"
+)
+
+// Equal tells if two JSON values are equal: numbers are compared by value, and the order of the keys of
+// objects doesn't matter.
+func Equal(a, b json.Document) bool {
+	switch {
+	case a.IsNull():
+		return b.IsNull()
+	case a.IsBool():
+		if !b.IsBool() {
+			return false
+		}
+		va, _ := a.Value()
+		vb, _ := b.Value()
+
+		return va.Bool() == vb.Bool()
+	case a.IsString():
+		return b.IsString() && a.String() == b.String()
+	case a.IsNumber():
+		if !b.IsNumber() {
+			return false
+		}
+		ra, okA := Rat(a)
+		rb, okB := Rat(b)
+
+		return okA && okB && ra.Cmp(rb) == 0
+	case a.IsArray():
+		if !b.IsArray() || a.Len() != b.Len() {
+			return false
+		}
+		for i, elem := range a.IndexedElems() {
+			other, _ := b.Elem(i)
+			if !Equal(elem, other) {
+				return false
+			}
+		}
+
+		return true
+	case a.IsObject():
+		if !b.IsObject() || a.Len() != b.Len() {
+			return false
+		}
+		for key, value := range a.Pairs() {
+			other, ok := b.AtKey(key)
+			if !ok || !Equal(value, other) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return false
+	}
+}
+
+// Rat is the value of a JSON number.
+func Rat(value json.Document) (*big.Rat, bool) {
+	v, ok := value.Value()
+	if !ok {
+		return nil, false
+	}
+
+	return new(big.Rat).SetString(string(v.NumberValue().Value))
+}
+
+// RatAt is the numerical value of a keyword.
+func RatAt(schema json.Document, keyword string) (*big.Rat, bool) {
+	value, ok := schema.AtKey(keyword)
+	if !ok || !value.IsNumber() {
+		return nil, false
+	}
+
+	return Rat(value)
+}
+
+// IntAt is the value of a keyword with an integer value.
+func IntAt(schema json.Document, keyword string) (int64, bool) {
+	r, ok := RatAt(schema, keyword)
+	if !ok || !r.IsInt() || !r.Num().IsInt64() {
+		return 0, false
+	}
+
+	return r.Num().Int64(), true
+}
+
+// StringAt is the value of a keyword with a string value.
+func StringAt(schema json.Document, keyword string) (string, bool) {
+	value, ok := schema.AtKey(keyword)
+	if !ok || !value.IsString() {
+		return "", false
+	}
+
+	return value.String(), true
+}
+
+// IsTrue tells if a keyword has the value true.
+func IsTrue(schema json.Document, keyword string) bool {
+	value, ok := schema.AtKey(keyword)
+	if !ok || !value.IsBool() {
+		return false
+	}
+	v, _ := value.Value()
+
+	return v.Bool()
+}
+
+// Text renders a JSON value compactly, e.g. in messages.
+func Text(value json.Document) string {
+	data, err := value.MarshalJSON()
+	if err != nil {
+		return value.String()
+	}
+
+	return string(data)
+}
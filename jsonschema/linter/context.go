@@ -0,0 +1,119 @@
+package linter
+
+import (
+	"fmt"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+	"github.com/fredbi/core/strfmt/registries"
+)
+
+// Context of a schema checked by a [Rule].
+type Context struct {
+	run      *run
+	document *document
+	schema   json.Document
+	pointer  string
+	keyword  string
+	name     string
+	severity Severity
+	ruleID   string
+	ignored  map[string]struct{}
+}
+
+// Schema being checked. It is always a JSON object.
+func (c *Context) Schema() json.Document {
+	return c.schema
+}
+
+// Pointer is the JSON pointer to the schema in its document.
+func (c *Context) Pointer() string {
+	return c.pointer
+}
+
+// Keyword of the parent schema that holds this schema, e.g. "properties" or "items".
+//
+// It is empty for the root schema.
+func (c *Context) Keyword() string {
+	return c.keyword
+}
+
+// Name of the schema when it is a member of "properties", "$defs" or "definitions", e.g. the name of a property.
+func (c *Context) Name() string {
+	return c.name
+}
+
+// IsRoot tells if the schema is the root of its document.
+func (c *Context) IsRoot() bool {
+	return c.pointer == ""
+}
+
+// Version of JSON schema of the document, or [jsonschema.VersionUndefined] if it is unknown (see [WithVersion]).
+func (c *Context) Version() jsonschema.Version {
+	return c.document.version
+}
+
+// URI of the document.
+func (c *Context) URI() string {
+	return c.document.uri
+}
+
+// Formats registry provided with [WithFormats], if any.
+func (c *Context) Formats() registries.Registry {
+	return c.run.formats
+}
+
+// Resolve a JSON reference found in the schema.
+func (c *Context) Resolve(ref string) (jsonschema.ResolvedSchema, error) {
+	base, err := c.run.resolver.Resolve(keywords.FragmentRef(c.pointer), c.document.uri)
+	if err != nil {
+		return jsonschema.ResolvedSchema{}, fmt.Errorf("cannot locate %s#%s: %w: %w", c.document.uri, c.pointer, err, ErrLinter)
+	}
+
+	resolved, err := c.run.resolver.Resolve(ref, base.BaseURI())
+	if err != nil {
+		return jsonschema.ResolvedSchema{}, fmt.Errorf("cannot resolve %q: %w: %w", ref, err, ErrLinter)
+	}
+
+	return resolved, nil
+}
+
+// Report a finding about the schema.
+//
+// Tokens locate the finding inside the schema, e.g. "required" and an index in "required".
+func (c *Context) Report(message string, tokens ...string) {
+	pointer := c.pointer
+	for _, token := range tokens {
+		if token != "" {
+			pointer += "/" + json.EscapeToken(token)
+		}
+	}
+
+	if c.isIgnored() {
+		c.run.suppressed++
+
+		return
+	}
+
+	c.run.findings = append(c.run.findings, Finding{
+		RuleID:   c.ruleID,
+		Severity: c.severity,
+		Index:    c.document.index,
+		URI:      c.document.uri,
+		Pointer:  pointer,
+		Source:   c.document.source(pointer),
+		Message:  message,
+	})
+}
+
+func (c *Context) isIgnored() bool {
+	if c.ignored == nil {
+		return false
+	}
+
+	_, all := c.ignored[""]
+	_, ignored := c.ignored[c.ruleID]
+
+	return all || ignored
+}
@@ -0,0 +1,20 @@
+// Package linter checks a collection of JSON schemas for problems that a meta-schema validation does not catch.
+//
+// A [Linter] walks every schema and subschema and applies a set of [Rule] s. Built-in rules (see [DefaultRules])
+// report:
+//
+//   - "$ref" s which cannot be resolved
+//   - "required" properties which are not defined
+//   - "default" and "examples" values which are invalid against their own schema
+//   - contradictory bounds, such as "minimum" > "maximum"
+//   - unsatisfiable schemas
+//   - keywords ignored by the declared version of JSON schema, e.g. siblings of "$ref" before draft 2019
+//   - unknown formats, i.e. neither standard nor supported by a [registries.Registry]
+//   - missing descriptions
+//
+// Rules may be disabled, their [Severity] may be overridden, and custom rules may be added (see [Option]).
+//
+// Every [Finding] carries the ID of its rule, a [Severity] and a JSON pointer to the offending schema or keyword.
+//
+// Findings are suppressed in a schema and its subschemas by the "x-lint-ignore" extension (see [IgnoreExtension]).
+package linter
@@ -0,0 +1,13 @@
+package linter
+
+// Error is an error raised by the [Linter].
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrLinter is the generic error raised by this package.
+	ErrLinter Error = "linter error"
+)
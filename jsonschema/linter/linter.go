@@ -0,0 +1,223 @@
+package linter
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/strfmt/registries"
+)
+
+// IgnoreExtension is the extension that suppresses findings in a schema and its subschemas.
+//
+// Its value is either true to suppress all findings, a rule ID or an array of rule IDs, e.g.:
+//
+//	{"type": "string", "format": "x-uuid", "x-lint-ignore": ["unknown-format"]}
+const IgnoreExtension = "x-lint-ignore"
+
+// Linter checks a collection of JSON schemas against a set of [Rule] s.
+type Linter struct {
+	*options
+}
+
+// New [Linter] with the [DefaultRules], unless some other rules are specified with [WithRules].
+func New(opts ...Option) *Linter {
+	return &Linter{
+		options: optionsWithDefaults(opts),
+	}
+}
+
+// Lint a single [jsonschema.Schema].
+func (l *Linter) Lint(sch jsonschema.Schema) (Result, error) {
+	c := jsonschema.MakeCollection(1)
+	c.Append(sch)
+
+	return l.LintCollection(c)
+}
+
+// LintCollection lints a [jsonschema.Collection] of schemas.
+//
+// Findings are reported in the order of the schemas in the collection. Every schema is checked by all rules
+// before its subschemas.
+//
+// It fails with an error wrapping [ErrLinter] only if the schemas cannot be registered for resolving "$ref" s.
+// Problems found in schemas are reported as [Finding] s.
+func (l *Linter) LintCollection(c jsonschema.Collection) (Result, error) {
+	r := l.newRun()
+
+	documents := make([]*document, 0, c.Len())
+	for sch := range c.Schemas() {
+		uri, err := l.documentURI(len(documents), c.Len())
+		if err != nil {
+			return Result{}, err
+		}
+
+		if err := r.resolver.AddDocument(uri, sch); err != nil {
+			return Result{}, fmt.Errorf("%w: %w", err, ErrLinter)
+		}
+
+		version := sch.Version()
+		if version == jsonschema.VersionUndefined {
+			version = l.version
+		}
+
+		documents = append(documents, &document{index: len(documents), uri: uri, schema: sch, version: version})
+	}
+
+	for _, doc := range documents {
+		r.walk(doc, doc.schema.Document, "", "", "", nil)
+	}
+
+	return Result{findings: r.findings, suppressed: r.suppressed}, nil
+}
+
+// documentURI locates the schema at index i in a collection of n schemas.
+func (l *Linter) documentURI(i, n int) (string, error) {
+	const single = "schema.json"
+
+	if n == 1 {
+		if l.baseURI == "" {
+			return single, nil
+		}
+
+		return l.baseURI, nil
+	}
+
+	name := "schema-" + strconv.Itoa(i) + ".json"
+	if l.baseURI == "" {
+		return name, nil
+	}
+
+	base, err := url.Parse(l.baseURI)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URI %q: %w: %w", l.baseURI, err, ErrLinter)
+	}
+
+	return base.ResolveReference(&url.URL{Path: name}).String(), nil
+}
+
+// document is a linted schema.
+type document struct {
+	index   int
+	uri     string
+	schema  jsonschema.Schema
+	version jsonschema.Version
+}
+
+// source of a value at some JSON pointer in the document, if the document has been loaded from a known source.
+func (d *document) source(pointer string) jsonschema.Source {
+	source := d.schema.Source()
+	if source.IsZero() {
+		return source
+	}
+
+	return source.At(pointer)
+}
+
+type checked struct {
+	rule     Rule
+	severity Severity
+}
+
+// run holds the state of a linting run.
+type run struct {
+	rules      []checked
+	resolver   *jsonschema.Resolver
+	formats    registries.Registry
+	findings   []Finding
+	suppressed int
+}
+
+func (l *Linter) newRun() *run {
+	r := &run{
+		resolver: l.resolver,
+		formats:  l.formats,
+	}
+
+	if r.resolver == nil {
+		r.resolver = jsonschema.NewResolver()
+	}
+
+	for _, rule := range l.rules {
+		if _, disabled := l.disabled[rule.ID()]; disabled {
+			continue
+		}
+
+		severity, overridden := l.severities[rule.ID()]
+		if !overridden {
+			severity = rule.Severity()
+		}
+
+		if severity == SeverityNone {
+			continue
+		}
+
+		r.rules = append(r.rules, checked{rule: rule, severity: severity})
+	}
+
+	return r
+}
+
+// walk a schema and its subschemas, applying all rules.
+func (r *run) walk(doc *document, schema json.Document, pointer, keyword, name string, ignored map[string]struct{}) {
+	if !schema.IsObject() {
+		return
+	}
+
+	ignored = ignoredRules(schema, ignored)
+
+	c := &Context{
+		run:      r,
+		document: doc,
+		schema:   schema,
+		pointer:  pointer,
+		keyword:  keyword,
+		name:     name,
+		ignored:  ignored,
+	}
+
+	for _, rule := range r.rules {
+		c.ruleID = rule.rule.ID()
+		c.severity = rule.severity
+		rule.rule.Check(c)
+	}
+
+	for sub := range jsonschema.Subschemas(schema, doc.version) {
+		r.walk(doc, sub.Schema, pointer+sub.Pointer(), sub.Keyword, sub.Name, ignored)
+	}
+}
+
+// ignoredRules merges the rules suppressed by the "x-lint-ignore" extension of a schema with those suppressed by
+// its parents. All rules are suppressed with the empty ID.
+func ignoredRules(schema json.Document, inherited map[string]struct{}) map[string]struct{} {
+	value, ok := schema.AtKey(IgnoreExtension)
+	if !ok {
+		return inherited
+	}
+
+	ignored := make(map[string]struct{}, len(inherited)+1)
+	for id := range inherited {
+		ignored[id] = struct{}{}
+	}
+
+	switch {
+	case value.IsBool():
+		if v, _ := value.Value(); v.Bool() {
+			ignored[""] = struct{}{}
+		}
+	case value.IsString():
+		v, _ := value.Value()
+		ignored[v.String()] = struct{}{}
+	case value.IsArray():
+		for elem := range value.Elems() {
+			if elem.IsString() {
+				v, _ := elem.Value()
+				ignored[v.String()] = struct{}{}
+			}
+		}
+	}
+
+	return ignored
+}
@@ -0,0 +1,305 @@
+package linter
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/strfmt"
+)
+
+func TestLinter(t *testing.T) {
+	t.Run("should report findings of built-in rules", func(t *testing.T) {
+		for _, tc := range []struct {
+			title    string
+			rule     string
+			input    string
+			expected []string // pointers
+		}{
+			{
+				title:    "unresolved $ref",
+				rule:     RuleUnresolvedRef,
+				input:    `{"properties": {"a": {"$ref": "#/$defs/missing"}, "b": {"$ref": "#/$defs/b"}}, "$defs": {"b": {}}}`,
+				expected: []string{"/properties/a/$ref"},
+			},
+			{
+				title: "required properties not defined",
+				rule:  RuleRequiredUndefined,
+				input: `{
+					"properties": {"a": {}},
+					"patternProperties": {"^x-": {}},
+					"allOf": [{"$ref": "#/$defs/base"}],
+					"required": ["a", "b", "x-c", "d"],
+					"$defs": {"base": {"properties": {"d": {}}}}
+				}`,
+				expected: []string{"/required/1"},
+			},
+			{
+				title:    "required properties without defined properties",
+				rule:     RuleRequiredUndefined,
+				input:    `{"required": ["a"]}`,
+				expected: nil,
+			},
+			{
+				title: "invalid default",
+				rule:  RuleInvalidDefault,
+				input: `{
+					"properties": {
+						"a": {"type": "integer", "minimum": 3, "default": 1},
+						"b": {"type": "integer", "minimum": 3, "default": 4},
+						"c": {"$ref": "#/$defs/c", "default": "x"},
+						"d": {"type": "string", "pattern": "^[a-z]+$", "default": "ABC"}
+					},
+					"$defs": {"c": {"type": "string", "maxLength": 0}}
+				}`,
+				expected: []string{"/properties/a/default", "/properties/c/default", "/properties/d/default"},
+			},
+			{
+				title:    "invalid examples",
+				rule:     RuleInvalidExamples,
+				input:    `{"type": "string", "enum": ["a", "b"], "examples": ["a", "c"]}`,
+				expected: []string{"/examples/1"},
+			},
+			{
+				title: "contradictory bounds",
+				rule:  RuleContradictoryBounds,
+				input: `{
+					"properties": {
+						"a": {"minimum": 5, "maximum": 2},
+						"b": {"minimum": 2, "exclusiveMaximum": 2},
+						"c": {"minLength": 3, "maxLength": 2},
+						"d": {"minimum": 2, "maximum": 2},
+						"e": {"required": ["x", "y"], "maxProperties": 1}
+					}
+				}`,
+				expected: []string{"/properties/a/minimum", "/properties/b/minimum", "/properties/c/minLength", "/properties/e/required"},
+			},
+			{
+				title: "draft 4 exclusive bounds",
+				rule:  RuleContradictoryBounds,
+				input: `{
+					"$schema": "http://json-schema.org/draft-04/schema#",
+					"minimum": 2, "maximum": 2, "exclusiveMaximum": true
+				}`,
+				expected: []string{"/minimum"},
+			},
+			{
+				title: "unsatisfiable schemas",
+				rule:  RuleUnsatisfiable,
+				input: `{
+					"properties": {
+						"a": {"type": "string", "enum": [1, 2]},
+						"b": {"type": "string", "allOf": [{"type": "integer"}]},
+						"c": {"type": "integer", "minimum": 5, "maximum": 2},
+						"d": {"not": {}},
+						"e": {"type": "object", "required": ["x"], "additionalProperties": false},
+						"f": {"type": ["integer", "string"], "minimum": 5, "maximum": 2},
+						"g": {"type": "number", "allOf": [{"type": "integer"}], "const": 1},
+						"h": {"allOf": [{"type": "string"}, {"type": "integer"}]},
+						"i": {"allOf": [{"type": "number"}, {"type": ["integer", "null"]}]}
+					}
+				}`,
+				expected: []string{
+					"/properties/a", "/properties/b", "/properties/c", "/properties/d", "/properties/e", "/properties/h",
+				},
+			},
+			{
+				title: "keywords next to $ref before draft 2019",
+				rule:  RuleIgnoredKeyword,
+				input: `{
+					"$schema": "http://json-schema.org/draft-07/schema#",
+					"properties": {"a": {"$ref": "#/definitions/a", "maxLength": 3}},
+					"definitions": {"a": {"type": "string", "dependentRequired": {}}}
+				}`,
+				expected: []string{"/properties/a/maxLength", "/definitions/a/dependentRequired"},
+			},
+			{
+				title: "keywords next to $ref since draft 2019",
+				rule:  RuleIgnoredKeyword,
+				input: `{
+					"$schema": "https://json-schema.org/draft/2020-12/schema",
+					"properties": {"a": {"$ref": "#/$defs/a", "maxLength": 3}},
+					"$defs": {"a": {"type": "string"}}
+				}`,
+				expected: nil,
+			},
+			{
+				title:    "unknown formats",
+				rule:     RuleUnknownFormat,
+				input:    `{"properties": {"a": {"format": "date-time"}, "b": {"format": "int64"}, "c": {"format": "zip-code"}}}`,
+				expected: []string{"/properties/c/format"},
+			},
+			{
+				title:    "missing descriptions",
+				rule:     RuleMissingDescription,
+				input:    `{"properties": {"a": {"description": "a"}, "b": {"type": "string"}, "c": {"$ref": "#/$defs/c"}}, "$defs": {"c": {}}}`,
+				expected: []string{"", "/properties/b", "/$defs/c"},
+			},
+		} {
+			t.Run(tc.title, func(t *testing.T) {
+				result, err := New(WithBaseURI("https://example.com/schema.json")).Lint(mustSchema(t, tc.input))
+				require.NoError(t, err)
+
+				assert.Equal(t, tc.expected, pointers(result, tc.rule))
+			})
+		}
+	})
+
+	t.Run("should validate default values with formats", func(t *testing.T) {
+		const input = `{"properties": {
+			"a": {"format": "zip-code", "default": "7500"},
+			"b": {"format": "zip-code", "default": "75001"}
+		}}`
+
+		result, err := New(WithFormats(zipCodes{})).Lint(mustSchema(t, input))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/properties/a/default"}, pointers(result, RuleInvalidDefault))
+
+		for finding := range result.Findings() {
+			if finding.RuleID == RuleInvalidDefault {
+				assert.Contains(t, finding.Message, `"format"`)
+			}
+		}
+	})
+
+	t.Run("should carry rule, severity and location", func(t *testing.T) {
+		result, err := New().Lint(mustSchema(t, `{"description": "d", "minLength": 2, "maxLength": 1}`))
+		require.NoError(t, err)
+
+		findings := slices.Collect(result.Findings())
+		require.Len(t, findings, 1)
+		assert.Equal(t, Finding{
+			RuleID:   RuleContradictoryBounds,
+			Severity: SeverityError,
+			URI:      "schema.json",
+			Pointer:  "/minLength",
+			Message:  `"minLength" (2) is greater than "maxLength" (1)`,
+		}, findings[0])
+		assert.Equal(t, SeverityError, result.MaxSeverity())
+	})
+
+	t.Run("should suppress findings with x-lint-ignore", func(t *testing.T) {
+		const input = `{
+			"description": "d",
+			"properties": {
+				"a": {"description": "a", "format": "zip-code", "x-lint-ignore": "unknown-format"},
+				"b": {
+					"x-lint-ignore": true,
+					"properties": {"c": {"format": "zip-code", "minimum": 2, "maximum": 1}}
+				},
+				"d": {"description": "d", "format": "zip-code", "x-lint-ignore": ["missing-description"]}
+			}
+		}`
+
+		result, err := New().Lint(mustSchema(t, input))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"/properties/d/format"}, pointers(result, ""))
+		assert.Equal(t, 5, result.Suppressed())
+	})
+
+	t.Run("should configure rules", func(t *testing.T) {
+		const input = `{"format": "zip-code", "minimum": 2, "maximum": 1}`
+
+		result, err := New(
+			WithDisabledRules(RuleMissingDescription),
+			WithSeverity(RuleUnknownFormat, SeverityError),
+			WithSeverity(RuleUnsatisfiable, SeverityNone),
+			WithCustomRules(NewRule("no-format", "formats are not used", SeverityInfo, func(c *Context) {
+				if _, ok := c.Schema().AtKey("format"); ok {
+					c.Report("format is used", "format")
+				}
+			})),
+		).Lint(mustSchema(t, input))
+		require.NoError(t, err)
+
+		var rules []string
+		for finding := range result.Findings(WithOrderBySeverityDesc(true)) {
+			rules = append(rules, finding.RuleID+":"+finding.Severity.String())
+		}
+		assert.Equal(t, []string{"contradictory-bounds:error", "unknown-format:error", "no-format:info"}, rules)
+
+		assert.Equal(t, 2, len(slices.Collect(result.Findings(WithSeverityThreshold(SeverityWarning)))))
+	})
+
+	t.Run("should lint a collection", func(t *testing.T) {
+		c := jsonschema.MakeCollection(2)
+		c.Append(mustSchema(t, `{"description": "d", "$ref": "schema-1.json#/$defs/a"}`))
+		c.Append(mustSchema(t, `{"description": "d", "$defs": {"a": {"description": "a"}}, "$ref": "schema-0.json#/missing"}`))
+
+		result, err := New(WithBaseURI("https://example.com/")).LintCollection(c)
+		require.NoError(t, err)
+
+		findings := slices.Collect(result.Findings())
+		require.Len(t, findings, 1)
+		assert.Equal(t, 1, findings[0].Index)
+		assert.Equal(t, "https://example.com/schema-1.json", findings[0].URI)
+		assert.Equal(t, RuleUnresolvedRef, findings[0].RuleID)
+		assert.True(t, findings[0].Source.IsZero())
+	})
+
+	t.Run("should locate findings in their source", func(t *testing.T) {
+		c := jsonschema.MakeCollection(2)
+		require.NoError(t, c.DecodeAppend(strings.NewReader(`[
+			{"description": "d"},
+			{"description": "d", "properties": {"id": {"description": "id", "minimum": 2, "maximum": 1}}}
+		]`)))
+
+		result, err := New().LintCollection(c)
+		require.NoError(t, err)
+
+		findings := slices.Collect(result.Findings())
+		require.Len(t, findings, 1)
+		assert.Equal(t, jsonschema.Source{Pointer: "/1/properties/id/minimum"}, findings[0].Source)
+		assert.Contains(t, findings[0].String(), "schema-1.json#/properties/id/minimum (#/1/properties/id/minimum)")
+	})
+}
+
+func TestParseSeverity(t *testing.T) {
+	severity, err := ParseSeverity("warning")
+	require.NoError(t, err)
+	assert.Equal(t, SeverityWarning, severity)
+
+	_, err = ParseSeverity("fatal")
+	require.ErrorIs(t, err, ErrLinter)
+}
+
+func pointers(result Result, rule string) []string {
+	var found []string
+	for finding := range result.Findings() {
+		if rule == "" || finding.RuleID == rule {
+			found = append(found, finding.Pointer)
+		}
+	}
+
+	return found
+}
+
+func mustSchema(t *testing.T, input string) jsonschema.Schema {
+	t.Helper()
+
+	s := jsonschema.Make()
+	require.NoError(t, s.UnmarshalJSON([]byte(input)))
+
+	return s
+}
+
+// zipCodes is a registry of string formats that supports 5-digit "zip-code" s.
+type zipCodes struct{}
+
+func (zipCodes) Parse(string, string) (strfmt.Format, error) { return nil, nil }
+
+func (zipCodes) Validate(_ string, value string) error {
+	if len(value) != 5 || strings.Trim(value, "0123456789") != "" {
+		return errors.New("not a zip code")
+	}
+
+	return nil
+}
+
+func (zipCodes) SupportedFormats() []string { return []string{"zip-code"} }
@@ -0,0 +1,100 @@
+package linter
+
+import (
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/strfmt/registries"
+)
+
+// Option customizes the behavior of the [Linter].
+type Option func(*options)
+
+type options struct {
+	rules      []Rule
+	disabled   map[string]struct{}
+	severities map[string]Severity
+	resolver   *jsonschema.Resolver
+	baseURI    string
+	version    jsonschema.Version
+	formats    registries.Registry
+}
+
+// WithRules replaces the [DefaultRules] by another set of [Rule] s.
+func WithRules(rules ...Rule) Option {
+	return func(o *options) {
+		o.rules = rules
+	}
+}
+
+// WithCustomRules adds custom [Rule] s to the rule set.
+func WithCustomRules(rules ...Rule) Option {
+	return func(o *options) {
+		o.rules = append(o.rules, rules...)
+	}
+}
+
+// WithDisabledRules disables [Rule] s, given their ID.
+func WithDisabledRules(ids ...string) Option {
+	return func(o *options) {
+		for _, id := range ids {
+			o.disabled[id] = struct{}{}
+		}
+	}
+}
+
+// WithSeverity overrides the [Severity] of the findings of a [Rule].
+//
+// [SeverityNone] disables the rule.
+func WithSeverity(id string, severity Severity) Option {
+	return func(o *options) {
+		o.severities[id] = severity
+	}
+}
+
+// WithResolver equips the [Linter] with a [jsonschema.Resolver] to resolve "$ref" s.
+//
+// By default, a new [jsonschema.Resolver] is used for every linted collection.
+func WithResolver(resolver *jsonschema.Resolver) Option {
+	return func(o *options) {
+		o.resolver = resolver
+	}
+}
+
+// WithBaseURI sets the URI of the linted schema, against which relative "$ref" s are resolved.
+//
+// When linting several schemas, they are located as "schema-{index}.json" relative to this URI.
+func WithBaseURI(uri string) Option {
+	return func(o *options) {
+		o.baseURI = uri
+	}
+}
+
+// WithVersion sets the version of JSON schema for the schemas that don't declare a meta-schema.
+//
+// By default, rules that depend on the version, such as [RuleIgnoredKeyword], don't apply to such schemas.
+func WithVersion(version jsonschema.Version) Option {
+	return func(o *options) {
+		o.version = version
+	}
+}
+
+// WithFormats declares the string formats supported by a registry, in addition to the formats defined by
+// JSON schema and OpenAPI.
+func WithFormats(registry registries.Registry) Option {
+	return func(o *options) {
+		o.formats = registry
+	}
+}
+
+func optionsWithDefaults(opts []Option) *options {
+	o := &options{
+		rules:      DefaultRules(),
+		disabled:   make(map[string]struct{}),
+		severities: make(map[string]Severity),
+	}
+
+	for _, apply := range opts {
+		apply(o)
+	}
+
+	return o
+}
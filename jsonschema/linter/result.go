@@ -0,0 +1,122 @@
+package linter
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+
+	"github.com/fredbi/core/jsonschema"
+)
+
+// Finding is a problem found by a [Rule] in a schema.
+type Finding struct {
+	// RuleID is the ID of the [Rule] that reported the finding, e.g. "unresolved-ref"
+	RuleID string
+
+	// Severity of the finding
+	Severity Severity
+
+	// Index of the schema in the linted collection
+	Index int
+
+	// URI of the linted schema document
+	URI string
+
+	// Pointer is the JSON pointer to the offending schema or keyword, e.g. "/properties/id/required/0"
+	Pointer string
+
+	// Source of the offending schema or keyword, if the linted schema has been loaded from a known source
+	// (see [jsonschema.Schema.Source])
+	Source jsonschema.Source
+
+	// Message explains the problem, in plain English
+	Message string
+}
+
+func (f Finding) String() string {
+	location := f.URI + "#" + f.Pointer
+	if !f.Source.IsZero() {
+		location += " (" + f.Source.String() + ")"
+	}
+
+	return f.Severity.String() + ": " + location + ": " + f.Message + " [" + f.RuleID + "]"
+}
+
+// Result of linting a collection of schemas.
+type Result struct {
+	findings   []Finding
+	suppressed int
+}
+
+// Len is the number of findings.
+func (r Result) Len() int {
+	return len(r.findings)
+}
+
+// Suppressed is the number of findings suppressed by an "x-lint-ignore" extension.
+func (r Result) Suppressed() int {
+	return r.suppressed
+}
+
+// MaxSeverity is the highest [Severity] of all findings, or [SeverityNone] if there is no finding.
+//
+// A CI pipeline may for instance reject schemas with findings of [SeverityError].
+func (r Result) MaxSeverity() Severity {
+	var highest Severity
+	for _, finding := range r.findings {
+		highest = max(highest, finding.Severity)
+	}
+
+	return highest
+}
+
+type FindingsOption func(*findingsOptions)
+
+type findingsOptions struct {
+	orderBySeverityDesc     bool
+	filterSeverityThreshold Severity
+}
+
+// WithOrderBySeverityDesc yields findings with the highest severity first.
+//
+// Findings with the same severity are yielded in the order they have been found.
+func WithOrderBySeverityDesc(enabled bool) FindingsOption {
+	return func(o *findingsOptions) {
+		o.orderBySeverityDesc = enabled
+	}
+}
+
+// WithSeverityThreshold only yields findings with a [Severity] greater than or equal to minSeverity.
+func WithSeverityThreshold(minSeverity Severity) FindingsOption {
+	return func(o *findingsOptions) {
+		o.filterSeverityThreshold = minSeverity
+	}
+}
+
+// Findings yields the findings, by default in the order they have been found.
+func (r Result) Findings(opts ...FindingsOption) iter.Seq[Finding] {
+	return slices.Values(r.findingsWithOptions(opts))
+}
+
+func (r Result) findingsWithOptions(opts []FindingsOption) []Finding {
+	if len(opts) == 0 {
+		return r.findings
+	}
+
+	var o findingsOptions
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	findings := slices.DeleteFunc(slices.Clone(r.findings), func(f Finding) bool {
+		return f.Severity.Less(o.filterSeverityThreshold)
+	})
+
+	if o.orderBySeverityDesc {
+		slices.SortStableFunc(findings, func(a, b Finding) int {
+			return cmp.Compare(b.Severity, a.Severity)
+		})
+	}
+
+	return findings
+}
@@ -0,0 +1,88 @@
+package linter
+
+// Rule checks every schema of a collection, and reports findings.
+//
+// Custom rules may be added to the [Linter] with [WithCustomRules]. They are conveniently built with [NewRule].
+type Rule interface {
+	// ID uniquely identifies the rule, e.g. "unresolved-ref"
+	ID() string
+
+	// Description of the rule, in plain English
+	Description() string
+
+	// Severity of the findings reported by the rule, unless overridden with [WithSeverity]
+	Severity() Severity
+
+	// Check a schema. Findings are reported with [Context.Report]
+	Check(*Context)
+}
+
+// IDs of the built-in rules.
+const (
+	// RuleUnresolvedRef reports "$ref" s and "$dynamicRef" s which cannot be resolved
+	RuleUnresolvedRef = "unresolved-ref"
+	// RuleRequiredUndefined reports "required" properties which are not defined by "properties"
+	RuleRequiredUndefined = "required-undefined"
+	// RuleInvalidDefault reports "default" values which are invalid against their schema
+	RuleInvalidDefault = "invalid-default"
+	// RuleInvalidExamples reports "examples" (or OpenAPI "example") which are invalid against their schema
+	RuleInvalidExamples = "invalid-examples"
+	// RuleContradictoryBounds reports lower bounds which exceed upper bounds, e.g. "minimum" > "maximum"
+	RuleContradictoryBounds = "contradictory-bounds"
+	// RuleUnsatisfiable reports schemas which no value can satisfy
+	RuleUnsatisfiable = "unsatisfiable"
+	// RuleIgnoredKeyword reports keywords which are ignored by the declared version of JSON schema
+	RuleIgnoredKeyword = "ignored-keyword"
+	// RuleUnknownFormat reports formats which are neither standard nor supported by the formats registry
+	RuleUnknownFormat = "unknown-format"
+	// RuleMissingDescription reports root schemas, definitions and properties without a "description"
+	RuleMissingDescription = "missing-description"
+)
+
+// DefaultRules yields the built-in rules.
+func DefaultRules() []Rule {
+	return []Rule{
+		NewRule(RuleUnresolvedRef, `"$ref" can be resolved`, SeverityError, checkRefs),
+		NewRule(RuleRequiredUndefined, `"required" properties are defined`, SeverityWarning, checkRequired),
+		NewRule(RuleInvalidDefault, `"default" is valid against its schema`, SeverityError, checkDefault),
+		NewRule(RuleInvalidExamples, `"examples" are valid against their schema`, SeverityWarning, checkExamples),
+		NewRule(RuleContradictoryBounds, "lower bounds don't exceed upper bounds", SeverityError, checkBounds),
+		NewRule(RuleUnsatisfiable, "schemas may be satisfied", SeverityError, checkSatisfiable),
+		NewRule(RuleIgnoredKeyword, "keywords are supported by the version of JSON schema", SeverityWarning, checkKeywords),
+		NewRule(RuleUnknownFormat, `"format" is known`, SeverityWarning, checkFormat),
+		NewRule(RuleMissingDescription, `schemas have a "description"`, SeverityInfo, checkDescription),
+	}
+}
+
+// NewRule builds a [Rule] from a function.
+func NewRule(id, description string, severity Severity, check func(*Context)) Rule {
+	return rule{
+		id:          id,
+		description: description,
+		severity:    severity,
+		check:       check,
+	}
+}
+
+type rule struct {
+	id          string
+	description string
+	severity    Severity
+	check       func(*Context)
+}
+
+func (r rule) ID() string {
+	return r.id
+}
+
+func (r rule) Description() string {
+	return r.description
+}
+
+func (r rule) Severity() Severity {
+	return r.severity
+}
+
+func (r rule) Check(c *Context) {
+	r.check(c)
+}
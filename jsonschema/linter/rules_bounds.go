@@ -0,0 +1,316 @@
+package linter
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+//nolint:gochecknoglobals // pairs of lower and upper bounds on sizes
+var sizeBounds = [][2]string{
+	{"minLength", "maxLength"},
+	{"minItems", "maxItems"},
+	{"minProperties", "maxProperties"},
+	{"minContains", "maxContains"},
+}
+
+// checkBounds reports lower bounds which exceed upper bounds.
+func checkBounds(c *Context) {
+	schema := c.Schema()
+
+	for _, bounds := range sizeBounds {
+		lower, hasLower := keywords.RatAt(schema, bounds[0])
+		upper, hasUpper := keywords.RatAt(schema, bounds[1])
+		if hasLower && hasUpper && lower.Cmp(upper) > 0 {
+			c.Report(fmt.Sprintf("%q (%s) is greater than %q (%s)",
+				bounds[0], lower.RatString(), bounds[1], upper.RatString(),
+			), bounds[0])
+		}
+	}
+
+	if lower, upper, ok := numberBounds(schema); ok && lower.excludes(upper) {
+		c.Report(fmt.Sprintf("lower bound %q (%s) excludes upper bound %q (%s)",
+			lower.keyword, lower.value.RatString(), upper.keyword, upper.value.RatString(),
+		), lower.keyword)
+	}
+
+	if maxProperties, ok := keywords.RatAt(schema, "maxProperties"); ok {
+		if required := requiredNames(schema); big.NewRat(int64(len(required)), 1).Cmp(maxProperties) > 0 {
+			c.Report(fmt.Sprintf(`%d properties are "required", but "maxProperties" is %s`,
+				len(required), maxProperties.RatString(),
+			), "required")
+		}
+	}
+}
+
+// bound on numbers.
+type bound struct {
+	keyword   string
+	value     *big.Rat
+	exclusive bool
+}
+
+// excludes tells if the lower bound b excludes the upper bound.
+func (b bound) excludes(upper bound) bool {
+	cmp := b.value.Cmp(upper.value)
+
+	return cmp > 0 || (cmp == 0 && (b.exclusive || upper.exclusive))
+}
+
+// numberBounds yields the tightest lower and upper bounds on numbers.
+//
+// Draft 4 schemas make "minimum" and "maximum" exclusive with a boolean "exclusiveMinimum" or "exclusiveMaximum".
+func numberBounds(schema json.Document) (lower, upper bound, ok bool) {
+	tighter := func(current bound, keyword string, exclusive bool, sign int) bound {
+		value, found := keywords.RatAt(schema, keyword)
+		if !found {
+			return current
+		}
+
+		candidate := bound{keyword: keyword, value: value, exclusive: exclusive}
+		if current.value == nil {
+			return candidate
+		}
+
+		if cmp := value.Cmp(current.value) * sign; cmp > 0 || (cmp == 0 && exclusive) {
+			return candidate
+		}
+
+		return current
+	}
+
+	lower = tighter(lower, "minimum", keywords.IsTrue(schema, "exclusiveMinimum"), 1)
+	lower = tighter(lower, "exclusiveMinimum", true, 1)
+	upper = tighter(upper, "maximum", keywords.IsTrue(schema, "exclusiveMaximum"), -1)
+	upper = tighter(upper, "exclusiveMaximum", true, -1)
+
+	return lower, upper, lower.value != nil && upper.value != nil
+}
+
+func requiredNames(schema json.Document) []string {
+	required, ok := schema.AtKey("required")
+	if !ok || !required.IsArray() {
+		return nil
+	}
+
+	names := make([]string, 0, required.Len())
+	for elem := range required.Elems() {
+		if elem.IsString() {
+			v, _ := elem.Value()
+			if name := v.String(); !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// checkSatisfiable reports schemas which no value can satisfy.
+//
+// Only contradictions local to a schema are detected, e.g. a "type" that excludes all values of the "enum",
+// members of an "allOf" with disjoint types, or bounds that exclude all values of the allowed types.
+func checkSatisfiable(c *Context) {
+	if reason := unsatisfiable(c, c.Schema()); reason != "" {
+		c.Report("no value satisfies this schema: " + reason)
+	}
+}
+
+func unsatisfiable(c *Context, schema json.Document) string {
+	if not, ok := schema.AtKey("not"); ok && acceptsAll(not) {
+		return `"not" rejects any value`
+	}
+
+	if enum, ok := schema.AtKey("enum"); ok && enum.IsArray() && enum.Len() == 0 {
+		return `"enum" is empty`
+	}
+
+	types := typesOf(schema)
+	if types != nil && len(types) == 0 {
+		return `"type" is empty`
+	}
+
+	if allOf, ok := schema.AtKey("allOf"); ok && allOf.IsArray() {
+		reason := `"type" and the types of the members of "allOf" are disjoint`
+		if types == nil {
+			reason = `the types of the members of "allOf" are disjoint`
+		}
+
+		for member := range allOf.Elems() {
+			memberTypes := typesOf(c.deref(member))
+			switch {
+			case memberTypes == nil:
+			case types == nil:
+				types = memberTypes
+			default:
+				types = intersectTypes(types, memberTypes)
+			}
+		}
+
+		if types != nil && len(types) == 0 {
+			return reason
+		}
+	}
+
+	if types == nil {
+		return ""
+	}
+
+	if values := enumValues(schema); len(values) > 0 && !slices.ContainsFunc(values, func(value json.Document) bool {
+		return hasSomeType(value, types)
+	}) {
+		return fmt.Sprintf(`no value of "enum" or "const" has type %s`, typeList(types))
+	}
+
+	for t := range types {
+		if !isEmptyType(c, schema, t) {
+			return ""
+		}
+	}
+
+	return fmt.Sprintf("the bounds exclude all values of type %s", typeList(types))
+}
+
+// acceptsAll tells if a schema is true or an empty object.
+func acceptsAll(schema json.Document) bool {
+	if schema.IsBool() {
+		v, _ := schema.Value()
+
+		return v.Bool()
+	}
+
+	return schema.IsObject() && schema.Len() == 0
+}
+
+func intersectTypes(types, others map[string]struct{}) map[string]struct{} {
+	intersection := make(map[string]struct{}, len(types))
+	for t := range types {
+		_, ok := others[t]
+		_, otherNumber := others["number"]
+		_, otherInteger := others["integer"]
+
+		switch {
+		case ok:
+			intersection[t] = struct{}{}
+		case t == "integer" && otherNumber, t == "number" && otherInteger:
+			intersection["integer"] = struct{}{}
+		}
+	}
+
+	return intersection
+}
+
+func enumValues(schema json.Document) []json.Document {
+	var values []json.Document
+	if enum, ok := schema.AtKey("enum"); ok && enum.IsArray() {
+		values = slices.Collect(enum.Elems())
+	}
+
+	if value, ok := schema.AtKey("const"); ok {
+		values = append(values, value)
+	}
+
+	return values
+}
+
+func hasSomeType(value json.Document, types map[string]struct{}) bool {
+	for t := range types {
+		if hasType(value, t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasType(value json.Document, t string) bool {
+	switch t {
+	case "null":
+		return value.IsNull()
+	case "boolean":
+		return value.IsBool()
+	case "string":
+		return value.IsString()
+	case "array":
+		return value.IsArray()
+	case "object":
+		return value.IsObject()
+	case "number":
+		return value.IsNumber()
+	case "integer":
+		if !value.IsNumber() {
+			return false
+		}
+
+		v, _ := value.Value()
+		r, ok := new(big.Rat).SetString(string(v.NumberValue().Value))
+
+		return ok && r.IsInt()
+	default:
+		return false
+	}
+}
+
+// isEmptyType tells if the bounds of a schema exclude all values of a type.
+func isEmptyType(c *Context, schema json.Document, t string) bool {
+	exceeds := func(lowerKeyword, upperKeyword string) bool {
+		lower, hasLower := keywords.RatAt(schema, lowerKeyword)
+		upper, hasUpper := keywords.RatAt(schema, upperKeyword)
+
+		return hasLower && hasUpper && lower.Cmp(upper) > 0
+	}
+
+	switch t {
+	case "number", "integer":
+		lower, upper, ok := numberBounds(schema)
+
+		return ok && lower.excludes(upper)
+	case "string":
+		return exceeds("minLength", "maxLength")
+	case "array":
+		return exceeds("minItems", "maxItems")
+	case "object":
+		return exceeds("minProperties", "maxProperties") || hasForbiddenRequired(c, schema)
+	default:
+		return false
+	}
+}
+
+// hasForbiddenRequired tells if a "required" property is forbidden, by a false schema or by a false
+// "additionalProperties".
+func hasForbiddenRequired(c *Context, schema json.Document) bool {
+	names := requiredNames(schema)
+	if maxProperties, ok := keywords.RatAt(schema, "maxProperties"); ok && big.NewRat(int64(len(names)), 1).Cmp(maxProperties) > 0 {
+		return true
+	}
+
+	defined := definedProperties(c, schema)
+	additional, hasAdditional := schema.AtKey("additionalProperties")
+	closed := hasAdditional && additional.IsBool() && !acceptsAll(additional)
+
+	for _, name := range names {
+		member, ok := defined.names[name]
+		switch {
+		case ok && member.IsBool() && !acceptsAll(member):
+			return true
+		case !ok && closed && !defined.has(name):
+			return true
+		}
+	}
+
+	return false
+}
+
+func typeList(types map[string]struct{}) string {
+	names := make([]string, 0, len(types))
+	for t := range types {
+		names = append(names, fmt.Sprintf("%q", t))
+	}
+	slices.Sort(names)
+
+	return strings.Join(names, " or ")
+}
@@ -0,0 +1,97 @@
+package linter
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// checkKeywords reports keywords which are ignored by the version of JSON schema of the document.
+//
+// Keywords may not be supported by this version. Before draft 2019, keywords next to a "$ref" are ignored.
+// Definitions are never reported, since they may be referred to by a JSON pointer.
+func checkKeywords(c *Context) {
+	version := c.Version()
+	if version == jsonschema.VersionUndefined {
+		return
+	}
+
+	_, hasRef := c.Schema().AtKey("$ref")
+	ignoresRefSiblings := hasRef && version.Less(jsonschema.VersionDraft2019)
+
+	for keyword := range c.Schema().Pairs() {
+		if slices.Contains(refSiblings, keyword) {
+			continue
+		}
+
+		requirements, isStandard := jsonschema.StandardKeyword(keyword)
+		switch {
+		case !isStandard:
+			continue
+		case !requirements.Allows(version):
+			c.Report(fmt.Sprintf("%q is not supported by JSON schema %v and is ignored", keyword, version), keyword)
+		case ignoresRefSiblings:
+			c.Report(fmt.Sprintf(`%q is ignored next to "$ref" with JSON schema %v`, keyword, version), keyword)
+		}
+	}
+}
+
+//nolint:gochecknoglobals // keywords which are not ignored next to a "$ref"
+var refSiblings = []string{"$ref", "$schema", "$id", "id", "$comment", "$defs", "definitions"}
+
+// checkFormat reports a "format" which is neither defined by JSON schema or OpenAPI,
+// nor supported by the formats registry.
+func checkFormat(c *Context) {
+	format, ok := keywords.StringAt(c.Schema(), "format")
+	if !ok {
+		return
+	}
+
+	if _, known := standardFormats[format]; known {
+		return
+	}
+
+	if registry := c.Formats(); registry != nil && slices.Contains(registry.SupportedFormats(), format) {
+		return
+	}
+
+	c.Report(fmt.Sprintf("unknown format %q", format), "format")
+}
+
+//nolint:gochecknoglobals // formats defined by all versions of JSON schema and OpenAPI
+var standardFormats = map[string]struct{}{
+	// JSON schema
+	"date-time": {}, "date": {}, "time": {}, "duration": {},
+	"email": {}, "idn-email": {}, "hostname": {}, "idn-hostname": {}, "ipv4": {}, "ipv6": {},
+	"uri": {}, "uri-reference": {}, "iri": {}, "iri-reference": {}, "uuid": {}, "uri-template": {},
+	"json-pointer": {}, "relative-json-pointer": {}, "regex": {},
+	// OpenAPI
+	"int32": {}, "int64": {}, "float": {}, "double": {}, "byte": {}, "binary": {}, "password": {},
+}
+
+// checkDescription reports root schemas, definitions and properties without a "description".
+//
+// Schemas with a "$ref" are not reported, since they are described by the referred schema.
+func checkDescription(c *Context) {
+	schema := c.Schema()
+	if _, ok := schema.AtKey("description"); ok {
+		return
+	}
+
+	if _, ok := schema.AtKey("$ref"); ok {
+		return
+	}
+
+	switch c.Keyword() {
+	case "":
+		if c.IsRoot() {
+			c.Report("the schema has no description")
+		}
+	case "properties":
+		c.Report(fmt.Sprintf("property %q has no description", c.Name()))
+	case "$defs", "definitions":
+		c.Report(fmt.Sprintf("definition %q has no description", c.Name()))
+	}
+}
@@ -0,0 +1,121 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// checkRefs reports "$ref" s and "$dynamicRef" s which cannot be resolved.
+func checkRefs(c *Context) {
+	for _, keyword := range []string{"$ref", "$dynamicRef"} {
+		ref, ok := keywords.StringAt(c.Schema(), keyword)
+		if !ok {
+			continue
+		}
+
+		if _, err := c.Resolve(ref); err != nil {
+			c.Report(fmt.Sprintf("cannot resolve %q", ref), keyword)
+		}
+	}
+}
+
+// checkRequired reports "required" properties which are not defined.
+//
+// Properties may be defined by "properties", "patternProperties" or by the members of an "allOf".
+// Schemas which don't define any property are not checked: their "required" properties are usually defined
+// by a sibling schema.
+func checkRequired(c *Context) {
+	required, ok := c.Schema().AtKey("required")
+	if !ok || !required.IsArray() {
+		return
+	}
+
+	defined := definedProperties(c, c.Schema())
+	if defined.isEmpty() {
+		return
+	}
+
+	for i, elem := range required.IndexedElems() {
+		if !elem.IsString() {
+			continue
+		}
+
+		v, _ := elem.Value()
+		if name := v.String(); !defined.has(name) {
+			c.Report(fmt.Sprintf(`required property %q is not defined in "properties"`, name), "required", strconv.Itoa(i))
+		}
+	}
+}
+
+// properties defined by a schema.
+type properties struct {
+	names    map[string]json.Document
+	patterns []*regexp.Regexp
+}
+
+func (p properties) isEmpty() bool {
+	return len(p.names) == 0 && len(p.patterns) == 0
+}
+
+func (p properties) has(name string) bool {
+	if _, ok := p.names[name]; ok {
+		return true
+	}
+
+	for _, pattern := range p.patterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// definedProperties collects the properties defined by a schema and by the members of its "allOf".
+func definedProperties(c *Context, schema json.Document) properties {
+	p := properties{names: make(map[string]json.Document)}
+
+	collect := func(s json.Document) {
+		for name, member := range namesOf(s, "properties") {
+			p.names[name] = member
+		}
+
+		for pattern := range namesOf(s, "patternProperties") {
+			if re, err := regexp.Compile(pattern); err == nil {
+				p.patterns = append(p.patterns, re)
+			}
+		}
+	}
+
+	collect(schema)
+
+	allOf, ok := schema.AtKey("allOf")
+	if !ok || !allOf.IsArray() {
+		return p
+	}
+
+	for member := range allOf.Elems() {
+		collect(c.deref(member))
+	}
+
+	return p
+}
+
+// deref follows the "$ref" of a subschema, if any.
+func (c *Context) deref(schema json.Document) json.Document {
+	ref, ok := keywords.StringAt(schema, "$ref")
+	if !ok {
+		return schema
+	}
+
+	resolved, err := c.Resolve(ref)
+	if err != nil {
+		return schema
+	}
+
+	return resolved.Document
+}
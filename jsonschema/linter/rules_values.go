@@ -0,0 +1,85 @@
+package linter
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+	"github.com/fredbi/core/jsonschema/validator"
+)
+
+// checkURI locates the schemas built to check values, against which the linted schemas are referenced.
+const checkURI = "lint-check.json"
+
+// checkDefault reports a "default" value which is invalid against its schema.
+func checkDefault(c *Context) {
+	value, ok := c.Schema().AtKey("default")
+	if !ok {
+		return
+	}
+
+	if reason, invalid := c.invalid(value); invalid {
+		c.Report(`"default" is invalid against its schema: `+reason, "default")
+	}
+}
+
+// checkExamples reports "examples", or an OpenAPI "example", which are invalid against their schema.
+func checkExamples(c *Context) {
+	if value, ok := c.Schema().AtKey("example"); ok {
+		if reason, invalid := c.invalid(value); invalid {
+			c.Report(`"example" is invalid against its schema: `+reason, "example")
+		}
+	}
+
+	examples, ok := c.Schema().AtKey("examples")
+	if !ok || !examples.IsArray() {
+		return
+	}
+
+	for i, value := range examples.IndexedElems() {
+		if reason, invalid := c.invalid(value); invalid {
+			c.Report(`"examples" has a value which is invalid against its schema: `+reason, "examples", strconv.Itoa(i))
+		}
+	}
+}
+
+// invalid tells if a value is invalid against the schema, with the reason.
+//
+// The value is validated against the schema {"$ref": "<location of the checked schema>"}.
+func (c *Context) invalid(value json.Document) (string, bool) {
+	ref, err := stdjson.Marshal(c.URI() + keywords.FragmentRef(c.Pointer()))
+	if err != nil {
+		return "", false
+	}
+
+	target := jsonschema.Make()
+	if err := target.UnmarshalJSON([]byte(`{"$ref":` + string(ref) + `}`)); err != nil {
+		return "", false
+	}
+
+	opts := []validator.Option{validator.WithResolver(c.run.resolver), validator.WithBaseURI(checkURI)}
+	if version := c.Version(); version != jsonschema.VersionUndefined {
+		opts = append(opts, validator.WithVersion(version))
+	}
+	if c.run.formats != nil {
+		opts = append(opts, validator.WithFormats(c.run.formats))
+	}
+
+	v, err := validator.New(target, opts...)
+	if err != nil {
+		return "", false
+	}
+
+	var violations validator.Violations
+	if !errors.As(v.Validate(value), &violations) || len(violations) == 0 {
+		return "", false
+	}
+
+	violation := violations[0]
+
+	return fmt.Sprintf("%q: %s", violation.Keyword, violation.Message), true
+}
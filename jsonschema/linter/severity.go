@@ -0,0 +1,44 @@
+package linter
+
+import "fmt"
+
+// Severity of a [Finding].
+type Severity uint8
+
+const (
+	// SeverityNone disables a [Rule] (see [WithSeverity]).
+	SeverityNone Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) Less(other Severity) bool {
+	return int(s) < int(other)
+}
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityNone:
+		fallthrough
+	default:
+		return "none"
+	}
+}
+
+// ParseSeverity parses the string representation of a [Severity], e.g. "warning".
+func ParseSeverity(s string) (Severity, error) {
+	for severity := SeverityNone; severity <= SeverityError; severity++ {
+		if severity.String() == s {
+			return severity, nil
+		}
+	}
+
+	return SeverityNone, fmt.Errorf("unknown severity %q: %w", s, ErrLinter)
+}
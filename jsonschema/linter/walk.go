@@ -0,0 +1,43 @@
+package linter
+
+import "github.com/fredbi/core/json"
+
+// typesOf a schema, or nil if "type" is not specified.
+func typesOf(schema json.Document) map[string]struct{} {
+	value, ok := schema.AtKey("type")
+	if !ok {
+		return nil
+	}
+
+	types := make(map[string]struct{})
+	if value.IsString() {
+		v, _ := value.Value()
+		types[v.String()] = struct{}{}
+
+		return types
+	}
+
+	for elem := range value.Elems() {
+		if elem.IsString() {
+			v, _ := elem.Value()
+			types[v.String()] = struct{}{}
+		}
+	}
+
+	return types
+}
+
+// namesOf the members of a keyword which value is an object, e.g. "properties".
+func namesOf(schema json.Document, keyword string) map[string]json.Document {
+	value, ok := schema.AtKey(keyword)
+	if !ok || !value.IsObject() {
+		return nil
+	}
+
+	names := make(map[string]json.Document, value.Len())
+	for name, member := range value.Pairs() {
+		names[name] = member
+	}
+
+	return names
+}
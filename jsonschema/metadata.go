@@ -24,6 +24,7 @@ import (
 //   - default
 //   - readOnly (>= draft 7)
 //   - writeOnly (>= draft 7)
+//   - deprecated (>= draft 2019)
 //
 // For OpenAPI schemas:
 //   - externalDocs
@@ -51,6 +52,7 @@ var (
 	defaultKey      = values.MakeInternedKey("default")
 	readOnlyKey     = values.MakeInternedKey("readOnly")
 	writeOnlyKey    = values.MakeInternedKey("writeOnly")
+	deprecatedKey   = values.MakeInternedKey("deprecated")
 	externalDocsKey = values.MakeInternedKey("externalDocs")
 	xmlKey          = values.MakeInternedKey("xml")
 
@@ -64,6 +66,7 @@ var (
 		defaultKey:      {},
 		readOnlyKey:     {},
 		writeOnlyKey:    {},
+		deprecatedKey:   {},
 		externalDocsKey: {},
 		xmlKey:          {},
 	}
@@ -74,6 +77,7 @@ var (
 		readOnlyKey:     {MinVersion: VersionDraft7, MinOAIVersion: VersionOpenAPIv2},
 		writeOnlyKey:    {MinVersion: VersionDraft7}, // TODO: check OAI version
 		vocabularyKey:   {MinVersion: VersionDraft2019},
+		deprecatedKey:   {MinVersion: VersionDraft2019},
 		exampleKey:      {MinOAIVersion: VersionOpenAPIv2},
 		externalDocsKey: {MinOAIVersion: VersionOpenAPIv2},
 		xmlKey:          {MinOAIVersion: VersionOpenAPIv2},
@@ -150,6 +154,6 @@ func (m Metadata) JSONComment() string {
 	return v.String()
 }
 
-func (m *Metadata) decode(ctx *light.ParentContext, key values.InternedKey, vr *VersionRequirements) error {
+func (m *Metadata) decode(_ *light.ParentContext, _ values.InternedKey, _ light.Node, _ *VersionRequirements) error {
 	return nil
 }
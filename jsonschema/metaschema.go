@@ -0,0 +1,581 @@
+package jsonschema
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/json/stores/values"
+)
+
+// MetaValidator validates [Schema] s against the meta-schema of their version of JSON schema.
+//
+// Schemas are checked natively, by rules equivalent to the published meta-schemas, rather than by evaluating
+// meta-schemas: keyword values must have the expected type and range (e.g. "minLength" is a non-negative integer,
+// "multipleOf" is strictly positive, "type" lists unique simple types), and all subschemas must be valid schemas.
+// Keywords that are not supported by the version of the schema are reported.
+//
+// Since draft 2019, a meta-schema declares the vocabularies of its dialect with "$vocabulary".
+// A schema that refers to a custom meta-schema is checked against the keywords of the vocabularies declared
+// by this meta-schema: keywords from other vocabularies are ignored, and the validation fails with [ErrVocabulary]
+// if the meta-schema requires a vocabulary which is not known by the [VocabularyRegistry] (see [WithMetaVocabularies]).
+//
+// The values of custom keywords are checked against the shape and the version requirements declared by their [Keyword].
+// Unknown keywords are ignored.
+type MetaValidator struct {
+	*metaOptions
+}
+
+// NewMetaValidator builds a [MetaValidator].
+func NewMetaValidator(opts ...MetaOption) *MetaValidator {
+	return &MetaValidator{
+		metaOptions: metaOptionsWithDefaults(opts),
+	}
+}
+
+// MetaViolation describes how a schema violates its meta-schema.
+type MetaViolation struct {
+	// Pointer is the JSON pointer to the violating schema.
+	Pointer string
+
+	// Keyword is the violating keyword, if any.
+	Keyword string
+
+	// Message explains the violation.
+	Message string
+}
+
+func (v MetaViolation) String() string {
+	if v.Keyword == "" {
+		return fmt.Sprintf("at %q: %s", v.Pointer, v.Message)
+	}
+
+	return fmt.Sprintf("%q at %q: %s", v.Keyword, v.Pointer, v.Message)
+}
+
+// MetaViolations lists the [MetaViolation] s of a schema.
+//
+// It is the error returned by [MetaValidator.Validate], and it wraps [ErrMetaSchema].
+type MetaViolations []MetaViolation
+
+func (v MetaViolations) Error() string {
+	messages := make([]string, 0, len(v))
+	for _, violation := range v {
+		messages = append(messages, violation.String())
+	}
+
+	return fmt.Sprintf("%v: %s", ErrMetaSchema, strings.Join(messages, "; "))
+}
+
+func (v MetaViolations) Unwrap() error {
+	return ErrMetaSchema
+}
+
+// Validate a [Schema] against its meta-schema.
+//
+// If the schema is invalid, the returned error is a [MetaViolations] that lists all violations.
+// Other errors, e.g. when the custom meta-schema of the schema cannot be resolved, wrap [ErrMetaSchema]
+// or [ErrVocabulary].
+func (v *MetaValidator) Validate(sch Schema) error {
+	d, err := v.dialectOf(sch)
+	if err != nil {
+		return err
+	}
+
+	var violations MetaViolations
+	d.schema(sch.Document, "", &violations)
+
+	if len(violations) > 0 {
+		return violations
+	}
+
+	return nil
+}
+
+// dialect knows about the keywords enabled for the schemas of some version and vocabularies.
+type dialect struct {
+	version    Version
+	dollarData bool                // "$data" references are allowed
+	standard   map[string]struct{} // enabled standard keywords. All are enabled when nil
+	custom     map[string]Keyword  // enabled custom keywords
+}
+
+// dialectOf determines the version and the vocabularies used by a schema.
+func (v *MetaValidator) dialectOf(sch Schema) (*dialect, error) {
+	d := &dialect{version: sch.Version(), dollarData: sch.UsesDollarData()}
+
+	metaURL, hasMeta := "", false
+	if dollarSchema, ok := sch.AtKey("$schema"); ok && dollarSchema.IsString() {
+		value, _ := dollarSchema.Value()
+		metaURL, hasMeta = value.String(), true
+	}
+
+	switch {
+	case d.version != VersionUndefined:
+		return d.withVocabularies(v.vocabularies, defaultVocabularies[d.version], nil), nil
+	case !hasMeta:
+		d.version = v.version
+
+		return d.withVocabularies(v.vocabularies, defaultVocabularies[d.version], nil), nil
+	}
+
+	// custom meta-schema
+	resolved, err := v.resolver.Resolve(metaURL, "")
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve the meta-schema %q: %w: %w", metaURL, err, ErrMetaSchema)
+	}
+
+	meta := resolved.Schema
+	d.version = meta.Version()
+	if d.version == VersionUndefined {
+		d.version = v.version
+	}
+
+	declared, ok := meta.AtKey("$vocabulary")
+	if !ok {
+		return d.withVocabularies(v.vocabularies, defaultVocabularies[d.version], nil), nil
+	}
+
+	if !declared.IsObject() {
+		return nil, fmt.Errorf(`"$vocabulary" in meta-schema %q must be an object: %w`, metaURL, ErrMetaSchema)
+	}
+
+	enabled := make([]string, 0, declared.Len())
+	for uri, required := range declared.Pairs() {
+		if _, known := v.vocabularies.Vocabulary(uri); known {
+			enabled = append(enabled, uri)
+
+			continue
+		}
+
+		if isTrue(required) {
+			return nil, fmt.Errorf("meta-schema %q requires the unknown vocabulary %q: %w", metaURL, uri, ErrVocabulary)
+		}
+	}
+
+	return d.withVocabularies(v.vocabularies, enabled, enabled), nil
+}
+
+// withVocabularies enables the keywords of some vocabularies.
+//
+// Standard keywords are restricted to the standard vocabularies listed, if any. Custom keywords are restricted to
+// the custom vocabularies listed, or all enabled if customs is nil.
+func (d *dialect) withVocabularies(registry *VocabularyRegistry, standard []string, customs []string) *dialect {
+	if len(standard) > 0 {
+		d.standard = make(map[string]struct{})
+		d.standard["$schema"] = struct{}{} // the core vocabulary is always enabled
+
+		for _, uri := range standard {
+			if !registry.isCustom(uri) {
+				vocabulary, _ := registry.Vocabulary(uri)
+				for _, keyword := range vocabulary.Keywords {
+					d.standard[keyword.Name] = struct{}{}
+				}
+			}
+		}
+
+		if !hasStandardCore(standard) {
+			for _, keyword := range standardVocabularies[0].Keywords {
+				d.standard[keyword.Name] = struct{}{}
+			}
+		}
+	}
+
+	d.custom = make(map[string]Keyword)
+	for keyword := range registry.Keywords() {
+		_, uri, _ := registry.Keyword(keyword.Name)
+		if customs == nil || slices.Contains(customs, uri) {
+			d.custom[keyword.Name] = keyword
+		}
+	}
+
+	return d
+}
+
+func hasStandardCore(uris []string) bool {
+	return slices.Contains(uris, VocabularyCore2019) || slices.Contains(uris, VocabularyCore2020)
+}
+
+// schema checks a schema and its subschemas.
+func (d *dialect) schema(node json.Document, pointer string, violations *MetaViolations) {
+	switch {
+	case node.IsBool():
+		if !boolSchemaVersions.Allows(d.version) {
+			violations.add(pointer, "", fmt.Sprintf("boolean schemas are not supported by JSON schema %v", d.version))
+		}
+
+		return
+	case !node.IsObject():
+		violations.add(pointer, "", "a schema must be a boolean or an object")
+
+		return
+	}
+
+	for key, value := range node.Pairs() {
+		d.keyword(key, value, pointer, violations)
+	}
+}
+
+// keyword checks the value of a keyword.
+func (d *dialect) keyword(key string, value json.Document, pointer string, violations *MetaViolations) {
+	at := pointer + "/" + json.EscapeToken(key)
+
+	if custom, ok := d.custom[key]; ok {
+		d.customKeyword(custom, value, pointer, violations)
+
+		return
+	}
+
+	if !isStandardKeyword(key) {
+		return // unknown keywords are ignored
+	}
+
+	if d.standard != nil {
+		if _, enabled := d.standard[key]; !enabled {
+			return // keywords from vocabularies which are not enabled are ignored
+		}
+	}
+
+	if requirements := keywordVersions[values.MakeInternedKey(key)]; !requirements.Allows(d.version) {
+		violations.add(pointer, key, fmt.Sprintf("not supported by JSON schema %v", d.version))
+
+		return
+	}
+
+	if d.dollarData && IsDataRef(key, value) {
+		if _, err := MakeDataRef(key, value); err != nil {
+			violations.add(pointer, key, err.Error())
+		}
+
+		return
+	}
+
+	if check, ok := metaChecks[key]; ok {
+		if message := check(d, value); message != "" {
+			violations.add(pointer, key, message)
+		}
+
+		return
+	}
+
+	if key == "dependencies" {
+		d.dependencies(value, pointer, at, violations)
+
+		return
+	}
+
+	switch SubschemaKeyword(key) {
+	case SubschemaArray:
+		d.schemaArray(key, value, pointer, at, violations)
+	case SubschemaSingle:
+		if key == "items" && value.IsArray() && AllowsItemsArray(d.version) {
+			d.schemaArray(key, value, pointer, at, violations)
+
+			return
+		}
+
+		d.schema(value, at, violations)
+	case SubschemaMap:
+		if !value.IsObject() {
+			violations.add(pointer, key, "must be an object of schemas")
+
+			return
+		}
+
+		for name, member := range value.Pairs() {
+			d.schema(member, at+"/"+json.EscapeToken(name), violations)
+		}
+	case NoSubschema:
+	}
+}
+
+// dependencies holds either schemas or arrays of property names.
+func (d *dialect) dependencies(value json.Document, pointer, at string, violations *MetaViolations) {
+	if !value.IsObject() {
+		violations.add(pointer, "dependencies", "must be an object")
+
+		return
+	}
+
+	for name, member := range value.Pairs() {
+		if member.IsArray() {
+			if message := uniqueStrings(member, false); message != "" {
+				violations.add(at, name, message)
+			}
+
+			continue
+		}
+
+		d.schema(member, at+"/"+json.EscapeToken(name), violations)
+	}
+}
+
+func (d *dialect) schemaArray(key string, value json.Document, pointer, at string, violations *MetaViolations) {
+	if !value.IsArray() || value.Len() == 0 {
+		violations.add(pointer, key, "must be a non-empty array of schemas")
+
+		return
+	}
+
+	for i, elem := range value.IndexedElems() {
+		d.schema(elem, at+"/"+strconv.Itoa(i), violations)
+	}
+}
+
+func (d *dialect) customKeyword(keyword Keyword, value json.Document, pointer string, violations *MetaViolations) {
+	if !keyword.Versions.Allows(d.version) {
+		violations.add(pointer, keyword.Name, fmt.Sprintf("not supported by JSON schema %v", d.version))
+
+		return
+	}
+
+	if keyword.Shape == nil {
+		return
+	}
+
+	if err := keyword.Shape.Check(*value.Node(), value.Store()); err != nil {
+		violations.add(pointer, keyword.Name, err.Error())
+	}
+}
+
+func (v *MetaViolations) add(pointer, keyword, message string) {
+	*v = append(*v, MetaViolation{Pointer: pointer, Keyword: keyword, Message: message})
+}
+
+// metaCheck checks the value of a keyword which is not a schema, and returns a message explaining the violation.
+type metaCheck func(d *dialect, value json.Document) string
+
+//nolint:gochecknoglobals // declarations of checks
+var metaChecks = map[string]metaCheck{
+	"$id":              isString,
+	"id":               isString,
+	"$schema":          isString,
+	"$ref":             isString,
+	"$anchor":          isString,
+	"$dynamicRef":      isString,
+	"$dynamicAnchor":   isString,
+	"$recursiveRef":    isString,
+	"$comment":         isString,
+	"title":            isString,
+	"description":      isString,
+	"format":           isString,
+	"pattern":          isString,
+	"contentEncoding":  isString,
+	"contentMediaType": isString,
+	"$recursiveAnchor": isBool,
+	"uniqueItems":      isBool,
+	"readOnly":         isBool,
+	"writeOnly":        isBool,
+	"deprecated":       isBool,
+	"maxLength":        isNonNegativeInteger,
+	"minLength":        isNonNegativeInteger,
+	"maxItems":         isNonNegativeInteger,
+	"minItems":         isNonNegativeInteger,
+	"maxProperties":    isNonNegativeInteger,
+	"minProperties":    isNonNegativeInteger,
+	"maxContains":      isNonNegativeInteger,
+	"minContains":      isNonNegativeInteger,
+	"maximum":          isNumber,
+	"minimum":          isNumber,
+	"multipleOf":       isPositiveNumber,
+	"exclusiveMaximum": isExclusiveBound,
+	"exclusiveMinimum": isExclusiveBound,
+	"type":             isTypeList,
+	"enum":             isEnum,
+	"required":         isRequired,
+	"examples":         isArray,
+	"$vocabulary":      isVocabulary,
+	"dependentRequired": func(_ *dialect, value json.Document) string {
+		if !value.IsObject() {
+			return "must be an object of arrays of strings"
+		}
+
+		for _, member := range value.Pairs() {
+			if message := uniqueStrings(member, false); message != "" {
+				return message
+			}
+		}
+
+		return ""
+	},
+}
+
+func isString(_ *dialect, value json.Document) string {
+	if !value.IsString() {
+		return "must be a string"
+	}
+
+	return ""
+}
+
+func isBool(_ *dialect, value json.Document) string {
+	if !value.IsBool() {
+		return "must be a boolean"
+	}
+
+	return ""
+}
+
+func isArray(_ *dialect, value json.Document) string {
+	if !value.IsArray() {
+		return "must be an array"
+	}
+
+	return ""
+}
+
+func isNumber(_ *dialect, value json.Document) string {
+	if !value.IsNumber() {
+		return "must be a number"
+	}
+
+	return ""
+}
+
+func isNonNegativeInteger(_ *dialect, value json.Document) string {
+	r, ok := ratOf(value)
+	if !ok || !r.IsInt() || r.Sign() < 0 {
+		return "must be a non-negative integer"
+	}
+
+	return ""
+}
+
+func isPositiveNumber(_ *dialect, value json.Document) string {
+	r, ok := ratOf(value)
+	if !ok || r.Sign() <= 0 {
+		return "must be a strictly positive number"
+	}
+
+	return ""
+}
+
+// isExclusiveBound checks "exclusiveMaximum" and "exclusiveMinimum", which are booleans until draft 5
+// (and for OpenAPI v2 and v3.0), then numbers.
+func isExclusiveBound(d *dialect, value json.Document) string {
+	switch {
+	case d.version == VersionUndefined:
+		if !value.IsBool() && !value.IsNumber() {
+			return "must be a boolean or a number"
+		}
+	case d.isDraft4():
+		return isBool(d, value)
+	default:
+		return isNumber(d, value)
+	}
+
+	return ""
+}
+
+// isDraft4 tells if the schemas of this dialect follow the meta-schema of draft 4, like OpenAPI v2 and v3.0 schemas.
+func (d *dialect) isDraft4() bool {
+	return !d.version.Less(VersionDraft4) && !VersionDraft5.Less(d.version) ||
+		d.version.isOpenAPI() && d.version.Less(VersionOpenAPIv310)
+}
+
+func isTypeList(_ *dialect, value json.Document) string {
+	const message = "must be a simple type or an array of unique simple types"
+
+	if value.IsString() {
+		v, _ := value.Value()
+		if !isSimpleType(v.String()) {
+			return message
+		}
+
+		return ""
+	}
+
+	if !value.IsArray() || uniqueStrings(value, false) != "" {
+		return message
+	}
+
+	for elem := range value.Elems() {
+		v, _ := elem.Value()
+		if !isSimpleType(v.String()) {
+			return message
+		}
+	}
+
+	return ""
+}
+
+func isEnum(d *dialect, value json.Document) string {
+	if !value.IsArray() {
+		return "must be an array"
+	}
+
+	if d.isDraft4() && value.Len() == 0 {
+		return "must be a non-empty array"
+	}
+
+	return ""
+}
+
+func isRequired(d *dialect, value json.Document) string {
+	return uniqueStrings(value, d.isDraft4())
+}
+
+func isVocabulary(_ *dialect, value json.Document) string {
+	if !value.IsObject() {
+		return "must be an object of booleans"
+	}
+
+	for _, member := range value.Pairs() {
+		if !member.IsBool() {
+			return "must be an object of booleans"
+		}
+	}
+
+	return ""
+}
+
+// uniqueStrings checks an array of unique strings, possibly non-empty.
+func uniqueStrings(value json.Document, nonEmpty bool) string {
+	const message = "must be an array of unique strings"
+
+	if !value.IsArray() {
+		return message
+	}
+
+	if nonEmpty && value.Len() == 0 {
+		return "must be a non-empty array of unique strings"
+	}
+
+	seen := make(map[string]struct{}, value.Len())
+	for elem := range value.Elems() {
+		if !elem.IsString() {
+			return message
+		}
+
+		v, _ := elem.Value()
+		if _, duplicate := seen[v.String()]; duplicate {
+			return message
+		}
+		seen[v.String()] = struct{}{}
+	}
+
+	return ""
+}
+
+func ratOf(value json.Document) (*big.Rat, bool) {
+	if !value.IsNumber() {
+		return nil, false
+	}
+
+	v, _ := value.Value()
+
+	return new(big.Rat).SetString(string(v.NumberValue().Value))
+}
+
+func isTrue(value json.Document) bool {
+	if !value.IsBool() {
+		return false
+	}
+
+	v, _ := value.Value()
+
+	return v.Bool()
+}
@@ -0,0 +1,290 @@
+package jsonschema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/json/constrained"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaValidator(t *testing.T) {
+	t.Run("should validate schemas", func(t *testing.T) {
+		v := NewMetaValidator()
+
+		for _, jazon := range []string{
+			`{}`,
+			`{"$schema": "http://json-schema.org/draft-04/schema#", "type": ["string", "null"], "maximum": 3, "exclusiveMaximum": true, "required": ["a"]}`,
+			`{"$schema": "http://json-schema.org/draft-07/schema#", "items": [{"type": "integer"}, true], "dependencies": {"a": ["b"], "c": {"minProperties": 1}}}`,
+			`{"$schema": "https://json-schema.org/draft/2020-12/schema", "prefixItems": [true], "items": {"minLength": 0}, "exclusiveMinimum": 1.5}`,
+			`{"$schema": "https://json-schema.org/draft/2020-12/schema", "properties": {"a": {"multipleOf": 0.5, "enum": []}}, "$defs": {"b": false}}`,
+		} {
+			require.NoErrorf(t, v.Validate(mustSchema(t, jazon)), "%s", jazon)
+		}
+	})
+
+	t.Run("should report violations with their location", func(t *testing.T) {
+		v := NewMetaValidator()
+
+		for _, tc := range []struct {
+			jazon    string
+			expected []MetaViolation
+		}{
+			{
+				jazon:    `{"type": "integral"}`,
+				expected: []MetaViolation{{Pointer: "", Keyword: "type"}},
+			},
+			{
+				jazon:    `{"properties": {"a": {"minLength": -1}, "b~/": {"maxItems": 1.5}}}`,
+				expected: []MetaViolation{{Pointer: "/properties/a", Keyword: "minLength"}, {Pointer: "/properties/b~0~1", Keyword: "maxItems"}},
+			},
+			{
+				jazon:    `{"allOf": [{"multipleOf": 0}, {"required": ["a", "a"]}], "not": 3}`,
+				expected: []MetaViolation{{Pointer: "/allOf/0", Keyword: "multipleOf"}, {Pointer: "/allOf/1", Keyword: "required"}, {Pointer: "/not"}},
+			},
+			{
+				jazon:    `{"$schema": "http://json-schema.org/draft-04/schema#", "exclusiveMinimum": 1, "items": true}`,
+				expected: []MetaViolation{{Pointer: "", Keyword: "exclusiveMinimum"}, {Pointer: "/items"}},
+			},
+			{
+				jazon:    `{"$schema": "http://json-schema.org/draft-07/schema#", "prefixItems": [true], "$vocabulary": {}}`,
+				expected: []MetaViolation{{Pointer: "", Keyword: "prefixItems"}, {Pointer: "", Keyword: "$vocabulary"}},
+			},
+			{
+				jazon:    `{"$schema": "https://json-schema.org/draft/2020-12/schema", "items": [true], "anyOf": []}`,
+				expected: []MetaViolation{{Pointer: "/items"}, {Pointer: "", Keyword: "anyOf"}},
+			},
+		} {
+			t.Run(tc.jazon, func(t *testing.T) {
+				err := v.Validate(rawSchema(t, tc.jazon))
+				require.ErrorIs(t, err, ErrMetaSchema)
+
+				var violations MetaViolations
+				require.True(t, errors.As(err, &violations))
+				require.Len(t, violations, len(tc.expected))
+
+				for _, expected := range tc.expected {
+					assert.True(t, containsViolation(violations, expected), "expected %v in %v", expected, violations)
+				}
+			})
+		}
+	})
+
+	t.Run("with a default version", func(t *testing.T) {
+		sch := mustSchema(t, `{"exclusiveMaximum": true}`)
+
+		require.NoError(t, NewMetaValidator().Validate(sch))
+		require.NoError(t, NewMetaValidator(WithMetaVersion(VersionDraft4)).Validate(sch))
+		require.ErrorIs(t, NewMetaValidator(WithMetaVersion(VersionDraft7)).Validate(sch), ErrMetaSchema)
+	})
+
+	t.Run("with custom keywords", func(t *testing.T) {
+		registry := NewVocabularyRegistry()
+		require.NoError(t, registry.Register(inHouseVocabulary()))
+		v := NewMetaValidator(WithMetaVocabularies(registry))
+
+		require.NoError(t, v.Validate(mustSchema(t, `{"x-unit": "meter", "deprecatedSince": "1.2"}`)))
+
+		err := v.Validate(mustSchema(t, `{"properties": {"a": {"x-unit": 12}}}`))
+		require.ErrorIs(t, err, ErrMetaSchema)
+		assert.Contains(t, err.Error(), `"x-unit" at "/properties/a"`)
+
+		err = v.Validate(mustSchema(t, `{"$schema": "http://json-schema.org/draft-04/schema#", "deprecatedSince": "1.2"}`))
+		require.ErrorIs(t, err, ErrMetaSchema)
+		assert.Contains(t, err.Error(), `"deprecatedSince"`)
+
+		// custom keywords are ignored when not registered
+		require.NoError(t, NewMetaValidator().Validate(mustSchema(t, `{"x-unit": 12}`)))
+	})
+
+	t.Run("with a custom meta-schema", func(t *testing.T) {
+		registry := NewVocabularyRegistry()
+		require.NoError(t, registry.Register(inHouseVocabulary()))
+
+		resolver := NewResolver(WithOfflineMode(true))
+		require.NoError(t, resolver.AddDocument("https://example.com/meta/in-house", mustSchema(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"$id": "https://example.com/meta/in-house",
+			"$vocabulary": {
+				"https://json-schema.org/draft/2020-12/vocab/core": true,
+				"https://json-schema.org/draft/2020-12/vocab/applicator": true,
+				"https://example.com/vocab/in-house": true,
+				"https://example.com/vocab/optional": false
+			}
+		}`)))
+		require.NoError(t, resolver.AddDocument("https://example.com/meta/unknown", mustSchema(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"$id": "https://example.com/meta/unknown",
+			"$vocabulary": {
+				"https://json-schema.org/draft/2020-12/vocab/core": true,
+				"https://example.com/vocab/unknown": true
+			}
+		}`)))
+
+		v := NewMetaValidator(WithMetaVocabularies(registry), WithMetaResolver(resolver))
+
+		t.Run("should ignore the keywords of vocabularies which are not enabled", func(t *testing.T) {
+			require.NoError(t, v.Validate(mustSchema(t, `{
+				"$schema": "https://example.com/meta/in-house",
+				"minLength": -1,
+				"properties": {"a": {"deprecatedSince": "1.0"}}
+			}`)))
+		})
+
+		t.Run("should check the keywords of enabled vocabularies", func(t *testing.T) {
+			err := v.Validate(rawSchema(t, `{
+				"$schema": "https://example.com/meta/in-house",
+				"properties": {"a": {"deprecatedSince": 1}, "b": 2}
+			}`))
+			require.ErrorIs(t, err, ErrMetaSchema)
+
+			var violations MetaViolations
+			require.True(t, errors.As(err, &violations))
+			assert.Len(t, violations, 2)
+		})
+
+		t.Run("should fail on an unknown required vocabulary", func(t *testing.T) {
+			err := v.Validate(mustSchema(t, `{"$schema": "https://example.com/meta/unknown"}`))
+			require.ErrorIs(t, err, ErrVocabulary)
+		})
+
+		t.Run("should fail on an unresolved meta-schema", func(t *testing.T) {
+			err := v.Validate(mustSchema(t, `{"$schema": "https://example.com/meta/missing"}`))
+			require.ErrorIs(t, err, ErrMetaSchema)
+		})
+	})
+}
+
+func TestVocabularyRegistry(t *testing.T) {
+	t.Run("should know about standard vocabularies", func(t *testing.T) {
+		registry := NewVocabularyRegistry()
+
+		vocabulary, ok := registry.Vocabulary(VocabularyValidation2020)
+		require.True(t, ok)
+		require.NotEmpty(t, vocabulary.Keywords)
+		assert.Equal(t, "type", vocabulary.Keywords[0].Name)
+		assert.True(t, vocabulary.Keywords[0].IsAssertion())
+
+		_, _, ok = registry.Keyword("type")
+		assert.False(t, ok)
+	})
+
+	t.Run("should register custom vocabularies", func(t *testing.T) {
+		registry := NewVocabularyRegistry()
+		require.NoError(t, registry.Register(inHouseVocabulary()))
+
+		keyword, uri, ok := registry.Keyword("deprecatedSince")
+		require.True(t, ok)
+		assert.Equal(t, "https://example.com/vocab/in-house", uri)
+		assert.Equal(t, KeywordAnnotation, keyword.Kind)
+
+		names := make([]string, 0, 3)
+		for keyword := range registry.Keywords() {
+			names = append(names, keyword.Name)
+		}
+		assert.Equal(t, []string{"deprecatedSince", "x-even", "x-unit"}, names)
+	})
+
+	t.Run("should refuse conflicting vocabularies", func(t *testing.T) {
+		registry := NewVocabularyRegistry()
+		require.NoError(t, registry.Register(inHouseVocabulary()))
+
+		for _, vocabulary := range []Vocabulary{
+			{},
+			inHouseVocabulary(),
+			{URI: VocabularyCore2020},
+			{URI: "https://example.com/vocab/other", Keywords: []Keyword{{Name: "x-unit"}}},
+			{URI: "https://example.com/vocab/other", Keywords: []Keyword{{Name: "minLength"}}},
+			{URI: "https://example.com/vocab/other", Keywords: []Keyword{{}}},
+		} {
+			require.ErrorIs(t, registry.Register(vocabulary), ErrVocabulary)
+		}
+	})
+
+	t.Run("should validate instances against custom assertions", func(t *testing.T) {
+		registry := NewVocabularyRegistry()
+		require.NoError(t, registry.Register(inHouseVocabulary()))
+		sch := mustSchema(t, `{"x-even": true, "x-unit": "meter", "type": "integer"}`)
+
+		require.NoError(t, registry.ValidateKeywords(sch.Document, mustDocument(t, `4`)))
+
+		err := registry.ValidateKeywords(sch.Document, mustDocument(t, `3`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `keyword "x-even"`)
+	})
+}
+
+func TestSchemaWithVocabularies(t *testing.T) {
+	registry := NewVocabularyRegistry()
+	require.NoError(t, registry.Register(inHouseVocabulary()))
+
+	t.Run("should check the shape of custom keywords", func(t *testing.T) {
+		sch := Make(WithVocabularies(registry))
+		require.NoError(t, sch.UnmarshalJSON([]byte(`{"x-unit": "meter", "deprecatedSince": "1.2"}`)))
+		assert.True(t, sch.HasExtensions())
+
+		sch = Make(WithVocabularies(registry))
+		require.Error(t, sch.UnmarshalJSON([]byte(`{"x-unit": ["meter"]}`)))
+
+		sch = Make()
+		require.NoError(t, sch.UnmarshalJSON([]byte(`{"x-unit": ["meter"]}`)))
+	})
+
+	t.Run("should check the versions of custom keywords", func(t *testing.T) {
+		sch := Make(WithVocabularies(registry), WithVersion(VersionDraft7))
+		require.ErrorIs(t, sch.UnmarshalJSON([]byte(`{"deprecatedSince": "1.2"}`)), ErrVersion)
+	})
+}
+
+func inHouseVocabulary() Vocabulary {
+	return Vocabulary{
+		URI: "https://example.com/vocab/in-house",
+		Keywords: []Keyword{
+			{
+				Name:  "x-unit",
+				Shape: &constrained.Constraints{Root: constrained.KindString},
+				Kind:  KeywordAnnotation,
+			},
+			{
+				Name:     "deprecatedSince",
+				Shape:    &constrained.Constraints{Root: constrained.KindString},
+				Versions: VersionRequirements{MinVersion: VersionDraft2019},
+			},
+			{
+				Name:  "x-even",
+				Shape: &constrained.Constraints{Root: constrained.KindBool},
+				Kind:  KeywordAssertion,
+				Validate: func(_ json.Document, instance json.Document) error {
+					data, err := instance.MarshalJSON()
+					if err != nil {
+						return err
+					}
+
+					if last := data[len(data)-1]; (last-'0')%2 != 0 {
+						return errors.New("odd number")
+					}
+
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func rawSchema(t *testing.T, jazon string) Schema {
+	t.Helper()
+
+	return Schema{Document: mustDocument(t, jazon)}
+}
+
+func containsViolation(violations MetaViolations, expected MetaViolation) bool {
+	for _, violation := range violations {
+		if violation.Pointer == expected.Pointer && violation.Keyword == expected.Keyword {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,8 +1,11 @@
 package jsonschema
 
 import (
+	"io/fs"
+
 	"github.com/fredbi/core/json"
 	"github.com/fredbi/core/jsonschema/overlay"
+	"github.com/fredbi/core/swag/loading"
 )
 
 // Option to customize the behavior of a [Schema] document.
@@ -12,6 +15,8 @@ type options struct {
 	version         Version
 	documentOptions []json.Option
 	useDollarData   bool // support for $data ajv extension
+	vocabularies    *VocabularyRegistry
+	fsys            fs.FS // to load collections
 }
 
 // TODO: as usual, transform this to use pool
@@ -32,6 +37,7 @@ func WithVersion(version Version) Option {
 	}
 }
 
+// WithDocumentOptions sets the options of the [json.Document] s holding schemas, e.g. to share a store.
 func WithDocumentOptions(opts ...json.Option) Option {
 	return func(o *options) {
 		o.documentOptions = append(o.documentOptions, opts...)
@@ -41,12 +47,36 @@ func WithDocumentOptions(opts ...json.Option) Option {
 // WithDollarData adds support for the "$data" extension popularized by the ajv validator.
 //
 // This extension applies to any jsonschema version or dialect.
+//
+// With this extension, the value of some validation keywords (see [SupportsDollarData]) may be a reference
+// to the data under validation, e.g. {"maximum": {"$data": "1/limit"}}. Such references are decoded as [DataRef] s
+// (see [Validation.DataRefs]) and resolved by the validator at runtime.
 func WithDollarData(enabled bool) Option {
 	return func(o *options) {
 		o.useDollarData = enabled
 	}
 }
 
+// WithVocabularies checks the custom keywords declared by a [VocabularyRegistry] when decoding a [Schema].
+//
+// The value of a custom keyword must satisfy the shape declared by its [Keyword], and the [VersionRequirements]
+// of the keyword are merged into those of the [Schema]. If the version of the [Schema] is enforced with [WithVersion],
+// custom keywords that are not supported by this version yield an error wrapping [ErrVersion].
+func WithVocabularies(registry *VocabularyRegistry) Option {
+	return func(o *options) {
+		o.vocabularies = registry
+	}
+}
+
+// WithFS sets the file system from which a [Collection] loads files (see [Collection.LoadDir]).
+//
+// By default, files are loaded from the local file system.
+func WithFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.fsys = fsys
+	}
+}
+
 func withOptions(opts *options) Option {
 	return func(o *options) {
 		*o = *opts
@@ -59,6 +89,7 @@ type OverlayOption func(*overlayOptions)
 type overlayOptions struct {
 	version         overlay.Version
 	documentOptions []json.Option
+	strict          bool
 }
 
 func overlayOptionsWithDefaults(opts []OverlayOption) *overlayOptions {
@@ -73,13 +104,23 @@ func overlayOptionsWithDefaults(opts []OverlayOption) *overlayOptions {
 
 // WithOverlayVersion enforces a version of the overlay specification.
 //
-// At this moment only [overlay.VersionUndefined] and [overlay.Version10] are supported.
+// At this moment only [overlay.VersionUndefined], [overlay.Version10] and [overlay.Version11] are supported.
 func WithOverlayVersion(version overlay.Version) OverlayOption {
 	return func(o *overlayOptions) {
 		o.version = version
 	}
 }
 
+// WithOverlayStrict makes the application of an [Overlay] fail whenever an action doesn't apply as specified,
+// e.g. when its target matches no node or when an update value is not an object to merge into an object.
+//
+// By default, such actions are skipped or resolved as a replacement of the target.
+func WithOverlayStrict(enabled bool) OverlayOption {
+	return func(o *overlayOptions) {
+		o.strict = enabled
+	}
+}
+
 func WithOverlayDocumentOptions(opts ...json.Option) OverlayOption {
 	return func(o *overlayOptions) {
 		o.documentOptions = append(o.documentOptions, opts...)
@@ -91,3 +132,135 @@ func withOverlayOptions(opts *overlayOptions) OverlayOption {
 		*o = *opts
 	}
 }
+
+// ResolverOption customizes the behavior of a [Resolver].
+type ResolverOption func(*resolverOptions)
+
+type resolverOptions struct {
+	cache          ResolverCache
+	mirrors        []mirror
+	offline        bool
+	loadingOptions []loading.Option
+	schemaOptions  []Option
+}
+
+type mirror struct {
+	prefix string
+	dir    string
+}
+
+func resolverOptionsWithDefaults(opts []ResolverOption) *resolverOptions {
+	var o resolverOptions
+
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	if o.cache == nil {
+		o.cache = NewResolverCache()
+	}
+
+	return &o
+}
+
+// WithResolverCache sets the cache of loaded documents, e.g. to share loaded documents between several [Resolver] s.
+//
+// By default, every [Resolver] uses its own in-memory cache.
+func WithResolverCache(cache ResolverCache) ResolverOption {
+	return func(o *resolverOptions) {
+		if cache != nil {
+			o.cache = cache
+		}
+	}
+}
+
+// WithOfflineMirror loads the documents with a URI starting with prefix from a local directory.
+//
+// For example, mirroring prefix "http://localhost:1234/" to directory "fixtures/remotes"
+// loads "http://localhost:1234/folder/schema.json" from "fixtures/remotes/folder/schema.json".
+//
+// When several mirrored prefixes match, the longest one is used.
+func WithOfflineMirror(prefix, dir string) ResolverOption {
+	return func(o *resolverOptions) {
+		o.mirrors = append(o.mirrors, mirror{prefix: prefix, dir: dir})
+	}
+}
+
+// WithOfflineMode prevents a [Resolver] from loading documents from remote HTTP(s) locations.
+//
+// Remote documents may still be resolved from a mirror (see [WithOfflineMirror]) or from the cache.
+func WithOfflineMode(enabled bool) ResolverOption {
+	return func(o *resolverOptions) {
+		o.offline = enabled
+	}
+}
+
+// WithLoadingOptions sets the options used to load documents, e.g. a timeout for remote documents,
+// or a file system for local ones.
+func WithLoadingOptions(opts ...loading.Option) ResolverOption {
+	return func(o *resolverOptions) {
+		o.loadingOptions = append(o.loadingOptions, opts...)
+	}
+}
+
+// WithResolverSchemaOptions sets the options of the [Schema] s loaded by a [Resolver].
+func WithResolverSchemaOptions(opts ...Option) ResolverOption {
+	return func(o *resolverOptions) {
+		o.schemaOptions = append(o.schemaOptions, opts...)
+	}
+}
+
+// MetaOption customizes the behavior of a [MetaValidator].
+type MetaOption func(*metaOptions)
+
+type metaOptions struct {
+	vocabularies *VocabularyRegistry
+	resolver     *Resolver
+	version      Version
+}
+
+func metaOptionsWithDefaults(opts []MetaOption) *metaOptions {
+	var o metaOptions
+
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	if o.vocabularies == nil {
+		o.vocabularies = NewVocabularyRegistry()
+	}
+
+	if o.resolver == nil {
+		o.resolver = NewResolver()
+	}
+
+	return &o
+}
+
+// WithMetaVocabularies sets the [VocabularyRegistry] that knows about custom vocabularies.
+//
+// By default, only the standard vocabularies of JSON schema are known.
+func WithMetaVocabularies(registry *VocabularyRegistry) MetaOption {
+	return func(o *metaOptions) {
+		o.vocabularies = registry
+	}
+}
+
+// WithMetaResolver sets the [Resolver] used to load custom meta-schemas, i.e. meta-schemas identified by
+// a "$schema" which is not the URL of a standard meta-schema.
+//
+// By default, a [MetaValidator] uses its own [Resolver].
+func WithMetaResolver(resolver *Resolver) MetaOption {
+	return func(o *metaOptions) {
+		o.resolver = resolver
+	}
+}
+
+// WithMetaVersion sets the version of JSON schema for the schemas that don't declare a meta-schema.
+//
+// By default, such schemas are checked against the rules common to all versions.
+func WithMetaVersion(version Version) MetaOption {
+	return func(o *metaOptions) {
+		o.version = version
+	}
+}
@@ -4,14 +4,13 @@ import (
 	"fmt"
 	"io"
 	"iter"
+	"net/url"
 	"slices"
 	"strings"
 
 	"github.com/fredbi/core/json"
 	"github.com/fredbi/core/json/jsonpath"
 	"github.com/fredbi/core/json/lexers"
-	"github.com/fredbi/core/json/lexers/token"
-	codes "github.com/fredbi/core/json/nodes/error-codes"
 	"github.com/fredbi/core/json/nodes/light"
 	"github.com/fredbi/core/json/stores"
 	"github.com/fredbi/core/json/stores/values"
@@ -25,6 +24,8 @@ import (
 // (https://spec.openapis.org/overlay/v1.0.0.html),
 // but does not require title, version or any metadata.
 //
+// The "copy" action introduced by version 1.1.0 of the overlay specification is supported.
+//
 // Unlike the OpenAPI overlay, a schema [Overlay] may be empty or contain an empty list of actions.
 //
 // Actions must specify a valid [jsonpath.Expression].
@@ -88,26 +89,180 @@ func (o Overlay) Extensions() analyzers.Extensions {
 
 // ApplyTo applies the set of actions defined by the [Overlay] to a [Schema].
 //
-// If no change applies to the input schema, the input is returned unaltered.
-//
-// If the target expression resolves as an object and the update specification is not an object,
-// the action rule is ignored and the input is returned unaltered (TODO: this form of error handling is questionable).
+// This is a shorthand for [Overlay.Apply] which ignores errors:
+// if the [Overlay] cannot be applied, the input is returned unaltered.
 func (o Overlay) ApplyTo(sch Schema) Schema {
+	applied, _, err := o.Apply(sch)
+	if err != nil {
+		return sch
+	}
+
+	return applied
+}
+
+// Apply the set of actions defined by the [Overlay] to a [Schema].
+//
+// Actions are applied in order, each to the result of the previous one.
+// An action applies to all the nodes selected by its target JSONPath expression:
+//
+//   - "remove" actions remove the selected nodes from their parent object or array
+//   - "copy" actions merge the value of the single node selected by the copy expression into the selected nodes
+//   - "update" actions merge their value into the selected nodes: objects are merged recursively, and values are
+//     appended to arrays (see [json.Builder.AtPointerMerge])
+//
+// If the [Overlay] extends a document, the "$id" of the schema must match the resolved "extends" URI.
+//
+// In strict mode (see [WithOverlayStrict]), actions with a target that matches no node fail,
+// as well as updates that are neither objects to merge into objects nor values to append to arrays.
+//
+// The returned [OverlayReport] tells which nodes have been touched by each action.
+//
+// If the [Overlay] cannot be applied, the input schema is returned unaltered with an error.
+func (o Overlay) Apply(sch Schema) (Schema, OverlayReport, error) {
+	var report OverlayReport
+
+	if err := o.checkExtends(sch); err != nil {
+		return sch, report, err
+	}
+
 	b := NewBuilder().From(sch) // TODO: pool
-	lastCorrect := sch
+	current := sch
+
+	for index, action := range o.actions {
+		pointers := slices.Collect(o.finder.Pointers(current.Document, action.Target()))
+		if len(pointers) == 0 && o.strict {
+			return sch, report, fmt.Errorf(
+				"action %d with target %q: %w: %w",
+				index, action.Target(), overlay.ErrNoMatch, overlay.ErrOverlay,
+			)
+		}
+
+		if err := o.applyAction(b, current, action, pointers); err != nil {
+			return sch, report, fmt.Errorf("action %d with target %q: %w", index, action.Target(), err)
+		}
 
-	for _, action := range o.actions {
-		for pointer := range o.finder.Pointers(sch.Document, action.Target()) {
-			b = b.AtPointerMerge(pointer, action.Update()) // TODO: implement this
-			if !b.Ok() {
-				return lastCorrect
+		current = b.Schema()
+		report.actions = append(report.actions, ActionReport{
+			index:   index,
+			action:  action,
+			touched: pointers,
+		})
+	}
+
+	return current, report, nil
+}
+
+func (o Overlay) applyAction(b *Builder, current Schema, action overlay.Action, pointers []json.Pointer) error {
+	if action.Remove() {
+		// remove from the last node: removing array elements doesn't shift the indices of the other selected nodes
+		for _, pointer := range slices.Backward(pointers) {
+			b.AtPointerRemove(pointer)
+		}
+
+		return b.Err()
+	}
+
+	var updates []json.Document
+
+	if expr, ok := action.Copy(); ok {
+		copied := slices.Collect(o.finder.Get(current.Document, expr))
+		switch {
+		case len(copied) == 1:
+			updates = append(updates, copied[0])
+		case len(copied) > 1:
+			return fmt.Errorf("copy %q should select a single node, but got %d: %w", expr, len(copied), overlay.ErrOverlay)
+		case o.strict:
+			return fmt.Errorf("copy %q: %w: %w", expr, overlay.ErrNoMatch, overlay.ErrOverlay)
+		}
+	}
+
+	if update := action.Update(); !update.IsEmpty() {
+		updates = append(updates, update)
+	}
+
+	for _, update := range updates {
+		for _, pointer := range pointers {
+			if o.strict {
+				if err := checkUpdate(current.Document, pointer, update); err != nil {
+					return err
+				}
 			}
 
-			lastCorrect = b.Schema()
+			b.AtPointerMerge(pointer, update)
 		}
 	}
 
-	return lastCorrect
+	return b.Err()
+}
+
+// checkUpdate verifies that an update value may be merged into the target, as required by the overlay specification.
+func checkUpdate(root json.Document, pointer json.Pointer, update json.Document) error {
+	target, err := root.GetPointer(pointer)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case target.IsArray():
+		return nil
+	case target.IsObject() && update.IsObject():
+		return nil
+	default:
+		return fmt.Errorf(
+			"update at %q should be an object to merge into an object, or a value to append to an array: %w",
+			pointer, overlay.ErrOverlay,
+		)
+	}
+}
+
+// checkExtends verifies that the schema is the document extended by the [Overlay].
+//
+// The check is only carried out when the [Overlay] specifies "extends" and the schema has an "$id".
+// A relative "extends" URI reference is resolved against the "$id".
+func (o Overlay) checkExtends(sch Schema) error {
+	extends := o.Extends()
+	if extends == "" {
+		return nil
+	}
+
+	id := schemaID(sch)
+	if id == "" {
+		return nil
+	}
+
+	base, err := url.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid schema id %q: %w: %w", id, err, overlay.ErrOverlay)
+	}
+
+	ref, err := url.Parse(extends)
+	if err != nil {
+		return fmt.Errorf("invalid extends URI %q: %w: %w", extends, err, overlay.ErrOverlay)
+	}
+
+	resolved := base.ResolveReference(ref)
+	resolved.Fragment = ""
+	base.Fragment = ""
+
+	if resolved.String() != base.String() {
+		return fmt.Errorf(
+			"overlay extends %q, but schema id is %q: %w: %w",
+			resolved, base, overlay.ErrExtends, overlay.ErrOverlay,
+		)
+	}
+
+	return nil
+}
+
+// schemaID is the "$id" of a schema, or "id" for draft 4 schemas.
+func schemaID(sch Schema) string {
+	for _, key := range []string{"$id", "id"} {
+		if id, ok := sch.AtKey(key); ok && id.IsString() {
+			return id.String()
+		}
+	}
+
+	return ""
 }
 
 func (o *Overlay) Decode(r io.Reader) error {
@@ -126,10 +281,9 @@ func (o *Overlay) UnmarshalJSON(data []byte) error {
 
 func (o *Overlay) hooks() light.DecodeOptions {
 	decodeOptions := o.DecodeOptions
-	decodeOptions.NodeHook = o.mustBeObject
-	decodeOptions.AfterKey = o.afterKey // TODO: we lose the error path, perhaps we should to beforeKey
+	decodeOptions.OnExit = o.afterKey
 
-	return decodeOptions
+	return overlayConstraints.Hooks(decodeOptions)
 }
 
 var (
@@ -164,36 +318,41 @@ func (o *Overlay) decodeActionsArray(n light.Node) error {
 }
 
 func (o *Overlay) afterKey(
-	ctx *light.ParentContext,
-	l lexers.Lexer,
-	key values.InternedKey,
-	n light.Node,
-) (skip bool, err error) {
+	_ *light.ParentContext,
+	_ lexers.Lexer,
+	ev light.HookEvent,
+) (light.Action, error) {
+	if ev.Depth != 1 || !ev.HasKey() {
+		// only the top-level keys of the overlay are interpreted here
+		return light.Continue, nil
+	}
+
+	key, n := ev.Key, ev.Node
 	s := o.Store()
 
 	switch key {
 	case overlayKey:
 		var version overlay.Version
 		if err := version.Decode(s, n); err != nil {
-			return false, err
+			return light.Continue, err
 		}
 		o.overlayVersion = version
 
 	case infoKey:
 		var info overlay.Info
 		if err := info.Decode(s, n); err != nil {
-			return false, err
+			return light.Continue, err
 		}
 		o.info = info
 	case extendsKey:
 		if !n.IsString(s) {
-			return false, fmt.Errorf("extends should be a string:%w", overlay.ErrOverlay)
+			return light.Continue, fmt.Errorf("extends should be a string:%w", overlay.ErrOverlay)
 		}
 		// TODO: validate URI? does not seem to be a strict requirement
 		o.extends, _ = n.Handle()
 	case actionsKey:
 		if err := o.decodeActionsArray(n); err != nil {
-			return false, err
+			return light.Continue, err
 		}
 	default:
 		// x-* extensions
@@ -207,39 +366,7 @@ func (o *Overlay) afterKey(
 	}
 
 	// other keys remain part of the document, but uninterpreted
-	return false, nil
-}
-
-func (o *Overlay) mustBeObject(
-	// TODO: generic constrained.MustBeObjectHook[overlayContext]???
-	ctx *light.ParentContext,
-	l lexers.Lexer,
-	tok token.T,
-) (skip bool, err error) {
-	octx, ok := ctx.X.(*overlayContext)
-	if !ok {
-		return false, nil
-	}
-
-	if octx.isObject {
-		return false, nil
-	}
-
-	level := l.IndentLevel() - octx.initialLevel
-
-	if level == 1 && tok.IsStartObject() {
-		octx.isObject = true
-
-		return false, nil
-	}
-
-	return false, fmt.Errorf("a JSON object is expected. Got: %v: %w", tok, codes.ErrNode)
-}
-
-type overlayContext struct {
-	// TODO: factorize constrained.ObjectContext
-	initialLevel int
-	isObject     bool
+	return light.Continue, nil
 }
 
 func (o *Overlay) decode(lex lexers.Lexer) error {
@@ -247,14 +374,10 @@ func (o *Overlay) decode(lex lexers.Lexer) error {
 	context.L = lex
 	context.S = o.Store()
 	context.DO = o.hooks()
-	octx := poolOfOverlayContexts.Borrow()
-	octx.initialLevel = lex.IndentLevel()
-	context.X = octx
 
 	n := o.Node()
 	n.Decode(context)
 	redeemContext()
-	poolOfOverlayContexts.Redeem(octx)
 
 	return lex.Err()
 }
@@ -8,4 +8,10 @@ func (e Error) Error() string {
 
 const (
 	ErrOverlay Error = "error in schema overlay"
+
+	// ErrNoMatch is raised in strict mode when the target of an action doesn't match any node.
+	ErrNoMatch Error = "overlay target matches no node"
+
+	// ErrExtends is raised when an overlay is applied to a document which is not the one it extends.
+	ErrExtends Error = "overlay applied to a document it doesn't extend"
 )
@@ -18,6 +18,7 @@ type Version uint8
 const (
 	VersionUndefined Version = iota
 	Version10
+	Version11
 )
 
 func (ov Version) String() string {
@@ -26,6 +27,8 @@ func (ov Version) String() string {
 		return "undefined"
 	case Version10:
 		return "1.0.0"
+	case Version11:
+		return "1.1.0"
 	default:
 		panic("unsupported overlay dialect version")
 	}
@@ -33,7 +36,7 @@ func (ov Version) String() string {
 
 func (ov Version) All() []Version {
 	return []Version{
-		VersionUndefined, Version10,
+		VersionUndefined, Version10, Version11,
 	}
 }
 
@@ -49,6 +52,9 @@ func (ov *Version) Decode(s stores.Store, n light.Node) error {
 	case Version10.String():
 		*ov = Version10
 		return nil
+	case Version11.String():
+		*ov = Version11
+		return nil
 	case "":
 		fallthrough
 	case VersionUndefined.String():
@@ -99,6 +105,7 @@ var (
 	descriptionKey = values.MakeInternedKey("description")
 	updateKey      = values.MakeInternedKey("update")
 	removeKey      = values.MakeInternedKey("remove")
+	copyKey        = values.MakeInternedKey("copy")
 )
 
 func (i *Info) Decode(s stores.Store, n light.Node) error {
@@ -147,6 +154,8 @@ type Action struct {
 	target      jsonpath.Expression
 	remove      bool
 	update      json.Document
+	copy        jsonpath.Expression
+	hasCopy     bool
 	extensions  analyzers.Extensions
 }
 
@@ -176,6 +185,16 @@ func (a Action) Update() json.Document {
 	return a.update
 }
 
+// Copy is a JSONPath expression selecting a single node in the target document,
+// which value is merged into the target nodes just like an update (overlay specification 1.1).
+//
+// When an action specifies both copy and update, the copied value is merged first.
+//
+// This field has no impact if the remove field of this action object is true.
+func (a Action) Copy() (jsonpath.Expression, bool) {
+	return a.copy, a.hasCopy
+}
+
 func (a Action) Extensions() analyzers.Extensions {
 	return a.extensions
 }
@@ -212,6 +231,17 @@ func (a *Action) Decode(s stores.Store, e light.Node) error {
 			}
 			v, _ := aNode.Value(s)
 			a.remove = v.Bool()
+		case copyKey:
+			if !aNode.IsString(s) {
+				return fmt.Errorf("action copy should be a string: %w", ErrOverlay)
+			}
+			v, _ := aNode.Value(s)
+			expr, err := jsonpath.MakeExpression(v.String())
+			if err != nil {
+				return fmt.Errorf("action copy should be a valid JSONpath expresion: %w: %w", err, ErrOverlay)
+			}
+			a.copy = expr
+			a.hasCopy = true
 		default:
 			// x-* extensions
 			if ext := aKey.String(); strings.HasPrefix(ext, "x-") {
@@ -230,5 +260,9 @@ func (a *Action) Decode(s stores.Store, e light.Node) error {
 		return fmt.Errorf("target is required in action: %w", ErrOverlay)
 	}
 
+	if a.remove && a.hasCopy {
+		return fmt.Errorf("copy cannot be specified in a remove action: %w", ErrOverlay)
+	}
+
 	return nil
 }
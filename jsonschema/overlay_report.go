@@ -0,0 +1,48 @@
+package jsonschema
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema/overlay"
+)
+
+// OverlayReport tells which nodes of a [Schema] have been touched when applying an [Overlay].
+type OverlayReport struct {
+	actions []ActionReport
+}
+
+// Actions yields a report for every applied action, in the order of the [Overlay] actions.
+func (r OverlayReport) Actions() iter.Seq[ActionReport] {
+	return slices.Values(r.actions)
+}
+
+// Len is the number of applied actions.
+func (r OverlayReport) Len() int {
+	return len(r.actions)
+}
+
+// ActionReport tells which nodes of a [Schema] have been touched by an [overlay.Action].
+type ActionReport struct {
+	index   int
+	action  overlay.Action
+	touched []json.Pointer
+}
+
+// Index of the action in the [Overlay].
+func (r ActionReport) Index() int {
+	return r.index
+}
+
+// Action that has been applied.
+func (r ActionReport) Action() overlay.Action {
+	return r.action
+}
+
+// Touched yields the JSON pointers to the nodes selected by the target of the action.
+//
+// Pointers locate nodes in the schema as it was before the action was applied.
+func (r ActionReport) Touched() iter.Seq[json.Pointer] {
+	return slices.Values(r.touched)
+}
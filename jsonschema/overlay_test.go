@@ -147,4 +147,151 @@ func TestOverlay(t *testing.T) {
 			})
 		})
 	})
+
+	t.Run("with invalid schema overlay", func(t *testing.T) {
+		for _, invalid := range []string{
+			`[]`,
+			`true`,
+			`{"extends": 1}`,
+			`{"actions": {}}`,
+			`{"info": "x"}`,
+			`{"overlay": "2.0.0"}`,
+			`{"actions": [{"target": "$.a", "remove": true, "copy": "$.b"}]}`,
+			`{"actions": [{"target": "$.a", "copy": "b"}]}`,
+			`{"actions": [{"target": "$.a[?@.b]", "remove": true}]}`,
+		} {
+			t.Run(invalid, func(t *testing.T) {
+				o := MakeOverlay()
+				require.Error(t, o.UnmarshalJSON([]byte(invalid)))
+			})
+		}
+	})
+
+	t.Run("should apply to a schema", func(t *testing.T) {
+		o := MakeOverlay()
+		require.NoError(t, o.UnmarshalJSON([]byte(`{
+			"actions": [
+				{"target": "$.properties.author_id", "remove": true},
+				{"target": "$.properties.*", "update": {"description": "a property"}},
+				{"target": "$.required", "update": "title"},
+				{"target": "$..enum[0,1]", "remove": true},
+				{"target": "$.missing", "update": {"x": 1}}
+			]
+		}`)))
+
+		sch := Make()
+		require.NoError(t, sch.UnmarshalJSON([]byte(`{
+			"properties": {
+				"author_id": {"type": "string"},
+				"title": {"type": "string", "enum": ["a", "b", "c"]}
+			},
+			"required": ["author_id"]
+		}`)))
+
+		applied := o.ApplyTo(sch)
+		output, err := applied.MarshalJSON()
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"properties": {
+				"title": {"type": "string", "enum": ["c"], "description": "a property"}
+			},
+			"required": ["author_id", "title"]
+		}`, string(output))
+
+		t.Run("should leave the input schema unaltered", func(t *testing.T) {
+			original, err := sch.MarshalJSON()
+			require.NoError(t, err)
+			require.Contains(t, string(original), "author_id")
+		})
+	})
+	t.Run("should apply with options", func(t *testing.T) {
+		sch := Make()
+		require.NoError(t, sch.UnmarshalJSON([]byte(`{
+			"$id": "https://example.com/schemas/pet.json",
+			"$defs": {"name": {"type": "string", "minLength": 1}},
+			"properties": {"name": {"title": "name"}, "alias": {}},
+			"enum": [1, 2]
+		}`)))
+
+		apply := func(t *testing.T, jazon string, opts ...OverlayOption) (string, OverlayReport, error) {
+			t.Helper()
+
+			o := MakeOverlay(opts...)
+			require.NoError(t, o.UnmarshalJSON([]byte(jazon)))
+
+			applied, report, err := o.Apply(sch)
+			output, merr := applied.MarshalJSON()
+			require.NoError(t, merr)
+
+			return string(output), report, err
+		}
+
+		t.Run("should copy a node and report touched nodes", func(t *testing.T) {
+			output, report, err := apply(t, `{
+				"overlay": "1.1.0",
+				"extends": "./pet.json",
+				"actions": [
+					{"target": "$.properties.*", "copy": "$['$defs'].name", "update": {"description": "copied"}},
+					{"target": "$.enum", "update": 3},
+					{"target": "$.nowhere", "remove": true}
+				]
+			}`)
+			require.NoError(t, err)
+			require.JSONEq(t, `{
+				"$id": "https://example.com/schemas/pet.json",
+				"$defs": {"name": {"type": "string", "minLength": 1}},
+				"properties": {
+					"name": {"title": "name", "type": "string", "minLength": 1, "description": "copied"},
+					"alias": {"type": "string", "minLength": 1, "description": "copied"}
+				},
+				"enum": [1, 2, 3]
+			}`, output)
+
+			require.Equal(t, 3, report.Len())
+			var touched [][]string
+			for action := range report.Actions() {
+				var pointers []string
+				for pointer := range action.Touched() {
+					pointers = append(pointers, pointer.String())
+				}
+				touched = append(touched, pointers)
+				require.Equal(t, len(touched)-1, action.Index())
+			}
+			require.Equal(t, [][]string{{"/properties/name", "/properties/alias"}, {"/enum"}, nil}, touched)
+		})
+
+		t.Run("should fail on a target matching nothing in strict mode", func(t *testing.T) {
+			_, _, err := apply(t, `{"actions": [{"target": "$.nowhere", "remove": true}]}`, WithOverlayStrict(true))
+			require.ErrorIs(t, err, overlay.ErrNoMatch)
+		})
+
+		t.Run("should fail on an update replacing an object in strict mode", func(t *testing.T) {
+			const jazon = `{"actions": [{"target": "$.properties.name", "update": false}]}`
+
+			_, _, err := apply(t, jazon, WithOverlayStrict(true))
+			require.ErrorIs(t, err, overlay.ErrOverlay)
+
+			output, _, err := apply(t, jazon)
+			require.NoError(t, err)
+			require.Contains(t, output, `"name":false`)
+		})
+
+		t.Run("should fail on a copy selecting several nodes", func(t *testing.T) {
+			output, _, err := apply(t, `{"actions": [{"target": "$.properties.name", "copy": "$.properties.*"}]}`)
+			require.ErrorIs(t, err, overlay.ErrOverlay)
+			require.Contains(t, output, `"title":"name"`)
+		})
+
+		t.Run("should fail on an overlay extending another document", func(t *testing.T) {
+			o := MakeOverlay()
+			require.NoError(t, o.UnmarshalJSON([]byte(`{"extends": "https://example.com/schemas/order.json", "actions": []}`)))
+
+			_, _, err := o.Apply(sch)
+			require.ErrorIs(t, err, overlay.ErrExtends)
+
+			unaltered, err := o.ApplyTo(sch).MarshalJSON()
+			require.NoError(t, err)
+			require.Contains(t, string(unaltered), `"enum":[1,2]`)
+		})
+	})
 }
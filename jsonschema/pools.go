@@ -4,9 +4,8 @@ import "github.com/fredbi/core/swag/pools"
 
 var (
 	// TODO: use go-openapi/swag/pools
-	poolOfOverlays        = pools.New[Overlay]()
-	poolOfSchemas         = pools.New[Schema]()
-	poolOfOverlayContexts = pools.New[overlayContext]()
-	poolOfOverlayOptions  = pools.New[overlayOptions]
-	poolOfOptions         = pools.New[options]
+	poolOfOverlays       = pools.New[Overlay]()
+	poolOfSchemas        = pools.New[Schema]()
+	poolOfOverlayOptions = pools.New[overlayOptions]
+	poolOfOptions        = pools.New[options]
 )
@@ -2,7 +2,7 @@ package jsonschema
 
 import (
 	"context"
-	"errors"
+	"fmt"
 )
 
 // Ref knows how to resolve a JSON reference
@@ -13,55 +13,127 @@ import (
 //   - for JSON schema draft 4 to 7: if a $ref is present, all other keys are ignored.
 //   - from JSON schema draft 2019 onwards, $ref is evaluated but sibling keys remain
 //
+// References are resolved by the [Resolver] held by a [ResolverContext] (see [ContextWithResolver]).
+//
 // TODO: check draft 6 assertion "it is now possible to describe instance properties named $ref"
 type Ref struct {
-	uri    string // TODO: URI
-	cached *Schema
+	uri     string
+	dynamic bool
+	cached  *cachedRef
+}
+
+// cachedRef is the resolution of a static [Ref] by a [Resolver], against a base URI.
+type cachedRef struct {
+	resolver *Resolver
+	base     string
+	resolved ResolvedSchema
+}
+
+// MakeRef builds a [Ref] for a "$ref" URI reference.
+func MakeRef(uri string) Ref {
+	return Ref{uri: uri}
+}
+
+// MakeDynamicRef builds a [Ref] for a "$dynamicRef" URI reference.
+func MakeDynamicRef(uri string) Ref {
+	return Ref{uri: uri, dynamic: true}
+}
+
+// IsDynamic tells if the [Ref] is a "$dynamicRef".
+func (r Ref) IsDynamic() bool {
+	return r.dynamic
 }
 
+// ResolverContext holds the state of the resolution of [Ref] s: the [Resolver], the current base URI
+// and the dynamic scope of the evaluation.
 type ResolverContext struct {
-	cache     map[string]Schema
-	basePath  string
-	baseStack []string // or container.List ?
+	resolver *Resolver
+	base     string
+	scope    DynamicScope
 }
 
-func defaultResolverContext() *ResolverContext {
+// NewResolverContext builds a [ResolverContext] to resolve [Ref] s found in the schema resource with a given base URI.
+func NewResolverContext(resolver *Resolver, base string) *ResolverContext {
+	return &ResolverContext{
+		resolver: resolver,
+		base:     base,
+		scope:    DynamicScope{}.Enter(base),
+	}
+}
+
+// Enter a schema resource: [Ref] s are now resolved against its base URI, and the dynamic scope is extended.
+func (c *ResolverContext) Enter(base string) *ResolverContext {
 	return &ResolverContext{
-		baseStack: []string{},
+		resolver: c.resolver,
+		base:     base,
+		scope:    c.scope.Enter(base),
 	}
 }
 
+// Base URI against which [Ref] s are resolved.
+func (c *ResolverContext) Base() string {
+	return c.base
+}
+
+func defaultResolverContext() *ResolverContext {
+	return NewResolverContext(NewResolver(), "")
+}
+
 type refCtxKey uint8
 
 const resolverKey refCtxKey = iota + 1
 
+// ContextWithResolver returns a [context.Context] holding a [ResolverContext].
+func ContextWithResolver(ctx context.Context, rctx *ResolverContext) context.Context {
+	return context.WithValue(ctx, resolverKey, rctx)
+}
+
 // Resolve a $ref as a [Schema]
-func (r *Ref) Resolve(ctx context.Context) (Schema, error) {
-	if r.cached != nil {
-		return *r.cached, nil
+//
+// If the context holds no [ResolverContext], the reference is resolved by a new [Resolver],
+// relative to the current working directory.
+//
+// Static references are resolved only once by the same [Resolver] against the same base URI.
+func (r *Ref) Resolve(ctx context.Context) (ResolvedSchema, error) {
+	rctx := resolverContextFrom(ctx)
+	if c := r.cached; c != nil && c.resolver == rctx.resolver && c.base == rctx.base {
+		return c.resolved, nil
 	}
 
-	rctx, ok := ctx.Value(resolverKey).(*ResolverContext)
-	if !ok {
-		rctx = defaultResolverContext()
-	}
+	if r.dynamic {
+		resolved, err := rctx.resolver.ResolveDynamic(r.uri, rctx.base, rctx.scope)
+		if err != nil {
+			return resolved, fmt.Errorf("%q: %w", r.uri, err)
+		}
 
-	// currentBase := "" // TODO
-	defer func() {
-		// TODO: only if base changes
-		rctx.baseStack = append(rctx.baseStack, "")
-	}()
+		return resolved, nil
+	}
 
-	// resolved = true
+	resolved, err := rctx.resolver.Resolve(r.uri, rctx.base)
+	if err != nil {
+		return resolved, fmt.Errorf("%q: %w", r.uri, err)
+	}
+	r.cached = &cachedRef{resolver: rctx.resolver, base: rctx.base, resolved: resolved}
 
-	return Schema{}, errors.ErrUnsupported
+	return resolved, nil
 }
 
 func (r *Ref) String() string {
-	return r.uri // TODO
+	return r.uri
 }
 
 // ResolveRecurse resolves a $ref recursively until all nested $ref are exhausted
-func (r Ref) ResolveRecurse(ctx context.Context) (Schema, error) {
-	return Schema{}, errors.ErrUnsupported
+func (r Ref) ResolveRecurse(ctx context.Context) (ResolvedSchema, error) {
+	rctx := resolverContextFrom(ctx)
+
+	return rctx.resolver.ResolveRecurse(r.uri, rctx.base)
+}
+
+func resolverContextFrom(ctx context.Context) *ResolverContext {
+	rctx, ok := ctx.Value(resolverKey).(*ResolverContext)
+	if !ok {
+		return defaultResolverContext()
+	}
+
+	return rctx
 }
@@ -0,0 +1,535 @@
+package jsonschema
+
+import (
+	"fmt"
+	"iter"
+	"net/url"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/swag/loading"
+)
+
+// Resolver resolves JSON references ("$ref", "$dynamicRef") in [Schema] s.
+//
+// A [Resolver] knows about schema resources, i.e. the documents it has loaded (or which have been added with
+// [Resolver.AddDocument]) and all the subschemas identified by an "$id" inside these documents.
+//
+// Base URIs are computed as specified by RFC 3986 through nested "$id" s (or "id" for draft 4 schemas).
+// Fragments are either JSON pointers relative to a schema resource, or plain names defined by
+// "$anchor" or "$dynamicAnchor" (or by a fragment-only "$id" for draft 4 to 7 schemas).
+//
+// Documents which are not known yet are loaded from the local file system or from HTTP(s) URLs,
+// possibly redirected to local directories mirroring remote locations (see [WithOfflineMirror]).
+//
+// A [Resolver] is not safe for concurrent use.
+type Resolver struct {
+	*resolverOptions
+
+	documents map[string]*resolvedDocument // documents by retrieval URI
+	resources map[string]*resource         // schema resources by absolute URI without fragment
+}
+
+// NewResolver builds a [Resolver] of JSON references.
+func NewResolver(opts ...ResolverOption) *Resolver {
+	return &Resolver{
+		resolverOptions: resolverOptionsWithDefaults(opts),
+		documents:       make(map[string]*resolvedDocument),
+		resources:       make(map[string]*resource),
+	}
+}
+
+// ResolvedSchema is a [Schema] obtained by resolving a JSON reference.
+type ResolvedSchema struct {
+	Schema
+
+	base    string
+	uri     string
+	pointer string
+}
+
+// BaseURI of the resolved schema, i.e. the URI against which references in this schema are resolved.
+func (r ResolvedSchema) BaseURI() string {
+	return r.base
+}
+
+// URI of the document holding the resolved schema.
+func (r ResolvedSchema) DocumentURI() string {
+	return r.uri
+}
+
+// Pointer is the JSON pointer to the resolved schema in its document.
+func (r ResolvedSchema) Pointer() string {
+	return r.pointer
+}
+
+// String is the canonical location of the resolved schema, as a document URI with a JSON pointer fragment.
+func (r ResolvedSchema) String() string {
+	return r.uri + "#" + r.pointer
+}
+
+// DynamicScope is the dynamic scope of a schema evaluation, used to resolve "$dynamicRef" s.
+//
+// It lists the base URIs of the schema resources entered during the evaluation, from the outermost one.
+type DynamicScope struct {
+	bases []string
+}
+
+// Enter a schema resource identified by its base URI.
+func (s DynamicScope) Enter(base string) DynamicScope {
+	if len(s.bases) > 0 && s.bases[len(s.bases)-1] == base {
+		return s
+	}
+
+	return DynamicScope{bases: append(slices.Clip(s.bases), base)}
+}
+
+// Bases yields the base URIs of the dynamic scope, from the outermost one.
+func (s DynamicScope) Bases() iter.Seq[string] {
+	return slices.Values(s.bases)
+}
+
+// ResolverCache caches the [Schema] s loaded by a [Resolver], by retrieval URI.
+//
+// A cache may be shared by several [Resolver] s.
+type ResolverCache interface {
+	Get(uri string) (Schema, bool)
+	Set(uri string, sch Schema)
+}
+
+// NewResolverCache builds a [ResolverCache] that keeps loaded documents in memory.
+//
+// It is safe for concurrent use.
+func NewResolverCache() ResolverCache {
+	return &memoryCache{
+		schemas: make(map[string]Schema),
+	}
+}
+
+type memoryCache struct {
+	mx      sync.RWMutex
+	schemas map[string]Schema
+}
+
+func (c *memoryCache) Get(uri string) (Schema, bool) {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	sch, ok := c.schemas[uri]
+
+	return sch, ok
+}
+
+func (c *memoryCache) Set(uri string, sch Schema) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.schemas[uri] = sch
+}
+
+type resolvedDocument struct {
+	uri   string
+	root  Schema
+	bases map[string]string // base URIs of the subschemas in the document, by JSON pointer
+}
+
+type resource struct {
+	uri            string
+	document       *resolvedDocument
+	pointer        string
+	anchors        map[string]string // JSON pointers in the document, by anchor name
+	dynamicAnchors map[string]struct{}
+}
+
+// AddDocument registers a [Schema] document, retrieved from uri.
+//
+// The uri is the initial base URI of the document. It may be empty or a relative file path.
+//
+// Schema resources identified by "$id" inside the document are registered as well.
+func (r *Resolver) AddDocument(uri string, sch Schema) error {
+	uri, _ = splitFragment(uri)
+	doc := &resolvedDocument{
+		uri:   uri,
+		root:  sch,
+		bases: make(map[string]string),
+	}
+	r.documents[uri] = doc
+
+	return r.index(doc, sch.Document, uri, "", sch.Version())
+}
+
+// Resolve a JSON reference against a base URI.
+func (r *Resolver) Resolve(ref, base string) (ResolvedSchema, error) {
+	uri, err := resolveURI(base, ref)
+	if err != nil {
+		return ResolvedSchema{}, err
+	}
+
+	resourceURI, fragment := splitFragment(uri)
+	res, err := r.resource(resourceURI)
+	if err != nil {
+		return ResolvedSchema{}, err
+	}
+
+	pointer := res.pointer
+	switch {
+	case fragment == "":
+	case strings.HasPrefix(fragment, "/"):
+		pointer += fragment
+	default:
+		anchored, ok := res.anchors[fragment]
+		if !ok {
+			return ResolvedSchema{}, fmt.Errorf("anchor %q not found in %q: %w", fragment, resourceURI, ErrRef)
+		}
+		pointer = anchored
+	}
+
+	return r.at(res.document, pointer)
+}
+
+// ResolveDynamic resolves a "$dynamicRef" against a base URI, in a [DynamicScope].
+//
+// The reference is first resolved like a "$ref". If the resolved schema defines a "$dynamicAnchor" matching
+// the fragment of the reference, the outermost schema resource in the dynamic scope that defines the same
+// "$dynamicAnchor" is used instead.
+func (r *Resolver) ResolveDynamic(ref, base string, scope DynamicScope) (ResolvedSchema, error) {
+	resolved, err := r.Resolve(ref, base)
+	if err != nil {
+		return resolved, err
+	}
+
+	_, fragment := splitFragment(ref)
+	if fragment == "" || strings.HasPrefix(fragment, "/") {
+		return resolved, nil
+	}
+
+	if res, ok := r.resources[resolved.base]; !ok || !res.hasDynamicAnchor(fragment) {
+		return resolved, nil
+	}
+
+	for outer := range scope.Bases() {
+		res, ok := r.resources[outer]
+		if !ok || !res.hasDynamicAnchor(fragment) {
+			continue
+		}
+
+		return r.at(res.document, res.anchors[fragment])
+	}
+
+	return resolved, nil
+}
+
+// ResolveRecurse resolves a JSON reference, then the "$ref" s of the resolved schemas
+// until a schema without "$ref" is found.
+//
+// Cyclic references are reported as an error wrapping [ErrRefCycle].
+func (r *Resolver) ResolveRecurse(ref, base string) (ResolvedSchema, error) {
+	visited := make(map[string]struct{})
+
+	for {
+		resolved, err := r.Resolve(ref, base)
+		if err != nil {
+			return resolved, err
+		}
+
+		location := resolved.String()
+		if _, seen := visited[location]; seen {
+			return resolved, fmt.Errorf("%q: %w: %w", location, ErrRefCycle, ErrRef)
+		}
+		visited[location] = struct{}{}
+
+		next, ok := resolved.AtKey("$ref")
+		if !ok || !next.IsString() {
+			return resolved, nil
+		}
+
+		ref, base = next.String(), resolved.base
+	}
+}
+
+// at builds the resolved schema at a JSON pointer in a document.
+func (r *Resolver) at(doc *resolvedDocument, pointer string) (ResolvedSchema, error) {
+	p, err := json.MakePointer(pointer)
+	if err != nil {
+		return ResolvedSchema{}, fmt.Errorf("invalid JSON pointer %q: %w: %w", pointer, err, ErrRef)
+	}
+
+	node, err := doc.root.GetPointer(p)
+	if err != nil {
+		return ResolvedSchema{}, fmt.Errorf("%q not found in %q: %w: %w", pointer, doc.uri, err, ErrRef)
+	}
+
+	return ResolvedSchema{
+		Schema:  Schema{Document: node, options: doc.root.options},
+		base:    doc.baseAt(pointer),
+		uri:     doc.uri,
+		pointer: pointer,
+	}, nil
+}
+
+// resource retrieves a schema resource, loading its document if not known yet.
+func (r *Resolver) resource(uri string) (*resource, error) {
+	if res, ok := r.resources[uri]; ok {
+		return res, nil
+	}
+
+	if _, loaded := r.documents[uri]; loaded {
+		return nil, fmt.Errorf("schema resource %q not found: %w", uri, ErrRef)
+	}
+
+	sch, err := r.load(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.AddDocument(uri, sch); err != nil {
+		return nil, err
+	}
+
+	res, ok := r.resources[uri]
+	if !ok {
+		return nil, fmt.Errorf("schema resource %q not found: %w", uri, ErrRef)
+	}
+
+	return res, nil
+}
+
+// load a document from the cache, a mirror, the local file system or a remote location.
+func (r *Resolver) load(uri string) (Schema, error) {
+	if sch, ok := r.cache.Get(uri); ok {
+		return sch, nil
+	}
+
+	location, mirrored, err := r.mirror(uri)
+	if err != nil {
+		return Schema{}, err
+	}
+	if !mirrored && isRemote(uri) && r.offline {
+		return Schema{}, fmt.Errorf("cannot load %q in offline mode: %w", uri, ErrRef)
+	}
+
+	var data []byte
+	if loading.YAMLMatcher(location) {
+		data, err = loading.YAMLDoc(location, r.loadingOptions...)
+	} else {
+		data, err = loading.LoadFromFileOrHTTP(location, r.loadingOptions...)
+	}
+	if err != nil {
+		return Schema{}, fmt.Errorf("cannot load %q: %w: %w", uri, err, ErrRef)
+	}
+
+	sch := Make(r.schemaOptions...)
+	if err := sch.UnmarshalJSON(data); err != nil {
+		return Schema{}, fmt.Errorf("invalid schema loaded from %q: %w: %w", uri, err, ErrRef)
+	}
+
+	r.cache.Set(uri, sch)
+
+	return sch, nil
+}
+
+// mirror returns the local location of a remote URI, using the longest matching mirrored prefix.
+//
+// A URI which would escape the directory of its mirror, e.g. with ".." segments, is rejected.
+func (r *Resolver) mirror(uri string) (string, bool, error) {
+	var (
+		dir, rel string
+		longest  = -1
+	)
+
+	for _, m := range r.mirrors {
+		rest, ok := strings.CutPrefix(uri, m.prefix)
+		if !ok || len(m.prefix) <= longest {
+			continue
+		}
+
+		longest = len(m.prefix)
+		dir, rel = m.dir, filepath.Clean(filepath.FromSlash(rest))
+	}
+
+	if longest < 0 {
+		return uri, false, nil
+	}
+
+	if !filepath.IsLocal(rel) {
+		return "", false, fmt.Errorf("cannot load %q outside of its mirror %q: %w", uri, dir, ErrRef)
+	}
+
+	return filepath.Join(dir, rel), true, nil
+}
+
+// index the schema resources, anchors and base URIs of a document.
+func (r *Resolver) index(doc *resolvedDocument, node json.Document, base, pointer string, version Version) error {
+	if pointer == "" {
+		r.addResource(base, doc, pointer)
+	}
+
+	if !node.IsObject() {
+		doc.bases[pointer] = base
+
+		return nil
+	}
+
+	if dollarSchema, ok := node.AtKey("$schema"); ok && dollarSchema.IsString() {
+		if v := VersionFromMetaSchemaURL(dollarSchema.String()); v != VersionUndefined {
+			version = v
+		}
+	}
+
+	if id, ok := schemaIDFor(node, version); ok {
+		uri, err := resolveURI(base, id)
+		if err != nil {
+			return err
+		}
+
+		resourceURI, fragment := splitFragment(uri)
+		if resourceURI != base {
+			base = resourceURI
+			r.addResource(base, doc, pointer)
+		}
+
+		if fragment != "" && !strings.HasPrefix(fragment, "/") {
+			// draft 4 to 7: a fragment in an id defines a plain name anchor
+			r.resources[base].anchors[fragment] = pointer
+		}
+	}
+
+	doc.bases[pointer] = base
+	res := r.resources[base]
+
+	if anchor, ok := node.AtKey("$anchor"); ok && anchor.IsString() {
+		res.anchors[anchor.String()] = pointer
+	}
+
+	if anchor, ok := node.AtKey("$dynamicAnchor"); ok && anchor.IsString() {
+		res.anchors[anchor.String()] = pointer
+		res.dynamicAnchors[anchor.String()] = struct{}{}
+	}
+
+	for sub := range Subschemas(node, version) {
+		if err := r.index(doc, sub.Schema, base, pointer+sub.Pointer(), version); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Resolver) addResource(uri string, doc *resolvedDocument, pointer string) {
+	r.resources[uri] = &resource{
+		uri:            uri,
+		document:       doc,
+		pointer:        pointer,
+		anchors:        make(map[string]string),
+		dynamicAnchors: make(map[string]struct{}),
+	}
+}
+
+func (res *resource) hasDynamicAnchor(name string) bool {
+	_, ok := res.dynamicAnchors[name]
+
+	return ok
+}
+
+// baseAt is the base URI of the schema at a JSON pointer, i.e. the base of the closest indexed subschema.
+func (doc *resolvedDocument) baseAt(pointer string) string {
+	for {
+		if base, ok := doc.bases[pointer]; ok {
+			return base
+		}
+
+		if pointer == "" {
+			return doc.uri
+		}
+
+		pointer = pointer[:strings.LastIndexByte(pointer, '/')]
+	}
+}
+
+// schemaIDFor returns the identifier of a schema object, i.e. "$id" or "id" for draft 4 schemas.
+//
+// For draft 4 to 7 schemas, the identifier is ignored when the schema has a "$ref".
+// When the version is unknown, "$id" is looked up first, then "id".
+func schemaIDFor(node json.Document, version Version) (string, bool) {
+	keys := []string{"$id"}
+
+	switch version {
+	case VersionUndefined:
+		keys = append(keys, "id")
+	case VersionDraft4, VersionDraft5, VersionOpenAPIv2, VersionOpenAPIv2Simple,
+		VersionOpenAPIv300, VersionOpenAPIv301, VersionOpenAPIv302, VersionOpenAPIv303, VersionOpenAPIv304:
+		keys = []string{"id"}
+	}
+
+	if _, hasRef := node.AtKey("$ref"); hasRef && version != VersionUndefined && version.Less(VersionDraft2019) {
+		return "", false
+	}
+
+	for _, key := range keys {
+		if id, ok := node.AtKey(key); ok && id.IsString() {
+			return id.String(), true
+		}
+	}
+
+	return "", false
+}
+
+// resolveURI resolves a URI reference against a base URI, as specified by RFC 3986.
+//
+// Bases without a scheme are considered file paths, and relative references are resolved as relative paths.
+func resolveURI(base, ref string) (string, error) {
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid URI reference %q: %w: %w", ref, err, ErrRef)
+	}
+
+	if refURL.IsAbs() {
+		return ref, nil
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URI %q: %w: %w", base, err, ErrRef)
+	}
+
+	if baseURL.IsAbs() {
+		return baseURL.ResolveReference(refURL).String(), nil
+	}
+
+	basePath, _ := splitFragment(base)
+	refPath, fragment := splitFragment(ref)
+
+	var resolved string
+	switch {
+	case refPath == "":
+		resolved = basePath
+	case path.IsAbs(refPath) || basePath == "":
+		resolved = refPath
+	default:
+		resolved = path.Join(path.Dir(basePath), refPath)
+	}
+
+	if strings.Contains(ref, "#") {
+		resolved += "#" + fragment
+	}
+
+	return resolved, nil
+}
+
+// splitFragment splits a URI into its part without fragment and its unescaped fragment.
+func splitFragment(uri string) (string, string) {
+	resource, fragment, _ := strings.Cut(uri, "#")
+	if unescaped, err := url.PathUnescape(fragment); err == nil {
+		fragment = unescaped
+	}
+
+	return resource, fragment
+}
+
+func isRemote(uri string) bool {
+	return strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://")
+}
@@ -0,0 +1,240 @@
+package jsonschema
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const remotesFixtures = "../fixtures/schemas/v4/jsonschema_suite/remotes"
+
+func TestResolver(t *testing.T) {
+	t.Run("with nested $id, anchors and pointers", func(t *testing.T) {
+		r := NewResolver(WithOfflineMode(true))
+		require.NoError(t, r.AddDocument("https://example.com/root.json", mustSchema(t, `{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"$id": "https://example.com/root.json",
+			"$defs": {
+				"A": {"$anchor": "foo", "type": "integer"},
+				"B": {
+					"$id": "other.json",
+					"$defs": {
+						"X": {"$anchor": "bar", "type": "string"},
+						"Y": {"$id": "t/inner.json", "type": "boolean"}
+					}
+				},
+				"a%b/c~d": {"type": "null"}
+			}
+		}`)))
+
+		for _, tc := range []struct {
+			ref, base        string
+			expectedBase     string
+			expectedPointer  string
+			expectedMaterial string
+		}{
+			{"#foo", "https://example.com/root.json", "https://example.com/root.json", "/$defs/A", `"integer"`},
+			{"#/$defs/A", "https://example.com/root.json", "https://example.com/root.json", "/$defs/A", `"integer"`},
+			{"other.json#bar", "https://example.com/root.json", "https://example.com/other.json", "/$defs/B/$defs/X", `"string"`},
+			{"#bar", "https://example.com/other.json", "https://example.com/other.json", "/$defs/B/$defs/X", `"string"`},
+			{"#/$defs/X", "https://example.com/other.json", "https://example.com/other.json", "/$defs/B/$defs/X", `"string"`},
+			{"t/inner.json", "https://example.com/other.json", "https://example.com/t/inner.json", "/$defs/B/$defs/Y", `"boolean"`},
+			{"#/$defs/a%25b~1c~0d", "https://example.com/root.json", "https://example.com/root.json", "/$defs/a%b~1c~0d", `"null"`},
+		} {
+			t.Run(tc.ref, func(t *testing.T) {
+				resolved, err := r.Resolve(tc.ref, tc.base)
+				require.NoError(t, err)
+
+				assert.Equal(t, tc.expectedBase, resolved.BaseURI())
+				assert.Equal(t, "https://example.com/root.json", resolved.DocumentURI())
+				assert.Equal(t, tc.expectedPointer, resolved.Pointer())
+
+				typ, ok := resolved.AtKey("type")
+				require.True(t, ok)
+				material, err := typ.MarshalJSON()
+				require.NoError(t, err)
+				assert.JSONEq(t, tc.expectedMaterial, string(material))
+			})
+		}
+
+		t.Run("should fail on an unknown anchor", func(t *testing.T) {
+			_, err := r.Resolve("#unknown", "https://example.com/root.json")
+			require.ErrorIs(t, err, ErrRef)
+		})
+
+		t.Run("should fail on an unknown pointer", func(t *testing.T) {
+			_, err := r.Resolve("#/$defs/Z", "https://example.com/root.json")
+			require.ErrorIs(t, err, ErrRef)
+		})
+	})
+
+	t.Run("with draft 4 fragment ids", func(t *testing.T) {
+		r := NewResolver()
+		require.NoError(t, r.AddDocument("schema.json", mustSchema(t, `{
+			"$schema": "http://json-schema.org/draft-04/schema#",
+			"definitions": {
+				"A": {"id": "#foo", "type": "integer"},
+				"B": {"id": "#ignored", "$ref": "#/definitions/A"}
+			}
+		}`)))
+
+		resolved, err := r.Resolve("#foo", "schema.json")
+		require.NoError(t, err)
+		assert.Equal(t, "/definitions/A", resolved.Pointer())
+
+		_, err = r.Resolve("#ignored", "schema.json")
+		require.ErrorIs(t, err, ErrRef)
+	})
+
+	t.Run("with $dynamicRef", func(t *testing.T) {
+		r := NewResolver(WithOfflineMode(true))
+		require.NoError(t, r.AddDocument("https://example.com/tree", mustSchema(t, `{
+			"$id": "https://example.com/tree",
+			"$dynamicAnchor": "node",
+			"type": "object",
+			"properties": {"children": {"items": {"$dynamicRef": "#node"}}}
+		}`)))
+		require.NoError(t, r.AddDocument("https://example.com/strict-tree", mustSchema(t, `{
+			"$id": "https://example.com/strict-tree",
+			"$dynamicAnchor": "node",
+			"$ref": "tree",
+			"unevaluatedProperties": false
+		}`)))
+
+		t.Run("should resolve statically outside of a dynamic scope", func(t *testing.T) {
+			resolved, err := r.ResolveDynamic("#node", "https://example.com/tree", DynamicScope{}.Enter("https://example.com/tree"))
+			require.NoError(t, err)
+			assert.Equal(t, "https://example.com/tree", resolved.BaseURI())
+		})
+
+		t.Run("should resolve to the outermost dynamic anchor", func(t *testing.T) {
+			rctx := NewResolverContext(r, "https://example.com/strict-tree").Enter("https://example.com/tree")
+			ref := MakeDynamicRef("#node")
+			require.True(t, ref.IsDynamic())
+
+			resolved, err := ref.Resolve(ContextWithResolver(context.Background(), rctx))
+			require.NoError(t, err)
+			assert.Equal(t, "https://example.com/strict-tree", resolved.BaseURI())
+			assert.Equal(t, "https://example.com/strict-tree#", resolved.String())
+		})
+	})
+
+	t.Run("with cyclic references", func(t *testing.T) {
+		r := NewResolver()
+		require.NoError(t, r.AddDocument("cycle.json", mustSchema(t, `{
+			"$defs": {
+				"a": {"$ref": "#/$defs/b"},
+				"b": {"$ref": "#/$defs/a"},
+				"c": {"$ref": "#/$defs/d"},
+				"d": {"type": "string"}
+			}
+		}`)))
+
+		_, err := r.ResolveRecurse("#/$defs/a", "cycle.json")
+		require.ErrorIs(t, err, ErrRefCycle)
+
+		resolved, err := r.ResolveRecurse("#/$defs/c", "cycle.json")
+		require.NoError(t, err)
+		assert.Equal(t, "/$defs/d", resolved.Pointer())
+	})
+
+	t.Run("with offline mirror", func(t *testing.T) {
+		cache := NewResolverCache()
+		opts := []ResolverOption{
+			WithOfflineMirror("http://localhost:1234/", remotesFixtures),
+			WithOfflineMode(true),
+			WithResolverCache(cache),
+		}
+		r := NewResolver(opts...)
+
+		t.Run("should resolve a remote reference", func(t *testing.T) {
+			resolved, err := r.Resolve("http://localhost:1234/integer.json", "")
+			require.NoError(t, err)
+			assert.Equal(t, "http://localhost:1234/integer.json", resolved.BaseURI())
+			_, ok := resolved.AtKey("type")
+			assert.True(t, ok)
+		})
+
+		t.Run("should resolve a remote reference recursively", func(t *testing.T) {
+			ref := MakeRef("subSchemas.json#/refToInteger")
+			ctx := ContextWithResolver(context.Background(), NewResolverContext(r, "http://localhost:1234/"))
+
+			resolved, err := ref.ResolveRecurse(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "http://localhost:1234/subSchemas.json#/integer", resolved.String())
+		})
+
+		t.Run("should resolve a reference relative to a remote folder", func(t *testing.T) {
+			resolved, err := r.Resolve("folderInteger.json", "http://localhost:1234/folder/")
+			require.NoError(t, err)
+			assert.Equal(t, "http://localhost:1234/folder/folderInteger.json", resolved.DocumentURI())
+		})
+
+		t.Run("should share the cache of loaded documents", func(t *testing.T) {
+			_, ok := cache.Get("http://localhost:1234/integer.json")
+			require.True(t, ok)
+
+			shared := NewResolver(WithOfflineMode(true), WithResolverCache(cache))
+			_, err := shared.Resolve("http://localhost:1234/integer.json", "")
+			require.NoError(t, err)
+		})
+
+		t.Run("should not load documents outside of the mirror", func(t *testing.T) {
+			for _, uri := range []string{
+				"http://localhost:1234/../../../../resolver_test.go",
+				"http://localhost:1234/folder/../../resolver.go",
+				"http://localhost:1234//etc/passwd",
+			} {
+				_, _, err := r.mirror(uri)
+				require.ErrorIsf(t, err, ErrRef, "expected %q to be rejected", uri)
+
+				_, err = r.load(uri)
+				require.ErrorIs(t, err, ErrRef)
+			}
+
+			location, ok, err := r.mirror("http://localhost:1234/folder/../integer.json")
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, filepath.Join(remotesFixtures, "integer.json"), location)
+		})
+
+		t.Run("should not load unmirrored remote documents", func(t *testing.T) {
+			_, err := r.Resolve("https://example.com/unknown.json", "")
+			require.ErrorIs(t, err, ErrRef)
+		})
+	})
+
+	t.Run("with a reference resolved against several base URIs", func(t *testing.T) {
+		r := NewResolver()
+		require.NoError(t, r.AddDocument("https://example.com/a/leaf.json", mustSchema(t, `{"type": "string"}`)))
+		require.NoError(t, r.AddDocument("https://example.com/b/leaf.json", mustSchema(t, `{"type": "integer"}`)))
+		ref := MakeRef("leaf.json")
+
+		for _, base := range []string{"https://example.com/a/", "https://example.com/b/", "https://example.com/a/"} {
+			resolved, err := ref.Resolve(ContextWithResolver(context.Background(), NewResolverContext(r, base)))
+			require.NoError(t, err)
+			assert.Equal(t, base+"leaf.json", resolved.DocumentURI())
+		}
+	})
+
+	t.Run("with local files", func(t *testing.T) {
+		ref := MakeRef("integer.json")
+		ctx := ContextWithResolver(context.Background(), NewResolverContext(NewResolver(), remotesFixtures+"/name.json"))
+
+		resolved, err := ref.Resolve(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, remotesFixtures+"/integer.json", resolved.DocumentURI())
+	})
+}
+
+func mustSchema(t *testing.T, jazon string) Schema {
+	t.Helper()
+
+	sch := Make()
+	require.NoError(t, sch.UnmarshalJSON([]byte(jazon)))
+
+	return sch
+}
@@ -8,13 +8,8 @@ import (
 
 	"github.com/fredbi/core/json"
 	"github.com/fredbi/core/json/lexers"
-	"github.com/fredbi/core/json/lexers/token"
-	codes "github.com/fredbi/core/json/nodes/error-codes"
 	"github.com/fredbi/core/json/nodes/light"
-	"github.com/fredbi/core/json/stores"
-	"github.com/fredbi/core/json/stores/values"
 	"github.com/fredbi/core/jsonschema/analyzers"
-	"github.com/fredbi/core/jsonschema/meta"
 )
 
 // SchemaType is one of the seven JSON schema simple types,
@@ -31,6 +26,28 @@ const (
 	SchemaTypeBool
 )
 
+// String representation of a [SchemaType], as used by the "type" keyword.
+func (t SchemaType) String() string {
+	switch t {
+	case SchemaTypeNull:
+		return "null"
+	case SchemaTypeObject:
+		return "object"
+	case SchemaTypeArray:
+		return "array"
+	case SchemaTypeString:
+		return "string"
+	case SchemaTypeNumber:
+		return "number"
+	case SchemaTypeInteger:
+		return "integer"
+	case SchemaTypeBool:
+		return "boolean"
+	default:
+		return fmt.Sprintf("SchemaType(%d)", uint8(t))
+	}
+}
+
 var EmptySchema = Schema{}
 
 // Schema represents a valid JSON schema specification.
@@ -49,6 +66,7 @@ type Schema struct {
 	metadata   Metadata
 	extensions analyzers.Extensions
 	extras     []*light.Node
+	source     Source
 }
 
 // Make builds an empty JSON schema.
@@ -72,6 +90,47 @@ func (s Schema) IsDefined() bool {
 	return s.defined
 }
 
+// Version of JSON schema for this [Schema].
+//
+// This is the version enforced with [WithVersion] or, if none is enforced,
+// the version identified by the "$schema" keyword of the schema (see [VersionFromMetaSchemaURL]).
+//
+// It returns [VersionUndefined] if the version is unknown.
+func (s Schema) Version() Version {
+	if s.options != nil && s.version != VersionUndefined {
+		return s.version
+	}
+
+	dollarSchema, ok := s.AtKey("$schema")
+	if !ok {
+		return VersionUndefined
+	}
+
+	v, ok := dollarSchema.Value()
+	if !ok || !dollarSchema.IsString() {
+		return VersionUndefined
+	}
+
+	return VersionFromMetaSchemaURL(v.String())
+}
+
+// Source locates the [Schema] in the input it has been loaded from (see [Collection.DecodeAppend]).
+//
+// The [Source] is zero when unknown.
+func (s Schema) Source() Source {
+	return s.source
+}
+
+// SetSource sets the location of the [Schema] in the input it has been loaded from.
+func (s *Schema) SetSource(source Source) {
+	s.source = source
+}
+
+// UsesDollarData tells if the "$data" extension is enabled for this [Schema] (see [WithDollarData]).
+func (s Schema) UsesDollarData() bool {
+	return s.options != nil && s.useDollarData
+}
+
 // Core definitions for this [Schema].
 //
 // See https://json-schema.org/draft/2020-12/meta/core
@@ -95,7 +154,7 @@ func (s Schema) Applicator() Applicator {
 	return Applicator{}
 }
 
-// HasMetadata tells if there is a non-empty [meta.Data] for this [Schema].
+// HasMetadata tells if there is a non-empty [Metadata] for this [Schema].
 func (s Schema) HasMetadata() bool {
 	return false
 }
@@ -103,9 +162,9 @@ func (s Schema) HasMetadata() bool {
 // Metadata definitions for this [Schema].
 //
 // See https://json-schema.org/draft/2020-12/meta/meta-data
-func (s Schema) Metadata() meta.Data {
+func (s Schema) Metadata() Metadata {
 	// title, description, examples, $deprecated, $id, readOnly, writeOnly, $comment...
-	return meta.Data{}
+	return s.metadata
 }
 
 // HasValidation tells if there is a non-empty [Validation] for this [Schema].
@@ -162,84 +221,71 @@ func (s *Schema) decode(lex lexers.Lexer) error {
 	context.L = lex
 	context.S = s.Store()
 	context.DO = s.hooks()
-	octx := poolOfOverlayContexts.Borrow()
-	octx.initialLevel = lex.IndentLevel()
-	context.X = octx
 
 	n := s.Node()
 	n.Decode(context)
 	redeemContext()
-	poolOfOverlayContexts.Redeem(octx)
 
 	return lex.Err()
 }
 
 func (s *Schema) hooks() light.DecodeOptions {
 	decodeOptions := s.DecodeOptions
-	decodeOptions.NodeHook = s.mustBeBoolOrObject
-	decodeOptions.BeforeKey = s.beforeKey
+	decodeOptions.OnExit = s.afterKey
 
-	return decodeOptions
+	return schemaConstraints.Hooks(decodeOptions)
 }
 
-type schemaContext struct {
-	initialLevel   int
-	isBoolOrObject bool
-
-	anchor stores.Handle
-}
-
-func (s *Schema) mustBeBoolOrObject(
-	ctx *light.ParentContext,
-	l lexers.Lexer,
-	tok token.T,
-) (skip bool, err error) {
-	octx, ok := ctx.X.(*schemaContext)
-	if !ok {
-		return false, nil
+func (s *Schema) afterKey(ctx *light.ParentContext, _ lexers.Lexer, ev light.HookEvent) (light.Action, error) {
+	if ev.Depth != 1 || !ev.HasKey() {
+		// only keywords of this schema are interpreted here
+		return light.Continue, nil
 	}
 
-	if octx.isBoolOrObject {
-		return false, nil
+	key, n := ev.Key, ev.Node
+	if s.UsesDollarData() && n.IsObject() {
+		if _, ok := dollarDataKeys[key]; ok {
+			if value := json.NewBuilder(s.Store()).WithRoot(n).Document(); IsDataRef(key.String(), value) {
+				ref, err := MakeDataRef(key.String(), value)
+				if err != nil {
+					return light.Continue, fmt.Errorf("invalid keyword %q: %w", key.String(), err)
+				}
+				s.validation.decodeDataRef(ref)
+
+				return light.Continue, nil
+			}
+		}
 	}
-	level := l.IndentLevel() - octx.initialLevel
-
-	switch {
-	case level == 0 && tok.Kind() == token.Boolean:
-		fallthrough
-	case level == 1 && tok.IsStartObject():
-		octx.isBoolOrObject = true
 
-		return false, nil
-	default:
-		return false, fmt.Errorf(
-			"a boolean or an object is expected. Got: %v: %w",
-			tok,
-			codes.ErrNode,
-		)
+	if c, ok := keywordConstraints[key]; ok {
+		if err := c.Check(n, s.Store()); err != nil {
+			return light.Continue, fmt.Errorf("invalid keyword %q: %w", key.String(), err)
+		}
 	}
-}
 
-func (s *Schema) beforeKey(ctx *light.ParentContext, l lexers.Lexer, key values.InternedKey) (skip bool, err error) {
-	// TODO
 	if _, isCore := coreKeys[key]; isCore {
-		err := s.core.decode(ctx, key)
-		return false, err
+		return light.Continue, s.core.decode(ctx, key, n)
 	}
 
 	if _, isApplicator := applicatorKeys[key]; isApplicator {
-		err := s.applicator.decode(ctx, key, &s.core.version)
-		return false, err
+		return light.Continue, s.applicator.decode(ctx, key, n, &s.core.version)
 	}
 
 	if _, isValidation := validationKeys[key]; isValidation {
-		err := s.validation.decode(ctx, key, &s.core.version)
-		return false, err
+		return light.Continue, s.validation.decode(ctx, key, n, &s.core.version)
 	}
 
 	if _, isMetadata := metadataKeys[key]; isMetadata {
-		err := s.metadata.decode(ctx, key, &s.core.version)
-		return false, err
+		return light.Continue, s.metadata.decode(ctx, key, n, &s.core.version)
+	}
+
+	// custom keywords
+	if s.options != nil && s.vocabularies != nil {
+		if keyword, _, ok := s.vocabularies.Keyword(key.String()); ok {
+			if err := s.decodeCustom(keyword, n); err != nil {
+				return light.Continue, err
+			}
+		}
 	}
 
 	// extensions
@@ -247,19 +293,30 @@ func (s *Schema) beforeKey(ctx *light.ParentContext, l lexers.Lexer, key values.
 		if s.extensions == nil {
 			s.extensions = make(analyzers.Extensions)
 		}
-		// consume node
-		// switch context
-		var n light.Node
-		n.Decode(ctx)
-		// switch back context
 		doc := json.NewBuilder(s.Store()).WithRoot(n).Document() // TODO: pool
 		s.extensions.Add(ext, doc)
 
-		return
+		return light.Continue, nil
 	}
 
 	// extra key
 	// TODO
 
-	return false, nil
+	return light.Continue, nil
+}
+
+// decodeCustom checks the value of a custom keyword declared by a [Vocabulary].
+func (s *Schema) decodeCustom(keyword Keyword, n light.Node) error {
+	if keyword.Shape != nil {
+		if err := keyword.Shape.Check(n, s.Store()); err != nil {
+			return fmt.Errorf("invalid keyword %q: %w", keyword.Name, err)
+		}
+	}
+
+	if !keyword.Versions.Allows(s.version) {
+		return fmt.Errorf("keyword %q is not supported by JSON schema %v: %w", keyword.Name, s.version, ErrVersion)
+	}
+	s.core.version = s.core.version.Merge(keyword.Versions)
+
+	return nil
 }
@@ -0,0 +1,55 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaDecode(t *testing.T) {
+	t.Run("should decode a valid schema", func(t *testing.T) {
+		for _, valid := range []string{
+			`true`,
+			`{}`,
+			`{"type": "string", "x-go-type": {"name": "X"}}`,
+			`{"type": ["string", "null"], "items": [{}, true], "required": ["a"]}`,
+			`{"allOf": [{"type": "object"}, false], "properties": {"a": {}}}`,
+		} {
+			t.Run(valid, func(t *testing.T) {
+				s := Make()
+				require.NoError(t, s.UnmarshalJSON([]byte(valid)))
+
+				output, err := s.MarshalJSON()
+				require.NoError(t, err)
+				require.JSONEq(t, valid, string(output))
+			})
+		}
+	})
+
+	t.Run("should hold extensions", func(t *testing.T) {
+		s := Make()
+		require.NoError(t, s.UnmarshalJSON([]byte(`{"type": "string", "x-go-type": {"name": "X"}}`)))
+		require.True(t, s.HasExtensions())
+		require.True(t, s.Extensions().Has("x-go-type"))
+	})
+
+	t.Run("should not decode an invalid schema", func(t *testing.T) {
+		for _, invalid := range []string{
+			`[]`,
+			`"string"`,
+			`{"type": 1}`,
+			`{"type": ["string", 1]}`,
+			`{"items": "x"}`,
+			`{"items": [1]}`,
+			`{"allOf": {}}`,
+			`{"not": []}`,
+			`{"required": [true]}`,
+			`{"properties": []}`,
+		} {
+			t.Run(invalid, func(t *testing.T) {
+				s := Make()
+				require.Error(t, s.UnmarshalJSON([]byte(invalid)))
+			})
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package jsonschema
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Source locates a [Schema] in the input it has been loaded from.
+//
+// Sources are stable: loading the same inputs in the same order always yields the same sources.
+// Analyzers report them along with their findings, e.g. in audit trails and error messages.
+type Source struct {
+	// File is the name of the file the schema has been loaded from, or empty if it has been decoded from a reader
+	File string
+
+	// Document is the index of the YAML document holding the schema, in a stream of several YAML documents
+	Document int
+
+	// Pointer is the JSON pointer to the schema in its document, e.g. "/2" for the third schema of a JSON array
+	Pointer string
+}
+
+// At is the [Source] of a value at some JSON pointer in the schema.
+func (s Source) At(pointer string) Source {
+	s.Pointer += pointer
+
+	return s
+}
+
+// IsZero tells if the [Source] is unknown.
+func (s Source) IsZero() bool {
+	return s == Source{}
+}
+
+// String representation of a [Source], e.g. "schemas.yaml[1]#/properties/id".
+//
+// The index of the YAML document is only rendered after the first document of a stream.
+func (s Source) String() string {
+	var b strings.Builder
+	b.WriteString(s.File)
+
+	if s.Document > 0 {
+		b.WriteString("[")
+		b.WriteString(strconv.Itoa(s.Document))
+		b.WriteString("]")
+	}
+
+	b.WriteString("#")
+	b.WriteString(s.Pointer)
+
+	return b.String()
+}
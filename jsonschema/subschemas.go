@@ -0,0 +1,322 @@
+package jsonschema
+
+import (
+	"iter"
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/json"
+)
+
+// SubschemaKind tells how the value of a keyword holds subschemas.
+type SubschemaKind uint8
+
+const (
+	// NoSubschema is the kind of keywords which don't hold subschemas, e.g. "maxLength" or "enum".
+	NoSubschema SubschemaKind = iota
+
+	// SubschemaSingle is the kind of keywords which value is a schema, e.g. "not".
+	SubschemaSingle
+
+	// SubschemaArray is the kind of keywords which value is an array of schemas, e.g. "allOf".
+	SubschemaArray
+
+	// SubschemaMap is the kind of keywords which value is an object with schemas as members, e.g. "properties".
+	SubschemaMap
+)
+
+func (k SubschemaKind) String() string {
+	switch k {
+	case SubschemaSingle:
+		return "schema"
+	case SubschemaArray:
+		return "array of schemas"
+	case SubschemaMap:
+		return "map of schemas"
+	default:
+		return "value"
+	}
+}
+
+// subschemaKeywords are the keywords of all versions of JSON schema which hold subschemas.
+//
+// "items" holds an array of schemas prior to draft 2020, when its value is an array (see [SubschemaKindOf]).
+//
+//nolint:gochecknoglobals // keywords specifications
+var subschemaKeywords = map[string]SubschemaKind{
+	"additionalItems":       SubschemaSingle,
+	"additionalProperties":  SubschemaSingle,
+	"contains":              SubschemaSingle,
+	"contentSchema":         SubschemaSingle,
+	"else":                  SubschemaSingle,
+	"if":                    SubschemaSingle,
+	"items":                 SubschemaSingle,
+	"not":                   SubschemaSingle,
+	"propertyNames":         SubschemaSingle,
+	"then":                  SubschemaSingle,
+	"unevaluatedItems":      SubschemaSingle,
+	"unevaluatedProperties": SubschemaSingle,
+	"allOf":                 SubschemaArray,
+	"anyOf":                 SubschemaArray,
+	"oneOf":                 SubschemaArray,
+	"prefixItems":           SubschemaArray,
+	"$defs":                 SubschemaMap,
+	"definitions":           SubschemaMap,
+	"dependencies":          SubschemaMap,
+	"dependentSchemas":      SubschemaMap,
+	"patternProperties":     SubschemaMap,
+	"properties":            SubschemaMap,
+}
+
+// SubschemaKeyword tells how a keyword holds subschemas, regardless of its value.
+//
+// "items" is reported as a [SubschemaSingle] keyword: use [SubschemaKindOf] to tell an array of schemas.
+func SubschemaKeyword(keyword string) SubschemaKind {
+	return subschemaKeywords[keyword]
+}
+
+// SubschemaKindOf tells how the value of a keyword holds subschemas, in a schema of some version of JSON schema.
+//
+// "items" holds an array of schemas when its value is an array, prior to draft 2020 or when the version
+// is undefined. Values which cannot hold subschemas, e.g. a "properties" which is not an object,
+// are reported as [NoSubschema].
+func SubschemaKindOf(keyword string, value json.Document, version Version) SubschemaKind {
+	kind := subschemaKeywords[keyword]
+
+	switch kind {
+	case SubschemaSingle:
+		if keyword == "items" && value.IsArray() && AllowsItemsArray(version) {
+			return SubschemaArray
+		}
+
+		if !IsSchemaValue(value) {
+			return NoSubschema
+		}
+	case SubschemaArray:
+		if !value.IsArray() {
+			return NoSubschema
+		}
+	case SubschemaMap:
+		if !value.IsObject() {
+			return NoSubschema
+		}
+	}
+
+	return kind
+}
+
+// AllowsItemsArray tells if "items" may be an array of schemas, i.e. prior to draft 2020 or OpenAPI 3.1.
+//
+// An undefined version allows it.
+func AllowsItemsArray(version Version) bool {
+	if version.isOpenAPI() {
+		return version.Less(VersionOpenAPIv310)
+	}
+
+	return version == VersionUndefined || version.Less(VersionDraft2020)
+}
+
+// IsSchemaValue tells if a JSON value may be a schema, i.e. an object or a boolean.
+func IsSchemaValue(value json.Document) bool {
+	return value.IsObject() || value.IsBool()
+}
+
+// Subschema is a subschema held by a keyword of a schema.
+type Subschema struct {
+	// Schema is the subschema, as a JSON object or boolean.
+	Schema json.Document
+
+	// Keyword holding the subschema, e.g. "properties".
+	Keyword string
+
+	// Kind of the keyword holding the subschema, as resolved from its value (see [SubschemaKindOf]).
+	Kind SubschemaKind
+
+	// Name of the member holding the subschema in a [SubschemaMap] keyword, e.g. a property name.
+	Name string
+
+	// Index of the subschema in a [SubschemaArray] keyword, or -1.
+	Index int
+}
+
+// Tokens are the unescaped reference tokens of the JSON pointer to the subschema, relative to its parent schema,
+// e.g. ["properties", "id"] or ["allOf", "0"].
+func (s Subschema) Tokens() []string {
+	switch s.Kind {
+	case SubschemaArray:
+		return []string{s.Keyword, strconv.Itoa(s.Index)}
+	case SubschemaMap:
+		return []string{s.Keyword, s.Name}
+	default:
+		return []string{s.Keyword}
+	}
+}
+
+// Pointer to the subschema, relative to its parent schema, e.g. "/properties/id" or "/allOf/0".
+func (s Subschema) Pointer() string {
+	var pointer strings.Builder
+	for _, token := range s.Tokens() {
+		pointer.WriteByte('/')
+		pointer.WriteString(json.EscapeToken(token))
+	}
+
+	return pointer.String()
+}
+
+// Subschemas yields the immediate subschemas of a schema, in the order of its keywords.
+//
+// Members and elements which are not schemas are skipped, e.g. the property dependencies of "dependencies".
+func Subschemas(schema json.Document, version Version) iter.Seq[Subschema] {
+	return func(yield func(Subschema) bool) {
+		if !schema.IsObject() {
+			return
+		}
+
+		for keyword, value := range schema.Pairs() {
+			switch kind := SubschemaKindOf(keyword, value, version); kind {
+			case SubschemaSingle:
+				if !yield(Subschema{Schema: value, Keyword: keyword, Kind: kind, Index: -1}) {
+					return
+				}
+			case SubschemaArray:
+				for i, elem := range value.IndexedElems() {
+					if !IsSchemaValue(elem) {
+						continue
+					}
+
+					if !yield(Subschema{Schema: elem, Keyword: keyword, Kind: kind, Index: i}) {
+						return
+					}
+				}
+			case SubschemaMap:
+				for name, member := range value.Pairs() {
+					if !IsSchemaValue(member) {
+						continue
+					}
+
+					if !yield(Subschema{Schema: member, Keyword: keyword, Kind: kind, Name: name, Index: -1}) {
+						return
+					}
+				}
+			case NoSubschema:
+			}
+		}
+	}
+}
+
+// SchemaVisit describes a schema visited by [WalkSchemas].
+type SchemaVisit struct {
+	json.Visit
+
+	// Keyword holding the visited schema, or the empty string for the root schema.
+	Keyword string
+
+	// Name of the member holding the visited schema in a [SubschemaMap] keyword, e.g. a property name.
+	Name string
+}
+
+// IsRoot tells if the visited schema is the root of the walked document.
+func (v SchemaVisit) IsRoot() bool {
+	return v.Depth == 0
+}
+
+// SchemaWalkFunc is a callback invoked by [WalkSchemas] for every visited schema.
+type SchemaWalkFunc func(v SchemaVisit) (json.WalkAction, error)
+
+// WalkSchemas visits a schema document and all its subschemas, depth-first.
+//
+// It is a [json.Walk] that only visits schemas: values of keywords which don't hold subschemas, e.g. "enum"
+// or "const", are not walked into. The pre and post callbacks are called like those of [json.Walk],
+// and either may be nil.
+//
+// Subschemas are located according to the version of JSON schema of the document (see [SubschemaKindOf]).
+func WalkSchemas(root json.Document, version Version, pre, post SchemaWalkFunc) error {
+	w := schemaWalker{version: version, pre: pre, post: post}
+
+	return json.Walk(root, w.visitPre, w.visitPost)
+}
+
+// schemaRole tells what a node visited by a [json.Walk] is, when walking schemas.
+type schemaRole struct {
+	kind    SubschemaKind // NoSubschema for a schema, the kind of the holding keyword for containers of schemas
+	keyword string
+	schema  bool
+}
+
+type schemaWalker struct {
+	version Version
+	pre     SchemaWalkFunc
+	post    SchemaWalkFunc
+	roles   []schemaRole // by depth
+}
+
+func (w *schemaWalker) visitPre(v json.Visit) (json.WalkAction, error) {
+	w.roles = w.roles[:v.Depth]
+
+	role, visit, ok := w.roleOf(v)
+	if !ok {
+		return json.WalkSkip, nil
+	}
+	w.roles = append(w.roles, role)
+
+	if !role.schema || w.pre == nil {
+		return json.WalkContinue, nil
+	}
+
+	return w.pre(visit)
+}
+
+func (w *schemaWalker) visitPost(v json.Visit) (json.WalkAction, error) {
+	if len(w.roles) <= v.Depth || w.post == nil {
+		// skipped node
+		return json.WalkContinue, nil
+	}
+
+	role := w.roles[v.Depth]
+	w.roles = w.roles[:v.Depth]
+	if !role.schema {
+		return json.WalkContinue, nil
+	}
+
+	visit := SchemaVisit{Visit: v, Keyword: role.keyword}
+	if role.kind == SubschemaMap {
+		visit.Name = v.Key
+	}
+
+	return w.post(visit)
+}
+
+// roleOf tells if a node visited by a [json.Walk] is a schema, a container of schemas, or a value to skip.
+func (w *schemaWalker) roleOf(v json.Visit) (schemaRole, SchemaVisit, bool) {
+	visit := SchemaVisit{Visit: v}
+
+	if v.Depth == 0 {
+		return schemaRole{schema: true}, visit, IsSchemaValue(v.Node)
+	}
+
+	parent := w.roles[v.Depth-1]
+	if parent.schema {
+		kind := SubschemaKindOf(v.Key, v.Node, w.version)
+		switch kind {
+		case NoSubschema:
+			return schemaRole{}, visit, false
+		case SubschemaSingle:
+			visit.Keyword = v.Key
+
+			return schemaRole{keyword: v.Key, schema: true}, visit, true
+		default:
+			return schemaRole{kind: kind, keyword: v.Key}, visit, true
+		}
+	}
+
+	if !IsSchemaValue(v.Node) {
+		return schemaRole{}, visit, false
+	}
+
+	visit.Keyword = parent.keyword
+	if parent.kind == SubschemaMap {
+		visit.Name = v.Key
+	}
+
+	return schemaRole{kind: parent.kind, keyword: parent.keyword, schema: true}, visit, true
+}
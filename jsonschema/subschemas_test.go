@@ -0,0 +1,89 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/json"
+)
+
+func TestSubschemas(t *testing.T) {
+	const schema = `{
+		"type": "object",
+		"properties": {"a/b": {"type": "string"}, "c": true},
+		"enum": [{"not": {}}],
+		"const": {"properties": {"x": {}}},
+		"dependencies": {"a": ["c"], "c": {"required": ["a"]}},
+		"items": [{"minimum": 1}, false],
+		"allOf": [{"$ref": "#/$defs/d"}, 1],
+		"$defs": {"d": {"not": {"maxLength": 1}}}
+	}`
+
+	t.Run("should locate keywords holding subschemas", func(t *testing.T) {
+		assert.Equal(t, SubschemaMap, SubschemaKeyword("properties"))
+		assert.Equal(t, SubschemaSingle, SubschemaKeyword("items"))
+		assert.Equal(t, NoSubschema, SubschemaKeyword("enum"))
+
+		items := mustDocument(t, `[{}]`)
+		assert.Equal(t, SubschemaArray, SubschemaKindOf("items", items, VersionDraft7))
+		assert.Equal(t, SubschemaArray, SubschemaKindOf("items", items, VersionUndefined))
+		assert.Equal(t, NoSubschema, SubschemaKindOf("items", items, VersionDraft2020))
+		assert.Equal(t, NoSubschema, SubschemaKindOf("properties", items, VersionDraft2020))
+		assert.Equal(t, "array of schemas", SubschemaArray.String())
+	})
+
+	t.Run("should yield immediate subschemas", func(t *testing.T) {
+		var pointers []string
+		for sub := range Subschemas(mustDocument(t, schema), VersionDraft7) {
+			pointers = append(pointers, sub.Pointer())
+		}
+
+		assert.Equal(t, []string{
+			"/properties/a~1b", "/properties/c", "/dependencies/c", "/items/0", "/items/1", "/allOf/0", "/$defs/d",
+		}, pointers)
+	})
+
+	t.Run("should walk all subschemas", func(t *testing.T) {
+		var pre, post []string
+		require.NoError(t, WalkSchemas(mustDocument(t, schema), VersionDraft7,
+			func(v SchemaVisit) (json.WalkAction, error) {
+				pre = append(pre, v.Pointer.String())
+				if v.Keyword == "dependencies" {
+					assert.Equal(t, "c", v.Name)
+				}
+
+				return json.WalkContinue, nil
+			},
+			func(v SchemaVisit) (json.WalkAction, error) {
+				post = append(post, v.Pointer.String())
+
+				return json.WalkContinue, nil
+			},
+		))
+
+		assert.Equal(t, []string{
+			"", "/properties/a~1b", "/properties/c", "/dependencies/c", "/items/0", "/items/1", "/allOf/0",
+			"/$defs/d", "/$defs/d/not",
+		}, pre)
+		assert.ElementsMatch(t, pre, post)
+		assert.Equal(t, "", post[len(post)-1])
+	})
+
+	t.Run("should skip subschemas", func(t *testing.T) {
+		var visited []string
+		require.NoError(t, WalkSchemas(mustDocument(t, schema), VersionDraft2020,
+			func(v SchemaVisit) (json.WalkAction, error) {
+				visited = append(visited, v.Pointer.String())
+				if v.Keyword == "$defs" {
+					return json.WalkSkip, nil
+				}
+
+				return json.WalkContinue, nil
+			}, nil,
+		))
+
+		assert.Equal(t, []string{"", "/properties/a~1b", "/properties/c", "/dependencies/c", "/allOf/0", "/$defs/d"}, visited)
+	})
+}
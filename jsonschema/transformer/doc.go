@@ -0,0 +1,27 @@
+// Package transformer mutates JSON data as directed by a schema.
+//
+// A [Transformer] is built for a [github.com/fredbi/core/jsonschema.Schema] and produces a new
+// [github.com/fredbi/core/json.Document] from some JSON data:
+//
+//   - absent properties are filled with the "default" value of their schema, honoring "readOnly" and "writeOnly"
+//     when the [Direction] of the data is known
+//   - properties not allowed by a false "additionalProperties" are removed
+//   - strings are coerced to the "type" of their schema, which is useful to process query parameters or
+//     configuration read from the environment
+//
+// The original data is left unchanged. Every change is reported at its JSON pointer:
+//
+//	t, err := transformer.New(schema, transformer.WithDirection(transformer.DirectionWrite))
+//	if err != nil {
+//		return err
+//	}
+//
+//	transformed, report, err := t.Transform(data)
+//	if err != nil {
+//		return err
+//	}
+//
+//	for change := range report.Changes() {
+//		fmt.Println(change)
+//	}
+package transformer
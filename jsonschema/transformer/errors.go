@@ -0,0 +1,13 @@
+package transformer
+
+// Error is an error raised by the [Transformer].
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrTransformer is the generic error raised by this package.
+	ErrTransformer Error = "transformer error"
+)
@@ -0,0 +1,129 @@
+package transformer
+
+import (
+	"github.com/fredbi/core/jsonschema"
+)
+
+// Direction tells in which direction data is exchanged, to honor "readOnly" and "writeOnly".
+type Direction uint8
+
+const (
+	// DirectionAny ignores "readOnly" and "writeOnly".
+	DirectionAny Direction = iota
+
+	// DirectionRead is for data read from an API, e.g. a response: "writeOnly" properties are not filled
+	// with their default value.
+	DirectionRead
+
+	// DirectionWrite is for data written to an API, e.g. a request: "readOnly" properties are not filled
+	// with their default value.
+	DirectionWrite
+)
+
+func (d Direction) String() string {
+	switch d {
+	case DirectionRead:
+		return "read"
+	case DirectionWrite:
+		return "write"
+	default:
+		return "any"
+	}
+}
+
+// Option customizes the behavior of the [Transformer].
+type Option func(*options)
+
+type options struct {
+	applyDefaults    bool
+	removeAdditional bool
+	coerceTypes      bool
+	direction        Direction
+	resolver         *jsonschema.Resolver
+	baseURI          string
+	version          jsonschema.Version
+}
+
+// WithApplyDefaults fills absent properties with the "default" value of their schema.
+//
+// This is enabled by default.
+func WithApplyDefaults(enabled bool) Option {
+	return func(o *options) {
+		o.applyDefaults = enabled
+	}
+}
+
+// WithRemoveAdditional removes the properties which are not allowed by a false "additionalProperties".
+//
+// This is enabled by default.
+func WithRemoveAdditional(enabled bool) Option {
+	return func(o *options) {
+		o.removeAdditional = enabled
+	}
+}
+
+// WithCoerceTypes converts strings to the "type" of their schema, when strings are not allowed by this schema,
+// e.g. "12" to 12 for an integer, "true" to true for a boolean or "" to null.
+//
+// This is enabled by default.
+func WithCoerceTypes(enabled bool) Option {
+	return func(o *options) {
+		o.coerceTypes = enabled
+	}
+}
+
+// WithDirection sets the [Direction] of the transformed data.
+//
+// The default is [DirectionAny].
+func WithDirection(direction Direction) Option {
+	return func(o *options) {
+		o.direction = direction
+	}
+}
+
+// WithResolver equips the [Transformer] with a [jsonschema.Resolver] to resolve "$ref" s.
+//
+// By default, a new [jsonschema.Resolver] is used.
+func WithResolver(resolver *jsonschema.Resolver) Option {
+	return func(o *options) {
+		o.resolver = resolver
+	}
+}
+
+// WithBaseURI sets the URI of the schema, against which relative "$ref" s are resolved.
+//
+// The default is "schema.json".
+func WithBaseURI(uri string) Option {
+	return func(o *options) {
+		o.baseURI = uri
+	}
+}
+
+// WithVersion sets the version of JSON schema for the schemas that don't declare a meta-schema.
+//
+// The default is [jsonschema.VersionDraft2020].
+func WithVersion(version jsonschema.Version) Option {
+	return func(o *options) {
+		o.version = version
+	}
+}
+
+func optionsWithDefaults(opts []Option) *options {
+	o := &options{
+		applyDefaults:    true,
+		removeAdditional: true,
+		coerceTypes:      true,
+		baseURI:          "schema.json",
+		version:          jsonschema.VersionDraft2020,
+	}
+
+	for _, apply := range opts {
+		apply(o)
+	}
+
+	if o.resolver == nil {
+		o.resolver = jsonschema.NewResolver()
+	}
+
+	return o
+}
@@ -0,0 +1,90 @@
+package transformer
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// ChangeKind qualifies a [Change] made by the [Transformer].
+type ChangeKind uint8
+
+const (
+	// ChangeDefault is an absent property filled with its "default" value.
+	ChangeDefault ChangeKind = iota + 1
+
+	// ChangeRemoved is a property removed because of a false "additionalProperties".
+	ChangeRemoved
+
+	// ChangeCoerced is a string converted to the "type" of its schema.
+	ChangeCoerced
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeDefault:
+		return "default"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeCoerced:
+		return "coerced"
+	default:
+		return "unknown"
+	}
+}
+
+// Change made to the transformed data.
+type Change struct {
+	Kind ChangeKind
+
+	// Pointer is the JSON pointer to the changed value, in the original data.
+	//
+	// Added properties are located at the pointer where they are added.
+	Pointer string
+
+	// From is the original value, for removed and coerced values.
+	From json.Document
+
+	// To is the new value, for defaulted and coerced values.
+	To json.Document
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeDefault:
+		return fmt.Sprintf("%v at %q: %s", c.Kind, c.Pointer, keywords.Text(c.To))
+	case ChangeRemoved:
+		return fmt.Sprintf("%v at %q", c.Kind, c.Pointer)
+	default:
+		return fmt.Sprintf("%v at %q: %s to %s", c.Kind, c.Pointer, keywords.Text(c.From), keywords.Text(c.To))
+	}
+}
+
+// Report lists the [Change] s made by the [Transformer], in the order of the transformed data.
+type Report struct {
+	changes []Change
+}
+
+// Len is the number of changes.
+func (r Report) Len() int {
+	return len(r.changes)
+}
+
+// Changes yields all changes.
+func (r Report) Changes() iter.Seq[Change] {
+	return slices.Values(r.changes)
+}
+
+// Change at a JSON pointer, if any.
+func (r Report) Change(pointer string) (Change, bool) {
+	for _, change := range r.changes {
+		if change.Pointer == pointer {
+			return change, true
+		}
+	}
+
+	return Change{}, false
+}
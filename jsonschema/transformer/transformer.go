@@ -0,0 +1,81 @@
+package transformer
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+)
+
+// Transformer transforms JSON data as directed by a [jsonschema.Schema].
+//
+// The data is walked along with the schemas that apply in place at every location: the schema itself, the
+// schemas referred to by "$ref" and the members of "allOf". Conditional applicators, i.e. "anyOf", "oneOf", "not"
+// and "if", are not followed, since they don't tell which schema the data should conform to.
+//
+// A [Transformer] is not safe for concurrent use.
+type Transformer struct {
+	*options
+
+	root     node
+	patterns map[string]*regexp.Regexp
+	refs     map[string]jsonschema.ResolvedSchema
+}
+
+// New [Transformer] for a [jsonschema.Schema].
+//
+// The schema is registered by the [jsonschema.Resolver] of the transformer, at the URI set by [WithBaseURI].
+func New(sch jsonschema.Schema, opts ...Option) (*Transformer, error) {
+	t := &Transformer{
+		options:  optionsWithDefaults(opts),
+		patterns: make(map[string]*regexp.Regexp),
+		refs:     make(map[string]jsonschema.ResolvedSchema),
+	}
+
+	if err := t.resolver.AddDocument(t.baseURI, sch); err != nil {
+		return nil, fmt.Errorf("%w: %w", err, ErrTransformer)
+	}
+
+	root, err := t.resolver.Resolve("#", t.baseURI)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, ErrTransformer)
+	}
+
+	t.root = t.nodeOf(root, t.version)
+
+	return t, nil
+}
+
+// Transform JSON data into a new [json.Document], with a [Report] of all changes.
+//
+// Errors, e.g. when a "$ref" cannot be resolved, wrap [ErrTransformer].
+func (t *Transformer) Transform(data json.Document) (json.Document, Report, error) {
+	w := &walk{Transformer: t}
+
+	roots, err := w.closure([]node{t.root})
+	if err != nil {
+		return data, Report{}, err
+	}
+
+	if err := w.transform(roots, data, ""); err != nil {
+		return data, Report{}, err
+	}
+
+	transformed, err := w.apply(data)
+	if err != nil {
+		return data, Report{}, err
+	}
+
+	return transformed, Report{changes: w.changes}, nil
+}
+
+// TransformBytes transforms JSON data given as bytes.
+func (t *Transformer) TransformBytes(data []byte) (json.Document, Report, error) {
+	doc := json.Make()
+	if err := doc.UnmarshalJSON(data); err != nil {
+		return doc, Report{}, fmt.Errorf("invalid JSON data: %w: %w", err, ErrTransformer)
+	}
+
+	return t.Transform(doc)
+}
@@ -0,0 +1,169 @@
+package transformer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+)
+
+func TestTransformer(t *testing.T) {
+	const schema = `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"size": {"type": "integer", "default": 10},
+			"ratio": {"type": ["number", "null"]},
+			"enabled": {"type": "boolean"},
+			"id": {"type": "string", "readOnly": true, "default": "auto"},
+			"secret": {"$ref": "#/$defs/secret"},
+			"tags": {"type": "array", "items": {"type": "integer"}},
+			"nested": {
+				"type": "object",
+				"properties": {"level": {"type": "integer", "default": 1}},
+				"additionalProperties": false
+			}
+		},
+		"patternProperties": {"^x-": {"type": "boolean"}},
+		"additionalProperties": false,
+		"$defs": {
+			"secret": {"type": "string", "writeOnly": true, "default": "changeme"}
+		}
+	}`
+
+	t.Run("should transform data", func(t *testing.T) {
+		tr, err := New(mustSchema(t, schema))
+		require.NoError(t, err)
+
+		transformed, report, err := tr.TransformBytes([]byte(`{
+			"name": "12",
+			"ratio": "",
+			"enabled": "true",
+			"tags": ["1", "x", 2],
+			"x-debug": "false",
+			"unknown": {"a": "1"},
+			"nested": {"other": 1}
+		}`))
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{
+			"name": "12",
+			"ratio": null,
+			"enabled": true,
+			"tags": [1, "x", 2],
+			"x-debug": false,
+			"nested": {"level": 1},
+			"size": 10,
+			"id": "auto",
+			"secret": "changeme"
+		}`, jsonString(t, transformed))
+
+		changes := make([]string, 0, report.Len())
+		for change := range report.Changes() {
+			changes = append(changes, change.String())
+		}
+
+		assert.Equal(t, []string{
+			`coerced at "/ratio": "" to null`,
+			`coerced at "/enabled": "true" to true`,
+			`coerced at "/tags/0": "1" to 1`,
+			`coerced at "/x-debug": "false" to false`,
+			`removed at "/unknown"`,
+			`removed at "/nested/other"`,
+			`default at "/nested/level": 1`,
+			`default at "/size": 10`,
+			`default at "/id": "auto"`,
+			`default at "/secret": "changeme"`,
+		}, changes)
+
+		change, ok := report.Change("/unknown")
+		require.True(t, ok)
+		assert.Equal(t, ChangeRemoved, change.Kind)
+	})
+
+	t.Run("should honor the direction of data", func(t *testing.T) {
+		tr, err := New(mustSchema(t, schema), WithDirection(DirectionWrite))
+		require.NoError(t, err)
+
+		transformed, _, err := tr.TransformBytes([]byte(`{}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"size": 10, "secret": "changeme"}`, jsonString(t, transformed))
+
+		tr, err = New(mustSchema(t, schema), WithDirection(DirectionRead))
+		require.NoError(t, err)
+
+		transformed, _, err = tr.TransformBytes([]byte(`{}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"size": 10, "id": "auto"}`, jsonString(t, transformed))
+	})
+
+	t.Run("should disable transformations", func(t *testing.T) {
+		tr, err := New(mustSchema(t, schema),
+			WithApplyDefaults(false), WithRemoveAdditional(false), WithCoerceTypes(false),
+		)
+		require.NoError(t, err)
+
+		const data = `{"size": "3", "unknown": 1}`
+		transformed, report, err := tr.TransformBytes([]byte(data))
+		require.NoError(t, err)
+		assert.Zero(t, report.Len())
+		assert.JSONEq(t, data, jsonString(t, transformed))
+	})
+
+	t.Run("should follow allOf and coerce the root", func(t *testing.T) {
+		tr, err := New(mustSchema(t, `{"allOf": [{"type": "number"}, {"type": "integer"}]}`))
+		require.NoError(t, err)
+
+		transformed, _, err := tr.TransformBytes([]byte(`"42"`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `42`, jsonString(t, transformed))
+
+		transformed, report, err := tr.TransformBytes([]byte(`"4.2"`))
+		require.NoError(t, err)
+		assert.Zero(t, report.Len())
+		assert.JSONEq(t, `"4.2"`, jsonString(t, transformed))
+	})
+
+	t.Run("should ignore siblings of $ref before draft 2019", func(t *testing.T) {
+		tr, err := New(mustSchema(t, `{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"definitions": {"a": {"properties": {"a": {"default": 1}}}},
+			"$ref": "#/definitions/a",
+			"properties": {"b": {"default": 2}}
+		}`))
+		require.NoError(t, err)
+
+		transformed, _, err := tr.TransformBytes([]byte(`{}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"a": 1}`, jsonString(t, transformed))
+	})
+
+	t.Run("should fail on unresolved references", func(t *testing.T) {
+		tr, err := New(mustSchema(t, `{"$ref": "#/$defs/missing"}`))
+		require.NoError(t, err)
+
+		_, _, err = tr.TransformBytes([]byte(`1`))
+		require.ErrorIs(t, err, ErrTransformer)
+	})
+}
+
+func mustSchema(t *testing.T, input string) jsonschema.Schema {
+	t.Helper()
+
+	s := jsonschema.Make()
+	require.NoError(t, s.UnmarshalJSON([]byte(input)))
+
+	return s
+}
+
+func jsonString(t *testing.T, doc json.Document) string {
+	t.Helper()
+
+	data, err := doc.MarshalJSON()
+	require.NoError(t, err)
+
+	return string(data)
+}
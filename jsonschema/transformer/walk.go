@@ -0,0 +1,540 @@
+package transformer
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// node locates a schema that applies to some data.
+type node struct {
+	schema  json.Document
+	uri     string // URI of the document holding the schema
+	pointer string // JSON pointer to the schema in its document
+	base    string // base URI to resolve references
+	version jsonschema.Version
+}
+
+func (t *Transformer) nodeOf(resolved jsonschema.ResolvedSchema, version jsonschema.Version) node {
+	if declared := resolved.Version(); declared != jsonschema.VersionUndefined {
+		version = declared
+	}
+
+	return node{
+		schema:  resolved.Document,
+		uri:     resolved.DocumentURI(),
+		pointer: resolved.Pointer(),
+		base:    resolved.BaseURI(),
+		version: version,
+	}
+}
+
+// since tells if the schema follows the semantics of some version of JSON schema, or a later one.
+func (n node) since(version jsonschema.Version) bool {
+	return keywords.Since(n.version, version)
+}
+
+// walk holds the state of the transformation of some JSON data.
+type walk struct {
+	*Transformer
+
+	changes []Change
+}
+
+// child node for the subschema of a keyword, followed by some tokens.
+//
+// A subschema that declares an identifier gets a new base URI.
+func (w *walk) child(n node, schema json.Document, tokens ...string) node {
+	pointer := n.pointer
+	for _, token := range tokens {
+		pointer = appendPointer(pointer, token)
+	}
+
+	c := node{
+		schema:  schema,
+		uri:     n.uri,
+		pointer: pointer,
+		base:    n.base,
+		version: n.version,
+	}
+
+	if !schema.IsObject() {
+		return c
+	}
+
+	_, hasID := schema.AtKey("$id")
+	if _, hasDraft4ID := schema.AtKey("id"); !hasID && !hasDraft4ID {
+		return c
+	}
+
+	if resolved, err := w.resolver.Resolve(keywords.FragmentRef(pointer), n.uri); err == nil {
+		c.base = resolved.BaseURI()
+	}
+
+	return c
+}
+
+// closure of schemas that apply in place, following "$ref" and "allOf".
+func (w *walk) closure(nodes []node) ([]node, error) {
+	var (
+		out  []node
+		seen = make(map[string]struct{})
+	)
+
+	var visit func(node) error
+	visit = func(n node) error {
+		if !n.schema.IsObject() {
+			return nil
+		}
+
+		location := n.uri + "#" + n.pointer
+		if _, ok := seen[location]; ok {
+			return nil
+		}
+		seen[location] = struct{}{}
+
+		if ref, ok := keywords.StringAt(n.schema, "$ref"); ok {
+			resolved, err := w.resolve(ref, n.base)
+			if err != nil {
+				return fmt.Errorf("cannot resolve %q at %q: %w: %w", ref, location, err, ErrTransformer)
+			}
+
+			if err := visit(w.nodeOf(resolved, n.version)); err != nil {
+				return err
+			}
+
+			if !n.since(jsonschema.VersionDraft2019) {
+				// keywords next to a "$ref" are ignored
+				return nil
+			}
+		}
+
+		out = append(out, n)
+
+		for sub := range jsonschema.Subschemas(n.schema, n.version) {
+			if sub.Keyword != "allOf" {
+				continue
+			}
+
+			if err := visit(w.child(n, sub.Schema, sub.Tokens()...)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, n := range nodes {
+		if err := visit(n); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// resolve a "$ref" against a base URI.
+func (w *walk) resolve(ref, base string) (jsonschema.ResolvedSchema, error) {
+	key := base + " " + ref
+	if resolved, ok := w.refs[key]; ok {
+		return resolved, nil
+	}
+
+	resolved, err := w.resolver.Resolve(ref, base)
+	if err != nil {
+		return resolved, err
+	}
+	w.refs[key] = resolved
+
+	return resolved, nil
+}
+
+// transform records the changes to JSON data at some location, as directed by the schemas that apply to it.
+func (w *walk) transform(nodes []node, data json.Document, at string) error {
+	switch {
+	case data.IsString():
+		if w.coerceTypes {
+			w.coerce(nodes, data, at)
+		}
+
+		return nil
+	case data.IsObject():
+		return w.transformObject(nodes, data, at)
+	case data.IsArray():
+		return w.transformArray(nodes, data, at)
+	default:
+		return nil
+	}
+}
+
+func (w *walk) transformObject(nodes []node, data json.Document, at string) error {
+	for name, value := range data.Pairs() {
+		children, allowed, err := w.propertySchemas(nodes, name)
+		if err != nil {
+			return err
+		}
+
+		location := appendPointer(at, name)
+		if !allowed && w.removeAdditional {
+			w.changes = append(w.changes, Change{Kind: ChangeRemoved, Pointer: location, From: value})
+
+			continue
+		}
+
+		closure, err := w.closure(children)
+		if err != nil {
+			return err
+		}
+
+		if err := w.transform(closure, value, location); err != nil {
+			return err
+		}
+	}
+
+	if w.applyDefaults {
+		return w.defaults(nodes, data, at)
+	}
+
+	return nil
+}
+
+// propertySchemas are the schemas of a property.
+//
+// A property is not allowed when some schema has a false "additionalProperties" that applies to it.
+func (w *walk) propertySchemas(nodes []node, name string) ([]node, bool, error) {
+	var children []node
+	allowed := true
+
+	for _, n := range nodes {
+		matched := false
+
+		if properties, ok := n.schema.AtKey("properties"); ok {
+			if property, ok := properties.AtKey(name); ok {
+				children = append(children, w.child(n, property, "properties", name))
+				matched = true
+			}
+		}
+
+		if patternProperties, ok := n.schema.AtKey("patternProperties"); ok {
+			for pattern, property := range patternProperties.Pairs() {
+				re, err := w.compile(pattern)
+				if err != nil {
+					return nil, false, err
+				}
+
+				if re.MatchString(name) {
+					children = append(children, w.child(n, property, "patternProperties", pattern))
+					matched = true
+				}
+			}
+		}
+
+		additional, ok := n.schema.AtKey("additionalProperties")
+		if matched || !ok {
+			continue
+		}
+
+		if additional.IsBool() {
+			v, _ := additional.Value()
+			allowed = allowed && v.Bool()
+
+			continue
+		}
+
+		children = append(children, w.child(n, additional, "additionalProperties"))
+	}
+
+	return children, allowed, nil
+}
+
+// defaults records the properties missing in an object, which have a "default" value.
+func (w *walk) defaults(nodes []node, data json.Document, at string) error {
+	var added []string
+
+	for _, n := range nodes {
+		properties, ok := n.schema.AtKey("properties")
+		if !ok || !properties.IsObject() {
+			continue
+		}
+
+		for name, property := range properties.Pairs() {
+			if _, present := data.AtKey(name); present || slices.Contains(added, name) {
+				continue
+			}
+
+			closure, err := w.closure([]node{w.child(n, property, "properties", name)})
+			if err != nil {
+				return err
+			}
+
+			value, ok := w.defaultOf(closure)
+			if !ok {
+				continue
+			}
+
+			added = append(added, name)
+			w.changes = append(w.changes, Change{Kind: ChangeDefault, Pointer: appendPointer(at, name), To: value})
+		}
+	}
+
+	return nil
+}
+
+// defaultOf is the first "default" value found in the schemas of a property, unless the direction
+// of the data excludes this property.
+func (w *walk) defaultOf(nodes []node) (json.Document, bool) {
+	var (
+		value json.Document
+		found bool
+	)
+
+	for _, n := range nodes {
+		switch {
+		case w.direction == DirectionWrite && keywords.IsTrue(n.schema, "readOnly"):
+			return value, false
+		case w.direction == DirectionRead && keywords.IsTrue(n.schema, "writeOnly"):
+			return value, false
+		}
+
+		if found {
+			continue
+		}
+
+		value, found = n.schema.AtKey("default")
+	}
+
+	return value, found
+}
+
+func (w *walk) transformArray(nodes []node, data json.Document, at string) error {
+	for i, elem := range data.IndexedElems() {
+		var children []node
+
+		for _, n := range nodes {
+			if child, ok := w.itemSchema(n, i); ok {
+				children = append(children, child)
+			}
+		}
+
+		closure, err := w.closure(children)
+		if err != nil {
+			return err
+		}
+
+		if err := w.transform(closure, elem, appendPointer(at, strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// itemSchema is the schema of the i-th element of an array.
+func (w *walk) itemSchema(n node, i int) (node, bool) {
+	prefix, tuple := "prefixItems", "items"
+	if jsonschema.AllowsItemsArray(n.version) {
+		prefix, tuple = "items", "additionalItems"
+	}
+
+	if prefixItems, ok := n.schema.AtKey(prefix); ok && jsonschema.SubschemaKindOf(prefix, prefixItems, n.version) == jsonschema.SubschemaArray {
+		if i < prefixItems.Len() {
+			item, _ := prefixItems.Elem(i)
+
+			return w.child(n, item, prefix, strconv.Itoa(i)), true
+		}
+
+		if items, ok := n.schema.AtKey(tuple); ok && jsonschema.SubschemaKindOf(tuple, items, n.version) == jsonschema.SubschemaSingle {
+			return w.child(n, items, tuple), true
+		}
+
+		return node{}, false
+	}
+
+	if items, ok := n.schema.AtKey("items"); ok && jsonschema.SubschemaKindOf("items", items, n.version) == jsonschema.SubschemaSingle {
+		return w.child(n, items, "items"), true
+	}
+
+	return node{}, false
+}
+
+//nolint:gochecknoglobals // JSON numbers, as accepted by coercion
+var numberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// coerce records the conversion of a string to the type of its schemas, when strings are not allowed.
+//
+// Candidate types are tried in this order: integer, number, boolean, null.
+func (w *walk) coerce(nodes []node, data json.Document, at string) {
+	var declared [][]string
+	for _, n := range nodes {
+		types, ok := typesOf(n.schema)
+		if !ok {
+			continue
+		}
+
+		if slices.Contains(types, "string") {
+			return
+		}
+
+		declared = append(declared, types)
+	}
+
+	if len(declared) == 0 {
+		return
+	}
+
+	allows := func(name string) bool {
+		for _, types := range declared {
+			if !slices.Contains(types, name) && (name != "integer" || !slices.Contains(types, "number")) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	text := data.String()
+	var coerced string
+
+	switch {
+	case allows("integer") && numberPattern.MatchString(text) && isInteger(text):
+		coerced = text
+	case allows("number") && numberPattern.MatchString(text):
+		coerced = text
+	case allows("boolean") && (text == "true" || text == "false"):
+		coerced = text
+	case allows("null") && (text == "" || text == "null"):
+		coerced = "null"
+	default:
+		return
+	}
+
+	value := json.Make()
+	if err := value.UnmarshalJSON([]byte(coerced)); err != nil {
+		return
+	}
+
+	w.changes = append(w.changes, Change{Kind: ChangeCoerced, Pointer: at, From: data, To: value})
+}
+
+func isInteger(text string) bool {
+	r, ok := new(big.Rat).SetString(text)
+
+	return ok && r.IsInt()
+}
+
+// apply the recorded changes to the data.
+func (w *walk) apply(data json.Document) (json.Document, error) {
+	b := json.NewBuilder(data.Store()).From(data)
+
+	for _, change := range w.changes {
+		switch change.Kind {
+		case ChangeCoerced:
+			p, err := json.MakePointer(change.Pointer)
+			if err != nil {
+				return data, fmt.Errorf("%w: %w", err, ErrTransformer)
+			}
+			b.AtPointer(p, change.To)
+		case ChangeRemoved:
+			p, err := json.MakePointer(change.Pointer)
+			if err != nil {
+				return data, fmt.Errorf("%w: %w", err, ErrTransformer)
+			}
+			b.AtPointerRemove(p)
+		case ChangeDefault:
+			parent, name := splitPointer(change.Pointer)
+			p, err := json.MakePointer(parent)
+			if err != nil {
+				return data, fmt.Errorf("%w: %w", err, ErrTransformer)
+			}
+
+			member, err := memberDocument(name, change.To)
+			if err != nil {
+				return data, fmt.Errorf("%w: %w", err, ErrTransformer)
+			}
+			b.AtPointerMerge(p, member)
+		}
+	}
+
+	if err := b.Err(); err != nil {
+		return data, fmt.Errorf("%w: %w", err, ErrTransformer)
+	}
+
+	return b.Document(), nil
+}
+
+// memberDocument builds a JSON object with a single member.
+func memberDocument(name string, value json.Document) (json.Document, error) {
+	key, err := stdjson.Marshal(name)
+	if err != nil {
+		return json.Document{}, err
+	}
+
+	member, err := value.MarshalJSON()
+	if err != nil {
+		return json.Document{}, err
+	}
+
+	doc := json.Make()
+	if err := doc.UnmarshalJSON([]byte("{" + string(key) + ":" + string(member) + "}")); err != nil {
+		return json.Document{}, err
+	}
+
+	return doc, nil
+}
+
+// compile a regular expression of "patternProperties".
+func (w *walk) compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := w.patterns[pattern]; ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w: %w", pattern, err, ErrTransformer)
+	}
+	w.patterns[pattern] = re
+
+	return re, nil
+}
+
+// typesOf lists the types declared by the "type" of a schema.
+func typesOf(schema json.Document) ([]string, bool) {
+	value, ok := schema.AtKey("type")
+	switch {
+	case !ok:
+		return nil, false
+	case value.IsString():
+		return []string{value.String()}, true
+	case value.IsArray():
+		types := make([]string, 0, value.Len())
+		for elem := range value.Elems() {
+			types = append(types, elem.String())
+		}
+
+		return types, true
+	default:
+		return nil, false
+	}
+}
+
+func appendPointer(pointer string, token string) string {
+	return pointer + "/" + json.EscapeToken(token)
+}
+
+// splitPointer splits a JSON pointer into the pointer to its parent and its last, unescaped, token.
+func splitPointer(pointer string) (string, string) {
+	i := strings.LastIndex(pointer, "/")
+	if i < 0 {
+		return "", json.UnescapeToken(pointer)
+	}
+
+	return pointer[:i], json.UnescapeToken(pointer[i+1:])
+}
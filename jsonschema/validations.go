@@ -63,6 +63,8 @@ type Validation struct {
 	objectV []ObjectValidation
 	arrayV  []ArrayValidation
 	enumV   []EnumValidation
+
+	dataRefs []DataRef // keywords which value is a "$data" reference
 }
 
 var (
@@ -156,10 +158,15 @@ var (
 	}
 )
 
-func (v *Validation) decode(ctx *light.ParentContext, key values.InternedKey, vr *VersionRequirements) error {
+func (v *Validation) decode(_ *light.ParentContext, _ values.InternedKey, _ light.Node, _ *VersionRequirements) error {
 	return nil // TODO
 }
 
+func (v *Validation) decodeDataRef(ref DataRef) {
+	v.defined = true
+	v.dataRefs = append(v.dataRefs, ref)
+}
+
 type NumberValidation struct{}
 type StringValidation struct{}
 type ObjectValidation struct{}
@@ -176,6 +183,34 @@ func (v Validation) IsDefined() bool {
 	return v.defined
 }
 
+// HasDataRefs tells if some keywords are "$data" references (see [WithDollarData]).
+func (v Validation) HasDataRefs() bool {
+	return len(v.dataRefs) > 0
+}
+
+// DataRefs yields the keywords which value is a "$data" reference.
+func (v Validation) DataRefs() iter.Seq[DataRef] {
+	return slices.Values(v.dataRefs)
+}
+
+// DataRef returns the "$data" reference of a keyword, if any.
+func (v Validation) DataRef(keyword string) (DataRef, bool) {
+	for _, ref := range v.dataRefs {
+		if ref.Keyword() == keyword {
+			return ref, true
+		}
+	}
+
+	return DataRef{}, false
+}
+
+// IsDynamic tells if the value of a keyword is only known at validation time, i.e. is a "$data" reference.
+func (v Validation) IsDynamic(keyword string) bool {
+	_, ok := v.DataRef(keyword)
+
+	return ok
+}
+
 func (v Validation) HasType() bool {
 	return len(v.types) > 0
 }
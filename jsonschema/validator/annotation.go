@@ -0,0 +1,278 @@
+package validator
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// Annotation is a value contributed to some JSON data by an annotation keyword of a schema.
+//
+// Locations follow the "basic" output format of JSON schema.
+type Annotation struct {
+	// InstanceLocation is the JSON pointer to the annotated value in the data.
+	InstanceLocation string
+
+	// KeywordLocation is the JSON pointer to the annotation keyword, following the "$ref" s evaluated
+	// from the root schema.
+	KeywordLocation string
+
+	// AbsoluteKeywordLocation is the URI of the annotation keyword, with a JSON pointer fragment in its document.
+	AbsoluteKeywordLocation string
+
+	// Keyword is the annotation keyword, e.g. "title" or "deprecated".
+	Keyword string
+
+	// Value of the keyword in the schema.
+	Value json.Document
+}
+
+func (a Annotation) String() string {
+	return fmt.Sprintf("at %q: %q: %s", a.InstanceLocation, a.Keyword, keywords.Text(a.Value))
+}
+
+// Annotations lists the [Annotation] s collected when validating some JSON data, in the order of evaluation.
+//
+// Only the schemas that the data is valid against contribute annotations.
+type Annotations struct {
+	annotations []Annotation
+}
+
+// Len is the number of annotations.
+func (a Annotations) Len() int {
+	return len(a.annotations)
+}
+
+// All yields all annotations.
+func (a Annotations) All() iter.Seq[Annotation] {
+	return slices.Values(a.annotations)
+}
+
+// Locations yields the JSON pointers to the annotated values, in the order they have been annotated.
+func (a Annotations) Locations() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		seen := make(map[string]struct{})
+		for _, annotation := range a.annotations {
+			if _, ok := seen[annotation.InstanceLocation]; ok {
+				continue
+			}
+			seen[annotation.InstanceLocation] = struct{}{}
+
+			if !yield(annotation.InstanceLocation) {
+				return
+			}
+		}
+	}
+}
+
+// At yields the annotations of the value at some JSON pointer in the data, from all schema locations.
+func (a Annotations) At(instanceLocation string) iter.Seq[Annotation] {
+	return func(yield func(Annotation) bool) {
+		for _, annotation := range a.annotations {
+			if annotation.InstanceLocation == instanceLocation && !yield(annotation) {
+				return
+			}
+		}
+	}
+}
+
+// Keyword yields the annotations of the value at some JSON pointer in the data, by a given keyword.
+func (a Annotations) Keyword(instanceLocation, keyword string) iter.Seq[Annotation] {
+	return func(yield func(Annotation) bool) {
+		for annotation := range a.At(instanceLocation) {
+			if annotation.Keyword == keyword && !yield(annotation) {
+				return
+			}
+		}
+	}
+}
+
+// IsDeprecated tells if the value at some JSON pointer in the data is deprecated by some schema.
+func (a Annotations) IsDeprecated(instanceLocation string) bool {
+	for annotation := range a.Keyword(instanceLocation, "deprecated") {
+		if v, ok := annotation.Value.Value(); ok && annotation.Value.IsBool() && v.Bool() {
+			return true
+		}
+	}
+
+	return false
+}
+
+//nolint:gochecknoglobals // standard annotation keywords, with the version that introduces them
+var annotationKeywords = []struct {
+	name  string
+	since jsonschema.Version
+}{
+	{name: "title"},
+	{name: "description"},
+	{name: "default"},
+	{name: "examples", since: jsonschema.VersionDraft6},
+	{name: "readOnly", since: jsonschema.VersionDraft7},
+	{name: "writeOnly", since: jsonschema.VersionDraft7},
+	{name: "contentMediaType", since: jsonschema.VersionDraft7},
+	{name: "contentEncoding", since: jsonschema.VersionDraft7},
+	{name: "contentSchema", since: jsonschema.VersionDraft2019},
+	{name: "deprecated", since: jsonschema.VersionDraft2019},
+	{name: "format"},
+}
+
+// annotate collects the annotations of a schema: standard annotation keywords and unknown keywords.
+//
+// Annotations never fail a validation.
+func (e *evaluation) annotate(f frame, _ json.Document, at string) bool {
+	if !e.collect {
+		return true
+	}
+
+	record := func(keyword string, value json.Document) {
+		path, absolute := f.location(keyword)
+
+		e.annotations = append(e.annotations, Annotation{
+			InstanceLocation:        at,
+			KeywordLocation:         path,
+			AbsoluteKeywordLocation: absolute,
+			Keyword:                 keyword,
+			Value:                   value,
+		})
+	}
+
+	for _, keyword := range annotationKeywords {
+		if value, ok := f.schema.AtKey(keyword.name); ok && f.since(keyword.since) {
+			record(keyword.name, value)
+		}
+	}
+
+	for name, value := range f.schema.Pairs() {
+		if _, standard := jsonschema.StandardKeyword(name); !standard {
+			record(name, value)
+		}
+	}
+
+	return true
+}
+
+// evaluatedMark records the properties or items of the data at some instance location that have been evaluated
+// by a schema, as needed by "unevaluatedProperties" and "unevaluatedItems".
+type evaluatedMark struct {
+	at      string
+	names   []string // evaluated properties
+	items   int      // number of evaluated leading items
+	indices []int    // evaluated items, as matched by "contains"
+	all     bool     // all properties or items are evaluated
+}
+
+// markProperties records evaluated properties.
+func (e *evaluation) markProperties(at string, names []string, all bool) {
+	e.evaluated = append(e.evaluated, evaluatedMark{at: at, names: names, all: all})
+}
+
+// markItems records evaluated items.
+func (e *evaluation) markItems(at string, items int, indices []int, all bool) {
+	e.evaluated = append(e.evaluated, evaluatedMark{at: at, items: items, indices: indices, all: all})
+}
+
+// marks yields the evaluated properties or items at some instance location, recorded by a schema and by all
+// the schemas applied in place.
+func (e *evaluation) marks(f frame, at string) iter.Seq[evaluatedMark] {
+	return func(yield func(evaluatedMark) bool) {
+		for _, mark := range e.evaluated[f.marks:] {
+			if mark.at == at && !yield(mark) {
+				return
+			}
+		}
+	}
+}
+
+// applyUnevaluated evaluates "unevaluatedProperties" and "unevaluatedItems".
+//
+// Both keywords apply to the properties or items that have not been evaluated by the schema nor by the
+// schemas it applies in place, i.e. "$ref", "allOf", "anyOf", "oneOf", "if", "then", "else" and "dependentSchemas".
+func (e *evaluation) applyUnevaluated(f frame, data json.Document, at string) bool {
+	if !f.since(jsonschema.VersionDraft2019) {
+		return true
+	}
+
+	switch {
+	case data.IsObject():
+		return e.applyUnevaluatedProperties(f, data, at)
+	case data.IsArray():
+		return e.applyUnevaluatedItems(f, data, at)
+	default:
+		return true
+	}
+}
+
+func (e *evaluation) applyUnevaluatedProperties(f frame, data json.Document, at string) bool {
+	schema, ok := f.schema.AtKey("unevaluatedProperties")
+	if !ok {
+		return true
+	}
+
+	evaluated := make(map[string]struct{})
+	for mark := range e.marks(f, at) {
+		if mark.all {
+			return true
+		}
+
+		for _, name := range mark.names {
+			evaluated[name] = struct{}{}
+		}
+	}
+
+	child := e.child(f, schema, "unevaluatedProperties")
+	valid := true
+	for name, value := range data.Pairs() {
+		if _, ok := evaluated[name]; ok {
+			continue
+		}
+
+		valid = e.evaluate(child, value, appendPointer(at, name)) && valid
+	}
+
+	if valid {
+		e.markProperties(at, nil, true)
+	}
+
+	return valid
+}
+
+func (e *evaluation) applyUnevaluatedItems(f frame, data json.Document, at string) bool {
+	schema, ok := f.schema.AtKey("unevaluatedItems")
+	if !ok {
+		return true
+	}
+
+	items := 0
+	evaluated := make(map[int]struct{})
+	for mark := range e.marks(f, at) {
+		if mark.all {
+			return true
+		}
+
+		items = max(items, mark.items)
+		for _, index := range mark.indices {
+			evaluated[index] = struct{}{}
+		}
+	}
+
+	child := e.child(f, schema, "unevaluatedItems")
+	valid := true
+	for i, elem := range data.IndexedElems() {
+		if _, ok := evaluated[i]; ok || i < items {
+			continue
+		}
+
+		valid = e.evaluate(child, elem, appendPointer(at, strconv.Itoa(i))) && valid
+	}
+
+	if valid {
+		e.markItems(at, 0, nil, true)
+	}
+
+	return valid
+}
@@ -1,2 +1,37 @@
 // Package validator exposes a convenient API to validate JSON data against a schema.
+//
+// A [Validator] is built for a [github.com/fredbi/core/jsonschema.Schema] and validates JSON data given as a
+// [github.com/fredbi/core/json.Document] or as bytes:
+//
+//	v, err := validator.New(schema)
+//	if err != nil {
+//		return err
+//	}
+//
+//	if err := v.ValidateBytes(data); err != nil {
+//		var violations validator.Violations
+//		if errors.As(err, &violations) {
+//			for _, violation := range violations {
+//				fmt.Println(violation)
+//			}
+//		}
+//	}
+//
+// Every [Violation] is located in the data and in the schema, following the "basic" output format of JSON schema.
+//
+// [Validator.Annotate] also collects the [Annotations] of valid data, i.e. the values of annotation keywords such as
+// "title", "default", "deprecated" or unknown keywords, for each location in the data and each schema location
+// that contributes to it:
+//
+//	annotations, err := v.Annotate(data)
+//	if err != nil {
+//		return err
+//	}
+//
+//	for annotation := range annotations.At("/name") {
+//		fmt.Println(annotation.KeywordLocation, annotation.Value)
+//	}
+//
+// Annotations are also what "unevaluatedProperties" and "unevaluatedItems" rely on: these keywords apply to the
+// properties and items not evaluated by the schemas that the data is valid against, at the same location.
 package validator
@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// resolveDataRefs substitutes the "$data" references of a schema with the values they refer to in the data,
+// for schemas that enable "$data" (see [jsonschema.WithDollarData]).
+//
+// As specified by ajv, a keyword which reference doesn't resolve is ignored, and a keyword which reference
+// resolves to a value that the keyword doesn't accept is violated.
+func (e *evaluation) resolveDataRefs(f frame, at string) (frame, bool) {
+	if !e.dollarData {
+		return f, true
+	}
+
+	var (
+		b     *json.Builder
+		valid = true
+	)
+
+	for keyword, value := range f.schema.Pairs() {
+		if !jsonschema.IsDataRef(keyword, value) {
+			continue
+		}
+
+		if b == nil {
+			b = json.NewBuilder(f.schema.Store()).From(f.schema)
+		}
+
+		p, err := json.MakePointer("/" + json.EscapeToken(keyword))
+		if err != nil {
+			e.err = fmt.Errorf("at %q: %w: %w", f.uri+"#"+f.pointer, err, ErrValidator)
+
+			return f, false
+		}
+
+		resolved, err := e.resolveDataRef(keyword, value, at)
+		switch {
+		case errors.Is(err, json.ErrPointerNotFound):
+			b.AtPointerRemove(p)
+		case err != nil:
+			e.err = fmt.Errorf("at %q: %w: %w", f.uri+"#"+f.pointer, err, ErrValidator)
+
+			return f, false
+		case !acceptsDataValue(e.Validator, keyword, resolved):
+			e.violate(f, at, keyword, "the $data reference %s resolves to %s, which is not a valid %q",
+				keywords.Text(value), keywords.Text(resolved), keyword)
+			b.AtPointerRemove(p)
+			valid = false
+		default:
+			b.AtPointer(p, resolved)
+		}
+	}
+
+	if b == nil {
+		return f, true
+	}
+
+	if err := b.Err(); err != nil {
+		e.err = fmt.Errorf("at %q: %w: %w", f.uri+"#"+f.pointer, err, ErrValidator)
+
+		return f, false
+	}
+	f.schema = b.Document()
+
+	return f, valid
+}
+
+// resolveDataRef resolves a "$data" reference against the validated data, from the instance location.
+func (e *evaluation) resolveDataRef(keyword string, value json.Document, at string) (json.Document, error) {
+	ref, err := jsonschema.MakeDataRef(keyword, value)
+	if err != nil {
+		return json.EmptyDocument, err
+	}
+
+	location, err := json.MakePointer(at)
+	if err != nil {
+		return json.EmptyDocument, err
+	}
+
+	return ref.Resolve(e.data, location)
+}
+
+// acceptsDataValue tells if a value resolved by a "$data" reference is a valid value for a keyword.
+func acceptsDataValue(v *Validator, keyword string, value json.Document) bool {
+	switch keyword {
+	case "maximum", "minimum":
+		return value.IsNumber()
+	case "exclusiveMaximum", "exclusiveMinimum":
+		return value.IsNumber() || value.IsBool()
+	case "multipleOf":
+		r, ok := keywords.Rat(value)
+
+		return ok && r.Sign() > 0
+	case "maxLength", "minLength", "maxItems", "minItems", "maxProperties", "minProperties":
+		r, ok := keywords.Rat(value)
+
+		return ok && r.IsInt() && r.Sign() >= 0
+	case "pattern":
+		if !value.IsString() {
+			return false
+		}
+		_, err := v.compile(value.String())
+
+		return err == nil
+	case "format":
+		return value.IsString()
+	case "enum", "required":
+		return value.IsArray()
+	case "uniqueItems":
+		return value.IsBool()
+	default:
+		return true
+	}
+}
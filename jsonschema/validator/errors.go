@@ -0,0 +1,16 @@
+package validator
+
+// Error is an error raised by the [Validator].
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+const (
+	// ErrValidator is raised when a [Validator] cannot be built or cannot evaluate a schema.
+	ErrValidator Error = "validator error"
+
+	// ErrInvalid is raised when some JSON data is invalid against a schema.
+	ErrInvalid Error = "data is invalid against the schema"
+)
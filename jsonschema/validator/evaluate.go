@@ -0,0 +1,291 @@
+package validator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// frame locates an evaluated schema.
+type frame struct {
+	schema  json.Document
+	uri     string // URI of the document holding the schema
+	pointer string // JSON pointer to the schema in its document
+	base    string // base URI to resolve references
+	path    string // keyword location, following the references evaluated from the root schema
+	version jsonschema.Version
+	scope   jsonschema.DynamicScope
+	marks   int // index of the first evaluated mark recorded by the schema
+}
+
+// frameOf locates a resolved schema, reached by the keyword location path.
+func (v *Validator) frameOf(resolved jsonschema.ResolvedSchema, path string, scope jsonschema.DynamicScope, version jsonschema.Version) frame {
+	if declared := resolved.Version(); declared != jsonschema.VersionUndefined {
+		version = declared
+	}
+
+	return frame{
+		schema:  resolved.Document,
+		uri:     resolved.DocumentURI(),
+		pointer: resolved.Pointer(),
+		base:    resolved.BaseURI(),
+		path:    path,
+		version: version,
+		scope:   scope,
+	}
+}
+
+// since tells if the schema follows the semantics of some version of JSON schema, or a later one.
+func (f frame) since(version jsonschema.Version) bool {
+	return keywords.Since(f.version, version)
+}
+
+// location of a keyword of the schema, followed by some tokens.
+func (f frame) location(keyword string, tokens ...string) (path, absolute string) {
+	var suffix strings.Builder
+	suffix.WriteString("/")
+	suffix.WriteString(json.EscapeToken(keyword))
+
+	for _, token := range tokens {
+		suffix.WriteString("/")
+		suffix.WriteString(json.EscapeToken(token))
+	}
+
+	return f.path + suffix.String(), f.uri + "#" + f.pointer + suffix.String()
+}
+
+// evaluation holds the state of the validation of some JSON data.
+type evaluation struct {
+	*Validator
+
+	data        json.Document // the validated data, to resolve "$data" references
+	violations  Violations
+	annotations []Annotation
+	evaluated   []evaluatedMark
+	collect     bool // collects annotations
+	depth       int
+	err         error
+}
+
+// evaluate JSON data at the instance location, against the schema of a frame.
+//
+// It tells if the data is valid, and records violations.
+func (e *evaluation) evaluate(f frame, data json.Document, at string) bool {
+	if e.err != nil {
+		return false
+	}
+
+	if f.schema.IsBool() {
+		value, _ := f.schema.Value()
+		if !value.Bool() {
+			e.violations = append(e.violations, Violation{
+				InstanceLocation:        at,
+				KeywordLocation:         f.path,
+				AbsoluteKeywordLocation: f.uri + "#" + f.pointer,
+				Message:                 "the false schema rejects any value",
+			})
+		}
+
+		return value.Bool()
+	}
+
+	if !f.schema.IsObject() {
+		return true
+	}
+
+	e.depth++
+	defer func() { e.depth-- }()
+	if e.depth > e.maxDepth {
+		e.err = fmt.Errorf("the evaluation of %q exceeds the maximum depth of %d: %w", f.uri+"#"+f.pointer, e.maxDepth, ErrValidator)
+
+		return false
+	}
+
+	f.scope = f.scope.Enter(f.base)
+	f.marks = len(e.evaluated)
+	annotations := len(e.annotations)
+
+	f, valid := e.resolveDataRefs(f, at)
+	valid = e.evaluateKeywords(f, data, at) && valid
+	if !valid {
+		// a schema that fails contributes no annotation
+		e.annotations = e.annotations[:annotations]
+		e.evaluated = e.evaluated[:f.marks]
+	}
+
+	return valid
+}
+
+// evaluateKeywords evaluates JSON data against all keywords of a schema.
+func (e *evaluation) evaluateKeywords(f frame, data json.Document, at string) bool {
+	valid := e.references(f, data, at)
+
+	if _, hasRef := f.schema.AtKey("$ref"); hasRef && !f.since(jsonschema.VersionDraft2019) {
+		// keywords next to a "$ref" are ignored
+		return valid
+	}
+
+	for _, check := range []func(frame, json.Document, string) bool{
+		e.assertType,
+		e.assertValues,
+		e.assertNumber,
+		e.assertString,
+		e.assertArray,
+		e.assertObject,
+		e.applyCombinators,
+		e.applyConditional,
+		e.assertCustom,
+		e.annotate,
+		e.applyUnevaluated,
+	} {
+		valid = check(f, data, at) && valid
+		if e.err != nil {
+			return false
+		}
+	}
+
+	return valid
+}
+
+// child frame for the subschema of a keyword, followed by some tokens.
+//
+// A subschema that declares an identifier gets a new base URI.
+func (e *evaluation) child(f frame, schema json.Document, keyword string, tokens ...string) frame {
+	path, _ := f.location(keyword, tokens...)
+	pointer := f.pointer + path[len(f.path):]
+
+	c := frame{
+		schema:  schema,
+		uri:     f.uri,
+		pointer: pointer,
+		base:    f.base,
+		path:    path,
+		version: f.version,
+		scope:   f.scope,
+	}
+
+	if !schema.IsObject() {
+		return c
+	}
+
+	_, hasID := schema.AtKey("$id")
+	if _, hasDraft4ID := schema.AtKey("id"); !hasID && !hasDraft4ID {
+		return c
+	}
+
+	if resolved, err := e.resolver.Resolve(keywords.FragmentRef(pointer), f.uri); err == nil {
+		c.base = resolved.BaseURI()
+	}
+
+	return c
+}
+
+// references evaluates "$ref", "$dynamicRef" and "$recursiveRef".
+func (e *evaluation) references(f frame, data json.Document, at string) bool {
+	valid := true
+
+	if ref, ok := keywords.StringAt(f.schema, "$ref"); ok {
+		resolved, err := e.resolve(ref, f.base)
+		if err != nil {
+			e.err = fmt.Errorf("cannot resolve %q at %q: %w: %w", ref, f.uri+"#"+f.pointer, err, ErrValidator)
+
+			return false
+		}
+
+		path, _ := f.location("$ref")
+		valid = e.evaluate(e.frameOf(resolved, path, f.scope, f.version), data, at)
+	}
+
+	if ref, ok := keywords.StringAt(f.schema, "$dynamicRef"); ok && f.since(jsonschema.VersionDraft2020) {
+		resolved, err := e.resolver.ResolveDynamic(ref, f.base, f.scope)
+		if err != nil {
+			e.err = fmt.Errorf("cannot resolve %q at %q: %w: %w", ref, f.uri+"#"+f.pointer, err, ErrValidator)
+
+			return false
+		}
+
+		path, _ := f.location("$dynamicRef")
+		valid = e.evaluate(e.frameOf(resolved, path, f.scope, f.version), data, at) && valid
+	}
+
+	if ref, ok := keywords.StringAt(f.schema, "$recursiveRef"); ok && f.version == jsonschema.VersionDraft2019 {
+		resolved, err := e.resolveRecursive(ref, f)
+		if err != nil {
+			e.err = fmt.Errorf("cannot resolve %q at %q: %w: %w", ref, f.uri+"#"+f.pointer, err, ErrValidator)
+
+			return false
+		}
+
+		path, _ := f.location("$recursiveRef")
+		valid = e.evaluate(e.frameOf(resolved, path, f.scope, f.version), data, at) && valid
+	}
+
+	return valid
+}
+
+// resolve a "$ref" against a base URI.
+func (e *evaluation) resolve(ref, base string) (jsonschema.ResolvedSchema, error) {
+	key := base + " " + ref
+	if resolved, ok := e.refs[key]; ok {
+		return resolved, nil
+	}
+
+	resolved, err := e.resolver.Resolve(ref, base)
+	if err != nil {
+		return resolved, err
+	}
+	e.refs[key] = resolved
+
+	return resolved, nil
+}
+
+// resolveRecursive resolves a draft 2019 "$recursiveRef".
+//
+// When the referred schema sets "$recursiveAnchor", the outermost schema resource of the dynamic scope that sets
+// "$recursiveAnchor" is used instead.
+func (e *evaluation) resolveRecursive(ref string, f frame) (jsonschema.ResolvedSchema, error) {
+	resolved, err := e.resolve(ref, f.base)
+	if err != nil || !keywords.IsTrue(resolved.Document, "$recursiveAnchor") {
+		return resolved, err
+	}
+
+	for outer := range f.scope.Bases() {
+		candidate, err := e.resolve("#", outer)
+		if err == nil && keywords.IsTrue(candidate.Document, "$recursiveAnchor") {
+			return candidate, nil
+		}
+	}
+
+	return resolved, nil
+}
+
+// violate records a violation of a keyword.
+func (e *evaluation) violate(f frame, at, keyword, format string, args ...any) {
+	path, absolute := f.location(keyword)
+
+	e.violations = append(e.violations, Violation{
+		InstanceLocation:        at,
+		KeywordLocation:         path,
+		AbsoluteKeywordLocation: absolute,
+		Keyword:                 keyword,
+		Message:                 fmt.Sprintf(format, args...),
+	})
+}
+
+// try evaluates a subschema, and retracts the violations it has recorded.
+func (e *evaluation) try(f frame, data json.Document, at string) (bool, Violations) {
+	start := len(e.violations)
+	valid := e.evaluate(f, data, at)
+	retracted := slices.Clone(e.violations[start:])
+	e.violations = e.violations[:start]
+
+	return valid, retracted
+}
+
+func appendPointer(pointer string, token string) string {
+	return pointer + "/" + json.EscapeToken(token)
+}
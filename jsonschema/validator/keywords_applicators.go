@@ -0,0 +1,122 @@
+package validator
+
+import (
+	"strconv"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+)
+
+// applyCombinators evaluates "allOf", "anyOf", "oneOf" and "not".
+//
+// The violations of the members of an "anyOf" or a "oneOf" are reported only when no member is valid.
+// All members of an "anyOf" are evaluated, since every valid member contributes annotations.
+func (e *evaluation) applyCombinators(f frame, data json.Document, at string) bool {
+	valid := true
+
+	if allOf, ok := f.schema.AtKey("allOf"); ok && allOf.IsArray() {
+		for i, schema := range allOf.IndexedElems() {
+			valid = e.evaluate(e.child(f, schema, "allOf", strconv.Itoa(i)), data, at) && valid
+		}
+	}
+
+	if anyOf, ok := f.schema.AtKey("anyOf"); ok && anyOf.IsArray() {
+		var retracted Violations
+		matched := false
+
+		for i, schema := range anyOf.IndexedElems() {
+			ok, violations := e.try(e.child(f, schema, "anyOf", strconv.Itoa(i)), data, at)
+			if ok {
+				matched = true
+
+				continue
+			}
+			retracted = append(retracted, violations...)
+		}
+
+		if !matched {
+			e.violate(f, at, "anyOf", "the value is not valid against any member of anyOf")
+			e.violations = append(e.violations, retracted...)
+			valid = false
+		}
+	}
+
+	if oneOf, ok := f.schema.AtKey("oneOf"); ok && oneOf.IsArray() {
+		var (
+			retracted Violations
+			matches   []int
+		)
+
+		for i, schema := range oneOf.IndexedElems() {
+			ok, violations := e.try(e.child(f, schema, "oneOf", strconv.Itoa(i)), data, at)
+			if ok {
+				matches = append(matches, i)
+			}
+			retracted = append(retracted, violations...)
+		}
+
+		switch len(matches) {
+		case 1:
+		case 0:
+			e.violate(f, at, "oneOf", "the value is not valid against any member of oneOf")
+			e.violations = append(e.violations, retracted...)
+			valid = false
+		default:
+			e.violate(f, at, "oneOf", "the value is valid against several members of oneOf: %v", matches)
+			valid = false
+		}
+	}
+
+	if not, ok := f.schema.AtKey("not"); ok {
+		if ok, _ := e.try(e.child(f, not, "not"), data, at); ok {
+			e.violate(f, at, "not", "the value is valid against the schema of not")
+			valid = false
+		}
+	}
+
+	return valid
+}
+
+// applyConditional evaluates "if", "then" and "else".
+func (e *evaluation) applyConditional(f frame, data json.Document, at string) bool {
+	condition, ok := f.schema.AtKey("if")
+	if !ok || !f.since(jsonschema.VersionDraft7) {
+		return true
+	}
+
+	matched, _ := e.try(e.child(f, condition, "if"), data, at)
+
+	branch := "else"
+	if matched {
+		branch = "then"
+	}
+
+	schema, ok := f.schema.AtKey(branch)
+	if !ok {
+		return true
+	}
+
+	return e.evaluate(e.child(f, schema, branch), data, at)
+}
+
+// assertCustom evaluates the custom assertion keywords declared by the vocabularies of the validator.
+func (e *evaluation) assertCustom(f frame, data json.Document, at string) bool {
+	if e.vocabularies == nil {
+		return true
+	}
+
+	valid := true
+	for name, value := range f.schema.Pairs() {
+		keyword, _, ok := e.vocabularies.Keyword(name)
+		if !ok || !keyword.IsAssertion() || keyword.Validate == nil {
+			continue
+		}
+
+		if err := keyword.Validate(value, data); err != nil {
+			e.violate(f, at, name, "%v", err)
+			valid = false
+		}
+	}
+
+	return valid
+}
@@ -0,0 +1,187 @@
+package validator
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// assertType evaluates "type".
+func (e *evaluation) assertType(f frame, data json.Document, at string) bool {
+	types, ok := f.schema.AtKey("type")
+	if !ok {
+		return true
+	}
+
+	var names []string
+	switch {
+	case types.IsString():
+		names = []string{types.String()}
+	case types.IsArray():
+		for elem := range types.Elems() {
+			if elem.IsString() {
+				names = append(names, elem.String())
+			}
+		}
+	default:
+		return true
+	}
+
+	for _, name := range names {
+		if hasType(data, name) {
+			return true
+		}
+	}
+
+	e.violate(f, at, "type", "expected %s, but got %s", strings.Join(names, " or "), typeOf(data))
+
+	return false
+}
+
+// assertValues evaluates "enum" and "const".
+func (e *evaluation) assertValues(f frame, data json.Document, at string) bool {
+	valid := true
+
+	if enum, ok := f.schema.AtKey("enum"); ok && enum.IsArray() {
+		found := false
+		for value := range enum.Elems() {
+			if keywords.Equal(data, value) {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			e.violate(f, at, "enum", "the value is not one of the values of the enum")
+			valid = false
+		}
+	}
+
+	if value, ok := f.schema.AtKey("const"); ok && f.since(jsonschema.VersionDraft6) && !keywords.Equal(data, value) {
+		e.violate(f, at, "const", "the value is not equal to the constant %s", keywords.Text(value))
+		valid = false
+	}
+
+	return valid
+}
+
+// assertNumber evaluates the keywords that apply to numbers.
+func (e *evaluation) assertNumber(f frame, data json.Document, at string) bool {
+	if !data.IsNumber() {
+		return true
+	}
+
+	number, ok := keywords.Rat(data)
+	if !ok {
+		return true
+	}
+
+	valid := true
+
+	if divisor, ok := keywords.RatAt(f.schema, "multipleOf"); ok && divisor.Sign() > 0 {
+		if !new(big.Rat).Quo(number, divisor).IsInt() {
+			e.violate(f, at, "multipleOf", "%s is not a multiple of %s", number.RatString(), divisor.RatString())
+			valid = false
+		}
+	}
+
+	check := func(keyword string, exclusive bool, sign int) {
+		bound, ok := keywords.RatAt(f.schema, keyword)
+		if !ok {
+			return
+		}
+
+		cmp := number.Cmp(bound) * sign
+		if cmp > 0 || (cmp == 0 && !exclusive) {
+			return
+		}
+
+		relation := map[int]string{1: "greater than", -1: "less than"}[sign]
+		if !exclusive {
+			relation += " or equal to"
+		}
+		e.violate(f, at, keyword, "%s is not %s %s", number.RatString(), relation, bound.RatString())
+		valid = false
+	}
+
+	if f.since(jsonschema.VersionDraft6) {
+		check("minimum", false, 1)
+		check("maximum", false, -1)
+		check("exclusiveMinimum", true, 1)
+		check("exclusiveMaximum", true, -1)
+	} else {
+		// draft 4: "exclusiveMinimum" and "exclusiveMaximum" are booleans that qualify "minimum" and "maximum"
+		check("minimum", keywords.IsTrue(f.schema, "exclusiveMinimum"), 1)
+		check("maximum", keywords.IsTrue(f.schema, "exclusiveMaximum"), -1)
+	}
+
+	return valid
+}
+
+// assertString evaluates the keywords that apply to strings.
+func (e *evaluation) assertString(f frame, data json.Document, at string) bool {
+	if !data.IsString() {
+		return true
+	}
+
+	value := data.String()
+	length := int64(utf8.RuneCountInString(value))
+	valid := true
+
+	if minLength, ok := keywords.IntAt(f.schema, "minLength"); ok && length < minLength {
+		e.violate(f, at, "minLength", "the length %d is less than %d", length, minLength)
+		valid = false
+	}
+
+	if maxLength, ok := keywords.IntAt(f.schema, "maxLength"); ok && length > maxLength {
+		e.violate(f, at, "maxLength", "the length %d is greater than %d", length, maxLength)
+		valid = false
+	}
+
+	if pattern, ok := keywords.StringAt(f.schema, "pattern"); ok {
+		re, err := e.compile(pattern)
+		if err != nil {
+			e.err = fmt.Errorf(`invalid "pattern" at %q: %w: %w`, f.uri+"#"+f.pointer, err, ErrValidator)
+
+			return false
+		}
+
+		if !re.MatchString(value) {
+			e.violate(f, at, "pattern", "the value does not match %q", pattern)
+			valid = false
+		}
+	}
+
+	if format, ok := keywords.StringAt(f.schema, "format"); ok && e.formats != nil {
+		if _, supported := e.formats[format]; supported {
+			if err := e.options.formats.Validate(format, value); err != nil {
+				e.violate(f, at, "format", "the value is not a valid %q: %v", format, err)
+				valid = false
+			}
+		}
+	}
+
+	return valid
+}
+
+// compile a regular expression, with a cache.
+func (v *Validator) compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns[pattern]; ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	v.patterns[pattern] = re
+
+	return re, nil
+}
@@ -0,0 +1,308 @@
+package validator
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+// assertArray evaluates the keywords that apply to arrays.
+func (e *evaluation) assertArray(f frame, data json.Document, at string) bool {
+	if !data.IsArray() {
+		return true
+	}
+
+	elems := slices.Collect(data.Elems())
+	size := int64(len(elems))
+	valid := true
+
+	if minItems, ok := keywords.IntAt(f.schema, "minItems"); ok && size < minItems {
+		e.violate(f, at, "minItems", "the array has %d items, less than %d", size, minItems)
+		valid = false
+	}
+
+	if maxItems, ok := keywords.IntAt(f.schema, "maxItems"); ok && size > maxItems {
+		e.violate(f, at, "maxItems", "the array has %d items, more than %d", size, maxItems)
+		valid = false
+	}
+
+	if keywords.IsTrue(f.schema, "uniqueItems") {
+	unique:
+		for i := range elems {
+			for j := i + 1; j < len(elems); j++ {
+				if keywords.Equal(elems[i], elems[j]) {
+					e.violate(f, at, "uniqueItems", "the items at index %d and %d are equal", i, j)
+					valid = false
+
+					break unique
+				}
+			}
+		}
+	}
+
+	valid = e.applyItems(f, elems, at) && valid
+
+	return e.applyContains(f, elems, at) && valid
+}
+
+// applyItems evaluates "prefixItems", "items" and "additionalItems".
+func (e *evaluation) applyItems(f frame, elems []json.Document, at string) bool {
+	valid := true
+	evaluated := 0
+
+	apply := func(schema json.Document, keyword string, index int, tokens ...string) {
+		valid = e.evaluate(e.child(f, schema, keyword, tokens...), elems[index], appendPointer(at, strconv.Itoa(index))) && valid
+	}
+
+	if prefix, ok := f.schema.AtKey("prefixItems"); ok && prefix.IsArray() && f.since(jsonschema.VersionDraft2020) {
+		for i, schema := range prefix.IndexedElems() {
+			if i >= len(elems) {
+				break
+			}
+			apply(schema, "prefixItems", i, strconv.Itoa(i))
+			evaluated = i + 1
+		}
+	}
+
+	items, hasItems := f.schema.AtKey("items")
+	switch {
+	case !hasItems:
+	case items.IsArray() && !f.since(jsonschema.VersionDraft2020):
+		for i, schema := range items.IndexedElems() {
+			if i >= len(elems) {
+				break
+			}
+			apply(schema, "items", i, strconv.Itoa(i))
+			evaluated = i + 1
+		}
+
+		if additional, ok := f.schema.AtKey("additionalItems"); ok {
+			for i := evaluated; i < len(elems); i++ {
+				apply(additional, "additionalItems", i)
+			}
+		}
+		if _, ok := f.schema.AtKey("additionalItems"); ok {
+			evaluated = len(elems)
+		}
+	case !items.IsArray():
+		for i := evaluated; i < len(elems); i++ {
+			apply(items, "items", i)
+		}
+		evaluated = len(elems)
+	}
+
+	if evaluated > 0 {
+		e.markItems(at, evaluated, nil, false)
+	}
+
+	return valid
+}
+
+// applyContains evaluates "contains", "minContains" and "maxContains".
+func (e *evaluation) applyContains(f frame, elems []json.Document, at string) bool {
+	contains, ok := f.schema.AtKey("contains")
+	if !ok || !f.since(jsonschema.VersionDraft6) {
+		return true
+	}
+
+	minContains, maxContains, hasMax := int64(1), int64(0), false
+	if f.since(jsonschema.VersionDraft2019) {
+		if value, ok := keywords.IntAt(f.schema, "minContains"); ok {
+			minContains = value
+		}
+		maxContains, hasMax = keywords.IntAt(f.schema, "maxContains")
+	}
+
+	var (
+		matches int64
+		indices []int
+	)
+	child := e.child(f, contains, "contains")
+	for i, elem := range elems {
+		if valid, _ := e.try(child, elem, appendPointer(at, strconv.Itoa(i))); valid {
+			matches++
+			indices = append(indices, i)
+		}
+	}
+
+	if f.since(jsonschema.VersionDraft2020) {
+		// since draft 2020, items matched by "contains" are evaluated
+		e.markItems(at, 0, indices, false)
+	}
+
+	switch {
+	case matches < minContains && minContains == 1:
+		e.violate(f, at, "contains", "no item of the array is valid against the schema of contains")
+	case matches < minContains:
+		e.violate(f, at, "minContains", "%d items are valid against the schema of contains, less than %d", matches, minContains)
+	case hasMax && matches > maxContains:
+		e.violate(f, at, "maxContains", "%d items are valid against the schema of contains, more than %d", matches, maxContains)
+	default:
+		return true
+	}
+
+	return false
+}
+
+// assertObject evaluates the keywords that apply to objects.
+func (e *evaluation) assertObject(f frame, data json.Document, at string) bool {
+	if !data.IsObject() {
+		return true
+	}
+
+	size := int64(data.Len())
+	valid := true
+
+	if minProperties, ok := keywords.IntAt(f.schema, "minProperties"); ok && size < minProperties {
+		e.violate(f, at, "minProperties", "the object has %d properties, less than %d", size, minProperties)
+		valid = false
+	}
+
+	if maxProperties, ok := keywords.IntAt(f.schema, "maxProperties"); ok && size > maxProperties {
+		e.violate(f, at, "maxProperties", "the object has %d properties, more than %d", size, maxProperties)
+		valid = false
+	}
+
+	if required, ok := f.schema.AtKey("required"); ok && required.IsArray() {
+		for name := range required.Elems() {
+			if !name.IsString() {
+				continue
+			}
+
+			if _, found := data.AtKey(name.String()); !found {
+				e.violate(f, at, "required", "the required property %q is missing", name.String())
+				valid = false
+			}
+		}
+	}
+
+	valid = e.applyProperties(f, data, at) && valid
+	valid = e.applyPropertyNames(f, data, at) && valid
+
+	return e.applyDependencies(f, data, at) && valid
+}
+
+// applyProperties evaluates "properties", "patternProperties" and "additionalProperties".
+func (e *evaluation) applyProperties(f frame, data json.Document, at string) bool {
+	properties, hasProperties := f.schema.AtKey("properties")
+	patterns, hasPatterns := f.schema.AtKey("patternProperties")
+	additional, hasAdditional := f.schema.AtKey("additionalProperties")
+	if !hasProperties && !hasPatterns && !hasAdditional {
+		return true
+	}
+
+	valid := true
+	evaluated := make([]string, 0, data.Len())
+	for name, value := range data.Pairs() {
+		location := appendPointer(at, name)
+		matched := false
+
+		if hasProperties && properties.IsObject() {
+			if schema, ok := properties.AtKey(name); ok {
+				matched = true
+				valid = e.evaluate(e.child(f, schema, "properties", name), value, location) && valid
+			}
+		}
+
+		if hasPatterns && patterns.IsObject() {
+			for pattern, schema := range patterns.Pairs() {
+				re, err := e.compile(pattern)
+				if err != nil {
+					continue
+				}
+
+				if re.MatchString(name) {
+					matched = true
+					valid = e.evaluate(e.child(f, schema, "patternProperties", pattern), value, location) && valid
+				}
+			}
+		}
+
+		if !matched && hasAdditional {
+			matched = true
+			valid = e.evaluate(e.child(f, additional, "additionalProperties"), value, location) && valid
+		}
+
+		if e.err != nil {
+			return false
+		}
+
+		if matched {
+			evaluated = append(evaluated, name)
+		}
+	}
+
+	e.markProperties(at, evaluated, false)
+
+	return valid
+}
+
+// applyPropertyNames evaluates "propertyNames".
+func (e *evaluation) applyPropertyNames(f frame, data json.Document, at string) bool {
+	schema, ok := f.schema.AtKey("propertyNames")
+	if !ok || !f.since(jsonschema.VersionDraft6) {
+		return true
+	}
+
+	child := e.child(f, schema, "propertyNames")
+	valid := true
+	for name := range data.Pairs() {
+		doc, err := stringDocument(name)
+		if err != nil {
+			continue
+		}
+
+		if !e.evaluate(child, doc, appendPointer(at, name)) {
+			valid = false
+		}
+	}
+
+	return valid
+}
+
+// applyDependencies evaluates "dependentRequired", "dependentSchemas" and their draft 4 to 7 counterpart,
+// "dependencies".
+func (e *evaluation) applyDependencies(f frame, data json.Document, at string) bool {
+	valid := true
+
+	check := func(keyword string, allowRequired, allowSchemas bool) {
+		dependencies, ok := f.schema.AtKey(keyword)
+		if !ok || !dependencies.IsObject() {
+			return
+		}
+
+		for name, dependency := range dependencies.Pairs() {
+			if _, present := data.AtKey(name); !present {
+				continue
+			}
+
+			switch {
+			case dependency.IsArray() && allowRequired:
+				for required := range dependency.Elems() {
+					if !required.IsString() {
+						continue
+					}
+
+					if _, found := data.AtKey(required.String()); !found {
+						e.violate(f, at, keyword, "the property %q is required by the property %q", required.String(), name)
+						valid = false
+					}
+				}
+			case !dependency.IsArray() && allowSchemas:
+				valid = e.evaluate(e.child(f, dependency, keyword, name), data, at) && valid
+			}
+		}
+	}
+
+	if f.since(jsonschema.VersionDraft2019) {
+		check("dependentRequired", true, false)
+		check("dependentSchemas", false, true)
+	} else {
+		check("dependencies", true, true)
+	}
+
+	return valid
+}
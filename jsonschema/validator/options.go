@@ -0,0 +1,96 @@
+package validator
+
+import (
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/strfmt/registries"
+)
+
+// Option customizes the behavior of the [Validator].
+type Option func(*options)
+
+type options struct {
+	resolver     *jsonschema.Resolver
+	baseURI      string
+	version      jsonschema.Version
+	formats      registries.Registry
+	vocabularies *jsonschema.VocabularyRegistry
+	maxDepth     int
+}
+
+// WithResolver equips the [Validator] with a [jsonschema.Resolver] to resolve "$ref" s.
+//
+// By default, a new [jsonschema.Resolver] is used.
+func WithResolver(resolver *jsonschema.Resolver) Option {
+	return func(o *options) {
+		o.resolver = resolver
+	}
+}
+
+// WithBaseURI sets the URI of the schema, against which relative "$ref" s are resolved.
+//
+// The default is "schema.json".
+func WithBaseURI(uri string) Option {
+	return func(o *options) {
+		o.baseURI = uri
+	}
+}
+
+// WithVersion sets the version of JSON schema for the schemas that don't declare a meta-schema.
+//
+// The default is [jsonschema.VersionDraft2020].
+func WithVersion(version jsonschema.Version) Option {
+	return func(o *options) {
+		o.version = version
+	}
+}
+
+// WithFormats asserts "format" with a registry of string formats.
+//
+// By default, "format" is an annotation and is not asserted. Formats that are not supported by the registry
+// are never asserted.
+func WithFormats(registry registries.Registry) Option {
+	return func(o *options) {
+		o.formats = registry
+	}
+}
+
+// WithVocabularies validates the custom assertion keywords declared by the vocabularies of a registry.
+//
+// By default, custom keywords are ignored.
+func WithVocabularies(registry *jsonschema.VocabularyRegistry) Option {
+	return func(o *options) {
+		o.vocabularies = registry
+	}
+}
+
+// WithMaxDepth limits the depth of nested schema evaluations, which guards against "$ref" s that loop without
+// consuming the instance.
+//
+// The default is 512.
+func WithMaxDepth(depth int) Option {
+	return func(o *options) {
+		if depth > 0 {
+			o.maxDepth = depth
+		}
+	}
+}
+
+func optionsWithDefaults(opts []Option) *options {
+	const defaultMaxDepth = 512
+
+	o := &options{
+		baseURI:  "schema.json",
+		version:  jsonschema.VersionDraft2020,
+		maxDepth: defaultMaxDepth,
+	}
+
+	for _, apply := range opts {
+		apply(o)
+	}
+
+	if o.resolver == nil {
+		o.resolver = jsonschema.NewResolver()
+	}
+
+	return o
+}
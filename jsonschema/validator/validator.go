@@ -0,0 +1,126 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+)
+
+// Validator validates JSON data against a [jsonschema.Schema].
+//
+// The schema is interpreted: keywords are evaluated as they are met, following "$ref", "$dynamicRef" and
+// "$recursiveRef" with a [jsonschema.Resolver]. All versions of JSON schema are supported, with the semantics
+// of the version declared by each schema document (see [WithVersion] for schemas that don't declare any).
+//
+// Schemas that enable "$data" references (see [jsonschema.WithDollarData]) have these references resolved against
+// the validated data.
+//
+// "format" is asserted only with a registry of formats (see [WithFormats]). Custom assertion keywords are
+// validated with the [jsonschema.KeywordValidator] declared by their vocabulary (see [WithVocabularies]).
+//
+// Annotations, e.g. "title", "deprecated" or unknown keywords, may be collected along with the validation
+// (see [Validator.Annotate]).
+//
+// A [Validator] is not safe for concurrent use.
+type Validator struct {
+	*options
+
+	root       frame
+	dollarData bool // resolves "$data" references
+	patterns   map[string]*regexp.Regexp
+	refs       map[string]jsonschema.ResolvedSchema
+	formats    map[string]struct{}
+}
+
+// New [Validator] for a [jsonschema.Schema].
+//
+// The schema is registered by the [jsonschema.Resolver] of the validator, at the URI set by [WithBaseURI].
+func New(sch jsonschema.Schema, opts ...Option) (*Validator, error) {
+	v := &Validator{
+		options:    optionsWithDefaults(opts),
+		dollarData: sch.UsesDollarData(),
+		patterns:   make(map[string]*regexp.Regexp),
+		refs:       make(map[string]jsonschema.ResolvedSchema),
+	}
+
+	if err := v.resolver.AddDocument(v.baseURI, sch); err != nil {
+		return nil, fmt.Errorf("%w: %w", err, ErrValidator)
+	}
+
+	root, err := v.resolver.Resolve("#", v.baseURI)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, ErrValidator)
+	}
+
+	v.root = v.frameOf(root, "", jsonschema.DynamicScope{}, v.version)
+
+	if v.options.formats != nil {
+		v.formats = make(map[string]struct{})
+		for _, format := range v.options.formats.SupportedFormats() {
+			v.formats[format] = struct{}{}
+		}
+	}
+
+	return v, nil
+}
+
+// Validate JSON data.
+//
+// If the data is invalid, the returned error is a [Violations] that lists all violations.
+// Other errors, e.g. when a "$ref" cannot be resolved, wrap [ErrValidator].
+func (v *Validator) Validate(data json.Document) error {
+	e := &evaluation{Validator: v, data: data}
+	e.evaluate(v.root, data, "")
+
+	if e.err != nil {
+		return e.err
+	}
+
+	if len(e.violations) > 0 {
+		return e.violations
+	}
+
+	return nil
+}
+
+// ValidateBytes validates JSON data from its bytes.
+//
+// Bytes which are not valid JSON are reported with an error wrapping [ErrInvalid].
+func (v *Validator) ValidateBytes(data []byte) error {
+	doc := json.Make()
+	if err := doc.UnmarshalJSON(data); err != nil {
+		return fmt.Errorf("%w: %w", err, ErrInvalid)
+	}
+
+	return v.Validate(doc)
+}
+
+// Annotate validates JSON data and collects the [Annotations] contributed by the schemas it is valid against.
+//
+// Errors are those of [Validator.Validate]: annotations are not collected from invalid data.
+func (v *Validator) Annotate(data json.Document) (Annotations, error) {
+	e := &evaluation{Validator: v, data: data, collect: true}
+	e.evaluate(v.root, data, "")
+
+	if e.err != nil {
+		return Annotations{}, e.err
+	}
+
+	if len(e.violations) > 0 {
+		return Annotations{}, e.violations
+	}
+
+	return Annotations{annotations: e.annotations}, nil
+}
+
+// AnnotateBytes validates JSON data from its bytes and collects [Annotations].
+func (v *Validator) AnnotateBytes(data []byte) (Annotations, error) {
+	doc := json.Make()
+	if err := doc.UnmarshalJSON(data); err != nil {
+		return Annotations{}, fmt.Errorf("%w: %w", err, ErrInvalid)
+	}
+
+	return v.Annotate(doc)
+}
@@ -0,0 +1,386 @@
+package validator
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+func TestValidator(t *testing.T) {
+	t.Run("should validate data", func(t *testing.T) {
+		for _, tc := range []struct {
+			title  string
+			schema string
+			data   string
+			valid  bool
+		}{
+			{title: "true schema", schema: `true`, data: `1`, valid: true},
+			{title: "false schema", schema: `false`, data: `1`, valid: false},
+			{title: "type", schema: `{"type": ["integer", "null"]}`, data: `1.0`, valid: true},
+			{title: "type mismatch", schema: `{"type": "integer"}`, data: `1.5`, valid: false},
+			{title: "enum", schema: `{"enum": [{"a": [1, 2.0]}]}`, data: `{"a": [1.0, 2]}`, valid: true},
+			{title: "const", schema: `{"const": "x"}`, data: `"y"`, valid: false},
+			{title: "multipleOf", schema: `{"multipleOf": 0.1}`, data: `0.3`, valid: true},
+			{title: "exclusiveMaximum", schema: `{"exclusiveMaximum": 3}`, data: `3`, valid: false},
+			{
+				title:  "draft 4 exclusiveMinimum",
+				schema: `{"$schema": "http://json-schema.org/draft-04/schema#", "minimum": 3, "exclusiveMinimum": true}`,
+				data:   `3`,
+				valid:  false,
+			},
+			{title: "maxLength counts runes", schema: `{"maxLength": 2}`, data: `"éé"`, valid: true},
+			{title: "pattern", schema: `{"pattern": "^a+$"}`, data: `"aab"`, valid: false},
+			{title: "prefixItems and items", schema: `{"prefixItems": [{"type": "string"}], "items": {"type": "integer"}}`, data: `["a", 1, 2]`, valid: true},
+			{title: "items mismatch", schema: `{"prefixItems": [{"type": "string"}], "items": false}`, data: `["a", 1]`, valid: false},
+			{
+				title:  "draft 7 items array and additionalItems",
+				schema: `{"$schema": "http://json-schema.org/draft-07/schema#", "items": [{"type": "string"}], "additionalItems": false}`,
+				data:   `["a", "b"]`,
+				valid:  false,
+			},
+			{title: "uniqueItems", schema: `{"uniqueItems": true}`, data: `[1, {"a": 1}, 1.0]`, valid: false},
+			{title: "contains", schema: `{"contains": {"const": 1}, "maxContains": 1}`, data: `[1, 2, 1]`, valid: false},
+			{title: "minContains zero", schema: `{"contains": {"const": 1}, "minContains": 0}`, data: `[]`, valid: true},
+			{title: "required", schema: `{"required": ["a"]}`, data: `{"b": 1}`, valid: false},
+			{
+				title:  "additionalProperties",
+				schema: `{"properties": {"a": {}}, "patternProperties": {"^x-": {}}, "additionalProperties": false}`,
+				data:   `{"a": 1, "x-b": 2}`,
+				valid:  true,
+			},
+			{title: "propertyNames", schema: `{"propertyNames": {"maxLength": 1}}`, data: `{"ab": 1}`, valid: false},
+			{title: "dependentRequired", schema: `{"dependentRequired": {"a": ["b"]}}`, data: `{"a": 1}`, valid: false},
+			{
+				title:  "draft 7 dependencies",
+				schema: `{"$schema": "http://json-schema.org/draft-07/schema#", "dependencies": {"a": {"required": ["b"]}}}`,
+				data:   `{"a": 1, "b": 2}`,
+				valid:  true,
+			},
+			{title: "anyOf", schema: `{"anyOf": [{"type": "string"}, {"minimum": 2}]}`, data: `1`, valid: false},
+			{title: "oneOf with several matches", schema: `{"oneOf": [{"type": "integer"}, {"minimum": 2}]}`, data: `3`, valid: false},
+			{title: "not", schema: `{"not": {"type": "null"}}`, data: `null`, valid: false},
+			{title: "if then else", schema: `{"if": {"type": "string"}, "then": {"minLength": 2}, "else": {"minimum": 0}}`, data: `-1`, valid: false},
+			{
+				title:  "$ref to definitions",
+				schema: `{"$defs": {"positive": {"exclusiveMinimum": 0}}, "properties": {"a": {"$ref": "#/$defs/positive"}}}`,
+				data:   `{"a": 0}`,
+				valid:  false,
+			},
+			{
+				title:  "recursive $ref",
+				schema: `{"properties": {"child": {"$ref": "#"}, "name": {"type": "string"}}}`,
+				data:   `{"child": {"child": {"name": 1}}}`,
+				valid:  false,
+			},
+			{
+				title: "keywords next to $ref before draft 2019",
+				schema: `{
+					"$schema": "http://json-schema.org/draft-07/schema#",
+					"definitions": {"a": {"type": "integer"}},
+					"$ref": "#/definitions/a",
+					"maximum": 1
+				}`,
+				data:  `2`,
+				valid: true,
+			},
+			{
+				title: "$ref with an embedded $id",
+				schema: `{
+					"$id": "https://example.com/root.json",
+					"$defs": {"b": {"$id": "b.json", "$defs": {"c": {"type": "string"}}, "$ref": "#/$defs/c"}},
+					"$ref": "b.json"
+				}`,
+				data:  `1`,
+				valid: false,
+			},
+			{
+				title: "$dynamicRef",
+				schema: `{
+					"$id": "https://example.com/strict-tree.json",
+					"$dynamicAnchor": "node",
+					"$ref": "tree.json",
+					"required": ["name"],
+					"$defs": {
+						"tree": {
+							"$id": "tree.json",
+							"$dynamicAnchor": "node",
+							"type": "object",
+							"properties": {"children": {"type": "array", "items": {"$dynamicRef": "#node"}}}
+						}
+					}
+				}`,
+				data:  `{"name": "a", "children": [{"children": []}]}`,
+				valid: false,
+			},
+			{
+				title:  "unevaluatedProperties with allOf",
+				schema: `{"allOf": [{"properties": {"a": {}}}], "properties": {"b": {}}, "unevaluatedProperties": false}`,
+				data:   `{"a": 1, "b": 2}`,
+				valid:  true,
+			},
+			{
+				title:  "unevaluatedProperties rejects other properties",
+				schema: `{"allOf": [{"properties": {"a": {}}}], "unevaluatedProperties": false}`,
+				data:   `{"a": 1, "c": 3}`,
+				valid:  false,
+			},
+			{
+				title:  "unevaluatedProperties ignores invalid members of anyOf",
+				schema: `{"anyOf": [{"properties": {"a": {"type": "string"}}}, {"properties": {"b": {}}}], "unevaluatedProperties": false}`,
+				data:   `{"a": 1, "b": 2}`,
+				valid:  false,
+			},
+			{
+				title: "unevaluatedProperties with if then else",
+				schema: `{
+					"if": {"properties": {"kind": {"const": "a"}}},
+					"then": {"properties": {"a": {}}},
+					"else": {"properties": {"b": {}}},
+					"unevaluatedProperties": false
+				}`,
+				data:  `{"kind": "a", "a": 1}`,
+				valid: true,
+			},
+			{
+				title:  "unevaluatedProperties through $ref",
+				schema: `{"$defs": {"base": {"properties": {"a": {}}}}, "$ref": "#/$defs/base", "unevaluatedProperties": {"type": "integer"}}`,
+				data:   `{"a": "x", "b": "y"}`,
+				valid:  false,
+			},
+			{
+				title:  "unevaluatedProperties does not see nested locations",
+				schema: `{"properties": {"n": {"properties": {"a": {}}}}, "unevaluatedProperties": false}`,
+				data:   `{"n": {"a": 1}, "a": 1}`,
+				valid:  false,
+			},
+			{
+				title:  "unevaluatedItems with prefixItems",
+				schema: `{"allOf": [{"prefixItems": [{}]}], "unevaluatedItems": {"type": "string"}}`,
+				data:   `[1, "a", "b"]`,
+				valid:  true,
+			},
+			{
+				title:  "unevaluatedItems rejects other items",
+				schema: `{"prefixItems": [{}], "unevaluatedItems": false}`,
+				data:   `[1, 2]`,
+				valid:  false,
+			},
+			{
+				title:  "unevaluatedItems with contains",
+				schema: `{"contains": {"type": "string"}, "unevaluatedItems": {"type": "integer"}}`,
+				data:   `["a", 1, "b"]`,
+				valid:  true,
+			},
+			{
+				title: "draft 2019 unevaluatedItems with items array and contains",
+				schema: `{
+					"$schema": "https://json-schema.org/draft/2019-09/schema",
+					"items": [{}],
+					"contains": {"type": "string"},
+					"unevaluatedItems": {"type": "integer"}
+				}`,
+				data:  `[1, "a"]`,
+				valid: false,
+			},
+		} {
+			t.Run(tc.title, func(t *testing.T) {
+				v, err := New(mustSchema(t, tc.schema))
+				require.NoError(t, err)
+
+				err = v.ValidateBytes([]byte(tc.data))
+				if tc.valid {
+					require.NoError(t, err)
+
+					return
+				}
+
+				require.ErrorIs(t, err, ErrInvalid)
+			})
+		}
+	})
+
+	t.Run("should report violations with their locations", func(t *testing.T) {
+		const schema = `{
+			"$defs": {"name": {"type": "string", "minLength": 2}},
+			"properties": {"names": {"items": {"$ref": "#/$defs/name"}}}
+		}`
+
+		v, err := New(mustSchema(t, schema), WithBaseURI("https://example.com/schema.json"))
+		require.NoError(t, err)
+
+		err = v.ValidateBytes([]byte(`{"names": ["ab", "c"]}`))
+		var violations Violations
+		require.True(t, errors.As(err, &violations))
+		require.Len(t, violations, 1)
+
+		assert.Equal(t, Violation{
+			InstanceLocation:        "/names/1",
+			KeywordLocation:         "/properties/names/items/$ref/minLength",
+			AbsoluteKeywordLocation: "https://example.com/schema.json#/$defs/name/minLength",
+			Keyword:                 "minLength",
+			Message:                 "the length 1 is less than 2",
+		}, violations[0])
+	})
+
+	t.Run("should report the violations of anyOf only when no member is valid", func(t *testing.T) {
+		v, err := New(mustSchema(t, `{"anyOf": [{"type": "string"}, {"minimum": 2}]}`))
+		require.NoError(t, err)
+
+		require.NoError(t, v.ValidateBytes([]byte(`3`)))
+
+		var violations Violations
+		require.True(t, errors.As(v.ValidateBytes([]byte(`1`)), &violations))
+
+		keywords := make([]string, 0, len(violations))
+		for _, violation := range violations {
+			keywords = append(keywords, violation.Keyword)
+		}
+		assert.Equal(t, []string{"anyOf", "type", "minimum"}, keywords)
+	})
+
+	t.Run("should validate custom keywords", func(t *testing.T) {
+		registry := jsonschema.NewVocabularyRegistry()
+		require.NoError(t, registry.Register(jsonschema.Vocabulary{
+			URI: "https://example.com/vocab/even",
+			Keywords: []jsonschema.Keyword{{
+				Name: "x-even",
+				Kind: jsonschema.KeywordAssertion,
+				Validate: func(_, instance json.Document) error {
+					if r, ok := keywords.Rat(instance); ok && r.IsInt() && r.Num().Bit(0) == 1 {
+						return errors.New("odd number")
+					}
+
+					return nil
+				},
+			}},
+		}))
+
+		v, err := New(mustSchema(t, `{"x-even": true}`), WithVocabularies(registry))
+		require.NoError(t, err)
+
+		require.NoError(t, v.ValidateBytes([]byte(`2`)))
+		require.ErrorIs(t, v.ValidateBytes([]byte(`3`)), ErrInvalid)
+	})
+
+	t.Run("should collect annotations", func(t *testing.T) {
+		const schema = `{
+			"title": "a pet",
+			"properties": {
+				"name": {"$ref": "#/$defs/name", "description": "the name of the pet"},
+				"tag": {"deprecated": true, "x-label": "Tag"}
+			},
+			"anyOf": [{"properties": {"name": {"title": "valid"}}}, {"properties": {"name": {"title": "invalid", "maxLength": 1}}}],
+			"$defs": {"name": {"type": "string", "default": "rex", "readOnly": true}}
+		}`
+
+		v, err := New(mustSchema(t, schema), WithBaseURI("https://example.com/schema.json"))
+		require.NoError(t, err)
+
+		annotations, err := v.AnnotateBytes([]byte(`{"name": "rex", "tag": "dog"}`))
+		require.NoError(t, err)
+
+		collected := make([]string, 0, annotations.Len())
+		for annotation := range annotations.All() {
+			collected = append(collected, annotation.String())
+		}
+
+		assert.Equal(t, []string{
+			`at "/name": "default": "rex"`,
+			`at "/name": "readOnly": true`,
+			`at "/name": "description": "the name of the pet"`,
+			`at "/tag": "deprecated": true`,
+			`at "/tag": "x-label": "Tag"`,
+			`at "/name": "title": "valid"`,
+			`at "": "title": "a pet"`,
+		}, collected)
+
+		assert.Equal(t, []string{"/name", "/tag", ""}, slices.Collect(annotations.Locations()))
+		assert.True(t, annotations.IsDeprecated("/tag"))
+		assert.False(t, annotations.IsDeprecated("/name"))
+
+		defaults := slices.Collect(annotations.Keyword("/name", "default"))
+		require.Len(t, defaults, 1)
+		assert.Equal(t, "/properties/name/$ref/default", defaults[0].KeywordLocation)
+		assert.Equal(t, "https://example.com/schema.json#/$defs/name/default", defaults[0].AbsoluteKeywordLocation)
+
+		_, err = v.AnnotateBytes([]byte(`{"name": 1}`))
+		require.ErrorIs(t, err, ErrInvalid)
+	})
+
+	t.Run("should resolve $data references against the data", func(t *testing.T) {
+		sch := jsonschema.Make(jsonschema.WithDollarData(true))
+		require.NoError(t, sch.UnmarshalJSON([]byte(`{
+			"properties": {
+				"a": {"maximum": {"$data": "1/b"}},
+				"name": {"enum": {"$data": "1/names"}},
+				"key": {"const": {"$data": "0#"}}
+			}
+		}`)))
+		v, err := New(sch)
+		require.NoError(t, err)
+
+		for _, tc := range []struct {
+			title string
+			data  string
+			valid bool
+		}{
+			{title: "within bound", data: `{"a": 3, "b": 5}`, valid: true},
+			{title: "beyond bound", data: `{"a": 5, "b": 3}`, valid: false},
+			{title: "enum from the root", data: `{"name": "x", "names": ["x", "y"]}`, valid: true},
+			{title: "not in enum from the root", data: `{"name": "z", "names": ["x", "y"]}`, valid: false},
+			{title: "key of the value", data: `{"key": "key"}`, valid: true},
+			{title: "unresolvable pointer is ignored", data: `{"a": 5, "name": "z"}`, valid: true},
+			{title: "resolved value of the wrong type", data: `{"a": 5, "b": "x"}`, valid: false},
+		} {
+			t.Run(tc.title, func(t *testing.T) {
+				err := v.ValidateBytes([]byte(tc.data))
+				if tc.valid {
+					require.NoError(t, err)
+
+					return
+				}
+
+				require.ErrorIs(t, err, ErrInvalid)
+			})
+		}
+
+		var violations Violations
+		require.ErrorAs(t, v.ValidateBytes([]byte(`{"a": 5, "b": 3}`)), &violations)
+		require.Len(t, violations, 1)
+		assert.Equal(t, "/a", violations[0].InstanceLocation)
+		assert.Equal(t, "/properties/a/maximum", violations[0].KeywordLocation)
+
+		t.Run("unless $data is disabled", func(t *testing.T) {
+			v, err := New(mustSchema(t, `{"const": {"$data": "0#"}}`))
+			require.NoError(t, err)
+
+			require.NoError(t, v.ValidateBytes([]byte(`{"$data": "0#"}`)))
+			require.ErrorIs(t, v.ValidateBytes([]byte(`1`)), ErrInvalid)
+		})
+	})
+
+	t.Run("should fail on unresolved references", func(t *testing.T) {
+		v, err := New(mustSchema(t, `{"$ref": "#/$defs/missing"}`))
+		require.NoError(t, err)
+
+		err = v.ValidateBytes([]byte(`1`))
+		require.ErrorIs(t, err, ErrValidator)
+		require.NotErrorIs(t, err, ErrInvalid)
+	})
+}
+
+func mustSchema(t *testing.T, input string) jsonschema.Schema {
+	t.Helper()
+
+	s := jsonschema.Make()
+	require.NoError(t, s.UnmarshalJSON([]byte(input)))
+
+	return s
+}
@@ -0,0 +1,59 @@
+package validator
+
+import (
+	stdjson "encoding/json"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/jsonschema/internal/keywords"
+)
+
+func hasType(data json.Document, name string) bool {
+	switch name {
+	case "null":
+		return data.IsNull()
+	case "boolean":
+		return data.IsBool()
+	case "string":
+		return data.IsString()
+	case "array":
+		return data.IsArray()
+	case "object":
+		return data.IsObject()
+	case "number":
+		return data.IsNumber()
+	case "integer":
+		if !data.IsNumber() {
+			return false
+		}
+		r, ok := keywords.Rat(data)
+
+		return ok && r.IsInt()
+	default:
+		return false
+	}
+}
+
+func typeOf(data json.Document) string {
+	for _, name := range []string{"null", "boolean", "string", "integer", "number", "array", "object"} {
+		if hasType(data, name) {
+			return name
+		}
+	}
+
+	return "undefined"
+}
+
+// stringDocument builds a JSON string, to evaluate property names.
+func stringDocument(value string) (json.Document, error) {
+	data, err := stdjson.Marshal(value)
+	if err != nil {
+		return json.Document{}, err
+	}
+
+	doc := json.Make()
+	if err := doc.UnmarshalJSON(data); err != nil {
+		return json.Document{}, err
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,49 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Violation describes how some JSON data violates a keyword of a schema.
+//
+// Locations follow the "basic" output format of JSON schema.
+type Violation struct {
+	// InstanceLocation is the JSON pointer to the violating value in the data.
+	InstanceLocation string
+
+	// KeywordLocation is the JSON pointer to the violated keyword, following the "$ref" s evaluated
+	// from the root schema.
+	KeywordLocation string
+
+	// AbsoluteKeywordLocation is the URI of the violated keyword, with a JSON pointer fragment in its document.
+	AbsoluteKeywordLocation string
+
+	// Keyword is the violated keyword.
+	Keyword string
+
+	// Message explains the violation.
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("at %q: %q: %s", v.InstanceLocation, v.Keyword, v.Message)
+}
+
+// Violations lists the [Violation] s found when validating some JSON data.
+//
+// It is the error returned by [Validator.Validate], and it wraps [ErrInvalid].
+type Violations []Violation
+
+func (v Violations) Error() string {
+	messages := make([]string, 0, len(v))
+	for _, violation := range v {
+		messages = append(messages, violation.String())
+	}
+
+	return fmt.Sprintf("%v: %s", ErrInvalid, strings.Join(messages, "; "))
+}
+
+func (v Violations) Unwrap() error {
+	return ErrInvalid
+}
@@ -1,5 +1,7 @@
 package jsonschema
 
+import "strings"
+
 // Version describes a recognized dialect of JSON schema.
 type Version uint8
 
@@ -71,18 +73,20 @@ func (v Version) String() string {
 // For OpenAPI schemas, see https://spec.openapis.org/oas.
 //
 // Notice that OpenAPI schema do not necesarily resolve with a meta-schema like all json-schema.org drafts.
+//
+// Drafts 4 to 7 are identified by their canonical "http://" URL, which other tools expect.
 func (v Version) MetaSchemaURL() string {
 	switch v {
 	case VersionUndefined:
 		return ""
 	case VersionDraft4:
-		return "https://json-schema.org/draft-04/schema#"
+		return "http://json-schema.org/draft-04/schema#"
 	case VersionDraft5:
-		return "https://json-schema.org/draft-04/schema#"
+		return "http://json-schema.org/draft-04/schema#"
 	case VersionDraft6:
-		return "https://json-schema.org/draft-06/schema#"
+		return "http://json-schema.org/draft-06/schema#"
 	case VersionDraft7:
-		return "https://json-schema.org/draft-07/schema#"
+		return "http://json-schema.org/draft-07/schema#"
 	case VersionDraft2019:
 		return "https://json-schema.org/draft/2019-09/schema"
 	case VersionDraft2020:
@@ -112,6 +116,37 @@ func (v Version) MetaSchemaURL() string {
 	}
 }
 
+// VersionFromMetaSchemaURL recognizes the version of JSON schema identified by the URL of a meta-schema,
+// e.g. the value of the "$schema" keyword.
+//
+// The URL may use the http or https scheme, and may have an empty fragment.
+//
+// Several versions share the same meta-schema: the earliest one is returned, e.g. [VersionDraft4] for
+// draft 4 and draft 5 or [VersionOpenAPIv300] for all OpenAPI v3.0.x versions.
+//
+// It returns [VersionUndefined] if the URL is not recognized.
+func VersionFromMetaSchemaURL(url string) Version {
+	normalize := func(u string) string {
+		u = strings.TrimSuffix(u, "#")
+		u = strings.TrimPrefix(u, "https://")
+
+		return strings.TrimPrefix(u, "http://")
+	}
+
+	url = normalize(url)
+	if url == "" {
+		return VersionUndefined
+	}
+
+	for v := VersionDraft4; v <= VersionOpenAPIv4Draft; v++ {
+		if normalize(v.MetaSchemaURL()) == url {
+			return v
+		}
+	}
+
+	return VersionUndefined
+}
+
 // Less compare the schema version of v with the version of vv.
 //
 // It returns true is the version of vv is prior to the version of v.
@@ -196,3 +231,76 @@ type VersionRequirements struct {
 	StrictMaxVersion    Version
 	StrictMaxOAIVersion Version
 }
+
+// Allows tells if a [Version] satisfies the [VersionRequirements].
+//
+// A non-strict MaxVersion only marks a deprecation and doesn't exclude later versions.
+// Requirements on OpenAPI versions apply to OpenAPI versions only: keywords which are only defined by OpenAPI
+// are not allowed by JSON schema drafts.
+//
+// Any requirement is satisfied by [VersionUndefined].
+func (r VersionRequirements) Allows(v Version) bool {
+	if v == VersionUndefined {
+		return true
+	}
+
+	if v.isOpenAPI() {
+		if r.MinOAIVersion != VersionUndefined || r.StrictMaxOAIVersion != VersionUndefined {
+			return isAtLeast(v, r.MinOAIVersion) && isAtMost(v, r.StrictMaxOAIVersion)
+		}
+
+		return isAtLeast(v, r.MinVersion) && isAtMost(v, r.StrictMaxVersion)
+	}
+
+	if r.MinVersion.isOpenAPI() || (r.MinVersion == VersionUndefined && r.MinOAIVersion != VersionUndefined) {
+		return false
+	}
+
+	return isAtLeast(v, r.MinVersion) && isAtMost(v, r.StrictMaxVersion)
+}
+
+// Merge requirements: the merged [VersionRequirements] are satisfied by versions which satisfy both.
+func (r VersionRequirements) Merge(other VersionRequirements) VersionRequirements {
+	return VersionRequirements{
+		MinVersion:          laterVersion(r.MinVersion, other.MinVersion),
+		MinOAIVersion:       laterVersion(r.MinOAIVersion, other.MinOAIVersion),
+		MaxVersion:          earlierVersion(r.MaxVersion, other.MaxVersion),
+		MaxOAIVersion:       earlierVersion(r.MaxOAIVersion, other.MaxOAIVersion),
+		StrictMaxVersion:    earlierVersion(r.StrictMaxVersion, other.StrictMaxVersion),
+		StrictMaxOAIVersion: earlierVersion(r.StrictMaxOAIVersion, other.StrictMaxOAIVersion),
+	}
+}
+
+func (v Version) isOpenAPI() bool {
+	return v >= VersionOpenAPIv2
+}
+
+func isAtLeast(v, minVersion Version) bool {
+	return minVersion == VersionUndefined || !v.Less(minVersion)
+}
+
+func isAtMost(v, maxVersion Version) bool {
+	return maxVersion == VersionUndefined || !maxVersion.Less(v)
+}
+
+func laterVersion(v, vv Version) Version {
+	switch {
+	case v == VersionUndefined:
+		return vv
+	case vv == VersionUndefined, vv.Less(v):
+		return v
+	default:
+		return vv
+	}
+}
+
+func earlierVersion(v, vv Version) Version {
+	switch {
+	case v == VersionUndefined:
+		return vv
+	case vv == VersionUndefined, v.Less(vv):
+		return v
+	default:
+		return vv
+	}
+}
@@ -0,0 +1,344 @@
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+
+	"github.com/fredbi/core/json"
+	"github.com/fredbi/core/json/constrained"
+	"github.com/fredbi/core/json/stores/values"
+)
+
+// KeywordKind tells how a keyword of a [Vocabulary] takes part in the validation of JSON data.
+type KeywordKind uint8
+
+const (
+	// KeywordAnnotation is a keyword that only describes JSON data, and never fails a validation.
+	KeywordAnnotation KeywordKind = iota + 1
+
+	// KeywordAssertion is a keyword that asserts a constraint on JSON data, and may fail a validation.
+	KeywordAssertion
+)
+
+func (k KeywordKind) String() string {
+	switch k {
+	case KeywordAnnotation:
+		return "annotation"
+	case KeywordAssertion:
+		return "assertion"
+	default:
+		return "unknown"
+	}
+}
+
+// KeywordValidator validates a JSON instance against the value of a custom keyword found in a schema.
+//
+// It returns an error if the instance is invalid.
+type KeywordValidator func(value json.Document, instance json.Document) error
+
+// Keyword declares a keyword of a [Vocabulary].
+type Keyword struct {
+	// Name of the keyword, e.g. "deprecatedSince".
+	Name string
+
+	// Shape constrains the value of the keyword. A nil Shape allows any value.
+	Shape *constrained.Constraints
+
+	// Versions of JSON schema that support the keyword. The zero value allows all versions.
+	Versions VersionRequirements
+
+	// Kind tells if the keyword is an annotation or an assertion. The default is [KeywordAnnotation].
+	Kind KeywordKind
+
+	// Validate is an optional function invoked by the validator to check JSON data against an assertion keyword.
+	Validate KeywordValidator
+}
+
+// IsAssertion tells if the keyword asserts a constraint on JSON data.
+func (k Keyword) IsAssertion() bool {
+	return k.Kind == KeywordAssertion
+}
+
+// Vocabulary is a set of keywords identified by a URI.
+//
+// Since draft 2019, a meta-schema declares the vocabularies used by the schemas of its dialect with the
+// "$vocabulary" keyword.
+type Vocabulary struct {
+	URI      string
+	Keywords []Keyword
+}
+
+// Standard vocabularies of JSON schema.
+const (
+	VocabularyCore2019       = "https://json-schema.org/draft/2019-09/vocab/core"
+	VocabularyApplicator2019 = "https://json-schema.org/draft/2019-09/vocab/applicator"
+	VocabularyValidation2019 = "https://json-schema.org/draft/2019-09/vocab/validation"
+	VocabularyMetadata2019   = "https://json-schema.org/draft/2019-09/vocab/meta-data"
+	VocabularyFormat2019     = "https://json-schema.org/draft/2019-09/vocab/format"
+	VocabularyContent2019    = "https://json-schema.org/draft/2019-09/vocab/content"
+
+	VocabularyCore2020             = "https://json-schema.org/draft/2020-12/vocab/core"
+	VocabularyApplicator2020       = "https://json-schema.org/draft/2020-12/vocab/applicator"
+	VocabularyUnevaluated2020      = "https://json-schema.org/draft/2020-12/vocab/unevaluated"
+	VocabularyValidation2020       = "https://json-schema.org/draft/2020-12/vocab/validation"
+	VocabularyMetadata2020         = "https://json-schema.org/draft/2020-12/vocab/meta-data"
+	VocabularyFormatAnnotation2020 = "https://json-schema.org/draft/2020-12/vocab/format-annotation"
+	VocabularyFormatAssertion2020  = "https://json-schema.org/draft/2020-12/vocab/format-assertion"
+	VocabularyContent2020          = "https://json-schema.org/draft/2020-12/vocab/content"
+)
+
+// standard vocabularies, with the names of their keywords.
+//
+//nolint:gochecknoglobals // declarations of standard vocabularies
+var (
+	standardVocabularies = []Vocabulary{
+		standardVocabulary(VocabularyCore2019, KeywordAssertion,
+			"$id", "$schema", "$anchor", "$ref", "$recursiveRef", "$recursiveAnchor", "$vocabulary", "$comment", "$defs",
+		),
+		standardVocabulary(VocabularyApplicator2019, KeywordAssertion,
+			"additionalItems", "unevaluatedItems", "items", "contains", "additionalProperties", "unevaluatedProperties",
+			"properties", "patternProperties", "dependentSchemas", "propertyNames", "if", "then", "else",
+			"allOf", "anyOf", "oneOf", "not",
+		),
+		standardVocabulary(VocabularyValidation2019, KeywordAssertion, validationKeywords...),
+		standardVocabulary(VocabularyMetadata2019, KeywordAnnotation, metadataKeywords...),
+		standardVocabulary(VocabularyFormat2019, KeywordAnnotation, "format"),
+		standardVocabulary(VocabularyContent2019, KeywordAnnotation, contentKeywords...),
+
+		standardVocabulary(VocabularyCore2020, KeywordAssertion,
+			"$id", "$schema", "$ref", "$anchor", "$dynamicRef", "$dynamicAnchor", "$vocabulary", "$comment", "$defs",
+		),
+		standardVocabulary(VocabularyApplicator2020, KeywordAssertion,
+			"prefixItems", "items", "contains", "additionalProperties", "properties", "patternProperties",
+			"dependentSchemas", "propertyNames", "if", "then", "else", "allOf", "anyOf", "oneOf", "not",
+		),
+		standardVocabulary(VocabularyUnevaluated2020, KeywordAssertion, "unevaluatedItems", "unevaluatedProperties"),
+		standardVocabulary(VocabularyValidation2020, KeywordAssertion, validationKeywords...),
+		standardVocabulary(VocabularyMetadata2020, KeywordAnnotation, metadataKeywords...),
+		standardVocabulary(VocabularyFormatAnnotation2020, KeywordAnnotation, "format"),
+		standardVocabulary(VocabularyFormatAssertion2020, KeywordAssertion, "format"),
+		standardVocabulary(VocabularyContent2020, KeywordAnnotation, contentKeywords...),
+	}
+
+	// the vocabularies enabled by the standard meta-schemas
+	defaultVocabularies = map[Version][]string{
+		VersionDraft2019: {
+			VocabularyCore2019, VocabularyApplicator2019, VocabularyValidation2019,
+			VocabularyMetadata2019, VocabularyFormat2019, VocabularyContent2019,
+		},
+		VersionDraft2020: {
+			VocabularyCore2020, VocabularyApplicator2020, VocabularyUnevaluated2020, VocabularyValidation2020,
+			VocabularyMetadata2020, VocabularyFormatAnnotation2020, VocabularyContent2020,
+		},
+	}
+)
+
+//nolint:gochecknoglobals // shared lists of keywords
+var (
+	validationKeywords = []string{
+		"type", "const", "enum", "multipleOf", "maximum", "exclusiveMaximum", "minimum", "exclusiveMinimum",
+		"maxLength", "minLength", "pattern", "maxItems", "minItems", "uniqueItems", "maxContains", "minContains",
+		"maxProperties", "minProperties", "required", "dependentRequired",
+	}
+
+	metadataKeywords = []string{"title", "description", "default", "deprecated", "readOnly", "writeOnly", "examples"}
+
+	contentKeywords = []string{"contentEncoding", "contentMediaType", "contentSchema"}
+)
+
+func standardVocabulary(uri string, kind KeywordKind, names ...string) Vocabulary {
+	keywords := make([]Keyword, 0, len(names))
+
+	for _, name := range names {
+		key := values.MakeInternedKey(name)
+		keywords = append(keywords, Keyword{
+			Name:     name,
+			Shape:    keywordConstraints[key],
+			Versions: keywordVersions[key],
+			Kind:     kind,
+		})
+	}
+
+	return Vocabulary{URI: uri, Keywords: keywords}
+}
+
+// VocabularyRegistry knows about the standard vocabularies of JSON schema and about custom vocabularies.
+//
+// Custom vocabularies declare in-house keywords, so that their values are checked when decoding a [Schema]
+// (see [WithVocabularies]) or when validating a [Schema] against its meta-schema (see [MetaValidator]).
+//
+// A [VocabularyRegistry] is not safe for concurrent registrations.
+type VocabularyRegistry struct {
+	vocabularies map[string]Vocabulary
+	custom       map[string]Keyword // custom keywords by name
+	vocabularyOf map[string]string  // custom keywords to the URI of their vocabulary
+}
+
+// NewVocabularyRegistry builds a [VocabularyRegistry] that knows about the standard vocabularies of JSON schema.
+func NewVocabularyRegistry() *VocabularyRegistry {
+	r := &VocabularyRegistry{
+		vocabularies: make(map[string]Vocabulary, len(standardVocabularies)),
+		custom:       make(map[string]Keyword),
+		vocabularyOf: make(map[string]string),
+	}
+
+	for _, vocabulary := range standardVocabularies {
+		r.vocabularies[vocabulary.URI] = vocabulary
+	}
+
+	return r
+}
+
+// Register custom vocabularies.
+//
+// It fails with [ErrVocabulary] if a vocabulary is already known, or if one of its keywords is a standard keyword
+// or a keyword of another custom vocabulary.
+func (r *VocabularyRegistry) Register(vocabularies ...Vocabulary) error {
+	for _, vocabulary := range vocabularies {
+		if vocabulary.URI == "" {
+			return fmt.Errorf("a vocabulary must be identified by a URI: %w", ErrVocabulary)
+		}
+
+		if _, exists := r.vocabularies[vocabulary.URI]; exists {
+			return fmt.Errorf("vocabulary %q is already registered: %w", vocabulary.URI, ErrVocabulary)
+		}
+
+		for _, keyword := range vocabulary.Keywords {
+			if keyword.Name == "" {
+				return fmt.Errorf("a keyword of vocabulary %q has no name: %w", vocabulary.URI, ErrVocabulary)
+			}
+
+			if isStandardKeyword(keyword.Name) {
+				return fmt.Errorf("keyword %q of vocabulary %q is a standard keyword: %w", keyword.Name, vocabulary.URI, ErrVocabulary)
+			}
+
+			if other, exists := r.vocabularyOf[keyword.Name]; exists {
+				return fmt.Errorf("keyword %q of vocabulary %q is already declared by vocabulary %q: %w",
+					keyword.Name, vocabulary.URI, other, ErrVocabulary,
+				)
+			}
+		}
+
+		r.vocabularies[vocabulary.URI] = vocabulary
+		for _, keyword := range vocabulary.Keywords {
+			if keyword.Kind == 0 {
+				keyword.Kind = KeywordAnnotation
+			}
+
+			r.custom[keyword.Name] = keyword
+			r.vocabularyOf[keyword.Name] = vocabulary.URI
+		}
+	}
+
+	return nil
+}
+
+// Vocabulary returns a known vocabulary, standard or custom.
+func (r *VocabularyRegistry) Vocabulary(uri string) (Vocabulary, bool) {
+	vocabulary, ok := r.vocabularies[uri]
+
+	return vocabulary, ok
+}
+
+// Keyword returns a custom keyword, with the URI of its vocabulary.
+func (r *VocabularyRegistry) Keyword(name string) (Keyword, string, bool) {
+	keyword, ok := r.custom[name]
+	if !ok {
+		return Keyword{}, "", false
+	}
+
+	return keyword, r.vocabularyOf[name], true
+}
+
+// Keywords yields all custom keywords, in lexicographic order.
+func (r *VocabularyRegistry) Keywords() iter.Seq[Keyword] {
+	return func(yield func(Keyword) bool) {
+		for _, name := range slices.Sorted(maps.Keys(r.custom)) {
+			if !yield(r.custom[name]) {
+				return
+			}
+		}
+	}
+}
+
+// ValidateKeywords validates a JSON instance against the custom assertion keywords of a schema,
+// by invoking their [KeywordValidator].
+//
+// Only the keywords of this schema are evaluated, not the keywords of its subschemas.
+// This is the hook used by the validator to support custom keywords.
+func (r *VocabularyRegistry) ValidateKeywords(schema json.Document, instance json.Document) error {
+	if !schema.IsObject() {
+		return nil
+	}
+
+	var errs []error
+	for name, value := range schema.Pairs() {
+		keyword, ok := r.custom[name]
+		if !ok || !keyword.IsAssertion() || keyword.Validate == nil {
+			continue
+		}
+
+		if err := keyword.Validate(value, instance); err != nil {
+			errs = append(errs, fmt.Errorf("keyword %q: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// isCustom tells if a vocabulary is a custom one.
+func (r *VocabularyRegistry) isCustom(uri string) bool {
+	_, isStandard := standardVocabularyURIs[uri]
+
+	return !isStandard
+}
+
+//nolint:gochecknoglobals // index of standard vocabularies and keywords
+var (
+	standardVocabularyURIs = func() map[string]struct{} {
+		index := make(map[string]struct{}, len(standardVocabularies))
+		for _, vocabulary := range standardVocabularies {
+			index[vocabulary.URI] = struct{}{}
+		}
+
+		return index
+	}()
+
+	standardKeywords = func() map[string]struct{} {
+		index := make(map[string]struct{})
+		for key := range keywordVersions {
+			index[key.String()] = struct{}{}
+		}
+
+		for _, vocabulary := range standardVocabularies {
+			for _, keyword := range vocabulary.Keywords {
+				index[keyword.Name] = struct{}{}
+			}
+		}
+
+		for _, name := range []string{"id", "definitions", "dependencies", "example"} {
+			index[name] = struct{}{}
+		}
+
+		return index
+	}()
+)
+
+func isStandardKeyword(name string) bool {
+	_, ok := standardKeywords[name]
+
+	return ok
+}
+
+// StandardKeyword tells if name is a keyword defined by JSON schema or by OpenAPI,
+// and yields the [VersionRequirements] of this keyword.
+func StandardKeyword(name string) (VersionRequirements, bool) {
+	if !isStandardKeyword(name) {
+		return VersionRequirements{}, false
+	}
+
+	return keywordVersions[values.MakeInternedKey(name)], true
+}
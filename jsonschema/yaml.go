@@ -0,0 +1,142 @@
+package jsonschema
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// YAMLToJSON converts a stream of YAML documents to JSON, preserving the order of keys.
+//
+// Every document of the stream yields a JSON document. An empty stream yields no document.
+// Invalid YAML, or YAML values that cannot be represented in JSON, yield an error wrapping [ErrYAML].
+func YAMLToJSON(data []byte) ([][]byte, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	var documents [][]byte
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w: %w", len(documents), err, ErrYAML)
+		}
+
+		var buf bytes.Buffer
+		if err := writeYAMLNode(&buf, &doc); err != nil {
+			return nil, fmt.Errorf("document %d: %w: %w", len(documents), err, ErrYAML)
+		}
+
+		documents = append(documents, buf.Bytes())
+	}
+}
+
+func writeYAMLNode(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+
+			return nil
+		}
+
+		return writeYAMLNode(buf, node.Content[0])
+	case yaml.AliasNode:
+		return writeYAMLNode(buf, node.Alias)
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, elem := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := writeYAMLNode(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+
+		return nil
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			key, err := stdjson.Marshal(node.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+
+			if err := writeYAMLNode(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+
+		return nil
+	case yaml.ScalarNode:
+		return writeYAMLScalar(buf, node)
+	default:
+		return fmt.Errorf("unsupported YAML node at line %d", node.Line)
+	}
+}
+
+func writeYAMLScalar(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.ShortTag() {
+	case "!!null":
+		buf.WriteString("null")
+	case "!!bool":
+		var value bool
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		buf.WriteString(strconv.FormatBool(value))
+	case "!!int":
+		var value int64
+		if err := node.Decode(&value); err == nil {
+			buf.WriteString(strconv.FormatInt(value, 10))
+
+			break
+		}
+
+		// integers which overflow an int64
+		large, ok := new(big.Int).SetString(strings.ReplaceAll(node.Value, "_", ""), 0)
+		if !ok {
+			return fmt.Errorf("invalid YAML integer %q at line %d", node.Value, node.Line)
+		}
+		buf.WriteString(large.String())
+	case "!!float":
+		var value float64
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+
+		if math.IsInf(value, 0) || math.IsNaN(value) {
+			return fmt.Errorf("the YAML number %q at line %d cannot be represented in JSON", node.Value, node.Line)
+		}
+		buf.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	default:
+		value, err := stdjson.Marshal(node.Value)
+		if err != nil {
+			return err
+		}
+		buf.Write(value)
+	}
+
+	return nil
+}
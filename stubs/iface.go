@@ -2,11 +2,14 @@ package stubs
 
 import (
 	"math/big"
+	"math/rand/v2"
 	"regexp"
 )
 
 type FakerContext struct {
-	// * random generator
+	// Rand is the random generator to be used by the [Faker], so generated values are deterministic for a seed.
+	Rand *rand.Rand
+
 	// * available classes for strings
 }
 